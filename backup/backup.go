@@ -0,0 +1,219 @@
+// Package backup snapshots the bot's data files into verified archives and restores them. Archives are
+// gzipped tarballs holding a manifest with each file's size and SHA-256, so a damaged or incomplete
+// backup is refused before anything is overwritten.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// manifestName is the archive entry describing the backed up files, always written first
+const manifestName = "manifest.json"
+
+// maxFileSize bounds how much of one archive entry is read, data files are far smaller
+const maxFileSize = 256 << 20
+
+// ErrCorrupt is returned when an archive doesn't match its manifest
+var ErrCorrupt = errors.New("backup is corrupt")
+
+// File is a data file to back up. Name identifies it in archives, so the file can be restored to
+// wherever Path points on the machine restoring it.
+type File struct {
+	Name string
+	Path string
+}
+
+// Entry describes one file in a backup
+type Entry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest lists the files in a backup
+type Manifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	Files     []Entry   `json:"files"`
+}
+
+// Entry returns the manifest entry of a file
+func (m Manifest) Entry(name string) (Entry, bool) {
+	for _, entry := range m.Files {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Write archives files to w. Files that don't exist yet, such as a store that was never saved, are left
+// out.
+func Write(w io.Writer, files []File, now time.Time) (Manifest, error) {
+	manifest := Manifest{CreatedAt: now.UTC(), Files: []Entry{}}
+	contents := make(map[string][]byte, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Manifest{}, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		contents[file.Name] = data
+		manifest.Files = append(manifest.Files, Entry{Name: file.Name, Size: int64(len(data)), SHA256: checksum(data)})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	if err := writeEntry(archive, manifestName, manifestData, now); err != nil {
+		return Manifest{}, err
+	}
+	for _, entry := range manifest.Files {
+		if err := writeEntry(archive, entry.Name, contents[entry.Name], now); err != nil {
+			return Manifest{}, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// writeEntry adds one file to an archive
+func writeEntry(archive *tar.Writer, name string, data []byte, now time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: now}
+	if err := archive.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := archive.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	return nil
+}
+
+// Read reads an archive and checks every file against the manifest, returning the manifest and the
+// files' contents by name. It fails with ErrCorrupt when a file is missing, extra or altered.
+func Read(r io.Reader) (Manifest, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, nil, fmt.Errorf("%w: not a gzip archive: %v", ErrCorrupt, err)
+	}
+	defer gz.Close()
+
+	var manifest *Manifest
+	contents := make(map[string][]byte)
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Manifest{}, nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(archive, maxFileSize+1))
+		if err != nil {
+			return Manifest{}, nil, fmt.Errorf("%w: failed to read %s: %v", ErrCorrupt, header.Name, err)
+		}
+		if len(data) > maxFileSize {
+			return Manifest{}, nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrCorrupt, header.Name, maxFileSize)
+		}
+
+		if header.Name == manifestName && manifest == nil {
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return Manifest{}, nil, fmt.Errorf("%w: invalid manifest: %v", ErrCorrupt, err)
+			}
+			continue
+		}
+		if _, seen := contents[header.Name]; seen {
+			return Manifest{}, nil, fmt.Errorf("%w: %s appears twice", ErrCorrupt, header.Name)
+		}
+		contents[header.Name] = data
+	}
+
+	if manifest == nil {
+		return Manifest{}, nil, fmt.Errorf("%w: no manifest", ErrCorrupt)
+	}
+	for _, entry := range manifest.Files {
+		data, ok := contents[entry.Name]
+		if !ok {
+			return Manifest{}, nil, fmt.Errorf("%w: %s is missing", ErrCorrupt, entry.Name)
+		}
+		if int64(len(data)) != entry.Size || checksum(data) != entry.SHA256 {
+			return Manifest{}, nil, fmt.Errorf("%w: %s doesn't match its checksum", ErrCorrupt, entry.Name)
+		}
+	}
+	for name := range contents {
+		if _, listed := manifest.Entry(name); !listed {
+			return Manifest{}, nil, fmt.Errorf("%w: %s isn't in the manifest", ErrCorrupt, name)
+		}
+	}
+	return *manifest, contents, nil
+}
+
+// Restore verifies an archive and writes each file in it to the path of the file with the same name.
+// Nothing is written unless the whole archive is intact. Files in the archive the bot no longer knows are
+// skipped, and known files missing from it are left as they are.
+func Restore(r io.Reader, files []File) (Manifest, error) {
+	manifest, contents, err := Read(r)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	for _, file := range files {
+		data, ok := contents[file.Name]
+		if !ok {
+			continue
+		}
+		if err := utils.WriteFileAtomic(file.Path, data, 0o644); err != nil {
+			return Manifest{}, fmt.Errorf("failed to restore %s: %w", file.Name, err)
+		}
+	}
+	return manifest, nil
+}
+
+// Restored returns the names of the manifest's files among files, the ones Restore writes
+func (m Manifest) Restored(files []File) []string {
+	var names []string
+	for _, file := range files {
+		if _, ok := m.Entry(file.Name); ok {
+			names = append(names, file.Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Verify reads an archive and reports whether it matches its manifest
+func Verify(r io.Reader) (Manifest, error) {
+	manifest, _, err := Read(r)
+	return manifest, err
+}
+
+// checksum returns the hex SHA-256 of data
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,143 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2025, time.June, 1, 3, 4, 5, 0, time.UTC)
+
+// dataFiles creates votes.json and prefs.json in a temporary directory, and names a third file that
+// doesn't exist
+func dataFiles(t *testing.T) []File {
+	dir := t.TempDir()
+	files := []File{
+		{Name: "votes.json", Path: filepath.Join(dir, "votes.json")},
+		{Name: "prefs.json", Path: filepath.Join(dir, "prefs.json")},
+		{Name: "missing.json", Path: filepath.Join(dir, "missing.json")},
+	}
+	require.NoError(t, os.WriteFile(files[0].Path, []byte(`{"user_1":1}`), 0o644))
+	require.NoError(t, os.WriteFile(files[1].Path, []byte(`{"user_1":"dark"}`), 0o644))
+	return files
+}
+
+func TestWriteAndRestore(t *testing.T) {
+	files := dataFiles(t)
+
+	var archive bytes.Buffer
+	manifest, err := Write(&archive, files, testTime)
+	require.NoError(t, err)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "votes.json", manifest.Files[0].Name)
+	assert.EqualValues(t, 12, manifest.Files[0].Size)
+	assert.True(t, manifest.CreatedAt.Equal(testTime))
+
+	require.NoError(t, os.WriteFile(files[0].Path, []byte(`{}`), 0o644))
+	require.NoError(t, os.Remove(files[1].Path))
+
+	restored, err := Restore(bytes.NewReader(archive.Bytes()), files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prefs.json", "votes.json"}, restored.Restored(files))
+
+	votes, err := os.ReadFile(files[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user_1":1}`, string(votes))
+	prefs, err := os.ReadFile(files[1].Path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user_1":"dark"}`, string(prefs))
+	assert.NoFileExists(t, files[2].Path)
+}
+
+// rewrite copies an archive, letting edit change or drop each entry, then adds extra entries
+func rewrite(t *testing.T, archive []byte, edit func(name string, data []byte) ([]byte, bool), extra ...string) []byte {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+	in := tar.NewReader(gz)
+
+	var out bytes.Buffer
+	outGz := gzip.NewWriter(&out)
+	w := tar.NewWriter(outGz)
+	for {
+		header, err := in.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(in)
+		require.NoError(t, err)
+		if data, keep := edit(header.Name, data); keep {
+			require.NoError(t, writeEntry(w, header.Name, data, testTime))
+		}
+	}
+	for _, name := range extra {
+		require.NoError(t, writeEntry(w, name, []byte("{}"), testTime))
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, outGz.Close())
+	return out.Bytes()
+}
+
+func TestVerifyDetectsCorruption(t *testing.T) {
+	files := dataFiles(t)
+	var archive bytes.Buffer
+	_, err := Write(&archive, files, testTime)
+	require.NoError(t, err)
+
+	keepAll := func(name string, data []byte) ([]byte, bool) { return data, true }
+	tests := []struct {
+		name  string
+		edit  func(name string, data []byte) ([]byte, bool)
+		extra []string
+	}{
+		{"altered file", func(name string, data []byte) ([]byte, bool) {
+			if name == "votes.json" {
+				return []byte(`{"user_1":9}`), true
+			}
+			return data, true
+		}, nil},
+		{"missing file", func(name string, data []byte) ([]byte, bool) { return data, name != "prefs.json" }, nil},
+		{"missing manifest", func(name string, data []byte) ([]byte, bool) { return data, name != manifestName }, nil},
+		{"extra file", keepAll, []string{"other.json"}},
+		{"duplicate file", keepAll, []string{"votes.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(bytes.NewReader(rewrite(t, archive.Bytes(), tt.edit, tt.extra...)))
+			assert.ErrorIs(t, err, ErrCorrupt)
+		})
+	}
+
+	_, err = Verify(bytes.NewReader([]byte("not an archive")))
+	assert.ErrorIs(t, err, ErrCorrupt)
+}
+
+func TestRestoreWritesNothingFromCorruptArchive(t *testing.T) {
+	files := dataFiles(t)
+	var archive bytes.Buffer
+	_, err := Write(&archive, files, testTime)
+	require.NoError(t, err)
+
+	corrupt := rewrite(t, archive.Bytes(), func(name string, data []byte) ([]byte, bool) {
+		if name == "prefs.json" {
+			return []byte(`{"user_1":"light"}`), true
+		}
+		return data, true
+	})
+	require.NoError(t, os.WriteFile(files[0].Path, []byte(`{}`), 0o644))
+
+	_, err = Restore(bytes.NewReader(corrupt), files)
+	assert.ErrorIs(t, err, ErrCorrupt)
+	votes, err := os.ReadFile(files[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(votes))
+}
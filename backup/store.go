@@ -0,0 +1,257 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultKeep is how many backups a store keeps before removing the oldest
+const DefaultKeep = 7
+
+// Archive names are the UTC time they were taken, so sorting them sorts them by age
+const (
+	archivePrefix = "backup-"
+	archiveSuffix = ".tar.gz"
+	archiveTime   = "20060102T150405Z"
+)
+
+// pendingName marks a backup to restore on the next start, holding its name
+const pendingName = "restore-pending"
+
+// ErrNotFound is returned for a backup name that isn't in the store
+var ErrNotFound = errors.New("backup not found")
+
+// Info describes a backup in a store
+type Info struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Store keeps backups in a directory, removing the oldest beyond its limit
+type Store struct {
+	dir  string
+	keep int
+}
+
+// NewStore creates a store in dir keeping up to keep backups, DefaultKeep when keep isn't positive
+func NewStore(dir string, keep int) *Store {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	return &Store{dir: dir, keep: keep}
+}
+
+// Dir returns the directory backups are kept in
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Create backs up files into a new archive, verifies it reads back intact, then prunes the oldest
+// backups. A backup that fails verification is removed rather than kept.
+func (s *Store) Create(files []File, now time.Time) (Info, Manifest, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return Info{}, Manifest{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	name := archivePrefix + now.UTC().Format(archiveTime) + archiveSuffix
+	path := filepath.Join(s.dir, name)
+	tmp := path + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return Info{}, Manifest{}, fmt.Errorf("failed to create backup: %w", err)
+	}
+	manifest, err := Write(out, files, now)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return Info{}, Manifest{}, err
+	}
+
+	if _, err := s.verifyPath(tmp); err != nil {
+		os.Remove(tmp)
+		return Info{}, Manifest{}, fmt.Errorf("new backup failed verification: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return Info{}, Manifest{}, fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	info, err := s.info(name)
+	if err != nil {
+		return Info{}, Manifest{}, err
+	}
+	s.prune()
+	return info, manifest, nil
+}
+
+// List returns the store's backups, newest first
+func (s *Store) List() ([]Info, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []Info
+	for _, entry := range entries {
+		if entry.IsDir() || !validName(entry.Name()) {
+			continue
+		}
+		info, err := s.info(entry.Name())
+		if err != nil {
+			continue
+		}
+		backups = append(backups, info)
+	}
+	slices.SortFunc(backups, func(a, b Info) int { return strings.Compare(b.Name, a.Name) })
+	return backups, nil
+}
+
+// Latest returns the newest backup's name, or ErrNotFound when there are none
+func (s *Store) Latest() (string, error) {
+	backups, err := s.List()
+	if err != nil {
+		return "", err
+	}
+	if len(backups) == 0 {
+		return "", ErrNotFound
+	}
+	return backups[0].Name, nil
+}
+
+// Verify checks a backup against its manifest
+func (s *Store) Verify(name string) (Manifest, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return Manifest{}, err
+	}
+	return s.verifyPath(path)
+}
+
+// StageRestore verifies a backup and marks it to be restored the next time the bot starts, since the
+// running bot would save over restored files
+func (s *Store) StageRestore(name string) (Manifest, error) {
+	manifest, err := s.Verify(name)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err := utils.WriteFileAtomic(filepath.Join(s.dir, pendingName), []byte(name+"\n"), 0o644); err != nil {
+		return Manifest{}, fmt.Errorf("failed to stage restore: %w", err)
+	}
+	return manifest, nil
+}
+
+// Staged returns the backup marked to be restored, if any
+func (s *Store) Staged() (string, bool) {
+	data, err := os.ReadFile(filepath.Join(s.dir, pendingName))
+	if err != nil {
+		return "", false
+	}
+	name := strings.TrimSpace(string(data))
+	return name, name != ""
+}
+
+// CancelStaged forgets a staged restore
+func (s *Store) CancelStaged() error {
+	err := os.Remove(filepath.Join(s.dir, pendingName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// ApplyStaged restores the staged backup over files, before the bot loads them. It returns the restored
+// backup's name, empty when none was staged. The mark is cleared even when the restore fails so a bad
+// backup can't stop every start.
+func (s *Store) ApplyStaged(files []File) (string, Manifest, error) {
+	name, ok := s.Staged()
+	if !ok {
+		return "", Manifest{}, nil
+	}
+	defer s.CancelStaged()
+
+	path, err := s.path(name)
+	if err != nil {
+		return name, Manifest{}, err
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		return name, Manifest{}, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer in.Close()
+
+	manifest, err := Restore(in, files)
+	return name, manifest, err
+}
+
+// verifyPath checks the archive at path
+func (s *Store) verifyPath(path string) (Manifest, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer in.Close()
+	return Verify(in)
+}
+
+// path returns where a backup is, refusing names that aren't the store's backups
+func (s *Store) path(name string) (string, error) {
+	if !validName(name) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return path, nil
+}
+
+// info describes a backup from its file
+func (s *Store) info(name string) (Info, error) {
+	stat, err := os.Stat(filepath.Join(s.dir, name))
+	if err != nil {
+		return Info{}, err
+	}
+	created, _ := time.Parse(archiveTime, strings.TrimSuffix(strings.TrimPrefix(name, archivePrefix), archiveSuffix))
+	return Info{Name: name, Size: stat.Size(), CreatedAt: created}, nil
+}
+
+// prune removes the oldest backups beyond the store's limit, never the one staged for restore
+func (s *Store) prune() {
+	backups, err := s.List()
+	if err != nil || len(backups) <= s.keep {
+		return
+	}
+	staged, _ := s.Staged()
+	for _, old := range backups[s.keep:] {
+		if old.Name != staged {
+			os.Remove(filepath.Join(s.dir, old.Name))
+		}
+	}
+}
+
+// validName reports whether name is a backup archive's name, which also keeps paths inside the store
+func validName(name string) bool {
+	stamp, ok := strings.CutPrefix(name, archivePrefix)
+	if !ok {
+		return false
+	}
+	stamp, ok = strings.CutSuffix(stamp, archiveSuffix)
+	if !ok {
+		return false
+	}
+	_, err := time.Parse(archiveTime, stamp)
+	return err == nil
+}
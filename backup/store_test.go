@@ -0,0 +1,98 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreCreateListAndPrune(t *testing.T) {
+	files := dataFiles(t)
+	store := NewStore(filepath.Join(t.TempDir(), "backups"), 2)
+
+	names := []string{"backup-20250601T030405Z.tar.gz", "backup-20250601T040405Z.tar.gz", "backup-20250601T050405Z.tar.gz"}
+	for n, want := range names {
+		taken := testTime.Add(time.Duration(n) * time.Hour)
+		info, manifest, err := store.Create(files, taken)
+		require.NoError(t, err)
+		assert.Equal(t, want, info.Name)
+		assert.True(t, info.CreatedAt.Equal(taken))
+		assert.Len(t, manifest.Files, 2)
+	}
+
+	backups, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, names[2], backups[0].Name)
+	assert.Equal(t, names[1], backups[1].Name)
+
+	latest, err := store.Latest()
+	require.NoError(t, err)
+	assert.Equal(t, backups[0].Name, latest)
+
+	_, err = store.Verify(latest)
+	assert.NoError(t, err)
+}
+
+func TestStoreRefusesUnknownNames(t *testing.T) {
+	store := NewStore(t.TempDir(), 0)
+
+	_, err := store.Latest()
+	assert.ErrorIs(t, err, ErrNotFound)
+	for _, name := range []string{"../votes.json", "backup-20250601T030405Z.tar.gz", "restore-pending"} {
+		_, err := store.Verify(name)
+		assert.ErrorIs(t, err, ErrNotFound, name)
+		_, err = store.StageRestore(name)
+		assert.ErrorIs(t, err, ErrNotFound, name)
+	}
+}
+
+func TestStoreStagedRestoreAppliesOnce(t *testing.T) {
+	files := dataFiles(t)
+	store := NewStore(t.TempDir(), 0)
+
+	info, _, err := store.Create(files, testTime)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(files[0].Path, []byte(`{"user_2":1}`), 0o644))
+
+	name, _, err := store.ApplyStaged(files)
+	require.NoError(t, err)
+	assert.Empty(t, name, "nothing is restored until staged")
+
+	_, err = store.StageRestore(info.Name)
+	require.NoError(t, err)
+	staged, ok := store.Staged()
+	require.True(t, ok)
+	assert.Equal(t, info.Name, staged)
+
+	name, manifest, err := store.ApplyStaged(files)
+	require.NoError(t, err)
+	assert.Equal(t, info.Name, name)
+	assert.Len(t, manifest.Files, 2)
+	votes, err := os.ReadFile(files[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user_1":1}`, string(votes))
+
+	_, ok = store.Staged()
+	assert.False(t, ok, "a restore is applied once")
+}
+
+func TestStoreStagedRestoreOfCorruptBackupIsCleared(t *testing.T) {
+	files := dataFiles(t)
+	store := NewStore(t.TempDir(), 0)
+
+	info, _, err := store.Create(files, testTime)
+	require.NoError(t, err)
+	_, err = store.StageRestore(info.Name)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(store.Dir(), info.Name), []byte("damaged"), 0o644))
+
+	_, _, err = store.ApplyStaged(files)
+	assert.ErrorIs(t, err, ErrCorrupt)
+	_, ok := store.Staged()
+	assert.False(t, ok)
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/backup"
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/scheduler"
+	"pxnx-discord-bot/utils"
+)
+
+// defaultBackupSchedule is how often backups are taken unless BACKUP_SCHEDULE says otherwise
+const defaultBackupSchedule = "@daily"
+
+// backupFiles are the bot's data files. The yt-dlp cache is left out, it refills by itself.
+func backupFiles() []backup.File {
+	return []backup.File{
+		{Name: "votes.json", Path: commands.VotesPath()},
+		{Name: "preferences.json", Path: commands.PreferencesPath()},
+		{Name: "premium.json", Path: commands.PremiumPath()},
+		{Name: "music-settings.json", Path: commands.MusicSettingsPath()},
+		{Name: "ai-personas.json", Path: commands.AIPersonasPath()},
+		{Name: "scheduler.json", Path: SchedulerPath()},
+		{Name: "usage.json", Path: commands.UsagePath()},
+	}
+}
+
+// scheduleBackups backs up the data files on the BACKUP_SCHEDULE, daily by default, when BACKUP_DIR is
+// set
+func (b *Bot) scheduleBackups(jobs *scheduler.Scheduler) {
+	if commands.Backups == nil {
+		return
+	}
+	spec := strings.TrimSpace(os.Getenv("BACKUP_SCHEDULE"))
+	if spec == "" {
+		spec = defaultBackupSchedule
+	}
+
+	jobs.Handle(backupJob, func(ctx context.Context, job scheduler.Job) error {
+		info, _, err := commands.RunBackup()
+		if err != nil {
+			return err
+		}
+		utils.LogInfo("Created backup %s", info.Name)
+		return nil
+	})
+	b.addJob(jobs, scheduler.Job{
+		ID:      backupJob,
+		Kind:    backupJob,
+		Spec:    spec,
+		Jitter:  10 * time.Minute,
+		CatchUp: true,
+	})
+}
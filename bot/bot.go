@@ -3,15 +3,34 @@ package bot
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 
 	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/metrics"
+	"pxnx-discord-bot/scheduler"
+	"pxnx-discord-bot/utils"
 )
 
 // Bot represents the Discord bot instance
 type Bot struct {
-	Session *discordgo.Session
+	Session      *discordgo.Session   // Session of the first shard this process runs
+	Shards       []*discordgo.Session // Sessions of every shard this process runs, Session first
+	IntentConfig IntentConfig         // Features that decide the requested gateway intents, applied in Setup
+
+	shardCount          int // Shards of the whole bot, across processes
+	identifyConcurrency int // Shards that may identify at once
+	readyShards         shardReadiness
+
+	guildResources     guildResources
+	unavailableGuilds  unavailableGuilds
+	scheduler          *scheduler.Scheduler // Runs periodic maintenance, nil until Start
+	stopLogChannel     func()               // Stops forwarding errors to the log channel, nil when not forwarding
+	stopStatsPage      func()               // Shuts down the public stats page, nil when it isn't served
+	stopMetrics        func()               // Shuts down the Prometheus metrics endpoint, nil when it isn't served
+	stopVoteWebhook    func()               // Shuts down the vote webhook, nil when it isn't served
+	stopSpeakingEvents func()               // Shuts down the speaking event stream, nil when it isn't served
 }
 
 // New creates a new bot instance
@@ -21,42 +40,147 @@ func New(token string) (*Bot, error) {
 		return nil, fmt.Errorf("error creating Discord session: %w", err)
 	}
 
-	return &Bot{Session: dg}, nil
+	b := &Bot{Session: dg, IntentConfig: LoadIntentConfig()}
+	if err := b.newShards(token, LoadShardConfig()); err != nil {
+		return nil, err
+	}
+	return b, nil
 }
 
 // Setup configures the bot with handlers and intents
 func (b *Bot) Setup() {
-	b.Session.AddHandler(b.ready)
-	b.Session.AddHandler(b.interactionCreate)
-	b.Session.AddHandler(b.voiceStateUpdate)
-	b.Session.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsGuildEmojis | discordgo.IntentsGuildVoiceStates
+	b.addHandler(b.ready)
+	b.addHandler(b.interactionCreate)
+	b.addHandler(b.guildCreate)
+	b.addHandler(b.guildDelete)
+	b.addHandler(b.gatewayEvent)
+	for _, session := range b.Shards {
+		session.Identify.Intents = b.IntentConfig.Intents()
+	}
+	commands.ShardReporter = b.shardReport
+	commands.MessageContent = b.IntentConfig.MessageContent
+	// A staged restore is applied before the stores below read their files
+	commands.InitializeBackups(backupFiles())
+	commands.InitializeStorage()
+	commands.InitializeVotes()
+	commands.InitializePreferences()
+	commands.InitializeGuildSettings()
+	commands.InitializeTheme()
+	commands.InitializeLLM()
+	commands.InitializeTranscriber()
+	commands.InitializeSpeech()
+	if commands.LLM != nil {
+		b.addHandler(b.messageCreate)
+	}
+
+	if b.IntentConfig.Music {
+		b.addHandler(b.voiceStateUpdate)
+		b.addHandler(b.voiceServerUpdate)
+		b.addHandler(b.resumed)
+		b.addHandler(b.entitlementCreate)
+		b.addHandler(b.entitlementUpdate)
+		b.addHandler(b.entitlementDelete)
 
-	// Initialize the simplified music player
-	commands.InitializeSimplePlayer(b.Session)
+		// Initialize the simplified music player
+		commands.InitializeSimplePlayer(b.Shards, b.sessionFor)
+		b.RegisterGuildResource("music player", commands.SimplePlayer.GuildIDs, commands.SimplePlayer.CleanupGuild)
+		b.RegisterGuildResource("now playing message", commands.NowPlaying.GuildIDs, commands.NowPlaying.Forget)
+	}
 }
 
 // Start opens the Discord connection
 func (b *Bot) Start() error {
-	return b.Session.Open()
+	b.validatePrivilegedIntents()
+	b.loadBotOwners()
+	if err := b.openShards(); err != nil {
+		return err
+	}
+
+	b.startLogChannel()
+	b.startStatsPage()
+	b.startMetrics()
+	b.startVoteWebhook()
+	b.startSpeakingEvents()
+
+	b.startScheduler()
+	return nil
 }
 
 // Stop closes the Discord connection
 func (b *Bot) Stop() error {
-	return b.Session.Close()
+	if b.scheduler != nil {
+		b.scheduler.Stop()
+		b.scheduler = nil
+	}
+	if b.stopLogChannel != nil {
+		b.stopLogChannel()
+		b.stopLogChannel = nil
+	}
+	if b.stopStatsPage != nil {
+		b.stopStatsPage()
+		b.stopStatsPage = nil
+	}
+	if b.stopMetrics != nil {
+		b.stopMetrics()
+		b.stopMetrics = nil
+	}
+	if b.stopVoteWebhook != nil {
+		b.stopVoteWebhook()
+		b.stopVoteWebhook = nil
+	}
+	if b.stopSpeakingEvents != nil {
+		b.stopSpeakingEvents()
+		b.stopSpeakingEvents = nil
+	}
+	commands.SaveUsage()
+	return b.closeShards()
 }
 
-// ready handles the ready event
+// ready handles the ready event of each shard
 func (b *Bot) ready(s *discordgo.Session, event *discordgo.Ready) {
-	fmt.Printf("Logged in as: %v#%v\n", s.State.User.Username, s.State.User.Discriminator)
+	if s.ShardCount > 1 {
+		fmt.Printf("Shard %d/%d logged in as: %v#%v\n", s.ShardID, s.ShardCount, s.State.User.Username, s.State.User.Discriminator)
+	} else {
+		fmt.Printf("Logged in as: %v#%v\n", s.State.User.Username, s.State.User.Discriminator)
+	}
 
-	if shouldRegisterCommands {
-		if err := RegisterCommands(s); err != nil {
-			log.Printf("Error registering commands: %v", err)
-			return
+	// Commands are registered once for all shards, by the first
+	if s.ShardID == 0 {
+		if shouldRegisterCommands {
+			if err := RegisterCommands(s, commandRegistration); err != nil {
+				log.Printf("Error registering commands: %v", err)
+				return
+			}
+			fmt.Println("Command registration complete. Bot is ready!")
+		} else {
+			fmt.Println("Bot is ready! (Use --register-commands flag to register slash commands)")
 		}
-		fmt.Println("Command registration complete. Bot is ready!")
-	} else {
-		fmt.Println("Bot is ready! (Use --register-commands flag to register slash commands)")
+	}
+
+	// A new session starts without voice connections, after a restart or a reconnect that couldn't resume.
+	// Subscriptions load first so premium servers in 24/7 mode are rejoined, once every shard of the process
+	// can join voice.
+	if commands.SimplePlayer != nil && b.readyShards.markReady(s.ShardID, len(b.Shards)) {
+		commands.SimplePlayer.ConnectBackend(s.State.User.ID)
+		commands.Presence.Reset()
+		utils.SafeGo("music.restore", func() {
+			loadEntitlements(s, s.State.User.ID)
+			commands.SimplePlayer.RejoinStayConnected()
+		})
+	}
+}
+
+// resumed handles gateway reconnects that resumed the previous session
+func (b *Bot) resumed(s *discordgo.Session, event *discordgo.Resumed) {
+	if commands.SimplePlayer != nil {
+		utils.SafeGo("music.rejoinStayConnected", commands.SimplePlayer.RejoinStayConnected)
+	}
+}
+
+// messageCreate answers messages that mention the bot with the language model
+func (b *Bot) messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if err := commands.HandleMention(&SimpleSessionWrapper{session: s}, m, s.State.User.ID); err != nil {
+		utils.LogError("Error answering mention in channel %s: %v", m.ChannelID, err)
 	}
 }
 
@@ -65,34 +189,24 @@ func (b *Bot) interactionCreate(s *discordgo.Session, i *discordgo.InteractionCr
 	// Create a simple session interface for compatibility
 	sessionInterface := &SimpleSessionWrapper{session: s}
 
-	var err error
-	switch i.ApplicationCommandData().Name {
-	case "ping":
-		err = commands.HandlePingCommand(sessionInterface, i)
-	case "peepee":
-		err = commands.HandlePeepeeCommandWithReaction(s, i)
-	case "8ball":
-		err = commands.Handle8BallCommand(sessionInterface, i)
-	case "coinflip":
-		err = commands.HandleCoinFlipCommand(sessionInterface, i)
-	case "server":
-		err = commands.HandleServerCommand(sessionInterface, i)
-	case "user":
-		err = commands.HandleUserCommand(sessionInterface, i)
-	case "weather":
-		err = commands.HandleWeatherCommand(sessionInterface, i)
-	case "roll":
-		err = commands.HandleRollCommand(sessionInterface, i)
-	case "join":
-		err = commands.HandleJoinCommand(sessionInterface, i)
-	case "leave":
-		err = commands.HandleLeaveCommand(sessionInterface, i)
-	case "play":
-		err = commands.HandlePlayCommand(sessionInterface, i)
+	if i.Type == discordgo.InteractionMessageComponent {
+		b.componentInteraction(sessionInterface, i)
+		return
 	}
 
+	// Errors are logged by the registry's middleware
+	_ = HandleCommand(sessionInterface, i)
+}
+
+// componentInteraction handles button and select menu interactions through the component handler registry
+func (b *Bot) componentInteraction(s commands.SessionInterface, i *discordgo.InteractionCreate) {
+	start := time.Now()
+	endTrace := commands.TraceInteraction(i, "component "+commands.ComponentHandlerName(i.MessageComponentData().CustomID))
+	err := commands.HandleComponentInteraction(s, i)
+	endTrace(metrics.OutcomeOf(err), err)
+	observeInteraction("component:"+commands.ComponentHandlerName(i.MessageComponentData().CustomID), metrics.OutcomeOf(err), start)
 	if err != nil {
-		log.Printf("Error handling command '%s': %v", i.ApplicationCommandData().Name, err)
+		utils.LogErrorContext(commands.RequestContext(i), "Error handling component '%s': %v", i.MessageComponentData().CustomID, err)
 	}
 }
 
@@ -121,6 +235,18 @@ func (s *SimpleSessionWrapper) Channel(channelID string, options ...discordgo.Re
 	return s.session.Channel(channelID, options...)
 }
 
+func (s *SimpleSessionWrapper) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.session.ChannelMessageSendComplex(channelID, data, options...)
+}
+
+func (s *SimpleSessionWrapper) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.session.ChannelMessageEditComplex(m, options...)
+}
+
+func (s *SimpleSessionWrapper) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	return s.session.ChannelMessages(channelID, limit, beforeID, afterID, aroundID, options...)
+}
+
 func (s *SimpleSessionWrapper) State() *discordgo.State {
 	return s.session.State
 }
@@ -132,10 +258,28 @@ func (b *Bot) voiceStateUpdate(s *discordgo.Session, vsu *discordgo.VoiceStateUp
 		return
 	}
 
+	// Moderators can move the bot to another channel
+	if s.State != nil && s.State.User != nil && vsu.UserID == s.State.User.ID {
+		commands.SimplePlayer.HandleBotVoiceState(vsu.GuildID, vsu.ChannelID, vsu.SessionID)
+	}
+
+	// Members who leave or switch channels stop talking in the bot's channel
+	if vsu.BeforeUpdate != nil && vsu.BeforeUpdate.ChannelID != vsu.ChannelID {
+		commands.SimplePlayer.StopSpeaking(vsu.GuildID, vsu.UserID)
+	}
+
 	// Handle auto-disconnect when channel becomes empty
 	commands.SimplePlayer.HandleVoiceStateUpdate(vsu.GuildID)
 }
 
+// voiceServerUpdate resumes playback when Discord moves a voice connection to another voice server
+func (b *Bot) voiceServerUpdate(s *discordgo.Session, vsu *discordgo.VoiceServerUpdate) {
+	if commands.SimplePlayer == nil {
+		return
+	}
+	commands.SimplePlayer.HandleVoiceServerUpdate(vsu.GuildID, vsu.Token, vsu.Endpoint)
+}
+
 // Global flag for command registration (will be set from main)
 var shouldRegisterCommands bool
 
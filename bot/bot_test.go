@@ -63,7 +63,7 @@ func TestBotSetup(t *testing.T) {
 	bot.Setup()
 
 	// Check intents (includes voice states for music functionality)
-	expectedIntents := discordgo.IntentsGuildMessages | discordgo.IntentsGuildEmojis | discordgo.IntentsGuildVoiceStates
+	expectedIntents := discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildEmojis | discordgo.IntentsGuildVoiceStates
 	if bot.Session.Identify.Intents != expectedIntents {
 		t.Errorf("Expected intents %d, got %d", expectedIntents, bot.Session.Identify.Intents)
 	}
@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
+)
+
+// CommandRegistration is where --register-commands registers the bot's commands
+type CommandRegistration struct {
+	Guilds []string // Guilds to register in, which takes effect instantly; none registers globally
+	Prune  bool     // Delete registered commands the bot no longer defines
+}
+
+// commandRegistration is set from main along with shouldRegisterCommands
+var commandRegistration = CommandRegistration{Prune: true}
+
+// SetCommandRegistration sets where --register-commands registers the bot's commands
+func SetCommandRegistration(registration CommandRegistration) {
+	commandRegistration = registration
+}
+
+// commandAPI is the part of discordgo.Session that registers commands, replaced in tests
+type commandAPI interface {
+	ApplicationCommands(appID, guildID string, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error)
+	ApplicationCommandCreate(appID, guildID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error)
+	ApplicationCommandEdit(appID, guildID, cmdID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error)
+	ApplicationCommandDelete(appID, guildID, cmdID string, options ...discordgo.RequestOption) error
+}
+
+// commandSync counts what syncing the commands of one scope changed
+type commandSync struct {
+	Created, Updated, Unchanged, Deleted int
+}
+
+func (c commandSync) String() string {
+	return fmt.Sprintf("%d created, %d updated, %d unchanged, %d deleted", c.Created, c.Updated, c.Unchanged, c.Deleted)
+}
+
+// RegisterCommands registers the bot's commands with Discord, globally or in the guilds of registration.
+// Only new and changed commands are sent; with Prune, commands the bot no longer defines are deleted, and
+// registering globally also deletes the guild commands of earlier guild registrations.
+func RegisterCommands(s *discordgo.Session, registration CommandRegistration) error {
+	appID := s.State.User.ID
+	defined := GetCommands()
+
+	if len(registration.Guilds) > 0 {
+		for _, guildID := range registration.Guilds {
+			result, err := syncCommands(s, appID, guildID, defined, registration.Prune)
+			if err != nil {
+				return fmt.Errorf("guild %s: %w", guildID, err)
+			}
+			utils.LogInfo("Registered %d commands in guild %s: %s", len(defined), guildID, result)
+		}
+		return nil
+	}
+
+	result, err := syncCommands(s, appID, "", defined, registration.Prune)
+	if err != nil {
+		return err
+	}
+	utils.LogInfo("Registered %d global commands: %s (changes can take up to an hour to reach every server)", len(defined), result)
+
+	// Guild commands left from testing would show up next to the global ones
+	if registration.Prune {
+		for _, guild := range s.State.Guilds {
+			result, err := syncCommands(s, appID, guild.ID, nil, true)
+			if err != nil {
+				utils.LogWarn("Failed to delete the commands of guild %s: %v", guild.ID, err)
+				continue
+			}
+			if result.Deleted > 0 {
+				utils.LogInfo("Deleted %d guild commands from guild %s", result.Deleted, guild.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// syncCommands makes the commands registered in a scope, a guild or "" for global, match defined: missing
+// commands are created, changed ones edited and, with prune, ones no longer defined deleted
+func syncCommands(api commandAPI, appID, guildID string, defined []*discordgo.ApplicationCommand, prune bool) (commandSync, error) {
+	var result commandSync
+	registered, err := api.ApplicationCommands(appID, guildID)
+	if err != nil {
+		return result, fmt.Errorf("cannot retrieve registered commands: %w", err)
+	}
+
+	// Commands are told apart by type and name, a slash command and a message command may share a name
+	existing := make(map[string]*discordgo.ApplicationCommand, len(registered))
+	for _, cmd := range registered {
+		existing[commandKey(cmd)] = cmd
+	}
+
+	for _, cmd := range defined {
+		current, found := existing[commandKey(cmd)]
+		delete(existing, commandKey(cmd))
+		switch {
+		case !found:
+			utils.LogInfo("Creating command %s", cmd.Name)
+			if _, err := api.ApplicationCommandCreate(appID, guildID, cmd); err != nil {
+				return result, fmt.Errorf("cannot create '%v' command: %w", cmd.Name, err)
+			}
+			result.Created++
+		case !sameCommand(cmd, current):
+			utils.LogInfo("Updating command %s", cmd.Name)
+			if _, err := api.ApplicationCommandEdit(appID, guildID, current.ID, cmd); err != nil {
+				return result, fmt.Errorf("cannot update '%v' command: %w", cmd.Name, err)
+			}
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+	}
+
+	if !prune {
+		return result, nil
+	}
+	for _, cmd := range registered {
+		if _, stale := existing[commandKey(cmd)]; !stale {
+			continue
+		}
+		utils.LogInfo("Deleting stale command %s", cmd.Name)
+		if err := api.ApplicationCommandDelete(appID, guildID, cmd.ID); err != nil {
+			return result, fmt.Errorf("cannot delete stale '%v' command: %w", cmd.Name, err)
+		}
+		result.Deleted++
+	}
+	return result, nil
+}
+
+// commandKey identifies a command within its scope
+func commandKey(cmd *discordgo.ApplicationCommand) string {
+	kind := cmd.Type
+	if kind == 0 {
+		kind = discordgo.ChatApplicationCommand
+	}
+	return fmt.Sprintf("%d/%s", kind, cmd.Name)
+}
+
+// commandShape is what a command definition sets, without the IDs and version Discord adds
+type commandShape struct {
+	Name                     string                                  `json:"name"`
+	NameLocalizations        map[discordgo.Locale]string             `json:"name_localizations,omitempty"`
+	Description              string                                  `json:"description,omitempty"`
+	DescriptionLocalizations map[discordgo.Locale]string             `json:"description_localizations,omitempty"`
+	Options                  []*discordgo.ApplicationCommandOption   `json:"options,omitempty"`
+	DefaultMemberPermissions *int64                                  `json:"default_member_permissions,omitempty"`
+	NSFW                     bool                                    `json:"nsfw,omitempty"`
+	DMPermission             *bool                                   `json:"dm_permission,omitempty"`
+	Contexts                 *[]discordgo.InteractionContextType     `json:"contexts,omitempty"`
+	IntegrationTypes         *[]discordgo.ApplicationIntegrationType `json:"integration_types,omitempty"`
+}
+
+// sameCommand reports whether a registered command matches its definition. Settings the definition leaves
+// out get Discord's defaults, so they are only compared when the definition sets them.
+func sameCommand(defined, registered *discordgo.ApplicationCommand) bool {
+	want, got := shapeOf(defined), shapeOf(registered)
+	if defined.DMPermission == nil {
+		got.DMPermission = nil
+	}
+	if defined.Contexts == nil {
+		got.Contexts = nil
+	}
+	if defined.IntegrationTypes == nil {
+		got.IntegrationTypes = nil
+	}
+
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(wantJSON, gotJSON)
+}
+
+// shapeOf takes the settings of a command that sameCommand compares
+func shapeOf(cmd *discordgo.ApplicationCommand) commandShape {
+	shape := commandShape{
+		Name:                     cmd.Name,
+		Description:              cmd.Description,
+		Options:                  cmd.Options,
+		DefaultMemberPermissions: cmd.DefaultMemberPermissions,
+		DMPermission:             cmd.DMPermission,
+		Contexts:                 cmd.Contexts,
+		IntegrationTypes:         cmd.IntegrationTypes,
+	}
+	if cmd.NameLocalizations != nil && len(*cmd.NameLocalizations) > 0 {
+		shape.NameLocalizations = *cmd.NameLocalizations
+	}
+	if cmd.DescriptionLocalizations != nil && len(*cmd.DescriptionLocalizations) > 0 {
+		shape.DescriptionLocalizations = *cmd.DescriptionLocalizations
+	}
+	if cmd.NSFW != nil {
+		shape.NSFW = *cmd.NSFW
+	}
+	return shape
+}
@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeCommandAPI keeps registered commands in memory and records the calls that changed them
+type fakeCommandAPI struct {
+	registered []*discordgo.ApplicationCommand
+	calls      []string
+}
+
+func (f *fakeCommandAPI) ApplicationCommands(appID, guildID string, options ...discordgo.RequestOption) ([]*discordgo.ApplicationCommand, error) {
+	return f.registered, nil
+}
+
+func (f *fakeCommandAPI) ApplicationCommandCreate(appID, guildID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error) {
+	f.calls = append(f.calls, "create "+cmd.Name)
+	return cmd, nil
+}
+
+func (f *fakeCommandAPI) ApplicationCommandEdit(appID, guildID, cmdID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error) {
+	f.calls = append(f.calls, "edit "+cmdID)
+	return cmd, nil
+}
+
+func (f *fakeCommandAPI) ApplicationCommandDelete(appID, guildID, cmdID string, options ...discordgo.RequestOption) error {
+	f.calls = append(f.calls, "delete "+cmdID)
+	return nil
+}
+
+// asRegistered round-trips a definition through JSON the way Discord returns it, with an ID and defaults
+func asRegistered(t *testing.T, id string, cmd *discordgo.ApplicationCommand) *discordgo.ApplicationCommand {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		t.Fatalf("Failed to encode command: %v", err)
+	}
+	var registered discordgo.ApplicationCommand
+	if err := json.Unmarshal(data, &registered); err != nil {
+		t.Fatalf("Failed to decode command: %v", err)
+	}
+	dm := true
+	registered.ID, registered.Version, registered.DMPermission = id, "1", &dm
+	if registered.Type == 0 {
+		registered.Type = discordgo.ChatApplicationCommand
+	}
+	return &registered
+}
+
+func TestSyncCommands(t *testing.T) {
+	defined := []*discordgo.ApplicationCommand{
+		{Name: "ping", Description: "Check if the bot is alive"},
+		{Name: "roll", Description: "Roll a die", Options: []*discordgo.ApplicationCommandOption{createIntegerOption("sides", "Sides", false, nil, nil)}},
+		{Name: "weather", Description: "Show the weather"},
+	}
+	changed := *defined[1]
+	changed.Description = "Roll dice"
+	api := &fakeCommandAPI{registered: []*discordgo.ApplicationCommand{
+		asRegistered(t, "1", defined[0]),
+		asRegistered(t, "2", &changed),
+		asRegistered(t, "3", &discordgo.ApplicationCommand{Name: "old", Description: "Removed command"}),
+	}}
+
+	result, err := syncCommands(api, "app", "guild", defined, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := commandSync{Created: 1, Updated: 1, Unchanged: 1, Deleted: 1}
+	if result != want {
+		t.Errorf("Expected %s, got %s", want, result)
+	}
+	sort.Strings(api.calls)
+	if !reflect.DeepEqual(api.calls, []string{"create weather", "delete 3", "edit 2"}) {
+		t.Errorf("Unexpected calls %v", api.calls)
+	}
+
+	// Without pruning, stale commands stay
+	api.calls = nil
+	if result, _ := syncCommands(api, "app", "guild", defined, false); result.Deleted != 0 {
+		t.Errorf("Expected nothing deleted without pruning, got %s", result)
+	}
+	for _, call := range api.calls {
+		if call == "delete 3" {
+			t.Error("Expected the stale command to stay")
+		}
+	}
+}
+
+func TestSameCommand(t *testing.T) {
+	for _, cmd := range GetCommands() {
+		if !sameCommand(cmd, asRegistered(t, "1", cmd)) {
+			t.Errorf("Expected /%s to match its registered copy", cmd.Name)
+		}
+	}
+
+	cmd := &discordgo.ApplicationCommand{Name: "ping", Description: "Ping"}
+	registered := asRegistered(t, "1", cmd)
+	permissions := int64(discordgo.PermissionManageServer)
+	cmd.DefaultMemberPermissions = &permissions
+	if sameCommand(cmd, registered) {
+		t.Error("Expected a permission change to count")
+	}
+}
+
+func TestCommandKey(t *testing.T) {
+	slash := &discordgo.ApplicationCommand{Name: "transcribe"}
+	message := &discordgo.ApplicationCommand{Name: "transcribe", Type: discordgo.MessageApplicationCommand}
+	if commandKey(slash) == commandKey(message) {
+		t.Error("Expected commands of different types to be told apart")
+	}
+	if commandKey(slash) != commandKey(&discordgo.ApplicationCommand{Name: "transcribe", Type: discordgo.ChatApplicationCommand}) {
+		t.Error("Expected the default type to be a slash command")
+	}
+}
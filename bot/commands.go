@@ -2,8 +2,20 @@ package bot
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/i18n"
+	"pxnx-discord-bot/music/filters"
+	"pxnx-discord-bot/music/history"
+	"pxnx-discord-bot/music/playlist"
+	"pxnx-discord-bot/music/queue"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/services/llm"
 )
 
 // createStringOption creates a string application command option
@@ -16,6 +28,29 @@ func createStringOption(name, description string, required bool) *discordgo.Appl
 	}
 }
 
+// withLength limits how many characters a string option takes, Discord checks it as the command is typed
+func withLength(option *discordgo.ApplicationCommandOption, minLength, maxLength int) *discordgo.ApplicationCommandOption {
+	option.MinLength = &minLength
+	option.MaxLength = maxLength
+	return option
+}
+
+// withChannelTypes limits which kinds of channel a channel option takes
+func withChannelTypes(option *discordgo.ApplicationCommandOption, types ...discordgo.ChannelType) *discordgo.ApplicationCommandOption {
+	option.ChannelTypes = types
+	return option
+}
+
+// createAttachmentOption creates a file attachment application command option
+func createAttachmentOption(name, description string, required bool) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionAttachment,
+		Name:        name,
+		Description: description,
+		Required:    required,
+	}
+}
+
 // createUserOption creates a user application command option
 func createUserOption(name, description string, required bool) *discordgo.ApplicationCommandOption {
 	return &discordgo.ApplicationCommandOption{
@@ -26,6 +61,56 @@ func createUserOption(name, description string, required bool) *discordgo.Applic
 	}
 }
 
+// createChannelOption creates a channel application command option
+func createChannelOption(name, description string, required bool) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionChannel,
+		Name:        name,
+		Description: description,
+		Required:    required,
+	}
+}
+
+// createBooleanOption creates a boolean application command option
+func createBooleanOption(name, description string, required bool) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionBoolean,
+		Name:        name,
+		Description: description,
+		Required:    required,
+	}
+}
+
+// createRoleOption creates a role application command option
+func createRoleOption(name, description string, required bool) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionRole,
+		Name:        name,
+		Description: description,
+		Required:    required,
+	}
+}
+
+// createSubcommandOption creates a subcommand application command option
+func createSubcommandOption(name, description string, options ...*discordgo.ApplicationCommandOption) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommand,
+		Name:        name,
+		Description: description,
+		Options:     options,
+	}
+}
+
+// createSubcommandGroupOption creates a subcommand group application command option
+func createSubcommandGroupOption(name, description string, subcommands ...*discordgo.ApplicationCommandOption) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+		Name:        name,
+		Description: description,
+		Options:     subcommands,
+	}
+}
+
 // createIntegerOption creates an integer application command option
 func createIntegerOption(name, description string, required bool, minValue, maxValue *float64) *discordgo.ApplicationCommandOption {
 	option := &discordgo.ApplicationCommandOption{
@@ -45,6 +130,18 @@ func createIntegerOption(name, description string, required bool, minValue, maxV
 	return option
 }
 
+// createNumberOption creates a number application command option, which takes decimals
+func createNumberOption(name, description string, required bool, minValue, maxValue float64) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionNumber,
+		Name:        name,
+		Description: description,
+		Required:    required,
+		MinValue:    &minValue,
+		MaxValue:    maxValue,
+	}
+}
+
 // createStringChoiceOption creates a string application command option with choices
 func createStringChoiceOption(name, description string, required bool, choices []*discordgo.ApplicationCommandOptionChoice) *discordgo.ApplicationCommandOption {
 	return &discordgo.ApplicationCommandOption{
@@ -56,145 +153,520 @@ func createStringChoiceOption(name, description string, required bool, choices [
 	}
 }
 
-// GetCommands returns the list of application commands for the bot
-func GetCommands() []*discordgo.ApplicationCommand {
-	return []*discordgo.ApplicationCommand{
+// filterChoices lists the audio filter presets as command choices
+func filterChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(filters.Presets))
+	for _, preset := range filters.Presets {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  fmt.Sprintf("%s - %s", preset.Name, preset.Description),
+			Value: preset.Name,
+		})
+	}
+	return choices
+}
+
+// onOffChoices are the choices of mode options that turn a feature on or off
+func onOffChoices() []*discordgo.ApplicationCommandOptionChoice {
+	return []*discordgo.ApplicationCommandOptionChoice{
+		{Name: "On", Value: "on"},
+		{Name: "Off", Value: "off"},
+	}
+}
+
+// languageChoices lists the languages a server can choose, by name
+func languageChoices() []*discordgo.ApplicationCommandOptionChoice {
+	codes := slices.Sorted(maps.Keys(settings.Languages))
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(codes))
+	for n, code := range codes {
+		choices[n] = &discordgo.ApplicationCommandOptionChoice{Name: settings.Languages[code], Value: code}
+	}
+	return choices
+}
+
+// localizeCommands adds the translations in the i18n catalogs to the names and descriptions of commands
+// and their options, keyed by their path such as "command.settings language.description"
+func localizeCommands(list []Command) []Command {
+	for _, command := range list {
+		definition := command.Definition
+		key := "command." + definition.Name
+		if names := i18n.Localizations(key + ".name"); names != nil {
+			definition.NameLocalizations = &names
+		}
+		if descriptions := i18n.Localizations(key + ".description"); descriptions != nil {
+			definition.DescriptionLocalizations = &descriptions
+		}
+		localizeOptions(definition.Name, definition.Options)
+	}
+	return list
+}
+
+// localizeOptions adds the catalog translations to options, descending into subcommands
+func localizeOptions(path string, options []*discordgo.ApplicationCommandOption) {
+	for _, option := range options {
+		optionPath := path + " " + option.Name
+		if names := i18n.Localizations("command." + optionPath + ".name"); names != nil {
+			option.NameLocalizations = names
+		}
+		if descriptions := i18n.Localizations("command." + optionPath + ".description"); descriptions != nil {
+			option.DescriptionLocalizations = descriptions
+		}
+		localizeOptions(optionPath, option.Options)
+	}
+}
+
+// botCommands returns every slash command of the bot: its definition, its handler and how the registry runs it
+func botCommands() []Command {
+	// Shuffle seeds are limited to what Discord integer options can carry
+	minShuffleSeed := 0.0
+	maxShuffleSeed := float64(queue.MaxSeed)
+
+	minReplayEntry := 1.0
+	minQueuePosition := 1.0
+	maxReplayEntry := float64(history.DefaultSize)
+
+	minRepeatHours := 1.0
+	maxRepeatHours := history.MaxLookback.Hours()
+
+	minPremiumDays := 1.0
+	maxPremiumDays := 3650.0
+
+	minAloneSeconds := 5.0
+	maxAloneSeconds := 3600.0
+	minIdleMinutes := 0.0
+	maxIdleMinutes := 1440.0
+
+	minVolume := 1.0
+	maxVolume := float64(settings.MaxVolume)
+
+	minSummaryMessages := 10.0
+	maxSummaryMessages := float64(commands.MaxSummaryMessages)
+
+	// /play is also /music play, so both are defined with the same options
+	playOptions := []*discordgo.ApplicationCommandOption{
+		withLength(createStringOption("query", "YouTube URL, playlist URL, link to an audio file or search query", false), 1, 500),
+		createAttachmentOption("file", "Audio file to play (mp3, ogg, opus, wav, flac or m4a)", false),
+		createIntegerOption("limit", fmt.Sprintf("Maximum tracks to queue from a playlist (default %d)", playlist.DefaultLimit), false, func() *float64 { v := float64(1); return &v }(), func() *float64 { v := float64(playlist.MaxLimit); return &v }()),
+		createStringChoiceOption("provider", "Where to play or search the query instead of the default", false, []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "YouTube", Value: "youtube"},
+			{Name: "Twitch", Value: "twitch"},
+			{Name: "Internet radio", Value: "radio"},
+			{Name: "Audio file link", Value: "direct"},
+		}),
+	}
+
+	return []Command{
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "ping",
+				Description: "Responds with Pong!",
+			},
+			Handler: commands.HandlePingCommand,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "peepee",
+				Description: "PeePee Inspection Time!",
+			},
+			Handler: handlePeepee,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "8ball",
+				Description: "Ask the magic 8-ball a question",
+				Options: []*discordgo.ApplicationCommandOption{
+					withLength(createStringOption("question", "Your question for the magic 8-ball", true), 1, 256),
+				},
+			},
+			Handler: commands.Handle8BallCommand,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "coinflip",
+				Description: "Flip a coin and choose heads or tails",
+			},
+			Handler: commands.HandleCoinFlipCommand,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "server",
+				Description: "Provides information about the server",
+			},
+			Handler: commands.HandleServerCommand,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "user",
+				Description: "Replies with user info!",
+				Options: []*discordgo.ApplicationCommandOption{
+					createUserOption("target", "The user to get info about (optional)", false),
+				},
+			},
+			Handler: commands.HandleUserCommand,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "weather",
+				Description: "Get the weather forecast for a city",
+				Options: []*discordgo.ApplicationCommandOption{
+					withLength(createStringOption("city", "City name to get weather for", true), 1, 100),
+					createStringChoiceOption("duration", "Weather forecast duration", false, []*discordgo.ApplicationCommandOptionChoice{
+						{
+							Name:  "Current Weather",
+							Value: "current",
+						},
+						{
+							Name:  "1-Day Forecast",
+							Value: "1-day",
+						},
+						{
+							Name:  "5-Day Forecast",
+							Value: "5-day",
+						},
+					}),
+				},
+			},
+			Handler:  commands.HandleWeatherCommand,
+			Cooldown: 5 * time.Second,
+			Slow:     true,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "roll",
+				Description: "Roll a dice with specified maximum value (default: 100)",
+				Options: []*discordgo.ApplicationCommandOption{
+					createIntegerOption("max", "Maximum value for the dice roll (1-1000000)", false, func() *float64 { v := float64(1); return &v }(), func() *float64 { v := float64(1000000); return &v }()),
+				},
+			},
+			Handler: commands.HandleRollCommand,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "play",
+				Description: "Play music from a URL or search query",
+				Options:     playOptions,
+			},
+			Handler: commands.HandlePlayCommand,
+			Slow:    true,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "skip",
+				Description: "Skip the current song",
+			},
+			Handler: commands.HandleSkipCommand,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "music",
+				Description: "Play music and manage the queue, volume, filters and settings",
+				Options: []*discordgo.ApplicationCommandOption{
+					createSubcommandOption("play", "Play music from a URL or search query", playOptions...),
+					createSubcommandOption("skip", "Skip the current song"),
+					createSubcommandOption("join", "Join your voice channel to play music"),
+					createSubcommandOption("leave", "Leave the voice channel and stop playing music"),
+					createSubcommandGroupOption("queue", "View and manage the music queue",
+						createSubcommandOption("show", "Show the current music queue"),
+						createSubcommandOption("undo", "Undo the last clear, remove, shuffle, move, swap or cleanup"),
+						createSubcommandOption("shuffle", "Shuffle the upcoming songs",
+							createIntegerOption("seed", "Seed to reproduce a previous shuffle", false, &minShuffleSeed, &maxShuffleSeed),
+						),
+						createSubcommandOption("unshuffle", "Restore the order songs were added in"),
+						createSubcommandOption("dedupe", "Remove repeated copies of queued songs (moderators)"),
+						createSubcommandOption("remove-user", "Remove every song a user queued (moderators)",
+							createUserOption("user", "Whose songs to remove", true),
+						),
+						createSubcommandOption("clear", "Clear the music queue (asks for confirmation)",
+							createBooleanOption("dry_run", "Only preview what would be removed", false),
+						),
+						createSubcommandOption("move", "Move a queued song to another position",
+							createIntegerOption("from", "Position of the song in /music queue show", true, &minQueuePosition, nil),
+							createIntegerOption("to", "Position to move it to", true, &minQueuePosition, nil),
+						),
+						createSubcommandOption("swap", "Swap two queued songs",
+							createIntegerOption("a", "Position of the first song in /music queue show", true, &minQueuePosition, nil),
+							createIntegerOption("b", "Position of the second song", true, &minQueuePosition, nil),
+						),
+					),
+					createSubcommandOption("volume", "Show or change the playback volume",
+						createIntegerOption("level", fmt.Sprintf("Volume in percent, 100 plays songs unchanged (1-%d)", settings.MaxVolume), false, &minVolume, &maxVolume),
+					),
+					createSubcommandGroupOption("filters", "Apply audio filters to the music",
+						createSubcommandOption("toggle", "Turn an audio filter on or off",
+							createStringChoiceOption("preset", "Filter to toggle", true, filterChoices()),
+						),
+						createSubcommandOption("clear", "Turn off all audio filters"),
+						createSubcommandOption("show", "Show the available and active filters"),
+					),
+					createSubcommandOption("history", "Show recently played songs"),
+					createSubcommandOption("replay", "Queue a recently played song again",
+						createIntegerOption("n", "Entry number from /music history (1 is the latest)", false, &minReplayEntry, &maxReplayEntry),
+					),
+					createSubcommandOption("radio", "Play an internet radio station",
+						withLength(createStringOption("station", "Station name to look up in the radio-browser.info directory", true), 1, 100),
+					),
+					createSubcommandOption("soundcheck", "Play a short test tone to check that the bot's audio works"),
+					createSubcommandOption("stats", "Show this server's most played and most skipped songs"),
+					createSubcommandOption("settings", "Show this server's music settings, or change them (Manage Server)",
+						createIntegerOption("alone_timeout", "Seconds to stay in an empty voice channel (5-3600)", false, &minAloneSeconds, &maxAloneSeconds),
+						createIntegerOption("idle_timeout", "Minutes to stay connected with nothing playing, 0 to never leave (0-1440)", false, &minIdleMinutes, &maxIdleMinutes),
+						createStringChoiceOption("search_provider", "Where /play searches go by default", false, []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "YouTube", Value: "youtube"},
+							{Name: "Internet radio", Value: "radio"},
+						}),
+						createBooleanOption("channel_status", "Show the current song as the voice channel's status", false),
+						createBooleanOption("voice_control", "Listen in voice for spoken commands after a wake word (experimental)", false),
+						withLength(createStringOption("wake_word", "Word that starts spoken commands, \"computer\" unless changed", false), 2, 32),
+						createBooleanOption("dj_intros", "Speak a short intro before each song, like a radio DJ", false),
+						createBooleanOption("nickname", "Show the current song in the bot's nickname", false),
+					),
+					createSubcommandOption("antirepeat", "Refuse or warn about songs played recently (Manage Server)",
+						createStringChoiceOption("mode", "How to handle recently played songs", true, []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Off", Value: string(history.RepeatAllow)},
+							{Name: "Warn", Value: string(history.RepeatWarn)},
+							{Name: "Refuse", Value: string(history.RepeatRefuse)},
+						}),
+						createIntegerOption("hours", "How many hours a played song counts as recent (default 6)", false, &minRepeatHours, &maxRepeatHours),
+					),
+					createSubcommandOption("loudnorm", "Play every song at a similar volume (Manage Server)",
+						createStringChoiceOption("mode", "Turn loudness normalization on or off", true, onOffChoices()),
+					),
+					createSubcommandOption("fairqueue", "Take turns between requesters when queueing songs (Manage Server)",
+						createStringChoiceOption("mode", "Turn fair queue on or off", true, onOffChoices()),
+					),
+					createSubcommandOption("247", "Stay in the voice channel around the clock (Manage Server)",
+						createStringChoiceOption("mode", "Turn 24/7 mode on or off", true, onOffChoices()),
+					),
+					createSubcommandOption("autodj", "Keep music going with a rotation of this server's favourites (Manage Server)",
+						createStringChoiceOption("mode", "Turn the auto-DJ on or off", true, onOffChoices()),
+					),
+					createSubcommandGroupOption("broadcast", "Listen along to another server's music",
+						createSubcommandOption("status", "Show the running broadcast"),
+						createSubcommandOption("join", "Play the broadcast in this server (Manage Server)"),
+						createSubcommandOption("leave", "Stop listening along, keeping the current songs (Manage Server)"),
+						createSubcommandOption("start", "Broadcast this server's music to servers that join (bot owner only)"),
+						createSubcommandOption("stop", "End the broadcast (bot owner only)"),
+					),
+					createSubcommandOption("diag", "Show the yt-dlp service's extraction counts, latency and errors (bot owner only)"),
+					createSubcommandGroupOption("circuit", "Inspect or close the yt-dlp service's circuit breaker (bot owner only)",
+						createSubcommandOption("status", "Show the circuit breaker's state and failure counts"),
+						createSubcommandOption("reset", "Close the circuit breaker so extractions go to the service again"),
+					),
+				},
+			},
+			Handler: commands.HandleMusicCommand,
+			Slow:    true,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "checkperms",
+				Description: "Check the bot's permissions in a channel",
+				Options: []*discordgo.ApplicationCommandOption{
+					createChannelOption("channel", "Channel to check (defaults to the current channel)", false),
+				},
+			},
+			Handler: commands.HandleCheckPermsCommand,
+		},
 		{
-			Name:        "ping",
-			Description: "Responds with Pong!",
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "premium",
+				Description: "Show premium benefits and subscribe for this server",
+			},
+			Handler: commands.HandlePremiumCommand,
 		},
 		{
-			Name:        "peepee",
-			Description: "PeePee Inspection Time!",
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "vote",
+				Description: "Vote for the bot on bot lists and see what voting unlocks",
+			},
+			Handler: commands.HandleVoteCommand,
 		},
 		{
-			Name:        "8ball",
-			Description: "Ask the magic 8-ball a question",
-			Options: []*discordgo.ApplicationCommandOption{
-				createStringOption("question", "Your question for the magic 8-ball", true),
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "preferences",
+				Description: "Show or change how the bot responds to you",
+				Options: []*discordgo.ApplicationCommandOption{
+					createBooleanOption("plain_text", "Plain text responses without embeds or emoji, for screen readers", false),
+				},
 			},
+			Handler: commands.HandlePreferencesCommand,
 		},
 		{
-			Name:        "coinflip",
-			Description: "Flip a coin and choose heads or tails",
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "admin",
+				Description: "Bot administration tools",
+				Options: []*discordgo.ApplicationCommandOption{
+					createSubcommandOption("memory", "Show sizes of in-memory maps and caches"),
+					createSubcommandOption("usage", "Show audio bandwidth streamed per server and provider"),
+					createSubcommandOption("premium", "Show this server's premium tier, or grant or revoke it (bot owner only)",
+						createStringChoiceOption("action", "What to do (defaults to show)", false, []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Show", Value: "show"},
+							{Name: "Grant", Value: "grant"},
+							{Name: "Revoke", Value: "revoke"},
+						}),
+						createIntegerOption("days", "How long a grant lasts (forever when left out)", false, &minPremiumDays, &maxPremiumDays),
+					),
+					createSubcommandOption("cache", "Show the cache of yt-dlp extractions, or clear it (bot owner only)",
+						createStringChoiceOption("action", "What to do (defaults to show)", false, []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Show", Value: "show"},
+							{Name: "Clear", Value: "clear"},
+						}),
+					),
+					createSubcommandOption("credentials", "Show the YouTube credentials yt-dlp uses, or reload them (bot owner only)",
+						createStringChoiceOption("action", "What to do (defaults to show)", false, []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Show", Value: "show"},
+							{Name: "Reload", Value: "reload"},
+						}),
+					),
+					createSubcommandOption("backup", "Back up the bot's data, or list, verify or restore backups (bot owner only)",
+						createStringChoiceOption("action", "What to do (defaults to list)", false, []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "List", Value: "list"},
+							{Name: "Create", Value: "create"},
+							{Name: "Verify", Value: "verify"},
+							{Name: "Restore on next start", Value: "restore"},
+						}),
+						withLength(createStringOption("name", "Backup to verify or restore (defaults to the newest)", false), 1, 64),
+					),
+					createSubcommandOption("logs", "Attach recent warnings and errors from the log (bot owner only)",
+						createStringChoiceOption("level", "Which entries to include (defaults to warnings and errors)", false, []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Warnings and errors", Value: "warn"},
+							{Name: "Errors only", Value: "error"},
+						}),
+						withLength(createStringOption("module", "Only entries from this package and its subpackages, e.g. music or services/ytdlp", false), 1, 64),
+					),
+				},
+			},
+			Handler:     commands.HandleAdminCommand,
+			Permissions: discordgo.PermissionAdministrator,
 		},
 		{
-			Name:        "server",
-			Description: "Provides information about the server",
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "settings",
+				Description: "Show or change this server's settings",
+				Options: []*discordgo.ApplicationCommandOption{
+					createSubcommandOption("show", "Show this server's settings"),
+					createSubcommandOption("volume", "Volume songs play at",
+						createIntegerOption("percent", fmt.Sprintf("Volume in percent, 100 plays songs unchanged (1-%d)", settings.MaxVolume), true, &minVolume, &maxVolume),
+					),
+					createSubcommandOption("alone_timeout", "How long the bot stays in a voice channel after everyone left",
+						createIntegerOption("seconds", "Seconds to stay in an empty voice channel (5-3600)", true, &minAloneSeconds, &maxAloneSeconds),
+					),
+					createSubcommandOption("autoplay", "Keep music going with this server's favourites when the queue runs out",
+						createBooleanOption("enabled", "Whether autoplay is on", true),
+					),
+					createSubcommandOption("dj_role", "Role that may clean up the music queue like moderators",
+						createRoleOption("role", "DJ role (leave out to remove it)", false),
+					),
+					createSubcommandOption("announcements", "Channel now-playing messages are posted in",
+						withChannelTypes(createChannelOption("channel", "Announcement channel (leave out to use the channel music is requested from)", false), discordgo.ChannelTypeGuildText),
+					),
+					createSubcommandOption("language", "Language the bot answers in",
+						createStringChoiceOption("language", "Language", true, languageChoices()),
+					),
+					createSubcommandOption("summaries", "Whether members can summarize channels with /summarize",
+						createBooleanOption("enabled", "Whether /summarize is on", true),
+					),
+				},
+			},
+			Handler:     commands.HandleSettingsCommand,
+			Permissions: discordgo.PermissionManageGuild,
+		},
+		{
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "ask",
+				Description: "Ask the bot's AI a question",
+				Options: []*discordgo.ApplicationCommandOption{
+					createSubcommandOption("chat", "Ask a question, follow-ups remember this channel's recent conversation",
+						withLength(createStringOption("question", "What you want to know", true), 1, 1000),
+					),
+					createSubcommandOption("reset", "Forget this channel's conversation so the next question starts over"),
+				},
+			},
+			Handler:  commands.HandleAskCommand,
+			Cooldown: 5 * time.Second,
+			Slow:     true,
 		},
 		{
-			Name:        "user",
-			Description: "Replies with user info!",
-			Options: []*discordgo.ApplicationCommandOption{
-				createUserOption("target", "The user to get info about (optional)", false),
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "summarize",
+				Description: "Summarize this channel's recent messages with the bot's AI",
+				Options: []*discordgo.ApplicationCommandOption{
+					createIntegerOption("count", fmt.Sprintf("How many recent messages to summarize (10-%d, default %d)", commands.MaxSummaryMessages, commands.DefaultSummaryMessages), false, &minSummaryMessages, &maxSummaryMessages),
+					withLength(createStringOption("since", "Only messages from this long ago, e.g. 30m or 2h (at most 24h)", false), 2, 16),
+				},
 			},
+			Handler:  commands.HandleSummarizeCommand,
+			Cooldown: 30 * time.Second,
+			Slow:     true,
 		},
 		{
-			Name:        "weather",
-			Description: "Get the weather forecast for a city",
-			Options: []*discordgo.ApplicationCommandOption{
-				createStringOption("city", "City name to get weather for", true),
-				createStringChoiceOption("duration", "Weather forecast duration", false, []*discordgo.ApplicationCommandOptionChoice{
-					{
-						Name:  "Current Weather",
-						Value: "current",
-					},
-					{
-						Name:  "1-Day Forecast",
-						Value: "1-day",
-					},
-					{
-						Name:  "5-Day Forecast",
-						Value: "5-day",
-					},
-				}),
+			// Message commands show in a message's Apps menu, named like a button and without a description
+			Definition: &discordgo.ApplicationCommand{
+				Name: "Transcribe",
+				Type: discordgo.MessageApplicationCommand,
 			},
+			Handler:  commands.HandleTranscribeCommand,
+			Cooldown: 15 * time.Second,
+			Slow:     true,
 		},
 		{
-			Name:        "roll",
-			Description: "Roll a dice with specified maximum value (default: 100)",
-			Options: []*discordgo.ApplicationCommandOption{
-				createIntegerOption("max", "Maximum value for the dice roll (1-1000000)", false, func() *float64 { v := float64(1); return &v }(), func() *float64 { v := float64(1000000); return &v }()),
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "ai",
+				Description: "Show or change how the bot's AI answers /ask in this server",
+				Options: []*discordgo.ApplicationCommandOption{
+					createSubcommandOption("show", "Show this server's AI persona, temperature and channels"),
+					createSubcommandOption("persona", "Give the AI a persona: who it is and how it talks",
+						withLength(createStringOption("prompt", "Instructions such as: You are Captain Byte, a cheerful pirate who loves music", true), 1, 4000),
+					),
+					createSubcommandOption("temperature", "How creative answers are, from 0 (focused) up to the bot's limit",
+						createNumberOption("value", "Temperature, e.g. 0.7", true, 0, llm.MaxTemperature),
+					),
+					createSubcommandOption("channel", "Allow or disallow /ask in a channel (every channel while none is allowed)",
+						withChannelTypes(createChannelOption("channel", "Channel to allow or disallow", true), discordgo.ChannelTypeGuildText),
+						createBooleanOption("allowed", "Whether /ask answers in the channel (defaults to True)", false),
+					),
+					createSubcommandOption("reset", "Go back to the default persona, temperature and channels"),
+				},
 			},
+			Handler:     commands.HandleAICommand,
+			Permissions: discordgo.PermissionManageGuild,
 		},
 		{
-			Name:        "join",
-			Description: "Join your voice channel to play music",
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "debug",
+				Description: "Attach a snapshot of the music player state for a bug report (bot owner only)",
+			},
+			Handler:     commands.HandleDebugCommand,
+			Permissions: discordgo.PermissionAdministrator,
 		},
 		{
-			Name:        "leave",
-			Description: "Leave the voice channel and stop playing music",
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "botinfo",
+				Description: "Show the bot's servers and the state of its gateway shards",
+			},
+			Handler: commands.HandleBotInfoCommand,
 		},
 		{
-			Name:        "play",
-			Description: "Play music from a URL or search query",
-			Options: []*discordgo.ApplicationCommandOption{
-				createStringOption("query", "YouTube URL or search query", true),
+			Definition: &discordgo.ApplicationCommand{
+				Name:        "support",
+				Description: "Get a diagnostics file for this server and a link to the support server",
 			},
+			Handler:     commands.HandleSupportCommand,
+			Permissions: discordgo.PermissionManageGuild,
+			Cooldown:    30 * time.Second,
 		},
 	}
 }
 
-// RegisterCommands registers all bot commands with Discord (includes cleanup of existing commands)
-func RegisterCommands(s *discordgo.Session) error {
-	fmt.Println("Starting command registration process...")
-
-	// Always clean up existing commands first to ensure clean state
-	fmt.Println("Cleaning up existing commands...")
-
-	// Clear all existing global commands
-	fmt.Println("Retrieving existing global commands...")
-	existingCommands, err := s.ApplicationCommands(s.State.User.ID, "")
-	if err != nil {
-		return fmt.Errorf("cannot retrieve existing commands: %w", err)
-	}
-
-	// Delete all existing global commands
-	if len(existingCommands) > 0 {
-		fmt.Printf("Deleting %d existing global commands...\n", len(existingCommands))
-		for _, cmd := range existingCommands {
-			fmt.Printf("Deleting global command: %s\n", cmd.Name)
-			err := s.ApplicationCommandDelete(s.State.User.ID, "", cmd.ID)
-			if err != nil {
-				return fmt.Errorf("cannot delete existing command '%v': %w", cmd.Name, err)
-			}
-		}
-	} else {
-		fmt.Println("No existing global commands found.")
-	}
-
-	// Also clear guild-specific commands for all guilds the bot is in
-	fmt.Println("Clearing guild-specific commands...")
-	for _, guild := range s.State.Guilds {
-		guildCommands, err := s.ApplicationCommands(s.State.User.ID, guild.ID)
-		if err != nil {
-			fmt.Printf("Warning: Could not retrieve commands for guild %s: %v\n", guild.ID, err)
-			continue
-		}
-
-		if len(guildCommands) > 0 {
-			fmt.Printf("Deleting %d commands from guild %s...\n", len(guildCommands), guild.ID)
-			for _, cmd := range guildCommands {
-				fmt.Printf("Deleting guild command: %s from guild %s\n", cmd.Name, guild.ID)
-				err := s.ApplicationCommandDelete(s.State.User.ID, guild.ID, cmd.ID)
-				if err != nil {
-					fmt.Printf("Warning: Could not delete command '%s' from guild %s: %v\n", cmd.Name, guild.ID, err)
-				}
-			}
-		}
-	}
+// GetCommands returns the list of application commands for the bot
+func GetCommands() []*discordgo.ApplicationCommand {
+	return commandRegistry().Definitions()
+}
 
-	// Register the current commands as global commands
-	fmt.Println("Registering new global commands...")
-	commands := GetCommands()
-	for _, cmd := range commands {
-		fmt.Printf("Creating global command: %s\n", cmd.Name)
-		_, err := s.ApplicationCommandCreate(s.State.User.ID, "", cmd)
-		if err != nil {
-			return fmt.Errorf("cannot create '%v' command: %w", cmd.Name, err)
-		}
+// CommandDefinition returns the definition of a top-level command, nil for unknown commands
+func CommandDefinition(name string) *discordgo.ApplicationCommand {
+	command, exists := commandRegistry().Command(name)
+	if !exists {
+		return nil
 	}
-
-	fmt.Printf("Successfully registered %d commands!\n", len(commands))
-	return nil
+	return command.Definition
 }
@@ -1,9 +1,13 @@
 package bot
 
 import (
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/i18n"
 )
 
 func TestCreateStringOption(t *testing.T) {
@@ -88,10 +92,52 @@ func TestCreateUserOption(t *testing.T) {
 	}
 }
 
+func TestCreateChannelOption(t *testing.T) {
+	option := createChannelOption("channel", "Channel to check", false)
+
+	if option.Type != discordgo.ApplicationCommandOptionChannel {
+		t.Errorf("Expected type Channel, got %v", option.Type)
+	}
+	if option.Name != "channel" {
+		t.Errorf("Expected name 'channel', got '%s'", option.Name)
+	}
+	if option.Required {
+		t.Error("Expected channel option to be optional")
+	}
+}
+
+func TestCreateBooleanOption(t *testing.T) {
+	option := createBooleanOption("dry_run", "Only preview", false)
+
+	if option.Type != discordgo.ApplicationCommandOptionBoolean {
+		t.Errorf("Expected type Boolean, got %v", option.Type)
+	}
+	if option.Name != "dry_run" {
+		t.Errorf("Expected name 'dry_run', got '%s'", option.Name)
+	}
+	if option.Required {
+		t.Error("Expected boolean option to be optional")
+	}
+}
+
+func TestCreateSubcommandOption(t *testing.T) {
+	option := createSubcommandOption("undo", "Undo the last change", createBooleanOption("dry_run", "Only preview", false))
+
+	if option.Type != discordgo.ApplicationCommandOptionSubCommand {
+		t.Errorf("Expected type SubCommand, got %v", option.Type)
+	}
+	if option.Name != "undo" {
+		t.Errorf("Expected name 'undo', got '%s'", option.Name)
+	}
+	if len(option.Options) != 1 {
+		t.Errorf("Expected 1 nested option, got %d", len(option.Options))
+	}
+}
+
 func TestGetCommands(t *testing.T) {
 	commands := GetCommands()
 
-	expectedCount := 11
+	expectedCount := 24
 	if len(commands) != expectedCount {
 		t.Errorf("Expected %d commands, got %d", expectedCount, len(commands))
 	}
@@ -102,17 +148,30 @@ func TestGetCommands(t *testing.T) {
 		hasOptions  bool
 		optionCount int
 	}{
-		"ping":     {"Responds with Pong!", false, 0},
-		"peepee":   {"PeePee Inspection Time!", false, 0},
-		"8ball":    {"Ask the magic 8-ball a question", true, 1},
-		"coinflip": {"Flip a coin and choose heads or tails", false, 0},
-		"server":   {"Provides information about the server", false, 0},
-		"user":     {"Replies with user info!", true, 1},
-		"weather":  {"Get the weather forecast for a city", true, 2},
-		"roll":     {"Roll a dice with specified maximum value (default: 100)", true, 1},
-		"join":     {"Join your voice channel to play music", false, 0},
-		"leave":    {"Leave the voice channel and stop playing music", false, 0},
-		"play":     {"Play music from a URL or search query", true, 1},
+		"ping":        {"Responds with Pong!", false, 0},
+		"peepee":      {"PeePee Inspection Time!", false, 0},
+		"8ball":       {"Ask the magic 8-ball a question", true, 1},
+		"coinflip":    {"Flip a coin and choose heads or tails", false, 0},
+		"server":      {"Provides information about the server", false, 0},
+		"user":        {"Replies with user info!", true, 1},
+		"weather":     {"Get the weather forecast for a city", true, 2},
+		"roll":        {"Roll a dice with specified maximum value (default: 100)", true, 1},
+		"play":        {"Play music from a URL or search query", true, 4},
+		"skip":        {"Skip the current song", false, 0},
+		"music":       {"Play music and manage the queue, volume, filters and settings", true, 21},
+		"checkperms":  {"Check the bot's permissions in a channel", true, 1},
+		"premium":     {"Show premium benefits and subscribe for this server", false, 0},
+		"vote":        {"Vote for the bot on bot lists and see what voting unlocks", false, 0},
+		"preferences": {"Show or change how the bot responds to you", true, 1},
+		"admin":       {"Bot administration tools", true, 7},
+		"settings":    {"Show or change this server's settings", true, 8},
+		"ask":         {"Ask the bot's AI a question", true, 2},
+		"summarize":   {"Summarize this channel's recent messages with the bot's AI", true, 2},
+		"Transcribe":  {"", false, 0},
+		"ai":          {"Show or change how the bot's AI answers /ask in this server", true, 5},
+		"debug":       {"Attach a snapshot of the music player state for a bug report (bot owner only)", false, 0},
+		"botinfo":     {"Show the bot's servers and the state of its gateway shards", false, 0},
+		"support":     {"Get a diagnostics file for this server and a link to the support server", false, 0},
 	}
 
 	foundCommands := make(map[string]bool)
@@ -221,6 +280,91 @@ func TestGetCommandsOptionsValidation(t *testing.T) {
 					t.Errorf("duration option should have 3 choices, got %d", len(durationOption.Choices))
 				}
 			}
+
+		case "music":
+			groups := map[string]bool{"queue": true, "filters": true, "broadcast": true, "circuit": true}
+			for _, option := range cmd.Options {
+				want := discordgo.ApplicationCommandOptionSubCommand
+				if groups[option.Name] {
+					want = discordgo.ApplicationCommandOptionSubCommandGroup
+				}
+				if option.Type != want {
+					t.Errorf("music %s should be type %v, got %v", option.Name, want, option.Type)
+				}
+				if option.Name == "play" && len(option.Options) != len(CommandDefinition("play").Options) {
+					t.Errorf("music play should take the same options as /play, got %d", len(option.Options))
+				}
+			}
 		}
 	}
 }
+
+func TestCommandDefinition(t *testing.T) {
+	roll := CommandDefinition("roll")
+	if roll == nil || roll.Name != "roll" {
+		t.Fatalf("CommandDefinition(\"roll\") = %v, want the roll command", roll)
+	}
+	if CommandDefinition("doesnotexist") != nil {
+		t.Error("CommandDefinition() should return nil for unknown commands")
+	}
+
+	city := CommandDefinition("weather").Options[0]
+	if city.MinLength == nil || *city.MinLength != 1 || city.MaxLength != 100 {
+		t.Errorf("weather city should take 1-100 characters, got %v-%d", city.MinLength, city.MaxLength)
+	}
+}
+
+// commandPaths lists every command, subcommand and option by its path, such as "settings language"
+func commandPaths(prefix string, options []*discordgo.ApplicationCommandOption, paths map[string]bool) {
+	for _, option := range options {
+		path := prefix + " " + option.Name
+		paths[path] = true
+		commandPaths(path, option.Options, paths)
+	}
+}
+
+func TestCommandLocalizations(t *testing.T) {
+	paths := make(map[string]bool)
+	for _, cmd := range GetCommands() {
+		paths[cmd.Name] = true
+		commandPaths(cmd.Name, cmd.Options, paths)
+	}
+	slashName := regexp.MustCompile(`^[-_\p{Ll}\p{Lo}\p{N}]{1,32}$`)
+
+	for _, language := range i18n.Languages() {
+		for _, key := range i18n.Keys(language) {
+			if !strings.HasPrefix(key, "command.") {
+				continue
+			}
+			path, isDescription := strings.CutSuffix(strings.TrimPrefix(key, "command."), ".description")
+			path, isName := strings.CutSuffix(path, ".name")
+			if !paths[path] || isDescription == isName {
+				t.Errorf("%s translates %q, which isn't the name or description of a command or option", language, key)
+				continue
+			}
+			text := i18n.T(language, key)
+			// Message commands are the only ones with names such as "Transcribe"
+			definition := CommandDefinition(path)
+			slash := definition == nil || definition.Type != discordgo.MessageApplicationCommand
+			if isName && slash && !slashName.MatchString(text) {
+				t.Errorf("%s name %q of %s must be lowercase without spaces", language, text, path)
+			}
+			if isDescription && len([]rune(text)) > 100 {
+				t.Errorf("%s description of %s is longer than Discord's 100 characters", language, path)
+			}
+		}
+	}
+
+	settings := CommandDefinition("settings")
+	if settings.DescriptionLocalizations == nil || (*settings.DescriptionLocalizations)[discordgo.German] == "" {
+		t.Error("Expected /settings to have a German description")
+	}
+	for _, option := range settings.Options {
+		if option.Name == "language" && option.DescriptionLocalizations[discordgo.French] == "" {
+			t.Error("Expected /settings language to have a French description")
+		}
+	}
+	if names := CommandDefinition("Transcribe").NameLocalizations; names == nil || (*names)[discordgo.German] != "Transkribieren" {
+		t.Error("Expected the Transcribe message command to have a German name")
+	}
+}
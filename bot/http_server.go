@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// serveHTTP serves handler on addr in the background and returns a function that shuts the server down.
+// addr is a TCP address such as :8090 or [::1]:8090, or a unix socket such as unix:/run/bot/stats.sock.
+// name identifies the server in logs, such as "Stats page".
+func serveHTTP(name, addr string, handler http.Handler) (func(), error) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	listener, err := utils.Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+	utils.SafeGo("bot.serveHTTP", func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			utils.LogError("%s stopped: %v", name, err)
+		}
+	})
+	utils.LogInfo("Serving %s on %s", name, listener.Addr())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			utils.LogWarn("%s shutdown: %v", name, err)
+		}
+	}, nil
+}
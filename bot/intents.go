@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
+)
+
+// IntentConfig selects the optional features that decide which gateway intents the bot requests
+type IntentConfig struct {
+	Music          bool // Voice state tracking for music playback and auto-disconnect
+	Members        bool // Privileged: guild member join/leave/update events
+	Presences      bool // Privileged: presence and activity updates
+	MessageContent bool // Privileged: content of messages that don't mention the bot
+}
+
+// DefaultIntentConfig returns the configuration used when no overrides are set
+func DefaultIntentConfig() IntentConfig {
+	return IntentConfig{Music: true}
+}
+
+// LoadIntentConfig reads the feature toggles from the environment, falling back to the defaults
+func LoadIntentConfig() IntentConfig {
+	config := DefaultIntentConfig()
+	config.Music = envBool("BOT_ENABLE_MUSIC", config.Music)
+	config.Members = envBool("BOT_INTENT_MEMBERS", config.Members)
+	config.Presences = envBool("BOT_INTENT_PRESENCES", config.Presences)
+	config.MessageContent = envBool("BOT_INTENT_MESSAGE_CONTENT", config.MessageContent)
+	return config
+}
+
+// envBool parses a boolean environment variable, warning and using the fallback on invalid values
+func envBool(key string, fallback bool) bool {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		utils.LogWarn("Ignoring invalid value %q for %s, using %v", raw, key, fallback)
+		return fallback
+	}
+	return value
+}
+
+// Intents returns the gateway intents needed by the enabled features
+func (c IntentConfig) Intents() discordgo.Intent {
+	// Guild events keep the state cache and guild lifecycle cleanup working,
+	// messages and emojis are needed for the core commands
+	intents := discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildEmojis
+
+	if c.Music {
+		intents |= discordgo.IntentsGuildVoiceStates
+	}
+	if c.Members {
+		intents |= discordgo.IntentsGuildMembers
+	}
+	if c.Presences {
+		intents |= discordgo.IntentsGuildPresences
+	}
+	if c.MessageContent {
+		intents |= discordgo.IntentsMessageContent
+	}
+
+	return intents
+}
+
+// Application flags reporting which privileged intents are enabled in the developer portal.
+// The "limited" variants are granted to unverified bots in fewer than 100 servers.
+const (
+	applicationFlagGatewayPresence              = 1 << 12
+	applicationFlagGatewayPresenceLimited       = 1 << 13
+	applicationFlagGatewayGuildMembers          = 1 << 14
+	applicationFlagGatewayGuildMembersLimited   = 1 << 15
+	applicationFlagGatewayMessageContent        = 1 << 18
+	applicationFlagGatewayMessageContentLimited = 1 << 19
+)
+
+// privilegedIntent maps a privileged intent to the application flags that grant it
+type privilegedIntent struct {
+	Intent     discordgo.Intent
+	Name       string
+	GrantFlags int
+	EnvVar     string
+}
+
+var privilegedIntents = []privilegedIntent{
+	{
+		Intent:     discordgo.IntentsGuildMembers,
+		Name:       "Server Members Intent",
+		GrantFlags: applicationFlagGatewayGuildMembers | applicationFlagGatewayGuildMembersLimited,
+		EnvVar:     "BOT_INTENT_MEMBERS",
+	},
+	{
+		Intent:     discordgo.IntentsGuildPresences,
+		Name:       "Presence Intent",
+		GrantFlags: applicationFlagGatewayPresence | applicationFlagGatewayPresenceLimited,
+		EnvVar:     "BOT_INTENT_PRESENCES",
+	},
+	{
+		Intent:     discordgo.IntentsMessageContent,
+		Name:       "Message Content Intent",
+		GrantFlags: applicationFlagGatewayMessageContent | applicationFlagGatewayMessageContentLimited,
+		EnvVar:     "BOT_INTENT_MESSAGE_CONTENT",
+	},
+}
+
+// missingPrivilegedIntents returns the privileged intents that are requested but not granted by the application flags
+func missingPrivilegedIntents(requested discordgo.Intent, applicationFlags int) []privilegedIntent {
+	var missing []privilegedIntent
+	for _, privileged := range privilegedIntents {
+		if requested&privileged.Intent == 0 {
+			continue
+		}
+		if applicationFlags&privileged.GrantFlags == 0 {
+			missing = append(missing, privileged)
+		}
+	}
+	return missing
+}
+
+// validatePrivilegedIntents warns about requested privileged intents that are not enabled for the application.
+// Discord rejects the gateway connection (close code 4014) when a disallowed intent is requested.
+func (b *Bot) validatePrivilegedIntents() {
+	requested := b.Session.Identify.Intents
+	if requested&(discordgo.IntentsGuildMembers|discordgo.IntentsGuildPresences|discordgo.IntentsMessageContent) == 0 {
+		return
+	}
+
+	application, err := b.Session.Application("@me")
+	if err != nil {
+		utils.LogWarn("Could not verify privileged intents: %v", err)
+		return
+	}
+
+	for _, missing := range missingPrivilegedIntents(requested, application.Flags) {
+		utils.LogWarn("%s is requested but not enabled in the Discord developer portal (Bot > Privileged Gateway Intents). "+
+			"Enable it there or set %s=false, otherwise Discord will refuse the connection.", missing.Name, missing.EnvVar)
+	}
+}
@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestIntentConfigIntents(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   IntentConfig
+		expected discordgo.Intent
+	}{
+		{
+			name:     "default config",
+			config:   DefaultIntentConfig(),
+			expected: discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildEmojis | discordgo.IntentsGuildVoiceStates,
+		},
+		{
+			name:     "music disabled",
+			config:   IntentConfig{},
+			expected: discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildEmojis,
+		},
+		{
+			name:   "all privileged intents",
+			config: IntentConfig{Music: true, Members: true, Presences: true, MessageContent: true},
+			expected: discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsGuildEmojis | discordgo.IntentsGuildVoiceStates |
+				discordgo.IntentsGuildMembers | discordgo.IntentsGuildPresences | discordgo.IntentsMessageContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Intents(); got != tt.expected {
+				t.Errorf("Expected intents %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadIntentConfig(t *testing.T) {
+	t.Setenv("BOT_ENABLE_MUSIC", "false")
+	t.Setenv("BOT_INTENT_MEMBERS", "true")
+	t.Setenv("BOT_INTENT_PRESENCES", "not-a-bool")
+	t.Setenv("BOT_INTENT_MESSAGE_CONTENT", "")
+
+	config := LoadIntentConfig()
+
+	if config.Music {
+		t.Error("Expected music to be disabled")
+	}
+	if !config.Members {
+		t.Error("Expected members intent to be enabled")
+	}
+	if config.Presences {
+		t.Error("Expected invalid presences value to fall back to false")
+	}
+	if config.MessageContent {
+		t.Error("Expected unset message content value to fall back to false")
+	}
+}
+
+func TestMissingPrivilegedIntents(t *testing.T) {
+	requested := discordgo.IntentsGuildMembers | discordgo.IntentsMessageContent
+
+	tests := []struct {
+		name     string
+		flags    int
+		expected []string
+	}{
+		{
+			name:     "nothing granted",
+			flags:    0,
+			expected: []string{"Server Members Intent", "Message Content Intent"},
+		},
+		{
+			name:     "limited members grant counts",
+			flags:    applicationFlagGatewayGuildMembersLimited,
+			expected: []string{"Message Content Intent"},
+		},
+		{
+			name:     "everything granted",
+			flags:    applicationFlagGatewayGuildMembers | applicationFlagGatewayMessageContent,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing := missingPrivilegedIntents(requested, tt.flags)
+			if len(missing) != len(tt.expected) {
+				t.Fatalf("Expected %d missing intents, got %d", len(tt.expected), len(missing))
+			}
+			for i, intent := range missing {
+				if intent.Name != tt.expected[i] {
+					t.Errorf("Expected missing intent %q, got %q", tt.expected[i], intent.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/utils"
+)
+
+// guildReconcileInterval is how often per-guild state is checked against the guilds the bot is still in
+const guildReconcileInterval = 10 * time.Minute
+
+// guildResource is per-guild state held by a feature (players, timers, cached config, scheduled jobs)
+type guildResource struct {
+	name    string
+	tracked func() []string      // Guild IDs the feature currently holds state for
+	cleanup func(guildID string) // Releases everything held for the guild
+}
+
+// guildResources tracks registered per-guild state so it can be torn down when the bot leaves a guild
+type guildResources struct {
+	mu        sync.Mutex
+	resources []guildResource
+}
+
+// register adds a feature's per-guild state to lifecycle cleanup
+func (g *guildResources) register(name string, tracked func() []string, cleanup func(guildID string)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.resources = append(g.resources, guildResource{name: name, tracked: tracked, cleanup: cleanup})
+}
+
+// cleanupGuild releases all registered state for a guild
+func (g *guildResources) cleanupGuild(guildID string) {
+	g.mu.Lock()
+	resources := append([]guildResource(nil), g.resources...)
+	g.mu.Unlock()
+
+	for _, resource := range resources {
+		utils.LogDebug("Cleaning up %s for guild %s", resource.name, guildID)
+		resource.cleanup(guildID)
+	}
+}
+
+// reconcile releases state held for guilds that current reports the bot is no longer in and returns how many were cleaned
+func (g *guildResources) reconcile(current func(guildID string) bool) int {
+	g.mu.Lock()
+	resources := append([]guildResource(nil), g.resources...)
+	g.mu.Unlock()
+
+	cleaned := 0
+	for _, resource := range resources {
+		for _, guildID := range resource.tracked() {
+			if current(guildID) {
+				continue
+			}
+			utils.LogInfo("Releasing stale %s for guild %s the bot is no longer in", resource.name, guildID)
+			resource.cleanup(guildID)
+			cleaned++
+		}
+	}
+	return cleaned
+}
+
+// unavailableGuilds are guilds Discord reported unavailable in an outage. discordgo drops them from its
+// state like guilds the bot left, so reconciliation has to tell the two apart.
+type unavailableGuilds struct {
+	mu     sync.Mutex
+	guilds map[string]bool
+}
+
+// mark records whether a guild is unavailable
+func (u *unavailableGuilds) mark(guildID string, unavailable bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if !unavailable {
+		delete(u.guilds, guildID)
+		return
+	}
+	if u.guilds == nil {
+		u.guilds = make(map[string]bool)
+	}
+	u.guilds[guildID] = true
+}
+
+// contains reports whether a guild is unavailable
+func (u *unavailableGuilds) contains(guildID string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.guilds[guildID]
+}
+
+// RegisterGuildResource adds per-guild state to lifecycle cleanup on guild removal and periodic reconciliation
+func (b *Bot) RegisterGuildResource(name string, tracked func() []string, cleanup func(guildID string)) {
+	b.guildResources.register(name, tracked, cleanup)
+}
+
+// guildDelete tears down per-guild state when the bot is removed from a guild
+func (b *Bot) guildDelete(s *discordgo.Session, event *discordgo.GuildDelete) {
+	// Unavailable guilds are part of an outage, the bot is still a member
+	if event.Unavailable {
+		utils.LogWarn("Guild %s became unavailable, keeping its state", event.ID)
+		b.unavailableGuilds.mark(event.ID, true)
+		return
+	}
+
+	utils.LogInfo("Removed from guild %s, cleaning up its state", event.ID)
+	b.unavailableGuilds.mark(event.ID, false)
+	b.guildResources.cleanupGuild(event.ID)
+}
+
+// guildCreate notes guilds that are available again after an outage
+func (b *Bot) guildCreate(s *discordgo.Session, event *discordgo.GuildCreate) {
+	b.unavailableGuilds.mark(event.ID, false)
+}
+
+// reconcileGuilds cleans state for guilds missing from the shards' state, catching missed GuildDelete events.
+// Guilds in an outage are kept, and so are those of shards that haven't received their guilds yet.
+func (b *Bot) reconcileGuilds() {
+	if b.Session.State == nil {
+		return
+	}
+
+	guilds := b.guildIDs()
+	current := func(guildID string) bool {
+		// Guilds on shards other processes run are theirs to reconcile
+		if !b.runsShard(commands.GuildShard(guildID, b.shardCount)) || !b.sessionFor(guildID).DataReady {
+			return true
+		}
+		return guilds[guildID] || b.unavailableGuilds.contains(guildID)
+	}
+	if cleaned := b.guildResources.reconcile(current); cleaned > 0 {
+		utils.LogInfo("Guild reconciliation released state for %d stale guild entries", cleaned)
+	}
+}
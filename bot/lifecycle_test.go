@@ -0,0 +1,216 @@
+package bot
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/lavalink"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/testutils"
+)
+
+// fakeGuildResource records cleanups of a set of tracked guilds
+type fakeGuildResource struct {
+	guilds  map[string]bool
+	cleaned []string
+}
+
+func newFakeGuildResource(guildIDs ...string) *fakeGuildResource {
+	resource := &fakeGuildResource{guilds: make(map[string]bool)}
+	for _, guildID := range guildIDs {
+		resource.guilds[guildID] = true
+	}
+	return resource
+}
+
+func (f *fakeGuildResource) tracked() []string {
+	guildIDs := make([]string, 0, len(f.guilds))
+	for guildID := range f.guilds {
+		guildIDs = append(guildIDs, guildID)
+	}
+	sort.Strings(guildIDs)
+	return guildIDs
+}
+
+func (f *fakeGuildResource) cleanup(guildID string) {
+	delete(f.guilds, guildID)
+	f.cleaned = append(f.cleaned, guildID)
+}
+
+func TestGuildDeleteCleansUpResources(t *testing.T) {
+	bot, err := New("test.token")
+	if err != nil {
+		t.Fatalf("Failed to create bot: %v", err)
+	}
+
+	resource := newFakeGuildResource("guild_1", "guild_2")
+	bot.RegisterGuildResource("fake", resource.tracked, resource.cleanup)
+
+	// An outage must not drop state
+	bot.guildDelete(bot.Session, &discordgo.GuildDelete{Guild: &discordgo.Guild{ID: "guild_1", Unavailable: true}})
+	if len(resource.cleaned) != 0 {
+		t.Errorf("Expected no cleanup for unavailable guild, got %v", resource.cleaned)
+	}
+
+	bot.guildDelete(bot.Session, &discordgo.GuildDelete{Guild: &discordgo.Guild{ID: "guild_1"}})
+	if len(resource.cleaned) != 1 || resource.cleaned[0] != "guild_1" {
+		t.Errorf("Expected guild_1 to be cleaned up, got %v", resource.cleaned)
+	}
+	if !resource.guilds["guild_2"] {
+		t.Error("Expected guild_2 state to be kept")
+	}
+}
+
+func TestReconcileGuilds(t *testing.T) {
+	bot, err := New("test.token")
+	if err != nil {
+		t.Fatalf("Failed to create bot: %v", err)
+	}
+
+	players := newFakeGuildResource("guild_1", "guild_2", "guild_3")
+	timers := newFakeGuildResource("guild_3")
+	bot.RegisterGuildResource("players", players.tracked, players.cleanup)
+	bot.RegisterGuildResource("timers", timers.tracked, timers.cleanup)
+
+	if err := bot.Session.State.GuildAdd(&discordgo.Guild{ID: "guild_1"}); err != nil {
+		t.Fatalf("Failed to add guild to state: %v", err)
+	}
+
+	// A shard still receiving its guilds can't tell which ones the bot left
+	bot.reconcileGuilds()
+	if len(players.cleaned) != 0 || len(timers.cleaned) != 0 {
+		t.Errorf("Expected nothing released before the shard is ready, got %v and %v", players.cleaned, timers.cleaned)
+	}
+
+	bot.Session.DataReady = true
+	bot.reconcileGuilds()
+
+	if len(players.guilds) != 1 || !players.guilds["guild_1"] {
+		t.Errorf("Expected only guild_1 players to remain, got %v", players.tracked())
+	}
+	if len(timers.guilds) != 0 {
+		t.Errorf("Expected stale timers to be released, got %v", timers.tracked())
+	}
+}
+
+func TestReconcileGuildsKeepsUnavailableGuilds(t *testing.T) {
+	bot, err := New("test.token")
+	if err != nil {
+		t.Fatalf("Failed to create bot: %v", err)
+	}
+	players := newFakeGuildResource("guild_1")
+	bot.RegisterGuildResource("players", players.tracked, players.cleanup)
+	bot.Session.DataReady = true
+
+	// discordgo drops a guild from its state when it goes unavailable, as if the bot had left
+	if err := bot.Session.State.GuildAdd(&discordgo.Guild{ID: "guild_1"}); err != nil {
+		t.Fatalf("Failed to add guild to state: %v", err)
+	}
+	outage := &discordgo.GuildDelete{Guild: &discordgo.Guild{ID: "guild_1", Unavailable: true}}
+	if err := bot.Session.State.GuildRemove(outage.Guild); err != nil {
+		t.Fatalf("Failed to remove guild from state: %v", err)
+	}
+	bot.guildDelete(bot.Session, outage)
+
+	bot.reconcileGuilds()
+	if len(players.cleaned) != 0 {
+		t.Errorf("Expected the state of an unavailable guild to survive reconciliation, got %v released", players.cleaned)
+	}
+
+	// Once the guild is back it is treated like any other
+	bot.guildCreate(bot.Session, &discordgo.GuildCreate{Guild: &discordgo.Guild{ID: "guild_1"}})
+	if bot.unavailableGuilds.contains("guild_1") {
+		t.Error("Expected guild_1 to be available again")
+	}
+}
+
+func TestGuildDeleteKeepsGuildSettings(t *testing.T) {
+	bot, err := New("test.token")
+	if err != nil {
+		t.Fatalf("Failed to create bot: %v", err)
+	}
+	original := commands.GuildSettings
+	commands.GuildSettings = settings.New()
+	defer func() { commands.GuildSettings = original }()
+	if _, err := commands.GuildSettings.Update("guild_1", func(g *settings.Guild) { g.Language = "de" }); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+
+	// Being kicked and invited back, or a reconciliation gone wrong, must not wipe the server's settings
+	bot.guildDelete(bot.Session, &discordgo.GuildDelete{Guild: &discordgo.Guild{ID: "guild_1"}})
+	bot.Session.DataReady = true
+	bot.reconcileGuilds()
+	if language := commands.GuildSettings.Get("guild_1").Language; language != "de" {
+		t.Errorf("Expected the settings of a removed guild to be kept, got language %q", language)
+	}
+}
+
+func TestGuildCleanupEmptiesPlayerMaps(t *testing.T) {
+	session := testutils.OpenTestSession(t, "bot_id")
+	player := music.NewSimplePlayer(session)
+	// A Lavalink node joins through the gateway alone, without a voice connection to wait for
+	player.UseLavalink(lavalink.NewNode(lavalink.Config{URL: "http://127.0.0.1:1"}))
+	if err := player.SetIdleTimeout("guild_1", time.Minute); err != nil {
+		t.Fatalf("Failed to set idle timeout: %v", err)
+	}
+	if _, _, err := player.ToggleFilter("guild_1", "bassboost"); err != nil {
+		t.Fatalf("Failed to turn on filter: %v", err)
+	}
+	// The bot is alone in its voice channel, which starts the disconnect timer
+	if err := session.State.GuildAdd(&discordgo.Guild{ID: "guild_1", VoiceStates: []*discordgo.VoiceState{
+		{GuildID: "guild_1", UserID: "bot_id", ChannelID: "voice_1"},
+	}}); err != nil {
+		t.Fatalf("Failed to add guild to state: %v", err)
+	}
+
+	join := func() {
+		t.Helper()
+		if err := player.JoinChannel("guild_1", "voice_1"); err != nil {
+			t.Fatalf("Failed to join voice channel: %v", err)
+		}
+		player.HandleVoiceStateUpdate("guild_1")
+
+		// The idle timer starts in the background after joining
+		deadline := time.Now().Add(time.Second)
+		for player.MemoryStats().IdleTimers == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		stats := player.MemoryStats()
+		if stats.Players != 1 || stats.DisconnectTimers != 1 || stats.IdleTimers != 1 || stats.PlayTimes != 1 || stats.FilterChains != 1 {
+			t.Fatalf("Expected one entry in every map after joining, got %+v", stats)
+		}
+	}
+
+	join()
+	if err := player.LeaveChannel("guild_1"); err != nil {
+		t.Fatalf("Failed to leave voice channel: %v", err)
+	}
+	stats := player.MemoryStats()
+	if stats.Players != 0 || stats.DisconnectTimers != 0 || stats.IdleTimers != 0 {
+		t.Errorf("Expected no player or timers after leaving, got %+v", stats)
+	}
+	// Play times and filters last beyond one voice session, until the bot leaves the guild
+	if stats.PlayTimes != 1 || stats.FilterChains != 1 {
+		t.Errorf("Expected play times and filters to be kept after leaving, got %+v", stats)
+	}
+
+	join()
+	bot, err := New("test.token")
+	if err != nil {
+		t.Fatalf("Failed to create bot: %v", err)
+	}
+	bot.RegisterGuildResource("music player", player.GuildIDs, player.CleanupGuild)
+	bot.guildDelete(bot.Session, &discordgo.GuildDelete{Guild: &discordgo.Guild{ID: "guild_1"}})
+
+	if stats := player.MemoryStats(); stats != (music.MemoryStats{SearchCacheCapacity: stats.SearchCacheCapacity}) {
+		t.Errorf("Expected every map to be empty after the bot left the guild, got %+v", stats)
+	}
+	if guildIDs := player.GuildIDs(); len(guildIDs) != 0 {
+		t.Errorf("Expected no guilds tracked after cleanup, got %v", guildIDs)
+	}
+}
@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
+)
+
+// Limits on what is forwarded to the owner log channel, so an error loop can't flood it or get the bot
+// rate limited
+const (
+	logChannelBurst      = 5                // Messages per logChannelWindow
+	logChannelWindow     = time.Minute      // Window of the rate limit
+	logChannelDedupe     = 10 * time.Minute // Repeats of an error within this are counted, not sent
+	logChannelQueue      = 64               // Errors waiting to be sent before new ones are dropped
+	logChannelMaxMessage = 1800             // Longest error text in a message, Discord allows 2000 characters
+)
+
+// logChannelSink forwards errors to a Discord channel. Repeats of the same error are folded into a count
+// on its next report and errors past the rate limit are only counted.
+type logChannelSink struct {
+	send    func(content string) error
+	entries chan utils.LogEntry
+	done    chan struct{}
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time // When each error was last sent, by module and message
+	repeats  map[string]int       // Repeats of each error held back since it was last sent
+	sent     []time.Time          // Sends within the rate limit window
+	dropped  int                  // Errors dropped by the rate limit or a full queue since the last send
+}
+
+// newLogChannelSink creates a sink that sends its messages through send
+func newLogChannelSink(send func(content string) error) *logChannelSink {
+	return &logChannelSink{
+		send:     send,
+		entries:  make(chan utils.LogEntry, logChannelQueue),
+		done:     make(chan struct{}),
+		lastSent: make(map[string]time.Time),
+		repeats:  make(map[string]int),
+	}
+}
+
+// Log queues errors for sending without blocking the goroutine that logged them; warnings are left out
+func (l *logChannelSink) Log(entry utils.LogEntry) {
+	if entry.Level != utils.LogLevelError {
+		return
+	}
+	select {
+	case l.entries <- entry:
+	default:
+		l.mu.Lock()
+		l.dropped++
+		l.mu.Unlock()
+	}
+}
+
+// run sends queued errors until close
+func (l *logChannelSink) run() {
+	for {
+		select {
+		case entry := <-l.entries:
+			content, ok := l.admit(entry, time.Now())
+			if !ok {
+				continue
+			}
+			if err := l.send(content); err != nil {
+				// Logged as a warning so the failure isn't forwarded back here
+				utils.LogWarn("Failed to send an error to the log channel: %v", err)
+			}
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// close stops sending; errors still queued are dropped
+func (l *logChannelSink) close() {
+	close(l.done)
+}
+
+// admit applies deduplication and the rate limit to an error and returns the message to send for it
+func (l *logChannelSink) admit(entry utils.LogEntry, now time.Time) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := entry.Module + "\x00" + entry.Message
+	if last, seen := l.lastSent[key]; seen && now.Sub(last) < logChannelDedupe {
+		l.repeats[key]++
+		return "", false
+	}
+
+	recent := l.sent[:0]
+	for _, at := range l.sent {
+		if now.Sub(at) < logChannelWindow {
+			recent = append(recent, at)
+		}
+	}
+	l.sent = recent
+	if len(l.sent) >= logChannelBurst {
+		l.dropped++
+		return "", false
+	}
+
+	content := formatLogChannelMessage(entry, l.repeats[key], l.dropped)
+	l.sent = append(l.sent, now)
+	l.lastSent[key] = now
+	delete(l.repeats, key)
+	l.dropped = 0
+
+	// Forget errors old enough to be sent again, with their held back repeats
+	for other, last := range l.lastSent {
+		if now.Sub(last) >= logChannelDedupe {
+			delete(l.lastSent, other)
+			delete(l.repeats, other)
+		}
+	}
+	return content, true
+}
+
+// formatLogChannelMessage renders an error with the repeats held back since it was last sent and the
+// errors dropped since the previous message
+func formatLogChannelMessage(entry utils.LogEntry, repeats, dropped int) string {
+	message := strings.ReplaceAll(entry.Message, "```", "'''")
+	if len(message) > logChannelMaxMessage {
+		message = message[:logChannelMaxMessage] + "…"
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "🚨 **Error** in `%s` <t:%d:T>\n```\n%s\n```", entry.Module, entry.Time.Unix(), message)
+	if !entry.Request.IsZero() {
+		fmt.Fprintf(&content, "\nWhile handling `%s`", entry.Request)
+	}
+	if repeats > 0 {
+		fmt.Fprintf(&content, "\nRepeated %d more times since it was last reported", repeats)
+	}
+	if dropped > 0 {
+		fmt.Fprintf(&content, "\n%d other errors were not sent because of the rate limit, see `/admin logs`", dropped)
+	}
+	return content.String()
+}
+
+// startLogChannel forwards errors to LOG_CHANNEL_ID when it is set
+func (b *Bot) startLogChannel() {
+	channelID := strings.TrimSpace(os.Getenv("LOG_CHANNEL_ID"))
+	if channelID == "" {
+		return
+	}
+
+	sink := newLogChannelSink(func(content string) error {
+		_, err := b.Session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+			Content:         content,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+		return err
+	})
+	removeSink := utils.AddLogSink(sink)
+	utils.SafeGo("bot.logChannel", sink.run)
+
+	b.stopLogChannel = func() {
+		removeSink()
+		sink.close()
+	}
+	utils.LogInfo("Forwarding errors to log channel %s", channelID)
+}
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+func TestLogChannelDeduplicates(t *testing.T) {
+	sink := newLogChannelSink(nil)
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	entry := utils.LogEntry{Time: now, Level: utils.LogLevelError, Module: "music", Message: "ffmpeg failed"}
+
+	if _, ok := sink.admit(entry, now); !ok {
+		t.Fatal("admit() held back the first report of an error")
+	}
+	for range 3 {
+		if _, ok := sink.admit(entry, now.Add(time.Minute)); ok {
+			t.Error("admit() sent a repeat within the dedupe window")
+		}
+	}
+
+	content, ok := sink.admit(entry, now.Add(logChannelDedupe))
+	if !ok {
+		t.Fatal("admit() held back an error after the dedupe window")
+	}
+	if !strings.Contains(content, "Repeated 3 more times") {
+		t.Errorf("admit() = %q, want the held back repeats counted", content)
+	}
+}
+
+func TestLogChannelRateLimit(t *testing.T) {
+	sink := newLogChannelSink(nil)
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	errorNumber := func(n int) utils.LogEntry {
+		return utils.LogEntry{Time: now, Level: utils.LogLevelError, Module: "music", Message: strings.Repeat("x", n)}
+	}
+
+	for n := 1; n <= logChannelBurst; n++ {
+		if _, ok := sink.admit(errorNumber(n), now); !ok {
+			t.Fatalf("admit() held back error %d within the burst", n)
+		}
+	}
+	if _, ok := sink.admit(errorNumber(logChannelBurst+1), now); ok {
+		t.Error("admit() sent past the rate limit")
+	}
+
+	content, ok := sink.admit(errorNumber(logChannelBurst+2), now.Add(logChannelWindow))
+	if !ok {
+		t.Fatal("admit() held back an error after the rate limit window")
+	}
+	if !strings.Contains(content, "1 other errors were not sent") {
+		t.Errorf("admit() = %q, want the dropped error counted", content)
+	}
+}
+
+func TestLogChannelOnlyQueuesErrors(t *testing.T) {
+	sink := newLogChannelSink(nil)
+	sink.Log(utils.LogEntry{Level: utils.LogLevelWarn, Message: "slow"})
+	if len(sink.entries) != 0 {
+		t.Error("Log() queued a warning")
+	}
+
+	for range logChannelQueue + 2 {
+		sink.Log(utils.LogEntry{Level: utils.LogLevelError, Message: "failed"})
+	}
+	if len(sink.entries) != logChannelQueue || sink.dropped != 2 {
+		t.Errorf("Log() queued %d and dropped %d, want %d queued and 2 dropped", len(sink.entries), sink.dropped, logChannelQueue)
+	}
+}
+
+func TestFormatLogChannelMessage(t *testing.T) {
+	entry := utils.LogEntry{Time: time.Unix(1700000000, 0), Level: utils.LogLevelError, Module: "commands", Message: "bad ``` input"}
+	content := formatLogChannelMessage(entry, 0, 0)
+
+	want := "🚨 **Error** in `commands` <t:1700000000:T>\n```\nbad ''' input\n```"
+	if content != want {
+		t.Errorf("formatLogChannelMessage() = %q, want %q", content, want)
+	}
+
+	entry.Request = utils.RequestInfo{Command: "play", GuildID: "123"}
+	content = formatLogChannelMessage(entry, 0, 0)
+	if !strings.HasSuffix(content, "\nWhile handling `command=play guild=123`") {
+		t.Errorf("formatLogChannelMessage() = %q, want the request it was logged for", content)
+	}
+
+	long := formatLogChannelMessage(utils.LogEntry{Message: strings.Repeat("x", 3000)}, 0, 0)
+	if len(long) > 2000 {
+		t.Errorf("formatLogChannelMessage() is %d characters, over Discord's limit", len(long))
+	}
+}
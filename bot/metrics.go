@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/metrics"
+	"pxnx-discord-bot/utils"
+)
+
+// outcomeRefused labels commands answered without running, because of the server's tier or invalid options
+const outcomeRefused = "refused"
+
+// defaultMetricsPath is where Prometheus scrapes the metrics unless METRICS_PATH says otherwise
+const defaultMetricsPath = "/metrics"
+
+// observeInteraction counts a handled command or component and how long it took
+func observeInteraction(name, outcome string, start time.Time) {
+	metrics.CommandInvocations.Inc(name, outcome)
+	metrics.CommandDuration.Observe(metrics.Since(start), name)
+}
+
+// gatewayEvent counts every event the gateway sends, by type
+func (b *Bot) gatewayEvent(s *discordgo.Session, event *discordgo.Event) {
+	if event.Type != "" {
+		metrics.GatewayEvents.Inc(event.Type)
+	}
+}
+
+// metricsHandler serves the bot's metrics on path
+func metricsHandler(path string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(path, metrics.Default.Handler())
+	return mux
+}
+
+// startMetrics serves Prometheus metrics on METRICS_ADDR, such as ":9090", at METRICS_PATH (/metrics by
+// default); it is off when METRICS_ADDR is unset
+func (b *Bot) startMetrics() {
+	addr := strings.TrimSpace(os.Getenv("METRICS_ADDR"))
+	if addr == "" {
+		return
+	}
+	path := strings.TrimSpace(os.Getenv("METRICS_PATH"))
+	if path == "" {
+		path = defaultMetricsPath
+	}
+	if !strings.HasPrefix(path, "/") {
+		utils.LogWarn("Ignoring METRICS_PATH %q, it must start with /", path)
+		path = defaultMetricsPath
+	}
+
+	if player := commands.SimplePlayer; player != nil {
+		metrics.VoiceConnections.Track(func() float64 { return float64(player.MemoryStats().Players) })
+		metrics.QueuedTracks.Track(func() float64 { return float64(player.MemoryStats().QueuedTracks) })
+	}
+
+	stop, err := serveHTTP("Prometheus metrics", addr, metricsHandler(path))
+	if err != nil {
+		utils.LogError("Metrics disabled, failed to listen on %s: %v", addr, err)
+		return
+	}
+	b.stopMetrics = stop
+}
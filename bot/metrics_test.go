@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/metrics"
+)
+
+func TestObserveInteraction(t *testing.T) {
+	before := metrics.CommandInvocations.Value("metrics-test", metrics.OutcomeOK)
+	count := metrics.CommandDuration.Count("metrics-test")
+
+	observeInteraction("metrics-test", metrics.OutcomeOK, time.Now())
+
+	if got := metrics.CommandInvocations.Value("metrics-test", metrics.OutcomeOK); got != before+1 {
+		t.Errorf("invocations = %v, want %v", got, before+1)
+	}
+	if got := metrics.CommandDuration.Count("metrics-test"); got != count+1 {
+		t.Errorf("durations observed = %d, want %d", got, count+1)
+	}
+}
+
+func TestGatewayEventCountsByType(t *testing.T) {
+	b := &Bot{}
+	before := metrics.GatewayEvents.Value("GUILD_CREATE")
+
+	b.gatewayEvent(nil, &discordgo.Event{Type: "GUILD_CREATE"})
+	b.gatewayEvent(nil, &discordgo.Event{})
+
+	if got := metrics.GatewayEvents.Value("GUILD_CREATE"); got != before+1 {
+		t.Errorf("GUILD_CREATE events = %v, want %v", got, before+1)
+	}
+	if got := metrics.GatewayEvents.Value(""); got != 0 {
+		t.Errorf("events without a type = %v, want them ignored", got)
+	}
+}
+
+func TestMetricsHandlerServesPath(t *testing.T) {
+	handler := metricsHandler("/internal/metrics")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/internal/metrics", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "pxnx_command_invocations_total") {
+		t.Errorf("GET /internal/metrics = %d %q, want the bot's metrics", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics = %d, want 404 when served on another path", recorder.Code)
+	}
+}
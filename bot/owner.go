@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"os"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/utils"
+)
+
+// loadBotOwners decides who may run owner-only commands: BOT_OWNER_IDS when set, otherwise the
+// application owner or the members of the team that owns it
+func (b *Bot) loadBotOwners() {
+	if owners := parseOwnerIDs(os.Getenv("BOT_OWNER_IDS")); len(owners) > 0 {
+		commands.SetBotOwners(owners)
+		return
+	}
+
+	application, err := b.Session.Application("@me")
+	if err != nil {
+		utils.LogWarn("Could not look up the bot owner, owner-only commands are disabled: %v", err)
+		return
+	}
+	commands.SetBotOwners(applicationOwnerIDs(application))
+}
+
+// parseOwnerIDs splits a comma separated list of user IDs
+func parseOwnerIDs(raw string) []string {
+	var owners []string
+	for _, userID := range strings.Split(raw, ",") {
+		if userID = strings.TrimSpace(userID); userID != "" {
+			owners = append(owners, userID)
+		}
+	}
+	return owners
+}
+
+// applicationOwnerIDs returns the users that own an application, every team member for team-owned applications
+func applicationOwnerIDs(application *discordgo.Application) []string {
+	var owners []string
+	if application.Team != nil {
+		for _, member := range application.Team.Members {
+			if member.User != nil {
+				owners = append(owners, member.User.ID)
+			}
+		}
+		return owners
+	}
+	if application.Owner != nil {
+		owners = append(owners, application.Owner.ID)
+	}
+	return owners
+}
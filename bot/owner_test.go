@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseOwnerIDs(t *testing.T) {
+	owners := parseOwnerIDs(" 123, ,456 ")
+	if !reflect.DeepEqual(owners, []string{"123", "456"}) {
+		t.Errorf("parseOwnerIDs() = %v, want [123 456]", owners)
+	}
+	if owners := parseOwnerIDs(""); len(owners) != 0 {
+		t.Errorf("parseOwnerIDs(\"\") = %v, want none", owners)
+	}
+}
+
+func TestApplicationOwnerIDs(t *testing.T) {
+	single := &discordgo.Application{Owner: &discordgo.User{ID: "owner"}}
+	if owners := applicationOwnerIDs(single); !reflect.DeepEqual(owners, []string{"owner"}) {
+		t.Errorf("applicationOwnerIDs() = %v, want [owner]", owners)
+	}
+
+	team := &discordgo.Application{
+		Owner: &discordgo.User{ID: "team_user"},
+		Team: &discordgo.Team{Members: []*discordgo.TeamMember{
+			{User: &discordgo.User{ID: "alice"}},
+			{User: &discordgo.User{ID: "bob"}},
+		}},
+	}
+	if owners := applicationOwnerIDs(team); !reflect.DeepEqual(owners, []string{"alice", "bob"}) {
+		t.Errorf("applicationOwnerIDs() = %v, want team members", owners)
+	}
+}
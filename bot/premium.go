@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/utils"
+)
+
+// entitlementPageSize is the most entitlements Discord returns per request
+const entitlementPageSize = 100
+
+// loadEntitlements fetches the active guild subscriptions to the premium SKU, so servers keep premium
+// across restarts before their next interaction reports it
+func loadEntitlements(s *discordgo.Session, applicationID string) {
+	if commands.SimplePlayer == nil {
+		return
+	}
+	entitlements := commands.SimplePlayer.Premium()
+	if !entitlements.Enabled() || entitlements.SKUID() == "" {
+		return
+	}
+
+	active, err := s.Entitlements(applicationID, &discordgo.EntitlementFilterOptions{
+		SkuIDs:       []string{entitlements.SKUID()},
+		ExcludeEnded: true,
+		Limit:        entitlementPageSize,
+	})
+	if err != nil {
+		utils.LogWarn("Failed to load premium subscriptions, servers regain premium on their next command: %v", err)
+		return
+	}
+	if len(active) == entitlementPageSize {
+		utils.LogWarn("Loaded the first %d premium subscriptions, the rest regain premium on their next command", entitlementPageSize)
+	}
+
+	for _, entitlement := range active {
+		commands.ApplyEntitlement(entitlement, false)
+	}
+	utils.LogInfo("Loaded %d premium subscriptions", len(active))
+}
+
+// entitlementCreate handles new premium subscriptions
+func (b *Bot) entitlementCreate(s *discordgo.Session, event *discordgo.EntitlementCreate) {
+	commands.ApplyEntitlement(event.Entitlement, false)
+}
+
+// entitlementUpdate handles renewed or cancelled subscriptions, which carry their end date
+func (b *Bot) entitlementUpdate(s *discordgo.Session, event *discordgo.EntitlementUpdate) {
+	commands.ApplyEntitlement(event.Entitlement, false)
+}
+
+// entitlementDelete handles refunded or removed subscriptions
+func (b *Bot) entitlementDelete(s *discordgo.Session, event *discordgo.EntitlementDelete) {
+	commands.ApplyEntitlement(event.Entitlement, true)
+}
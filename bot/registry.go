@@ -0,0 +1,248 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/metrics"
+	"pxnx-discord-bot/utils"
+)
+
+// Handler runs a slash command
+type Handler func(s commands.SessionInterface, i *discordgo.InteractionCreate) error
+
+// Command is a slash command: what Discord shows, what runs it and how the registry runs it
+type Command struct {
+	Definition  *discordgo.ApplicationCommand
+	Handler     Handler
+	Permissions int64         // Permissions members need, also hiding the command from others by default
+	Cooldown    time.Duration // Least time between uses by one member, 0 for none
+	Slow        bool          // May take longer than Discord waits, acknowledged when the handler hasn't answered in time
+}
+
+// Middleware wraps the handler of a command with what every command shares. It runs once per command when
+// the registry is built and may return next unchanged when it doesn't apply to the command.
+type Middleware func(command Command, next Handler) Handler
+
+// errRefused is returned by middleware that answered a command instead of running it
+var errRefused = errors.New("command refused")
+
+// refuse returns errRefused along with any error answering the refused command
+func refuse(err error) error {
+	if err == nil {
+		return errRefused
+	}
+	return fmt.Errorf("%w: %w", errRefused, err)
+}
+
+// Registry routes slash commands to their handlers through a middleware chain built for each command
+type Registry struct {
+	commands map[string]Command
+	handlers map[string]Handler
+	order    []string
+}
+
+// NewRegistry registers commands behind middleware, the first middleware outermost
+func NewRegistry(list []Command, middleware ...Middleware) *Registry {
+	r := &Registry{commands: make(map[string]Command, len(list)), handlers: make(map[string]Handler, len(list))}
+	for _, command := range list {
+		name := command.Definition.Name
+		if _, exists := r.commands[name]; exists {
+			panic(fmt.Sprintf("command /%s is registered twice", name))
+		}
+		if command.Permissions != 0 {
+			permissions := command.Permissions
+			command.Definition.DefaultMemberPermissions = &permissions
+		}
+
+		handler := command.Handler
+		for n := len(middleware) - 1; n >= 0; n-- {
+			handler = middleware[n](command, handler)
+		}
+		r.commands[name] = command
+		r.handlers[name] = handler
+		r.order = append(r.order, name)
+	}
+	return r
+}
+
+// Command returns a registered command by name
+func (r *Registry) Command(name string) (Command, bool) {
+	command, exists := r.commands[name]
+	return command, exists
+}
+
+// Definitions returns the definitions of every command, in the order they were registered
+func (r *Registry) Definitions() []*discordgo.ApplicationCommand {
+	definitions := make([]*discordgo.ApplicationCommand, len(r.order))
+	for n, name := range r.order {
+		definitions[n] = r.commands[name].Definition
+	}
+	return definitions
+}
+
+// Handle runs the command of an interaction through its middleware. Commands refused by middleware were
+// answered and aren't errors, unless answering them failed.
+func (r *Registry) Handle(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+	name := i.ApplicationCommandData().Name
+	handler, exists := r.handlers[name]
+	if !exists {
+		utils.LogWarn("Received unknown command '%s', are the registered commands out of date?", name)
+		return nil
+	}
+	if err := handler(s, i); err != errRefused {
+		return err
+	}
+	return nil
+}
+
+var (
+	registry     *Registry
+	registryOnce sync.Once
+)
+
+// commandRegistry returns the registry of the bot's commands
+func commandRegistry() *Registry {
+	registryOnce.Do(func() {
+		registry = NewRegistry(localizeCommands(botCommands()),
+			observeCommand,
+			recoverCommand,
+			logCommandErrors,
+			checkPremium,
+			checkOptions,
+			checkPermissions,
+			checkCooldown,
+			deferSlowCommand,
+		)
+	})
+	return registry
+}
+
+// HandleCommand runs the command of an interaction the way the bot does, through every middleware
+func HandleCommand(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+	return commandRegistry().Handle(s, i)
+}
+
+// observeCommand traces and counts every command, outermost so refusals and panics are seen too
+func observeCommand(command Command, next Handler) Handler {
+	name := command.Definition.Name
+	return func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+		start := time.Now()
+		// The span covers the whole command, the player and services continue it through RequestContext
+		endTrace := commands.TraceInteraction(i, "/"+name)
+		err := next(s, i)
+
+		outcome, traced := metrics.OutcomeOf(err), err
+		if errors.Is(err, errRefused) {
+			outcome = outcomeRefused
+			if err == errRefused {
+				traced = nil
+			}
+		}
+		observeInteraction(name, outcome, start)
+		endTrace(outcome, traced)
+		return err
+	}
+}
+
+// recoverCommand turns a panicking command into an error instead of crashing the bot
+func recoverCommand(command Command, next Handler) Handler {
+	name := command.Definition.Name
+	return func(s commands.SessionInterface, i *discordgo.InteractionCreate) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				utils.RecordPanic("command /"+name, recovered)
+				err = fmt.Errorf("command /%s panicked: %v", name, recovered)
+			}
+		}()
+		return next(s, i)
+	}
+}
+
+// logCommandErrors logs the errors of commands, and of answering refused ones
+func logCommandErrors(command Command, next Handler) Handler {
+	name := command.Definition.Name
+	return func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+		err := next(s, i)
+		if err != nil && err != errRefused {
+			utils.LogErrorContext(commands.RequestContext(i), "Error handling command '%s': %v", name, err)
+		}
+		return err
+	}
+}
+
+// checkPremium answers commands the server's tier doesn't include instead of running them
+func checkPremium(command Command, next Handler) Handler {
+	return func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+		if allowed, err := commands.CheckPremium(s, i); !allowed {
+			return refuse(err)
+		}
+		return next(s, i)
+	}
+}
+
+// checkOptions answers option values outside the command's ranges, lengths, choices or formats
+func checkOptions(command Command, next Handler) Handler {
+	return func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+		if valid, err := commands.CheckOptions(s, i, command.Definition); !valid {
+			return refuse(err)
+		}
+		return next(s, i)
+	}
+}
+
+// checkPermissions answers members without the command's permissions. Discord hides such commands by
+// default, this covers servers that changed who sees them.
+func checkPermissions(command Command, next Handler) Handler {
+	if command.Permissions == 0 {
+		return next
+	}
+	return func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+		if allowed, err := commands.CheckMemberPermissions(s, i, command.Permissions); !allowed {
+			return refuse(err)
+		}
+		return next(s, i)
+	}
+}
+
+// checkCooldown answers members using a command again before its cooldown passed
+func checkCooldown(command Command, next Handler) Handler {
+	if command.Cooldown <= 0 {
+		return next
+	}
+	return func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+		if allowed, err := commands.CheckCooldown(s, i, command.Cooldown); !allowed {
+			return refuse(err)
+		}
+		return next(s, i)
+	}
+}
+
+// deferSlowCommand acknowledges slow commands that haven't answered by commands.AutoDeferAfter, their
+// handlers respond or edit the same way whether or not that happened
+func deferSlowCommand(command Command, next Handler) Handler {
+	if !command.Slow {
+		return next
+	}
+	return func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+		session := commands.AutoDefer(s, i, commands.AutoDeferAfter)
+		err := next(session, i)
+		if deferErr := session.Stop(); err == nil {
+			err = deferErr
+		}
+		return err
+	}
+}
+
+// handlePeepee adds the emoji reaction to /peepee, which needs the discordgo session behind the wrapper
+func handlePeepee(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+	if wrapper, ok := s.(*SimpleSessionWrapper); ok {
+		return commands.HandlePeepeeCommandWithReaction(wrapper.session, i)
+	}
+	return commands.HandlePeepeeCommand(s, i)
+}
@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/testutils"
+)
+
+// testCommand creates a command that records that it ran
+func testCommand(name string, ran *bool) Command {
+	return Command{
+		Definition: &discordgo.ApplicationCommand{Name: name, Description: "Test command"},
+		Handler: func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+			*ran = true
+			return nil
+		},
+	}
+}
+
+func TestRegistryMiddlewareOrder(t *testing.T) {
+	var calls []string
+	trace := func(label string) Middleware {
+		return func(command Command, next Handler) Handler {
+			return func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+				calls = append(calls, label)
+				return next(s, i)
+			}
+		}
+	}
+
+	var ran bool
+	registry := NewRegistry([]Command{testCommand("test", &ran)}, trace("outer"), trace("inner"))
+	if err := registry.Handle(&testutils.MockSession{}, testutils.CreateTestInteraction("test", nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !ran {
+		t.Error("Expected the handler to run")
+	}
+	if !reflect.DeepEqual(calls, []string{"outer", "inner"}) {
+		t.Errorf("Expected the first middleware outermost, got %v", calls)
+	}
+
+	// Unknown commands are ignored
+	if err := registry.Handle(&testutils.MockSession{}, testutils.CreateTestInteraction("unknown", nil)); err != nil {
+		t.Errorf("Expected unknown commands to be ignored, got %v", err)
+	}
+}
+
+func TestRegistryDefinitions(t *testing.T) {
+	var ran bool
+	admin := testCommand("admin", &ran)
+	admin.Permissions = discordgo.PermissionAdministrator
+	registry := NewRegistry([]Command{testCommand("first", &ran), admin})
+
+	definitions := registry.Definitions()
+	if len(definitions) != 2 || definitions[0].Name != "first" || definitions[1].Name != "admin" {
+		t.Fatalf("Expected the definitions in registration order, got %v", definitions)
+	}
+	if definitions[0].DefaultMemberPermissions != nil {
+		t.Error("Expected commands without permissions to be available to everyone")
+	}
+	if permissions := definitions[1].DefaultMemberPermissions; permissions == nil || *permissions != discordgo.PermissionAdministrator {
+		t.Errorf("Expected the command's permissions as its default member permissions, got %v", permissions)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a command twice to panic")
+		}
+	}()
+	NewRegistry([]Command{testCommand("twice", &ran), testCommand("twice", &ran)})
+}
+
+func TestRegistryRecoversPanics(t *testing.T) {
+	command := Command{
+		Definition: &discordgo.ApplicationCommand{Name: "panics"},
+		Handler: func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+			panic("boom")
+		},
+	}
+	registry := NewRegistry([]Command{command}, recoverCommand)
+
+	err := registry.Handle(&testutils.MockSession{}, testutils.CreateTestInteraction("panics", nil))
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected the panic as an error, got %v", err)
+	}
+}
+
+func TestRegistryRefusals(t *testing.T) {
+	var ran bool
+	command := testCommand("manage", &ran)
+	command.Permissions = discordgo.PermissionManageGuild
+	registry := NewRegistry([]Command{command}, logCommandErrors, checkPermissions)
+
+	interaction := testutils.CreateTestInteraction("manage", nil)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser("member_1", "member", ""))
+	mockSession := &testutils.MockSession{}
+	if err := registry.Handle(mockSession, interaction); err != nil {
+		t.Errorf("Expected answered refusals not to be errors, got %v", err)
+	}
+	if ran {
+		t.Error("Expected the handler not to run for members without the permissions")
+	}
+	if mockSession.RespondData == nil || !strings.Contains(mockSession.RespondData.Content, "Manage Server") {
+		t.Errorf("Expected the missing permission to be named, got %+v", mockSession.RespondData)
+	}
+
+	// Failing to answer a refusal is an error
+	mockSession = &testutils.MockSession{RespondError: errors.New("unknown interaction")}
+	if err := registry.Handle(mockSession, interaction); !errors.Is(err, errRefused) {
+		t.Errorf("Expected a refusal error, got %v", err)
+	}
+
+	interaction.Member.Permissions = discordgo.PermissionManageGuild
+	if err := registry.Handle(&testutils.MockSession{}, interaction); err != nil || !ran {
+		t.Errorf("Expected the handler to run for members with the permissions, got %v", err)
+	}
+}
+
+func TestRegistrySlowCommands(t *testing.T) {
+	command := Command{
+		Definition: &discordgo.ApplicationCommand{Name: "slow"},
+		Slow:       true,
+		Handler: func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+			content := "done"
+			_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &content})
+			return err
+		},
+	}
+	registry := NewRegistry([]Command{command}, deferSlowCommand)
+
+	// A slow command answering in time responds with its edit instead of being deferred
+	mockSession := &testutils.MockSession{}
+	if err := registry.Handle(mockSession, testutils.CreateTestInteraction("slow", nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mockSession.RespondType != discordgo.InteractionResponseChannelMessageWithSource || mockSession.RespondData.Content != "done" {
+		t.Errorf("Expected the edit to be sent as the response, got %v %+v", mockSession.RespondType, mockSession.RespondData)
+	}
+	if mockSession.InteractionResponseEditCalled {
+		t.Error("Expected no edit before anything was sent")
+	}
+}
+
+func TestRegistryCooldown(t *testing.T) {
+	var runs int
+	command := Command{
+		Definition: &discordgo.ApplicationCommand{Name: "registry_cooldown"},
+		Cooldown:   time.Minute,
+		Handler: func(s commands.SessionInterface, i *discordgo.InteractionCreate) error {
+			runs++
+			return nil
+		},
+	}
+	registry := NewRegistry([]Command{command}, checkCooldown)
+
+	interaction := testutils.CreateTestInteraction("registry_cooldown", nil)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser("member_1", "member", ""))
+	for range 2 {
+		if err := registry.Handle(&testutils.MockSession{}, interaction); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("Expected the command to run once within its cooldown, ran %d times", runs)
+	}
+}
+
+func TestBotCommandsRegistered(t *testing.T) {
+	for _, name := range []string{"ping", "music", "support"} {
+		if _, exists := commandRegistry().Command(name); !exists {
+			t.Errorf("Expected /%s to be registered", name)
+		}
+	}
+	if command, _ := commandRegistry().Command("music"); !command.Slow {
+		t.Errorf("Expected /music to be slow, got %+v", command)
+	}
+}
@@ -0,0 +1,173 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/music/download"
+	"pxnx-discord-bot/scheduler"
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/utils"
+)
+
+// Kinds of the jobs the bot schedules itself
+const (
+	guildReconcileJob = "guild-reconciliation"
+	downloadPruneJob  = "download-cleanup"
+	ytdlpUpdateJob    = "ytdlp-update"
+	ytdlpMetricsJob   = "ytdlp-metrics"
+	ytdlpHealthJob    = "ytdlp-health"
+	backupJob         = "backup"
+)
+
+// ytdlpMetricsInterval is how often the yt-dlp service's metrics are polled
+const ytdlpMetricsInterval = time.Minute
+
+// ytdlpHealthInterval is how often the health of a yt-dlp service extractions go to is checked
+const ytdlpHealthInterval = 30 * time.Second
+
+// SchedulerPath returns where scheduled jobs are saved, SCHEDULER_FILE or the default
+func SchedulerPath() string {
+	if path := strings.TrimSpace(os.Getenv("SCHEDULER_FILE")); path != "" {
+		return path
+	}
+	return scheduler.DefaultPath
+}
+
+// startScheduler loads the saved jobs, registers the bot's periodic maintenance and starts running jobs
+func (b *Bot) startScheduler() {
+	jobs, err := scheduler.Load(SchedulerPath())
+	if err != nil {
+		utils.LogWarn("Scheduled jobs will not be saved this run: %v", err)
+		jobs = scheduler.New()
+	}
+
+	jobs.Handle(guildReconcileJob, func(ctx context.Context, job scheduler.Job) error {
+		b.reconcileGuilds()
+		return nil
+	})
+	b.addJob(jobs, scheduler.Job{
+		ID:   guildReconcileJob,
+		Kind: guildReconcileJob,
+		Spec: fmt.Sprintf("@every %s", guildReconcileInterval),
+	})
+	b.scheduleBackups(jobs)
+
+	if b.IntentConfig.Music {
+		// Tracks are removed after playing, this catches the ones a crash left behind
+		jobs.Handle(downloadPruneJob, func(ctx context.Context, job scheduler.Job) error {
+			removed, err := download.PruneStale(download.DefaultDir(), download.StaleAge)
+			if removed > 0 {
+				utils.LogInfo("Removed %d stale downloaded tracks", removed)
+			}
+			return err
+		})
+		b.addJob(jobs, scheduler.Job{
+			ID:      downloadPruneJob,
+			Kind:    downloadPruneJob,
+			Spec:    "@hourly",
+			Jitter:  5 * time.Minute,
+			CatchUp: true,
+		})
+		b.scheduleYtdlpUpdates(jobs)
+		b.scheduleYtdlpMetrics(jobs)
+		b.scheduleYtdlpHealth(jobs)
+	}
+
+	jobs.Start()
+	b.scheduler = jobs
+}
+
+// scheduleYtdlpUpdates keeps yt-dlp current on the YTDLP_AUTO_UPDATE schedule, installing with
+// YTDLP_UPDATE_METHOD. Extractions run the binary directly, so an update applies from the next one.
+func (b *Bot) scheduleYtdlpUpdates(jobs *scheduler.Scheduler) {
+	spec := strings.TrimSpace(os.Getenv("YTDLP_AUTO_UPDATE"))
+	if spec == "" {
+		return
+	}
+	method := strings.TrimSpace(os.Getenv("YTDLP_UPDATE_METHOD"))
+	if method == "" {
+		method = ytdlp.UpdateBinary
+	}
+	updater, err := ytdlp.NewUpdater(method, ytdlp.DefaultServiceConfig().BinaryPath)
+	if err != nil {
+		utils.LogWarn("yt-dlp will not be updated automatically: %v", err)
+		return
+	}
+
+	jobs.Handle(ytdlpUpdateJob, func(ctx context.Context, job scheduler.Job) error {
+		check, err := updater.Run(ctx)
+		if err != nil {
+			return err
+		}
+		if check.Updated {
+			utils.LogInfo("Updated yt-dlp to %s", check.Installed)
+		} else {
+			utils.LogDebug("yt-dlp %s is the latest release", check.Installed)
+		}
+		return nil
+	})
+	b.addJob(jobs, scheduler.Job{
+		ID:      ytdlpUpdateJob,
+		Kind:    ytdlpUpdateJob,
+		Spec:    spec,
+		Jitter:  30 * time.Minute,
+		CatchUp: true,
+	})
+}
+
+// scheduleYtdlpMetrics polls the yt-dlp service at YTDLP_SERVICE_URL, for /music diag and warnings when
+// it starts failing
+func (b *Bot) scheduleYtdlpMetrics(jobs *scheduler.Scheduler) {
+	poller := commands.YtdlpMetrics
+	if poller == nil {
+		return
+	}
+
+	jobs.Handle(ytdlpMetricsJob, func(ctx context.Context, job scheduler.Job) error {
+		// Failures are logged by the poller when the service goes away, not on every poll
+		poller.Poll(ctx)
+		return nil
+	})
+	b.addJob(jobs, scheduler.Job{
+		ID:   ytdlpMetricsJob,
+		Kind: ytdlpMetricsJob,
+		Spec: fmt.Sprintf("@every %s", ytdlpMetricsInterval),
+	})
+	utils.SafeGo("ytdlp.metrics", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		poller.Poll(ctx)
+	})
+}
+
+// scheduleYtdlpHealth checks the yt-dlp service extractions go to, so its circuit breaker closes again
+// once it recovers and extractions move back to it from the binary
+func (b *Bot) scheduleYtdlpHealth(jobs *scheduler.Scheduler) {
+	failover := commands.YtdlpFailover
+	if failover == nil {
+		return
+	}
+
+	jobs.Handle(ytdlpHealthJob, func(ctx context.Context, job scheduler.Job) error {
+		// Switching between the service and the binary is logged by the provider
+		failover.CheckHealth(ctx)
+		return nil
+	})
+	b.addJob(jobs, scheduler.Job{
+		ID:   ytdlpHealthJob,
+		Kind: ytdlpHealthJob,
+		Spec: fmt.Sprintf("@every %s", ytdlpHealthInterval),
+	})
+}
+
+// addJob schedules one of the bot's own jobs, logging instead of failing startup
+func (b *Bot) addJob(jobs *scheduler.Scheduler, job scheduler.Job) {
+	if err := jobs.Add(job); err != nil {
+		utils.LogWarn("Failed to schedule %s: %v", job.ID, err)
+	}
+}
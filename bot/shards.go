@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/utils"
+)
+
+// identifyInterval is how long Discord wants between identifies of the same rate limit bucket
+const identifyInterval = 5 * time.Second
+
+// ShardConfig chooses the gateway shards of the bot and which of them this process runs
+type ShardConfig struct {
+	Count int // Shards of the whole bot, 0 for Discord's recommendation
+	ID    int // The one shard this process runs when the shards are spread over processes, -1 for all of them
+}
+
+// LoadShardConfig reads SHARD_COUNT ("auto" or a number) and SHARD_ID, warning about invalid values. A
+// process given a SHARD_ID runs that shard alone, which needs a fixed SHARD_COUNT shared by every process.
+func LoadShardConfig() ShardConfig {
+	config := ShardConfig{ID: -1}
+	if raw := strings.TrimSpace(os.Getenv("SHARD_COUNT")); raw != "" && !strings.EqualFold(raw, "auto") {
+		count, err := strconv.Atoi(raw)
+		if err != nil || count < 1 {
+			utils.LogWarn("Ignoring invalid value %q for SHARD_COUNT, using Discord's recommendation", raw)
+		} else {
+			config.Count = count
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("SHARD_ID")); raw != "" {
+		id, err := strconv.Atoi(raw)
+		switch {
+		case config.Count == 0:
+			utils.LogWarn("Ignoring SHARD_ID without a SHARD_COUNT, running every shard in this process")
+		case err != nil || id < 0 || id >= config.Count:
+			utils.LogWarn("Ignoring invalid value %q for SHARD_ID, expected 0 to %d", raw, config.Count-1)
+		default:
+			config.ID = id
+		}
+	}
+	return config
+}
+
+// shardIDs returns the shards a process runs out of count
+func (c ShardConfig) shardIDs(count int) []int {
+	if c.ID >= 0 {
+		return []int{c.ID}
+	}
+	ids := make([]int, count)
+	for n := range ids {
+		ids[n] = n
+	}
+	return ids
+}
+
+// newShards creates the sessions of the shards this process runs. Without a SHARD_COUNT the count comes from
+// Discord's recommendation, one shard when it can't be asked for.
+func (b *Bot) newShards(token string, config ShardConfig) error {
+	b.shardCount, b.identifyConcurrency = config.Count, 1
+	gateway, err := b.Session.GatewayBot()
+	switch {
+	case err != nil && config.Count == 0:
+		utils.LogWarn("Could not ask Discord how many shards to run, running one: %v", err)
+		b.shardCount = 1
+	case err != nil:
+		utils.LogWarn("Could not read the identify rate limit, identifying one shard at a time: %v", err)
+	default:
+		if b.shardCount == 0 {
+			b.shardCount = max(1, gateway.Shards)
+		}
+		b.identifyConcurrency = max(1, gateway.SessionStartLimit.MaxConcurrency)
+	}
+
+	b.Shards = nil
+	for _, id := range config.shardIDs(b.shardCount) {
+		session := b.Session
+		if len(b.Shards) > 0 {
+			if session, err = discordgo.New("Bot " + token); err != nil {
+				return fmt.Errorf("error creating Discord session for shard %d: %w", id, err)
+			}
+		}
+		session.ShardID, session.ShardCount = id, b.shardCount
+		b.Shards = append(b.Shards, session)
+	}
+	if b.shardCount > 1 {
+		utils.LogInfo("Running %d of %d shards in this process", len(b.Shards), b.shardCount)
+	}
+	return nil
+}
+
+// addHandler adds an event handler to every shard
+func (b *Bot) addHandler(handler interface{}) {
+	for _, session := range b.Shards {
+		session.AddHandler(handler)
+	}
+}
+
+// openShards connects the shards, as many at once as Discord's identify rate limit allows. The shards already
+// connected are closed again when one fails.
+func (b *Bot) openShards() error {
+	for n, session := range b.Shards {
+		if n > 0 && n%b.identifyConcurrency == 0 {
+			time.Sleep(identifyInterval)
+		}
+		if err := session.Open(); err != nil {
+			for _, opened := range b.Shards[:n] {
+				opened.Close()
+			}
+			return fmt.Errorf("failed to connect shard %d: %w", session.ShardID, err)
+		}
+	}
+	return nil
+}
+
+// closeShards disconnects every shard
+func (b *Bot) closeShards() error {
+	var errs []error
+	for _, session := range b.Shards {
+		if err := session.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("shard %d: %w", session.ShardID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runsShard reports whether this process runs a shard
+func (b *Bot) runsShard(shardID int) bool {
+	for _, session := range b.Shards {
+		if session.ShardID == shardID {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionFor returns the session of the shard a guild is on, the first shard's for guilds this process doesn't run
+func (b *Bot) sessionFor(guildID string) *discordgo.Session {
+	shard := commands.GuildShard(guildID, b.shardCount)
+	for _, session := range b.Shards {
+		if session.ShardID == shard {
+			return session
+		}
+	}
+	return b.Session
+}
+
+// shardReadiness tracks which shards of the process have received their guilds since starting
+type shardReadiness struct {
+	mu    sync.Mutex
+	ready map[int]bool
+}
+
+// markReady records a shard as ready and reports whether every one of total shards has been
+func (r *shardReadiness) markReady(shardID, total int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ready == nil {
+		r.ready = make(map[int]bool)
+	}
+	r.ready[shardID] = true
+	return len(r.ready) >= total
+}
+
+// guildIDs returns the guilds of every shard's state
+func (b *Bot) guildIDs() map[string]bool {
+	guilds := make(map[string]bool)
+	for _, session := range b.Shards {
+		if session.State == nil {
+			continue
+		}
+		session.State.RLock()
+		for _, guild := range session.State.Guilds {
+			guilds[guild.ID] = true
+		}
+		session.State.RUnlock()
+	}
+	return guilds
+}
+
+// shardReport describes the shards of this process for /botinfo
+func (b *Bot) shardReport() commands.ShardReport {
+	report := commands.ShardReport{Total: b.shardCount}
+	for _, session := range b.Shards {
+		info := commands.ShardInfo{ID: session.ShardID, Ready: session.DataReady, Latency: session.HeartbeatLatency()}
+		if session.State != nil {
+			session.State.RLock()
+			info.Guilds = len(session.State.Guilds)
+			session.State.RUnlock()
+		}
+		report.Shards = append(report.Shards, info)
+	}
+	return report
+}
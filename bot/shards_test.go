@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadShardConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    string
+		id       string
+		expected ShardConfig
+	}{
+		{name: "unset", expected: ShardConfig{ID: -1}},
+		{name: "auto", count: "auto", expected: ShardConfig{ID: -1}},
+		{name: "fixed count", count: "4", expected: ShardConfig{Count: 4, ID: -1}},
+		{name: "external shard", count: "4", id: "2", expected: ShardConfig{Count: 4, ID: 2}},
+		{name: "shard ID beyond the count", count: "4", id: "4", expected: ShardConfig{Count: 4, ID: -1}},
+		{name: "shard ID without a count", id: "1", expected: ShardConfig{ID: -1}},
+		{name: "invalid count", count: "many", expected: ShardConfig{ID: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SHARD_COUNT", tt.count)
+			t.Setenv("SHARD_ID", tt.id)
+			if got := LoadShardConfig(); got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestShardIDs(t *testing.T) {
+	if got := (ShardConfig{ID: -1}).shardIDs(3); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Errorf("Expected every shard, got %v", got)
+	}
+	if got := (ShardConfig{Count: 3, ID: 1}).shardIDs(3); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("Expected only shard 1, got %v", got)
+	}
+}
+
+func TestShardReadiness(t *testing.T) {
+	var readiness shardReadiness
+	if readiness.markReady(0, 2) {
+		t.Error("Expected the process to wait for its second shard")
+	}
+	if readiness.markReady(0, 2) {
+		t.Error("Expected a shard becoming ready again not to count twice")
+	}
+	if !readiness.markReady(1, 2) {
+		t.Error("Expected every shard to be ready")
+	}
+	if !readiness.markReady(0, 2) {
+		t.Error("Expected later ready events to find every shard ready")
+	}
+}
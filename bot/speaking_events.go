@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/music/speaking"
+	"pxnx-discord-bot/utils"
+)
+
+// speakingKeepAlive is how often an idle event stream gets a comment, so proxies don't close it
+const speakingKeepAlive = 30 * time.Second
+
+// speakingEvents streams who starts and stops talking in a guild's voice channel as Server-Sent Events,
+// for overlays and dashboards. Clients send the token as "Authorization: Bearer <token>".
+type speakingEvents struct {
+	token     string
+	speakers  func(guildID string) []speaking.Speaker
+	subscribe func(guildID string) (<-chan speaking.Event, func())
+	done      chan struct{} // Closed on shutdown, which ends the open streams
+	keepAlive time.Duration
+}
+
+func newSpeakingEvents(token string, speakers func(string) []speaking.Speaker, subscribe func(string) (<-chan speaking.Event, func())) *speakingEvents {
+	return &speakingEvents{
+		token:     token,
+		speakers:  speakers,
+		subscribe: subscribe,
+		done:      make(chan struct{}),
+		keepAlive: speakingKeepAlive,
+	}
+}
+
+// Handler returns the HTTP handler with the event stream of each guild
+func (e *speakingEvents) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /guilds/{guildID}/speaking", e.handleStream)
+	return mux
+}
+
+// authorized compares the bearer token to the configured one in constant time
+func (e *speakingEvents) authorized(r *http.Request) bool {
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+e.token)) == 1
+}
+
+// handleStream sends who is talking right now, then every change until the client or the bot goes away
+func (e *speakingEvents) handleStream(rw http.ResponseWriter, r *http.Request) {
+	if !e.authorized(r) {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		http.Error(rw, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	guildID := r.PathValue("guildID")
+	// Subscribing first means a turn starting in between is sent twice rather than missed
+	events, cancel := e.subscribe(guildID)
+	defer cancel()
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.WriteHeader(http.StatusOK)
+	for _, speaker := range e.speakers(guildID) {
+		if err := writeSpeakingEvent(rw, speaking.Event{GuildID: guildID, UserID: speaker.UserID, Speaking: true, Time: speaker.Since}); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(e.keepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			if err := writeSpeakingEvent(rw, event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(rw, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-e.done:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeSpeakingEvent writes an event in the Server-Sent Events format
+func writeSpeakingEvent(rw http.ResponseWriter, event speaking.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(rw, "event: speaking\ndata: %s\n\n", data)
+	return err
+}
+
+// startSpeakingEvents streams speaking events on SPEAKING_EVENTS_ADDR, such as ":8092", to clients with
+// SPEAKING_EVENTS_TOKEN; it is off when the address is unset
+func (b *Bot) startSpeakingEvents() {
+	addr := strings.TrimSpace(os.Getenv("SPEAKING_EVENTS_ADDR"))
+	if addr == "" {
+		return
+	}
+	token := strings.TrimSpace(os.Getenv("SPEAKING_EVENTS_TOKEN"))
+	if token == "" {
+		utils.LogError("Speaking events disabled, SPEAKING_EVENTS_TOKEN must be set so members' voice activity isn't public")
+		return
+	}
+	player := commands.SimplePlayer
+	if player == nil {
+		utils.LogWarn("Speaking events disabled, the music system is not available")
+		return
+	}
+
+	events := newSpeakingEvents(token, player.Speakers, player.SubscribeSpeaking)
+	stop, err := serveHTTP("speaking events", addr, events.Handler())
+	if err != nil {
+		utils.LogError("Speaking events disabled, failed to listen on %s: %v", addr, err)
+		return
+	}
+	b.stopSpeakingEvents = func() {
+		// Open streams never finish on their own, so they end before the server waits for them
+		close(events.done)
+		stop()
+	}
+}
@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pxnx-discord-bot/music/speaking"
+)
+
+func newTestSpeakingEvents(tracker *speaking.Tracker) (*speakingEvents, *httptest.Server) {
+	events := newSpeakingEvents("s3cret", tracker.Speakers, tracker.Subscribe)
+	return events, httptest.NewServer(events.Handler())
+}
+
+func openSpeakingStream(t *testing.T, url, token string) *http.Response {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Authorization", token)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+// readSpeakingData returns the data line of the next event on a stream
+func readSpeakingData(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended before the next event: %v", err)
+		}
+		if data, found := strings.CutPrefix(line, "data: "); found {
+			return strings.TrimSpace(data)
+		}
+	}
+}
+
+func TestSpeakingEventsStream(t *testing.T) {
+	tracker := speaking.NewTracker()
+	tracker.Update("guild1", "user1", true)
+	events, server := newTestSpeakingEvents(tracker)
+	defer server.Close()
+
+	response := openSpeakingStream(t, server.URL+"/guilds/guild1/speaking", "Bearer s3cret")
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET speaking = %d %q, want 200 text/event-stream", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(response.Body)
+
+	if data := readSpeakingData(t, reader); !strings.Contains(data, `"user_id":"user1","speaking":true`) {
+		t.Errorf("first event = %s, want who is already talking", data)
+	}
+
+	tracker.Update("guild2", "user3", true)
+	tracker.Update("guild1", "user2", true)
+	if data := readSpeakingData(t, reader); !strings.Contains(data, `"guild_id":"guild1","user_id":"user2","speaking":true`) {
+		t.Errorf("second event = %s, want user2 starting to talk", data)
+	}
+	tracker.Update("guild1", "user1", false)
+	if data := readSpeakingData(t, reader); !strings.Contains(data, `"user_id":"user1","speaking":false`) {
+		t.Errorf("third event = %s, want user1 stopping", data)
+	}
+
+	close(events.done)
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Errorf("stream should end cleanly on shutdown, got %v", err)
+	}
+}
+
+func TestSpeakingEventsNeedToken(t *testing.T) {
+	_, server := newTestSpeakingEvents(speaking.NewTracker())
+	defer server.Close()
+
+	for _, token := range []string{"", "s3cret", "Bearer guess"} {
+		response := openSpeakingStream(t, server.URL+"/guilds/guild1/speaking", token)
+		response.Body.Close()
+		if response.StatusCode != http.StatusUnauthorized {
+			t.Errorf("GET speaking with %q = %d, want 401", token, response.StatusCode)
+		}
+	}
+}
+
+func TestSpeakingEventsKeepAlive(t *testing.T) {
+	events, server := newTestSpeakingEvents(speaking.NewTracker())
+	events.keepAlive = 10 * time.Millisecond
+	defer server.Close()
+	defer close(events.done)
+
+	response := openSpeakingStream(t, server.URL+"/guilds/guild1/speaking", "Bearer s3cret")
+	defer response.Body.Close()
+	line, err := bufio.NewReader(response.Body).ReadString('\n')
+	if err != nil || line != ": keep-alive\n" {
+		t.Errorf("idle stream sent %q (%v), want a keep-alive comment", line, err)
+	}
+}
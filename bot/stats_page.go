@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/storage"
+	"pxnx-discord-bot/utils"
+)
+
+// statsPageCacheTTL is how long collected stats are served before they are collected again, so a busy
+// bot-list website doesn't lock the session state on every request
+const statsPageCacheTTL = time.Minute
+
+// PublicStats are the numbers the public stats page shows. They are totals only, nothing on the page
+// identifies a server or a member.
+type PublicStats struct {
+	Name             string    `json:"name"`
+	Guilds           int       `json:"guilds"`
+	SongsPlayedToday int       `json:"songs_played_today"`
+	UptimeSeconds    int64     `json:"uptime_seconds"`
+	GeneratedAt      time.Time `json:"generated_at"`
+}
+
+// Uptime formats UptimeSeconds for the page, such as "3d 4h 12m"
+func (s PublicStats) Uptime() string {
+	uptime := time.Duration(s.UptimeSeconds) * time.Second
+	days := int(uptime.Hours()) / 24
+	hours := int(uptime.Hours()) % 24
+	minutes := int(uptime.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// statsPageTemplate renders the HTML page
+var statsPageTemplate = template.Must(template.New("stats").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Name}} stats</title>
+<style>
+body { font-family: system-ui, sans-serif; background: #2b2d31; color: #f2f3f5; margin: 0; padding: 2rem; }
+h1 { font-weight: 600; }
+dl { display: grid; grid-template-columns: repeat(auto-fit, minmax(12rem, 1fr)); gap: 1rem; }
+div { background: #1e1f22; border-radius: 8px; padding: 1rem; }
+dt { color: #b5bac1; }
+dd { font-size: 2rem; margin: 0.25rem 0 0; }
+footer { color: #80848e; margin-top: 2rem; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<dl>
+<div><dt>Servers</dt><dd>{{.Guilds}}</dd></div>
+<div><dt>Songs played today</dt><dd>{{.SongsPlayedToday}}</dd></div>
+<div><dt>Uptime</dt><dd>{{.Uptime}}</dd></div>
+</dl>
+<footer>Updated {{.GeneratedAt.Format "2006-01-02 15:04 MST"}} · <a href="stats.json">JSON</a></footer>
+</body>
+</html>
+`))
+
+// statsPage serves PublicStats as an HTML page at / and as JSON at /stats.json
+type statsPage struct {
+	collect func() PublicStats
+	now     func() time.Time
+
+	mu      sync.Mutex
+	cached  PublicStats
+	expires time.Time
+}
+
+func newStatsPage(collect func() PublicStats) *statsPage {
+	return &statsPage{collect: collect, now: time.Now}
+}
+
+// Handler returns the HTTP handler of the page and its JSON endpoint
+func (p *statsPage) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", p.handlePage)
+	mux.HandleFunc("GET /stats.json", p.handleJSON)
+	return mux
+}
+
+// current returns the cached stats, collecting them again once they are older than statsPageCacheTTL
+func (p *statsPage) current() PublicStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if now.Before(p.expires) {
+		return p.cached
+	}
+	p.cached = p.collect()
+	p.cached.GeneratedAt = now.UTC()
+	p.expires = now.Add(statsPageCacheTTL)
+	return p.cached
+}
+
+func (p *statsPage) handlePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(statsPageCacheTTL.Seconds())))
+	if err := statsPageTemplate.Execute(w, p.current()); err != nil {
+		utils.LogWarn("Failed to render stats page: %v", err)
+	}
+}
+
+func (p *statsPage) handleJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(statsPageCacheTTL.Seconds())))
+	// Bot-list websites fetch the numbers from their own pages
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if err := json.NewEncoder(w).Encode(p.current()); err != nil {
+		utils.LogWarn("Failed to write stats JSON: %v", err)
+	}
+}
+
+// collectPublicStats counts the bot's servers and today's plays from the session state and music player
+func (b *Bot) collectPublicStats(started time.Time) PublicStats {
+	stats := PublicStats{Name: "Bot", UptimeSeconds: int64(time.Since(started).Seconds())}
+
+	state := b.Session.State
+	state.RLock()
+	if state.User != nil {
+		stats.Name = state.User.Username
+	}
+	state.RUnlock()
+	stats.Guilds = len(b.guildIDs())
+
+	if commands.SimplePlayer != nil {
+		stats.SongsPlayedToday = commands.SimplePlayer.PlaysToday()
+	}
+	return stats
+}
+
+// startStatsPage serves the public stats page on STATS_PAGE_ADDR, such as ":8090"; it is off when unset
+func (b *Bot) startStatsPage() {
+	addr := strings.TrimSpace(os.Getenv("STATS_PAGE_ADDR"))
+	if addr == "" {
+		return
+	}
+
+	started := time.Now()
+	page := newStatsPage(func() PublicStats { return b.collectPublicStats(started) })
+	stop, err := serveHTTP("public stats page", addr, withStorageFiles(page.Handler()))
+	if err != nil {
+		utils.LogError("Stats page disabled, failed to listen on %s: %v", addr, err)
+		return
+	}
+	b.stopStatsPage = stop
+}
+
+// withStorageFiles also serves the signed links of the local asset store under /files/, the path
+// STORAGE_PUBLIC_URL points at. S3 links go to the bucket itself.
+func withStorageFiles(handler http.Handler) http.Handler {
+	local, ok := commands.Storage.(*storage.Local)
+	if !ok {
+		return handler
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", handler)
+	mux.Handle("/files/", http.StripPrefix("/files", local.Handler()))
+	return mux
+}
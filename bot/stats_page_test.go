@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsPageCachesStats(t *testing.T) {
+	collected := 0
+	page := newStatsPage(func() PublicStats {
+		collected++
+		return PublicStats{Name: "pxnx", Guilds: collected}
+	})
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	page.now = func() time.Time { return now }
+
+	first := page.current()
+	now = now.Add(statsPageCacheTTL - time.Second)
+	if cached := page.current(); cached != first || collected != 1 {
+		t.Errorf("current() = %+v after %d collections, want the cached %+v", cached, collected, first)
+	}
+
+	now = now.Add(time.Second)
+	if fresh := page.current(); fresh.Guilds != 2 || !fresh.GeneratedAt.Equal(now) {
+		t.Errorf("current() = %+v, want stats collected again after the cache TTL", fresh)
+	}
+}
+
+func TestStatsPageJSON(t *testing.T) {
+	page := newStatsPage(func() PublicStats {
+		return PublicStats{Name: "pxnx", Guilds: 42, SongsPlayedToday: 7, UptimeSeconds: 3 * 86400}
+	})
+
+	recorder := httptest.NewRecorder()
+	page.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/stats.json", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /stats.json status = %d, want 200", recorder.Code)
+	}
+	if origin := recorder.Header().Get("Access-Control-Allow-Origin"); origin != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want other sites allowed to fetch the stats", origin)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET /stats.json returned invalid JSON: %v", err)
+	}
+	for _, field := range []string{"name", "guilds", "songs_played_today", "uptime_seconds", "generated_at"} {
+		if _, ok := body[field]; !ok {
+			t.Errorf("GET /stats.json is missing %q: %s", field, recorder.Body.String())
+		}
+	}
+	if len(body) != 5 {
+		t.Errorf("GET /stats.json = %s, want only the aggregate fields", recorder.Body.String())
+	}
+}
+
+func TestStatsPageHTML(t *testing.T) {
+	page := newStatsPage(func() PublicStats {
+		return PublicStats{Name: "<pxnx>", Guilds: 42, SongsPlayedToday: 7, UptimeSeconds: 3*86400 + 4*3600 + 12*60}
+	})
+
+	recorder := httptest.NewRecorder()
+	page.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	body := recorder.Body.String()
+	for _, want := range []string{"&lt;pxnx&gt;", "<dd>42</dd>", "<dd>7</dd>", "<dd>3d 4h 12m</dd>"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("GET / is missing %q", want)
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	page.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/guilds", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("GET /guilds status = %d, want 404", recorder.Code)
+	}
+}
+
+func TestPublicStatsUptime(t *testing.T) {
+	if got := (PublicStats{UptimeSeconds: 2*3600 + 5*60 + 59}).Uptime(); got != "2h 5m" {
+		t.Errorf("Uptime() = %q, want %q", got, "2h 5m")
+	}
+}
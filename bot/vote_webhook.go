@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/utils"
+)
+
+// maxVoteBodySize bounds vote payloads, which are a few hundred bytes
+const maxVoteBodySize = 64 << 10
+
+// topggVote is the body top.gg posts for a vote
+type topggVote struct {
+	Bot       string `json:"bot"`
+	User      string `json:"user"`
+	Type      string `json:"type"` // "upvote", or "test" from the webhook page's test button
+	IsWeekend bool   `json:"isWeekend"`
+}
+
+// dblVote is the body discordbotlist.com posts for a vote
+type dblVote struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// voteWebhook receives vote events from bot lists. Both lists send the secret configured on their webhook
+// page in the Authorization header.
+type voteWebhook struct {
+	secret string
+	botID  func() string // The bot's user ID, empty while it isn't known yet
+	record func(userID, site string) error
+}
+
+func newVoteWebhook(secret string, botID func() string, record func(userID, site string) error) *voteWebhook {
+	return &voteWebhook{secret: secret, botID: botID, record: record}
+}
+
+// Handler returns the HTTP handler with an endpoint per bot list
+func (w *voteWebhook) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /topgg", w.handleTopgg)
+	mux.HandleFunc("POST /discordbotlist", w.handleDiscordBotList)
+	return mux
+}
+
+// authorized compares the Authorization header to the secret in constant time
+func (w *voteWebhook) authorized(r *http.Request) bool {
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(w.secret)) == 1
+}
+
+func (w *voteWebhook) handleTopgg(rw http.ResponseWriter, r *http.Request) {
+	var vote topggVote
+	if !w.decode(rw, r, &vote) {
+		return
+	}
+	// A secret shared between several bots on top.gg must not let votes for the others count
+	if botID := w.botID(); botID != "" && vote.Bot != botID {
+		utils.LogWarn("Rejected top.gg vote for bot %q, this bot is %s", vote.Bot, botID)
+		http.Error(rw, "vote for another bot", http.StatusBadRequest)
+		return
+	}
+	// The webhook page's test button checks the endpoint, the vote doesn't count
+	if vote.Type == "test" {
+		utils.LogInfo("Received a test vote from top.gg for user %s", vote.User)
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.accept(rw, vote.User, "top.gg")
+}
+
+func (w *voteWebhook) handleDiscordBotList(rw http.ResponseWriter, r *http.Request) {
+	var vote dblVote
+	if !w.decode(rw, r, &vote) {
+		return
+	}
+	w.accept(rw, vote.ID, "discordbotlist")
+}
+
+// decode checks the secret and reads the body into v, answering the request itself when either fails
+func (w *voteWebhook) decode(rw http.ResponseWriter, r *http.Request, v any) bool {
+	if !w.authorized(r) {
+		utils.LogWarn("Rejected vote webhook request from %s: wrong secret", r.RemoteAddr)
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(rw, r.Body, maxVoteBodySize)).Decode(v); err != nil {
+		http.Error(rw, "invalid vote payload", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// accept records a vote. Bot lists retry deliveries that fail, so a vote that can't be saved still
+// counts in memory and is acknowledged.
+func (w *voteWebhook) accept(rw http.ResponseWriter, userID, site string) {
+	if userID == "" {
+		http.Error(rw, "missing user", http.StatusBadRequest)
+		return
+	}
+	if err := w.record(userID, site); err != nil {
+		utils.LogWarn("Vote by %s on %s was not saved: %v", userID, site, err)
+	}
+	utils.LogDebug("Recorded vote by %s on %s", userID, site)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// startVoteWebhook receives bot list votes on VOTE_WEBHOOK_ADDR, such as ":8091", checked against
+// VOTE_WEBHOOK_SECRET; it is off when the address is unset
+func (b *Bot) startVoteWebhook() {
+	addr := strings.TrimSpace(os.Getenv("VOTE_WEBHOOK_ADDR"))
+	if addr == "" {
+		return
+	}
+	secret := strings.TrimSpace(os.Getenv("VOTE_WEBHOOK_SECRET"))
+	if secret == "" {
+		utils.LogError("Vote webhook disabled, VOTE_WEBHOOK_SECRET must be set so votes can't be forged")
+		return
+	}
+
+	botID := func() string {
+		if b.Session.State == nil || b.Session.State.User == nil {
+			return ""
+		}
+		return b.Session.State.User.ID
+	}
+	webhook := newVoteWebhook(secret, botID, func(userID, site string) error {
+		_, err := commands.Votes.Record(userID, site)
+		return err
+	})
+	stop, err := serveHTTP("vote webhook", addr, webhook.Handler())
+	if err != nil {
+		utils.LogError("Vote webhook disabled, failed to listen on %s: %v", addr, err)
+		return
+	}
+	b.stopVoteWebhook = stop
+}
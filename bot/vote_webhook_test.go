@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordedVote struct {
+	userID, site string
+}
+
+func newTestVoteWebhook(votes *[]recordedVote, err error) http.Handler {
+	return newVoteWebhook("s3cret", func() string { return "1" }, func(userID, site string) error {
+		*votes = append(*votes, recordedVote{userID, site})
+		return err
+	}).Handler()
+}
+
+func postVote(handler http.Handler, path, secret, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	request.Header.Set("Authorization", secret)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestVoteWebhookRecordsVotes(t *testing.T) {
+	var votes []recordedVote
+	handler := newTestVoteWebhook(&votes, nil)
+
+	if got := postVote(handler, "/topgg", "s3cret", `{"bot":"1","user":"42","type":"upvote","isWeekend":false}`); got.Code != http.StatusNoContent {
+		t.Errorf("POST /topgg status = %d, want 204", got.Code)
+	}
+	if got := postVote(handler, "/discordbotlist", "s3cret", `{"id":"43","username":"pxnx","admin":false}`); got.Code != http.StatusNoContent {
+		t.Errorf("POST /discordbotlist status = %d, want 204", got.Code)
+	}
+
+	want := []recordedVote{{"42", "top.gg"}, {"43", "discordbotlist"}}
+	if len(votes) != len(want) || votes[0] != want[0] || votes[1] != want[1] {
+		t.Errorf("recorded votes = %v, want %v", votes, want)
+	}
+}
+
+func TestVoteWebhookRejectsBadRequests(t *testing.T) {
+	var votes []recordedVote
+	handler := newTestVoteWebhook(&votes, nil)
+
+	tests := []struct {
+		name   string
+		path   string
+		secret string
+		body   string
+		status int
+	}{
+		{"wrong secret", "/topgg", "guess", `{"user":"42"}`, http.StatusUnauthorized},
+		{"no secret", "/discordbotlist", "", `{"id":"42"}`, http.StatusUnauthorized},
+		{"invalid JSON", "/topgg", "s3cret", `{"user":`, http.StatusBadRequest},
+		{"missing user", "/topgg", "s3cret", `{"bot":"1","type":"upvote"}`, http.StatusBadRequest},
+		{"other bot", "/topgg", "s3cret", `{"bot":"2","user":"42","type":"upvote"}`, http.StatusBadRequest},
+		{"no bot", "/topgg", "s3cret", `{"user":"42","type":"upvote"}`, http.StatusBadRequest},
+		{"unknown bot list", "/other", "s3cret", `{"user":"42"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postVote(handler, tt.path, tt.secret, tt.body); got.Code != tt.status {
+				t.Errorf("POST %s status = %d, want %d", tt.path, got.Code, tt.status)
+			}
+		})
+	}
+	if len(votes) != 0 {
+		t.Errorf("recorded votes = %v, want none", votes)
+	}
+}
+
+func TestVoteWebhookAcknowledgesUnsavedVotes(t *testing.T) {
+	var votes []recordedVote
+	handler := newTestVoteWebhook(&votes, errors.New("disk full"))
+
+	if got := postVote(handler, "/topgg", "s3cret", `{"bot":"1","user":"42","type":"upvote"}`); got.Code != http.StatusNoContent {
+		t.Errorf("POST /topgg status = %d, want 204 so the bot list doesn't retry", got.Code)
+	}
+}
+
+func TestVoteWebhookAcknowledgesTestVotes(t *testing.T) {
+	var votes []recordedVote
+	handler := newTestVoteWebhook(&votes, nil)
+
+	if got := postVote(handler, "/topgg", "s3cret", `{"bot":"1","user":"42","type":"test"}`); got.Code != http.StatusNoContent {
+		t.Errorf("POST /topgg status = %d, want 204", got.Code)
+	}
+	if len(votes) != 0 {
+		t.Errorf("recorded votes = %v, want none for a test vote", votes)
+	}
+}
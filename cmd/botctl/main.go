@@ -0,0 +1,292 @@
+// Command botctl runs command handlers locally against a mock session using synthetic
+// interactions loaded from JSON fixtures, printing every response the handler sends.
+//
+// Usage:
+//
+//	go run ./cmd/botctl -fixture cmd/botctl/fixtures/basic.json
+//	go run ./cmd/botctl -command 8ball -option question="Will it work?"
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/bot"
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/testutils"
+)
+
+// runnable lists the commands that can run without a live Discord connection
+var runnable = map[string]bool{
+	"ping":        true,
+	"8ball":       true,
+	"coinflip":    true,
+	"server":      true,
+	"user":        true,
+	"weather":     true,
+	"roll":        true,
+	"play":        true,
+	"skip":        true,
+	"music":       true,
+	"checkperms":  true,
+	"premium":     true,
+	"vote":        true,
+	"preferences": true,
+	"admin":       true,
+	"debug":       true,
+	"support":     true,
+}
+
+// Fixture describes one synthetic interaction
+type Fixture struct {
+	Name     string          `json:"name"`
+	Command  string          `json:"command"`
+	CustomID string          `json:"custom_id"` // Set instead of Command for button/select menu presses
+	Values   []string        `json:"values"`
+	UserID   string          `json:"user_id"`
+	Username string          `json:"username"`
+	GuildID  string          `json:"guild_id"`
+	Options  []FixtureOption `json:"options"`
+}
+
+// FixtureOption is a command option. Type is inferred from the JSON value when omitted
+// and can be one of: string, integer, boolean, user, channel, subcommand, group.
+type FixtureOption struct {
+	Name    string          `json:"name"`
+	Type    string          `json:"type"`
+	Value   interface{}     `json:"value"`
+	Options []FixtureOption `json:"options"`
+}
+
+// optionList collects repeated -option name=value flags
+type optionList []string
+
+func (o *optionList) String() string     { return strings.Join(*o, ",") }
+func (o *optionList) Set(v string) error { *o = append(*o, v); return nil }
+
+func main() {
+	fixturePath := flag.String("fixture", "", "JSON file with a fixture or a list of fixtures")
+	command := flag.String("command", "", "Command to invoke when no fixture file is given")
+	list := flag.Bool("list", false, "List the commands that can be invoked")
+	var options optionList
+	flag.Var(&options, "option", "Command option as name=value (repeatable)")
+	flag.Parse()
+
+	if *list {
+		for _, name := range commandNames() {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	var fixtures []Fixture
+	switch {
+	case *fixturePath != "":
+		loaded, err := loadFixtures(*fixturePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "botctl: %v\n", err)
+			os.Exit(1)
+		}
+		fixtures = loaded
+	case *command != "":
+		fixture, err := fixtureFromFlags(*command, options)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "botctl: %v\n", err)
+			os.Exit(1)
+		}
+		fixtures = []Fixture{fixture}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	failures := 0
+	for _, fixture := range fixtures {
+		if err := runFixture(os.Stdout, fixture); err != nil {
+			fmt.Fprintf(os.Stdout, "  ❌ handler error: %v\n", err)
+			failures++
+		}
+	}
+
+	fmt.Printf("\n%d fixtures run, %d failed\n", len(fixtures), failures)
+	if failures > 0 {
+		os.Exit(1)
+	}
+}
+
+// commandNames returns the invokable command names in sorted order
+func commandNames() []string {
+	names := make([]string, 0, len(runnable))
+	for name := range runnable {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadFixtures reads a single fixture object or a list of fixtures from a JSON file
+func loadFixtures(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture file: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "{") {
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture: %w", err)
+		}
+		return []Fixture{fixture}, nil
+	}
+
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+// fixtureFromFlags builds a fixture from -command and -option flags
+func fixtureFromFlags(command string, options []string) (Fixture, error) {
+	fixture := Fixture{Name: command, Command: command}
+	for _, option := range options {
+		name, raw, found := strings.Cut(option, "=")
+		if !found {
+			return Fixture{}, fmt.Errorf("invalid option %q, expected name=value", option)
+		}
+
+		var value interface{} = raw
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			value = n
+		} else if b, err := strconv.ParseBool(raw); err == nil {
+			value = b
+		}
+		fixture.Options = append(fixture.Options, FixtureOption{Name: name, Value: value})
+	}
+	return fixture, nil
+}
+
+// buildInteraction converts a fixture into a synthetic interaction
+func buildInteraction(fixture Fixture) (*discordgo.InteractionCreate, error) {
+	var interaction *discordgo.InteractionCreate
+	if fixture.CustomID != "" {
+		interaction = testutils.CreateTestComponentInteraction(fixture.CustomID, fixture.Values...)
+	} else {
+		options, err := buildOptions(fixture.Options)
+		if err != nil {
+			return nil, err
+		}
+		interaction = testutils.CreateTestInteraction(fixture.Command, options)
+	}
+
+	userID := fixture.UserID
+	if userID == "" {
+		userID = "botctl_user"
+	}
+	username := fixture.Username
+	if username == "" {
+		username = "botctl"
+	}
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser(userID, username, ""))
+	if fixture.GuildID != "" {
+		interaction.GuildID = fixture.GuildID
+	}
+
+	return interaction, nil
+}
+
+// buildOptions converts fixture options into interaction options
+func buildOptions(fixtureOptions []FixtureOption) ([]*discordgo.ApplicationCommandInteractionDataOption, error) {
+	options := make([]*discordgo.ApplicationCommandInteractionDataOption, 0, len(fixtureOptions))
+	for _, fo := range fixtureOptions {
+		optionType, err := resolveOptionType(fo)
+		if err != nil {
+			return nil, err
+		}
+
+		option := &discordgo.ApplicationCommandInteractionDataOption{
+			Name:  fo.Name,
+			Type:  optionType,
+			Value: fo.Value,
+		}
+		if optionType == discordgo.ApplicationCommandOptionSubCommand || optionType == discordgo.ApplicationCommandOptionSubCommandGroup {
+			option.Value = nil
+			option.Options, err = buildOptions(fo.Options)
+			if err != nil {
+				return nil, err
+			}
+		}
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+// resolveOptionType maps the fixture type name, or the JSON value, to a Discord option type
+func resolveOptionType(option FixtureOption) (discordgo.ApplicationCommandOptionType, error) {
+	switch option.Type {
+	case "string":
+		return discordgo.ApplicationCommandOptionString, nil
+	case "integer":
+		return discordgo.ApplicationCommandOptionInteger, nil
+	case "boolean":
+		return discordgo.ApplicationCommandOptionBoolean, nil
+	case "user":
+		return discordgo.ApplicationCommandOptionUser, nil
+	case "channel":
+		return discordgo.ApplicationCommandOptionChannel, nil
+	case "subcommand":
+		return discordgo.ApplicationCommandOptionSubCommand, nil
+	case "group":
+		return discordgo.ApplicationCommandOptionSubCommandGroup, nil
+	case "":
+		// Infer from the value
+	default:
+		return 0, fmt.Errorf("option %s has unknown type %q", option.Name, option.Type)
+	}
+
+	switch option.Value.(type) {
+	case float64:
+		return discordgo.ApplicationCommandOptionInteger, nil
+	case bool:
+		return discordgo.ApplicationCommandOptionBoolean, nil
+	case nil:
+		return discordgo.ApplicationCommandOptionSubCommand, nil
+	default:
+		return discordgo.ApplicationCommandOptionString, nil
+	}
+}
+
+// runFixture invokes the handler for a fixture and prints every response it sends
+func runFixture(out io.Writer, fixture Fixture) error {
+	name := fixture.Name
+	if name == "" {
+		name = fixture.Command + fixture.CustomID
+	}
+	fmt.Fprintf(out, "▶ %s\n", name)
+
+	interaction, err := buildInteraction(fixture)
+	if err != nil {
+		return err
+	}
+
+	session := newRecordingSession(out)
+	if fixture.CustomID != "" {
+		return commands.HandleComponentInteraction(session, interaction)
+	}
+
+	if !runnable[fixture.Command] {
+		return fmt.Errorf("unknown command %q (available: %s)", fixture.Command, strings.Join(commandNames(), ", "))
+	}
+
+	// The command runs through the bot's middleware, so refusals get the same answer the bot gives them
+	return bot.HandleCommand(session, interaction)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundledFixturesRun(t *testing.T) {
+	fixtures, err := loadFixtures("fixtures/basic.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, runFixture(&out, fixture))
+			assert.Contains(t, out.String(), "respond")
+		})
+	}
+}
+
+func TestFixtureFromFlags(t *testing.T) {
+	fixture, err := fixtureFromFlags("roll", []string{"max=20", "secret=true", "label=hello"})
+	require.NoError(t, err)
+
+	options, err := buildOptions(fixture.Options)
+	require.NoError(t, err)
+	require.Len(t, options, 3)
+	assert.Equal(t, discordgo.ApplicationCommandOptionInteger, options[0].Type)
+	assert.Equal(t, int64(20), options[0].IntValue())
+	assert.Equal(t, discordgo.ApplicationCommandOptionBoolean, options[1].Type)
+	assert.Equal(t, discordgo.ApplicationCommandOptionString, options[2].Type)
+
+	_, err = fixtureFromFlags("roll", []string{"max"})
+	assert.Error(t, err)
+}
+
+func TestRunFixtureUnknownCommand(t *testing.T) {
+	var out bytes.Buffer
+	err := runFixture(&out, Fixture{Command: "doesnotexist"})
+	assert.ErrorContains(t, err, "unknown command")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/testutils"
+)
+
+// recordingSession is a mock session that prints every response a handler sends
+type recordingSession struct {
+	*testutils.MockSession
+	out io.Writer
+}
+
+// newRecordingSession creates a mock session backed by a test guild and channel
+func newRecordingSession(out io.Writer) *recordingSession {
+	guild := testutils.CreateTestGuild("guild_id_123", "botctl Test Server", 42)
+	channel := &discordgo.Channel{ID: "channel_id_123", GuildID: guild.ID, Name: "general", Type: discordgo.ChannelTypeGuildText}
+	guild.Channels = []*discordgo.Channel{channel}
+	guild.Roles = []*discordgo.Role{
+		{ID: guild.ID, Permissions: discordgo.PermissionAllText | discordgo.PermissionAllVoice | discordgo.PermissionAddReactions | discordgo.PermissionUseExternalEmojis},
+	}
+
+	state := discordgo.NewState()
+	state.User = testutils.CreateTestUser("bot_id", "pxnx", "")
+	_ = state.GuildAdd(guild)
+	_ = state.MemberAdd(&discordgo.Member{GuildID: guild.ID, User: state.User})
+
+	return &recordingSession{
+		MockSession: &testutils.MockSession{
+			GuildReturn:   guild,
+			ChannelReturn: channel,
+			StateReturn:   state,
+		},
+		out: out,
+	}
+}
+
+// print writes a labelled, indented JSON dump of a response payload
+func (r *recordingSession) print(label string, payload interface{}) {
+	fmt.Fprintf(r.out, "  %s:\n  ", label)
+
+	// Keep emoji and mentions readable instead of HTML-escaped
+	encoder := json.NewEncoder(r.out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("  ", "  ")
+	if err := encoder.Encode(payload); err != nil {
+		fmt.Fprintf(r.out, "<unprintable: %v>\n", err)
+	}
+}
+
+func (r *recordingSession) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	r.print(fmt.Sprintf("respond (type %d)", resp.Type), resp.Data)
+	return r.MockSession.InteractionRespond(interaction, resp, options...)
+}
+
+func (r *recordingSession) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	r.print("edit", newresp)
+	return r.MockSession.InteractionResponseEdit(interaction, newresp, options...)
+}
+
+func (r *recordingSession) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	r.print("followup", data)
+	return r.MockSession.FollowupMessageCreate(interaction, wait, data, options...)
+}
+
+func (r *recordingSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	r.print("channel message", data)
+	return r.MockSession.ChannelMessageSendComplex(channelID, data, options...)
+}
+
+func (r *recordingSession) ChannelMessageEditComplex(data *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	r.print("channel message edit", data)
+	return r.MockSession.ChannelMessageEditComplex(data, options...)
+}
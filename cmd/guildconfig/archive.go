@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"pxnx-discord-bot/music/premium"
+	"pxnx-discord-bot/music/settings"
+)
+
+// archiveVersion is the format version written by export; import refuses other versions
+const archiveVersion = 1
+
+// Archive holds every guild's configuration for moving it to another instance
+type Archive struct {
+	Version    int                    `json:"version"`
+	ExportedAt time.Time              `json:"exported_at"`
+	Guilds     map[string]GuildConfig `json:"guilds"`
+}
+
+// GuildConfig is one guild's saved configuration; sections the guild never set are left out
+type GuildConfig struct {
+	Music   *settings.Guild `json:"music,omitempty"`
+	Premium *premium.Grant  `json:"premium,omitempty"`
+}
+
+// Change is one configuration value an import would change
+type Change struct {
+	GuildID string
+	Field   string // Section and JSON name, such as "music.loudnorm"
+	Old     string // Empty when the value isn't set
+	New     string // Empty when the value is removed
+}
+
+// exportArchive collects the configuration of every guild with music settings or a premium grant
+func exportArchive(store *settings.Store, entitlements *premium.Entitlements, now time.Time) Archive {
+	archive := Archive{Version: archiveVersion, ExportedAt: now.UTC(), Guilds: make(map[string]GuildConfig)}
+	for guildID, guild := range store.All() {
+		config := archive.Guilds[guildID]
+		config.Music = &guild
+		archive.Guilds[guildID] = config
+	}
+	for guildID, grant := range entitlements.Grants() {
+		config := archive.Guilds[guildID]
+		config.Premium = &grant
+		archive.Guilds[guildID] = config
+	}
+	return archive
+}
+
+// parseArchive decodes an archive and checks that it can be imported
+func parseArchive(data []byte) (Archive, error) {
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return Archive{}, fmt.Errorf("failed to parse archive: %w", err)
+	}
+	if archive.Version != archiveVersion {
+		return Archive{}, fmt.Errorf("unsupported archive version %d, expected %d", archive.Version, archiveVersion)
+	}
+	return archive, nil
+}
+
+// diffArchive lists what importing the archive would change, ordered by guild and field. Each guild in
+// the archive ends up with exactly the archive's configuration; other guilds are left alone.
+func diffArchive(archive Archive, store *settings.Store, entitlements *premium.Entitlements) ([]Change, error) {
+	current := exportArchive(store, entitlements, time.Time{})
+
+	var changes []Change
+	for _, guildID := range sortedGuilds(archive.Guilds) {
+		before, err := flatten(current.Guilds[guildID])
+		if err != nil {
+			return nil, err
+		}
+		after, err := flatten(archive.Guilds[guildID])
+		if err != nil {
+			return nil, err
+		}
+
+		fields := make(map[string]bool)
+		for field := range before {
+			fields[field] = true
+		}
+		for field := range after {
+			fields[field] = true
+		}
+		for _, field := range sortedKeys(fields) {
+			if before[field] != after[field] {
+				changes = append(changes, Change{GuildID: guildID, Field: field, Old: before[field], New: after[field]})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// applyArchive gives every guild in the archive the archive's configuration and saves both stores
+func applyArchive(archive Archive, store *settings.Store, entitlements *premium.Entitlements) error {
+	guilds := make(map[string]settings.Guild, len(archive.Guilds))
+	grants := make(map[string]*premium.Grant, len(archive.Guilds))
+	for guildID, config := range archive.Guilds {
+		if config.Music != nil {
+			guilds[guildID] = *config.Music
+		} else {
+			guilds[guildID] = settings.Guild{}
+		}
+		grants[guildID] = config.Premium
+	}
+
+	if err := store.Import(guilds); err != nil {
+		return err
+	}
+	return entitlements.ImportGrants(grants)
+}
+
+// flatten turns a guild's configuration into its set values keyed by section and JSON field name, so
+// fields added to the settings later show up in diffs without changes here
+func flatten(config GuildConfig) (map[string]string, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode guild config: %w", err)
+	}
+	var sections map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("failed to decode guild config: %w", err)
+	}
+
+	values := make(map[string]string)
+	for section, fields := range sections {
+		for name, value := range fields {
+			values[section+"."+name] = string(value)
+		}
+	}
+	return values, nil
+}
+
+// sortedGuilds returns the guild IDs of an archive in order
+func sortedGuilds(guilds map[string]GuildConfig) []string {
+	ids := make([]string, 0, len(guilds))
+	for guildID := range guilds {
+		ids = append(ids, guildID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/premium"
+	"pxnx-discord-bot/music/settings"
+)
+
+// openTestStores creates empty stores saved in a temporary directory
+func openTestStores(t *testing.T) (*settings.Store, *premium.Entitlements) {
+	t.Helper()
+	dir := t.TempDir()
+	store, entitlements, err := openStores(filepath.Join(dir, "settings.json"), filepath.Join(dir, "premium.json"))
+	require.NoError(t, err)
+	return store, entitlements
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	store, entitlements := openTestStores(t)
+	_, err := store.Update("guild_1", func(g *settings.Guild) { g.Loudnorm, g.IdleTimeoutMinutes = true, 10 })
+	require.NoError(t, err)
+	expires := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, entitlements.Grant("guild_1", expires, "sponsor"))
+	require.NoError(t, entitlements.Grant("guild_2", time.Time{}, ""))
+
+	exportedAt := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	archive := exportArchive(store, entitlements, exportedAt)
+	assert.Equal(t, Archive{
+		Version:    archiveVersion,
+		ExportedAt: exportedAt,
+		Guilds: map[string]GuildConfig{
+			"guild_1": {Music: &settings.Guild{Loudnorm: true, IdleTimeoutMinutes: 10}, Premium: &premium.Grant{Expires: expires, Note: "sponsor"}},
+			"guild_2": {Premium: &premium.Grant{}},
+		},
+	}, archive)
+
+	data, err := json.Marshal(archive)
+	require.NoError(t, err)
+	parsed, err := parseArchive(data)
+	require.NoError(t, err)
+
+	target, targetGrants := openTestStores(t)
+	require.NoError(t, applyArchive(parsed, target, targetGrants))
+	assert.Equal(t, store.All(), target.All())
+	assert.Equal(t, entitlements.Grants(), targetGrants.Grants())
+
+	changes, err := diffArchive(parsed, target, targetGrants)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "importing the same archive again changes nothing")
+}
+
+func TestDiffArchive(t *testing.T) {
+	store, entitlements := openTestStores(t)
+	_, err := store.Update("guild_1", func(g *settings.Guild) { g.Loudnorm, g.FairQueue = true, true })
+	require.NoError(t, err)
+	_, err = store.Update("guild_3", func(g *settings.Guild) { g.StayConnected = true })
+	require.NoError(t, err)
+	require.NoError(t, entitlements.Grant("guild_1", time.Time{}, "old"))
+
+	archive := Archive{Version: archiveVersion, Guilds: map[string]GuildConfig{
+		"guild_1": {Music: &settings.Guild{Loudnorm: true, SearchProvider: "radio"}},
+		"guild_2": {Music: &settings.Guild{FairQueue: true}},
+	}}
+
+	changes, err := diffArchive(archive, store, entitlements)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{GuildID: "guild_1", Field: "music.fair_queue", Old: "true"},
+		{GuildID: "guild_1", Field: "music.search_provider", New: `"radio"`},
+		{GuildID: "guild_1", Field: "premium.expires", Old: `"0001-01-01T00:00:00Z"`},
+		{GuildID: "guild_1", Field: "premium.note", Old: `"old"`},
+		{GuildID: "guild_2", Field: "music.fair_queue", New: "true"},
+	}, changes, "guilds outside the archive are left alone")
+
+	var out bytes.Buffer
+	printChanges(&out, changes, len(archive.Guilds))
+	assert.Equal(t, `guild guild_1:
+  - music.fair_queue = true
+  + music.search_provider = "radio"
+  - premium.expires = "0001-01-01T00:00:00Z"
+  - premium.note = "old"
+guild guild_2:
+  + music.fair_queue = true
+2 of 2 guilds change
+`, out.String())
+
+	require.NoError(t, applyArchive(archive, store, entitlements))
+	assert.Equal(t, map[string]settings.Guild{
+		"guild_1": {Loudnorm: true, SearchProvider: "radio"},
+		"guild_2": {FairQueue: true},
+		"guild_3": {StayConnected: true},
+	}, store.All())
+	assert.Empty(t, entitlements.Grants())
+}
+
+func TestParseArchiveRejectsOtherVersions(t *testing.T) {
+	_, err := parseArchive([]byte(`{"version": 2, "guilds": {}}`))
+	assert.ErrorContains(t, err, "unsupported archive version 2")
+
+	_, err = parseArchive([]byte(`not json`))
+	assert.ErrorContains(t, err, "failed to parse archive")
+}
@@ -0,0 +1,157 @@
+// Command guildconfig exports every guild's configuration (music settings and premium grants) to a
+// JSON archive and imports it into another instance, for moving the bot between hosts. Stop the bot
+// before importing, it rewrites the files from memory on its next change.
+//
+// Usage:
+//
+//	go run ./cmd/guildconfig export -out guilds.json
+//	go run ./cmd/guildconfig import -dry-run guilds.json
+//	go run ./cmd/guildconfig import guilds.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"pxnx-discord-bot/commands"
+	"pxnx-discord-bot/music/premium"
+	"pxnx-discord-bot/music/settings"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = runExport(os.Args[2:])
+	case "import":
+		err = runImport(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "guildconfig: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: guildconfig export [-out file] | import [-dry-run] file")
+}
+
+// storeFlags adds the data file flags shared by both subcommands, defaulting to the bot's environment
+func storeFlags(flags *flag.FlagSet) (settingsPath, premiumPath *string) {
+	settingsPath = flags.String("settings", commands.MusicSettingsPath(), "Music settings file (default $MUSIC_SETTINGS_FILE)")
+	premiumPath = flags.String("premium", commands.PremiumPath(), "Premium grants file (default $PREMIUM_FILE)")
+	return settingsPath, premiumPath
+}
+
+// openStores loads the music settings and premium grants the bot saved
+func openStores(settingsPath, premiumPath string) (*settings.Store, *premium.Entitlements, error) {
+	store, err := settings.Load(settingsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	entitlements, err := premium.Load(premiumPath, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, entitlements, nil
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	out := flags.String("out", "", "Archive to write (default stdout)")
+	settingsPath, premiumPath := storeFlags(flags)
+	flags.Parse(args)
+
+	store, entitlements, err := openStores(*settingsPath, *premiumPath)
+	if err != nil {
+		return err
+	}
+	archive := exportArchive(store, entitlements, time.Now())
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive: %w", err)
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d guilds to %s\n", len(archive.Guilds), *out)
+	return nil
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "Print what would change without saving")
+	settingsPath, premiumPath := storeFlags(flags)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	archive, err := parseArchive(data)
+	if err != nil {
+		return err
+	}
+
+	store, entitlements, err := openStores(*settingsPath, *premiumPath)
+	if err != nil {
+		return err
+	}
+	changes, err := diffArchive(archive, store, entitlements)
+	if err != nil {
+		return err
+	}
+	printChanges(os.Stdout, changes, len(archive.Guilds))
+
+	if *dryRun || len(changes) == 0 {
+		return nil
+	}
+	if err := applyArchive(archive, store, entitlements); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Imported into %s and %s\n", *settingsPath, *premiumPath)
+	return nil
+}
+
+// printChanges writes the changes grouped by guild, then a summary line
+func printChanges(w io.Writer, changes []Change, guilds int) {
+	changed := 0
+	for index, change := range changes {
+		if index == 0 || changes[index-1].GuildID != change.GuildID {
+			fmt.Fprintf(w, "guild %s:\n", change.GuildID)
+			changed++
+		}
+		switch {
+		case change.Old == "":
+			fmt.Fprintf(w, "  + %s = %s\n", change.Field, change.New)
+		case change.New == "":
+			fmt.Fprintf(w, "  - %s = %s\n", change.Field, change.Old)
+		default:
+			fmt.Fprintf(w, "  ~ %s: %s -> %s\n", change.Field, change.Old, change.New)
+		}
+	}
+	fmt.Fprintf(w, "%d of %d guilds change\n", changed, guilds)
+}
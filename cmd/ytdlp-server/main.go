@@ -0,0 +1,76 @@
+// Command ytdlp-server serves the yt-dlp service API (the same HTTP contract as
+// services/ytdlp/server.py) using only the yt-dlp binary, without Python or aiohttp.
+//
+// Usage:
+//
+//	go run ./cmd/ytdlp-server -port 8080 -workers 4
+//	go run ./cmd/ytdlp-server -host :: -port 8080
+//	go run ./cmd/ytdlp-server -socket /run/ytdlp/ytdlp.sock
+//	go run ./cmd/ytdlp-server -ytdlp /usr/local/bin/yt-dlp
+//	go run ./cmd/ytdlp-server -cookies cookies.txt -po-token web.gvs+TOKEN
+//	go run ./cmd/ytdlp-server -proxies http://proxy1:3128,socks5://proxy2:1080
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/tracing"
+)
+
+func main() {
+	config := ytdlp.DefaultServiceConfig()
+	flag.StringVar(&config.Host, "host", config.Host, "Host to bind to")
+	flag.IntVar(&config.Port, "port", config.Port, "Port to bind to")
+	flag.StringVar(&config.Socket, "socket", os.Getenv("YTDLP_SERVICE_SOCKET"), "Unix socket to listen on instead of host and port")
+	flag.IntVar(&config.MaxWorkers, "workers", config.MaxWorkers, "Maximum concurrent yt-dlp processes")
+	flag.StringVar(&config.BinaryPath, "ytdlp", config.BinaryPath, "yt-dlp executable")
+	flag.StringVar(&config.Format, "format", config.Format, "Default yt-dlp format selector")
+	flag.DurationVar(&config.CacheTTL, "cache-ttl", config.CacheTTL, "How long extract and search results are cached")
+	flag.StringVar(&config.Credentials.CookiesFile, "cookies", os.Getenv("YTDLP_COOKIES_FILE"), "cookies.txt passed to yt-dlp")
+	flag.StringVar(&config.Credentials.CookiesFromBrowser, "cookies-from-browser", os.Getenv("YTDLP_COOKIES_FROM_BROWSER"), "Browser yt-dlp reads cookies from")
+	flag.StringVar(&config.Credentials.POToken, "po-token", os.Getenv("YTDLP_PO_TOKEN"), "YouTube PO token, CLIENT.CONTEXT+TOKEN")
+	flag.StringVar(&config.Proxy, "proxy", os.Getenv("YTDLP_PROXY"), "Proxy every yt-dlp run goes through")
+	proxies := flag.String("proxies", os.Getenv("YTDLP_PROXIES"), "Comma separated proxies yt-dlp runs take turns between")
+	flag.Parse()
+	config.Proxies = ytdlp.ParseProxyList(*proxies)
+
+	if err := config.Credentials.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "ytdlp-server: %v\n", err)
+		os.Exit(1)
+	}
+	if err := config.ValidateProxies(); err != nil {
+		fmt.Fprintf(os.Stderr, "ytdlp-server: %v\n", err)
+		os.Exit(1)
+	}
+
+	binaryPath, err := exec.LookPath(config.BinaryPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ytdlp-server: %v\n", err)
+		os.Exit(1)
+	}
+	config.BinaryPath = binaryPath
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Requests from a traced bot continue its traces when OTEL_EXPORTER_OTLP_ENDPOINT is set
+	tracingConfig, err := tracing.ConfigFromEnv("ytdlp-server")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ytdlp-server: %v\n", err)
+		os.Exit(1)
+	}
+	shutdownTracing := tracing.Configure(tracingConfig)
+	defer shutdownTracing(context.Background())
+
+	if err := ytdlp.NewServer(config).ListenAndServe(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "ytdlp-server: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,452 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/premium"
+	"pxnx-discord-bot/music/usage"
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/utils"
+)
+
+// HandleAdminCommand handles the /admin command and its subcommands
+func HandleAdminCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	subcommand := ""
+	if options := i.ApplicationCommandData().Options; len(options) > 0 {
+		subcommand = options[0].Name
+	}
+
+	switch subcommand {
+	case "memory":
+		return handleAdminMemory(s, i)
+	case "usage":
+		return handleAdminUsage(s, i)
+	case "premium":
+		return handleAdminPremium(s, i)
+	case "logs":
+		return handleAdminLogs(s, i)
+	case "cache":
+		return handleAdminCache(s, i)
+	case "credentials":
+		return handleAdminCredentials(s, i)
+	case "backup":
+		return handleAdminBackup(s, i)
+	default:
+		return respondWithEphemeral(s, i, translate(i, "admin.unknown"))
+	}
+}
+
+// handleAdminMemory reports the size of the bot's in-memory maps and caches
+func handleAdminMemory(s SessionInterface, i *discordgo.InteractionCreate) error {
+	var playerStats *music.MemoryStats
+	if SimplePlayer != nil {
+		stats := SimplePlayer.MemoryStats()
+		playerStats = &stats
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	embed := createMemoryReportEmbed(playerStats, pendingConfirmationCount(), &memStats, runtime.NumGoroutine(), utils.TotalPanics())
+	if pools := utils.WorkerPoolStats(); len(pools) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Worker Pools", Value: formatWorkerPools(pools)})
+	}
+
+	return respondWithEphemeralEmbed(s, i, embed)
+}
+
+// createMemoryReportEmbed builds the /admin memory embed; playerStats is nil when music is disabled
+func createMemoryReportEmbed(playerStats *music.MemoryStats, pendingConfirmations int, memStats *runtime.MemStats, goroutines int, panics int64) *discordgo.MessageEmbed {
+	embed := NewEmbed("🧠 Memory Report")
+
+	if playerStats != nil {
+		embed.
+			InlineField("Voice Players", fmt.Sprintf("%d", playerStats.Players)).
+			InlineField("Disconnect Timers", fmt.Sprintf("%d", playerStats.DisconnectTimers)).
+			InlineField("Idle Timers", fmt.Sprintf("%d", playerStats.IdleTimers)).
+			InlineField("Play Times", fmt.Sprintf("%d guilds", playerStats.PlayTimes)).
+			InlineField("Filter Chains", fmt.Sprintf("%d", playerStats.FilterChains)).
+			InlineField("Queued Tracks", fmt.Sprintf("%d", playerStats.QueuedTracks)).
+			InlineField("Queue Journal Entries", fmt.Sprintf("%d", playerStats.JournalEntries)).
+			InlineField("History Entries", fmt.Sprintf("%d", playerStats.HistoryEntries)).
+			InlineField("Listening Stats", fmt.Sprintf("%d tracks", playerStats.StatsTracks)).
+			InlineField("Search Cache", fmt.Sprintf("%d / %d", playerStats.SearchCacheEntries, playerStats.SearchCacheCapacity))
+	} else {
+		embed.InlineField("Music", "Disabled")
+	}
+
+	return embed.
+		InlineField("Pending Confirmations", fmt.Sprintf("%d", pendingConfirmations)).
+		InlineField("Heap In Use", formatBytes(memStats.HeapAlloc)).
+		InlineField("Goroutines", fmt.Sprintf("%d", goroutines)).
+		InlineField("Recovered Panics", fmt.Sprintf("%d", panics)).
+		Build()
+}
+
+// formatWorkerPools lists each pool's busy workers, queue and finished and refused tasks
+func formatWorkerPools(pools []utils.PoolStats) string {
+	lines := make([]string, 0, len(pools))
+	for _, pool := range pools {
+		line := fmt.Sprintf("`%s` %d/%d busy, %d queued, %d done", pool.Name, pool.Running, pool.Workers, pool.Queued, pool.Completed)
+		if pool.Rejected > 0 {
+			line += fmt.Sprintf(", %d refused", pool.Rejected)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// usageReportRows caps how many guilds and providers /admin usage lists
+const usageReportRows = 10
+
+// handleAdminUsage reports the audio bandwidth streamed per guild and provider
+func handleAdminUsage(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithEphemeral(s, i, translate(i, "settings.music_unavailable"))
+	}
+
+	embed := createUsageReportEmbed(SimplePlayer.Usage(), i.GuildID)
+
+	return respondWithEphemeralEmbed(s, i, embed)
+}
+
+// createUsageReportEmbed builds the /admin usage embed, marking the guild it was requested from
+func createUsageReportEmbed(report usage.Report, guildID string) *discordgo.MessageEmbed {
+	monthlyCap := "Unlimited"
+	if report.MonthlyCap > 0 {
+		monthlyCap = formatBytes(uint64(report.MonthlyCap)) + " per server"
+	}
+
+	var thisServer int64
+	for _, entry := range report.Guilds {
+		if entry.Key == guildID {
+			thisServer = entry.Bytes
+		}
+	}
+
+	return NewEmbed("📶 Bandwidth Usage").
+		InlineField("This Month", formatBytes(uint64(report.MonthBytes))).
+		InlineField("Since Restart", formatBytes(uint64(report.TotalBytes))).
+		InlineField("Monthly Cap", monthlyCap).
+		InlineField("This Server", formatBytes(uint64(thisServer))).
+		Field("Top Servers", formatUsageEntries(report.Guilds, guildID)).
+		Field("Providers", formatUsageEntries(report.Providers, "")).
+		Footer(fmt.Sprintf("Month %s (UTC) • Counters start over when the bot restarts", report.Month)).
+		Build()
+}
+
+// formatUsageEntries lists the largest entries of a usage report, marking the highlighted key
+func formatUsageEntries(entries []usage.Entry, highlight string) string {
+	if len(entries) == 0 {
+		return "Nothing streamed yet"
+	}
+
+	var lines []string
+	for index, entry := range entries {
+		if index >= usageReportRows {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(entries)-usageReportRows))
+			break
+		}
+		line := fmt.Sprintf("`%s` %s", entry.Key, formatBytes(uint64(entry.Bytes)))
+		if entry.Key == highlight {
+			line += " (this server)"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleAdminLogs attaches the warnings and errors kept in memory, optionally only errors or only one module
+func handleAdminLogs(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if !IsBotOwner(getInteractionUserID(i)) {
+		return respondWithEphemeral(s, i, translate(i, "admin.logs_owner_only"))
+	}
+
+	level, module := utils.LogLevelWarn, ""
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		switch option.Name {
+		case "level":
+			level = utils.GetLogLevelFromString(option.StringValue())
+		case "module":
+			module = option.StringValue()
+		}
+	}
+
+	entries := utils.RecentLogs(level, module)
+	summary := summarizeLogEntries(entries, level, module)
+	if len(entries) == 0 {
+		return respondWithEphemeral(s, i, summary)
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: summary,
+			Files: []*discordgo.File{{
+				Name:        fmt.Sprintf("logs-%s.txt", time.Now().UTC().Format("20060102-150405")),
+				ContentType: "text/plain",
+				Reader:      bytes.NewReader(formatLogEntries(entries)),
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// summarizeLogEntries is the message shown above the attached log entries
+func summarizeLogEntries(entries []utils.LogEntry, level utils.LogLevel, module string) string {
+	kind := "warnings and errors"
+	if level == utils.LogLevelError {
+		kind = "errors"
+	}
+	if module != "" {
+		kind += " from " + module
+	}
+
+	if len(entries) == 0 {
+		return fmt.Sprintf("📋 No %s logged recently", kind)
+	}
+	return fmt.Sprintf("📋 %d recent %s, oldest first (since <t:%d:R>)", len(entries), kind, entries[0].Time.Unix())
+}
+
+// formatLogEntries renders log entries one per line like the log file, in UTC
+func formatLogEntries(entries []utils.LogEntry) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		fmt.Fprintf(&buf, "%s [%s] %s: %s", entry.Time.UTC().Format("2006-01-02 15:04:05"),
+			strings.ToUpper(entry.Level.String()), entry.Module, entry.Message)
+		if !entry.Request.IsZero() {
+			fmt.Fprintf(&buf, " [%s]", entry.Request)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// handleAdminPremium shows the server's tier, or lets bot owners grant or revoke premium by hand
+func handleAdminPremium(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithEphemeral(s, i, translate(i, "settings.music_unavailable"))
+	}
+
+	action, days := "show", int64(0)
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		switch option.Name {
+		case "action":
+			action = option.StringValue()
+		case "days":
+			days = option.IntValue()
+		}
+	}
+
+	entitlements := SimplePlayer.Premium()
+	if action != "show" {
+		if !IsBotOwner(getInteractionUserID(i)) {
+			return respondWithEphemeral(s, i, translate(i, "admin.premium_owner_only"))
+		}
+		if !entitlements.Enabled() {
+			return respondWithEphemeral(s, i, translate(i, "admin.premium_off"))
+		}
+	}
+
+	switch action {
+	case "grant":
+		var expires time.Time
+		if days > 0 {
+			expires = time.Now().Add(time.Duration(days) * 24 * time.Hour)
+		}
+		if err := entitlements.Grant(i.GuildID, expires, "granted by "+getInteractionUserID(i)); err != nil {
+			return respondWithEphemeral(s, i, translate(i, "admin.premium_grant_not_saved", err))
+		}
+	case "revoke":
+		if err := entitlements.Revoke(i.GuildID); err != nil {
+			return respondWithEphemeral(s, i, translate(i, "admin.premium_revoke_not_saved", err))
+		}
+	}
+
+	embed := createPremiumEmbed(entitlements.Enabled(), entitlements.Status(i.GuildID))
+	return respondWithEphemeralEmbed(s, i, embed)
+}
+
+// createPremiumEmbed describes a server's tier, where it comes from and the limits it gets
+func createPremiumEmbed(enabled bool, status premium.Status) *discordgo.MessageEmbed {
+	limits := premium.LimitsFor(status.Tier)
+
+	tier := "Free"
+	embed := NewEmbed("💎 Premium").Style(StyleMuted)
+	if status.Tier == premium.Premium {
+		tier = "⭐ Premium"
+		embed.Color(0xf1c40f) // Gold
+	}
+
+	source := "Not premium"
+	switch {
+	case !enabled:
+		source = "Tiers are off, every server gets premium limits"
+	case status.Source == premium.SourceGrant:
+		source = "Granted by a bot owner"
+	case status.Source == premium.SourceSubscription:
+		source = "Discord subscription"
+	}
+
+	expires := "Never"
+	if !status.Expires.IsZero() {
+		expires = fmt.Sprintf("<t:%d:R>", status.Expires.Unix())
+	}
+
+	maxQueue := "Unlimited"
+	if limits.MaxQueue > 0 {
+		maxQueue = fmt.Sprintf("%d songs", limits.MaxQueue)
+	}
+
+	embed.InlineField("Tier", tier).InlineField("Source", source)
+	if status.Tier == premium.Premium && enabled {
+		embed.InlineField("Expires", expires)
+	}
+	return embed.
+		InlineField("Queue Size", maxQueue).
+		InlineField("Audio Quality", fmt.Sprintf("%d kbps", limits.Bitrate)).
+		InlineField("Audio Filters", formatAllowed(limits.Filters)).
+		InlineField("24/7 Mode", formatAllowed(limits.StayConnected)).
+		Build()
+}
+
+// formatAllowed renders whether a tier includes a feature
+func formatAllowed(allowed bool) string {
+	if allowed {
+		return "✅ Included"
+	}
+	return "❌ Premium only"
+}
+
+// formatBytes renders a byte count in human readable units
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// handleAdminCache shows the yt-dlp metadata cache; bot owners can clear it
+func handleAdminCache(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithEphemeral(s, i, translate(i, "settings.music_unavailable"))
+	}
+
+	action := "show"
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		if option.Name == "action" {
+			action = option.StringValue()
+		}
+	}
+
+	cache := SimplePlayer.MetadataCache()
+	if action == "clear" {
+		if !IsBotOwner(getInteractionUserID(i)) {
+			return respondWithEphemeral(s, i, translate(i, "admin.cache_owner_only"))
+		}
+		if err := cache.Purge(); err != nil {
+			return respondWithEphemeral(s, i, translate(i, "admin.cache_not_saved", err))
+		}
+	}
+
+	return respondWithEphemeralEmbed(s, i, createCacheEmbed(cache.Stats()))
+}
+
+// createCacheEmbed builds the /admin cache embed
+func createCacheEmbed(stats ytdlp.CacheStats) *discordgo.MessageEmbed {
+	hitRate := "No lookups yet"
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		hitRate = fmt.Sprintf("%.0f%% (%d of %d)", float64(stats.Hits)*100/float64(lookups), stats.Hits, lookups)
+	}
+	ttl := "Disabled"
+	if stats.TTL > 0 {
+		ttl = stats.TTL.String() + ", less when a stream URL expires sooner"
+	}
+	storage := "Memory only"
+	if stats.Path != "" {
+		storage = "`" + stats.Path + "`"
+	}
+
+	return NewEmbed("🗃️ yt-dlp Cache").
+		InlineField("Tracks", fmt.Sprintf("%d / %d", stats.Entries, stats.Capacity)).
+		InlineField("Search Queries", fmt.Sprintf("%d", stats.Queries)).
+		InlineField("Hit Rate", hitRate).
+		Field("Lifetime", ttl).
+		Field("Saved To", storage).
+		Build()
+}
+
+// handleAdminCredentials shows the cookies and PO token yt-dlp runs with, or reloads them from the
+// environment after they were rotated. Both are bot owner only, as the credentials sign in to an account.
+func handleAdminCredentials(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if !IsBotOwner(getInteractionUserID(i)) {
+		return respondWithEphemeral(s, i, translate(i, "admin.credentials_owner_only"))
+	}
+	if SimplePlayer == nil {
+		return respondWithEphemeral(s, i, translate(i, "settings.music_unavailable"))
+	}
+
+	action := "show"
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		if option.Name == "action" {
+			action = option.StringValue()
+		}
+	}
+
+	if action == "reload" {
+		credentials, err := LoadYtdlpCredentials()
+		if err != nil {
+			utils.LogWarn("Kept the previous yt-dlp credentials: %v", err)
+			return respondWithEphemeral(s, i, translate(i, "admin.credentials_kept", err))
+		}
+		SimplePlayer.UseYtdlpCredentials(credentials)
+		utils.LogInfo("Reloaded yt-dlp credentials")
+	}
+
+	return respondWithEphemeralEmbed(s, i, createCredentialsEmbed(SimplePlayer.YtdlpCredentials(), action == "reload"))
+}
+
+// createCredentialsEmbed builds the /admin credentials embed without revealing the PO token
+func createCredentialsEmbed(credentials ytdlp.Credentials, reloaded bool) *discordgo.MessageEmbed {
+	cookies := "None"
+	switch {
+	case credentials.CookiesFile != "":
+		cookies = "`" + credentials.CookiesFile + "`"
+		if info, err := os.Stat(credentials.CookiesFile); err != nil {
+			cookies += "\n⚠️ Can't be read"
+		} else {
+			cookies += fmt.Sprintf("\nUpdated <t:%d:R>", info.ModTime().Unix())
+		}
+	case credentials.CookiesFromBrowser != "":
+		cookies = "From " + credentials.CookiesFromBrowser
+	}
+	poToken := "None"
+	if credentials.POToken != "" {
+		poToken = "`" + maskSecret(credentials.POToken) + "`"
+	}
+
+	embed := NewEmbed("🔑 yt-dlp Credentials").
+		Field("Cookies", cookies).
+		Field("PO Token", poToken)
+	if reloaded {
+		embed.Style(StyleSuccess).Description("✅ Reloaded from the environment")
+	}
+	if credentials.IsZero() {
+		embed.Footer("Set YTDLP_COOKIES_FILE, YTDLP_COOKIES_FROM_BROWSER or YTDLP_PO_TOKEN to play age-restricted videos")
+	}
+	return embed.Build()
+}
@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/usage"
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/testutils"
+	"pxnx-discord-bot/utils"
+)
+
+func newAdminInteraction(subcommand string) *discordgo.InteractionCreate {
+	return testutils.CreateTestInteraction("admin", []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: subcommand, Type: discordgo.ApplicationCommandOptionSubCommand},
+	})
+}
+
+func TestHandleAdminMemoryWithoutMusic(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+
+	err := HandleAdminCommand(mockSession, newAdminInteraction("memory"))
+	require.NoError(t, err)
+
+	require.True(t, mockSession.RespondCalled)
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+
+	embed := mockSession.RespondData.Embeds[0]
+	assert.Equal(t, "🧠 Memory Report", embed.Title)
+	assert.Equal(t, "Music", embed.Fields[0].Name)
+	assert.Equal(t, "Disabled", embed.Fields[0].Value)
+}
+
+func TestHandleAdminUnknownSubcommand(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+
+	err := HandleAdminCommand(mockSession, testutils.CreateTestInteraction("admin", nil))
+	require.NoError(t, err)
+	assert.Contains(t, mockSession.RespondData.Content, "Unknown admin subcommand")
+}
+
+func TestCreateMemoryReportEmbed(t *testing.T) {
+	stats := &music.MemoryStats{
+		Players:             2,
+		DisconnectTimers:    1,
+		IdleTimers:          2,
+		PlayTimes:           6,
+		FilterChains:        1,
+		QueuedTracks:        12,
+		JournalEntries:      3,
+		HistoryEntries:      8,
+		StatsTracks:         40,
+		SearchCacheEntries:  5,
+		SearchCacheCapacity: 200,
+	}
+	memStats := &runtime.MemStats{HeapAlloc: 3 * 1024 * 1024}
+
+	embed := createMemoryReportEmbed(stats, 4, memStats, 17, 2)
+
+	values := make(map[string]string)
+	for _, field := range embed.Fields {
+		values[field.Name] = field.Value
+	}
+	assert.Equal(t, "2", values["Voice Players"])
+	assert.Equal(t, "1", values["Disconnect Timers"])
+	assert.Equal(t, "2", values["Idle Timers"])
+	assert.Equal(t, "6 guilds", values["Play Times"])
+	assert.Equal(t, "1", values["Filter Chains"])
+	assert.Equal(t, "12", values["Queued Tracks"])
+	assert.Equal(t, "3", values["Queue Journal Entries"])
+	assert.Equal(t, "8", values["History Entries"])
+	assert.Equal(t, "40 tracks", values["Listening Stats"])
+	assert.Equal(t, "5 / 200", values["Search Cache"])
+	assert.Equal(t, "4", values["Pending Confirmations"])
+	assert.Equal(t, "3.0 MiB", values["Heap In Use"])
+	assert.Equal(t, "17", values["Goroutines"])
+	assert.Equal(t, "2", values["Recovered Panics"])
+}
+
+func TestHandleAdminUsageWithoutMusic(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+
+	err := HandleAdminCommand(mockSession, newAdminInteraction("usage"))
+	require.NoError(t, err)
+
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+	assert.Contains(t, mockSession.RespondData.Content, "Music system is not available")
+}
+
+func TestCreateUsageReportEmbed(t *testing.T) {
+	report := usage.Report{
+		Month:      "2025-03",
+		MonthBytes: 3 << 30,
+		TotalBytes: 5 << 30,
+		Guilds:     []usage.Entry{{Key: "guild1", Bytes: 2 << 30}, {Key: "guild2", Bytes: 1 << 30}},
+		Providers:  []usage.Entry{{Key: "youtube", Bytes: 3 << 30}},
+		MonthlyCap: 20 << 30,
+	}
+
+	embed := createUsageReportEmbed(report, "guild2")
+
+	values := make(map[string]string)
+	for _, field := range embed.Fields {
+		values[field.Name] = field.Value
+	}
+	assert.Equal(t, "3.0 GiB", values["This Month"])
+	assert.Equal(t, "5.0 GiB", values["Since Restart"])
+	assert.Equal(t, "20.0 GiB per server", values["Monthly Cap"])
+	assert.Equal(t, "1.0 GiB", values["This Server"])
+	assert.Equal(t, "`guild1` 2.0 GiB\n`guild2` 1.0 GiB (this server)", values["Top Servers"])
+	assert.Equal(t, "`youtube` 3.0 GiB", values["Providers"])
+	assert.Contains(t, embed.Footer.Text, "2025-03")
+}
+
+func TestCreateUsageReportEmbedEmpty(t *testing.T) {
+	embed := createUsageReportEmbed(usage.Report{Month: "2025-03"}, "guild1")
+
+	values := make(map[string]string)
+	for _, field := range embed.Fields {
+		values[field.Name] = field.Value
+	}
+	assert.Equal(t, "Unlimited", values["Monthly Cap"])
+	assert.Equal(t, "0 B", values["This Server"])
+	assert.Equal(t, "Nothing streamed yet", values["Top Servers"])
+}
+
+func TestFormatUsageEntriesTruncates(t *testing.T) {
+	entries := make([]usage.Entry, usageReportRows+3)
+	for index := range entries {
+		entries[index] = usage.Entry{Key: fmt.Sprintf("guild%d", index), Bytes: 1024}
+	}
+
+	formatted := formatUsageEntries(entries, "")
+	assert.Equal(t, usageReportRows+1, strings.Count(formatted, "\n")+1)
+	assert.True(t, strings.HasSuffix(formatted, "... and 3 more"))
+}
+
+func TestPendingConfirmationCountDropsExpired(t *testing.T) {
+	pendingConfirmationsMu.Lock()
+	pendingConfirmations = map[string]*pendingConfirmation{
+		"live":    {expiresAt: time.Now().Add(time.Minute)},
+		"expired": {expiresAt: time.Now().Add(-time.Minute)},
+	}
+	pendingConfirmationsMu.Unlock()
+	t.Cleanup(func() {
+		pendingConfirmationsMu.Lock()
+		pendingConfirmations = make(map[string]*pendingConfirmation)
+		pendingConfirmationsMu.Unlock()
+	})
+
+	assert.Equal(t, 1, pendingConfirmationCount())
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "2.0 GiB", formatBytes(2*1024*1024*1024))
+}
+
+func TestFormatLogEntries(t *testing.T) {
+	at := time.Date(2025, time.March, 1, 12, 30, 0, 0, time.UTC)
+	entries := []utils.LogEntry{
+		{Time: at, Level: utils.LogLevelWarn, Module: "music", Message: "Voice connection slow"},
+		{Time: at.Add(time.Second), Level: utils.LogLevelError, Module: "services/ytdlp", Message: "yt-dlp failed",
+			Request: utils.RequestInfo{Command: "play", GuildID: "123"}},
+	}
+
+	assert.Equal(t, "2025-03-01 12:30:00 [WARN] music: Voice connection slow\n"+
+		"2025-03-01 12:30:01 [ERROR] services/ytdlp: yt-dlp failed [command=play guild=123]\n", string(formatLogEntries(entries)))
+
+	assert.Equal(t, "📋 No errors from music logged recently", summarizeLogEntries(nil, utils.LogLevelError, "music"))
+	assert.True(t, strings.HasPrefix(summarizeLogEntries(entries, utils.LogLevelWarn, ""), "📋 2 recent warnings and errors"))
+}
+
+func TestHandleAdminLogsOwnerOnly(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+
+	require.NoError(t, HandleAdminCommand(mockSession, newAdminInteraction("logs")))
+	require.True(t, mockSession.RespondCalled)
+	assert.Contains(t, mockSession.RespondData.Content, "Only the bot owner")
+}
+
+func TestHandleAdminCacheWithoutMusic(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+
+	require.NoError(t, HandleAdminCommand(mockSession, newAdminInteraction("cache")))
+	assert.Contains(t, mockSession.RespondData.Content, "Music system is not available")
+}
+
+func TestCreateCacheEmbed(t *testing.T) {
+	embed := createCacheEmbed(ytdlp.CacheStats{
+		Entries:  12,
+		Queries:  5,
+		Capacity: 1000,
+		Hits:     3,
+		Misses:   1,
+		TTL:      24 * time.Hour,
+		Path:     "data/ytdlp-cache.json",
+	})
+
+	values := make(map[string]string)
+	for _, field := range embed.Fields {
+		values[field.Name] = field.Value
+	}
+	assert.Equal(t, "12 / 1000", values["Tracks"])
+	assert.Equal(t, "5", values["Search Queries"])
+	assert.Equal(t, "75% (3 of 4)", values["Hit Rate"])
+	assert.Equal(t, "`data/ytdlp-cache.json`", values["Saved To"])
+
+	empty := createCacheEmbed(ytdlp.CacheStats{Capacity: 1000})
+	assert.Equal(t, "No lookups yet", empty.Fields[2].Value)
+	assert.Equal(t, "Disabled", empty.Fields[3].Value)
+	assert.Equal(t, "Memory only", empty.Fields[4].Value)
+}
+
+func TestFormatWorkerPools(t *testing.T) {
+	formatted := formatWorkerPools([]utils.PoolStats{
+		{Name: "playlist", Workers: 2, Running: 1, Queued: 0, Completed: 5},
+		{Name: "prefetch", Workers: 8, Running: 8, Queued: 64, Completed: 120, Rejected: 3},
+	})
+
+	assert.Equal(t, "`playlist` 1/2 busy, 0 queued, 5 done\n`prefetch` 8/8 busy, 64 queued, 120 done, 3 refused", formatted)
+}
@@ -0,0 +1,261 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/services/llm"
+	"pxnx-discord-bot/utils"
+)
+
+// askTimeout bounds how long /ask waits for the model
+const askTimeout = 90 * time.Second
+
+// LLM answers /ask in each server's persona, nil when AI_ENDPOINT is not set
+var LLM *llm.Service
+
+// InitializeLLM sets up AI chat when AI_ENDPOINT is set, loading the servers' personas from AI_PERSONAS_FILE
+func InitializeLLM() {
+	config := llm.ConfigFromEnv()
+	if config.Endpoint == "" {
+		return
+	}
+	personas, err := llm.LoadPersonas(AIPersonasPath())
+	if err != nil {
+		utils.LogWarn("AI personas will not be saved this run: %v", err)
+		personas = llm.NewPersonaStore()
+	}
+	LLM = llm.NewService(llm.NewClient(config), personas)
+	utils.LogInfo("AI chat asks %s at %s", config.Model, config.Endpoint)
+}
+
+// AIPersonasPath returns where server personas are saved, AI_PERSONAS_FILE or the default
+func AIPersonasPath() string {
+	if path := strings.TrimSpace(os.Getenv("AI_PERSONAS_FILE")); path != "" {
+		return path
+	}
+	return llm.DefaultPersonasPath
+}
+
+// HandleAskCommand handles /ask: chat answers a question with the language model in the server's persona,
+// following up on the channel's conversation, and reset starts the conversation over
+func HandleAskCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if LLM == nil {
+		return respondWithEphemeral(s, i, translate(i, "ai.not_set_up"))
+	}
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return respondWithEphemeral(s, i, translate(i, "ai.unknown_ask"))
+	}
+	switch options[0].Name {
+	case "chat":
+	case "reset":
+		if !LLM.ResetConversation(i.ChannelID) {
+			return respondWithEphemeral(s, i, translate(i, "ai.nothing_to_forget"))
+		}
+		return respondWithInteraction(s, i, translate(i, "ai.forgotten"))
+	default:
+		return respondWithEphemeral(s, i, translate(i, "ai.unknown_ask"))
+	}
+	question := strings.TrimSpace(subcommandString(options[0], "question"))
+	if !LLM.Personas().Get(i.GuildID).Allows(i.ChannelID) {
+		return respondWithEphemeral(s, i, translate(i, "ai.channel_not_allowed"))
+	}
+
+	ctx, cancel := context.WithTimeout(RequestContext(i), askTimeout)
+	defer cancel()
+	answer, err := LLM.Ask(ctx, i.GuildID, i.ChannelID, question)
+	if errors.Is(err, llm.ErrChannelNotAllowed) {
+		return respondWithError(s, i, translate(i, "ai.channel_not_allowed_short"))
+	}
+	if err != nil {
+		utils.LogWarnContext(ctx, "Failed to answer /ask: %v", err)
+		return respondWithError(s, i, translate(i, "ai.no_answer"))
+	}
+
+	// Answers never ping anyone, whatever the model writes
+	content := truncateText(fmt.Sprintf("> %s\n%s", truncateText(question, 200), answer), maxMessageLength)
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:         &content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	return err
+}
+
+// HandleMention answers messages that mention the bot like /ask chat, in the same conversation. Mentions in
+// channels AI chat isn't on in and messages of bots are ignored.
+func HandleMention(s SessionInterface, m *discordgo.MessageCreate, botID string) error {
+	if LLM == nil || m.Author == nil || m.Author.Bot || !mentions(m.Message, botID) {
+		return nil
+	}
+	if !LLM.Personas().Get(m.GuildID).Allows(m.ChannelID) {
+		return nil
+	}
+	question := strings.TrimSpace(strings.NewReplacer("<@"+botID+">", "", "<@!"+botID+">", "").Replace(m.Content))
+	if question == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), askTimeout)
+	defer cancel()
+	answer, err := LLM.Ask(ctx, m.GuildID, m.ChannelID, question)
+	if errors.Is(err, llm.ErrChannelNotAllowed) {
+		return nil
+	}
+	if err != nil {
+		utils.LogWarnContext(ctx, "Failed to answer a mention in channel %s: %v", m.ChannelID, err)
+		answer = "❌ I couldn't come up with an answer right now, try again in a moment"
+	}
+
+	// Answers never ping anyone, whatever the model writes
+	_, err = s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content:         truncateText(answer, maxMessageLength),
+		Reference:       m.Reference(),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to answer mention: %w", err)
+	}
+	return nil
+}
+
+// mentions reports whether a message mentions a user
+func mentions(m *discordgo.Message, userID string) bool {
+	for _, user := range m.Mentions {
+		if user.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleAICommand handles /ai, which shows and changes the server's AI persona, temperature and channels
+func HandleAICommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if LLM == nil {
+		return respondWithEphemeral(s, i, translate(i, "ai.not_set_up"))
+	}
+	if i.GuildID == "" {
+		return respondWithEphemeral(s, i, translate(i, "ai.server_only"))
+	}
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return respondWithEphemeral(s, i, translate(i, "ai.unknown"))
+	}
+	subcommand := options[0]
+
+	limits := llm.CurrentLimits()
+	var change func(*llm.Persona)
+	switch subcommand.Name {
+	case "show":
+		return respondWithEmbed(s, i, createAIPersonaEmbed(LLM.Personas().Get(i.GuildID), limits, LLM.Model()))
+	case "persona":
+		prompt := strings.TrimSpace(subcommandString(subcommand, "prompt"))
+		if limits.MaxPersonaLength == 0 {
+			return respondWithEphemeral(s, i, translate(i, "ai.personas_off"))
+		}
+		if length := len([]rune(prompt)); length > limits.MaxPersonaLength {
+			return respondWithEphemeral(s, i, translate(i, "ai.persona_too_long", length, limits.MaxPersonaLength))
+		}
+		change = func(p *llm.Persona) { p.Prompt = prompt }
+	case "temperature":
+		value := subcommandNumber(subcommand, "value")
+		if value > limits.MaxTemperature {
+			return respondWithEphemeral(s, i, translate(i, "ai.temperature_too_high", limits.MaxTemperature))
+		}
+		change = func(p *llm.Persona) { p.Temperature = &value }
+	case "channel":
+		channelID, allowed := "", true
+		for _, option := range subcommand.Options {
+			switch option.Name {
+			case "channel":
+				channelID = option.ChannelValue(nil).ID
+			case "allowed":
+				allowed = option.BoolValue()
+			}
+		}
+		change = func(p *llm.Persona) { p.Channels = setChannelAllowed(p.Channels, channelID, allowed) }
+	case "reset":
+		change = func(p *llm.Persona) { *p = llm.Persona{} }
+	default:
+		return respondWithEphemeral(s, i, translate(i, "ai.unknown"))
+	}
+
+	persona, err := LLM.Personas().Update(i.GuildID, change)
+	embed := createAIPersonaEmbed(persona, limits, LLM.Model())
+	if err != nil {
+		utils.LogWarnContext(RequestContext(i), "Failed to save the AI persona of guild %s: %v", i.GuildID, err)
+		embed.Description = "⚠️ The change could not be saved and resets when the bot restarts"
+	}
+	return respondWithEmbed(s, i, embed)
+}
+
+// setChannelAllowed adds a channel to or removes it from the channels AI chat is on in
+func setChannelAllowed(channels []string, channelID string, allowed bool) []string {
+	kept := channels[:0]
+	for _, channel := range channels {
+		if channel != channelID {
+			kept = append(kept, channel)
+		}
+	}
+	if allowed {
+		kept = append(kept, channelID)
+	}
+	return kept
+}
+
+// subcommandString returns a string option of a subcommand, empty when not given
+func subcommandString(subcommand *discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	for _, option := range subcommand.Options {
+		if option.Name == name {
+			return option.StringValue()
+		}
+	}
+	return ""
+}
+
+// subcommandNumber returns a number option of a subcommand, 0 when not given
+func subcommandNumber(subcommand *discordgo.ApplicationCommandInteractionDataOption, name string) float64 {
+	for _, option := range subcommand.Options {
+		if option.Name == name {
+			return option.FloatValue()
+		}
+	}
+	return 0
+}
+
+// createAIPersonaEmbed shows a server's AI persona and the limits it stays within
+func createAIPersonaEmbed(persona llm.Persona, limits llm.Limits, model string) *discordgo.MessageEmbed {
+	prompt := "None, the model answers as itself"
+	if persona.Prompt != "" {
+		prompt = limits.PersonaPrompt(persona)
+	}
+	temperature := fmt.Sprintf("%g (default)", limits.Temperature(persona))
+	if persona.Temperature != nil {
+		temperature = fmt.Sprintf("%g", limits.Temperature(persona))
+		if *persona.Temperature > limits.MaxTemperature {
+			temperature += fmt.Sprintf(" (%g is above this bot's limit)", *persona.Temperature)
+		}
+	}
+	channels := "Every channel"
+	if len(persona.Channels) > 0 {
+		mentions := make([]string, len(persona.Channels))
+		for n, channelID := range persona.Channels {
+			mentions[n] = "<#" + channelID + ">"
+		}
+		channels = strings.Join(mentions, ", ")
+	}
+
+	return NewEmbed("🤖 AI Persona").
+		Field("Persona", prompt).
+		InlineField("Temperature", temperature).
+		InlineField("Model", model).
+		Field("Channels", channels).
+		Footer(fmt.Sprintf("Change these with /ai. Personas are at most %d characters and temperatures at most %g on this bot.", limits.MaxPersonaLength, limits.MaxTemperature)).
+		Build()
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/services/llm"
+	"pxnx-discord-bot/testutils"
+)
+
+// useLLM installs an AI service asking endpoint with in-memory personas for the duration of a test
+func useLLM(t *testing.T, endpoint string) *llm.PersonaStore {
+	original := LLM
+	personas := llm.NewPersonaStore()
+	LLM = llm.NewService(llm.NewClient(llm.Config{Endpoint: endpoint, Model: "test-model"}), personas)
+	t.Cleanup(func() { LLM = original })
+	return personas
+}
+
+// aiSubcommand creates an /ai interaction running a subcommand with options
+func aiSubcommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return testutils.CreateTestInteraction("ai", []*discordgo.ApplicationCommandInteractionDataOption{{
+		Name:    name,
+		Type:    discordgo.ApplicationCommandOptionSubCommand,
+		Options: options,
+	}})
+}
+
+func TestHandleAICommandChangesPersona(t *testing.T) {
+	personas := useLLM(t, "http://localhost")
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleAICommand(mockSession, aiSubcommand("persona", testutils.CreateStringOption("prompt", " You are a pirate. "))))
+	assert.Equal(t, "You are a pirate.", personas.Get("guild_id_123").Prompt)
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Equal(t, "You are a pirate.", mockSession.RespondData.Embeds[0].Fields[0].Value)
+
+	mockSession = &testutils.MockSession{}
+	tooHot := &discordgo.ApplicationCommandInteractionDataOption{Name: "value", Type: discordgo.ApplicationCommandOptionNumber, Value: 1.9}
+	require.NoError(t, HandleAICommand(mockSession, aiSubcommand("temperature", tooHot)))
+	assert.Contains(t, mockSession.RespondData.Content, "at most 1.2")
+	assert.Nil(t, personas.Get("guild_id_123").Temperature, "temperatures above the bot's limit are refused")
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleAICommand(mockSession, aiSubcommand("channel", testutils.CreateChannelOption("channel", "channel_1"))))
+	assert.Equal(t, []string{"channel_1"}, personas.Get("guild_id_123").Channels)
+
+	require.NoError(t, HandleAICommand(&testutils.MockSession{}, aiSubcommand("reset")))
+	assert.True(t, personas.Get("guild_id_123").IsZero())
+}
+
+func TestHandleAskCommand(t *testing.T) {
+	asked := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asked++
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"Forty-two."}}]}`)
+	}))
+	defer server.Close()
+	personas := useLLM(t, server.URL)
+
+	interaction := testutils.CreateTestInteraction("ask", []*discordgo.ApplicationCommandInteractionDataOption{{
+		Name:    "chat",
+		Type:    discordgo.ApplicationCommandOptionSubCommand,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{testutils.CreateStringOption("question", "What is the answer?")},
+	}})
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleAskCommand(mockSession, interaction))
+	assert.True(t, mockSession.InteractionResponseEditCalled)
+	assert.Equal(t, 1, asked)
+
+	_, err := personas.Update("guild_id_123", func(p *llm.Persona) { p.Channels = []string{"other_channel"} })
+	require.NoError(t, err)
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleAskCommand(mockSession, interaction))
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+	assert.Contains(t, mockSession.RespondData.Content, "not allowed in this channel")
+	assert.Equal(t, 1, asked, "questions in other channels don't reach the model")
+
+	reset := testutils.CreateTestInteraction("ask", []*discordgo.ApplicationCommandInteractionDataOption{{
+		Name: "reset", Type: discordgo.ApplicationCommandOptionSubCommand,
+	}})
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleAskCommand(mockSession, reset))
+	assert.Contains(t, mockSession.RespondData.Content, "Conversation forgotten")
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleAskCommand(mockSession, reset))
+	assert.Contains(t, mockSession.RespondData.Content, "no conversation to forget")
+}
+
+func TestHandleMention(t *testing.T) {
+	var question string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []llm.Message `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		question = body.Messages[len(body.Messages)-1].Content
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"Forty-two."}}]}`)
+	}))
+	defer server.Close()
+	useLLM(t, server.URL)
+
+	message := &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        "message_1",
+		ChannelID: "channel_id_123",
+		GuildID:   "guild_id_123",
+		Content:   "<@bot_1> what is the answer?",
+		Author:    &discordgo.User{ID: "user_1"},
+		Mentions:  []*discordgo.User{{ID: "bot_1"}},
+	}}
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleMention(mockSession, message, "bot_1"))
+	assert.Equal(t, "what is the answer?", question, "the mention is left out of the question")
+	require.True(t, mockSession.ChannelMessageSendCalled)
+	assert.Equal(t, "Forty-two.", mockSession.ChannelMessageSendData.Content)
+	assert.Equal(t, "message_1", mockSession.ChannelMessageSendData.Reference.MessageID)
+
+	message.Author.Bot = true
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleMention(mockSession, message, "bot_1"))
+	assert.False(t, mockSession.ChannelMessageSendCalled, "bots aren't answered")
+
+	message.Author.Bot = false
+	message.Mentions = nil
+	require.NoError(t, HandleMention(mockSession, message, "bot_1"))
+	assert.False(t, mockSession.ChannelMessageSendCalled, "messages that don't mention the bot aren't answered")
+}
+
+func TestHandleAskCommandWithoutLLM(t *testing.T) {
+	original := LLM
+	LLM = nil
+	t.Cleanup(func() { LLM = original })
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleAskCommand(mockSession, testutils.CreateTestInteraction("ask", nil)))
+	assert.Contains(t, mockSession.RespondData.Content, "not set up")
+}
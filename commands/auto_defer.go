@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// AutoDeferAfter is how long a slow command may work before it's acknowledged, within the 3 seconds
+// Discord waits for a response
+const AutoDeferAfter = 2 * time.Second
+
+// deferState is how far an interaction has been answered
+type deferState int
+
+const (
+	deferPending   deferState = iota // Nothing was sent yet
+	deferDeferred                    // Acknowledged, the response is edited from now on
+	deferResponded                   // Answered with a message
+)
+
+// AutoDeferSession answers the interaction of a slow command. When the handler hasn't answered by the
+// time it's given, the session acknowledges the interaction and turns the handler's responses into edits
+// of the acknowledged response; when the handler answers in time, its edits become the response. Handlers
+// can respond or edit either way without knowing which happened. Ephemeral responses end up public once
+// the interaction was acknowledged, as Discord can't make the acknowledged response ephemeral.
+type AutoDeferSession struct {
+	SessionInterface
+	i     *discordgo.InteractionCreate
+	timer *time.Timer
+
+	mu       sync.Mutex // Held while answering so the timer and the handler can't both respond
+	state    deferState
+	deferErr error
+}
+
+// AutoDefer wraps s for the handler of interaction i, acknowledging it unless answered within after
+func AutoDefer(s SessionInterface, i *discordgo.InteractionCreate, after time.Duration) *AutoDeferSession {
+	a := &AutoDeferSession{SessionInterface: s, i: i}
+	a.timer = time.AfterFunc(after, a.acknowledge)
+	return a
+}
+
+// Stop stops waiting to acknowledge the interaction and returns the error acknowledging it, if it was
+func (a *AutoDeferSession) Stop() error {
+	a.timer.Stop()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.deferErr
+}
+
+// acknowledge defers the response when the handler hasn't answered yet
+func (a *AutoDeferSession) acknowledge() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.state != deferPending {
+		return
+	}
+	err := a.SessionInterface.InteractionRespond(a.i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		a.deferErr = fmt.Errorf("failed to defer response: %w", err)
+		return
+	}
+	a.state = deferDeferred
+}
+
+// InteractionRespond answers the interaction, or edits the acknowledged response with the message
+func (a *AutoDeferSession) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if interaction.ID != a.i.ID {
+		return a.SessionInterface.InteractionRespond(interaction, resp, options...)
+	}
+
+	switch a.state {
+	case deferPending:
+		a.timer.Stop()
+		if err := a.SessionInterface.InteractionRespond(interaction, resp, options...); err != nil {
+			return err
+		}
+		a.state = deferResponded
+		if resp.Type == discordgo.InteractionResponseDeferredChannelMessageWithSource {
+			a.state = deferDeferred
+		}
+		return nil
+	case deferDeferred:
+		switch resp.Type {
+		case discordgo.InteractionResponseDeferredChannelMessageWithSource:
+			return nil
+		case discordgo.InteractionResponseChannelMessageWithSource:
+			_, err := a.SessionInterface.InteractionResponseEdit(interaction, responseEdit(resp.Data), options...)
+			return err
+		}
+	}
+	return a.SessionInterface.InteractionRespond(interaction, resp, options...)
+}
+
+// InteractionResponseEdit edits the response, or answers with the edited message when nothing was sent yet
+func (a *AutoDeferSession) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if interaction.ID != a.i.ID || a.state != deferPending {
+		return a.SessionInterface.InteractionResponseEdit(interaction, newresp, options...)
+	}
+
+	a.timer.Stop()
+	err := a.SessionInterface.InteractionRespond(interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: responseData(newresp),
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+	a.state = deferResponded
+	// Responding doesn't return the message, callers only use it to reply to the response later
+	return nil, nil
+}
+
+// responseEdit turns response data into an edit of the acknowledged response
+func responseEdit(data *discordgo.InteractionResponseData) *discordgo.WebhookEdit {
+	if data == nil {
+		return &discordgo.WebhookEdit{}
+	}
+	return &discordgo.WebhookEdit{
+		Content:         &data.Content,
+		Embeds:          &data.Embeds,
+		Components:      &data.Components,
+		Files:           data.Files,
+		AllowedMentions: data.AllowedMentions,
+	}
+}
+
+// responseData turns an edit into the data of a response
+func responseData(edit *discordgo.WebhookEdit) *discordgo.InteractionResponseData {
+	data := &discordgo.InteractionResponseData{Files: edit.Files, AllowedMentions: edit.AllowedMentions}
+	if edit.Content != nil {
+		data.Content = *edit.Content
+	}
+	if edit.Embeds != nil {
+		data.Embeds = *edit.Embeds
+	}
+	if edit.Components != nil {
+		data.Components = *edit.Components
+	}
+	return data
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/testutils"
+)
+
+func TestAutoDeferAnsweredInTime(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("weather", nil)
+	session := AutoDefer(mockSession, interaction, time.Minute)
+
+	require.NoError(t, respondWithEphemeral(session, interaction, "❌ Not here"))
+	require.NoError(t, session.Stop())
+
+	assert.Equal(t, discordgo.InteractionResponseChannelMessageWithSource, mockSession.RespondType)
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags, "responses in time keep their flags")
+	assert.False(t, mockSession.InteractionResponseEditCalled)
+}
+
+func TestAutoDeferEditsBecomeTheResponse(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("play", nil)
+	session := AutoDefer(mockSession, interaction, time.Minute)
+
+	require.NoError(t, respondWithError(session, interaction, "Music system is not available"))
+	require.NoError(t, session.Stop())
+
+	assert.Equal(t, discordgo.InteractionResponseChannelMessageWithSource, mockSession.RespondType)
+	assert.Contains(t, mockSession.RespondData.Content, "Music system is not available")
+	assert.False(t, mockSession.InteractionResponseEditCalled)
+
+	// Later edits change the response
+	_, err := session.InteractionResponseEdit(interaction.Interaction, &discordgo.WebhookEdit{})
+	require.NoError(t, err)
+	assert.True(t, mockSession.InteractionResponseEditCalled)
+}
+
+func TestAutoDeferAcknowledgesSlowHandlers(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("weather", nil)
+	session := AutoDefer(mockSession, interaction, time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		session.mu.Lock()
+		defer session.mu.Unlock()
+		return session.state == deferDeferred
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, discordgo.InteractionResponseDeferredChannelMessageWithSource, mockSession.RespondType)
+
+	embed := NewEmbed("☀️ Weather in Vilnius").Build()
+	require.NoError(t, respondWithEmbed(session, interaction, embed))
+	require.NoError(t, session.Stop())
+
+	require.True(t, mockSession.InteractionResponseEditCalled, "responses after the acknowledgement edit it")
+	require.NotNil(t, mockSession.InteractionResponseEditData.Embeds)
+	assert.Equal(t, []*discordgo.MessageEmbed{embed}, *mockSession.InteractionResponseEditData.Embeds)
+
+	// Handlers deferring themselves aren't deferred twice
+	mockSession.Reset()
+	require.NoError(t, session.InteractionRespond(interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}))
+	assert.False(t, mockSession.RespondCalled)
+}
+
+func TestAutoDeferReportsFailedAcknowledgement(t *testing.T) {
+	mockSession := &testutils.MockSession{RespondError: errors.New("unknown interaction")}
+	session := AutoDefer(mockSession, testutils.CreateTestInteraction("weather", nil), time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		session.mu.Lock()
+		defer session.mu.Unlock()
+		return session.deferErr != nil
+	}, time.Second, time.Millisecond)
+	assert.ErrorContains(t, session.Stop(), "failed to defer response")
+}
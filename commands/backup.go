@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/backup"
+	"pxnx-discord-bot/storage"
+	"pxnx-discord-bot/utils"
+)
+
+// maxListedBackups is how many backups /admin backup list shows
+const maxListedBackups = 10
+
+var (
+	// Backups keeps snapshots of the bot's data files in BACKUP_DIR, nil when backups are off
+	Backups *backup.Store
+	// BackupFiles are the data files backed up and restored
+	BackupFiles []backup.File
+)
+
+// InitializeBackups sets up backups of files in BACKUP_DIR keeping the newest BACKUP_KEEP, and applies a
+// restore staged with /admin backup restore. It runs before any store loads so they read the restored
+// files.
+func InitializeBackups(files []backup.File) {
+	dir := strings.TrimSpace(os.Getenv("BACKUP_DIR"))
+	if dir == "" {
+		return
+	}
+	keep := backup.DefaultKeep
+	if raw := strings.TrimSpace(os.Getenv("BACKUP_KEEP")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			utils.LogWarn("Ignoring BACKUP_KEEP %q, keeping %d backups", raw, keep)
+		} else {
+			keep = n
+		}
+	}
+
+	Backups = backup.NewStore(dir, keep)
+	BackupFiles = files
+
+	name, manifest, err := Backups.ApplyStaged(files)
+	switch {
+	case err != nil:
+		utils.LogError("Failed to restore backup %s, starting with the current data: %v", name, err)
+	case name != "":
+		utils.LogInfo("Restored backup %s from %s: %s", name, manifest.CreatedAt.Format(time.RFC3339),
+			strings.Join(manifest.Restored(files), ", "))
+	}
+}
+
+// RunBackup backs up the data files now and copies the backup to Storage when there is one. A failed copy
+// is returned as an error along with the backup, which is kept locally.
+func RunBackup() (backup.Info, backup.Manifest, error) {
+	if Backups == nil {
+		return backup.Info{}, backup.Manifest{}, errors.New("backups are off, set BACKUP_DIR to turn them on")
+	}
+	info, manifest, err := Backups.Create(BackupFiles, time.Now())
+	if err != nil {
+		return backup.Info{}, backup.Manifest{}, err
+	}
+	if err := uploadBackup(info.Name); err != nil {
+		return info, manifest, fmt.Errorf("backup %s was kept locally but not copied to storage: %w", info.Name, err)
+	}
+	return info, manifest, nil
+}
+
+// handleAdminBackup lets bot owners take, list, verify and restore backups of the bot's data
+func handleAdminBackup(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if !IsBotOwner(getInteractionUserID(i)) {
+		return respondWithEphemeral(s, i, translate(i, "backup.owner_only"))
+	}
+	if Backups == nil {
+		return respondWithEphemeral(s, i, translate(i, "backup.off"))
+	}
+
+	action, name := "list", ""
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		switch option.Name {
+		case "action":
+			action = option.StringValue()
+		case "name":
+			name = strings.TrimSpace(option.StringValue())
+		}
+	}
+	if name == "" && (action == "verify" || action == "restore") {
+		latest, err := Backups.Latest()
+		if err != nil {
+			return respondWithEphemeral(s, i, translate(i, "backup.none"))
+		}
+		name = latest
+	}
+
+	var embed *discordgo.MessageEmbed
+	switch action {
+	case "create":
+		info, manifest, err := RunBackup()
+		if info.Name == "" {
+			utils.LogError("Backup failed: %v", err)
+			return respondWithEphemeral(s, i, translate(i, "backup.failed", err))
+		}
+		utils.LogInfo("Created backup %s", info.Name)
+		builder := describeBackup(SuccessEmbed("💾 Backup Created"), info.Name, manifest)
+		if err != nil {
+			utils.LogWarn("%v", err)
+			builder.Style(StyleWarning).Field("Storage", "⚠️ Not copied: "+err.Error())
+		} else if link := backupLink(info.Name); link != "" {
+			builder.Field("Download", fmt.Sprintf("[%s](%s), valid for an hour", info.Name, link))
+		}
+		embed = builder.Build()
+	case "verify":
+		manifest, err := Backups.Verify(name)
+		if err != nil {
+			return respondWithEphemeral(s, i, fmt.Sprintf("❌ %v", err))
+		}
+		embed = describeBackup(SuccessEmbed("💾 Backup Verified"), name, manifest).Build()
+	case "restore":
+		manifest, err := Backups.StageRestore(name)
+		if err != nil {
+			return respondWithEphemeral(s, i, translate(i, "backup.not_restoring", err))
+		}
+		utils.LogInfo("Backup %s staged for restore by %s", name, getInteractionUserID(i))
+		embed = describeBackup(WarningEmbed("💾 Restore Staged"), name, manifest).
+			Description("The backup is intact and will be restored the next time the bot starts. " +
+				"Restart the bot to apply it; changes made until then are lost.").
+			Build()
+	default:
+		backups, err := Backups.List()
+		if err != nil {
+			return respondWithEphemeral(s, i, fmt.Sprintf("❌ %v", err))
+		}
+		staged, _ := Backups.Staged()
+		embed = createBackupListEmbed(Backups.Dir(), backups, staged)
+	}
+
+	return respondWithEphemeralEmbed(s, i, embed)
+}
+
+// describeBackup adds one backup and the files in it to an embed
+func describeBackup(builder *EmbedBuilder, name string, manifest backup.Manifest) *EmbedBuilder {
+	var files []string
+	for _, entry := range manifest.Files {
+		files = append(files, fmt.Sprintf("`%s` %s", entry.Name, formatBytes(uint64(entry.Size))))
+	}
+	if len(files) == 0 {
+		files = append(files, "No data files existed yet")
+	}
+
+	return builder.
+		Field("Backup", "`"+name+"`").
+		InlineField("Taken", fmt.Sprintf("<t:%d:R>", manifest.CreatedAt.Unix())).
+		LinesField("Files", files)
+}
+
+// createBackupListEmbed lists the newest backups
+func createBackupListEmbed(dir string, backups []backup.Info, staged string) *discordgo.MessageEmbed {
+	var lines []string
+	for n, info := range backups {
+		if n == maxListedBackups {
+			lines = append(lines, fmt.Sprintf("…and %d older", len(backups)-n))
+			break
+		}
+		line := fmt.Sprintf("`%s` %s, <t:%d:R>", info.Name, formatBytes(uint64(info.Size)), info.CreatedAt.Unix())
+		if info.Name == staged {
+			line += " ⏳ restores on restart"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No backups yet")
+	}
+
+	return NewEmbed("💾 Backups").
+		Field("Directory", "`"+dir+"`").
+		LinesField("Newest First", lines).
+		Build()
+}
+
+// backupLink returns a signed download link of a backup copied to Storage, or "" when there is none
+func backupLink(name string) string {
+	if Storage == nil {
+		return ""
+	}
+	link, err := Storage.SignedURL(storage.Key(storage.KindBackups, name), backupLinkLifetime)
+	if err != nil {
+		utils.LogDebug("No download link for backup %s: %v", name, err)
+		return ""
+	}
+	return link
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/backup"
+	"pxnx-discord-bot/testutils"
+)
+
+// useBackups backs up one votes file into a temporary store for the test
+func useBackups(t *testing.T) string {
+	dir := t.TempDir()
+	votes := filepath.Join(dir, "votes.json")
+	require.NoError(t, os.WriteFile(votes, []byte(`{}`), 0o644))
+
+	previousStore, previousFiles := Backups, BackupFiles
+	Backups = backup.NewStore(filepath.Join(dir, "backups"), 0)
+	BackupFiles = []backup.File{{Name: "votes.json", Path: votes}}
+	t.Cleanup(func() { Backups, BackupFiles = previousStore, previousFiles })
+	return votes
+}
+
+func newAdminBackupInteraction(userID, action string) *discordgo.InteractionCreate {
+	interaction := testutils.CreateTestInteraction("admin", []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "backup", Type: discordgo.ApplicationCommandOptionSubCommand, Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "action", Type: discordgo.ApplicationCommandOptionString, Value: action},
+		}},
+	})
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: userID}}
+	return interaction
+}
+
+func TestHandleAdminBackupRequiresOwner(t *testing.T) {
+	SetBotOwners([]string{"owner_id"})
+	defer SetBotOwners(nil)
+	useBackups(t)
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleAdminCommand(mockSession, newAdminBackupInteraction("someone_else", "create")))
+	assert.Equal(t, "❌ Only the bot owner can manage backups", mockSession.RespondData.Content)
+
+	backups, err := Backups.List()
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestHandleAdminBackupCreateAndRestore(t *testing.T) {
+	SetBotOwners([]string{"owner_id"})
+	defer SetBotOwners(nil)
+	useBackups(t)
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleAdminCommand(mockSession, newAdminBackupInteraction("owner_id", "create")))
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Equal(t, "💾 Backup Created", mockSession.RespondData.Embeds[0].Title)
+
+	name, err := Backups.Latest()
+	require.NoError(t, err)
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleAdminCommand(mockSession, newAdminBackupInteraction("owner_id", "restore")))
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Equal(t, "💾 Restore Staged", mockSession.RespondData.Embeds[0].Title)
+	staged, ok := Backups.Staged()
+	require.True(t, ok)
+	assert.Equal(t, name, staged)
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleAdminCommand(mockSession, newAdminBackupInteraction("owner_id", "list")))
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Contains(t, mockSession.RespondData.Embeds[0].Fields[1].Value, name)
+	assert.Contains(t, mockSession.RespondData.Embeds[0].Fields[1].Value, "restores on restart")
+}
+
+func TestHandleAdminBackupOff(t *testing.T) {
+	SetBotOwners([]string{"owner_id"})
+	defer SetBotOwners(nil)
+	previous := Backups
+	Backups = nil
+	defer func() { Backups = previous }()
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleAdminCommand(mockSession, newAdminBackupInteraction("owner_id", "list")))
+	assert.Contains(t, mockSession.RespondData.Content, "BACKUP_DIR")
+}
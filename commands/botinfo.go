@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ShardInfo is the state of one gateway shard this process runs
+type ShardInfo struct {
+	ID      int
+	Ready   bool // Whether the shard is connected and received its guilds
+	Latency time.Duration
+	Guilds  int
+}
+
+// ShardReport is the shards of this process out of the bot's total
+type ShardReport struct {
+	Total  int
+	Shards []ShardInfo
+}
+
+// ShardReporter reports the bot's shards for /botinfo, set by the bot at startup
+var ShardReporter func() ShardReport
+
+// GuildShard returns the shard Discord sends a guild's events on, out of count
+func GuildShard(guildID string, count int) int {
+	id, err := strconv.ParseUint(guildID, 10, 64)
+	if err != nil || count < 2 {
+		return 0
+	}
+	return int((id >> 22) % uint64(count))
+}
+
+// HandleBotInfoCommand handles /botinfo: the bot's servers and the state of its gateway shards
+func HandleBotInfoCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if ShardReporter == nil {
+		return respondWithEphemeral(s, i, translate(i, "botinfo.unavailable"))
+	}
+	return respondWithEmbed(s, i, createBotInfoEmbed(ShardReporter(), i.GuildID))
+}
+
+// createBotInfoEmbed shows the shards of this process, marking the one a guild is on
+func createBotInfoEmbed(report ShardReport, guildID string) *discordgo.MessageEmbed {
+	guilds := 0
+	lines := make([]string, 0, len(report.Shards))
+	for _, shard := range report.Shards {
+		guilds += shard.Guilds
+		status := "🔴 Connecting"
+		if shard.Ready {
+			status = fmt.Sprintf("🟢 %dms", shard.Latency.Milliseconds())
+		}
+		lines = append(lines, fmt.Sprintf("`#%d` %s · %d servers", shard.ID, status, shard.Guilds))
+	}
+
+	servers, shards := "Servers", strconv.Itoa(report.Total)
+	if len(report.Shards) < report.Total {
+		servers, shards = "Servers on These Shards", fmt.Sprintf("%d of %d in this process", len(report.Shards), report.Total)
+	}
+	embed := NewEmbed("🤖 Bot Info").
+		InlineField(servers, strconv.Itoa(guilds)).
+		InlineField("Shards", shards)
+	if guildID != "" {
+		embed.InlineField("This Server", fmt.Sprintf("Shard #%d", GuildShard(guildID, report.Total)))
+	}
+	return embed.LinesField("Shard Status", lines).Build()
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/testutils"
+)
+
+func TestGuildShard(t *testing.T) {
+	assert.Equal(t, 2, GuildShard("81384788765712384", 4))
+	assert.Equal(t, 0, GuildShard("81384788765712384", 1), "a single shard gets every guild")
+	assert.Equal(t, 0, GuildShard("not-a-snowflake", 4))
+}
+
+func TestHandleBotInfoCommand(t *testing.T) {
+	original := ShardReporter
+	ShardReporter = func() ShardReport {
+		return ShardReport{Total: 4, Shards: []ShardInfo{
+			{ID: 2, Ready: true, Latency: 42 * time.Millisecond, Guilds: 10},
+			{ID: 3, Guilds: 0},
+		}}
+	}
+	t.Cleanup(func() { ShardReporter = original })
+
+	interaction := testutils.CreateTestInteraction("botinfo", nil)
+	interaction.GuildID = "81384788765712384"
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleBotInfoCommand(mockSession, interaction))
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	fields := mockSession.RespondData.Embeds[0].Fields
+	require.Len(t, fields, 4)
+	assert.Equal(t, "10", fields[0].Value)
+	assert.Equal(t, "2 of 4 in this process", fields[1].Value)
+	assert.Equal(t, "Shard #2", fields[2].Value)
+	assert.Equal(t, "`#2` 🟢 42ms · 10 servers\n`#3` 🔴 Connecting · 0 servers", fields[3].Value)
+}
@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/i18n"
+)
+
+// permissionRequirement describes a permission the bot needs for a feature
+type permissionRequirement struct {
+	Permission int64
+	Name       string // Catalog key of the permission's name
+	Feature    string // Catalog key of what needs it
+	Voice      bool   // Only relevant for voice channels
+	Server     bool   // Server-wide permission, relevant in every channel
+	Optional   bool   // Audited, but no enabled feature fails without it
+}
+
+// permissionSetVoiceChannelStatus allows setting a voice channel's status, discordgo has no constant for it
+const permissionSetVoiceChannelStatus int64 = 1 << 48
+
+// botPermissionRequirements lists the permissions required by the bot's features that are enabled, in
+// a guild for the per-server music settings. Music permissions are left out when music is turned off.
+func botPermissionRequirements(guildID string) []permissionRequirement {
+	history := "checkperms.feature.reactions"
+	if LLM != nil && MessageContent && !GuildSettings.Get(guildID).SummariesDisabled {
+		history = "checkperms.feature.reactions_summaries"
+	}
+
+	requirements := []permissionRequirement{
+		{Permission: discordgo.PermissionViewChannel, Name: "permission.view_channel", Feature: "checkperms.feature.all"},
+		{Permission: discordgo.PermissionSendMessages, Name: "permission.send_messages", Feature: "checkperms.feature.all"},
+		{Permission: discordgo.PermissionEmbedLinks, Name: "permission.embed_links", Feature: "checkperms.feature.embeds"},
+		{Permission: discordgo.PermissionAttachFiles, Name: "permission.attach_files", Feature: "checkperms.feature.files"},
+		{Permission: discordgo.PermissionAddReactions, Name: "permission.add_reactions", Feature: "checkperms.feature.reactions"},
+		{Permission: discordgo.PermissionUseExternalEmojis, Name: "permission.use_external_emojis", Feature: "checkperms.feature.reactions"},
+		{Permission: discordgo.PermissionReadMessageHistory, Name: "permission.read_message_history", Feature: history},
+		// Queue moderation is the members' Manage Messages, the bot itself never deletes or pins messages
+		{Permission: discordgo.PermissionManageMessages, Name: "permission.manage_messages", Feature: "checkperms.feature.unused", Optional: true},
+	}
+	if SimplePlayer == nil {
+		return requirements
+	}
+
+	requirements = append(requirements,
+		permissionRequirement{Permission: discordgo.PermissionVoiceConnect, Name: "permission.connect", Feature: "checkperms.feature.music", Voice: true},
+		permissionRequirement{Permission: discordgo.PermissionVoiceSpeak, Name: "permission.speak", Feature: "checkperms.feature.music", Voice: true},
+	)
+	if SimplePlayer.ChannelStatus(guildID) {
+		requirements = append(requirements, permissionRequirement{
+			Permission: permissionSetVoiceChannelStatus, Name: "permission.set_voice_channel_status", Feature: "checkperms.feature.channel_status", Voice: true,
+		})
+	}
+	if SimplePlayer.NowPlayingNickname(guildID) {
+		requirements = append(requirements, permissionRequirement{
+			Permission: discordgo.PermissionChangeNickname, Name: "permission.change_nickname", Feature: "checkperms.feature.nickname", Server: true,
+		})
+	}
+	return requirements
+}
+
+// permissionCheckResult holds the outcome of checking a single requirement
+type permissionCheckResult struct {
+	Requirement permissionRequirement
+	Granted     bool
+}
+
+// checkPermissions evaluates the given permission bitset against the requirements relevant for a channel type
+func checkPermissions(requirements []permissionRequirement, permissions int64, voiceChannel bool) []permissionCheckResult {
+	results := make([]permissionCheckResult, 0, len(requirements))
+	for _, req := range requirements {
+		// Voice permissions only matter in voice channels, text permissions only in text channels
+		// (View Channel and server-wide permissions are needed everywhere)
+		if req.Voice != voiceChannel && req.Permission != discordgo.PermissionViewChannel && !req.Server {
+			continue
+		}
+		results = append(results, permissionCheckResult{
+			Requirement: req,
+			Granted:     permissions&req.Permission == req.Permission,
+		})
+	}
+	return results
+}
+
+// isVoiceChannel reports whether the channel type supports voice
+func isVoiceChannel(channel *discordgo.Channel) bool {
+	return channel.Type == discordgo.ChannelTypeGuildVoice || channel.Type == discordgo.ChannelTypeGuildStageVoice
+}
+
+// HandleCheckPermsCommand handles the checkperms slash command
+func HandleCheckPermsCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	channelID := i.ChannelID
+	if len(i.ApplicationCommandData().Options) > 0 {
+		channelID = i.ApplicationCommandData().Options[0].ChannelValue(nil).ID
+	}
+
+	channel, permissions, err := botChannelPermissions(s, channelID)
+	if err != nil {
+		return respondWithEphemeral(s, i, "❌ "+err.Message(responseLanguage(i)))
+	}
+
+	results := checkPermissions(botPermissionRequirements(channel.GuildID), permissions, isVoiceChannel(channel))
+	embed := createPermissionsEmbed(i, channel, results)
+
+	return respondWithEphemeralEmbed(s, i, embed)
+}
+
+// PermissionsError explains why the bot's permissions in a channel couldn't be checked, worded for members
+type PermissionsError struct {
+	Reason string // Catalog key of the reason
+	Args   []any  // Values the reason is formatted with
+}
+
+func (e *PermissionsError) Error() string {
+	return e.Message(i18n.Default)
+}
+
+// Message explains the error in a language
+func (e *PermissionsError) Message(language string) string {
+	return i18n.T(language, e.Reason, e.Args...)
+}
+
+// botChannelPermissions looks up a channel and the bot's permissions in it
+func botChannelPermissions(s SessionInterface, channelID string) (*discordgo.Channel, int64, *PermissionsError) {
+	state := s.State()
+	if state == nil || state.User == nil {
+		return nil, 0, &PermissionsError{Reason: "checkperms.no_state"}
+	}
+
+	channel, err := state.Channel(channelID)
+	if err != nil {
+		// Fall back to the API when the channel is not cached
+		channel, err = s.Channel(channelID)
+		if err != nil {
+			return nil, 0, &PermissionsError{Reason: "checkperms.no_channel"}
+		}
+	}
+
+	permissions, err := state.UserChannelPermissions(state.User.ID, channel.ID)
+	if err != nil {
+		return nil, 0, &PermissionsError{Reason: "checkperms.failed", Args: []any{channel.ID, err}}
+	}
+	return channel, permissions, nil
+}
+
+// createPermissionsEmbed creates the permission audit embed with actionable fixes
+func createPermissionsEmbed(i *discordgo.InteractionCreate, channel *discordgo.Channel, results []permissionCheckResult) *discordgo.MessageEmbed {
+	var checks strings.Builder
+	var fixes strings.Builder
+	missing := 0
+
+	for _, result := range results {
+		name := translate(i, result.Requirement.Name)
+		if result.Granted {
+			checks.WriteString(fmt.Sprintf("✅ %s\n", name))
+			continue
+		}
+		if result.Requirement.Optional {
+			checks.WriteString(translate(i, "checkperms.optional", name, translate(i, result.Requirement.Feature)) + "\n")
+			continue
+		}
+		missing++
+		checks.WriteString(translate(i, "checkperms.missing", name, translate(i, result.Requirement.Feature)) + "\n")
+		fixes.WriteString(translate(i, "checkperms.fix", name, channel.ID) + "\n")
+	}
+
+	embed := SuccessEmbed(translate(i, "checkperms.title")).
+		Description(translate(i, "checkperms.description", channel.ID)).
+		Field(translate(i, "checkperms.permissions"), checks.String())
+
+	if missing > 0 {
+		embed.Style(StyleError).Field(translate(i, "checkperms.how_to_fix", missing), fixes.String())
+	} else {
+		embed.Footer(translate(i, "checkperms.all_granted"))
+	}
+
+	return embed.Build()
+}
+
+func respondWithEphemeral(s SessionInterface, i *discordgo.InteractionCreate, message string) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: messageFor(i, message),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/testutils"
+)
+
+// createPermissionTestState builds a session state where the bot role has the given permissions
+func createPermissionTestState(t *testing.T, rolePermissions int64) *discordgo.State {
+	t.Helper()
+
+	state := discordgo.NewState()
+	state.User = testutils.CreateTestUser("bot_id", "pxnx", "")
+
+	guild := testutils.CreateTestGuild("guild_id_123", "Test Server", 10)
+	guild.Roles = []*discordgo.Role{
+		{ID: "guild_id_123", Permissions: 0},
+		{ID: "bot_role", Permissions: rolePermissions},
+	}
+	guild.Channels = []*discordgo.Channel{
+		{ID: "channel_id_123", GuildID: "guild_id_123", Type: discordgo.ChannelTypeGuildText},
+		{ID: "voice_id_123", GuildID: "guild_id_123", Type: discordgo.ChannelTypeGuildVoice},
+	}
+	require.NoError(t, state.GuildAdd(guild))
+	require.NoError(t, state.MemberAdd(&discordgo.Member{
+		GuildID: "guild_id_123",
+		User:    state.User,
+		Roles:   []string{"bot_role"},
+	}))
+
+	return state
+}
+
+func TestCheckPermissions(t *testing.T) {
+	tests := []struct {
+		name          string
+		permissions   int64
+		voice         bool
+		expectMissing []string
+	}{
+		{
+			name:          "all text permissions granted",
+			permissions:   discordgo.PermissionAllText | discordgo.PermissionAddReactions | discordgo.PermissionUseExternalEmojis,
+			voice:         false,
+			expectMissing: nil,
+		},
+		{
+			name:          "missing embed links",
+			permissions:   discordgo.PermissionViewChannel | discordgo.PermissionSendMessages | discordgo.PermissionAttachFiles | discordgo.PermissionAddReactions | discordgo.PermissionUseExternalEmojis | discordgo.PermissionReadMessageHistory,
+			voice:         false,
+			expectMissing: []string{"permission.embed_links"},
+		},
+		{
+			name:          "voice channel missing speak",
+			permissions:   discordgo.PermissionViewChannel | discordgo.PermissionVoiceConnect,
+			voice:         true,
+			expectMissing: []string{"permission.speak"},
+		},
+	}
+
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkPermissions(botPermissionRequirements("guild_id_123"), tt.permissions, tt.voice)
+
+			var missing []string
+			for _, result := range results {
+				if !result.Granted && !result.Requirement.Optional {
+					missing = append(missing, result.Requirement.Name)
+				}
+				if result.Requirement.Voice {
+					assert.True(t, tt.voice, "voice requirement %s should not be checked for text channels", result.Requirement.Name)
+				}
+			}
+			assert.Equal(t, tt.expectMissing, missing)
+		})
+	}
+}
+
+func TestBotPermissionRequirementsFollowFeatures(t *testing.T) {
+	names := func(requirements []permissionRequirement) []string {
+		var names []string
+		for _, requirement := range requirements {
+			names = append(names, requirement.Name)
+		}
+		return names
+	}
+
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	requirements := names(botPermissionRequirements("guild_id_123"))
+	assert.Contains(t, requirements, "permission.attach_files")
+	assert.Contains(t, requirements, "permission.manage_messages")
+	assert.NotContains(t, requirements, "permission.connect", "voice permissions aren't needed with music turned off")
+
+	SimplePlayer = music.NewSimplePlayer(nil)
+	requirements = names(botPermissionRequirements("guild_id_123"))
+	assert.Contains(t, requirements, "permission.connect")
+	assert.NotContains(t, requirements, "permission.set_voice_channel_status")
+	assert.NotContains(t, requirements, "permission.change_nickname")
+
+	require.NoError(t, SimplePlayer.SetChannelStatus("guild_id_123", true))
+	require.NoError(t, SimplePlayer.SetNowPlayingNickname("guild_id_123", true))
+	requirements = names(botPermissionRequirements("guild_id_123"))
+	assert.Contains(t, requirements, "permission.set_voice_channel_status")
+	assert.Contains(t, requirements, "permission.change_nickname")
+	assert.NotContains(t, names(botPermissionRequirements("other_guild")), "permission.change_nickname")
+
+	// The nickname is server-wide, so it's checked in text and voice channels alike
+	for _, voice := range []bool{false, true} {
+		var checked []string
+		for _, result := range checkPermissions(botPermissionRequirements("guild_id_123"), 0, voice) {
+			checked = append(checked, result.Requirement.Name)
+		}
+		assert.Contains(t, checked, "permission.change_nickname")
+	}
+}
+
+func TestHandleCheckPermsCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		permissions   int64
+		options       []*discordgo.ApplicationCommandInteractionDataOption
+		music         bool
+		expectColor   int
+		expectFixHint bool
+	}{
+		{
+			name:        "current channel fully permitted",
+			permissions: discordgo.PermissionAllText | discordgo.PermissionAddReactions | discordgo.PermissionUseExternalEmojis,
+			expectColor: 0x2ecc71,
+		},
+		{
+			name:          "current channel missing embed links",
+			permissions:   discordgo.PermissionViewChannel | discordgo.PermissionSendMessages,
+			expectColor:   0xe74c3c,
+			expectFixHint: true,
+		},
+		{
+			name:          "voice channel option missing connect",
+			permissions:   discordgo.PermissionAllText,
+			options:       []*discordgo.ApplicationCommandInteractionDataOption{testutils.CreateChannelOption("channel", "voice_id_123")},
+			music:         true,
+			expectColor:   0xe74c3c,
+			expectFixHint: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := SimplePlayer
+			SimplePlayer = nil
+			if tt.music {
+				SimplePlayer = music.NewSimplePlayer(nil)
+			}
+			defer func() { SimplePlayer = original }()
+
+			mockSession := &testutils.MockSession{
+				StateReturn: createPermissionTestState(t, tt.permissions),
+			}
+			interaction := testutils.CreateTestInteraction("checkperms", tt.options)
+
+			err := HandleCheckPermsCommand(mockSession, interaction)
+			require.NoError(t, err)
+
+			require.True(t, mockSession.RespondCalled)
+			require.NotNil(t, mockSession.RespondData)
+			assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+			require.Len(t, mockSession.RespondData.Embeds, 1)
+
+			embed := mockSession.RespondData.Embeds[0]
+			assert.Equal(t, tt.expectColor, embed.Color)
+			if tt.expectFixHint {
+				require.Len(t, embed.Fields, 2)
+				assert.Contains(t, embed.Fields[1].Name, "How to fix")
+			} else {
+				assert.Len(t, embed.Fields, 1)
+			}
+		})
+	}
+}
+
+func TestHandleCheckPermsCommandWithoutState(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("checkperms", nil)
+
+	err := HandleCheckPermsCommand(mockSession, interaction)
+	require.NoError(t, err)
+
+	assert.True(t, mockSession.RespondCalled)
+	assert.Contains(t, mockSession.RespondData.Content, "not available")
+}
+
+func TestHandleCheckPermsCommandInServerLanguage(t *testing.T) {
+	store := useGuildSettings(t)
+	_, err := store.Update("guild_id_123", func(g *settings.Guild) { g.Language = "de" })
+	require.NoError(t, err)
+
+	mockSession := &testutils.MockSession{StateReturn: createPermissionTestState(t, discordgo.PermissionViewChannel|discordgo.PermissionSendMessages)}
+	require.NoError(t, HandleCheckPermsCommand(mockSession, testutils.CreateTestInteraction("checkperms", nil)))
+
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	embed := mockSession.RespondData.Embeds[0]
+	assert.Equal(t, "🔐 Berechtigungsprüfung", embed.Title)
+	assert.Contains(t, embed.Fields[0].Value, "❌ Links einbetten – nötig für Wetter-, Musik- und Info-Embeds")
+	assert.Contains(t, embed.Fields[0].Value, "➖ Nachrichten verwalten – optional")
+	assert.NotContains(t, embed.Fields[1].Value, "Nachrichten verwalten", "optional permissions aren't listed as fixes")
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleCheckPermsCommand(mockSession, testutils.CreateTestInteraction("checkperms", nil)))
+	assert.Equal(t, "❌ Der Zustand des Bots ist noch nicht verfügbar, versuch es gleich noch einmal", mockSession.RespondData.Content)
+}
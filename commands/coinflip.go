@@ -1,9 +1,9 @@
 package commands
 
 import (
-	"fmt"
-
 	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
 )
 
 // HandleCoinFlipCommand handles the coinflip slash command
@@ -13,16 +13,10 @@ func HandleCoinFlipCommand(s SessionInterface, i *discordgo.InteractionCreate) e
 		result = "Tails"
 	}
 
-	embed := &discordgo.MessageEmbed{
-		Title:       "🪙 Coin Flip",
-		Description: fmt.Sprintf("The coin landed on **%s**!", result),
-		Color:       0xf39c12, // ColorOrange
-	}
+	embed := NewEmbed("🪙 Coin Flip").
+		Descriptionf("The coin landed on **%s**!", result).
+		Color(utils.ColorOrange).
+		Build()
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	return respondWithEmbed(s, i, embed)
 }
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/i18n"
+)
+
+// memberPermissionNames are the catalog keys naming the permissions commands can require, for refusals
+var memberPermissionNames = []struct {
+	Permission int64
+	Key        string
+}{
+	{discordgo.PermissionAdministrator, "permission.administrator"},
+	{discordgo.PermissionManageGuild, "permission.manage_server"},
+	{discordgo.PermissionManageMessages, "permission.manage_messages"},
+	{discordgo.PermissionManageRoles, "permission.manage_roles"},
+	{discordgo.PermissionManageChannels, "permission.manage_channels"},
+}
+
+// CheckMemberPermissions answers commands the member lacks permissions for instead of running them. It
+// reports whether the command may run; the error is from answering a refused command. Administrators and
+// bot owners may run every command.
+func CheckMemberPermissions(s SessionInterface, i *discordgo.InteractionCreate, permissions int64) (bool, error) {
+	if permissions == 0 || IsBotOwner(getInteractionUserID(i)) {
+		return true, nil
+	}
+	if i.Member == nil {
+		return false, respondWithEphemeral(s, i, translate(i, "check.server_only"))
+	}
+	granted := i.Member.Permissions
+	if granted&discordgo.PermissionAdministrator != 0 || granted&permissions == permissions {
+		return true, nil
+	}
+
+	language := responseLanguage(i)
+	var missing []string
+	for _, permission := range memberPermissionNames {
+		if permissions&permission.Permission != 0 && granted&permission.Permission == 0 {
+			missing = append(missing, i18n.T(language, permission.Key))
+		}
+	}
+	name := i.ApplicationCommandData().Name
+	refusal := i18n.T(language, "check.more_permissions", name)
+	switch len(missing) {
+	case 0:
+	case 1:
+		refusal = i18n.T(language, "check.missing_permission", missing[0], name)
+	default:
+		refusal = i18n.T(language, "check.missing_permissions", strings.Join(missing, i18n.T(language, "list.and")), name)
+	}
+	return false, respondWithEphemeral(s, i, refusal)
+}
+
+// commandCooldowns is when members may use commands with a cooldown again
+var commandCooldowns = newCooldowns()
+
+// cooldowns tracks until when each member waits before using a command again
+type cooldowns struct {
+	mu    sync.Mutex
+	until map[string]time.Time // By command and user
+	now   func() time.Time
+}
+
+// newCooldowns creates an empty cooldown tracker
+func newCooldowns() *cooldowns {
+	return &cooldowns{until: make(map[string]time.Time), now: time.Now}
+}
+
+// take starts a member's cooldown of a command and returns zero, or how long is left of a running one
+func (c *cooldowns) take(command, userID string, cooldown time.Duration) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	key := command + ":" + userID
+	if left := c.until[key].Sub(now); left > 0 {
+		return left
+	}
+	// Finished cooldowns are dropped now and then so members who left don't pile up
+	if len(c.until) >= 1000 {
+		for key, until := range c.until {
+			if !until.After(now) {
+				delete(c.until, key)
+			}
+		}
+	}
+	c.until[key] = now.Add(cooldown)
+	return 0
+}
+
+// CheckCooldown answers a member using a command again before its cooldown passed instead of running it.
+// It reports whether the command may run; the error is from answering a refused command.
+func CheckCooldown(s SessionInterface, i *discordgo.InteractionCreate, cooldown time.Duration) (bool, error) {
+	if cooldown <= 0 {
+		return true, nil
+	}
+	name := i.ApplicationCommandData().Name
+	left := commandCooldowns.take(name, getInteractionUserID(i), cooldown)
+	if left <= 0 {
+		return true, nil
+	}
+	seconds := int(left.Round(time.Second).Seconds())
+	return false, respondWithEphemeral(s, i, translate(i, "check.cooldown", max(1, seconds), name))
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestCheckMemberPermissions(t *testing.T) {
+	interaction := testutils.CreateTestInteraction("settings", nil)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser("member_id", "member", ""))
+	permissions := int64(discordgo.PermissionManageGuild | discordgo.PermissionManageMessages)
+
+	mockSession := &testutils.MockSession{}
+	allowed, err := CheckMemberPermissions(mockSession, interaction, permissions)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, "❌ You need the Manage Server and Manage Messages permissions to use /settings", mockSession.RespondData.Content)
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+
+	interaction.Member.Permissions = discordgo.PermissionManageGuild
+	allowed, _ = CheckMemberPermissions(&testutils.MockSession{}, interaction, permissions)
+	assert.False(t, allowed, "every permission is needed")
+
+	interaction.Member.Permissions = permissions
+	allowed, _ = CheckMemberPermissions(&testutils.MockSession{}, interaction, permissions)
+	assert.True(t, allowed)
+
+	interaction.Member.Permissions = discordgo.PermissionAdministrator
+	allowed, _ = CheckMemberPermissions(&testutils.MockSession{}, interaction, permissions)
+	assert.True(t, allowed, "administrators may run every command")
+
+	interaction.Member.Permissions = 0
+	SetBotOwners([]string{"member_id"})
+	defer SetBotOwners(nil)
+	allowed, _ = CheckMemberPermissions(&testutils.MockSession{}, interaction, permissions)
+	assert.True(t, allowed, "bot owners may run every command")
+
+	// Outside of servers there are no permissions to check
+	interaction.Member = nil
+	interaction.User = testutils.CreateTestUser("user_id", "user", "")
+	mockSession = &testutils.MockSession{}
+	allowed, _ = CheckMemberPermissions(mockSession, interaction, permissions)
+	assert.False(t, allowed)
+	assert.Contains(t, mockSession.RespondData.Content, "only be used in a server")
+}
+
+func TestCheckCooldown(t *testing.T) {
+	original := commandCooldowns
+	commandCooldowns = newCooldowns()
+	defer func() { commandCooldowns = original }()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	commandCooldowns.now = func() time.Time { return now }
+
+	interaction := testutils.CreateTestInteraction("weather", nil)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser("member_id", "member", ""))
+
+	allowed, err := CheckCooldown(&testutils.MockSession{}, interaction, 5*time.Second)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	now = now.Add(2 * time.Second)
+	mockSession := &testutils.MockSession{}
+	allowed, err = CheckCooldown(mockSession, interaction, 5*time.Second)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, "⏳ Wait 3 more seconds before using /weather again", mockSession.RespondData.Content)
+
+	other := testutils.CreateTestInteraction("weather", nil)
+	other.Member = testutils.CreateTestMember(testutils.CreateTestUser("other_id", "other", ""))
+	allowed, _ = CheckCooldown(&testutils.MockSession{}, other, 5*time.Second)
+	assert.True(t, allowed, "cooldowns are per member")
+
+	now = now.Add(3 * time.Second)
+	allowed, _ = CheckCooldown(&testutils.MockSession{}, interaction, 5*time.Second)
+	assert.True(t, allowed, "the cooldown passed")
+}
+
+func TestCommandChecksAnswerInMemberLanguage(t *testing.T) {
+	store := useGuildSettings(t)
+	_, err := store.Update("guild_id_123", func(g *settings.Guild) { g.Language = "fr" })
+	require.NoError(t, err)
+
+	interaction := testutils.CreateTestInteraction("settings", nil)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser("member_id", "member", ""))
+	interaction.Locale = discordgo.German
+	mockSession := &testutils.MockSession{}
+	_, err = CheckMemberPermissions(mockSession, interaction, discordgo.PermissionManageGuild|discordgo.PermissionManageMessages)
+	require.NoError(t, err)
+	assert.Equal(t, "❌ Il te faut les permissions Gérer le serveur et Gérer les messages pour utiliser /settings", mockSession.RespondData.Content, "servers are answered in their language")
+
+	// In DMs the member's own Discord language is used
+	interaction.GuildID, interaction.Member = "", nil
+	interaction.User = testutils.CreateTestUser("user_id", "user", "")
+	mockSession = &testutils.MockSession{}
+	_, err = CheckMemberPermissions(mockSession, interaction, discordgo.PermissionManageGuild)
+	require.NoError(t, err)
+	assert.Equal(t, "❌ Dieser Befehl kann nur auf einem Server verwendet werden", mockSession.RespondData.Content)
+}
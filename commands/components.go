@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
+)
+
+// Component custom IDs have the form "<handler>:<arg>:<arg>...". Everything a handler needs is
+// encoded in the ID and handlers are registered at startup, so buttons and select menus sent
+// before a restart are re-bound to their handler instead of going dead.
+const (
+	componentIDSeparator = ":"
+	maxComponentIDLength = 100 // Discord limit for custom IDs
+)
+
+// ComponentHandler handles a button or select menu interaction. Args are the custom ID segments after the handler name.
+type ComponentHandler func(s SessionInterface, i *discordgo.InteractionCreate, args []string) error
+
+var (
+	componentHandlers   = make(map[string]ComponentHandler)
+	componentHandlersMu sync.RWMutex
+)
+
+// RegisterComponentHandler binds a handler name to the function that serves its components
+func RegisterComponentHandler(name string, handler ComponentHandler) {
+	componentHandlersMu.Lock()
+	defer componentHandlersMu.Unlock()
+	componentHandlers[name] = handler
+}
+
+// ComponentID builds a custom ID routed to the named handler with the given arguments
+func ComponentID(name string, args ...string) string {
+	customID := strings.Join(append([]string{name}, args...), componentIDSeparator)
+	if len(customID) > maxComponentIDLength {
+		utils.LogWarn("Component custom ID for %s exceeds %d characters and will be rejected by Discord", name, maxComponentIDLength)
+	}
+	return customID
+}
+
+// parseComponentID splits a custom ID into its handler name and arguments
+func parseComponentID(customID string) (string, []string) {
+	parts := strings.Split(customID, componentIDSeparator)
+	return parts[0], parts[1:]
+}
+
+// ComponentHandlerName returns the name of the handler a custom ID is routed to
+func ComponentHandlerName(customID string) string {
+	name, _ := parseComponentID(customID)
+	return name
+}
+
+// HandleComponentInteraction routes a component interaction to its registered handler
+func HandleComponentInteraction(s SessionInterface, i *discordgo.InteractionCreate) error {
+	name, args := parseComponentID(i.MessageComponentData().CustomID)
+
+	componentHandlersMu.RLock()
+	handler, exists := componentHandlers[name]
+	componentHandlersMu.RUnlock()
+
+	if !exists {
+		return respondWithEphemeral(s, i, translate(i, "component.expired", name))
+	}
+
+	return handler(s, i, args)
+}
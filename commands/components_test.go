@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/testutils"
+)
+
+func TestComponentIDRoundTrip(t *testing.T) {
+	name, args := parseComponentID(ComponentID("poll", "vote", "42"))
+	assert.Equal(t, "poll", name)
+	assert.Equal(t, []string{"vote", "42"}, args)
+
+	name, args = parseComponentID(ComponentID("panel"))
+	assert.Equal(t, "panel", name)
+	assert.Empty(t, args)
+}
+
+func TestHandleComponentInteraction(t *testing.T) {
+	var receivedArgs []string
+	RegisterComponentHandler("test_component", func(s SessionInterface, i *discordgo.InteractionCreate, args []string) error {
+		receivedArgs = args
+		return nil
+	})
+	defer func() {
+		componentHandlersMu.Lock()
+		delete(componentHandlers, "test_component")
+		componentHandlersMu.Unlock()
+	}()
+
+	t.Run("routes to registered handler", func(t *testing.T) {
+		mockSession := &testutils.MockSession{}
+		err := HandleComponentInteraction(mockSession, testutils.CreateTestComponentInteraction(ComponentID("test_component", "a", "b")))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, receivedArgs)
+		assert.False(t, mockSession.RespondCalled)
+	})
+
+	t.Run("unknown handler responds ephemerally", func(t *testing.T) {
+		mockSession := &testutils.MockSession{}
+		err := HandleComponentInteraction(mockSession, testutils.CreateTestComponentInteraction("missing:1"))
+		require.NoError(t, err)
+		require.True(t, mockSession.RespondCalled)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+		assert.Contains(t, mockSession.RespondData.Content, "no longer available")
+	})
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Component handler name and choices for confirmation buttons ("confirm:<choice>:<token>")
+const (
+	confirmComponent = "confirm"
+	confirmChoiceYes = "yes"
+	confirmChoiceNo  = "no"
+)
+
+// confirmationTimeout is how long a destructive action waits for its second confirmation
+const confirmationTimeout = 60 * time.Second
+
+// pendingConfirmation is a destructive action waiting for the requester to confirm it
+type pendingConfirmation struct {
+	userID    string
+	execute   func() (string, error)
+	expiresAt time.Time
+}
+
+var (
+	pendingConfirmations   = make(map[string]*pendingConfirmation)
+	pendingConfirmationsMu sync.Mutex
+)
+
+func init() {
+	RegisterComponentHandler(confirmComponent, handleConfirmationComponent)
+}
+
+// getInteractionUserID returns the ID of the user who triggered an interaction (guild or DM)
+func getInteractionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// requestConfirmation responds with a preview of a destructive action and Confirm/Cancel buttons.
+// The action only runs once the same user presses Confirm. With dryRun set, only the preview is shown.
+func requestConfirmation(s SessionInterface, i *discordgo.InteractionCreate, preview *discordgo.MessageEmbed, dryRun bool, execute func() (string, error)) error {
+	if dryRun {
+		preview.Footer = &discordgo.MessageEmbedFooter{
+			Text: translate(i, "confirm.dry_run"),
+		}
+		return respondWithEphemeralEmbed(s, i, preview)
+	}
+
+	token := i.ID
+	pendingConfirmationsMu.Lock()
+	cleanupExpiredConfirmations(time.Now())
+	pendingConfirmations[token] = &pendingConfirmation{
+		userID:    getInteractionUserID(i),
+		execute:   execute,
+		expiresAt: time.Now().Add(confirmationTimeout),
+	}
+	pendingConfirmationsMu.Unlock()
+
+	preview.Footer = &discordgo.MessageEmbedFooter{
+		Text: translate(i, "confirm.footer", int(confirmationTimeout.Seconds())),
+	}
+
+	data := embedResponse(i, preview)
+	data.Components = []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    translate(i, "confirm.confirm"),
+					Style:    discordgo.DangerButton,
+					CustomID: ComponentID(confirmComponent, confirmChoiceYes, token),
+				},
+				discordgo.Button{
+					Label:    translate(i, "confirm.cancel"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: ComponentID(confirmComponent, confirmChoiceNo, token),
+				},
+			},
+		},
+	}
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+}
+
+// cleanupExpiredConfirmations removes confirmations that can no longer be accepted (caller holds the lock)
+func cleanupExpiredConfirmations(now time.Time) {
+	for token, pending := range pendingConfirmations {
+		if now.After(pending.expiresAt) {
+			delete(pendingConfirmations, token)
+		}
+	}
+}
+
+// pendingConfirmationCount returns how many confirmations are waiting, after dropping expired ones
+func pendingConfirmationCount() int {
+	pendingConfirmationsMu.Lock()
+	defer pendingConfirmationsMu.Unlock()
+	cleanupExpiredConfirmations(time.Now())
+	return len(pendingConfirmations)
+}
+
+// handleConfirmationComponent handles presses of the Confirm and Cancel buttons.
+// Pending actions live in memory, so buttons from before a restart report as expired.
+func handleConfirmationComponent(s SessionInterface, i *discordgo.InteractionCreate, args []string) error {
+	if len(args) != 2 {
+		return respondWithEphemeral(s, i, translate(i, "confirm.invalid"))
+	}
+	confirmed := args[0] == confirmChoiceYes
+	token := args[1]
+
+	pendingConfirmationsMu.Lock()
+	pending, exists := pendingConfirmations[token]
+	if exists && pending.userID != getInteractionUserID(i) {
+		pendingConfirmationsMu.Unlock()
+		return respondWithEphemeral(s, i, translate(i, "confirm.not_yours"))
+	}
+	delete(pendingConfirmations, token)
+	pendingConfirmationsMu.Unlock()
+
+	var message string
+	switch {
+	case !exists || time.Now().After(pending.expiresAt):
+		message = translate(i, "confirm.expired")
+	case !confirmed:
+		message = translate(i, "confirm.cancelled")
+	default:
+		result, err := pending.execute()
+		if err != nil {
+			message = fmt.Sprintf("❌ %v", err)
+		} else {
+			message = result
+		}
+	}
+
+	return updateComponentMessage(s, i, message)
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/testutils"
+)
+
+func newConfirmationInteraction(userID string) *discordgo.InteractionCreate {
+	interaction := testutils.CreateTestInteraction("clear", nil)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser(userID, "testuser", "avatar"))
+	return interaction
+}
+
+func newButtonInteraction(customID, userID string) *discordgo.InteractionCreate {
+	interaction := testutils.CreateTestComponentInteraction(customID)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser(userID, "testuser", "avatar"))
+	return interaction
+}
+
+func TestRequestConfirmationDryRun(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+	interaction := newConfirmationInteraction("user_1")
+	executed := false
+
+	err := requestConfirmation(mockSession, interaction, &discordgo.MessageEmbed{Title: "Preview"}, true, func() (string, error) {
+		executed = true
+		return "done", nil
+	})
+	require.NoError(t, err)
+
+	assert.False(t, executed)
+	assert.Empty(t, mockSession.RespondData.Components, "dry run should not offer confirmation buttons")
+	assert.Contains(t, mockSession.RespondData.Embeds[0].Footer.Text, "Dry run")
+
+	pendingConfirmationsMu.Lock()
+	_, exists := pendingConfirmations[interaction.ID]
+	pendingConfirmationsMu.Unlock()
+	assert.False(t, exists)
+}
+
+func TestConfirmationFlow(t *testing.T) {
+	tests := []struct {
+		name          string
+		choice        string
+		presserID     string
+		executeErr    error
+		expire        bool
+		expectRun     bool
+		expectContent string
+	}{
+		{name: "confirm executes action", choice: confirmChoiceYes, presserID: "user_1", expectRun: true, expectContent: "done"},
+		{name: "cancel skips action", choice: confirmChoiceNo, presserID: "user_1", expectContent: "Cancelled"},
+		{name: "other user cannot confirm", choice: confirmChoiceYes, presserID: "user_2", expectContent: "Only the user"},
+		{name: "expired confirmation", choice: confirmChoiceYes, presserID: "user_1", expire: true, expectContent: "expired"},
+		{name: "action error is reported", choice: confirmChoiceYes, presserID: "user_1", executeErr: errors.New("boom"), expectRun: true, expectContent: "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSession := &testutils.MockSession{}
+			interaction := newConfirmationInteraction("user_1")
+			executed := false
+
+			err := requestConfirmation(mockSession, interaction, &discordgo.MessageEmbed{Title: "Preview"}, false, func() (string, error) {
+				executed = true
+				return "done", tt.executeErr
+			})
+			require.NoError(t, err)
+			require.Len(t, mockSession.RespondData.Components, 1)
+
+			if tt.expire {
+				pendingConfirmationsMu.Lock()
+				pendingConfirmations[interaction.ID].expiresAt = time.Now().Add(-time.Second)
+				pendingConfirmationsMu.Unlock()
+			}
+
+			mockSession.Reset()
+			err = HandleComponentInteraction(mockSession, newButtonInteraction(ComponentID(confirmComponent, tt.choice, interaction.ID), tt.presserID))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectRun, executed)
+			assert.Contains(t, mockSession.RespondData.Content, tt.expectContent)
+
+			pendingConfirmationsMu.Lock()
+			delete(pendingConfirmations, interaction.ID)
+			pendingConfirmationsMu.Unlock()
+		})
+	}
+}
+
+func TestHandleClearCommandWithoutPlayer(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+	interaction := newConfirmationInteraction("user_1")
+
+	err := HandleClearCommand(mockSession, interaction)
+	require.NoError(t, err)
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
+
+func TestHandleClearCommandRequiresModerator(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	interaction := newConfirmationInteraction("user_1")
+
+	err := HandleClearCommand(mockSession, interaction)
+	require.NoError(t, err)
+	assert.Contains(t, mockSession.RespondData.Content, "Manage Messages permission")
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+)
+
+// HandleDebugCommand handles the owner-only /debug command, attaching a JSON snapshot of the guild's player state
+func HandleDebugCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if !IsBotOwner(getInteractionUserID(i)) {
+		return respondWithEphemeral(s, i, translate(i, "debug.owner_only"))
+	}
+	if SimplePlayer == nil {
+		return respondWithEphemeral(s, i, translate(i, "music.unavailable"))
+	}
+
+	state := SimplePlayer.DumpState(i.GuildID)
+	dump, err := marshalGuildState(state)
+	if err != nil {
+		return respondWithEphemeral(s, i, translate(i, "debug.failed", err))
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: summarizeGuildState(state),
+			Files: []*discordgo.File{{
+				Name:        fmt.Sprintf("player-state-%s.json", i.GuildID),
+				ContentType: "application/json",
+				Reader:      bytes.NewReader(dump),
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// marshalGuildState formats a player snapshot for a bug report
+func marshalGuildState(state music.GuildState) ([]byte, error) {
+	return json.MarshalIndent(state, "", "  ")
+}
+
+// summarizeGuildState is the one-line message shown above the attached dump
+func summarizeGuildState(state music.GuildState) string {
+	if state.Player == nil {
+		return "🐞 Player state dump (not connected to voice)"
+	}
+
+	status := "idle"
+	if state.Player.Playing {
+		status = "playing at " + state.Player.Position
+	}
+	return fmt.Sprintf("🐞 Player state dump (%s, %d queued)", status, state.Player.QueueSize)
+}
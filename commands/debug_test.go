@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestHandleDebugCommandRequiresOwner(t *testing.T) {
+	SetBotOwners([]string{"owner_id"})
+	defer SetBotOwners(nil)
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("debug", nil)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "someone_else"}}
+
+	require.NoError(t, HandleDebugCommand(mockSession, interaction))
+	assert.Equal(t, "❌ Only the bot owner can use this command", mockSession.RespondData.Content)
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+}
+
+func TestSetBotOwners(t *testing.T) {
+	SetBotOwners([]string{" owner_id ", ""})
+	defer SetBotOwners(nil)
+
+	assert.True(t, IsBotOwner("owner_id"))
+	assert.False(t, IsBotOwner(""))
+	assert.False(t, IsBotOwner("someone_else"))
+}
+
+func TestSummarizeGuildState(t *testing.T) {
+	assert.Equal(t, "🐞 Player state dump (not connected to voice)", summarizeGuildState(music.GuildState{}))
+
+	playing := music.GuildState{Player: &music.PlayerState{Playing: true, Position: "1m5s", QueueSize: 3}}
+	assert.Equal(t, "🐞 Player state dump (playing at 1m5s, 3 queued)", summarizeGuildState(playing))
+
+	idle := music.GuildState{Player: &music.PlayerState{}}
+	assert.Equal(t, "🐞 Player state dump (idle, 0 queued)", summarizeGuildState(idle))
+}
+
+func TestMarshalGuildState(t *testing.T) {
+	state := music.GuildState{
+		GuildID: "guild_1",
+		Player: &music.PlayerState{
+			Current: &music.TrackState{Title: "Song", StreamURL: "https://stream.example.com/?sig=REDACTED"},
+		},
+	}
+
+	dump, err := marshalGuildState(state)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(dump, &decoded))
+	assert.Equal(t, "guild_1", decoded["guild_id"])
+	player := decoded["player"].(map[string]any)
+	assert.Equal(t, "Song", player["current"].(map[string]any)["title"])
+}
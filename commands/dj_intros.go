@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/services/tts"
+	"pxnx-discord-bot/utils"
+)
+
+// Speaker speaks the DJ intros played between tracks, nil when TTS_BACKEND is not set
+var Speaker *tts.Speaker
+
+// InitializeSpeech sets up speech with the backend TTS_BACKEND chooses, piper or an API
+func InitializeSpeech() {
+	backend, err := tts.New(tts.ConfigFromEnv())
+	if err != nil {
+		utils.LogWarn("Speech is off: %v", err)
+		return
+	}
+	if backend == nil {
+		return
+	}
+	Speaker = tts.NewSpeaker(backend)
+	utils.LogInfo("Speaking DJ intros with %s", backend.Name())
+}
+
+// introNoise matches the parts of video titles that sound odd read aloud, such as "(Official Video)"
+var introNoise = regexp.MustCompile(`(?i)\s*[(\[][^)\]]*\b(official|lyrics?|video|audio|visuali[sz]er|hd|4k|remaster(ed)?)\b[^)\]]*[)\]]`)
+
+// announceTrack speaks the intro of a track with Speaker, naming its requester as the guild knows them
+func announceTrack(memberName func(guildID, userID string) string) music.Announcer {
+	return func(ctx context.Context, guildID string, track types.AudioSource) (string, error) {
+		return Speaker.Speak(ctx, djIntro(track, memberName(guildID, track.RequestedBy)))
+	}
+}
+
+// djIntro is what the DJ says before a track, such as "Up next: Bohemian Rhapsody by Queen, requested by
+// Alex." The requester is left out when empty.
+func djIntro(track types.AudioSource, requester string) string {
+	title := strings.TrimSpace(introNoise.ReplaceAllString(track.Title, ""))
+	if title == "" {
+		title = track.Title
+	}
+	artist := strings.TrimSpace(strings.TrimSuffix(track.Uploader, " - Topic"))
+
+	var intro strings.Builder
+	switch {
+	case track.Live:
+		intro.WriteString("Up next, live: " + title)
+	case artist == "" || strings.Contains(title, " - ") || strings.Contains(strings.ToLower(title), strings.ToLower(artist)):
+		// Titles such as "Queen - Bohemian Rhapsody" name the artist already
+		intro.WriteString("Up next: " + title)
+	default:
+		intro.WriteString("Up next: " + title + " by " + artist)
+	}
+	if requester != "" {
+		intro.WriteString(", requested by " + requester)
+	}
+	intro.WriteString(".")
+	return intro.String()
+}
+
+// stateMemberName returns a requester's name in a guild from the cached state of the guild's shard, empty for
+// the auto-DJ and members the cache doesn't know
+func stateMemberName(session *discordgo.Session, sessionFor func(guildID string) *discordgo.Session) func(guildID, userID string) string {
+	return func(guildID, userID string) string {
+		if _, err := strconv.ParseUint(userID, 10, 64); err != nil {
+			return "" // Not a member, such as music.AutoDJRequester
+		}
+		shard := session
+		if sessionFor != nil {
+			shard = sessionFor(guildID)
+		}
+		if shard == nil || shard.State == nil {
+			return ""
+		}
+		member, err := shard.State.Member(guildID, userID)
+		if err != nil || member.User == nil {
+			return ""
+		}
+		return member.DisplayName()
+	}
+}
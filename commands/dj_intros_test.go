@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/types"
+)
+
+func TestDJIntro(t *testing.T) {
+	tests := []struct {
+		name      string
+		track     types.AudioSource
+		requester string
+		want      string
+	}{
+		{"title and artist", types.AudioSource{Title: "Bohemian Rhapsody", Uploader: "Queen - Topic"}, "Alex", "Up next: Bohemian Rhapsody by Queen, requested by Alex."},
+		{"artist in title", types.AudioSource{Title: "Queen - Bohemian Rhapsody (Official Video Remastered)", Uploader: "Queen Official"}, "", "Up next: Queen - Bohemian Rhapsody."},
+		{"noise only", types.AudioSource{Title: "[Official Audio]"}, "", "Up next: [Official Audio]."},
+		{"live", types.AudioSource{Title: "Radio Paradise", Live: true}, "Sam", "Up next, live: Radio Paradise, requested by Sam."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, djIntro(tt.track, tt.requester))
+		})
+	}
+}
+
+func TestStateMemberName(t *testing.T) {
+	name := stateMemberName(nil, nil)
+	assert.Empty(t, name("guild", music.AutoDJRequester), "the auto-DJ isn't named")
+	assert.Empty(t, name("guild", "123456789"), "members are only named from the cache")
+}
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
 )
 
 var eightBallResponses = []string{
@@ -38,25 +40,11 @@ func Handle8BallCommand(s SessionInterface, i *discordgo.InteractionCreate) erro
 	question := options[0].StringValue()
 	response := get8BallResponse()
 
-	embed := &discordgo.MessageEmbed{
-		Title: "🎱 Magic 8-Ball",
-		Color: 0x9b59b6, // ColorPurple
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:  "Question",
-				Value: question,
-			},
-			{
-				Name:  "Answer",
-				Value: response,
-			},
-		},
-	}
+	embed := NewEmbed("🎱 Magic 8-Ball").
+		Color(utils.ColorPurple).
+		Field("Question", question).
+		Field("Answer", response).
+		Build()
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	return respondWithEmbed(s, i, embed)
 }
@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
+)
+
+// Discord's limits on embeds
+const (
+	maxEmbedTitle       = 256
+	maxEmbedDescription = 4096
+	maxEmbedFields      = 25
+	maxFieldName        = 256
+	maxFieldValue       = 1024
+	maxEmbedFooter      = 2048
+)
+
+// Theme is the look shared by the bot's embeds
+type Theme struct {
+	Brand   int    // Color of embeds without a style of their own
+	Success int    // Color of embeds reporting something that worked
+	Warning int    // Color of embeds about something to look at
+	Error   int    // Color of embeds reporting a failure
+	Muted   int    // Color of embeds about something inactive or empty
+	Footer  string // Added to embeds without a footer of their own, "" adds none
+}
+
+// DefaultTheme returns the colors the bot always used, without a footer
+func DefaultTheme() Theme {
+	return Theme{
+		Brand:   utils.ColorBlue,
+		Success: utils.ColorGreen,
+		Warning: utils.ColorOrange,
+		Error:   utils.ColorRed,
+		Muted:   0x95a5a6, // Grey
+	}
+}
+
+// CurrentTheme is the theme embeds are built with, from EMBED_COLOR and EMBED_FOOTER
+var CurrentTheme = DefaultTheme()
+
+// InitializeTheme loads the embed theme from EMBED_COLOR and EMBED_FOOTER
+func InitializeTheme() {
+	CurrentTheme = LoadTheme()
+}
+
+// LoadTheme returns the default theme with the brand color from EMBED_COLOR, such as #5865F2, and the
+// footer from EMBED_FOOTER. An invalid color is ignored with a warning.
+func LoadTheme() Theme {
+	theme := DefaultTheme()
+	if raw := strings.TrimSpace(os.Getenv("EMBED_COLOR")); raw != "" {
+		if color, err := ParseColor(raw); err != nil {
+			utils.LogWarn("Ignoring EMBED_COLOR: %v", err)
+		} else {
+			theme.Brand = color
+		}
+	}
+	theme.Footer = strings.TrimSpace(os.Getenv("EMBED_FOOTER"))
+	return theme
+}
+
+// ParseColor reads a hex RGB color written as "#5865F2", "0x5865F2" or "5865F2"
+func ParseColor(raw string) (int, error) {
+	hex := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(raw)), "#"), "0x")
+	color, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return 0, fmt.Errorf("invalid color %q, expected hex RGB such as #5865F2", raw)
+	}
+	return int(color), nil
+}
+
+// EmbedStyle picks an embed's color from the theme
+type EmbedStyle int
+
+const (
+	StyleBrand EmbedStyle = iota
+	StyleSuccess
+	StyleWarning
+	StyleError
+	StyleMuted
+)
+
+// color returns the style's color in a theme
+func (s EmbedStyle) color(theme Theme) int {
+	switch s {
+	case StyleSuccess:
+		return theme.Success
+	case StyleWarning:
+		return theme.Warning
+	case StyleError:
+		return theme.Error
+	case StyleMuted:
+		return theme.Muted
+	default:
+		return theme.Brand
+	}
+}
+
+// EmbedBuilder builds an embed within Discord's limits in the current theme. Text that is too long is
+// cut with an ellipsis and fields past the 25th are dropped, so a long queue or error never makes
+// Discord refuse the whole response.
+type EmbedBuilder struct {
+	embed *discordgo.MessageEmbed
+	style EmbedStyle
+	color int // Set by Color, 0 uses the style's color
+}
+
+// NewEmbed starts an embed in the brand color
+func NewEmbed(title string) *EmbedBuilder {
+	return &EmbedBuilder{embed: &discordgo.MessageEmbed{Title: title}}
+}
+
+// SuccessEmbed starts an embed reporting something that worked
+func SuccessEmbed(title string) *EmbedBuilder {
+	return NewEmbed(title).Style(StyleSuccess)
+}
+
+// WarningEmbed starts an embed about something to look at
+func WarningEmbed(title string) *EmbedBuilder {
+	return NewEmbed(title).Style(StyleWarning)
+}
+
+// ErrorEmbed starts an embed reporting a failure
+func ErrorEmbed(title string) *EmbedBuilder {
+	return NewEmbed(title).Style(StyleError)
+}
+
+// Style changes which of the theme's colors the embed has
+func (b *EmbedBuilder) Style(style EmbedStyle) *EmbedBuilder {
+	b.style = style
+	return b
+}
+
+// Color gives the embed a color of its own instead of the theme's, for commands with their own look
+func (b *EmbedBuilder) Color(color int) *EmbedBuilder {
+	b.color = color
+	return b
+}
+
+// Description sets the text below the title
+func (b *EmbedBuilder) Description(text string) *EmbedBuilder {
+	b.embed.Description = text
+	return b
+}
+
+// Descriptionf sets the text below the title from a format
+func (b *EmbedBuilder) Descriptionf(format string, args ...any) *EmbedBuilder {
+	return b.Description(fmt.Sprintf(format, args...))
+}
+
+// URL links the title
+func (b *EmbedBuilder) URL(url string) *EmbedBuilder {
+	b.embed.URL = url
+	return b
+}
+
+// Thumbnail shows an image next to the text, nothing for ""
+func (b *EmbedBuilder) Thumbnail(url string) *EmbedBuilder {
+	if url != "" {
+		b.embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: url}
+	}
+	return b
+}
+
+// Field adds a field on its own line
+func (b *EmbedBuilder) Field(name, value string) *EmbedBuilder {
+	return b.addField(name, value, false)
+}
+
+// InlineField adds a field that sits next to other inline fields
+func (b *EmbedBuilder) InlineField(name, value string) *EmbedBuilder {
+	return b.addField(name, value, true)
+}
+
+// LinesField adds lines under a name, spread over as many fields as their length needs. Lines are never
+// split; later fields are named "<name> (continued)".
+func (b *EmbedBuilder) LinesField(name string, lines []string) *EmbedBuilder {
+	var chunk strings.Builder
+	fieldName := name
+	for _, line := range lines {
+		if chunk.Len() > 0 && chunk.Len()+1+len(line) > maxFieldValue {
+			b.addField(fieldName, chunk.String(), false)
+			chunk.Reset()
+			fieldName = name + " (continued)"
+		}
+		if chunk.Len() > 0 {
+			chunk.WriteString("\n")
+		}
+		chunk.WriteString(line)
+	}
+	if chunk.Len() > 0 {
+		b.addField(fieldName, chunk.String(), false)
+	}
+	return b
+}
+
+// addField adds a field with its text cut to Discord's limits, dropping it once the embed is full
+func (b *EmbedBuilder) addField(name, value string, inline bool) *EmbedBuilder {
+	if len(b.embed.Fields) >= maxEmbedFields {
+		utils.LogDebug("Dropping embed field %q, %q already has %d", name, b.embed.Title, maxEmbedFields)
+		return b
+	}
+	b.embed.Fields = append(b.embed.Fields, &discordgo.MessageEmbedField{
+		Name:   truncateText(name, maxFieldName),
+		Value:  truncateText(value, maxFieldValue),
+		Inline: inline,
+	})
+	return b
+}
+
+// Footer sets the text at the bottom, which replaces the theme's footer
+func (b *EmbedBuilder) Footer(text string) *EmbedBuilder {
+	b.embed.Footer = &discordgo.MessageEmbedFooter{Text: text}
+	return b
+}
+
+// Timestamp shows a time next to the footer
+func (b *EmbedBuilder) Timestamp(t time.Time) *EmbedBuilder {
+	b.embed.Timestamp = t.Format(time.RFC3339)
+	return b
+}
+
+// Build returns the embed in the current theme with its title, description and footer cut to Discord's
+// limits
+func (b *EmbedBuilder) Build() *discordgo.MessageEmbed {
+	embed := *b.embed
+	embed.Color = b.color
+	if embed.Color == 0 {
+		embed.Color = b.style.color(CurrentTheme)
+	}
+	if embed.Footer == nil && CurrentTheme.Footer != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: CurrentTheme.Footer}
+	}
+
+	embed.Title = truncateText(embed.Title, maxEmbedTitle)
+	embed.Description = truncateText(embed.Description, maxEmbedDescription)
+	if embed.Footer != nil {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: truncateText(embed.Footer.Text, maxEmbedFooter)}
+	}
+	return &embed
+}
+
+// truncateText shortens text to at most limit characters, adding an ellipsis when cut
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/utils"
+)
+
+// useTheme sets the embed theme for the duration of a test
+func useTheme(t *testing.T, theme Theme) {
+	original := CurrentTheme
+	CurrentTheme = theme
+	t.Cleanup(func() { CurrentTheme = original })
+}
+
+func TestParseColor(t *testing.T) {
+	for _, raw := range []string{"#5865F2", "0x5865f2", " 5865F2 "} {
+		color, err := ParseColor(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, 0x5865f2, color, raw)
+	}
+
+	for _, raw := range []string{"", "#fff", "blue", "#5865F2FF"} {
+		_, err := ParseColor(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	t.Setenv("EMBED_COLOR", "#5865F2")
+	t.Setenv("EMBED_FOOTER", " pxnx bot ")
+	theme := LoadTheme()
+	assert.Equal(t, 0x5865f2, theme.Brand)
+	assert.Equal(t, "pxnx bot", theme.Footer)
+	assert.Equal(t, utils.ColorRed, theme.Error, "only the brand color is configurable")
+
+	t.Setenv("EMBED_COLOR", "not a color")
+	assert.Equal(t, DefaultTheme().Brand, LoadTheme().Brand)
+}
+
+func TestEmbedBuilderTheme(t *testing.T) {
+	useTheme(t, Theme{Brand: 0x111111, Success: 0x222222, Warning: 0x333333, Error: 0x444444, Muted: 0x555555, Footer: "pxnx bot"})
+
+	assert.Equal(t, 0x111111, NewEmbed("Brand").Build().Color)
+	assert.Equal(t, 0x222222, SuccessEmbed("Success").Build().Color)
+	assert.Equal(t, 0x333333, WarningEmbed("Warning").Build().Color)
+	assert.Equal(t, 0x444444, ErrorEmbed("Error").Build().Color)
+	assert.Equal(t, 0x555555, NewEmbed("Muted").Style(StyleMuted).Build().Color)
+	assert.Equal(t, 0xf1c40f, ErrorEmbed("Own color").Color(0xf1c40f).Build().Color)
+
+	assert.Equal(t, "pxnx bot", NewEmbed("Themed").Build().Footer.Text)
+	assert.Equal(t, "Own footer", NewEmbed("Footer").Footer("Own footer").Build().Footer.Text)
+}
+
+func TestEmbedBuilderLimits(t *testing.T) {
+	useTheme(t, DefaultTheme())
+
+	builder := NewEmbed(strings.Repeat("t", 300)).Description(strings.Repeat("d", 5000))
+	for n := range 30 {
+		builder.InlineField(strings.Repeat("n", 300), strings.Repeat("v", 2000+n))
+	}
+	embed := builder.Build()
+
+	assert.Len(t, []rune(embed.Title), maxEmbedTitle)
+	assert.True(t, strings.HasSuffix(embed.Title, "…"))
+	assert.Len(t, []rune(embed.Description), maxEmbedDescription)
+	require.Len(t, embed.Fields, maxEmbedFields)
+	assert.Len(t, []rune(embed.Fields[0].Name), maxFieldName)
+	assert.Len(t, []rune(embed.Fields[0].Value), maxFieldValue)
+	assert.True(t, embed.Fields[0].Inline)
+	assert.Nil(t, embed.Footer, "the default theme adds no footer")
+}
+
+func TestEmbedBuilderLinesField(t *testing.T) {
+	line := strings.Repeat("x", 300)
+	embed := NewEmbed("Queue").LinesField("Up Next", []string{line, line, line, line, "last"}).Build()
+
+	require.Len(t, embed.Fields, 2)
+	assert.Equal(t, "Up Next", embed.Fields[0].Name)
+	assert.Equal(t, strings.Join([]string{line, line, line}, "\n"), embed.Fields[0].Value, "lines are never split")
+	assert.Equal(t, "Up Next (continued)", embed.Fields[1].Name)
+	assert.Equal(t, line+"\nlast", embed.Fields[1].Value)
+
+	assert.Empty(t, NewEmbed("Empty").LinesField("Up Next", nil).Build().Fields)
+}
+
+func TestEmbedBuilderBuildIsRepeatable(t *testing.T) {
+	builder := NewEmbed("Twice").Field("One", "1")
+	first := builder.Build()
+	first.Title = "Changed"
+	assert.Equal(t, "Twice", builder.Build().Title)
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// interactionTokenLifetime is how long Discord accepts edits and followups for an interaction
+const interactionTokenLifetime = 15 * time.Minute
+
+// interactionTokenMargin leaves room for in-flight requests before the token actually expires
+const interactionTokenMargin = 30 * time.Second
+
+// InteractionResponder updates the response of a long-running interaction. While the interaction
+// token is valid it edits the original response; once the token is about to expire it falls back
+// to channel messages that reply to the original response.
+type InteractionResponder struct {
+	session   SessionInterface
+	i         *discordgo.InteractionCreate
+	expiresAt time.Time
+	now       func() time.Time
+
+	mu        sync.Mutex
+	messageID string // Original response, used as the reply reference after expiry
+}
+
+// NewInteractionResponder creates a responder for an interaction that has already been acknowledged
+func NewInteractionResponder(s SessionInterface, i *discordgo.InteractionCreate) *InteractionResponder {
+	// The interaction ID is a snowflake carrying the creation time, which is when the token lifetime starts
+	createdAt, err := discordgo.SnowflakeTimestamp(i.ID)
+	if err != nil {
+		createdAt = time.Now()
+	}
+
+	return &InteractionResponder{
+		session:   s,
+		i:         i,
+		expiresAt: createdAt.Add(interactionTokenLifetime - interactionTokenMargin),
+		now:       time.Now,
+	}
+}
+
+// Expired reports whether the interaction token can no longer be used
+func (r *InteractionResponder) Expired() bool {
+	return !r.now().Before(r.expiresAt)
+}
+
+// Edit replaces the original response, or sends a reply in the channel once the token has expired.
+// Like every update it is shown the way the member prefers.
+func (r *InteractionResponder) Edit(content string, embeds ...*discordgo.MessageEmbed) error {
+	hasEmbeds := len(embeds) > 0
+	content, embeds = contentFor(getInteractionUserID(r.i), content, embeds)
+	if r.Expired() {
+		return r.sendChannelReply(content, embeds)
+	}
+
+	edit := &discordgo.WebhookEdit{Content: &content}
+	if hasEmbeds {
+		edit.Embeds = &embeds
+	}
+
+	message, err := r.session.InteractionResponseEdit(r.i.Interaction, edit)
+	if err != nil {
+		return fmt.Errorf("failed to edit interaction response: %w", err)
+	}
+	r.rememberMessage(message)
+	return nil
+}
+
+// Followup sends an additional message, falling back to a channel reply once the token has expired
+func (r *InteractionResponder) Followup(content string, embeds ...*discordgo.MessageEmbed) error {
+	content, embeds = contentFor(getInteractionUserID(r.i), content, embeds)
+	if r.Expired() {
+		return r.sendChannelReply(content, embeds)
+	}
+
+	_, err := r.session.FollowupMessageCreate(r.i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Embeds:  embeds,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send followup: %w", err)
+	}
+	return nil
+}
+
+// sendChannelReply posts a channel message that references the original response when it is known
+func (r *InteractionResponder) sendChannelReply(content string, embeds []*discordgo.MessageEmbed) error {
+	data := &discordgo.MessageSend{
+		Content: content,
+		Embeds:  embeds,
+	}
+
+	r.mu.Lock()
+	if r.messageID != "" {
+		data.Reference = &discordgo.MessageReference{
+			MessageID: r.messageID,
+			ChannelID: r.i.ChannelID,
+			GuildID:   r.i.GuildID,
+		}
+	}
+	r.mu.Unlock()
+
+	if _, err := r.session.ChannelMessageSendComplex(r.i.ChannelID, data); err != nil {
+		return fmt.Errorf("failed to send channel message after interaction token expired: %w", err)
+	}
+	return nil
+}
+
+// rememberMessage records the original response message ID for later reply references
+func (r *InteractionResponder) rememberMessage(message *discordgo.Message) {
+	if message == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.messageID == "" {
+		r.messageID = message.ID
+	}
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/testutils"
+)
+
+func TestInteractionResponderEditBeforeExpiry(t *testing.T) {
+	mockSession := &testutils.MockSession{
+		InteractionResponseEditReturn: &discordgo.Message{ID: "response_message_id"},
+	}
+	responder := NewInteractionResponder(mockSession, testutils.CreateTestInteraction("play", nil))
+
+	require.False(t, responder.Expired())
+	require.NoError(t, responder.Edit("Importing playlist..."))
+
+	assert.True(t, mockSession.InteractionResponseEditCalled)
+	assert.False(t, mockSession.ChannelMessageSendCalled)
+}
+
+func TestInteractionResponderFallsBackAfterExpiry(t *testing.T) {
+	mockSession := &testutils.MockSession{
+		InteractionResponseEditReturn: &discordgo.Message{ID: "response_message_id"},
+	}
+	interaction := testutils.CreateTestInteraction("play", nil)
+	responder := NewInteractionResponder(mockSession, interaction)
+
+	require.NoError(t, responder.Edit("Importing playlist..."))
+
+	// Jump past the token lifetime
+	responder.now = func() time.Time { return time.Now().Add(interactionTokenLifetime) }
+	require.True(t, responder.Expired())
+
+	mockSession.Reset()
+	require.NoError(t, responder.Edit("Imported 500 tracks"))
+
+	assert.False(t, mockSession.InteractionResponseEditCalled)
+	require.True(t, mockSession.ChannelMessageSendCalled)
+	assert.Equal(t, "Imported 500 tracks", mockSession.ChannelMessageSendData.Content)
+	require.NotNil(t, mockSession.ChannelMessageSendData.Reference)
+	assert.Equal(t, "response_message_id", mockSession.ChannelMessageSendData.Reference.MessageID)
+	assert.Equal(t, interaction.ChannelID, mockSession.ChannelMessageSendData.Reference.ChannelID)
+
+	mockSession.Reset()
+	require.NoError(t, responder.Followup("Done"))
+	assert.False(t, mockSession.FollowupCalled)
+	assert.True(t, mockSession.ChannelMessageSendCalled)
+}
+
+func TestInteractionResponderSnowflakeExpiry(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("play", nil)
+
+	// Snowflake created 20 minutes ago
+	createdAt := time.Now().Add(-20 * time.Minute)
+	interaction.ID = snowflakeAt(createdAt)
+
+	responder := NewInteractionResponder(mockSession, interaction)
+	assert.True(t, responder.Expired())
+}
+
+func TestInteractionResponderErrors(t *testing.T) {
+	mockSession := &testutils.MockSession{
+		InteractionResponseEditError: errors.New("unknown webhook"),
+	}
+	responder := NewInteractionResponder(mockSession, testutils.CreateTestInteraction("play", nil))
+
+	err := responder.Edit("Importing playlist...")
+	assert.ErrorContains(t, err, "unknown webhook")
+}
+
+// snowflakeAt builds a Discord snowflake ID for the given creation time
+func snowflakeAt(t time.Time) string {
+	const discordEpoch = 1420070400000
+	ms := t.UnixMilli() - discordEpoch
+	return strconv.FormatInt(ms<<22, 10)
+}
+
+func TestInteractionResponderFollowsPlainTextPreference(t *testing.T) {
+	store := usePreferences(t)
+	require.NoError(t, store.SetPlainText("user_1", true))
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("play", nil)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user_1"}}
+	responder := NewInteractionResponder(mockSession, interaction)
+
+	embed := NewEmbed("🎶 Now Playing").Field("Duration", "3:30").Build()
+	require.NoError(t, responder.Edit("🎵 Now playing", embed))
+
+	edit := mockSession.InteractionResponseEditData
+	assert.Equal(t, "Now playing\n\nNow Playing\nDuration: 3:30", *edit.Content)
+	require.NotNil(t, edit.Embeds, "the edit clears the embeds a response had")
+	assert.Empty(t, *edit.Embeds)
+}
@@ -12,6 +12,11 @@ type SessionInterface interface {
 	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
 	Guild(guildID string, options ...discordgo.RequestOption) (*discordgo.Guild, error)
 	Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	// Channel messages are used once an interaction token has expired
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	// Message history for /summarize, newest first
+	ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error)
 	// Access to session state for voice channel detection
 	State() *discordgo.State
 }
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+)
+
+// Handle247Command handles the /music 247 command, keeping the bot in its voice channel when everyone leaves
+func Handle247Command(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	enabled := false
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "mode" {
+			enabled = option.StringValue() == "on"
+		}
+	}
+
+	err := SimplePlayer.SetStayConnected(i.GuildID, enabled)
+	if errors.Is(err, music.ErrNotConnected) {
+		return respondWithEphemeral(s, i, translate(i, "music.join_first_retry"))
+	}
+	if err != nil {
+		return respondWithInteraction(s, i, translate(i, "music.setting_not_saved", describe247(enabled)))
+	}
+	return respondWithInteraction(s, i, describe247(enabled))
+}
+
+// describe247 explains the 24/7 setting
+func describe247(enabled bool) string {
+	if enabled {
+		return "📻 24/7 mode is on, I'll stay in this voice channel even when it's empty and come back after restarts"
+	}
+	return "📻 24/7 mode is off, I'll leave the voice channel shortly after everyone else does"
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestDescribe247(t *testing.T) {
+	assert.Contains(t, describe247(true), "is on")
+	assert.Contains(t, describe247(false), "is off")
+}
+
+func TestHandle247CommandWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("247", nil)
+	require.NoError(t, Handle247Command(mockSession, interaction))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
+
+func TestHandle247CommandNeedsVoiceChannel(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("247", []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "mode", Type: discordgo.ApplicationCommandOptionString, Value: "on"},
+	})
+	require.NoError(t, Handle247Command(mockSession, interaction))
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+	assert.Contains(t, mockSession.RespondData.Content, "/music join")
+	assert.False(t, SimplePlayer.StayConnected(interaction.GuildID))
+
+	// Turning it off works without a connection
+	off := testutils.CreateTestInteraction("247", []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "mode", Type: discordgo.ApplicationCommandOptionString, Value: "off"},
+	})
+	require.NoError(t, Handle247Command(mockSession, off))
+	assert.Contains(t, mockSession.RespondData.Content, "is off")
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/history"
+	"pxnx-discord-bot/music/types"
+)
+
+// defaultRepeatWindowHours is used when /music antirepeat is enabled without an hours option
+const defaultRepeatWindowHours = 6
+
+// HandleAntiRepeatCommand handles the /music antirepeat command, configuring how recently played tracks are handled
+func HandleAntiRepeatCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	mode := history.RepeatAllow
+	hours := int64(defaultRepeatWindowHours)
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "mode":
+			mode = history.RepeatMode(option.StringValue())
+		case "hours":
+			hours = option.IntValue()
+		}
+	}
+
+	policy := history.RepeatPolicy{Mode: mode, Window: time.Duration(hours) * time.Hour}
+	if err := SimplePlayer.SetRepeatPolicy(i.GuildID, policy); err != nil {
+		return respondWithInteraction(s, i, translate(i, "music.setting_not_saved", describeRepeatPolicy(policy)))
+	}
+	return respondWithInteraction(s, i, describeRepeatPolicy(policy))
+}
+
+// describeRepeatPolicy explains an anti-repeat setting to users
+func describeRepeatPolicy(policy history.RepeatPolicy) string {
+	if !policy.Enabled() {
+		return "🔁 Anti-repeat is off, any track can be queued again"
+	}
+
+	hours := int(policy.Window / time.Hour)
+	if policy.Mode == history.RepeatRefuse {
+		return fmt.Sprintf("🚫 Tracks played in the last %d hours will be refused", hours)
+	}
+	return fmt.Sprintf("⚠️ Tracks played in the last %d hours will be queued with a warning", hours)
+}
+
+// formatRepeatNotice tells a requester that a track was played recently
+func formatRepeatNotice(title string, ago time.Duration, policy history.RepeatPolicy) string {
+	if policy.Mode == history.RepeatRefuse {
+		return fmt.Sprintf("🚫 **%s** was played %s, this server doesn't allow repeats within %d hours",
+			title, formatAgo(ago), int(policy.Window/time.Hour))
+	}
+	return fmt.Sprintf("⚠️ **%s** was already played %s", title, formatAgo(ago))
+}
+
+// formatPlaylistRepeats summarises repeats found in a playlist import
+func formatPlaylistRepeats(repeats int, mode history.RepeatMode) string {
+	if mode == history.RepeatRefuse {
+		return fmt.Sprintf("Skipped %d recently played tracks", repeats)
+	}
+	return fmt.Sprintf("%d tracks were played recently", repeats)
+}
+
+// filterRepeats applies the guild's anti-repeat setting to a batch of tracks.
+// Refused repeats are dropped; the count of repeats found is returned either way.
+func filterRepeats(guildID string, tracks []types.AudioSource) ([]types.AudioSource, int) {
+	refuse := SimplePlayer.RepeatPolicy(guildID).Mode == history.RepeatRefuse
+
+	kept := make([]types.AudioSource, 0, len(tracks))
+	repeats := 0
+	for _, track := range tracks {
+		if _, repeat := SimplePlayer.CheckRepeat(guildID, track); repeat {
+			repeats++
+			if refuse {
+				continue
+			}
+		}
+		kept = append(kept, track)
+	}
+	return kept, repeats
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/history"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestDescribeRepeatPolicy(t *testing.T) {
+	assert.Contains(t, describeRepeatPolicy(history.RepeatPolicy{Mode: history.RepeatAllow}), "off")
+	assert.Equal(t, "🚫 Tracks played in the last 6 hours will be refused",
+		describeRepeatPolicy(history.RepeatPolicy{Mode: history.RepeatRefuse, Window: 6 * time.Hour}))
+	assert.Equal(t, "⚠️ Tracks played in the last 2 hours will be queued with a warning",
+		describeRepeatPolicy(history.RepeatPolicy{Mode: history.RepeatWarn, Window: 2 * time.Hour}))
+}
+
+func TestFormatRepeatNotice(t *testing.T) {
+	refuse := history.RepeatPolicy{Mode: history.RepeatRefuse, Window: 3 * time.Hour}
+	assert.Equal(t, "🚫 **Song** was played 45m ago, this server doesn't allow repeats within 3 hours",
+		formatRepeatNotice("Song", 45*time.Minute, refuse))
+
+	warn := history.RepeatPolicy{Mode: history.RepeatWarn, Window: 3 * time.Hour}
+	assert.Equal(t, "⚠️ **Song** was already played 45m ago", formatRepeatNotice("Song", 45*time.Minute, warn))
+}
+
+func TestFormatPlaylistRepeats(t *testing.T) {
+	assert.Equal(t, "Skipped 3 recently played tracks", formatPlaylistRepeats(3, history.RepeatRefuse))
+	assert.Equal(t, "3 tracks were played recently", formatPlaylistRepeats(3, history.RepeatWarn))
+}
+
+func TestHandleAntiRepeatCommandWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("antirepeat", nil)
+	require.NoError(t, HandleAntiRepeatCommand(mockSession, interaction))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
+
+func TestHandleAntiRepeatCommandSavesPolicy(t *testing.T) {
+	store := useGuildSettings(t)
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	SimplePlayer.UseSettings(store)
+	defer func() { SimplePlayer = original }()
+
+	interaction := testutils.CreateTestInteraction("antirepeat", []*discordgo.ApplicationCommandInteractionDataOption{
+		testutils.CreateStringOption("mode", "refuse"),
+		{Name: "hours", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(3)},
+	})
+	require.NoError(t, HandleAntiRepeatCommand(&testutils.MockSession{}, interaction))
+	assert.Equal(t, settings.Guild{RepeatMode: "refuse", RepeatWindowHours: 3}, store.Get(interaction.GuildID))
+	assert.Equal(t, history.RepeatPolicy{Mode: history.RepeatRefuse, Window: 3 * time.Hour}, SimplePlayer.RepeatPolicy(interaction.GuildID))
+
+	off := testutils.CreateTestInteraction("antirepeat", []*discordgo.ApplicationCommandInteractionDataOption{
+		testutils.CreateStringOption("mode", "off"),
+	})
+	require.NoError(t, HandleAntiRepeatCommand(&testutils.MockSession{}, off))
+	assert.Equal(t, settings.Guild{}, store.Get(interaction.GuildID), "turning it off leaves nothing saved")
+	assert.False(t, SimplePlayer.RepeatPolicy(interaction.GuildID).Enabled())
+}
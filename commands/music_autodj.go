@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// HandleAutoDJCommand handles the /music autodj command, turning the history-based rotation on or off
+func HandleAutoDJCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	enabled := false
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "mode" {
+			enabled = option.StringValue() == "on"
+		}
+	}
+
+	saveErr := SimplePlayer.SetAutoDJ(i.GuildID, enabled)
+
+	_, connected := SimplePlayer.GetPlayer(i.GuildID)
+	message := describeAutoDJ(enabled, connected, len(SimplePlayer.Stats(i.GuildID)))
+	if saveErr != nil {
+		message += "\n⚠️ The setting could not be saved and resets when the bot restarts"
+	}
+	return respondWithInteraction(s, i, message)
+}
+
+// describeAutoDJ explains the auto-DJ state; played is how many distinct tracks the server has listened to
+func describeAutoDJ(enabled, connected bool, played int) string {
+	if !enabled {
+		return "📻 Auto-DJ is off, playback stops when the queue runs out"
+	}
+
+	message := "📻 Auto-DJ is on, an empty queue is refilled from this server's favourites and related songs"
+	if played == 0 {
+		message += "\nNothing has been played here yet, play a few songs to give it something to go on"
+	}
+	if !connected {
+		message += "\nUse /music join to bring the bot into a voice channel"
+	}
+	return message
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/testutils"
+)
+
+func TestDescribeAutoDJ(t *testing.T) {
+	assert.Equal(t, "📻 Auto-DJ is off, playback stops when the queue runs out", describeAutoDJ(false, true, 10))
+
+	on := describeAutoDJ(true, true, 10)
+	assert.Contains(t, on, "Auto-DJ is on")
+	assert.NotContains(t, on, "/music join")
+	assert.NotContains(t, on, "Nothing has been played")
+
+	fresh := describeAutoDJ(true, false, 0)
+	assert.Contains(t, fresh, "Nothing has been played")
+	assert.Contains(t, fresh, "/music join")
+}
+
+func TestHandleAutoDJCommandWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("autodj", nil)
+	require.NoError(t, HandleAutoDJCommand(mockSession, interaction))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/broadcast"
+)
+
+// handleMusicBroadcast handles /music broadcast. The bot owner starts a broadcast of one server's music,
+// other servers join it to play the same songs at the same time.
+func handleMusicBroadcast(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	options := i.ApplicationCommandData().Options[0].Options
+	if len(options) == 0 {
+		return respondWithEphemeral(s, i, translate(i, "broadcast.unknown"))
+	}
+
+	switch options[0].Name {
+	case "status":
+		current, running := SimplePlayer.Broadcast()
+		if !running {
+			return respondWithInteraction(s, i, translate(i, "broadcast.none"))
+		}
+		return respondWithEmbed(s, i, createBroadcastEmbed(current, i.GuildID))
+	case "start":
+		if !IsBotOwner(getInteractionUserID(i)) {
+			return respondWithEphemeral(s, i, translate(i, "broadcast.start_owner_only"))
+		}
+		if err := SimplePlayer.StartBroadcast(i.GuildID, getInteractionUserID(i)); err != nil {
+			return respondWithEphemeral(s, i, describeBroadcastError(err))
+		}
+		return respondWithInteraction(s, i, translate(i, "broadcast.started"))
+	case "stop":
+		if !IsBotOwner(getInteractionUserID(i)) {
+			return respondWithEphemeral(s, i, translate(i, "broadcast.stop_owner_only"))
+		}
+		ended, stopped := SimplePlayer.StopBroadcast()
+		if !stopped {
+			return respondWithEphemeral(s, i, translate(i, "broadcast.not_running"))
+		}
+		return respondWithInteraction(s, i, translate(i, "broadcast.ended", formatListeners(len(ended.Followers))))
+	case "join":
+		if !canChangeMusicSettings(i) {
+			return respondWithEphemeral(s, i, translate(i, "broadcast.join_denied"))
+		}
+		if err := SimplePlayer.JoinBroadcast(i.GuildID); err != nil {
+			return respondWithEphemeral(s, i, describeBroadcastError(err))
+		}
+		return respondWithInteraction(s, i, translate(i, "broadcast.joined"))
+	case "leave":
+		if !canChangeMusicSettings(i) {
+			return respondWithEphemeral(s, i, translate(i, "broadcast.leave_denied"))
+		}
+		if !SimplePlayer.LeaveBroadcast(i.GuildID) {
+			return respondWithEphemeral(s, i, translate(i, "broadcast.not_listening"))
+		}
+		return respondWithInteraction(s, i, translate(i, "broadcast.left"))
+	default:
+		return respondWithEphemeral(s, i, translate(i, "broadcast.unknown"))
+	}
+}
+
+// describeBroadcastError explains why a broadcast couldn't be started or joined
+func describeBroadcastError(err error) string {
+	switch {
+	case errors.Is(err, music.ErrNotConnected):
+		return "❌ I need to be in a voice channel first. Use `/music join`"
+	case errors.Is(err, music.ErrListeningAlong):
+		return "❌ This server is listening along to the broadcast, it can't broadcast itself"
+	case errors.Is(err, broadcast.ErrRunning):
+		return "❌ A broadcast is already running, stop it first"
+	case errors.Is(err, broadcast.ErrNotRunning):
+		return "❌ No broadcast is running"
+	case errors.Is(err, broadcast.ErrSource):
+		return "❌ This server is the broadcast's source"
+	default:
+		return fmt.Sprintf("❌ %v", err)
+	}
+}
+
+// createBroadcastEmbed shows the running broadcast as seen from a guild
+func createBroadcastEmbed(current broadcast.Broadcast, guildID string) *discordgo.MessageEmbed {
+	role := "Not listening along"
+	switch {
+	case current.SourceGuildID == guildID:
+		role = "Broadcasting"
+	case slices.Contains(current.Followers, guildID):
+		role = "Listening along"
+	}
+
+	return NewEmbed("📡 Broadcast").
+		InlineField("This Server", role).
+		InlineField("Listeners", formatListeners(len(current.Followers))).
+		InlineField("Started", fmt.Sprintf("<t:%d:R> by <@%s>", current.StartedAt.Unix(), current.StartedBy)).
+		Build()
+}
+
+// formatListeners counts the servers listening along
+func formatListeners(n int) string {
+	if n == 1 {
+		return "1 server"
+	}
+	return fmt.Sprintf("%d servers", n)
+}
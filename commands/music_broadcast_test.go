@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/broadcast"
+	"pxnx-discord-bot/testutils"
+)
+
+// newBroadcastInteraction builds /music broadcast <subcommand> run by a member
+func newBroadcastInteraction(subcommand, userID string, permissions int64) *discordgo.InteractionCreate {
+	interaction := testutils.CreateTestInteraction("music", []*discordgo.ApplicationCommandInteractionDataOption{{
+		Name: "broadcast",
+		Type: discordgo.ApplicationCommandOptionSubCommandGroup,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: subcommand, Type: discordgo.ApplicationCommandOptionSubCommand},
+		},
+	}})
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: userID}, Permissions: permissions}
+	return interaction
+}
+
+func TestHandleMusicBroadcast(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+	SetBotOwners([]string{"owner_id"})
+	defer SetBotOwners(nil)
+
+	tests := []struct {
+		subcommand  string
+		userID      string
+		permissions int64
+		want        string
+	}{
+		{"status", "member", 0, "📡 No broadcast is running"},
+		{"start", "member", discordgo.PermissionAdministrator, "❌ Only the bot owner can start a broadcast"},
+		{"start", "owner_id", 0, "❌ I need to be in a voice channel first. Use `/music join`"},
+		{"stop", "owner_id", 0, "❌ No broadcast is running"},
+		{"join", "member", 0, "❌ Listening along needs the Manage Server permission"},
+		{"join", "member", discordgo.PermissionManageGuild, "❌ I need to be in a voice channel first. Use `/music join`"},
+		{"leave", "member", discordgo.PermissionManageGuild, "❌ This server isn't listening along to a broadcast"},
+	}
+	for _, tt := range tests {
+		mockSession := &testutils.MockSession{}
+		require.NoError(t, HandleMusicCommand(mockSession, newBroadcastInteraction(tt.subcommand, tt.userID, tt.permissions)))
+		assert.Equal(t, tt.want, mockSession.RespondData.Content, "%s by %s", tt.subcommand, tt.userID)
+	}
+}
+
+func TestDescribeBroadcastError(t *testing.T) {
+	assert.Equal(t, "❌ A broadcast is already running, stop it first", describeBroadcastError(broadcast.ErrRunning))
+	assert.Equal(t, "❌ This server is the broadcast's source", describeBroadcastError(fmt.Errorf("joining: %w", broadcast.ErrSource)))
+	assert.Equal(t, "❌ This server is listening along to the broadcast, it can't broadcast itself", describeBroadcastError(music.ErrListeningAlong))
+}
+
+func TestCreateBroadcastEmbed(t *testing.T) {
+	current := broadcast.Broadcast{
+		SourceGuildID: "source",
+		StartedBy:     "owner_id",
+		StartedAt:     time.Unix(1700000000, 0),
+		Followers:     []string{"follower"},
+	}
+
+	values := embedValues(createBroadcastEmbed(current, "source"))
+	assert.Equal(t, "Broadcasting", values["This Server"])
+	assert.Equal(t, "1 server", values["Listeners"])
+	assert.Equal(t, "<t:1700000000:R> by <@owner_id>", values["Started"])
+
+	assert.Equal(t, "Listening along", embedValues(createBroadcastEmbed(current, "follower"))["This Server"])
+	assert.Equal(t, "Not listening along", embedValues(createBroadcastEmbed(current, "other"))["This Server"])
+}
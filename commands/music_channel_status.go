@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// maxChannelStatusLength is the longest voice channel status Discord accepts
+const maxChannelStatusLength = 500
+
+// channelStatus is a status set on a voice channel
+type channelStatus struct {
+	channelID string
+	text      string
+}
+
+// ChannelStatusBoard shows the current track as the status of the voice channel the bot plays in, in
+// guilds that turned it on with /music settings, and clears it when playback stops
+type ChannelStatusBoard struct {
+	mu       sync.Mutex
+	set      func(channelID, status string) error
+	statuses map[string]channelStatus // Status last set per guild
+}
+
+// ChannelStatus is the bot-wide voice channel status board, active once the music player is initialized
+var ChannelStatus = newChannelStatusBoard()
+
+func newChannelStatusBoard() *ChannelStatusBoard {
+	return &ChannelStatusBoard{statuses: make(map[string]channelStatus)}
+}
+
+// start sets the function that changes a voice channel's status, an empty status clears it
+func (b *ChannelStatusBoard) start(set func(channelID, status string) error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.set = set
+}
+
+// refresh sets the guild's channel status to its current track, or clears the status it set when
+// nothing plays, the bot moved or the guild turned the status off
+func (b *ChannelStatusBoard) refresh(guildID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.set == nil || SimplePlayer == nil {
+		return
+	}
+
+	var want channelStatus
+	if SimplePlayer.ChannelStatus(guildID) {
+		if player, connected := SimplePlayer.GetPlayer(guildID); connected {
+			if track := player.GetCurrent(); track != nil {
+				want = channelStatus{channelID: player.ChannelID(), text: formatChannelStatus(*track, player.IsPaused())}
+			}
+		}
+	}
+
+	last := b.statuses[guildID]
+	if want == last {
+		return
+	}
+	if last.channelID != "" && last.channelID != want.channelID {
+		if err := b.set(last.channelID, ""); err != nil {
+			utils.LogWarn("Failed to clear the voice channel status in guild %s: %v", guildID, err)
+		}
+		delete(b.statuses, guildID)
+	}
+	if want.channelID == "" {
+		return
+	}
+	if err := b.set(want.channelID, want.text); err != nil {
+		utils.LogWarn("Failed to set the voice channel status in guild %s, the bot may lack the Set Voice Channel Status permission: %v", guildID, err)
+		return
+	}
+	b.statuses[guildID] = want
+}
+
+// formatChannelStatus describes the current track in a voice channel status
+func formatChannelStatus(track types.AudioSource, paused bool) string {
+	icon := "🎵"
+	switch {
+	case paused:
+		icon = "⏸️"
+	case track.Live:
+		icon = "🔴"
+	}
+
+	status := icon + " " + track.Title
+	if runes := []rune(status); len(runes) > maxChannelStatusLength {
+		status = string(runes[:maxChannelStatusLength-1]) + "…"
+	}
+	return status
+}
+
+// setVoiceChannelStatus returns a function that sets a voice channel's status through Discord's
+// voice-status endpoint, which discordgo has no method for
+func setVoiceChannelStatus(session *discordgo.Session) func(channelID, status string) error {
+	if session == nil {
+		return nil
+	}
+	return func(channelID, status string) error {
+		endpoint := discordgo.EndpointChannel(channelID) + "/voice-status"
+		_, err := session.RequestWithBucketID(http.MethodPut, endpoint, map[string]string{"status": status}, endpoint)
+		return err
+	}
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/types"
+)
+
+func TestFormatChannelStatus(t *testing.T) {
+	track := types.AudioSource{Title: "Never Gonna Give You Up"}
+	assert.Equal(t, "🎵 Never Gonna Give You Up", formatChannelStatus(track, false))
+	assert.Equal(t, "⏸️ Never Gonna Give You Up", formatChannelStatus(track, true))
+	assert.Equal(t, "🔴 Lofi Radio", formatChannelStatus(types.AudioSource{Title: "Lofi Radio", Live: true}, false))
+
+	long := formatChannelStatus(types.AudioSource{Title: strings.Repeat("ä", 600)}, false)
+	assert.Equal(t, maxChannelStatusLength, utf8.RuneCountInString(long))
+	assert.True(t, strings.HasSuffix(long, "…"))
+}
+
+func TestChannelStatusBoardClearsWhenPlaybackEnds(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+	require.NoError(t, SimplePlayer.SetChannelStatus("guild_1", true))
+
+	type call struct{ channelID, status string }
+	var calls []call
+	board := newChannelStatusBoard()
+	board.refresh("guild_1")
+	board.start(func(channelID, status string) error {
+		calls = append(calls, call{channelID, status})
+		return nil
+	})
+
+	// The bot left the channel it had set a status on
+	board.statuses["guild_1"] = channelStatus{channelID: "voice_1", text: "🎵 Song"}
+	board.refresh("guild_1")
+	assert.Equal(t, []call{{"voice_1", ""}}, calls)
+	assert.Empty(t, board.statuses)
+
+	// Nothing was set, nothing to clear
+	board.refresh("guild_1")
+	assert.Len(t, calls, 1)
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/utils"
+)
+
+// noYtdlpServiceMessage answers /music circuit when extractions only run the yt-dlp binary
+const noYtdlpServiceMessage = "ℹ️ Extractions don't go through a yt-dlp service, so there is no circuit breaker. Set `YTDLP_SERVICE_URL` and `YTDLP_SERVICE_EXTRACT` to use one."
+
+// handleMusicCircuit shows the yt-dlp service's circuit breaker or closes it. The service is shared by
+// every server, so only the bot owner can use it.
+func handleMusicCircuit(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if !IsBotOwner(getInteractionUserID(i)) {
+		return respondWithEphemeral(s, i, translate(i, "circuit.owner_only"))
+	}
+	if SimplePlayer == nil {
+		return respondWithEphemeral(s, i, translate(i, "music.unavailable"))
+	}
+
+	options := i.ApplicationCommandData().Options[0].Options
+	if len(options) == 0 {
+		return respondWithEphemeral(s, i, translate(i, "circuit.unknown"))
+	}
+
+	switch options[0].Name {
+	case "status":
+		metrics, ok := SimplePlayer.YtdlpCircuit()
+		if !ok {
+			return respondWithEphemeral(s, i, noYtdlpServiceMessage)
+		}
+		data := embedResponse(i, createCircuitEmbed(metrics))
+		data.Flags = discordgo.MessageFlagsEphemeral
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: data,
+		})
+	case "reset":
+		previous, ok := SimplePlayer.ResetYtdlpCircuit()
+		if !ok {
+			return respondWithEphemeral(s, i, noYtdlpServiceMessage)
+		}
+		utils.LogInfoContext(RequestContext(i), "yt-dlp circuit breaker reset by %s (was %s)", getInteractionUserID(i), previous)
+		if previous == ytdlp.StateClosed {
+			return respondWithEphemeral(s, i, translate(i, "circuit.already_closed"))
+		}
+		return respondWithEphemeral(s, i, translate(i, "circuit.closed", previous))
+	default:
+		return respondWithEphemeral(s, i, translate(i, "circuit.unknown"))
+	}
+}
+
+// createCircuitEmbed builds the /music circuit status embed
+func createCircuitEmbed(metrics ytdlp.CircuitBreakerMetrics) *discordgo.MessageEmbed {
+	embed := SuccessEmbed("🔌 yt-dlp Circuit Breaker").Description("✅ Closed, extractions go to the yt-dlp service")
+	switch metrics.State {
+	case ytdlp.StateOpen:
+		embed.Style(StyleError).Descriptionf("⛔ Open, extractions run the yt-dlp binary. The service is tried again <t:%d:R>.", metrics.NextAttempt.Unix())
+	case ytdlp.StateHalfOpen:
+		embed.Style(StyleWarning).Descriptionf("⚠️ Half-open, trying the service again (%d successful so far)", metrics.Successes)
+	}
+
+	lastFailure := "None yet"
+	if !metrics.LastFailure.IsZero() {
+		lastFailure = fmt.Sprintf("<t:%d:R>", metrics.LastFailure.Unix())
+	}
+	return embed.
+		InlineField("Failures in a Row", fmt.Sprintf("%d", metrics.Failures)).
+		InlineField("Times Opened", fmt.Sprintf("%d", metrics.Opened)).
+		InlineField("Requests Refused", fmt.Sprintf("%d", metrics.Rejected)).
+		InlineField("In Flight", fmt.Sprintf("%d", metrics.ActiveRequests)).
+		InlineField("Last Failure", lastFailure).
+		Footer("Close it by hand with /music circuit reset").
+		Build()
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestHandleMusicCircuit(t *testing.T) {
+	SetBotOwners([]string{"owner_id"})
+	defer SetBotOwners(nil)
+	originalPlayer := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = originalPlayer }()
+
+	tests := []struct {
+		userID     string
+		subcommand string
+		want       string
+	}{
+		{"member", "status", "❌ Only the bot owner can manage the yt-dlp circuit breaker"},
+		{"owner_id", "status", noYtdlpServiceMessage},
+		{"owner_id", "reset", noYtdlpServiceMessage},
+	}
+	for _, tt := range tests {
+		interaction := newMusicInteraction("circuit", &discordgo.ApplicationCommandInteractionDataOption{
+			Name: tt.subcommand,
+			Type: discordgo.ApplicationCommandOptionSubCommand,
+		})
+		interaction.Member = &discordgo.Member{User: &discordgo.User{ID: tt.userID}}
+		mockSession := &testutils.MockSession{}
+		require.NoError(t, HandleMusicCommand(mockSession, interaction))
+		assert.Equal(t, tt.want, mockSession.RespondData.Content, tt.userID+" "+tt.subcommand)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+	}
+}
+
+func TestCreateCircuitEmbed(t *testing.T) {
+	closed := createCircuitEmbed(ytdlp.CircuitBreakerMetrics{State: ytdlp.StateClosed})
+	assert.Contains(t, closed.Description, "Closed")
+	assert.Equal(t, "None yet", embedValues(closed)["Last Failure"])
+
+	retryAt := time.Unix(1700000060, 0)
+	open := createCircuitEmbed(ytdlp.CircuitBreakerMetrics{
+		State:       ytdlp.StateOpen,
+		Failures:    5,
+		Opened:      2,
+		Rejected:    14,
+		LastFailure: time.Unix(1700000000, 0),
+		NextAttempt: retryAt,
+	})
+	assert.Equal(t, 0xe74c3c, open.Color)
+	assert.Contains(t, open.Description, "<t:1700000060:R>")
+	values := embedValues(open)
+	assert.Equal(t, "5", values["Failures in a Row"])
+	assert.Equal(t, "2", values["Times Opened"])
+	assert.Equal(t, "14", values["Requests Refused"])
+	assert.Equal(t, "<t:1700000000:R>", values["Last Failure"])
+}
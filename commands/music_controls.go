@@ -1,99 +1,256 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/queue"
+	"pxnx-discord-bot/music/types"
 )
 
 // HandleStopCommand handles the /stop command using the simplified approach
 func HandleStopCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
 	if SimplePlayer == nil {
-		return respondWithInteraction(s, i, "Music system is not available")
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
 	}
 
 	player, connected := SimplePlayer.GetPlayer(i.GuildID)
 	if !connected {
-		return respondWithInteraction(s, i, "Not connected to a voice channel")
+		return respondWithInteraction(s, i, translate(i, "music.not_connected"))
 	}
 
 	player.Stop()
-	return respondWithInteraction(s, i, "⏹️ Stopped playback and cleared queue")
+	return respondWithInteraction(s, i, translate(i, "music.stopped"))
 }
 
-// HandleSkipCommand handles the /skip command using the simplified approach
+// HandleSkipCommand handles the /skip and /music skip commands
 func HandleSkipCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
 	if SimplePlayer == nil {
-		return respondWithInteraction(s, i, "Music system is not available")
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
 	}
 
 	player, connected := SimplePlayer.GetPlayer(i.GuildID)
 	if !connected {
-		return respondWithInteraction(s, i, "Not connected to a voice channel")
+		return respondWithInteraction(s, i, translate(i, "music.not_connected"))
 	}
 
 	if !player.IsPlaying() {
-		return respondWithInteraction(s, i, "Nothing is currently playing")
+		return respondWithInteraction(s, i, translate(i, "music.nothing_playing"))
 	}
 
 	player.Skip()
 
 	queue := player.GetQueue()
 	if len(queue) > 0 {
-		return respondWithInteraction(s, i, fmt.Sprintf("⏭️ Skipped! Playing next track... (%d songs remaining)", len(queue)))
+		return respondWithInteraction(s, i, translate(i, "music.skipped", len(queue)))
 	} else {
-		return respondWithInteraction(s, i, "⏭️ Skipped! No more songs in queue")
+		return respondWithInteraction(s, i, translate(i, "music.skipped_last"))
 	}
 }
 
-// HandleQueueCommand handles the /queue command using the simplified approach
+// HandleQueueCommand handles /music queue and its subcommands
 func HandleQueueCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
 	if SimplePlayer == nil {
-		return respondWithInteraction(s, i, "Music system is not available")
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
 	}
 
 	player, connected := SimplePlayer.GetPlayer(i.GuildID)
 	if !connected {
-		return respondWithInteraction(s, i, "Not connected to a voice channel")
+		return respondWithInteraction(s, i, translate(i, "music.not_connected"))
 	}
 
-	current := player.GetCurrent()
-	queue := player.GetQueue()
+	subcommand := "show"
+	if options := i.ApplicationCommandData().Options; len(options) > 0 {
+		subcommand = options[0].Name
+	}
+
+	switch subcommand {
+	case "undo":
+		return handleQueueUndo(s, i, player)
+	case "shuffle":
+		return handleQueueShuffle(s, i, player)
+	case "unshuffle":
+		return handleQueueUnshuffle(s, i, player)
+	case "dedupe":
+		return handleQueueDedupe(s, i, player)
+	case "remove-user":
+		return handleQueueRemoveUser(s, i, player)
+	default:
+		return handleQueueShow(s, i, player)
+	}
+}
+
+// handleQueueShow lists the current track and the upcoming queue
+func handleQueueShow(s SessionInterface, i *discordgo.InteractionCreate, player *music.VoicePlayer) error {
+	embed, components := renderQueuePage(player, 1)
+	data := embedResponse(i, embed)
+	data.Components = components
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+}
 
-	embed := &discordgo.MessageEmbed{
-		Title: "🎵 Music Queue",
-		Color: 0x3498db,
+// handleQueueUndo reverts the most recent change to the queue
+func handleQueueUndo(s SessionInterface, i *discordgo.InteractionCreate, player *music.VoicePlayer) error {
+	operation, err := player.UndoQueue()
+	if errors.Is(err, queue.ErrNothingToUndo) {
+		return respondWithInteraction(s, i, translate(i, "queue.nothing_to_undo"))
+	}
+	if err != nil {
+		return respondWithInteraction(s, i, translate(i, "queue.undo_failed", err))
 	}
 
-	if current != nil {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   "Now Playing",
-			Value:  fmt.Sprintf("🎶 **%s**", current.Title),
-			Inline: false,
-		})
+	return respondWithInteraction(s, i, translate(i, "queue.undone", operation, len(player.GetQueue())))
+}
+
+// handleQueueShuffle shuffles the queue, using the seed option when given so an order can be reproduced
+func handleQueueShuffle(s SessionInterface, i *discordgo.InteractionCreate, player *music.VoicePlayer) error {
+	size := len(player.GetQueue())
+	if size < 2 {
+		return respondWithInteraction(s, i, translate(i, "music.too_few_to_shuffle"))
 	}
 
-	if len(queue) == 0 {
-		embed.Description = "Queue is empty"
+	var seed uint64
+	seeded := false
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		if option.Name == "seed" {
+			seed = uint64(option.IntValue())
+			seeded = true
+		}
+	}
+
+	if seeded {
+		player.ShuffleQueueWithSeed(seed)
 	} else {
-		queueText := ""
-		for i, track := range queue {
-			if i >= 10 { // Limit to 10 tracks
-				queueText += fmt.Sprintf("... and %d more tracks\n", len(queue)-10)
-				break
-			}
-			queueText += fmt.Sprintf("%d. **%s**\n", i+1, track.Title)
+		seed = player.ShuffleQueue()
+	}
+
+	return respondWithInteraction(s, i, translate(i, "queue.shuffled", size, seed))
+}
+
+// handleQueueUnshuffle restores the order songs were added in
+func handleQueueUnshuffle(s SessionInterface, i *discordgo.InteractionCreate, player *music.VoicePlayer) error {
+	err := player.UnshuffleQueue()
+	if errors.Is(err, queue.ErrNotShuffled) {
+		return respondWithInteraction(s, i, translate(i, "queue.not_shuffled"))
+	}
+	if err != nil {
+		return respondWithInteraction(s, i, translate(i, "queue.unshuffle_failed", err))
+	}
+
+	return respondWithInteraction(s, i, translate(i, "queue.unshuffled", len(player.GetQueue())))
+}
+
+// queueModeratorPermissions are the permissions that allow removing other members' songs in bulk
+const queueModeratorPermissions = discordgo.PermissionManageMessages | discordgo.PermissionAdministrator
+
+// isQueueModerator reports whether the member behind an interaction may clean up the queue
+func isQueueModerator(i *discordgo.InteractionCreate) bool {
+	if i.Member != nil && i.Member.Permissions&queueModeratorPermissions != 0 {
+		return true
+	}
+	if hasDJRole(i) {
+		return true
+	}
+	return IsBotOwner(getInteractionUserID(i))
+}
+
+// handleQueueDedupe removes repeated copies of queued songs, keeping the one that plays first
+func handleQueueDedupe(s SessionInterface, i *discordgo.InteractionCreate, player *music.VoicePlayer) error {
+	if !isQueueModerator(i) {
+		return respondWithEphemeral(s, i, translate(i, "queue.cleanup_denied"))
+	}
+
+	removed := player.DeduplicateQueue()
+	if removed == 0 {
+		return respondWithInteraction(s, i, translate(i, "queue.no_duplicates"))
+	}
+	return respondWithInteraction(s, i, translate(i, "queue.deduped", removed, len(player.GetQueue())))
+}
+
+// handleQueueRemoveUser removes every song queued by the user in the user option
+func handleQueueRemoveUser(s SessionInterface, i *discordgo.InteractionCreate, player *music.VoicePlayer) error {
+	if !isQueueModerator(i) {
+		return respondWithEphemeral(s, i, translate(i, "queue.cleanup_denied"))
+	}
+
+	var userID string
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		if option.Name == "user" {
+			userID, _ = option.Value.(string)
 		}
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:  fmt.Sprintf("Up Next (%d songs)", len(queue)),
-			Value: queueText,
-		})
+	}
+	if userID == "" {
+		return respondWithEphemeral(s, i, translate(i, "queue.choose_user"))
 	}
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
+	// Name the user without mentioning them
+	name := userID
+	if resolved := i.ApplicationCommandData().Resolved; resolved != nil {
+		if user, ok := resolved.Users[userID]; ok {
+			name = user.Username
+		}
+	}
+
+	removed := player.RemoveQueuedBy(userID)
+	if removed == 0 {
+		return respondWithInteraction(s, i, translate(i, "queue.user_has_none", name))
+	}
+	return respondWithInteraction(s, i, translate(i, "queue.user_removed", removed, name, len(player.GetQueue())))
+}
+
+// HandleClearCommand handles the /music queue clear command, previewing the tracks to remove before confirmation
+func HandleClearCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+	if !isQueueModerator(i) {
+		return respondWithEphemeral(s, i, translate(i, "queue.cleanup_denied"))
+	}
+
+	player, connected := SimplePlayer.GetPlayer(i.GuildID)
+	if !connected {
+		return respondWithInteraction(s, i, translate(i, "music.not_connected"))
+	}
+
+	queue := player.GetQueue()
+	if len(queue) == 0 {
+		return respondWithInteraction(s, i, translate(i, "queue.already_empty"))
+	}
+
+	dryRun := false
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "dry_run" {
+			dryRun = option.BoolValue()
+		}
+	}
+
+	return requestConfirmation(s, i, createClearPreviewEmbed(i, queue), dryRun, func() (string, error) {
+		// Only clear the tracks the preview showed, songs queued or moved since need a new confirmation
+		if !player.ClearQueueIfUnchanged(queue) {
+			return translate(i, "queue.clear_changed"), nil
+		}
+		return translate(i, "queue.cleared", len(queue)), nil
 	})
+}
+
+// createClearPreviewEmbed lists the tracks that a queue clear would remove
+func createClearPreviewEmbed(i *discordgo.InteractionCreate, queue []types.AudioSource) *discordgo.MessageEmbed {
+	preview := ""
+	for index, track := range queue {
+		if index >= 10 { // Limit to 10 tracks
+			preview += translate(i, "track.more", len(queue)-10) + "\n"
+			break
+		}
+		preview += fmt.Sprintf("%d. **%s**\n", index+1, track.Title)
+	}
+
+	return WarningEmbed(translate(i, "queue.clear_title")).
+		Description(translate(i, "queue.clear_description", len(queue))).
+		Field(translate(i, "queue.clear_tracks"), preview).
+		Build()
 }
\ No newline at end of file
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+
+	"pxnx-discord-bot/testutils"
+)
+
+func TestIsQueueModerator(t *testing.T) {
+	interactionWith := func(permissions int64) *discordgo.InteractionCreate {
+		interaction := testutils.CreateTestInteraction("queue", nil)
+		interaction.Member = &discordgo.Member{
+			User:        &discordgo.User{ID: "member_1"},
+			Permissions: permissions,
+		}
+		return interaction
+	}
+
+	assert.True(t, isQueueModerator(interactionWith(discordgo.PermissionManageMessages)))
+	assert.True(t, isQueueModerator(interactionWith(discordgo.PermissionAdministrator)))
+	assert.False(t, isQueueModerator(interactionWith(discordgo.PermissionSendMessages|discordgo.PermissionVoiceConnect)))
+	assert.False(t, isQueueModerator(testutils.CreateTestInteraction("queue", nil)))
+
+	SetBotOwners([]string{"member_1"})
+	defer SetBotOwners(nil)
+	assert.True(t, isQueueModerator(interactionWith(0)), "bot owners may always clean up")
+}
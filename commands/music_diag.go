@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/utils"
+)
+
+// YtdlpMetrics polls the yt-dlp service the bot is pointed at, nil when no service is configured
+var YtdlpMetrics *ytdlp.MetricsPoller
+
+// YtdlpFailover resolves and searches through the yt-dlp service when YTDLP_SERVICE_EXTRACT is set,
+// nil when extractions only run the yt-dlp binary
+var YtdlpFailover *ytdlp.FailoverProvider
+
+// ytdlpServiceConfig returns the config of the yt-dlp service at YTDLP_SERVICE_URL, such as
+// http://ytdlp:8080, or nil when the variable is unset or invalid
+func ytdlpServiceConfig() *ytdlp.ServiceConfig {
+	raw := strings.TrimSpace(os.Getenv("YTDLP_SERVICE_URL"))
+	if raw == "" {
+		return nil
+	}
+	config := ytdlp.DefaultServiceConfig()
+	if err := config.UseURL(raw); err != nil {
+		utils.LogWarn("Ignoring YTDLP_SERVICE_URL: %v", err)
+		return nil
+	}
+	return config
+}
+
+// LoadYtdlpMetrics creates a poller for the yt-dlp service at YTDLP_SERVICE_URL. It returns nil when the
+// variable is unset or invalid.
+func LoadYtdlpMetrics() *ytdlp.MetricsPoller {
+	config := ytdlpServiceConfig()
+	if config == nil {
+		return nil
+	}
+	config.Timeout = 10 * time.Second
+	return ytdlp.NewMetricsPoller(ytdlp.NewClient(config))
+}
+
+// LoadYtdlpFailover sends the player's extractions and searches to the yt-dlp service at
+// YTDLP_SERVICE_URL when YTDLP_SERVICE_EXTRACT is true, falling back to the binary while the service is
+// unavailable. It returns nil otherwise.
+func LoadYtdlpFailover(player *music.SimplePlayer) *ytdlp.FailoverProvider {
+	raw := strings.TrimSpace(os.Getenv("YTDLP_SERVICE_EXTRACT"))
+	if raw == "" {
+		return nil
+	}
+	enabled, err := strconv.ParseBool(raw)
+	if err != nil {
+		utils.LogWarn("Ignoring invalid value %q for YTDLP_SERVICE_EXTRACT", raw)
+		return nil
+	}
+	if !enabled {
+		return nil
+	}
+	config := ytdlpServiceConfig()
+	if config == nil {
+		utils.LogWarn("YTDLP_SERVICE_EXTRACT needs YTDLP_SERVICE_URL, extractions run the yt-dlp binary")
+		return nil
+	}
+	return player.UseYtdlpService(config, ytdlpRetryConfig())
+}
+
+// ytdlpRetryConfig returns how requests to the yt-dlp service are retried, with the per-operation
+// budgets from YTDLP_RETRY_BUDGETS such as "extract=3:0.2,search=1"
+func ytdlpRetryConfig() *ytdlp.RetryConfig {
+	config := ytdlp.DefaultRetryConfig()
+	raw := strings.TrimSpace(os.Getenv("YTDLP_RETRY_BUDGETS"))
+	if raw == "" {
+		return config
+	}
+	budgets, err := ytdlp.ParseRetryBudgets(raw)
+	if err != nil {
+		utils.LogWarn("Ignoring YTDLP_RETRY_BUDGETS: %v", err)
+		return config
+	}
+	config.Budgets = budgets
+	return config
+}
+
+// handleMusicDiag shows the yt-dlp service's extraction counts, latencies, error rate and cache hit
+// ratio as of the last poll. The service is shared by every server, so only the bot owner sees them.
+func handleMusicDiag(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if !IsBotOwner(getInteractionUserID(i)) {
+		return respondWithEphemeral(s, i, translate(i, "diag.owner_only"))
+	}
+	if YtdlpMetrics == nil {
+		return respondWithEphemeral(s, i, translate(i, "diag.no_service"))
+	}
+
+	embed := createDiagEmbed(YtdlpMetrics.Snapshot())
+	if YtdlpFailover != nil {
+		embed.Fields = append(embed.Fields,
+			failoverField(YtdlpFailover.UsingService(), YtdlpFailover.CircuitState()),
+			retriesField(YtdlpFailover.RetryMetrics()))
+	}
+
+	return respondWithEphemeralEmbed(s, i, embed)
+}
+
+// createDiagEmbed builds the /music diag embed
+func createDiagEmbed(snapshot ytdlp.MetricsSnapshot) *discordgo.MessageEmbed {
+	embed := SuccessEmbed("🩺 yt-dlp Service")
+	if snapshot.Err != nil {
+		embed.Style(StyleError).Descriptionf("❌ Last poll failed: %v", snapshot.Err)
+	}
+	if snapshot.PolledAt.IsZero() {
+		if snapshot.Err == nil {
+			embed.Description("⏳ The service hasn't been polled yet")
+		}
+		return embed.Build()
+	}
+
+	metrics := snapshot.Metrics
+	return embed.
+		InlineField("Extractions", fmt.Sprintf("%d", metrics.Extractions)).
+		InlineField("Searches", fmt.Sprintf("%d", metrics.Searches)).
+		InlineField("Error Rate", formatRatio(metrics.ErrorRate(), metrics.Errors, metrics.Requests())).
+		InlineField("Cache Hit Ratio", formatRatio(metrics.CacheHitRatio(), metrics.CacheHits, metrics.CacheHits+metrics.CacheMisses)).
+		Field("Latency", fmt.Sprintf("avg %s · p95 %s · max %s", formatMillis(metrics.LatencyAvgMs), formatMillis(metrics.LatencyP95Ms), formatMillis(metrics.LatencyMaxMs))).
+		InlineField("Service Uptime", (time.Duration(metrics.UptimeSeconds)*time.Second).String()).
+		InlineField("Polled", fmt.Sprintf("<t:%d:R>", snapshot.PolledAt.Unix())).
+		Build()
+}
+
+// failoverField shows whether extractions go to the service or fell back to the binary
+func failoverField(usingService bool, circuit ytdlp.CircuitBreakerState) *discordgo.MessageEmbedField {
+	value := fmt.Sprintf("✅ The service (circuit %s)", circuit)
+	if !usingService {
+		value = fmt.Sprintf("⚠️ The yt-dlp binary, the service is unavailable (circuit %s)", circuit)
+	}
+	return &discordgo.MessageEmbedField{Name: "Extractions Run On", Value: value}
+}
+
+// retriesField shows how often each kind of request to the service was retried and how that went
+func retriesField(metrics map[ytdlp.Operation]ytdlp.RetryStats) *discordgo.MessageEmbedField {
+	var lines []string
+	for _, operation := range ytdlp.Operations {
+		stats := metrics[operation]
+		if stats.Requests == 0 {
+			continue
+		}
+		line := fmt.Sprintf("`%s` %d requests, %d retries (%d recovered)", operation, stats.Requests, stats.Retries, stats.Recovered)
+		if stats.Exhausted > 0 {
+			line += fmt.Sprintf(", %d gave up", stats.Exhausted)
+		}
+		if stats.Denied > 0 {
+			line += fmt.Sprintf(", %d over budget", stats.Denied)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No requests yet")
+	}
+	return &discordgo.MessageEmbedField{Name: "Retries", Value: strings.Join(lines, "\n")}
+}
+
+// formatRatio shows a share with the counts it came from, such as "25% (1 of 4)"
+func formatRatio(ratio float64, part, total int64) string {
+	if total == 0 {
+		return "No requests yet"
+	}
+	return fmt.Sprintf("%.0f%% (%d of %d)", ratio*100, part, total)
+}
+
+// formatMillis shows a latency in milliseconds, or seconds once it reaches one
+func formatMillis(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.1fs", ms/1000)
+	}
+	return fmt.Sprintf("%.0fms", ms)
+}
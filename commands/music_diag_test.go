@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestHandleMusicDiag(t *testing.T) {
+	SetBotOwners([]string{"owner_id"})
+	defer SetBotOwners(nil)
+	original := YtdlpMetrics
+	YtdlpMetrics = nil
+	defer func() { YtdlpMetrics = original }()
+
+	tests := []struct {
+		userID string
+		want   string
+	}{
+		{"member", "❌ Only the bot owner can see yt-dlp diagnostics"},
+		{"owner_id", "ℹ️ No yt-dlp service is configured, extractions run the yt-dlp binary. Set `YTDLP_SERVICE_URL` to monitor a service."},
+	}
+	for _, tt := range tests {
+		interaction := newMusicInteraction("diag")
+		interaction.Member = &discordgo.Member{User: &discordgo.User{ID: tt.userID}, Permissions: discordgo.PermissionAdministrator}
+		mockSession := &testutils.MockSession{}
+		require.NoError(t, HandleMusicCommand(mockSession, interaction))
+		assert.Equal(t, tt.want, mockSession.RespondData.Content, tt.userID)
+	}
+}
+
+func TestCreateDiagEmbed(t *testing.T) {
+	assert.Equal(t, "⏳ The service hasn't been polled yet", createDiagEmbed(ytdlp.MetricsSnapshot{}).Description)
+
+	snapshot := ytdlp.MetricsSnapshot{
+		Metrics: ytdlp.ServiceMetrics{
+			UptimeSeconds: 3600,
+			Extractions:   6,
+			Searches:      2,
+			Errors:        2,
+			CacheHits:     1,
+			CacheMisses:   3,
+			LatencyAvgMs:  850,
+			LatencyP95Ms:  2100,
+			LatencyMaxMs:  3050,
+		},
+		PolledAt: time.Unix(1700000000, 0),
+	}
+	values := embedValues(createDiagEmbed(snapshot))
+	assert.Equal(t, "25% (2 of 8)", values["Error Rate"])
+	assert.Equal(t, "25% (1 of 4)", values["Cache Hit Ratio"])
+	assert.Equal(t, "avg 850ms · p95 2.1s · max 3.0s", values["Latency"])
+	assert.Equal(t, "1h0m0s", values["Service Uptime"])
+
+	snapshot.Err = errors.New("connection refused")
+	embed := createDiagEmbed(snapshot)
+	assert.Equal(t, "❌ Last poll failed: connection refused", embed.Description)
+	assert.Equal(t, "6", embedValues(embed)["Extractions"], "the last metrics stay visible")
+}
+
+func TestLoadYtdlpMetrics(t *testing.T) {
+	t.Setenv("YTDLP_SERVICE_URL", "")
+	assert.Nil(t, LoadYtdlpMetrics())
+
+	t.Setenv("YTDLP_SERVICE_URL", "http://ytdlp")
+	assert.Nil(t, LoadYtdlpMetrics(), "a URL without a port is ignored")
+
+	t.Setenv("YTDLP_SERVICE_URL", "http://ytdlp:8080")
+	assert.NotNil(t, LoadYtdlpMetrics())
+}
+
+func TestLoadYtdlpFailover(t *testing.T) {
+	player := music.NewSimplePlayer(nil)
+
+	t.Setenv("YTDLP_SERVICE_URL", "http://ytdlp:8080")
+	t.Setenv("YTDLP_SERVICE_EXTRACT", "")
+	assert.Nil(t, LoadYtdlpFailover(player), "the service is only monitored by default")
+
+	t.Setenv("YTDLP_SERVICE_EXTRACT", "true")
+	failover := LoadYtdlpFailover(player)
+	require.NotNil(t, failover)
+	assert.True(t, failover.UsingService())
+
+	t.Setenv("YTDLP_SERVICE_URL", "")
+	assert.Nil(t, LoadYtdlpFailover(player), "extracting through the service needs its URL")
+}
+
+func TestFailoverField(t *testing.T) {
+	assert.Equal(t, "✅ The service (circuit closed)", failoverField(true, ytdlp.StateClosed).Value)
+	assert.Equal(t, "⚠️ The yt-dlp binary, the service is unavailable (circuit open)", failoverField(false, ytdlp.StateOpen).Value)
+}
+
+func TestRetriesField(t *testing.T) {
+	assert.Equal(t, "No requests yet", retriesField(nil).Value)
+
+	field := retriesField(map[ytdlp.Operation]ytdlp.RetryStats{
+		ytdlp.OperationSearch:  {Requests: 4},
+		ytdlp.OperationExtract: {Requests: 20, Retries: 5, Recovered: 3, Exhausted: 1, Denied: 2},
+	})
+	assert.Equal(t, "`extract` 20 requests, 5 retries (3 recovered), 1 gave up, 2 over budget\n`search` 4 requests, 0 retries (0 recovered)", field.Value)
+}
+
+func TestYtdlpRetryConfig(t *testing.T) {
+	t.Setenv("YTDLP_RETRY_BUDGETS", "extract=5:0.5")
+	assert.Equal(t, ytdlp.RetryBudget{MaxRetries: 5, Ratio: 0.5}, ytdlpRetryConfig().Budgets[ytdlp.OperationExtract])
+
+	t.Setenv("YTDLP_RETRY_BUDGETS", "download=1")
+	assert.Equal(t, ytdlp.DefaultRetryBudgets(), ytdlpRetryConfig().Budgets, "invalid budgets are ignored")
+}
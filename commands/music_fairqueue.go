@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// HandleFairQueueCommand handles the /music fairqueue command, toggling round-robin ordering by requester for the server
+func HandleFairQueueCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	enabled := false
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "mode" {
+			enabled = option.StringValue() == "on"
+		}
+	}
+
+	if err := SimplePlayer.SetFairQueue(i.GuildID, enabled); err != nil {
+		return respondWithInteraction(s, i, translate(i, "music.setting_not_saved", describeFairQueue(enabled)))
+	}
+	return respondWithInteraction(s, i, describeFairQueue(enabled))
+}
+
+// describeFairQueue explains the fair queue setting
+func describeFairQueue(enabled bool) string {
+	if enabled {
+		return "🔄 Fair queue is on, requests take turns so everyone gets a song in before anyone's next one"
+	}
+	return "🔄 Fair queue is off, songs play in the order they were requested"
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/testutils"
+)
+
+func TestDescribeFairQueue(t *testing.T) {
+	assert.Contains(t, describeFairQueue(true), "is on")
+	assert.Contains(t, describeFairQueue(false), "is off")
+}
+
+func TestFormatRequester(t *testing.T) {
+	assert.Equal(t, "<@123456789012345678>", formatRequester("123456789012345678"))
+	assert.Equal(t, "Auto-DJ", formatRequester("Auto-DJ"))
+	assert.Equal(t, "Unknown", formatRequester(""))
+}
+
+func TestHandleFairQueueCommandWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("fairqueue", nil)
+	require.NoError(t, HandleFairQueueCommand(mockSession, interaction))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
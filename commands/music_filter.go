@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/filters"
+	"pxnx-discord-bot/utils"
+)
+
+// HandleFilterCommand handles /music filters and its subcommands
+func HandleFilterCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	options := i.ApplicationCommandData().Options
+	subcommand := "show"
+	if len(options) > 0 {
+		subcommand = options[0].Name
+	}
+
+	switch subcommand {
+	case "toggle":
+		preset := ""
+		for _, option := range options[0].Options {
+			if option.Name == "preset" {
+				preset = option.StringValue()
+			}
+		}
+
+		chain, enabled, err := SimplePlayer.ToggleFilter(i.GuildID, preset)
+		if err != nil {
+			return respondWithInteraction(s, i, fmt.Sprintf("❌ %v", err))
+		}
+
+		action := "Disabled"
+		if enabled {
+			action = "Enabled"
+		}
+		return respondWithInteraction(s, i, translate(i, "filters.toggled", action, preset, chain))
+	case "clear":
+		SimplePlayer.ClearFilters(i.GuildID)
+		return respondWithInteraction(s, i, translate(i, "filters.cleared"))
+	default:
+		return respondWithEmbed(s, i, createFiltersEmbed(SimplePlayer.Filters(i.GuildID)))
+	}
+}
+
+// createFiltersEmbed lists every preset and whether it is enabled
+func createFiltersEmbed(chain filters.Chain) *discordgo.MessageEmbed {
+	var lines strings.Builder
+	for _, preset := range filters.Presets {
+		marker := "▫️"
+		if chain.Has(preset.Name) {
+			marker = "✅"
+		}
+		fmt.Fprintf(&lines, "%s **%s** - %s\n", marker, preset.Name, preset.Description)
+	}
+
+	return NewEmbed("🎛️ Audio Filters").
+		Color(utils.ColorPurple).
+		Description(lines.String()).
+		Footer("Use /music filters toggle to switch a filter, changes apply to the current song right away").
+		Build()
+}
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/filters"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestCreateFiltersEmbed(t *testing.T) {
+	embed := createFiltersEmbed(filters.Chain{"nightcore"})
+
+	assert.Equal(t, "🎛️ Audio Filters", embed.Title)
+	assert.Contains(t, embed.Description, "✅ **nightcore**")
+	assert.Contains(t, embed.Description, "▫️ **bassboost**")
+	for _, preset := range filters.Presets {
+		assert.Contains(t, embed.Description, preset.Description)
+	}
+}
+
+func TestHandleFilterCommandWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleFilterCommand(mockSession, testutils.CreateTestInteraction("filter", nil)))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
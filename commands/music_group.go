@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// musicSettingsPermissions may change music settings through /music settings, like the Manage Server
+// permission /musicsettings was limited to
+const musicSettingsPermissions = discordgo.PermissionManageGuild | discordgo.PermissionAdministrator
+
+// soundcheckCooldown is how long a member waits between soundchecks, which each hold the voice connection
+const soundcheckCooldown = 30 * time.Second
+
+// musicServerHandlers are the /music subcommands that change how the server plays music, which need the
+// Manage Server permission
+var musicServerHandlers = map[string]func(SessionInterface, *discordgo.InteractionCreate) error{
+	"antirepeat": HandleAntiRepeatCommand,
+	"loudnorm":   HandleLoudnormCommand,
+	"fairqueue":  HandleFairQueueCommand,
+	"247":        Handle247Command,
+	"autodj":     HandleAutoDJCommand,
+}
+
+// HandleMusicCommand handles the /music command group. Subcommands that replaced a standalone command
+// run its handler with the subcommand's options.
+func HandleMusicCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return respondWithEphemeral(s, i, translate(i, "music.unknown"))
+	}
+
+	switch options[0].Name {
+	case "play":
+		return HandlePlayCommand(s, musicAlias(i, "play"))
+	case "skip":
+		return HandleSkipCommand(s, musicAlias(i, "skip"))
+	case "join":
+		return HandleJoinCommand(s, musicAlias(i, "join"))
+	case "leave":
+		return HandleLeaveCommand(s, musicAlias(i, "leave"))
+	case "queue":
+		return handleMusicQueue(s, musicAlias(i, "queue"))
+	case "volume":
+		return handleMusicVolume(s, i)
+	case "filters":
+		return HandleFilterCommand(s, musicAlias(i, "filter"))
+	case "history":
+		return HandleHistoryCommand(s, musicAlias(i, "history"))
+	case "replay":
+		return HandleReplayCommand(s, musicAlias(i, "replay"))
+	case "radio":
+		return HandleRadioCommand(s, musicAlias(i, "radio"))
+	case "soundcheck":
+		if ok, err := CheckCooldown(s, musicAlias(i, "music soundcheck"), soundcheckCooldown); !ok {
+			return err
+		}
+		return HandleSoundcheckCommand(s, musicAlias(i, "soundcheck"))
+	case "stats":
+		return HandleMusicStatsCommand(s, musicAlias(i, "musicstats"))
+	case "broadcast":
+		return handleMusicBroadcast(s, i)
+	case "diag":
+		return handleMusicDiag(s, i)
+	case "circuit":
+		return handleMusicCircuit(s, i)
+	case "settings":
+		// Everyone can look at the settings, changing them needs Manage Server
+		if len(options[0].Options) > 0 && !canChangeMusicSettings(i) {
+			return respondWithEphemeral(s, i, translate(i, "music.settings_need_manage_server"))
+		}
+		return HandleMusicSettingsCommand(s, musicAlias(i, "musicsettings"))
+	default:
+		name := options[0].Name
+		handler, found := musicServerHandlers[name]
+		if !found {
+			return respondWithEphemeral(s, i, translate(i, "music.unknown"))
+		}
+		// Refusals name the command members typed, /music 247 rather than /247
+		if ok, err := CheckMemberPermissions(s, musicAlias(i, "music "+name), discordgo.PermissionManageGuild); !ok {
+			return err
+		}
+		return handler(s, musicAlias(i, name))
+	}
+}
+
+// handleMusicQueue runs /music queue clear, move and swap with the handlers of the standalone commands
+// they replaced, and the other queue subcommands with HandleQueueCommand
+func handleMusicQueue(s SessionInterface, i *discordgo.InteractionCreate) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return HandleQueueCommand(s, i)
+	}
+
+	switch options[0].Name {
+	case "clear":
+		return HandleClearCommand(s, musicAlias(i, "clear"))
+	case "move":
+		return HandleMoveCommand(s, musicAlias(i, "move"))
+	case "swap":
+		return HandleSwapCommand(s, musicAlias(i, "swap"))
+	default:
+		return HandleQueueCommand(s, i)
+	}
+}
+
+// musicAlias rewrites /music <subcommand> as the standalone command name with the subcommand's options,
+// which is what the handlers read. Subcommand groups keep their subcommands, so /music queue show reads
+// like /queue show.
+func musicAlias(i *discordgo.InteractionCreate, name string) *discordgo.InteractionCreate {
+	data := i.ApplicationCommandData()
+	subcommand := data.Options[0]
+	data.Name = name
+	data.Options = subcommand.Options
+
+	interaction := *i.Interaction
+	interaction.Data = data
+	return &discordgo.InteractionCreate{Interaction: &interaction}
+}
+
+// canChangeMusicSettings reports whether the member may change the server's music settings
+func canChangeMusicSettings(i *discordgo.InteractionCreate) bool {
+	if i.Member != nil && i.Member.Permissions&musicSettingsPermissions != 0 {
+		return true
+	}
+	return IsBotOwner(getInteractionUserID(i))
+}
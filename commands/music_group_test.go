@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/testutils"
+)
+
+// newMusicInteraction builds /music <subcommand> with the subcommand's options
+func newMusicInteraction(subcommand string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return testutils.CreateTestInteraction("music", []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: subcommand, Type: discordgo.ApplicationCommandOptionSubCommand, Options: options},
+	})
+}
+
+func TestMusicAlias(t *testing.T) {
+	interaction := testutils.CreateTestInteraction("music", []*discordgo.ApplicationCommandInteractionDataOption{{
+		Name: "queue",
+		Type: discordgo.ApplicationCommandOptionSubCommandGroup,
+		Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "show", Type: discordgo.ApplicationCommandOptionSubCommand},
+		},
+	}})
+
+	alias := musicAlias(interaction, "queue")
+	data := alias.ApplicationCommandData()
+	assert.Equal(t, "queue", data.Name)
+	require.Len(t, data.Options, 1)
+	assert.Equal(t, "show", data.Options[0].Name)
+	assert.Equal(t, interaction.Token, alias.Token, "the response goes to the same interaction")
+	assert.Equal(t, "music", interaction.ApplicationCommandData().Name, "the original interaction is unchanged")
+}
+
+func TestHandleMusicCommandWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	for _, subcommand := range []string{"skip", "volume", "settings"} {
+		mockSession := &testutils.MockSession{}
+		require.NoError(t, HandleMusicCommand(mockSession, newMusicInteraction(subcommand)))
+		assert.Equal(t, "Music system is not available", mockSession.RespondData.Content, subcommand)
+	}
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, testutils.CreateTestInteraction("music", nil)))
+	assert.Equal(t, "❌ Unknown music subcommand", mockSession.RespondData.Content)
+}
+
+func TestHandleMusicCommandAnswersInServerLanguage(t *testing.T) {
+	store := useGuildSettings(t)
+	_, err := store.Update("guild_id_123", func(g *settings.Guild) { g.Language = "de" })
+	require.NoError(t, err)
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, newMusicInteraction("skip")))
+	assert.Equal(t, "Das Musiksystem ist nicht verfügbar", mockSession.RespondData.Content)
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, testutils.CreateTestInteraction("music", nil)))
+	assert.Equal(t, "❌ Unbekannter Musikbefehl", mockSession.RespondData.Content)
+}
+
+func TestHandleMusicSettingsNeedsManageServer(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	interaction := newMusicInteraction("settings",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "idle_timeout", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(30)},
+	)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "member"}}
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, interaction))
+	assert.Equal(t, "❌ Changing music settings needs the Manage Server permission", mockSession.RespondData.Content)
+	_, idle := SimplePlayer.Timeouts(interaction.GuildID)
+	assert.Zero(t, idle)
+
+	interaction.Member.Permissions = discordgo.PermissionManageGuild
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, interaction))
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Equal(t, "Leave after 30m0s with nothing playing", embedValues(mockSession.RespondData.Embeds[0])["Idle Timeout"])
+
+	// Anyone can look at the settings
+	viewer := newMusicInteraction("settings")
+	viewer.Member = &discordgo.Member{User: &discordgo.User{ID: "member"}}
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, viewer))
+	assert.Len(t, mockSession.RespondData.Embeds, 1)
+}
+
+func TestHandleMusicVolume(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, newMusicInteraction("volume")))
+	assert.Equal(t, "🔊 Volume is 100%, songs play at their original volume", mockSession.RespondData.Content)
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, newMusicInteraction("volume",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "level", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(60)},
+	)))
+	assert.Equal(t, "🔉 Volume is 60%, songs play quieter than their original volume", mockSession.RespondData.Content)
+	assert.Equal(t, 60, SimplePlayer.Volume("guild_id_123"))
+}
+
+func TestHandleMusicServerSubcommandsNeedManageServer(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	interaction := newMusicInteraction("loudnorm",
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "mode", Type: discordgo.ApplicationCommandOptionString, Value: "on"},
+	)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "member"}}
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, interaction))
+	assert.Equal(t, "❌ You need the Manage Server permission to use /music loudnorm", mockSession.RespondData.Content)
+	assert.False(t, SimplePlayer.Loudnorm(interaction.GuildID))
+
+	interaction.Member.Permissions = discordgo.PermissionManageGuild
+	require.NoError(t, HandleMusicCommand(&testutils.MockSession{}, interaction))
+	assert.True(t, SimplePlayer.Loudnorm(interaction.GuildID))
+}
+
+func TestHandleMusicSoundcheckCooldown(t *testing.T) {
+	original := commandCooldowns
+	commandCooldowns = newCooldowns()
+	defer func() { commandCooldowns = original }()
+
+	originalPlayer := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = originalPlayer }()
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, newMusicInteraction("soundcheck")))
+	assert.Equal(t, "❌ Music system is not available", *mockSession.InteractionResponseEditData.Content)
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleMusicCommand(mockSession, newMusicInteraction("soundcheck")))
+	assert.Equal(t, "⏳ Wait 30 more seconds before using /music soundcheck again", mockSession.RespondData.Content)
+}
+
+func TestHandleMusicQueueReorder(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	for _, subcommand := range []string{"clear", "move", "swap"} {
+		interaction := testutils.CreateTestInteraction("music", []*discordgo.ApplicationCommandInteractionDataOption{{
+			Name:    "queue",
+			Type:    discordgo.ApplicationCommandOptionSubCommandGroup,
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{{Name: subcommand, Type: discordgo.ApplicationCommandOptionSubCommand}},
+		}})
+		mockSession := &testutils.MockSession{}
+		require.NoError(t, HandleMusicCommand(mockSession, interaction))
+		assert.Contains(t, mockSession.RespondData.Content, "not available", subcommand)
+	}
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/history"
+)
+
+// HandleHistoryCommand handles the /music history command, listing recently played tracks
+func HandleHistoryCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	player, connected := SimplePlayer.GetPlayer(i.GuildID)
+	if !connected {
+		return respondWithInteraction(s, i, translate(i, "music.not_connected"))
+	}
+
+	return respondWithEmbed(s, i, createHistoryEmbed(player.GetHistory(), time.Now()))
+}
+
+// HandleReplayCommand handles the /music replay command, re-enqueueing a track from the history
+func HandleReplayCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	n := 1
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "n" {
+			n = int(option.IntValue())
+		}
+	}
+
+	if _, connected := SimplePlayer.GetPlayer(i.GuildID); !connected {
+		return respondWithInteraction(s, i, translate(i, "music.not_connected"))
+	}
+
+	track, err := SimplePlayer.Replay(i.GuildID, n, trackRequest(i))
+	if err != nil {
+		return respondWithInteraction(s, i, translate(i, "history.replay_failed", n, err))
+	}
+
+	return respondWithInteraction(s, i, translate(i, "history.replayed", track.Title))
+}
+
+// createHistoryEmbed lists history entries, numbered the way /music replay expects
+func createHistoryEmbed(entries []history.Entry, now time.Time) *discordgo.MessageEmbed {
+	embed := NewEmbed("🕘 Recently Played")
+
+	if len(entries) == 0 {
+		return embed.Description("Nothing has been played yet").Build()
+	}
+
+	var lines strings.Builder
+	for n, entry := range entries {
+		fmt.Fprintf(&lines, "%d. **%s**", n+1, entry.Track.Title)
+		if entry.Track.Duration.Known() {
+			fmt.Fprintf(&lines, " (%s)", entry.Track.Duration)
+		}
+		fmt.Fprintf(&lines, " - %s\n", formatAgo(now.Sub(entry.PlayedAt)))
+	}
+
+	return embed.Description(lines.String()).Footer("Use /music replay <n> to queue an entry again").Build()
+}
+
+// formatAgo renders an elapsed duration at minute precision
+func formatAgo(elapsed time.Duration) string {
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	default:
+		return fmt.Sprintf("%dh %dm ago", int(elapsed.Hours()), int(elapsed.Minutes())%60)
+	}
+}
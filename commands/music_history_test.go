@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/history"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestCreateHistoryEmbed(t *testing.T) {
+	now := time.Now()
+	entries := []history.Entry{
+		{Track: types.AudioSource{Title: "Latest Song", Duration: types.Seconds(210)}, PlayedAt: now.Add(-30 * time.Second)},
+		{Track: types.AudioSource{Title: "Older Song"}, PlayedAt: now.Add(-90 * time.Minute)},
+	}
+
+	embed := createHistoryEmbed(entries, now)
+
+	assert.Equal(t, "🕘 Recently Played", embed.Title)
+	assert.Contains(t, embed.Description, "1. **Latest Song** (3:30) - just now")
+	assert.Contains(t, embed.Description, "2. **Older Song** - 1h 30m ago")
+	require.NotNil(t, embed.Footer)
+	assert.Contains(t, embed.Footer.Text, "/music replay")
+}
+
+func TestCreateHistoryEmbedEmpty(t *testing.T) {
+	embed := createHistoryEmbed(nil, time.Now())
+	assert.Equal(t, "Nothing has been played yet", embed.Description)
+	assert.Nil(t, embed.Footer)
+}
+
+func TestFormatAgo(t *testing.T) {
+	assert.Equal(t, "just now", formatAgo(10*time.Second))
+	assert.Equal(t, "5m ago", formatAgo(5*time.Minute))
+	assert.Equal(t, "2h 0m ago", formatAgo(2*time.Hour))
+}
+
+func TestHistoryCommandsWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleHistoryCommand(mockSession, testutils.CreateTestInteraction("history", nil)))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+
+	mockSession.Reset()
+	require.NoError(t, HandleReplayCommand(mockSession, testutils.CreateTestInteraction("replay", nil)))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
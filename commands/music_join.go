@@ -1,17 +1,15 @@
 package commands
 
 import (
-	"fmt"
-
 	"github.com/bwmarrin/discordgo"
 	"pxnx-discord-bot/utils"
 )
 
-// HandleJoinCommand handles the /join command using the simplified approach
+// HandleJoinCommand handles the /music join command using the simplified approach
 func HandleJoinCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
 	// Check if simple player is initialized
 	if SimplePlayer == nil {
-		return respondWithInteraction(s, i, "Music system is not available")
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
 	}
 
 	// Get the user's voice state from session state (more reliable than API call)
@@ -43,7 +41,7 @@ func HandleJoinCommand(s SessionInterface, i *discordgo.InteractionCreate) error
 		guild, err := s.Guild(i.GuildID)
 		if err != nil {
 			utils.LogDebug("Failed to get guild via API: %v", err)
-			return respondWithInteraction(s, i, "Failed to get server information")
+			return respondWithInteraction(s, i, translate(i, "join.no_server"))
 		}
 
 		utils.LogDebug("Found %d voice states in API response", len(guild.VoiceStates))
@@ -59,13 +57,13 @@ func HandleJoinCommand(s SessionInterface, i *discordgo.InteractionCreate) error
 	}
 
 	if userChannelID == "" {
-		return respondWithInteraction(s, i, "You need to be in a voice channel first!")
+		return respondWithInteraction(s, i, translate(i, "join.member_not_in_voice"))
 	}
 
 	// Join the voice channel
 	err := SimplePlayer.JoinChannel(i.GuildID, userChannelID)
 	if err != nil {
-		return respondWithInteraction(s, i, fmt.Sprintf("Failed to join voice channel: %v", err))
+		return respondWithInteraction(s, i, translate(i, "join.failed", err))
 	}
 
 	// Get channel name for response
@@ -75,21 +73,29 @@ func HandleJoinCommand(s SessionInterface, i *discordgo.InteractionCreate) error
 		channelName = channel.Name
 	}
 
-	return respondWithInteraction(s, i, fmt.Sprintf("✅ Joined **%s**", channelName))
+	return respondWithInteraction(s, i, translate(i, "join.joined", channelName))
 }
 
-// HandleLeaveCommand handles the /leave command using the simplified approach
+// HandleLeaveCommand handles the /music leave command using the simplified approach
 func HandleLeaveCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
 	// Check if simple player is initialized
 	if SimplePlayer == nil {
-		return respondWithInteraction(s, i, "Music system is not available")
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
 	}
 
 	// Leave the voice channel
 	err := SimplePlayer.LeaveChannel(i.GuildID)
 	if err != nil {
-		return respondWithInteraction(s, i, fmt.Sprintf("Failed to leave voice channel: %v", err))
+		return respondWithInteraction(s, i, translate(i, "leave.failed", err))
+	}
+
+	// Asking the bot to leave ends 24/7 mode, otherwise it would come back after a restart
+	if SimplePlayer.StayConnected(i.GuildID) {
+		if err := SimplePlayer.SetStayConnected(i.GuildID, false); err != nil {
+			utils.LogWarn("Failed to turn off 24/7 mode in guild %s: %v", i.GuildID, err)
+		}
+		return respondWithInteraction(s, i, translate(i, "leave.left_247_off"))
 	}
 
-	return respondWithInteraction(s, i, "👋 Left voice channel and cleared queue")
+	return respondWithInteraction(s, i, translate(i, "leave.left"))
 }
\ No newline at end of file
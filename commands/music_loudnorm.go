@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// HandleLoudnormCommand handles the /music loudnorm command, toggling loudness normalization for the server
+func HandleLoudnormCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	enabled := false
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "mode" {
+			enabled = option.StringValue() == "on"
+		}
+	}
+
+	if err := SimplePlayer.SetLoudnorm(i.GuildID, enabled); err != nil {
+		return respondWithInteraction(s, i, translate(i, "music.setting_not_saved", describeLoudnorm(enabled)))
+	}
+	return respondWithInteraction(s, i, describeLoudnorm(enabled))
+}
+
+// describeLoudnorm explains the loudness normalization setting
+func describeLoudnorm(enabled bool) string {
+	if enabled {
+		return "🔊 Loudness normalization is on, every song plays at a similar volume"
+	}
+	return "🔊 Loudness normalization is off, songs play at their original volume"
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestDescribeLoudnorm(t *testing.T) {
+	assert.Contains(t, describeLoudnorm(true), "is on")
+	assert.Contains(t, describeLoudnorm(false), "is off")
+}
+
+func TestMusicSettingsPath(t *testing.T) {
+	t.Setenv("MUSIC_SETTINGS_FILE", "")
+	assert.Equal(t, settings.DefaultPath, MusicSettingsPath())
+
+	path := filepath.Join(t.TempDir(), "music.json")
+	t.Setenv("MUSIC_SETTINGS_FILE", path)
+	assert.Equal(t, path, MusicSettingsPath())
+}
+
+func TestHandleLoudnormCommandWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("loudnorm", nil)
+	require.NoError(t, HandleLoudnormCommand(mockSession, interaction))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
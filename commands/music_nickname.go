@@ -0,0 +1,240 @@
+package commands
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
+)
+
+// nicknameInterval is the least time between nickname changes in a guild. Discord rate limits member
+// updates per guild, and a nickname changing faster than this is hard to read anyway.
+const nicknameInterval = 15 * time.Second
+
+// maxNicknameLength is the longest nickname Discord accepts
+const maxNicknameLength = 32
+
+// nicknameScrollStep is how many characters a long title moves on each scroll
+const nicknameScrollStep = 8
+
+// Prefixes of now playing nicknames, also how a nickname left from an earlier run is recognized
+const (
+	nicknamePlaying = "🎵 "
+	nicknamePaused  = "⏸️ "
+)
+
+// nicknameGap separates the end of a scrolling title from its start
+const nicknameGap = "  •  "
+
+// nicknameState is the now playing nickname of one guild
+type nicknameState struct {
+	original string      // Nickname before the bot showed tracks, restored when playback stops
+	title    string      // Track shown
+	offset   int         // Characters a long title has scrolled by
+	shown    string      // Nickname last set
+	lastSet  time.Time   // When shown was set
+	timer    *time.Timer // Pending update or scroll
+}
+
+// NicknameBoard shows the current track in the bot's nickname in guilds that turned it on with
+// /music settings, scrolling titles too long for a nickname and restoring the bot's own nickname
+// when playback stops. Each guild's nickname changes at most once per nicknameInterval.
+type NicknameBoard struct {
+	mu       sync.Mutex
+	set      func(guildID, nickname string) error
+	current  func(guildID string) string // The bot's nickname in a guild, empty for none
+	interval time.Duration
+	guilds   map[string]*nicknameState
+}
+
+// Nickname is the bot-wide now playing nickname board, active once the music player is initialized
+var Nickname = newNicknameBoard()
+
+func newNicknameBoard() *NicknameBoard {
+	return &NicknameBoard{interval: nicknameInterval, guilds: make(map[string]*nicknameState)}
+}
+
+// start sets the functions that change and read the bot's nickname in a guild, an empty nickname
+// removes it
+func (b *NicknameBoard) start(set func(guildID, nickname string) error, current func(guildID string) string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.set, b.current = set, current
+}
+
+// refresh shows the guild's current track in the nickname, or restores the bot's own nickname when
+// nothing plays, the bot left or the guild turned it off. It waits for the interval when the nickname
+// changed recently, and reads the live state when sending, so a burst of changes sends one update.
+func (b *NicknameBoard) refresh(guildID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.set == nil || SimplePlayer == nil {
+		return
+	}
+	state := b.guilds[guildID]
+	if state == nil {
+		b.update(guildID, false)
+		return
+	}
+	if state.timer != nil {
+		return
+	}
+	wait := b.interval - time.Since(state.lastSet)
+	if wait <= 0 {
+		b.update(guildID, false)
+		return
+	}
+	b.schedule(guildID, state, wait, false)
+}
+
+// schedule updates the guild's nickname after wait, scrolling it on. The caller holds b.mu.
+func (b *NicknameBoard) schedule(guildID string, state *nicknameState, wait time.Duration, scroll bool) {
+	state.timer = time.AfterFunc(wait, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.guilds[guildID] != state {
+			return // Reverted meanwhile
+		}
+		state.timer = nil
+		b.update(guildID, scroll)
+	})
+}
+
+// update sets the guild's nickname to its current track, scrolled on when scroll is set, and keeps long
+// titles scrolling while they play. The caller holds b.mu.
+func (b *NicknameBoard) update(guildID string, scroll bool) {
+	title, paused := b.playing(guildID)
+	state := b.guilds[guildID]
+
+	if title == "" {
+		if state == nil {
+			return
+		}
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+		delete(b.guilds, guildID)
+		if err := b.set(guildID, state.original); err != nil {
+			utils.LogWarn("Failed to restore the bot's nickname in guild %s: %v", guildID, err)
+		}
+		return
+	}
+
+	if state == nil {
+		state = &nicknameState{original: b.originalNickname(guildID)}
+		b.guilds[guildID] = state
+	}
+	switch {
+	case title != state.title:
+		state.title, state.offset = title, 0
+	case scroll:
+		state.offset += nicknameScrollStep
+	}
+
+	nickname := formatNickname(state.title, state.offset, paused)
+	if nickname != state.shown {
+		if err := b.set(guildID, nickname); err != nil {
+			utils.LogWarn("Failed to show the current track in the bot's nickname in guild %s, the bot may lack the Change Nickname permission: %v", guildID, err)
+			return
+		}
+		state.shown, state.lastSet = nickname, time.Now()
+	}
+
+	// Titles longer than a nickname scroll while they play
+	if !paused && len([]rune(title)) > maxNicknameLength-len([]rune(nicknamePlaying)) {
+		b.schedule(guildID, state, b.interval, true)
+	}
+}
+
+// playing returns the title of the guild's current track and whether it is paused, an empty title when
+// the guild doesn't show it or nothing plays
+func (b *NicknameBoard) playing(guildID string) (string, bool) {
+	if !SimplePlayer.NowPlayingNickname(guildID) {
+		return "", false
+	}
+	player, connected := SimplePlayer.GetPlayer(guildID)
+	if !connected {
+		return "", false
+	}
+	track := player.GetCurrent()
+	if track == nil {
+		return "", false
+	}
+	return strings.TrimSpace(track.Title), player.IsPaused()
+}
+
+// originalNickname is the bot's nickname to restore later. A now playing nickname left by a run that
+// stopped without restoring it restores to none.
+func (b *NicknameBoard) originalNickname(guildID string) string {
+	if b.current == nil {
+		return ""
+	}
+	nickname := b.current(guildID)
+	if strings.HasPrefix(nickname, nicknamePlaying) || strings.HasPrefix(nickname, nicknamePaused) {
+		return ""
+	}
+	return nickname
+}
+
+// formatNickname shows a track title in a nickname. Titles too long for it are shown from offset on,
+// wrapping around to their start.
+func formatNickname(title string, offset int, paused bool) string {
+	prefix := nicknamePlaying
+	if paused {
+		prefix = nicknamePaused
+	}
+	width := maxNicknameLength - len([]rune(prefix))
+	runes := []rune(title)
+	if len(runes) <= width {
+		return prefix + title
+	}
+
+	loop := append(runes, []rune(nicknameGap)...)
+	start := offset % len(loop)
+	window := make([]rune, 0, width)
+	for n := range width {
+		window = append(window, loop[(start+n)%len(loop)])
+	}
+	return prefix + strings.TrimSpace(string(window))
+}
+
+// setBotNickname returns a function that changes the bot's nickname in a guild on the session of the
+// guild's shard
+func setBotNickname(session *discordgo.Session, sessionFor func(guildID string) *discordgo.Session) func(guildID, nickname string) error {
+	if session == nil {
+		return nil
+	}
+	return func(guildID, nickname string) error {
+		shard := session
+		if sessionFor != nil {
+			shard = sessionFor(guildID)
+		}
+		endpoint := discordgo.EndpointGuildMember(guildID, "@me")
+		_, err := shard.RequestWithBucketID(http.MethodPatch, endpoint, map[string]string{"nick": nickname}, discordgo.EndpointGuildMember(guildID, ""))
+		return err
+	}
+}
+
+// botNickname returns a function that reads the bot's nickname in a guild from the cached state of the
+// guild's shard
+func botNickname(session *discordgo.Session, sessionFor func(guildID string) *discordgo.Session) func(guildID string) string {
+	return func(guildID string) string {
+		shard := session
+		if sessionFor != nil {
+			shard = sessionFor(guildID)
+		}
+		if shard == nil || shard.State == nil || shard.State.User == nil {
+			return ""
+		}
+		member, err := shard.State.Member(guildID, shard.State.User.ID)
+		if err != nil {
+			return ""
+		}
+		return member.Nick
+	}
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+)
+
+func TestFormatNickname(t *testing.T) {
+	assert.Equal(t, "🎵 Never Gonna Give You Up", formatNickname("Never Gonna Give You Up", 0, false))
+	assert.Equal(t, "⏸️ Never Gonna Give You Up", formatNickname("Never Gonna Give You Up", 0, true))
+
+	title := "Rick Astley - Never Gonna Give You Up (Official Music Video)"
+	first := formatNickname(title, 0, false)
+	assert.Equal(t, "🎵 Rick Astley - Never Gonna Give", first)
+	assert.LessOrEqual(t, utf8.RuneCountInString(first), maxNicknameLength)
+	assert.Equal(t, "🎵 ley - Never Gonna Give You Up", formatNickname(title, nicknameScrollStep, false), "long titles scroll")
+
+	// The end of the title wraps around to its start
+	end := formatNickname(title, len([]rune(title))-5, false)
+	assert.True(t, strings.HasPrefix(end, "🎵 ideo)"), end)
+	assert.Contains(t, end, "•  Rick")
+}
+
+func TestNicknameBoardRestoresNickname(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+	require.NoError(t, SimplePlayer.SetNowPlayingNickname("guild_1", true))
+
+	var nicknames []string
+	board := newNicknameBoard()
+	board.refresh("guild_1")
+	board.start(func(guildID, nickname string) error {
+		nicknames = append(nicknames, nickname)
+		return nil
+	}, func(guildID string) string { return "🎵 Left over" })
+
+	// Nothing plays and nothing was shown
+	board.refresh("guild_1")
+	assert.Empty(t, nicknames)
+
+	// Playback stopped, the bot gets its own nickname back
+	board.guilds["guild_1"] = &nicknameState{original: "DJ Bot", title: "Song", shown: "🎵 Song", lastSet: time.Now().Add(-time.Minute)}
+	board.refresh("guild_1")
+	assert.Equal(t, []string{"DJ Bot"}, nicknames)
+	assert.Empty(t, board.guilds)
+
+	assert.Empty(t, board.originalNickname("guild_1"), "a now playing nickname from an earlier run isn't restored")
+}
@@ -0,0 +1,338 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/radio"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// Component handler name and actions for now-playing controls ("nowplaying:<action>")
+const (
+	nowPlayingComponent     = "nowplaying"
+	nowPlayingActionPause   = "pause"
+	nowPlayingActionResume  = "resume"
+	nowPlayingActionSkip    = "skip"
+	nowPlayingActionStop    = "stop"
+	nowPlayingActionShuffle = "shuffle"
+)
+
+func init() {
+	RegisterComponentHandler(nowPlayingComponent, handleNowPlayingComponent)
+}
+
+// nowPlayingMessage identifies a posted now-playing message
+type nowPlayingMessage struct {
+	channelID string
+	messageID string
+}
+
+// NowPlayingBoard keeps one now-playing message per guild, posted in the channel music was last
+// requested from and edited in place as tracks change
+type NowPlayingBoard struct {
+	mu       sync.Mutex
+	session  SessionInterface
+	channels map[string]string            // Channel music was last requested from
+	messages map[string]nowPlayingMessage // Message currently showing the player
+}
+
+// NowPlaying is the bot-wide now-playing board, active once the music player is initialized
+var NowPlaying = newNowPlayingBoard()
+
+func newNowPlayingBoard() *NowPlayingBoard {
+	return &NowPlayingBoard{
+		channels: make(map[string]string),
+		messages: make(map[string]nowPlayingMessage),
+	}
+}
+
+// start sets the session used to post and edit messages outside of interactions
+func (b *NowPlayingBoard) start(session SessionInterface) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.session = session
+}
+
+// follow makes a channel the home of a guild's now-playing message, unless the guild set an announcement
+// channel with /settings. Moving to another channel posts a fresh message there on the next track.
+func (b *NowPlayingBoard) follow(guildID, channelID string) {
+	if announcements := GuildSettings.Get(guildID).AnnouncementChannelID; announcements != "" {
+		channelID = announcements
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if message, posted := b.messages[guildID]; posted && message.channelID != channelID {
+		delete(b.messages, guildID)
+	}
+	b.channels[guildID] = channelID
+}
+
+// refresh renders the guild's current player state into its now-playing message. It always reads
+// the live state, so refreshes arriving out of order still end on the latest one.
+func (b *NowPlayingBoard) refresh(guildID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.session == nil || SimplePlayer == nil {
+		return
+	}
+
+	embed, components := renderNowPlaying(guildID)
+	content, embeds := contentFor(nowPlayingRequester(guildID), "", []*discordgo.MessageEmbed{embed})
+	message, posted := b.messages[guildID]
+
+	// Nothing playing: retire the message instead of posting a new one
+	if len(components) == 0 {
+		if posted {
+			if err := b.edit(message, content, embeds, components); err != nil {
+				utils.LogWarn("Failed to retire now-playing message in guild %s: %v", guildID, err)
+			}
+			delete(b.messages, guildID)
+		}
+		return
+	}
+
+	if posted {
+		err := b.edit(message, content, embeds, components)
+		if err == nil {
+			return
+		}
+		// The message was most likely deleted, post a new one
+		utils.LogWarn("Failed to update now-playing message in guild %s, posting a new one: %v", guildID, err)
+		delete(b.messages, guildID)
+	}
+
+	channelID, known := b.channels[guildID]
+	if !known {
+		return
+	}
+
+	sent, err := b.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content:    content,
+		Embeds:     embeds,
+		Components: components,
+	})
+	if err != nil {
+		utils.LogWarn("Failed to post now-playing message in guild %s: %v", guildID, err)
+		return
+	}
+	if sent != nil {
+		b.messages[guildID] = nowPlayingMessage{channelID: channelID, messageID: sent.ID}
+	}
+}
+
+// edit replaces the content, embeds and buttons of a posted message (caller holds the lock)
+func (b *NowPlayingBoard) edit(message nowPlayingMessage, content string, embeds []*discordgo.MessageEmbed, components []discordgo.MessageComponent) error {
+	edit := discordgo.NewMessageEdit(message.channelID, message.messageID)
+	edit.Content = &content
+	edit.Embeds = &embeds
+	edit.Components = &components
+
+	_, err := b.session.ChannelMessageEditComplex(edit)
+	return err
+}
+
+// GuildIDs returns the guilds with a now-playing channel or message
+func (b *NowPlayingBoard) GuildIDs() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	guildIDs := make([]string, 0, len(b.channels))
+	for guildID := range b.channels {
+		guildIDs = append(guildIDs, guildID)
+	}
+	for guildID := range b.messages {
+		if _, counted := b.channels[guildID]; !counted {
+			guildIDs = append(guildIDs, guildID)
+		}
+	}
+	return guildIDs
+}
+
+// Forget drops the now-playing channel and message of a guild
+func (b *NowPlayingBoard) Forget(guildID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.channels, guildID)
+	delete(b.messages, guildID)
+}
+
+// renderNowPlaying builds the now-playing embed and buttons for a guild's current player state.
+// No components means nothing is playing.
+func renderNowPlaying(guildID string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	player, connected := SimplePlayer.GetPlayer(guildID)
+	if !connected {
+		return createIdleNowPlayingEmbed("Disconnected from the voice channel"), []discordgo.MessageComponent{}
+	}
+
+	current := player.GetCurrent()
+	if !player.IsPlaying() || current == nil {
+		return createIdleNowPlayingEmbed("The queue is empty, use /play to add more songs"), []discordgo.MessageComponent{}
+	}
+
+	paused := player.IsPaused()
+	return createNowPlayingEmbed(current, paused, len(player.GetQueue())), createNowPlayingComponents(paused)
+}
+
+// nowPlayingRequester returns who queued a guild's current track. The now-playing message is shown the
+// way they prefer, as it's mostly there for them.
+func nowPlayingRequester(guildID string) string {
+	player, connected := SimplePlayer.GetPlayer(guildID)
+	if !connected {
+		return ""
+	}
+	if current := player.GetCurrent(); current != nil {
+		return current.RequestedBy
+	}
+	return ""
+}
+
+// formatRequester shows who queued a track, mentioning members by their user ID. Tracks queued by
+// the bot itself, such as auto-DJ picks, keep their label.
+func formatRequester(requestedBy string) string {
+	if requestedBy == "" {
+		return "Unknown"
+	}
+	if _, err := strconv.ParseUint(requestedBy, 10, 64); err == nil {
+		return "<@" + requestedBy + ">"
+	}
+	return requestedBy
+}
+
+// createNowPlayingEmbed shows the current track, its requester and how much is queued after it
+func createNowPlayingEmbed(track *types.AudioSource, paused bool, queued int) *discordgo.MessageEmbed {
+	embed := NewEmbed("🎶 Now Playing").Color(0x1db954) // Green
+	if paused {
+		embed = WarningEmbed("⏸️ Paused")
+	}
+
+	upNext := "Nothing queued"
+	if queued > 0 {
+		upNext = fmt.Sprintf("%d songs", queued)
+	}
+
+	embed.
+		Descriptionf("**[%s](%s)**", track.Title, track.URL).
+		InlineField("Duration", formatTrackDuration(*track)).
+		InlineField("Requested by", formatRequester(track.RequestedBy)).
+		InlineField("Up next", upNext).
+		Thumbnail(track.Thumbnail)
+
+	if station, ok := radio.StationOf(*track); ok {
+		embed.Field("Station", formatStationDetails(station))
+	}
+
+	return embed.Build()
+}
+
+// createIdleNowPlayingEmbed replaces the now-playing embed once playback has ended
+func createIdleNowPlayingEmbed(reason string) *discordgo.MessageEmbed {
+	return NewEmbed("⏹️ Nothing Playing").Style(StyleMuted).Description(reason).Build()
+}
+
+// createNowPlayingComponents builds the playback buttons, pause turns into resume while paused
+func createNowPlayingComponents(paused bool) []discordgo.MessageComponent {
+	toggle := discordgo.Button{
+		Label:    "Pause",
+		Emoji:    &discordgo.ComponentEmoji{Name: "⏸️"},
+		Style:    discordgo.SecondaryButton,
+		CustomID: ComponentID(nowPlayingComponent, nowPlayingActionPause),
+	}
+	if paused {
+		toggle = discordgo.Button{
+			Label:    "Resume",
+			Emoji:    &discordgo.ComponentEmoji{Name: "▶️"},
+			Style:    discordgo.SuccessButton,
+			CustomID: ComponentID(nowPlayingComponent, nowPlayingActionResume),
+		}
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				toggle,
+				discordgo.Button{
+					Label:    "Skip",
+					Emoji:    &discordgo.ComponentEmoji{Name: "⏭️"},
+					Style:    discordgo.SecondaryButton,
+					CustomID: ComponentID(nowPlayingComponent, nowPlayingActionSkip),
+				},
+				discordgo.Button{
+					Label:    "Stop",
+					Emoji:    &discordgo.ComponentEmoji{Name: "⏹️"},
+					Style:    discordgo.DangerButton,
+					CustomID: ComponentID(nowPlayingComponent, nowPlayingActionStop),
+				},
+				discordgo.Button{
+					Label:    "Shuffle",
+					Emoji:    &discordgo.ComponentEmoji{Name: "🔀"},
+					Style:    discordgo.SecondaryButton,
+					CustomID: ComponentID(nowPlayingComponent, nowPlayingActionShuffle),
+				},
+			},
+		},
+	}
+}
+
+// handleNowPlayingComponent applies a playback button and updates the now-playing message
+func handleNowPlayingComponent(s SessionInterface, i *discordgo.InteractionCreate, args []string) error {
+	if len(args) != 1 {
+		return respondWithEphemeral(s, i, translate(i, "nowplaying.invalid_control"))
+	}
+
+	if SimplePlayer == nil {
+		return respondWithEphemeral(s, i, translate(i, "music.unavailable"))
+	}
+
+	player, connected := SimplePlayer.GetPlayer(i.GuildID)
+	if !connected {
+		return respondWithEphemeral(s, i, translate(i, "music.not_connected"))
+	}
+
+	switch args[0] {
+	case nowPlayingActionPause:
+		if !player.Pause() {
+			return respondWithEphemeral(s, i, translate(i, "music.nothing_playing"))
+		}
+	case nowPlayingActionResume:
+		if !player.Resume() {
+			return respondWithEphemeral(s, i, translate(i, "nowplaying.not_paused"))
+		}
+	case nowPlayingActionShuffle:
+		if len(player.GetQueue()) < 2 {
+			return respondWithEphemeral(s, i, translate(i, "music.too_few_to_shuffle"))
+		}
+		player.ShuffleQueue()
+	case nowPlayingActionSkip, nowPlayingActionStop:
+		if !player.IsPlaying() {
+			return respondWithEphemeral(s, i, translate(i, "music.nothing_playing"))
+		}
+		if args[0] == nowPlayingActionSkip {
+			player.Skip()
+		} else {
+			player.Stop()
+		}
+
+		// The track listener edits the message once the player has moved on
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		})
+	default:
+		return respondWithEphemeral(s, i, translate(i, "nowplaying.invalid_control"))
+	}
+
+	embed, components := renderNowPlaying(i.GuildID)
+	data := embedResponse(i, embed)
+	data.Components = components
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: data,
+	})
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestCreateNowPlayingEmbed(t *testing.T) {
+	track := &types.AudioSource{
+		Title:       "Song One",
+		URL:         "https://www.youtube.com/watch?v=one",
+		Duration:    types.Seconds(225),
+		Thumbnail:   "https://i.ytimg.com/vi/one/hq.jpg",
+		RequestedBy: "testuser",
+	}
+
+	embed := createNowPlayingEmbed(track, false, 3)
+	assert.Equal(t, "🎶 Now Playing", embed.Title)
+	assert.Contains(t, embed.Description, "[Song One](https://www.youtube.com/watch?v=one)")
+	require.Len(t, embed.Fields, 3)
+	assert.Equal(t, "3:45", embed.Fields[0].Value)
+	assert.Equal(t, "testuser", embed.Fields[1].Value)
+	assert.Equal(t, "3 songs", embed.Fields[2].Value)
+	require.NotNil(t, embed.Thumbnail)
+
+	paused := createNowPlayingEmbed(&types.AudioSource{Title: "Song Two"}, true, 0)
+	assert.Equal(t, "⏸️ Paused", paused.Title)
+	assert.NotEqual(t, embed.Color, paused.Color)
+	assert.Equal(t, "Unknown", paused.Fields[1].Value)
+	assert.Equal(t, "Nothing queued", paused.Fields[2].Value)
+	assert.Nil(t, paused.Thumbnail)
+}
+
+func TestCreateNowPlayingComponents(t *testing.T) {
+	buttonIDs := func(components []discordgo.MessageComponent) []string {
+		require.Len(t, components, 1)
+		var ids []string
+		for _, component := range components[0].(discordgo.ActionsRow).Components {
+			ids = append(ids, component.(discordgo.Button).CustomID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{
+		ComponentID(nowPlayingComponent, nowPlayingActionPause),
+		ComponentID(nowPlayingComponent, nowPlayingActionSkip),
+		ComponentID(nowPlayingComponent, nowPlayingActionStop),
+		ComponentID(nowPlayingComponent, nowPlayingActionShuffle),
+	}, buttonIDs(createNowPlayingComponents(false)))
+
+	assert.Equal(t, ComponentID(nowPlayingComponent, nowPlayingActionResume), buttonIDs(createNowPlayingComponents(true))[0])
+}
+
+func TestHandleNowPlayingComponent(t *testing.T) {
+	tests := []struct {
+		name          string
+		customID      string
+		expectContent string
+	}{
+		{
+			name:          "malformed control",
+			customID:      nowPlayingComponent,
+			expectContent: "Invalid playback control",
+		},
+		{
+			name:          "pause without music system",
+			customID:      ComponentID(nowPlayingComponent, nowPlayingActionPause),
+			expectContent: "Music system is not available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSession := &testutils.MockSession{}
+			interaction := testutils.CreateTestComponentInteraction(tt.customID)
+
+			err := HandleComponentInteraction(mockSession, interaction)
+			require.NoError(t, err)
+
+			assert.Equal(t, discordgo.InteractionResponseChannelMessageWithSource, mockSession.RespondType)
+			assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+			assert.Contains(t, mockSession.RespondData.Content, tt.expectContent)
+		})
+	}
+}
+
+func TestNowPlayingBoardTracking(t *testing.T) {
+	board := newNowPlayingBoard()
+	board.follow("guild_1", "channel_1")
+	board.messages["guild_1"] = nowPlayingMessage{channelID: "channel_1", messageID: "message_1"}
+	board.messages["guild_2"] = nowPlayingMessage{channelID: "channel_2", messageID: "message_2"}
+	assert.ElementsMatch(t, []string{"guild_1", "guild_2"}, board.GuildIDs())
+
+	// Following the same channel keeps editing the posted message
+	board.follow("guild_1", "channel_1")
+	assert.Contains(t, board.messages, "guild_1")
+
+	// A new channel gets a fresh message
+	board.follow("guild_1", "channel_3")
+	assert.NotContains(t, board.messages, "guild_1")
+	assert.Equal(t, "channel_3", board.channels["guild_1"])
+
+	board.Forget("guild_1")
+	board.Forget("guild_2")
+	assert.Empty(t, board.GuildIDs())
+}
+
+func TestNowPlayingBoardRefreshWithoutMusicSystem(t *testing.T) {
+	mockSession := &testutils.MockSession{}
+	board := newNowPlayingBoard()
+	board.start(mockSession)
+	board.follow("guild_1", "channel_1")
+
+	board.refresh("guild_1")
+	assert.False(t, mockSession.ChannelMessageSendCalled)
+	assert.False(t, mockSession.ChannelMessageEditCalled)
+}
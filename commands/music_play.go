@@ -1,8 +1,25 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/direct"
+	"pxnx-discord-bot/music/history"
+	"pxnx-discord-bot/music/lavalink"
+	"pxnx-discord-bot/music/playlist"
+	"pxnx-discord-bot/music/providers"
+	"pxnx-discord-bot/music/radio"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/music/twitch"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/music/usage"
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/utils"
+	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -10,55 +27,304 @@ import (
 // SimplePlayer is a global instance of the simplified music player
 var SimplePlayer *music.SimplePlayer
 
-// InitializeSimplePlayer initializes the global simple player
-func InitializeSimplePlayer(session *discordgo.Session) {
+// InitializeSimplePlayer initializes the global simple player on the sessions of the bot's shards, the first
+// one for everything that isn't tied to a guild; sessionFor picks the session of the shard a guild is on
+func InitializeSimplePlayer(sessions []*discordgo.Session, sessionFor func(guildID string) *discordgo.Session) {
+	session := sessions[0]
 	SimplePlayer = music.NewSimplePlayer(session)
-}
+	SimplePlayer.UseShards(sessionFor)
+	SimplePlayer.UsePremium(LoadPremium())
+	MusicPriority = LoadPriorityConfig()
+	MusicQuota = LoadRequestQuota()
+	YtdlpMetrics = LoadYtdlpMetrics()
+	YtdlpFailover = LoadYtdlpFailover(SimplePlayer)
 
-// HandlePlayCommand handles the /play slash command using the simplified approach
-func HandlePlayCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
-	// Defer response to avoid timeout
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	// Track changes keep each guild's now-playing message, voice channel status and nickname, and the
+	// bot's presence, current
+	NowPlaying.start(&sessionWrapper{session: session})
+	ChannelStatus.start(setVoiceChannelStatus(session))
+	Nickname.start(setBotNickname(session, sessionFor), botNickname(session, sessionFor))
+	Presence.start(updatePresence(sessions...))
+	SimplePlayer.SetTrackListener(func(guildID string) {
+		NowPlaying.refresh(guildID)
+		ChannelStatus.refresh(guildID)
+		Nickname.refresh(guildID)
+		Presence.refresh()
 	})
-	if err != nil {
-		return fmt.Errorf("failed to defer response: %w", err)
+
+	// Servers that turn voice control on command the player by voice, which needs speech to text
+	if Transcriber != nil {
+		SimplePlayer.UseVoiceControl(runVoiceCommand)
+	}
+
+	// Servers that turn DJ intros on hear one before each track, which needs text to speech
+	if Speaker != nil {
+		SimplePlayer.UseAnnouncer(announceTrack(stateMemberName(session, sessionFor)))
+	}
+
+	// Audio files played from links and attachments are limited in size
+	if value := strings.TrimSpace(os.Getenv("MUSIC_MAX_FILE_SIZE")); value != "" {
+		if maxSize, err := usage.ParseSize(value); err != nil {
+			utils.LogWarn("Ignoring MUSIC_MAX_FILE_SIZE: %v", err)
+		} else {
+			SimplePlayer.SetMaxFileSize(maxSize)
+		}
+	}
+
+	// Radio stations come from a radio-browser.info mirror, which can be pinned to one server
+	if baseURL := strings.TrimSpace(os.Getenv("RADIO_BROWSER_URL")); baseURL != "" {
+		SimplePlayer.SetRadioDirectory(baseURL)
+	}
+
+	// One server queueing many songs can't keep yt-dlp busy for the others
+	if value := strings.TrimSpace(os.Getenv("MUSIC_EXTRACTION_LIMITS")); value != "" {
+		if limits, err := providers.ParseLimits(value); err != nil {
+			utils.LogWarn("Ignoring MUSIC_EXTRACTION_LIMITS: %v", err)
+		} else {
+			SimplePlayer.SetExtractionLimits(limits)
+		}
+	}
+
+	// Links several providers support go to the first one in MUSIC_PROVIDER_ORDER
+	if order := providers.ParseOrder(os.Getenv("MUSIC_PROVIDER_ORDER")); len(order) > 0 {
+		if err := SimplePlayer.SetProviderOrder(order); err != nil {
+			utils.LogWarn("Ignoring MUSIC_PROVIDER_ORDER: %v", err)
+		}
+	}
+
+	// Traffic counters survive restarts, so the monthly cap can't be reset by restarting the bot
+	if meter, err := usage.LoadMeter(UsagePath(), 0); err != nil {
+		utils.LogWarn("Music usage counters will not be saved this run: %v", err)
+	} else {
+		SimplePlayer.UseUsageMeter(meter)
+	}
+
+	// Hosted deployments can limit how much audio each server streams per month
+	if value := strings.TrimSpace(os.Getenv("MUSIC_BANDWIDTH_CAP")); value != "" {
+		if monthlyCap, err := usage.ParseSize(value); err != nil {
+			utils.LogWarn("Ignoring MUSIC_BANDWIDTH_CAP: %v", err)
+		} else {
+			SimplePlayer.SetBandwidthCap(monthlyCap)
+			utils.LogInfo("Music bandwidth capped at %s per server per month", value)
+		}
+	}
+
+	// Larger deployments can hand playback to a Lavalink node instead of running FFmpeg in the bot
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("MUSIC_BACKEND"))); backend {
+	case "", music.BackendFFmpeg:
+	case music.BackendLavalink:
+		if url := strings.TrimSpace(os.Getenv("LAVALINK_URL")); url == "" {
+			utils.LogWarn("MUSIC_BACKEND is lavalink but LAVALINK_URL is not set, using the built-in player")
+		} else {
+			SimplePlayer.UseLavalink(lavalink.NewNode(lavalink.Config{URL: url, Password: os.Getenv("LAVALINK_PASSWORD")}))
+			utils.LogInfo("Music is played through the Lavalink node at %s", url)
+		}
+	default:
+		utils.LogWarn("Ignoring unknown MUSIC_BACKEND %q, using the built-in player", backend)
+	}
+
+	// Extracted tracks are reused until their stream URLs expire, so popular tracks skip yt-dlp
+	cacheTTL := ytdlp.DefaultServiceConfig().CacheTTL
+	if value := strings.TrimSpace(os.Getenv("YTDLP_CACHE_TTL")); value != "" {
+		if ttl, err := time.ParseDuration(value); err != nil {
+			utils.LogWarn("Ignoring YTDLP_CACHE_TTL: %v", err)
+		} else {
+			cacheTTL = ttl
+		}
+	}
+	if cache, err := ytdlp.LoadMetadataCache(MetadataCachePath(), cacheTTL); err != nil {
+		utils.LogWarn("yt-dlp extractions will not be saved this run: %v", err)
+		SimplePlayer.UseMetadataCache(ytdlp.NewMetadataCache(cacheTTL))
+	} else {
+		SimplePlayer.UseMetadataCache(cache)
 	}
 
+	// Age-restricted and bot-flagged videos need YouTube cookies or a PO token
+	if credentials, err := LoadYtdlpCredentials(); err != nil {
+		utils.LogWarn("yt-dlp runs without YouTube credentials: %v", err)
+	} else if !credentials.IsZero() {
+		SimplePlayer.UseYtdlpCredentials(credentials)
+		utils.LogInfo("yt-dlp signs in to YouTube with the configured credentials")
+	}
+
+	// Rate-limited deployments spread extractions across proxies
+	if proxies := LoadYtdlpProxies(); len(proxies) > 0 {
+		SimplePlayer.UseYtdlpProxies(proxies)
+		utils.LogInfo("yt-dlp runs take turns between %d proxies", len(proxies))
+	}
+
+	SimplePlayer.UseSettings(GuildSettings)
+}
+
+// MetadataCachePath returns where yt-dlp extractions are saved, YTDLP_CACHE_FILE or the default
+func MetadataCachePath() string {
+	if path := strings.TrimSpace(os.Getenv("YTDLP_CACHE_FILE")); path != "" {
+		return path
+	}
+	return ytdlp.DefaultMetadataCachePath
+}
+
+// UsagePath returns where the music traffic counters are saved, MUSIC_USAGE_FILE or the default
+func UsagePath() string {
+	if path := strings.TrimSpace(os.Getenv("MUSIC_USAGE_FILE")); path != "" {
+		return path
+	}
+	return usage.DefaultPath
+}
+
+// SaveUsage saves the music traffic counters, for when the bot shuts down
+func SaveUsage() {
+	if SimplePlayer == nil {
+		return
+	}
+	if err := SimplePlayer.SaveUsage(); err != nil {
+		utils.LogWarn("Music usage counters not saved: %v", err)
+	}
+}
+
+// MusicSettingsPath returns where guild music settings are saved, MUSIC_SETTINGS_FILE or the default
+func MusicSettingsPath() string {
+	if path := strings.TrimSpace(os.Getenv("MUSIC_SETTINGS_FILE")); path != "" {
+		return path
+	}
+	return settings.DefaultPath
+}
+
+// HandlePlayCommand handles the /play slash command using the simplified approach
+func HandlePlayCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
 	// Check if simple player is initialized
 	if SimplePlayer == nil {
-		return respondWithError(s, i, "Music system is not available")
+		return respondWithError(s, i, translate(i, "music.unavailable"))
+	}
+
+	// Get the query, attached file, provider and playlist limit from command options
+	data := i.ApplicationCommandData()
+	var query, provider string
+	var limit int
+	var file *discordgo.MessageAttachment
+	for _, option := range data.Options {
+		switch option.Name {
+		case "query":
+			query = option.StringValue()
+		case "limit":
+			limit = int(option.IntValue())
+		case "provider":
+			provider = option.StringValue()
+		case "file":
+			if data.Resolved != nil {
+				file = data.Resolved.Attachments[option.StringValue()]
+			}
+		}
 	}
 
-	// Get the query from command options
-	var query string
-	if len(i.ApplicationCommandData().Options) > 0 {
-		query = i.ApplicationCommandData().Options[0].StringValue()
+	if file != nil {
+		if query != "" {
+			return respondWithError(s, i, translate(i, "play.song_or_file"))
+		}
+		if message := checkAudioAttachment(file, SimplePlayer.MaxFileSize()); message != "" {
+			return respondWithError(s, i, message)
+		}
+		query = file.URL
 	}
 
 	if query == "" {
-		return respondWithError(s, i, "Please provide a song name or YouTube URL")
+		return respondWithError(s, i, translate(i, "play.missing_query"))
+	}
+	if message := checkProviderQuery(provider, query); message != "" {
+		return respondWithError(s, i, message)
 	}
 
 	// Check if bot is connected to a voice channel
 	player, connected := SimplePlayer.GetPlayer(i.GuildID)
 	if !connected {
-		return respondWithError(s, i, "I need to be in a voice channel first. Use `/join` command")
+		return respondWithError(s, i, translate(i, "music.join_first"))
+	}
+	if SimplePlayer.ListeningAlong(i.GuildID) {
+		return respondWithError(s, i, translate(i, "play.listening_along"))
+	}
+
+	// Members get a number of song requests per hour, more after voting for the bot
+	userID := getInteractionUserID(i)
+	voter := Votes.HasPerk(userID)
+	if retryAt, ok := MusicQuota.Take(userID, voter); !ok {
+		return respondWithError(s, i, quotaExceededMessage(MusicQuota, voter, retryAt.Unix()))
+	}
+
+	// The now-playing message follows the channel music is requested from
+	NowPlaying.follow(i.GuildID, i.ChannelID)
+
+	if playlist.IsPlaylistURL(query) && expandsPlaylists(provider) {
+		return handlePlaylistImport(s, i, query, limit)
+	}
+
+	// Search queries let the user pick from the top results instead of auto-playing the first one
+	if !isURL(query) {
+		if provider == "" {
+			provider = SimplePlayer.SearchProvider(i.GuildID)
+		}
+		return handleSearchResults(s, i, query, provider)
+	}
+
+	return playQuery(s, i, player, query, provider)
+}
+
+// checkProviderQuery returns why a picked provider can't take a query, or "" when it can
+func checkProviderQuery(provider, query string) string {
+	if provider == "" || isURL(query) {
+		return ""
+	}
+	if capabilities, ok := SimplePlayer.ProviderCapabilities(provider); ok && !capabilities.Search {
+		return fmt.Sprintf("The %s provider can't be searched, give it a link instead", provider)
+	}
+	return ""
+}
+
+// expandsPlaylists reports whether a playlist link is imported as its tracks. Routed links are, and so are
+// links given to a provider that expands playlists.
+func expandsPlaylists(provider string) bool {
+	if provider == "" {
+		return true
 	}
+	capabilities, ok := SimplePlayer.ProviderCapabilities(provider)
+	return ok && capabilities.Playlists
+}
+
+// playQuery extracts and enqueues a single track with a provider, or the one routing picks when empty,
+// then edits the response with the track embed
+func playQuery(s SessionInterface, i *discordgo.InteractionCreate, player *music.VoicePlayer, query, provider string) error {
+	// Extraction can be slow, so later updates go through a responder that survives token expiry
+	responder := NewInteractionResponder(s, i)
 
 	// Send searching status
-	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Content: &[]string{"🔍 Searching for music..."}[0],
-	})
-	if err != nil {
+	if err := responder.Edit("🔍 Searching for music..."); err != nil {
 		return fmt.Errorf("failed to update response: %w", err)
 	}
 
-	// Try to play the track
-	track, err := SimplePlayer.Play(i.GuildID, query)
+	track, err := SimplePlayer.ResolveWith(extractionContext(i, responder), provider, query)
+	if errors.Is(err, providers.ErrQueueFull) {
+		return respondWithError(s, i, translate(i, "play.extraction_queue_full"))
+	}
 	if err != nil {
-		return respondWithError(s, i, fmt.Sprintf("Failed to play music: %v", err))
+		return respondWithError(s, i, translate(i, "play.failed", err))
+	}
+
+	// Guilds with anti-repeat enabled refuse or flag tracks played recently
+	notice := ""
+	if playedAt, repeat := SimplePlayer.CheckRepeat(i.GuildID, *track); repeat {
+		policy := SimplePlayer.RepeatPolicy(i.GuildID)
+		if policy.Mode == history.RepeatRefuse {
+			return responder.Edit(formatRepeatNotice(track.Title, time.Since(playedAt), policy))
+		}
+		notice = "\n" + formatRepeatNotice(track.Title, time.Since(playedAt), policy)
+	}
+
+	// Try to play the track
+	request := trackRequest(i)
+	if err := SimplePlayer.Enqueue(i.GuildID, []types.AudioSource{*track}, request); err != nil {
+		return respondWithError(s, i, translate(i, "play.failed", err))
 	}
+	track.RequestedBy = request.RequestedBy
+	track.Priority = request.Priority
 
 	// Create success response
 	var content string
@@ -66,66 +332,147 @@ func HandlePlayCommand(s SessionInterface, i *discordgo.InteractionCreate) error
 
 	if player.IsPlaying() {
 		// Currently playing - added to queue
-		queuePosition := len(player.GetQueue())
-		content = fmt.Sprintf("🎵 Added to queue (position %d)", queuePosition)
-		embed = createTrackEmbed(track, "Added to Queue", 0x3498db, i.Member.User) // Blue
+		position := queuePosition(player.GetQueue(), track.Priority)
+		content = translate(i, "play.queued", position)
+		if track.Priority {
+			content = translate(i, "play.priority_queued", position)
+		}
+		embed = createTrackEmbed(i, track, translate(i, "track.queued"), 0x3498db) // Blue
 	} else {
 		// Started playing immediately
-		content = "🎵 Now playing"
-		embed = createTrackEmbed(track, "Now Playing", 0x1db954, i.Member.User) // Spotify green
+		content = translate(i, "play.now_playing")
+		embed = createTrackEmbed(i, track, translate(i, "track.now_playing"), 0x1db954) // Spotify green
 	}
 
 	// Edit the response with success
-	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Content: &content,
-		Embeds:  &[]*discordgo.MessageEmbed{embed},
+	return responder.Edit(content+notice, embed)
+}
+
+// extractionContext returns the request's context, whose extractions show their place in line on the
+// response while they wait for yt-dlp
+func extractionContext(i *discordgo.InteractionCreate, responder *InteractionResponder) context.Context {
+	return providers.WithQueueListener(RequestContext(i), func(position int) {
+		if err := responder.Edit(translate(i, "play.extraction_waiting", position)); err != nil {
+			utils.LogWarnContext(RequestContext(i), "Failed to show extraction queue position: %v", err)
+		}
 	})
+}
 
-	return err
+// checkAudioAttachment explains why an attached file can't be played, or returns "" when it can
+func checkAudioAttachment(file *discordgo.MessageAttachment, maxSize int64) string {
+	if !direct.IsAudio(file.ContentType, file.Filename) {
+		return fmt.Sprintf("%s is not an audio file, attach an mp3, ogg, opus, wav, flac or m4a file", file.Filename)
+	}
+	if int64(file.Size) > maxSize {
+		return fmt.Sprintf("%s is %d MB, files can be up to %d MB", file.Filename, file.Size>>20, maxSize>>20)
+	}
+	return ""
 }
 
-// Helper functions
+// isURL reports whether a play query is a link rather than a search term
+func isURL(query string) bool {
+	return strings.HasPrefix(query, "http://") || strings.HasPrefix(query, "https://")
+}
 
-func createTrackEmbed(track *music.AudioTrack, title string, color int, requestedBy *discordgo.User) *discordgo.MessageEmbed {
-	embed := &discordgo.MessageEmbed{
-		Title:       title,
-		Description: fmt.Sprintf("**[%s](%s)**", track.Title, track.URL),
-		Color:       color,
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Duration",
-				Value:  track.Duration,
-				Inline: true,
-			},
-			{
-				Name:   "Provider",
-				Value:  "Youtube",
-				Inline: true,
-			},
-			{
-				Name:   "Requested by",
-				Value:  requestedBy.Username,
-				Inline: true,
-			},
-		},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Use /pause, /skip, or /stop to control playback",
-		},
+// handlePlaylistImport enqueues the entries of a playlist and reports the result in a followup
+func handlePlaylistImport(s SessionInterface, i *discordgo.InteractionCreate, playlistURL string, limit int) error {
+	// Large playlists can take a while, so updates go through a responder that survives token expiry
+	responder := NewInteractionResponder(s, i)
+
+	if err := responder.Edit(translate(i, "playlist.loading", playlist.ClampLimit(limit))); err != nil {
+		return fmt.Errorf("failed to update response: %w", err)
+	}
+
+	tracks, err := SimplePlayer.GetPlaylist(RequestContext(i), playlistURL, limit)
+	if err != nil {
+		return responder.Edit(translate(i, "playlist.load_failed", err))
+	}
+
+	if err := responder.Edit(translate(i, "playlist.adding", len(tracks))); err != nil {
+		return fmt.Errorf("failed to update response: %w", err)
+	}
+
+	tracks, repeats := filterRepeats(i.GuildID, tracks)
+	if len(tracks) == 0 {
+		return responder.Edit(translate(i, "playlist.all_repeats", repeats))
+	}
+
+	if err := SimplePlayer.Enqueue(i.GuildID, tracks, trackRequest(i)); err != nil {
+		return responder.Edit(translate(i, "playlist.queue_failed", err))
+	}
+
+	embed := createPlaylistEmbed(i, tracks, playlistURL)
+	if repeats > 0 {
+		embed.Footer.Text = formatPlaylistRepeats(repeats, SimplePlayer.RepeatPolicy(i.GuildID).Mode) + " • " + embed.Footer.Text
 	}
+	return responder.Followup("", embed)
+}
 
-	// Add thumbnail if available
-	if track.Thumbnail != "" {
-		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
-			URL: track.Thumbnail,
+// Helper functions
+
+// createPlaylistEmbed summarises a playlist import with the first few tracks
+func createPlaylistEmbed(i *discordgo.InteractionCreate, tracks []types.AudioSource, playlistURL string) *discordgo.MessageEmbed {
+	var preview strings.Builder
+	for index, track := range tracks {
+		if index >= 5 {
+			preview.WriteString(translate(i, "track.more", len(tracks)-5) + "\n")
+			break
 		}
+		preview.WriteString(fmt.Sprintf("%d. **%s**\n", index+1, track.Title))
+	}
+
+	return NewEmbed(translate(i, "playlist.title")).
+		Description(translate(i, "playlist.description", len(tracks), playlistURL)).
+		Field(translate(i, "playlist.tracks"), preview.String()).
+		InlineField(translate(i, "track.requested_by"), i.Member.User.Username).
+		Footer(translate(i, "playlist.footer")).
+		Build()
+}
+
+// createTrackEmbed shows a track requested with an interaction, in the interaction's language
+func createTrackEmbed(i *discordgo.InteractionCreate, track *types.AudioSource, title string, color int) *discordgo.MessageEmbed {
+	provider := "Youtube"
+	switch track.Provider {
+	case direct.ProviderName:
+		provider = translate(i, "track.provider_file")
+	case radio.ProviderName:
+		provider = translate(i, "track.provider_radio")
+	case twitch.ProviderName:
+		provider = "Twitch"
+	}
+
+	embed := NewEmbed(title).
+		Color(color).
+		Descriptionf("**[%s](%s)**", track.Title, track.URL).
+		InlineField(translate(i, "track.duration"), formatTrackDuration(*track)).
+		InlineField(translate(i, "track.provider"), provider).
+		InlineField(translate(i, "track.requested_by"), i.Member.User.Username).
+		Thumbnail(track.Thumbnail).
+		Footer(translate(i, "track.footer"))
+
+	if station, ok := radio.StationOf(*track); ok {
+		embed.Field(translate(i, "track.station"), formatStationDetails(station))
 	}
 
-	return embed
+	return embed.Build()
+}
+
+// formatTrackDuration is a track's length for embeds; files played from a link have no known length
+// until they play and live streams have none
+func formatTrackDuration(track types.AudioSource) string {
+	switch {
+	case track.Live:
+		return "🔴 Live"
+	case !track.Duration.Known():
+		return "Unknown"
+	default:
+		return track.Duration.String()
+	}
 }
 
 func respondWithError(s SessionInterface, i *discordgo.InteractionCreate, message string) error {
 	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Content: &[]string{fmt.Sprintf("❌ %s", message)}[0],
+		Content: &[]string{messageFor(i, fmt.Sprintf("❌ %s", message))}[0],
 	})
 	return err
 }
@@ -134,7 +481,7 @@ func respondWithInteraction(s SessionInterface, i *discordgo.InteractionCreate,
 	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: message,
+			Content: messageFor(i, message),
 		},
 	})
 }
\ No newline at end of file
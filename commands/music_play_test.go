@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/direct"
+	"pxnx-discord-bot/music/radio"
+	"pxnx-discord-bot/music/twitch"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestCheckAudioAttachment(t *testing.T) {
+	song := &discordgo.MessageAttachment{Filename: "song.mp3", ContentType: "audio/mpeg", Size: 4 << 20}
+	assert.Empty(t, checkAudioAttachment(song, direct.DefaultMaxSize))
+
+	voiceMessage := &discordgo.MessageAttachment{Filename: "voice-message.ogg", Size: 1 << 10}
+	assert.Empty(t, checkAudioAttachment(voiceMessage, direct.DefaultMaxSize), "the extension is enough without a content type")
+
+	image := &discordgo.MessageAttachment{Filename: "cat.png", ContentType: "image/png", Size: 1 << 10}
+	assert.Equal(t, "cat.png is not an audio file, attach an mp3, ogg, opus, wav, flac or m4a file", checkAudioAttachment(image, direct.DefaultMaxSize))
+
+	assert.Equal(t, "song.mp3 is 4 MB, files can be up to 1 MB", checkAudioAttachment(song, 1<<20))
+}
+
+// newPlayInteraction builds a /play request from a member, which track embeds name as the requester
+func newPlayInteraction() *discordgo.InteractionCreate {
+	interaction := testutils.CreateTestInteraction("play", nil)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser("user_id", "testuser", ""))
+	return interaction
+}
+
+func TestCreateTrackEmbedForAudioFiles(t *testing.T) {
+	track := &types.AudioSource{Title: "my song", URL: "https://example.com/my_song.mp3", Provider: direct.ProviderName}
+	embed := createTrackEmbed(newPlayInteraction(), track, "Now Playing", 0x1db954)
+
+	assert.Equal(t, "Unknown", embed.Fields[0].Value, "embed fields can't be empty")
+	assert.Equal(t, "Audio file", embed.Fields[1].Value)
+	assert.Nil(t, embed.Thumbnail)
+}
+
+func TestCreateTrackEmbedForTwitchLive(t *testing.T) {
+	track := &types.AudioSource{Title: "Streamer live", URL: "https://www.twitch.tv/streamer", Provider: twitch.ProviderName, Live: true}
+	embed := createTrackEmbed(newPlayInteraction(), track, "Now Playing", 0x1db954)
+
+	assert.Equal(t, "🔴 Live", embed.Fields[0].Value)
+	assert.Equal(t, "Twitch", embed.Fields[1].Value)
+}
+
+func TestProviderCapabilitiesShapePlayRequests(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	assert.Empty(t, checkProviderQuery("", "lofi beats"))
+	assert.Empty(t, checkProviderQuery(radio.ProviderName, "lofi beats"))
+	assert.Empty(t, checkProviderQuery(twitch.ProviderName, "https://twitch.tv/streamer"))
+	assert.Equal(t, "The twitch provider can't be searched, give it a link instead", checkProviderQuery(twitch.ProviderName, "streamer"))
+	assert.Equal(t, "The direct provider can't be searched, give it a link instead", checkProviderQuery(direct.ProviderName, "my song"))
+
+	assert.True(t, expandsPlaylists(""))
+	assert.True(t, expandsPlaylists(music.YouTubeProvider))
+	assert.False(t, expandsPlaylists(twitch.ProviderName))
+	assert.False(t, expandsPlaylists("unknown"))
+}
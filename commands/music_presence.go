@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// presenceInterval is the least time between presence updates. Discord drops presence updates sent
+// more often than about five per minute, and busy bots change tracks far more often than that.
+const presenceInterval = 20 * time.Second
+
+// maxActivityNameLength is the longest activity name Discord shows
+const maxActivityNameLength = 128
+
+// PresenceBoard shows what the bot plays as its presence: the track when a single server is listening,
+// the number of servers otherwise. Updates are coalesced so at most one is sent per presenceInterval.
+type PresenceBoard struct {
+	mu       sync.Mutex
+	set      func(activity *discordgo.Activity) error
+	interval time.Duration
+	timer    *time.Timer         // Pending update, set while waiting for the interval to pass
+	last     *discordgo.Activity // Activity last shown, nil when idle
+	shown    bool                // Whether last was sent on the current gateway session
+	lastSet  time.Time
+}
+
+// Presence is the bot-wide presence board, active once the music player is initialized. It counts
+// every guild this process plays in, so each shard of a sharded process shows the same total.
+var Presence = newPresenceBoard()
+
+func newPresenceBoard() *PresenceBoard {
+	return &PresenceBoard{interval: presenceInterval}
+}
+
+// start sets the function that changes the bot's activity, nil clears it
+func (b *PresenceBoard) start(set func(activity *discordgo.Activity) error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.set = set
+}
+
+// Reset forgets the shown activity and shows the current one again. A new gateway session starts
+// without a presence.
+func (b *PresenceBoard) Reset() {
+	b.mu.Lock()
+	b.shown = false
+	b.mu.Unlock()
+	b.refresh()
+}
+
+// refresh shows the current playback, right away when the last update is old enough and otherwise
+// once the interval has passed. It reads the live state when sending, so a burst of track changes
+// sends one update.
+func (b *PresenceBoard) refresh() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.set == nil || SimplePlayer == nil || b.timer != nil {
+		return
+	}
+	wait := b.interval - time.Since(b.lastSet)
+	if wait <= 0 {
+		b.update()
+		return
+	}
+	b.timer = time.AfterFunc(wait, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.timer = nil
+		b.update()
+	})
+}
+
+// update sends the current activity unless it is already shown. The caller holds b.mu.
+func (b *PresenceBoard) update() {
+	if b.set == nil || SimplePlayer == nil {
+		return
+	}
+	activity := presenceActivity(SimplePlayer.CurrentTracks())
+	if b.shown && sameActivity(activity, b.last) {
+		return
+	}
+	if err := b.set(activity); err != nil {
+		utils.LogWarn("Failed to update the bot's presence: %v", err)
+		return
+	}
+	b.last, b.shown, b.lastSet = activity, true, time.Now()
+}
+
+// presenceActivity describes the tracks playing across guilds, nil when nothing plays
+func presenceActivity(tracks map[string]types.AudioSource) *discordgo.Activity {
+	switch len(tracks) {
+	case 0:
+		return nil
+	case 1:
+		for _, track := range tracks {
+			name := track.Title
+			if runes := []rune(name); len(runes) > maxActivityNameLength {
+				name = string(runes[:maxActivityNameLength-1]) + "…"
+			}
+			return &discordgo.Activity{Name: name, Type: discordgo.ActivityTypeListening}
+		}
+	}
+	return &discordgo.Activity{Name: fmt.Sprintf("music in %d servers", len(tracks)), Type: discordgo.ActivityTypeGame}
+}
+
+// sameActivity reports whether two activities show the same thing
+func sameActivity(a, b *discordgo.Activity) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name == b.Name && a.Type == b.Type
+}
+
+// updatePresence returns a function that sets the bot's activity over the gateway, on every shard as each
+// has a presence of its own
+func updatePresence(sessions ...*discordgo.Session) func(activity *discordgo.Activity) error {
+	if len(sessions) == 0 || sessions[0] == nil {
+		return nil
+	}
+	return func(activity *discordgo.Activity) error {
+		status := discordgo.UpdateStatusData{Status: string(discordgo.StatusOnline), Activities: []*discordgo.Activity{}}
+		if activity != nil {
+			status.Activities = []*discordgo.Activity{activity}
+		}
+		var errs []error
+		for _, session := range sessions {
+			if err := session.UpdateStatusComplex(status); err != nil {
+				errs = append(errs, fmt.Errorf("shard %d: %w", session.ShardID, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
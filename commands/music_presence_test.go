@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/types"
+)
+
+func TestPresenceActivity(t *testing.T) {
+	assert.Nil(t, presenceActivity(nil))
+
+	one := presenceActivity(map[string]types.AudioSource{"guild": {Title: "Song"}})
+	assert.Equal(t, &discordgo.Activity{Name: "Song", Type: discordgo.ActivityTypeListening}, one)
+
+	many := presenceActivity(map[string]types.AudioSource{"a": {Title: "One"}, "b": {Title: "Two"}, "c": {Title: "Three"}})
+	assert.Equal(t, &discordgo.Activity{Name: "music in 3 servers", Type: discordgo.ActivityTypeGame}, many)
+
+	long := presenceActivity(map[string]types.AudioSource{"guild": {Title: strings.Repeat("a", 200)}})
+	assert.Len(t, []rune(long.Name), maxActivityNameLength)
+}
+
+func TestPresenceBoardCoalescesUpdates(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	var mu sync.Mutex
+	updates := 0
+	board := newPresenceBoard()
+	board.interval = 50 * time.Millisecond
+	board.start(func(activity *discordgo.Activity) error {
+		mu.Lock()
+		defer mu.Unlock()
+		updates++
+		return nil
+	})
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return updates
+	}
+
+	board.refresh()
+	assert.Equal(t, 1, count(), "the first update is sent right away")
+
+	board.refresh()
+	board.refresh()
+	assert.Equal(t, 1, count(), "updates within the interval wait")
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, count(), "an unchanged activity isn't sent again")
+
+	board.Reset()
+	assert.Equal(t, 2, count(), "a new gateway session gets the activity again")
+}
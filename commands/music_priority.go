@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/tracing"
+	"pxnx-discord-bot/utils"
+)
+
+// PriorityConfig decides which requesters have their tracks queued ahead of normal requests
+type PriorityConfig struct {
+	Boosters bool   // Server boosters get priority
+	RoleID   string // Members with this role get priority
+}
+
+// MusicPriority is the active priority configuration, loaded when the music player is initialized
+var MusicPriority PriorityConfig
+
+// LoadPriorityConfig reads MUSIC_PRIORITY_BOOSTERS and MUSIC_PRIORITY_ROLE_ID from the environment
+func LoadPriorityConfig() PriorityConfig {
+	config := PriorityConfig{
+		RoleID: strings.TrimSpace(os.Getenv("MUSIC_PRIORITY_ROLE_ID")),
+	}
+
+	if raw := strings.TrimSpace(os.Getenv("MUSIC_PRIORITY_BOOSTERS")); raw != "" {
+		boosters, err := strconv.ParseBool(raw)
+		if err != nil {
+			utils.LogWarn("Ignoring invalid value %q for MUSIC_PRIORITY_BOOSTERS", raw)
+		}
+		config.Boosters = boosters
+	}
+	return config
+}
+
+// IsPriority reports whether a member's requests go into the priority tier
+func (c PriorityConfig) IsPriority(member *discordgo.Member) bool {
+	if member == nil {
+		return false
+	}
+	if c.Boosters && member.PremiumSince != nil {
+		return true
+	}
+	if c.RoleID != "" {
+		for _, roleID := range member.Roles {
+			if roleID == c.RoleID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// trackRequest builds the queue request for the user behind an interaction
+func trackRequest(i *discordgo.InteractionCreate) music.TrackRequest {
+	return music.TrackRequest{
+		RequestedBy: getInteractionUserID(i),
+		Priority:    MusicPriority.IsPriority(i.Member),
+		Trace:       tracing.SpanContextFromContext(RequestContext(i)),
+	}
+}
+
+// queuePosition returns the 1-based position of the most recently added track of a tier
+func queuePosition(queue []types.AudioSource, priority bool) int {
+	if !priority {
+		return len(queue)
+	}
+
+	position := 0
+	for position < len(queue) && queue[position].Priority {
+		position++
+	}
+	return position
+}
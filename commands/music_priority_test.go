@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestLoadPriorityConfig(t *testing.T) {
+	t.Setenv("MUSIC_PRIORITY_BOOSTERS", "true")
+	t.Setenv("MUSIC_PRIORITY_ROLE_ID", " role_dj ")
+
+	config := LoadPriorityConfig()
+	assert.True(t, config.Boosters)
+	assert.Equal(t, "role_dj", config.RoleID)
+
+	t.Setenv("MUSIC_PRIORITY_BOOSTERS", "sometimes")
+	assert.False(t, LoadPriorityConfig().Boosters, "invalid values fall back to disabled")
+}
+
+func TestPriorityConfigIsPriority(t *testing.T) {
+	boostedSince := time.Now().Add(-24 * time.Hour)
+	booster := &discordgo.Member{PremiumSince: &boostedSince}
+	dj := &discordgo.Member{Roles: []string{"role_other", "role_dj"}}
+	regular := &discordgo.Member{Roles: []string{"role_other"}}
+
+	tests := []struct {
+		name     string
+		config   PriorityConfig
+		member   *discordgo.Member
+		expected bool
+	}{
+		{"disabled", PriorityConfig{}, booster, false},
+		{"booster", PriorityConfig{Boosters: true}, booster, true},
+		{"booster without boost", PriorityConfig{Boosters: true}, regular, false},
+		{"premium role", PriorityConfig{RoleID: "role_dj"}, dj, true},
+		{"missing role", PriorityConfig{RoleID: "role_dj"}, regular, false},
+		{"no member", PriorityConfig{Boosters: true, RoleID: "role_dj"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.IsPriority(tt.member))
+		})
+	}
+}
+
+func TestTrackRequest(t *testing.T) {
+	original := MusicPriority
+	MusicPriority = PriorityConfig{RoleID: "role_dj"}
+	defer func() { MusicPriority = original }()
+
+	interaction := testutils.CreateTestInteraction("play", nil)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser("user_1", "dj", ""))
+	interaction.Member.Roles = []string{"role_dj"}
+
+	request := trackRequest(interaction)
+	assert.Equal(t, "user_1", request.RequestedBy)
+	assert.True(t, request.Priority)
+}
+
+func TestQueuePosition(t *testing.T) {
+	queue := []types.AudioSource{
+		{Title: "p1", Priority: true},
+		{Title: "p2", Priority: true},
+		{Title: "n1"},
+	}
+
+	assert.Equal(t, 2, queuePosition(queue, true))
+	assert.Equal(t, 3, queuePosition(queue, false))
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/types"
+)
+
+// Component handler name and actions for queue pages ("queue:page:<n>" buttons, "queue:jump" select)
+const (
+	queueComponent  = "queue"
+	queueActionPage = "page"
+	queueActionJump = "jump"
+)
+
+// queuePageSize is how many upcoming tracks a queue page lists
+const queuePageSize = 10
+
+// maxQueueJumpOptions is Discord's limit for select menu options
+const maxQueueJumpOptions = 25
+
+// maxQueueTitleLength keeps a full page of titles and requesters under Discord's 1024 character field limit
+const maxQueueTitleLength = 50
+
+func init() {
+	RegisterComponentHandler(queueComponent, handleQueueComponent)
+}
+
+// queuePageCount returns how many pages a queue of the given size spans, at least one
+func queuePageCount(size int) int {
+	if size == 0 {
+		return 1
+	}
+	return (size + queuePageSize - 1) / queuePageSize
+}
+
+// clampQueuePage keeps a 1-based page number within the available pages
+func clampQueuePage(page, pages int) int {
+	if page < 1 {
+		return 1
+	}
+	if page > pages {
+		return pages
+	}
+	return page
+}
+
+// queueDuration sums the durations of queued tracks and counts the tracks without a known length
+func queueDuration(tracks []types.AudioSource) (time.Duration, int) {
+	var total time.Duration
+	unknown := 0
+	for _, track := range tracks {
+		if !track.Duration.Known() {
+			unknown++
+			continue
+		}
+		total += track.Duration.Std()
+	}
+	return total, unknown
+}
+
+// renderQueuePage builds the embed and page controls for one page of a player's queue
+func renderQueuePage(player *music.VoicePlayer, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	tracks := player.GetQueue()
+	page = clampQueuePage(page, queuePageCount(len(tracks)))
+	return createQueueEmbed(player.GetCurrent(), player.Position(), tracks, page), createQueueComponents(page, len(tracks))
+}
+
+// createQueueEmbed lists one page of upcoming tracks with the queue's total duration in the footer. The
+// current track shows how far in it is at position.
+func createQueueEmbed(current *types.AudioSource, position time.Duration, tracks []types.AudioSource, page int) *discordgo.MessageEmbed {
+	embed := NewEmbed("🎵 Music Queue")
+
+	if current != nil {
+		value := fmt.Sprintf("🎶 **%s**", current.Title)
+		if current.Duration.Known() {
+			value += fmt.Sprintf(" `%s / %s`", types.FormatClock(min(position, current.Duration.Std())), current.Duration)
+		}
+		embed.Field("Now Playing", value)
+	}
+
+	if len(tracks) == 0 {
+		return embed.Description("Queue is empty").Build()
+	}
+
+	pages := queuePageCount(len(tracks))
+	page = clampQueuePage(page, pages)
+	start := (page - 1) * queuePageSize
+	end := min(start+queuePageSize, len(tracks))
+
+	lines := make([]string, 0, end-start)
+	for index := start; index < end; index++ {
+		track := tracks[index]
+		marker := ""
+		if track.Priority {
+			marker = "⭐ "
+		}
+		line := fmt.Sprintf("%d. %s**%s**", index+1, marker, truncateText(track.Title, maxQueueTitleLength))
+		if track.Duration.Known() {
+			line += fmt.Sprintf(" `%s`", track.Duration)
+		}
+		if track.RequestedBy != "" {
+			line += " • " + formatRequester(track.RequestedBy)
+		}
+		lines = append(lines, line)
+	}
+	embed.LinesField(fmt.Sprintf("Up Next (%d songs)", len(tracks)), lines)
+
+	total, unknown := queueDuration(tracks)
+	footer := fmt.Sprintf("Page %d/%d • Total duration %s", page, pages, types.FormatClock(total))
+	if unknown > 0 {
+		footer += fmt.Sprintf("+ (%d of unknown length)", unknown)
+	} else if current != nil && current.Duration.Known() {
+		// Everything has a known length, so the queue's end can be worked out
+		footer += fmt.Sprintf(" • Ends in %s", types.FormatClock(total+max(current.Duration.Std()-position, 0)))
+	}
+	return embed.Footer(footer).Build()
+}
+
+// createQueueComponents builds previous/next buttons and a jump-to-page menu for a queue of size tracks,
+// none when it fits on one page
+func createQueueComponents(page, size int) []discordgo.MessageComponent {
+	pages := queuePageCount(size)
+	if pages <= 1 {
+		return []discordgo.MessageComponent{}
+	}
+
+	// The menu shows a window of pages around the current one when there are too many to list
+	first := max(1, min(page-maxQueueJumpOptions/2, pages-maxQueueJumpOptions+1))
+	last := min(pages, first+maxQueueJumpOptions-1)
+
+	options := make([]discordgo.SelectMenuOption, 0, last-first+1)
+	for option := first; option <= last; option++ {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       fmt.Sprintf("Page %d", option),
+			Description: fmt.Sprintf("Songs %d-%d", (option-1)*queuePageSize+1, min(option*queuePageSize, size)),
+			Value:       strconv.Itoa(option),
+			Default:     option == page,
+		})
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Previous",
+					Emoji:    &discordgo.ComponentEmoji{Name: "◀️"},
+					Style:    discordgo.SecondaryButton,
+					CustomID: ComponentID(queueComponent, queueActionPage, strconv.Itoa(page-1)),
+					Disabled: page <= 1,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Emoji:    &discordgo.ComponentEmoji{Name: "▶️"},
+					Style:    discordgo.SecondaryButton,
+					CustomID: ComponentID(queueComponent, queueActionPage, strconv.Itoa(page+1)),
+					Disabled: page >= pages,
+				},
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    ComponentID(queueComponent, queueActionJump),
+					Placeholder: "Jump to page",
+					Options:     options,
+				},
+			},
+		},
+	}
+}
+
+// handleQueueComponent turns the queue message to another page, reading the live queue
+func handleQueueComponent(s SessionInterface, i *discordgo.InteractionCreate, args []string) error {
+	var pageText string
+	switch {
+	case len(args) == 2 && args[0] == queueActionPage:
+		pageText = args[1]
+	case len(args) == 1 && args[0] == queueActionJump && len(i.MessageComponentData().Values) > 0:
+		pageText = i.MessageComponentData().Values[0]
+	default:
+		return respondWithEphemeral(s, i, translate(i, "queue.invalid_control"))
+	}
+
+	page, err := strconv.Atoi(pageText)
+	if err != nil {
+		return respondWithEphemeral(s, i, translate(i, "queue.invalid_page"))
+	}
+
+	if SimplePlayer == nil {
+		return respondWithEphemeral(s, i, translate(i, "music.unavailable"))
+	}
+
+	player, connected := SimplePlayer.GetPlayer(i.GuildID)
+	if !connected {
+		return respondWithEphemeral(s, i, translate(i, "music.not_connected"))
+	}
+
+	embed, components := renderQueuePage(player, page)
+	data := embedResponse(i, embed)
+	data.Components = components
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: data,
+	})
+}
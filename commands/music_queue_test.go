@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/testutils"
+)
+
+func createTestQueue(size int) []types.AudioSource {
+	tracks := make([]types.AudioSource, size)
+	for index := range tracks {
+		tracks[index] = types.AudioSource{Title: fmt.Sprintf("Song %d", index+1), Duration: types.Seconds(180)}
+	}
+	return tracks
+}
+
+func TestQueuePageCount(t *testing.T) {
+	assert.Equal(t, 1, queuePageCount(0))
+	assert.Equal(t, 1, queuePageCount(10))
+	assert.Equal(t, 2, queuePageCount(11))
+	assert.Equal(t, 50, queuePageCount(500))
+
+	assert.Equal(t, 1, clampQueuePage(0, 3))
+	assert.Equal(t, 3, clampQueuePage(7, 3))
+	assert.Equal(t, 2, clampQueuePage(2, 3))
+}
+
+func TestQueueDuration(t *testing.T) {
+	tracks := []types.AudioSource{{Duration: types.Seconds(210)}, {Duration: types.Seconds(3600)}, {}, {Live: true}}
+	total, unknown := queueDuration(tracks)
+	assert.Equal(t, time.Hour+3*time.Minute+30*time.Second, total)
+	assert.Equal(t, 2, unknown)
+}
+
+func TestCreateQueueEmbed(t *testing.T) {
+	tracks := createTestQueue(25)
+	tracks[12].Priority = true
+	tracks[13].Title = strings.Repeat("Long title ", 20)
+	tracks[24].Duration = 0
+	tracks[11].RequestedBy = "123456789012345678"
+
+	embed := createQueueEmbed(&types.AudioSource{Title: "Current"}, 0, tracks, 2)
+	require.Len(t, embed.Fields, 2)
+	assert.Contains(t, embed.Fields[0].Value, "Current")
+	assert.Equal(t, "Up Next (25 songs)", embed.Fields[1].Name)
+
+	lines := strings.Split(strings.TrimSpace(embed.Fields[1].Value), "\n")
+	require.Len(t, lines, queuePageSize)
+	assert.True(t, strings.HasPrefix(lines[0], "11. **Song 11**"))
+	assert.Contains(t, lines[2], "13. ⭐ **Song 13**")
+	assert.Contains(t, lines[3], "…")
+	assert.True(t, strings.HasSuffix(lines[1], " • <@123456789012345678>"))
+	assert.Equal(t, "Page 2/3 • Total duration 1:12:00+ (1 of unknown length)", embed.Footer.Text)
+
+	// Out of range pages show the last page
+	last := createQueueEmbed(nil, 0, tracks, 9)
+	require.Len(t, last.Fields, 1)
+	assert.True(t, strings.HasPrefix(last.Fields[0].Value, "21. **Song 21**"))
+	assert.Contains(t, last.Footer.Text, "Page 3/3")
+
+	empty := createQueueEmbed(nil, 0, nil, 1)
+	assert.Equal(t, "Queue is empty", empty.Description)
+	assert.Nil(t, empty.Footer)
+}
+
+func TestCreateQueueEmbedShowsPositionAndEnd(t *testing.T) {
+	current := &types.AudioSource{Title: "Current", Duration: types.Seconds(200)}
+	embed := createQueueEmbed(current, 80*time.Second, createTestQueue(2), 1)
+	assert.Equal(t, "🎶 **Current** `1:20 / 3:20`", embed.Fields[0].Value)
+	assert.Equal(t, "Page 1/1 • Total duration 6:00 • Ends in 8:00", embed.Footer.Text)
+}
+
+func TestCreateQueueComponents(t *testing.T) {
+	assert.Empty(t, createQueueComponents(1, 10), "a single page has no controls")
+
+	components := createQueueComponents(1, 25)
+	require.Len(t, components, 2)
+
+	buttons := components[0].(discordgo.ActionsRow).Components
+	previous, next := buttons[0].(discordgo.Button), buttons[1].(discordgo.Button)
+	assert.True(t, previous.Disabled)
+	assert.False(t, next.Disabled)
+	assert.Equal(t, ComponentID(queueComponent, queueActionPage, "2"), next.CustomID)
+	assert.NotEqual(t, previous.CustomID, next.CustomID)
+
+	menu := components[1].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	assert.Equal(t, ComponentID(queueComponent, queueActionJump), menu.CustomID)
+	require.Len(t, menu.Options, 3)
+	assert.True(t, menu.Options[0].Default)
+	assert.Equal(t, "Songs 21-25", menu.Options[2].Description)
+
+	lastPage := createQueueComponents(3, 25)[0].(discordgo.ActionsRow).Components
+	assert.False(t, lastPage[0].(discordgo.Button).Disabled)
+	assert.True(t, lastPage[1].(discordgo.Button).Disabled)
+}
+
+func TestCreateQueueComponentsWindow(t *testing.T) {
+	// 50 pages are more than a select menu can list
+	menuFor := func(page int) discordgo.SelectMenu {
+		return createQueueComponents(page, 500)[1].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	}
+
+	first := menuFor(1)
+	require.Len(t, first.Options, maxQueueJumpOptions)
+	assert.Equal(t, "1", first.Options[0].Value)
+
+	middle := menuFor(30)
+	require.Len(t, middle.Options, maxQueueJumpOptions)
+	assert.Equal(t, "18", middle.Options[0].Value)
+	assert.True(t, middle.Options[12].Default)
+
+	last := menuFor(50)
+	require.Len(t, last.Options, maxQueueJumpOptions)
+	assert.Equal(t, "50", last.Options[maxQueueJumpOptions-1].Value)
+}
+
+func TestHandleQueueComponent(t *testing.T) {
+	tests := []struct {
+		name          string
+		customID      string
+		values        []string
+		expectContent string
+	}{
+		{
+			name:          "malformed control",
+			customID:      ComponentID(queueComponent, queueActionPage),
+			expectContent: "Invalid queue control",
+		},
+		{
+			name:          "non-numeric page",
+			customID:      ComponentID(queueComponent, queueActionPage, "next"),
+			expectContent: "Invalid queue page",
+		},
+		{
+			name:          "jump without music system",
+			customID:      ComponentID(queueComponent, queueActionJump),
+			values:        []string{"2"},
+			expectContent: "Music system is not available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSession := &testutils.MockSession{}
+			interaction := testutils.CreateTestComponentInteraction(tt.customID, tt.values...)
+
+			err := HandleComponentInteraction(mockSession, interaction)
+			require.NoError(t, err)
+
+			assert.Equal(t, discordgo.InteractionResponseChannelMessageWithSource, mockSession.RespondType)
+			assert.Contains(t, mockSession.RespondData.Content, tt.expectContent)
+		})
+	}
+}
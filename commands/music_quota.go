@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// requestQuotaWindow is the sliding window song requests are counted over
+const requestQuotaWindow = time.Hour
+
+// voterQuotaMultiplier is how many times the usual quota members get while their vote perk lasts
+const voterQuotaMultiplier = 2
+
+// RequestQuota limits how many songs a member can request with /play per hour. Members who voted for the
+// bot on a bot list get voterQuotaMultiplier times as many. A limit of 0 means no limit.
+type RequestQuota struct {
+	limit int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	requests  map[string][]time.Time // Request times within the window by user ID, oldest first
+	lastSweep time.Time
+}
+
+// MusicQuota is the active request quota, loaded when the music player is initialized
+var MusicQuota = NewRequestQuota(0)
+
+// NewRequestQuota creates a quota of limit requests per member per hour
+func NewRequestQuota(limit int) *RequestQuota {
+	return &RequestQuota{limit: max(0, limit), now: time.Now, requests: make(map[string][]time.Time)}
+}
+
+// LoadRequestQuota reads MUSIC_REQUEST_QUOTA, songs a member can request per hour, from the environment
+func LoadRequestQuota() *RequestQuota {
+	raw := strings.TrimSpace(os.Getenv("MUSIC_REQUEST_QUOTA"))
+	if raw == "" {
+		return NewRequestQuota(0)
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 0 {
+		utils.LogWarn("Ignoring invalid value %q for MUSIC_REQUEST_QUOTA", raw)
+		return NewRequestQuota(0)
+	}
+	return NewRequestQuota(limit)
+}
+
+// Limit returns how many songs a member can request per hour, 0 when there is no limit
+func (q *RequestQuota) Limit(voter bool) int {
+	if voter {
+		return q.limit * voterQuotaMultiplier
+	}
+	return q.limit
+}
+
+// Take counts a request by a member. When the member is out of requests it returns false and when the
+// oldest request in the window stops counting.
+func (q *RequestQuota) Take(userID string, voter bool) (time.Time, bool) {
+	if q.limit == 0 {
+		return time.Time{}, true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	q.sweep(now)
+	recent := q.recent(userID, now)
+	if len(recent) >= q.Limit(voter) {
+		return recent[0].Add(requestQuotaWindow), false
+	}
+	q.requests[userID] = append(recent, now)
+	return time.Time{}, true
+}
+
+// recent drops a member's requests older than the window and returns the rest (caller holds the lock)
+func (q *RequestQuota) recent(userID string, now time.Time) []time.Time {
+	requests := q.requests[userID]
+	for len(requests) > 0 && !now.Before(requests[0].Add(requestQuotaWindow)) {
+		requests = requests[1:]
+	}
+	if len(requests) == 0 {
+		delete(q.requests, userID)
+		return nil
+	}
+	q.requests[userID] = requests
+	return requests
+}
+
+// sweep forgets members without recent requests once per window, so the map doesn't keep every member
+// who ever played a song (caller holds the lock)
+func (q *RequestQuota) sweep(now time.Time) {
+	if now.Sub(q.lastSweep) < requestQuotaWindow {
+		return
+	}
+	q.lastSweep = now
+	for userID := range q.requests {
+		q.recent(userID, now)
+	}
+}
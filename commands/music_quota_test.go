@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestQuotaSlidingWindow(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	quota := NewRequestQuota(2)
+	quota.now = func() time.Time { return now }
+
+	first := now
+	for range 2 {
+		_, ok := quota.Take("user_1", false)
+		assert.True(t, ok)
+		now = now.Add(10 * time.Minute)
+	}
+	retryAt, ok := quota.Take("user_1", false)
+	assert.False(t, ok)
+	assert.Equal(t, first.Add(requestQuotaWindow), retryAt, "the oldest request frees up first")
+
+	_, ok = quota.Take("user_2", false)
+	assert.True(t, ok, "members have their own quota")
+
+	now = first.Add(requestQuotaWindow)
+	_, ok = quota.Take("user_1", false)
+	assert.True(t, ok)
+}
+
+func TestRequestQuotaVoters(t *testing.T) {
+	quota := NewRequestQuota(1)
+	assert.Equal(t, 2, quota.Limit(true))
+
+	_, ok := quota.Take("user_1", true)
+	assert.True(t, ok)
+	_, ok = quota.Take("user_1", true)
+	assert.True(t, ok, "voters get twice the quota")
+	_, ok = quota.Take("user_1", true)
+	assert.False(t, ok)
+}
+
+func TestRequestQuotaUnlimited(t *testing.T) {
+	quota := NewRequestQuota(0)
+	for range 100 {
+		_, ok := quota.Take("user_1", false)
+		assert.True(t, ok)
+	}
+	assert.Empty(t, quota.requests)
+}
+
+func TestLoadRequestQuota(t *testing.T) {
+	t.Setenv("MUSIC_REQUEST_QUOTA", "")
+	assert.Equal(t, 0, LoadRequestQuota().Limit(false))
+
+	t.Setenv("MUSIC_REQUEST_QUOTA", "-3")
+	assert.Equal(t, 0, LoadRequestQuota().Limit(false))
+
+	t.Setenv("MUSIC_REQUEST_QUOTA", " 20 ")
+	assert.Equal(t, 20, LoadRequestQuota().Limit(false))
+}
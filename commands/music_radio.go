@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/radio"
+	"pxnx-discord-bot/music/types"
+)
+
+// radioMatches is how many stations a /music radio search looks at; the best one plays, the rest are suggested
+const radioMatches = 5
+
+// HandleRadioCommand handles the /music radio command: it finds an internet radio station by name in the
+// radio-browser.info directory and queues it as a live stream. The directory lookup can take a few seconds,
+// so the command is registered as slow.
+func HandleRadioCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithError(s, i, translate(i, "music.unavailable"))
+	}
+
+	var name string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "station" {
+			name = strings.TrimSpace(option.StringValue())
+		}
+	}
+
+	player, connected := SimplePlayer.GetPlayer(i.GuildID)
+	if !connected {
+		return respondWithError(s, i, translate(i, "music.join_first"))
+	}
+
+	// The now-playing message follows the channel music is requested from
+	NowPlaying.follow(i.GuildID, i.ChannelID)
+
+	ctx, cancel := context.WithTimeout(RequestContext(i), 15*time.Second)
+	defer cancel()
+	stations, err := SimplePlayer.SearchRadio(ctx, name, radioMatches)
+	if errors.Is(err, radio.ErrNoStations) {
+		return respondWithError(s, i, translate(i, "radio.not_found", name))
+	}
+	if err != nil {
+		return respondWithError(s, i, translate(i, "radio.search_failed", err))
+	}
+
+	station := stations[0]
+	track := station.Track()
+	request := trackRequest(i)
+	if err := SimplePlayer.Enqueue(i.GuildID, []types.AudioSource{track}, request); err != nil {
+		return respondWithError(s, i, translate(i, "radio.play_failed", err))
+	}
+	track.RequestedBy = request.RequestedBy
+	track.Priority = request.Priority
+
+	content, title, color := translate(i, "radio.now_playing"), translate(i, "track.now_playing"), 0x1db954 // Spotify green
+	if player.IsPlaying() {
+		content = translate(i, "radio.queued", queuePosition(player.GetQueue(), track.Priority))
+		title, color = translate(i, "track.queued"), 0x3498db // Blue
+	}
+
+	embed := createTrackEmbed(i, &track, title, color)
+	if others := formatOtherStations(stations[1:]); others != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: translate(i, "radio.other_matches"), Value: others})
+	}
+
+	return NewInteractionResponder(s, i).Edit(content, embed)
+}
+
+// formatStationDetails describes where a station is from, what it plays and its stream quality
+func formatStationDetails(station radio.Station) string {
+	var details []string
+	if station.Country != "" {
+		details = append(details, "🌍 "+station.Country)
+	}
+	if tags := station.TagList(3); len(tags) > 0 {
+		details = append(details, "🏷️ "+strings.Join(tags, ", "))
+	}
+	switch {
+	case station.Codec != "" && station.Bitrate > 0:
+		details = append(details, fmt.Sprintf("🎧 %s %d kbps", station.Codec, station.Bitrate))
+	case station.Codec != "":
+		details = append(details, "🎧 "+station.Codec)
+	}
+	if station.Homepage != "" {
+		details = append(details, fmt.Sprintf("🔗 [Homepage](%s)", station.Homepage))
+	}
+
+	if len(details) == 0 {
+		return "Internet radio"
+	}
+	return strings.Join(details, "\n")
+}
+
+// formatOtherStations lists the other matches of a search so a more exact name can be tried
+func formatOtherStations(stations []radio.Station) string {
+	var lines []string
+	for _, station := range stations {
+		line := "• " + strings.TrimSpace(station.Name)
+		if station.Country != "" {
+			line += " (" + station.Country + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/radio"
+)
+
+func TestCreateTrackEmbedForRadio(t *testing.T) {
+	station := radio.Station{
+		Name:     "Jazz FM",
+		URL:      "http://jazz.example/stream",
+		Homepage: "https://jazz.example",
+		Tags:     "jazz,smooth jazz,lounge,chill",
+		Country:  "Latvia",
+		Codec:    "MP3",
+		Bitrate:  128,
+	}
+	track := station.Track()
+	embed := createTrackEmbed(newPlayInteraction(), &track, "Now Playing", 0x1db954)
+
+	require.Len(t, embed.Fields, 4)
+	assert.Equal(t, "🔴 Live", embed.Fields[0].Value)
+	assert.Equal(t, "Internet radio", embed.Fields[1].Value)
+	assert.Equal(t, "🌍 Latvia\n🏷️ jazz, smooth jazz, lounge\n🎧 MP3 128 kbps\n🔗 [Homepage](https://jazz.example)", embed.Fields[3].Value)
+
+	nowPlaying := createNowPlayingEmbed(&track, false, 0)
+	assert.Equal(t, "🔴 Live", nowPlaying.Fields[0].Value)
+	assert.Equal(t, "Station", nowPlaying.Fields[len(nowPlaying.Fields)-1].Name)
+}
+
+func TestFormatStationDetails(t *testing.T) {
+	assert.Equal(t, "Internet radio", formatStationDetails(radio.Station{Name: "Mystery"}))
+	assert.Equal(t, "🎧 AAC", formatStationDetails(radio.Station{Codec: "AAC"}))
+}
+
+func TestFormatOtherStations(t *testing.T) {
+	assert.Empty(t, formatOtherStations(nil))
+	assert.Equal(t, "• Jazz Radio (France)\n• Jazz 24", formatOtherStations([]radio.Station{
+		{Name: "Jazz Radio", Country: "France"},
+		{Name: " Jazz 24 "},
+	}))
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/queue"
+)
+
+// HandleMoveCommand handles the /music queue move command, moving a queued song to another position
+func HandleMoveCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	player, titles, from, to, ok, err := prepareReorder(s, i, "from", "to")
+	if !ok {
+		return err
+	}
+
+	if err := player.MoveInQueue(from-1, to-1); err != nil {
+		return respondWithInteraction(s, i, describeReorderError(err, len(titles)))
+	}
+	return respondWithInteraction(s, i, translate(i, "queue.moved", titles[from-1], to))
+}
+
+// HandleSwapCommand handles the /music queue swap command, exchanging two queued songs
+func HandleSwapCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	player, titles, a, b, ok, err := prepareReorder(s, i, "a", "b")
+	if !ok {
+		return err
+	}
+
+	if err := player.SwapInQueue(a-1, b-1); err != nil {
+		return respondWithInteraction(s, i, describeReorderError(err, len(titles)))
+	}
+	return respondWithInteraction(s, i, translate(i, "queue.swapped", titles[a-1], titles[b-1]))
+}
+
+// prepareReorder reads two 1-based queue positions and checks them against the current queue.
+// When it returns false it has already responded, and err is the result of that response.
+func prepareReorder(s SessionInterface, i *discordgo.InteractionCreate, firstOption, secondOption string) (*music.VoicePlayer, []string, int, int, bool, error) {
+	if SimplePlayer == nil {
+		return nil, nil, 0, 0, false, respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	var first, second int
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case firstOption:
+			first = int(option.IntValue())
+		case secondOption:
+			second = int(option.IntValue())
+		}
+	}
+
+	player, connected := SimplePlayer.GetPlayer(i.GuildID)
+	if !connected {
+		return nil, nil, 0, 0, false, respondWithInteraction(s, i, translate(i, "music.not_connected"))
+	}
+
+	queued := player.GetQueue()
+	titles := make([]string, len(queued))
+	for index, track := range queued {
+		titles[index] = track.Title
+	}
+
+	for _, position := range []int{first, second} {
+		if position < 1 || position > len(titles) {
+			return nil, nil, 0, 0, false, respondWithInteraction(s, i, describeReorderError(nil, len(titles)))
+		}
+	}
+	return player, titles, first, second, true, nil
+}
+
+// describeReorderError explains why a move or swap was refused
+func describeReorderError(err error, size int) string {
+	switch {
+	case errors.Is(err, queue.ErrCrossesPriority):
+		return "❌ ⭐ Priority requests stay ahead of normal requests, reorder them within their own group"
+	case size == 0:
+		return "The queue is empty"
+	default:
+		return fmt.Sprintf("❌ Positions must be between 1 and %d (see /music queue show)", size)
+	}
+}
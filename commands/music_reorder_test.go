@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/queue"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestDescribeReorderError(t *testing.T) {
+	assert.Contains(t, describeReorderError(queue.ErrCrossesPriority, 5), "Priority requests stay ahead")
+	assert.Equal(t, "The queue is empty", describeReorderError(nil, 0))
+	assert.Equal(t, "❌ Positions must be between 1 and 5 (see /music queue show)", describeReorderError(errors.New("position 7 out of range"), 5))
+}
+
+func TestReorderCommandsWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleMoveCommand(mockSession, testutils.CreateTestInteraction("move", nil)))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+
+	mockSession.Reset()
+	require.NoError(t, HandleSwapCommand(mockSession, testutils.CreateTestInteraction("swap", nil)))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
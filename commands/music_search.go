@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/providers"
+	"pxnx-discord-bot/music/types"
+)
+
+// Component handler name and actions for search result pickers ("search:<action>:<userID>", with
+// ":<provider>" added for providers other than YouTube)
+const (
+	searchComponent    = "search"
+	searchActionPick   = "pick"
+	searchActionCancel = "cancel"
+)
+
+// searchResultCount is how many search results are offered in the picker
+const searchResultCount = 5
+
+// maxSelectTextLength is Discord's limit for select option labels, descriptions and values
+const maxSelectTextLength = 100
+
+func init() {
+	RegisterComponentHandler(searchComponent, handleSearchComponent)
+}
+
+// handleSearchResults shows a provider's top search results for a query with a select menu to pick one
+func handleSearchResults(s SessionInterface, i *discordgo.InteractionCreate, query, provider string) error {
+	responder := NewInteractionResponder(s, i)
+	if err := responder.Edit("🔍 Searching for music..."); err != nil {
+		return fmt.Errorf("failed to update response: %w", err)
+	}
+
+	results, err := SimplePlayer.SearchWith(extractionContext(i, responder), provider, query, searchResultCount)
+	if errors.Is(err, providers.ErrQueueFull) {
+		return respondWithError(s, i, translate(i, "play.extraction_queue_full"))
+	}
+	if err != nil {
+		return respondWithError(s, i, translate(i, "search.failed", err))
+	}
+	if len(results) == 0 {
+		return respondWithError(s, i, translate(i, "search.nothing_found", query))
+	}
+
+	userID := getInteractionUserID(i)
+	components := createSearchComponents(results, userID, provider)
+	content, embeds := contentFor(userID, "Pick a track to play:", []*discordgo.MessageEmbed{createSearchResultsEmbed(query, results)})
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &content,
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	return err
+}
+
+// createSearchResultsEmbed lists search results with their uploader and duration
+func createSearchResultsEmbed(query string, results []types.AudioSource) *discordgo.MessageEmbed {
+	var description strings.Builder
+	for index, result := range results {
+		description.WriteString(fmt.Sprintf("**%d.** [%s](%s)", index+1, result.Title, result.URL))
+		if details := searchResultDetails(result); details != "" {
+			description.WriteString(fmt.Sprintf("\n%s", details))
+		}
+		description.WriteString("\n")
+	}
+
+	return NewEmbed(fmt.Sprintf("🔍 Results for \"%s\"", query)).
+		Description(description.String()).
+		Footer("Only the user who searched can pick a result").
+		Build()
+}
+
+// createSearchComponents builds the result select menu and a cancel button for the searching user. The
+// pick remembers the provider so the chosen link is played by the provider that found it.
+func createSearchComponents(results []types.AudioSource, userID, provider string) []discordgo.MessageComponent {
+	options := make([]discordgo.SelectMenuOption, 0, len(results))
+	for index, result := range results {
+		// The URL is the option value so the pick can be served after a restart
+		if len(result.URL) > maxSelectTextLength {
+			continue
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       truncateText(fmt.Sprintf("%d. %s", index+1, result.Title), maxSelectTextLength),
+			Description: truncateText(searchResultDetails(result), maxSelectTextLength),
+			Value:       result.URL,
+		})
+	}
+
+	pickID := ComponentID(searchComponent, searchActionPick, userID)
+	if provider != "" && provider != music.YouTubeProvider {
+		pickID = ComponentID(searchComponent, searchActionPick, userID, provider)
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    pickID,
+					Placeholder: "Choose a track",
+					Options:     options,
+				},
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Cancel",
+					Style:    discordgo.SecondaryButton,
+					CustomID: ComponentID(searchComponent, searchActionCancel, userID),
+				},
+			},
+		},
+	}
+}
+
+// searchResultDetails formats the uploader and duration of a result
+func searchResultDetails(result types.AudioSource) string {
+	var details []string
+	if result.Uploader != "" {
+		details = append(details, result.Uploader)
+	}
+	if result.Duration.Known() {
+		details = append(details, result.Duration.String())
+	}
+	return strings.Join(details, " • ")
+}
+
+// handleSearchComponent handles picking a search result or cancelling the picker
+func handleSearchComponent(s SessionInterface, i *discordgo.InteractionCreate, args []string) error {
+	if len(args) != 2 && len(args) != 3 {
+		return respondWithEphemeral(s, i, translate(i, "search.invalid_control"))
+	}
+	action, ownerID := args[0], args[1]
+	var provider string
+	if len(args) == 3 {
+		provider = args[2]
+	}
+
+	if getInteractionUserID(i) != ownerID {
+		return respondWithEphemeral(s, i, translate(i, "search.not_yours"))
+	}
+
+	if action == searchActionCancel {
+		return updateComponentMessage(s, i, "❎ Search cancelled")
+	}
+
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return respondWithEphemeral(s, i, translate(i, "search.nothing_selected"))
+	}
+
+	if SimplePlayer == nil {
+		return updateComponentMessage(s, i, "❌ Music system is not available")
+	}
+
+	player, connected := SimplePlayer.GetPlayer(i.GuildID)
+	if !connected {
+		return updateComponentMessage(s, i, "❌ I need to be in a voice channel first. Use `/music join`")
+	}
+
+	// Remove the picker right away, extraction continues by editing the same message
+	if err := updateComponentMessage(s, i, "🔍 Loading your pick..."); err != nil {
+		return err
+	}
+
+	return playQuery(s, i, player, values[0], provider)
+}
+
+// updateComponentMessage replaces the message a component is attached to with plain text and no components
+func updateComponentMessage(s SessionInterface, i *discordgo.InteractionCreate, content string) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Embeds:     []*discordgo.MessageEmbed{},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
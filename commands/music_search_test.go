@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/testutils"
+)
+
+func createTestSearchResults() []types.AudioSource {
+	return []types.AudioSource{
+		{Title: "Song One", URL: "https://www.youtube.com/watch?v=one", Uploader: "Artist A", Duration: types.Seconds(225)},
+		{Title: strings.Repeat("Long title ", 20), URL: "https://www.youtube.com/watch?v=two"},
+		{Title: "Too long URL", URL: "https://www.youtube.com/watch?v=" + strings.Repeat("x", 100)},
+	}
+}
+
+func TestCreateSearchComponents(t *testing.T) {
+	components := createSearchComponents(createTestSearchResults(), "user_1", "")
+	require.Len(t, components, 2)
+
+	menu := components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	assert.Equal(t, ComponentID(searchComponent, searchActionPick, "user_1"), menu.CustomID)
+	require.Len(t, menu.Options, 2, "results with URLs over the value limit are skipped")
+	assert.Equal(t, "1. Song One", menu.Options[0].Label)
+	assert.Equal(t, "Artist A • 3:45", menu.Options[0].Description)
+	assert.Equal(t, "https://www.youtube.com/watch?v=one", menu.Options[0].Value)
+	assert.LessOrEqual(t, len([]rune(menu.Options[1].Label)), maxSelectTextLength)
+
+	button := components[1].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	assert.Equal(t, ComponentID(searchComponent, searchActionCancel, "user_1"), button.CustomID)
+
+	// Picks from other providers are played by the provider that found them
+	components = createSearchComponents(createTestSearchResults(), "user_1", "radio")
+	menu = components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	assert.Equal(t, ComponentID(searchComponent, searchActionPick, "user_1", "radio"), menu.CustomID)
+}
+
+func TestCreateSearchResultsEmbed(t *testing.T) {
+	embed := createSearchResultsEmbed("lofi", createTestSearchResults()[:1])
+	assert.Contains(t, embed.Title, "lofi")
+	assert.Contains(t, embed.Description, "[Song One](https://www.youtube.com/watch?v=one)")
+	assert.Contains(t, embed.Description, "Artist A • 3:45")
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "short", truncateText("short", 10))
+	assert.Equal(t, "abcd…", truncateText("abcdefgh", 5))
+}
+
+func TestHandleSearchComponent(t *testing.T) {
+	tests := []struct {
+		name          string
+		customID      string
+		presserID     string
+		values        []string
+		expectType    discordgo.InteractionResponseType
+		expectContent string
+	}{
+		{
+			name:          "other user cannot pick",
+			customID:      ComponentID(searchComponent, searchActionPick, "user_1"),
+			presserID:     "user_2",
+			values:        []string{"https://www.youtube.com/watch?v=one"},
+			expectType:    discordgo.InteractionResponseChannelMessageWithSource,
+			expectContent: "Only the user who searched",
+		},
+		{
+			name:          "cancel removes the picker",
+			customID:      ComponentID(searchComponent, searchActionCancel, "user_1"),
+			presserID:     "user_1",
+			expectType:    discordgo.InteractionResponseUpdateMessage,
+			expectContent: "cancelled",
+		},
+		{
+			name:          "pick without music system",
+			customID:      ComponentID(searchComponent, searchActionPick, "user_1"),
+			presserID:     "user_1",
+			values:        []string{"https://www.youtube.com/watch?v=one"},
+			expectType:    discordgo.InteractionResponseUpdateMessage,
+			expectContent: "Music system is not available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSession := &testutils.MockSession{}
+			interaction := testutils.CreateTestComponentInteraction(tt.customID, tt.values...)
+			interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser(tt.presserID, "testuser", "avatar"))
+
+			err := HandleComponentInteraction(mockSession, interaction)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectType, mockSession.RespondType)
+			assert.Contains(t, mockSession.RespondData.Content, tt.expectContent)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/radio"
+)
+
+// HandleMusicSettingsCommand handles /music settings: it changes the timeouts, search provider, channel
+// status, nickname, voice control and DJ intros that are given and shows the server's music settings
+func HandleMusicSettingsCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	var saveErr error
+	var notices []string
+	for _, option := range i.ApplicationCommandData().Options {
+		var err error
+		switch option.Name {
+		case "alone_timeout":
+			err = SimplePlayer.SetAloneTimeout(i.GuildID, time.Duration(option.IntValue())*time.Second)
+		case "idle_timeout":
+			err = SimplePlayer.SetIdleTimeout(i.GuildID, time.Duration(option.IntValue())*time.Minute)
+		case "search_provider":
+			err = SimplePlayer.SetSearchProvider(i.GuildID, option.StringValue())
+		case "channel_status":
+			err = SimplePlayer.SetChannelStatus(i.GuildID, option.BoolValue())
+		case "nickname":
+			err = SimplePlayer.SetNowPlayingNickname(i.GuildID, option.BoolValue())
+		case "voice_control":
+			err = SimplePlayer.SetVoiceControl(i.GuildID, option.BoolValue())
+			// The bot hears the channel only when it joins undeafened
+			if _, connected := SimplePlayer.GetPlayer(i.GuildID); connected {
+				notices = append(notices, "🎙️ Voice control changes the next time the bot joins a voice channel")
+			}
+		case "wake_word":
+			err = SimplePlayer.SetWakeWord(i.GuildID, option.StringValue())
+		case "dj_intros":
+			err = SimplePlayer.SetDJIntros(i.GuildID, option.BoolValue())
+		}
+		if err != nil {
+			saveErr = err
+		}
+	}
+
+	alone, idle := SimplePlayer.Timeouts(i.GuildID)
+	voiceControl, wakeWord := SimplePlayer.VoiceControl(i.GuildID)
+	embed := createMusicSettingsEmbed(musicSettingsView{
+		AloneTimeout:   alone,
+		IdleTimeout:    idle,
+		Loudnorm:       SimplePlayer.Loudnorm(i.GuildID),
+		FairQueue:      SimplePlayer.FairQueue(i.GuildID),
+		StayConnected:  SimplePlayer.StayConnected(i.GuildID),
+		SearchProvider: SimplePlayer.SearchProvider(i.GuildID),
+		Volume:         SimplePlayer.Volume(i.GuildID),
+		ChannelStatus:  SimplePlayer.ChannelStatus(i.GuildID),
+		Nickname:       SimplePlayer.NowPlayingNickname(i.GuildID),
+		VoiceControl:   voiceControl,
+		WakeWord:       wakeWord,
+		CanListen:      SimplePlayer.VoiceControlAvailable(),
+		DJIntros:       SimplePlayer.DJIntros(i.GuildID),
+		CanSpeak:       SimplePlayer.DJIntrosAvailable(),
+	})
+
+	if saveErr != nil {
+		notices = append(notices, "⚠️ The settings could not be saved and reset when the bot restarts")
+	}
+	content, embeds := contentFor(getInteractionUserID(i), strings.Join(notices, "\n"), []*discordgo.MessageEmbed{embed})
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Embeds:  embeds,
+		},
+	})
+}
+
+// musicSettingsView is what /music settings shows
+type musicSettingsView struct {
+	AloneTimeout   time.Duration
+	IdleTimeout    time.Duration
+	Loudnorm       bool
+	FairQueue      bool
+	StayConnected  bool
+	SearchProvider string
+	Volume         int
+	ChannelStatus  bool
+	Nickname       bool
+	VoiceControl   bool
+	WakeWord       string
+	CanListen      bool // Whether speech to text is set up, voice control needs it
+	DJIntros       bool
+	CanSpeak       bool // Whether text to speech is set up, DJ intros need it
+}
+
+// createMusicSettingsEmbed lists a server's music settings and the commands that change them
+func createMusicSettingsEmbed(view musicSettingsView) *discordgo.MessageEmbed {
+	alone := fmt.Sprintf("Leave %s after everyone else does", view.AloneTimeout)
+	idle := "Stay while nothing plays"
+	if view.IdleTimeout > 0 {
+		idle = fmt.Sprintf("Leave after %s with nothing playing", view.IdleTimeout)
+	}
+	if view.StayConnected {
+		alone, idle = "Off in 24/7 mode", "Off in 24/7 mode"
+	}
+
+	return NewEmbed("⚙️ Music Settings").
+		InlineField("Alone Timeout", alone).
+		InlineField("Idle Timeout", idle).
+		InlineField("24/7 Mode", formatOnOff(view.StayConnected)).
+		InlineField("Loudness Normalization", formatOnOff(view.Loudnorm)).
+		InlineField("Fair Queue", formatOnOff(view.FairQueue)).
+		InlineField("Search Provider", formatSearchProvider(view.SearchProvider)).
+		InlineField("Volume", fmt.Sprintf("%d%%", view.Volume)).
+		InlineField("Channel Status", formatOnOff(view.ChannelStatus)).
+		InlineField("Now Playing Nickname", formatOnOff(view.Nickname)).
+		InlineField("Voice Control", formatVoiceControl(view)).
+		InlineField("DJ Intros", formatDJIntros(view)).
+		Footer("Change timeouts, the search provider, the channel status, nickname, voice control and DJ intros with /music settings, the volume with /music volume, the rest with /music 247, /music loudnorm and /music fairqueue").
+		Build()
+}
+
+// formatSearchProvider names the provider /play searches go to
+func formatSearchProvider(provider string) string {
+	if provider == radio.ProviderName {
+		return "Internet radio"
+	}
+	return "YouTube"
+}
+
+// formatVoiceControl describes voice control and the wake word that starts spoken commands
+func formatVoiceControl(view musicSettingsView) string {
+	switch {
+	case !view.VoiceControl:
+		return "Off"
+	case !view.CanListen:
+		return "On, but speech to text isn't set up on this bot"
+	default:
+		return fmt.Sprintf("On, say \"%s\" and a command such as skip or play", view.WakeWord)
+	}
+}
+
+// formatDJIntros describes DJ intros, which need text to speech
+func formatDJIntros(view musicSettingsView) string {
+	switch {
+	case !view.DJIntros:
+		return "Off"
+	case !view.CanSpeak:
+		return "On, but text to speech isn't set up on this bot"
+	default:
+		return "On, the bot introduces each song"
+	}
+}
+
+// formatOnOff renders a toggle setting
+func formatOnOff(enabled bool) string {
+	if enabled {
+		return "On"
+	}
+	return "Off"
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/testutils"
+)
+
+func embedValues(embed *discordgo.MessageEmbed) map[string]string {
+	values := make(map[string]string)
+	for _, field := range embed.Fields {
+		values[field.Name] = field.Value
+	}
+	return values
+}
+
+func TestCreateMusicSettingsEmbed(t *testing.T) {
+	values := embedValues(createMusicSettingsEmbed(musicSettingsView{AloneTimeout: 15 * time.Second, Loudnorm: true}))
+	assert.Equal(t, "Leave 15s after everyone else does", values["Alone Timeout"])
+	assert.Equal(t, "Stay while nothing plays", values["Idle Timeout"])
+	assert.Equal(t, "On", values["Loudness Normalization"])
+	assert.Equal(t, "Off", values["Fair Queue"])
+	assert.Equal(t, "YouTube", values["Search Provider"])
+	assert.Equal(t, "Off", values["Channel Status"])
+	assert.Equal(t, "Off", values["Now Playing Nickname"])
+	assert.Equal(t, "Off", values["Voice Control"])
+	assert.Equal(t, "Off", values["DJ Intros"])
+
+	values = embedValues(createMusicSettingsEmbed(musicSettingsView{AloneTimeout: time.Minute, IdleTimeout: 10 * time.Minute}))
+	assert.Equal(t, "Leave after 10m0s with nothing playing", values["Idle Timeout"])
+
+	values = embedValues(createMusicSettingsEmbed(musicSettingsView{AloneTimeout: time.Minute, IdleTimeout: 10 * time.Minute, StayConnected: true}))
+	assert.Equal(t, "Off in 24/7 mode", values["Alone Timeout"])
+	assert.Equal(t, "Off in 24/7 mode", values["Idle Timeout"])
+
+	values = embedValues(createMusicSettingsEmbed(musicSettingsView{VoiceControl: true, WakeWord: "jukebox", CanListen: true}))
+	assert.Equal(t, `On, say "jukebox" and a command such as skip or play`, values["Voice Control"])
+
+	values = embedValues(createMusicSettingsEmbed(musicSettingsView{DJIntros: true, CanSpeak: true}))
+	assert.Equal(t, "On, the bot introduces each song", values["DJ Intros"])
+}
+
+func TestHandleMusicSettingsCommand(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("musicsettings", []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "alone_timeout", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(60)},
+		{Name: "idle_timeout", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(30)},
+		{Name: "search_provider", Type: discordgo.ApplicationCommandOptionString, Value: "radio"},
+		{Name: "channel_status", Type: discordgo.ApplicationCommandOptionBoolean, Value: true},
+		{Name: "nickname", Type: discordgo.ApplicationCommandOptionBoolean, Value: true},
+		{Name: "voice_control", Type: discordgo.ApplicationCommandOptionBoolean, Value: true},
+		{Name: "wake_word", Type: discordgo.ApplicationCommandOptionString, Value: " Jukebox "},
+		{Name: "dj_intros", Type: discordgo.ApplicationCommandOptionBoolean, Value: true},
+	})
+	require.NoError(t, HandleMusicSettingsCommand(mockSession, interaction))
+
+	alone, idle := SimplePlayer.Timeouts(interaction.GuildID)
+	assert.Equal(t, time.Minute, alone)
+	assert.Equal(t, 30*time.Minute, idle)
+	assert.Equal(t, "radio", SimplePlayer.SearchProvider(interaction.GuildID))
+	assert.True(t, SimplePlayer.ChannelStatus(interaction.GuildID))
+	assert.True(t, SimplePlayer.NowPlayingNickname(interaction.GuildID))
+	voiceControl, wakeWord := SimplePlayer.VoiceControl(interaction.GuildID)
+	assert.True(t, voiceControl)
+	assert.Equal(t, "jukebox", wakeWord)
+	assert.True(t, SimplePlayer.DJIntros(interaction.GuildID))
+
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Equal(t, "Leave 1m0s after everyone else does", embedValues(mockSession.RespondData.Embeds[0])["Alone Timeout"])
+	assert.Equal(t, "On, but speech to text isn't set up on this bot", embedValues(mockSession.RespondData.Embeds[0])["Voice Control"])
+	assert.Equal(t, "On, but text to speech isn't set up on this bot", embedValues(mockSession.RespondData.Embeds[0])["DJ Intros"])
+
+	// Without options the settings are only shown
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleMusicSettingsCommand(mockSession, testutils.CreateTestInteraction("musicsettings", nil)))
+	assert.Equal(t, "Leave after 30m0s with nothing playing", embedValues(mockSession.RespondData.Embeds[0])["Idle Timeout"])
+}
+
+func TestHandleMusicSettingsCommandWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleMusicSettingsCommand(mockSession, testutils.CreateTestInteraction("musicsettings", nil)))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
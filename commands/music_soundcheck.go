@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/utils"
+)
+
+// HandleSoundcheckCommand handles the /music soundcheck command: it plays a generated test tone through the
+// music pipeline to tell voice and encoding problems apart from problems with a song's stream. The tone
+// plays for several seconds before the result is known, so the command is registered as slow.
+func HandleSoundcheckCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithError(s, i, translate(i, "music.unavailable"))
+	}
+
+	played, err := SimplePlayer.Soundcheck(i.GuildID)
+	switch {
+	case errors.Is(err, music.ErrNotConnected):
+		return respondWithError(s, i, translate(i, "music.join_first"))
+	case errors.Is(err, music.ErrPlaying):
+		return respondWithError(s, i, translate(i, "soundcheck.busy"))
+	case errors.Is(err, music.ErrSoundcheckRemote):
+		return respondWithError(s, i, translate(i, "soundcheck.lavalink"))
+	case err != nil:
+		utils.LogWarn("Soundcheck failed in guild %s: %v", i.GuildID, err)
+	}
+
+	return editWithEmbed(s, i, createSoundcheckEmbed(played, err))
+}
+
+// createSoundcheckEmbed reports how a soundcheck went and what to check next
+func createSoundcheckEmbed(played time.Duration, err error) *discordgo.MessageEmbed {
+	tone := fmt.Sprintf("%d Hz for %s", music.SoundcheckFrequency, music.SoundcheckLength)
+
+	if err != nil {
+		return ErrorEmbed("❌ Soundcheck Failed").
+			Descriptionf("The test tone could not be played: %v", err).
+			InlineField("Tone", tone).
+			InlineField("Played", played.Round(100*time.Millisecond).String()).
+			Field("What to check", "FFmpeg must be installed and on the PATH, and the voice connection must be up; try `/music leave` and `/music join`").
+			Build()
+	}
+
+	embed := SuccessEmbed("🔊 Soundcheck Complete")
+	if played < music.SoundcheckLength-time.Second {
+		embed = WarningEmbed("⏹️ Soundcheck Stopped")
+	}
+	return embed.
+		Description("A locally generated tone was encoded and sent to the voice channel, no stream was involved.").
+		InlineField("Tone", tone).
+		InlineField("Played", played.Round(100*time.Millisecond).String()).
+		Field("Heard nothing?", "Check that the bot may speak in the channel, isn't server muted and isn't muted for you; songs that stay silent while the tone plays point at their streams").
+		Build()
+}
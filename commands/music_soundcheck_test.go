@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestCreateSoundcheckEmbed(t *testing.T) {
+	embed := createSoundcheckEmbed(music.SoundcheckLength, nil)
+	assert.Equal(t, "🔊 Soundcheck Complete", embed.Title)
+	assert.Equal(t, "440 Hz for 5s", embed.Fields[0].Value)
+	assert.Equal(t, "5s", embed.Fields[1].Value)
+
+	embed = createSoundcheckEmbed(1200*time.Millisecond, nil)
+	assert.Equal(t, "⏹️ Soundcheck Stopped", embed.Title)
+	assert.Equal(t, "1.2s", embed.Fields[1].Value)
+
+	embed = createSoundcheckEmbed(0, errors.New("failed to start ffmpeg"))
+	assert.Equal(t, "❌ Soundcheck Failed", embed.Title)
+	assert.Contains(t, embed.Description, "failed to start ffmpeg")
+}
+
+func TestHandleSoundcheckCommand(t *testing.T) {
+	original := SimplePlayer
+	defer func() { SimplePlayer = original }()
+
+	SimplePlayer = nil
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleSoundcheckCommand(mockSession, testutils.CreateTestInteraction("soundcheck", nil)))
+	assert.True(t, mockSession.InteractionResponseEditCalled)
+
+	// Without a voice connection nothing plays
+	SimplePlayer = music.NewSimplePlayer(nil)
+	_, err := SimplePlayer.Soundcheck("test_guild_id")
+	assert.ErrorIs(t, err, music.ErrNotConnected)
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleSoundcheckCommand(mockSession, testutils.CreateTestInteraction("soundcheck", nil)))
+	assert.True(t, mockSession.InteractionResponseEditCalled)
+}
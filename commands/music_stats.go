@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/stats"
+	"pxnx-discord-bot/utils"
+)
+
+// musicStatsTopTracks is how many tracks each /music stats list shows
+const musicStatsTopTracks = 5
+
+// HandleMusicStatsCommand handles the /music stats command, showing the server's most played and most skipped tracks
+func HandleMusicStatsCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	played := SimplePlayer.Stats(i.GuildID)
+	if len(played) > musicStatsTopTracks {
+		played = played[:musicStatsTopTracks]
+	}
+	skipped := SimplePlayer.MostSkipped(i.GuildID, musicStatsTopTracks)
+
+	return respondWithEmbed(s, i, createMusicStatsEmbed(played, skipped))
+}
+
+// createMusicStatsEmbed lists the most played tracks and the tracks most often skipped early
+func createMusicStatsEmbed(played, skipped []stats.TrackStats) *discordgo.MessageEmbed {
+	embed := NewEmbed("📊 Music Stats").Color(utils.ColorPurple)
+
+	if len(played) == 0 {
+		return embed.Description("Nothing has been played on this server yet").Build()
+	}
+
+	var mostPlayed strings.Builder
+	for n, track := range played {
+		fmt.Fprintf(&mostPlayed, "`%d.` **%s** - %d plays\n", n+1, track.Track.Title, track.Plays)
+	}
+
+	mostSkipped := "No early skips yet"
+	if len(skipped) > 0 {
+		var lines strings.Builder
+		for n, track := range skipped {
+			fmt.Fprintf(&lines, "`%d.` **%s** - %d of %d plays (%.0f%%)\n",
+				n+1, track.Track.Title, track.Skips, track.Plays, track.SkipRate()*100)
+		}
+		mostSkipped = lines.String()
+	}
+
+	return embed.
+		Field("Most Played", mostPlayed.String()).
+		Field("Most Skipped", mostSkipped).
+		Footer(fmt.Sprintf("Skips count when a song is skipped in its first %.0f%%", stats.EarlySkipThreshold*100)).
+		Build()
+}
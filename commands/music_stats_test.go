@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/stats"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestCreateMusicStatsEmbed(t *testing.T) {
+	played := []stats.TrackStats{
+		{Track: types.AudioSource{Title: "Favourite"}, Plays: 12, Skips: 0},
+		{Track: types.AudioSource{Title: "Annoying"}, Plays: 4, Skips: 3},
+	}
+
+	embed := createMusicStatsEmbed(played, played[1:])
+	require.Len(t, embed.Fields, 2)
+	assert.Contains(t, embed.Fields[0].Value, "`1.` **Favourite** - 12 plays")
+	assert.Contains(t, embed.Fields[1].Value, "`1.` **Annoying** - 3 of 4 plays (75%)")
+	assert.Equal(t, "Skips count when a song is skipped in its first 30%", embed.Footer.Text)
+}
+
+func TestCreateMusicStatsEmbedEmpty(t *testing.T) {
+	embed := createMusicStatsEmbed(nil, nil)
+	assert.Empty(t, embed.Fields)
+	assert.Contains(t, embed.Description, "Nothing has been played")
+
+	noSkips := createMusicStatsEmbed([]stats.TrackStats{{Track: types.AudioSource{Title: "Song"}, Plays: 1}}, nil)
+	require.Len(t, noSkips.Fields, 2)
+	assert.Equal(t, "No early skips yet", noSkips.Fields[1].Value)
+}
+
+func TestHandleMusicStatsCommandWithoutMusic(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = nil
+	defer func() { SimplePlayer = original }()
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("musicstats", nil)
+	require.NoError(t, HandleMusicStatsCommand(mockSession, interaction))
+	assert.Equal(t, "Music system is not available", mockSession.RespondData.Content)
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleMusicVolume handles /music volume: it shows the server's playback volume or changes it
+func handleMusicVolume(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithInteraction(s, i, translate(i, "music.unavailable"))
+	}
+
+	level := 0
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		if option.Name == "level" {
+			level = int(option.IntValue())
+		}
+	}
+	if level == 0 {
+		return respondWithInteraction(s, i, describeVolume(SimplePlayer.Volume(i.GuildID)))
+	}
+
+	if err := SimplePlayer.SetVolume(i.GuildID, level); err != nil {
+		return respondWithInteraction(s, i, translate(i, "music.setting_not_saved", describeVolume(level)))
+	}
+	return respondWithInteraction(s, i, describeVolume(level))
+}
+
+// describeVolume explains the playback volume setting
+func describeVolume(volume int) string {
+	switch {
+	case volume < 100:
+		return fmt.Sprintf("🔉 Volume is %d%%, songs play quieter than their original volume", volume)
+	case volume > 100:
+		return fmt.Sprintf("🔊 Volume is %d%%, songs play louder than their original volume", volume)
+	default:
+		return "🔊 Volume is 100%, songs play at their original volume"
+	}
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	botOwners   = make(map[string]bool)
+	botOwnersMu sync.RWMutex
+)
+
+// SetBotOwners replaces the user IDs allowed to run owner-only commands
+func SetBotOwners(userIDs []string) {
+	botOwnersMu.Lock()
+	defer botOwnersMu.Unlock()
+
+	botOwners = make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if userID = strings.TrimSpace(userID); userID != "" {
+			botOwners[userID] = true
+		}
+	}
+}
+
+// IsBotOwner reports whether a user may run owner-only commands
+func IsBotOwner(userID string) bool {
+	botOwnersMu.RLock()
+	defer botOwnersMu.RUnlock()
+	return botOwners[userID]
+}
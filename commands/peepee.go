@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
 )
 
 // Initialize random seed once for peepee command
@@ -37,6 +39,18 @@ func (sw *sessionWrapper) Channel(channelID string, options ...discordgo.Request
 	return sw.session.Channel(channelID, options...)
 }
 
+func (sw *sessionWrapper) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return sw.session.ChannelMessageSendComplex(channelID, data, options...)
+}
+
+func (sw *sessionWrapper) ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return sw.session.ChannelMessageEditComplex(m, options...)
+}
+
+func (sw *sessionWrapper) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	return sw.session.ChannelMessages(channelID, limit, beforeID, afterID, aroundID, options...)
+}
+
 func (sw *sessionWrapper) State() *discordgo.State {
 	return sw.session.State
 }
@@ -91,14 +105,11 @@ func createPeepeeEmbed(user *discordgo.User) *discordgo.MessageEmbed {
 	randomPhrase := getRandomPhrase(displayName)
 	avatarURL := getUserAvatarURL(user)
 
-	return &discordgo.MessageEmbed{
-		Title:       "PeePee Inspection Time",
-		Description: randomPhrase,
-		Color:       0x3498db, // ColorBlue
-		Thumbnail: &discordgo.MessageEmbedThumbnail{
-			URL: avatarURL,
-		},
-	}
+	return NewEmbed("PeePee Inspection Time").
+		Color(utils.ColorBlue).
+		Description(randomPhrase).
+		Thumbnail(avatarURL).
+		Build()
 }
 
 func getRandomEmoji(s *discordgo.Session, guildID string) string {
@@ -118,12 +129,7 @@ func getRandomEmoji(s *discordgo.Session, guildID string) string {
 func HandlePeepeeCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
 	embed := createPeepeeEmbed(i.Member.User)
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	return respondWithEmbed(s, i, embed)
 }
 
 // HandlePeepeeCommandWithReaction handles the peepee command with emoji reaction
@@ -138,7 +144,7 @@ func HandlePeepeeCommandWithReaction(s *discordgo.Session, i *discordgo.Interact
 	// Add random emoji reaction
 	if i.GuildID != "" {
 		emoji := getRandomEmoji(s, i.GuildID)
-		go func() {
+		utils.SafeGo("commands.peepeeReaction", func() {
 			// Small delay to ensure message is sent
 			time.Sleep(100 * time.Millisecond)
 			// Get the interaction response message
@@ -148,7 +154,7 @@ func HandlePeepeeCommandWithReaction(s *discordgo.Session, i *discordgo.Interact
 					log.Printf("Error adding reaction: %v", err)
 				}
 			}
-		}()
+		})
 	}
 
 	return nil
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"os"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/preferences"
+	"pxnx-discord-bot/utils"
+)
+
+// Preferences records how each member wants the bot to respond to them, set with /preferences
+var Preferences = preferences.New()
+
+// InitializePreferences loads saved preferences from PREFERENCES_FILE
+func InitializePreferences() {
+	store, err := preferences.Load(PreferencesPath())
+	if err != nil {
+		utils.LogWarn("Preferences will not be saved this run: %v", err)
+		store = preferences.New()
+	}
+	Preferences = store
+}
+
+// PreferencesPath returns where preferences are saved, PREFERENCES_FILE or the default
+func PreferencesPath() string {
+	if path := strings.TrimSpace(os.Getenv("PREFERENCES_FILE")); path != "" {
+		return path
+	}
+	return preferences.DefaultPath
+}
+
+// HandlePreferencesCommand handles the /preferences command. Without options it shows the member's
+// preferences; plain_text turns plain text responses on or off for them everywhere.
+func HandlePreferencesCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	userID := getInteractionUserID(i)
+	if userID == "" {
+		return respondWithEphemeral(s, i, translate(i, "preferences.unknown_user"))
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 || options[0].Name != "plain_text" {
+		if Preferences.PlainText(userID) {
+			return respondWithEphemeral(s, i, translate(i, "preferences.plain_text_on"))
+		}
+		return respondWithEphemeral(s, i, translate(i, "preferences.embeds"))
+	}
+
+	enabled := options[0].BoolValue()
+	if err := Preferences.SetPlainText(userID, enabled); err != nil {
+		utils.LogWarnContext(RequestContext(i), "Failed to save preferences of %s: %v", userID, err)
+	}
+	if enabled {
+		return respondWithEphemeral(s, i, translate(i, "preferences.plain_text_enabled"))
+	}
+	return respondWithEphemeral(s, i, translate(i, "preferences.plain_text_disabled"))
+}
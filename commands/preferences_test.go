@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/preferences"
+	"pxnx-discord-bot/testutils"
+)
+
+// usePreferences installs an in-memory preference store for the duration of a test
+func usePreferences(t *testing.T) *preferences.Store {
+	original := Preferences
+	Preferences = preferences.New()
+	t.Cleanup(func() { Preferences = original })
+	return Preferences
+}
+
+func TestHandlePreferencesCommand(t *testing.T) {
+	store := usePreferences(t)
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("preferences", []*discordgo.ApplicationCommandInteractionDataOption{
+		testutils.CreateBooleanOption("plain_text", true),
+	})
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user_1"}}
+
+	require.NoError(t, HandlePreferencesCommand(mockSession, interaction))
+	assert.True(t, store.PlainText("user_1"))
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+	assert.Contains(t, mockSession.RespondData.Content, "Plain text responses are on")
+
+	mockSession = &testutils.MockSession{}
+	interaction = testutils.CreateTestInteraction("preferences", nil)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user_1"}}
+
+	require.NoError(t, HandlePreferencesCommand(mockSession, interaction))
+	assert.Contains(t, mockSession.RespondData.Content, "/preferences plain_text:False")
+}
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/premium"
+	"pxnx-discord-bot/utils"
+)
+
+// premiumCommands maps the commands, or "command subcommand" down to the subcommand of a group, that need
+// a premium feature
+var premiumCommands = map[string]premium.Feature{
+	"music filters toggle": premium.FeatureFilters,
+	"music 247":            premium.FeatureStayConnected,
+}
+
+// premiumFeatureNames are the catalog keys naming premium features in refusals
+var premiumFeatureNames = map[premium.Feature]string{
+	premium.FeatureFilters:       "premium.feature.filters",
+	premium.FeatureStayConnected: "premium.feature.stay_connected",
+}
+
+// LoadPremium reads PREMIUM_ENABLED, PREMIUM_FILE and PREMIUM_SKU_ID from the environment. Tiers are only
+// enforced when PREMIUM_ENABLED is true, otherwise every server gets the premium limits.
+func LoadPremium() *premium.Entitlements {
+	raw := strings.TrimSpace(os.Getenv("PREMIUM_ENABLED"))
+	if raw == "" {
+		return premium.New()
+	}
+	enabled, err := strconv.ParseBool(raw)
+	if err != nil {
+		utils.LogWarn("Ignoring invalid value %q for PREMIUM_ENABLED", raw)
+	}
+	if !enabled {
+		return premium.New()
+	}
+
+	path := PremiumPath()
+	entitlements, err := premium.Load(path, strings.TrimSpace(os.Getenv("PREMIUM_SKU_ID")))
+	if err != nil {
+		// Refusing every grant would lock paying servers out, so gating stays off until the file is fixed
+		utils.LogError("Premium tiers are disabled this run: %v", err)
+		return premium.New()
+	}
+	utils.LogInfo("Premium tiers enabled (grants in %s)", path)
+	return entitlements
+}
+
+// PremiumPath returns where manual premium grants are saved, PREMIUM_FILE or the default
+func PremiumPath() string {
+	if path := strings.TrimSpace(os.Getenv("PREMIUM_FILE")); path != "" {
+		return path
+	}
+	return premium.DefaultPath
+}
+
+// ApplyEntitlement records a Discord entitlement for the premium SKU
+func ApplyEntitlement(entitlement *discordgo.Entitlement, deleted bool) {
+	if SimplePlayer == nil || entitlement == nil {
+		return
+	}
+	SimplePlayer.Premium().ApplySubscription(entitlement.GuildID, entitlement.SKUID, entitlement.EndsAt, deleted || entitlement.Deleted)
+}
+
+// premiumFeature returns the premium feature a command needs, if any
+func premiumFeature(data discordgo.ApplicationCommandInteractionData) (premium.Feature, bool) {
+	path := data.Name
+	for options := data.Options; len(options) > 0; options = options[0].Options {
+		if options[0].Type != discordgo.ApplicationCommandOptionSubCommand && options[0].Type != discordgo.ApplicationCommandOptionSubCommandGroup {
+			break
+		}
+		path += " " + options[0].Name
+		if feature, exists := premiumCommands[path]; exists {
+			return feature, true
+		}
+	}
+	feature, exists := premiumCommands[data.Name]
+	return feature, exists
+}
+
+// CheckPremium is the command middleware for premium tiers. It records the entitlements Discord sends with
+// the interaction, then answers commands the server's tier doesn't include and returns false so they don't run.
+func CheckPremium(s SessionInterface, i *discordgo.InteractionCreate) (bool, error) {
+	if SimplePlayer == nil {
+		return true, nil
+	}
+	for _, entitlement := range i.Entitlements {
+		ApplyEntitlement(entitlement, false)
+	}
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return true, nil
+	}
+
+	feature, gated := premiumFeature(i.ApplicationCommandData())
+	if !gated || SimplePlayer.Limits(i.GuildID).Allows(feature) {
+		return true, nil
+	}
+
+	name := strings.ToUpper(string(feature[:1])) + string(feature[1:])
+	if key, named := premiumFeatureNames[feature]; named {
+		name = translate(i, key)
+	}
+	data := &discordgo.InteractionResponseData{
+		Content: translate(i, "premium.ask_owner", name),
+		Flags:   discordgo.MessageFlagsEphemeral,
+	}
+	if skuID := SimplePlayer.Premium().SKUID(); skuID != "" {
+		data.Content = translate(i, "premium.subscribe", name)
+		data.Components = premiumButton(skuID)
+	}
+
+	return false, s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+}
+
+// premiumButton is Discord's subscribe button for the premium SKU
+func premiumButton(skuID string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Style: discordgo.PremiumButton, SKUID: skuID},
+		}},
+	}
+}
+
+// HandlePremiumCommand handles the /premium command: it shows the server's tier, what premium adds and,
+// for free servers, a button to subscribe
+func HandlePremiumCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if SimplePlayer == nil {
+		return respondWithEphemeral(s, i, translate(i, "settings.music_unavailable"))
+	}
+
+	entitlements := SimplePlayer.Premium()
+	status := entitlements.Status(i.GuildID)
+	data := embedResponse(i, createPremiumBenefitsEmbed(entitlements.Enabled(), status, entitlements.SKUID() != ""))
+	data.Flags = discordgo.MessageFlagsEphemeral
+	if status.Tier != premium.Premium && entitlements.SKUID() != "" {
+		data.Components = premiumButton(entitlements.SKUID())
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+}
+
+// createPremiumBenefitsEmbed compares the free and premium tiers for a server
+func createPremiumBenefitsEmbed(enabled bool, status premium.Status, subscribable bool) *discordgo.MessageEmbed {
+	free, paid := premium.LimitsFor(premium.Free), premium.LimitsFor(premium.Premium)
+
+	description := "This server is on the free tier. "
+	switch {
+	case !enabled:
+		description = "Premium tiers are off for this bot, so every server gets all premium features."
+	case status.Tier == premium.Premium && status.Source == premium.SourceSubscription:
+		description = "⭐ This server has premium through a Discord subscription, thank you for the support!"
+	case status.Tier == premium.Premium:
+		description = "⭐ This server has premium, granted by a bot owner."
+	case subscribable:
+		description += "Subscribe with the button below to unlock premium for everyone here."
+	default:
+		description += "Ask a bot owner about upgrading it."
+	}
+	if enabled && status.Tier == premium.Premium && !status.Expires.IsZero() {
+		description += fmt.Sprintf(" It ends <t:%d:R>.", status.Expires.Unix())
+	}
+
+	embed := NewEmbed("💎 Premium").Style(StyleMuted)
+	if status.Tier == premium.Premium {
+		embed.Color(0xf1c40f) // Gold
+	}
+	return embed.
+		Description(description).
+		InlineField("Free", formatTierBenefits(free)).
+		InlineField("⭐ Premium", formatTierBenefits(paid)).
+		Build()
+}
+
+// formatTierBenefits lists what a tier's limits allow
+func formatTierBenefits(limits premium.Limits) string {
+	maxQueue := "Unlimited queue"
+	if limits.MaxQueue > 0 {
+		maxQueue = fmt.Sprintf("%d song queue", limits.MaxQueue)
+	}
+	return strings.Join([]string{
+		maxQueue,
+		fmt.Sprintf("%d kbps audio", limits.Bitrate),
+		"Audio filters: " + formatAllowed(limits.Filters),
+		"24/7 mode: " + formatAllowed(limits.StayConnected),
+	}, "\n")
+}
@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/premium"
+	"pxnx-discord-bot/testutils"
+)
+
+// usePremiumPlayer installs a player that enforces tiers for the duration of a test
+func usePremiumPlayer(t *testing.T, skuID string) *premium.Entitlements {
+	entitlements, err := premium.Load(filepath.Join(t.TempDir(), "premium.json"), skuID)
+	require.NoError(t, err)
+
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	SimplePlayer.UsePremium(entitlements)
+	t.Cleanup(func() { SimplePlayer = original })
+	return entitlements
+}
+
+func TestLoadPremium(t *testing.T) {
+	t.Setenv("PREMIUM_ENABLED", "")
+	assert.False(t, LoadPremium().Enabled())
+
+	t.Setenv("PREMIUM_ENABLED", "nope")
+	assert.False(t, LoadPremium().Enabled())
+
+	t.Setenv("PREMIUM_ENABLED", "true")
+	t.Setenv("PREMIUM_FILE", filepath.Join(t.TempDir(), "premium.json"))
+	t.Setenv("PREMIUM_SKU_ID", " sku1 ")
+	entitlements := LoadPremium()
+	assert.True(t, entitlements.Enabled())
+	assert.Equal(t, "sku1", entitlements.SKUID())
+}
+
+func TestPremiumFeature(t *testing.T) {
+	filtersGroup := func(subcommand string) discordgo.ApplicationCommandInteractionData {
+		return discordgo.ApplicationCommandInteractionData{
+			Name: "music",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{{
+				Name:    "filters",
+				Type:    discordgo.ApplicationCommandOptionSubCommandGroup,
+				Options: []*discordgo.ApplicationCommandInteractionDataOption{{Name: subcommand, Type: discordgo.ApplicationCommandOptionSubCommand}},
+			}},
+		}
+	}
+
+	feature, gated := premiumFeature(filtersGroup("toggle"))
+	assert.True(t, gated)
+	assert.Equal(t, premium.FeatureFilters, feature)
+
+	_, gated = premiumFeature(filtersGroup("clear"))
+	assert.False(t, gated, "turning filters off stays free")
+
+	feature, gated = premiumFeature(newMusicInteraction("247").ApplicationCommandData())
+	assert.True(t, gated)
+	assert.Equal(t, premium.FeatureStayConnected, feature)
+
+	_, gated = premiumFeature(discordgo.ApplicationCommandInteractionData{Name: "play"})
+	assert.False(t, gated)
+}
+
+func TestCheckPremiumBlocksFreeServers(t *testing.T) {
+	usePremiumPlayer(t, "")
+
+	mockSession := &testutils.MockSession{}
+	allowed, err := CheckPremium(mockSession, newMusicInteraction("247"))
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, "⭐ 24/7 mode is a premium feature, ask a bot owner about upgrading this server", mockSession.RespondData.Content)
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+
+	mockSession = &testutils.MockSession{}
+	allowed, err = CheckPremium(mockSession, testutils.CreateTestInteraction("play", nil))
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.False(t, mockSession.RespondCalled)
+}
+
+func TestCheckPremiumAppliesInteractionEntitlements(t *testing.T) {
+	usePremiumPlayer(t, "sku1")
+
+	interaction := newMusicInteraction("247")
+	mockSession := &testutils.MockSession{}
+	allowed, err := CheckPremium(mockSession, interaction)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	require.Len(t, mockSession.RespondData.Components, 1, "subscriptions offer the SKU's premium button")
+
+	interaction.Entitlements = []*discordgo.Entitlement{{SKUID: "sku1", GuildID: interaction.GuildID}}
+	allowed, err = CheckPremium(&testutils.MockSession{}, interaction)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestCheckPremiumWithoutTiers(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	allowed, err := CheckPremium(&testutils.MockSession{}, newMusicInteraction("247"))
+	require.NoError(t, err)
+	assert.True(t, allowed, "every server is premium unless tiers are enabled")
+}
+
+func newAdminPremiumInteraction(userID string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	interaction := testutils.CreateTestInteraction("admin", []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "premium", Type: discordgo.ApplicationCommandOptionSubCommand, Options: options},
+	})
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: userID}}
+	return interaction
+}
+
+func TestHandleAdminPremiumGrantAndRevoke(t *testing.T) {
+	entitlements := usePremiumPlayer(t, "")
+	SetBotOwners([]string{"owner_id"})
+	defer SetBotOwners(nil)
+
+	grant := &discordgo.ApplicationCommandInteractionDataOption{Name: "action", Type: discordgo.ApplicationCommandOptionString, Value: "grant"}
+	days := &discordgo.ApplicationCommandInteractionDataOption{Name: "days", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(30)}
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleAdminCommand(mockSession, newAdminPremiumInteraction("someone_else", grant)))
+	assert.Contains(t, mockSession.RespondData.Content, "Only the bot owner")
+	assert.Equal(t, premium.Free, entitlements.Status("guild_id_123").Tier)
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleAdminCommand(mockSession, newAdminPremiumInteraction("owner_id", grant, days)))
+	status := entitlements.Status("guild_id_123")
+	assert.Equal(t, premium.Premium, status.Tier)
+	assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), status.Expires, time.Minute)
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Equal(t, "⭐ Premium", mockSession.RespondData.Embeds[0].Fields[0].Value)
+
+	revoke := &discordgo.ApplicationCommandInteractionDataOption{Name: "action", Type: discordgo.ApplicationCommandOptionString, Value: "revoke"}
+	require.NoError(t, HandleAdminCommand(&testutils.MockSession{}, newAdminPremiumInteraction("owner_id", revoke)))
+	assert.Equal(t, premium.Free, entitlements.Status("guild_id_123").Tier)
+}
+
+func TestCreatePremiumEmbed(t *testing.T) {
+	values := func(embed *discordgo.MessageEmbed) map[string]string {
+		result := make(map[string]string)
+		for _, field := range embed.Fields {
+			result[field.Name] = field.Value
+		}
+		return result
+	}
+
+	free := values(createPremiumEmbed(true, premium.Status{Tier: premium.Free}))
+	assert.Equal(t, "Free", free["Tier"])
+	assert.Equal(t, "❌ Premium only", free["Audio Filters"])
+	assert.Equal(t, "100 songs", free["Queue Size"])
+	assert.NotContains(t, free, "Expires")
+
+	expires := time.Unix(1750000000, 0)
+	paid := values(createPremiumEmbed(true, premium.Status{Tier: premium.Premium, Source: premium.SourceSubscription, Expires: expires}))
+	assert.Equal(t, "Discord subscription", paid["Source"])
+	assert.Equal(t, "<t:1750000000:R>", paid["Expires"])
+	assert.Equal(t, "✅ Included", paid["24/7 Mode"])
+	assert.Equal(t, "128 kbps", paid["Audio Quality"])
+
+	off := values(createPremiumEmbed(false, premium.Status{Tier: premium.Premium}))
+	assert.Contains(t, off["Source"], "Tiers are off")
+}
+
+func TestHandlePremiumCommand(t *testing.T) {
+	entitlements := usePremiumPlayer(t, "sku1")
+	interaction := testutils.CreateTestInteraction("premium", nil)
+
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandlePremiumCommand(mockSession, interaction))
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Contains(t, mockSession.RespondData.Embeds[0].Description, "Subscribe with the button below")
+	require.Len(t, mockSession.RespondData.Components, 1, "free servers get the subscribe button")
+
+	entitlements.ApplySubscription(interaction.GuildID, "sku1", nil, false)
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandlePremiumCommand(mockSession, interaction))
+	assert.Contains(t, mockSession.RespondData.Embeds[0].Description, "through a Discord subscription")
+	assert.Empty(t, mockSession.RespondData.Components)
+}
+
+func TestCreatePremiumBenefitsEmbed(t *testing.T) {
+	embed := createPremiumBenefitsEmbed(false, premium.Status{Tier: premium.Premium}, false)
+	assert.Contains(t, embed.Description, "every server gets all premium features")
+
+	embed = createPremiumBenefitsEmbed(true, premium.Status{Tier: premium.Free}, false)
+	assert.Contains(t, embed.Description, "Ask a bot owner")
+	require.Len(t, embed.Fields, 2)
+	assert.Contains(t, embed.Fields[0].Value, "100 song queue")
+	assert.Contains(t, embed.Fields[1].Value, "24/7 mode: ✅ Included")
+
+	expires := time.Now().Add(time.Hour)
+	embed = createPremiumBenefitsEmbed(true, premium.Status{Tier: premium.Premium, Source: premium.SourceGrant, Expires: expires}, true)
+	assert.Contains(t, embed.Description, "granted by a bot owner")
+	assert.Contains(t, embed.Description, "<t:")
+}
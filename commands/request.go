@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/tracing"
+	"pxnx-discord-bot/utils"
+)
+
+// RequestContext returns a context carrying who an interaction came from and what it ran, for passing
+// to the music player and services so their logs and error reports are attributed to it
+func RequestContext(i *discordgo.InteractionCreate) context.Context {
+	ctx := tracing.ContextWithSpan(context.Background(), interactionSpan(i))
+	return utils.WithRequest(ctx, requestInfo(i))
+}
+
+// requestInfo describes an interaction for logs
+func requestInfo(i *discordgo.InteractionCreate) utils.RequestInfo {
+	if i == nil || i.Interaction == nil {
+		return utils.RequestInfo{}
+	}
+
+	info := utils.RequestInfo{
+		InteractionID: i.ID,
+		GuildID:       i.GuildID,
+		UserID:        getInteractionUserID(i),
+		Locale:        string(i.Locale),
+	}
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand, discordgo.InteractionApplicationCommandAutocomplete:
+		info.Command = i.ApplicationCommandData().Name
+	case discordgo.InteractionMessageComponent:
+		info.Command = i.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		info.Command = i.ModalSubmitData().CustomID
+	}
+	return info
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/metrics"
+	"pxnx-discord-bot/tracing"
+	"pxnx-discord-bot/utils"
+)
+
+func TestRequestContext(t *testing.T) {
+	interaction := newConfirmationInteraction("user-1")
+	interaction.Locale = discordgo.German
+
+	info, ok := utils.RequestFrom(RequestContext(interaction))
+	require.True(t, ok)
+	assert.Equal(t, interaction.ID, info.InteractionID)
+	assert.Equal(t, interaction.GuildID, info.GuildID)
+	assert.Equal(t, "user-1", info.UserID)
+	assert.Equal(t, "de", info.Locale)
+	assert.Equal(t, "clear", info.Command)
+
+	button, _ := utils.RequestFrom(RequestContext(newButtonInteraction("confirm:abc", "user-2")))
+	assert.Equal(t, "confirm:abc", button.Command)
+	assert.Equal(t, "user-2", button.UserID)
+}
+
+func TestRequestContextContinuesInteractionTrace(t *testing.T) {
+	interaction := newConfirmationInteraction("user-1")
+	assert.False(t, tracing.SpanContextFromContext(RequestContext(interaction)).IsValid(), "nothing is traced while tracing is off")
+	TraceInteraction(interaction, "/clear")(metrics.OutcomeOK, nil)
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer collector.Close()
+	shutdown := tracing.Configure(tracing.Config{Endpoint: collector.URL, SampleRatio: 1})
+	defer shutdown(context.Background())
+
+	end := TraceInteraction(interaction, "/clear")
+	traced := tracing.SpanContextFromContext(RequestContext(interaction))
+	require.True(t, traced.IsValid())
+	assert.Equal(t, traced, trackRequest(interaction).Trace, "queued tracks continue the command's trace")
+
+	end(metrics.OutcomeOK, nil)
+	assert.False(t, tracing.SpanContextFromContext(RequestContext(interaction)).IsValid(), "the span is forgotten once the handler is done")
+}
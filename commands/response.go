@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxMessageLength is the most characters Discord accepts in a message's content
+const maxMessageLength = 2000
+
+// embedResponse returns response data showing embed, or the same information as plain text without emoji
+// for members who prefer plain text. Callers add components and flags to it.
+func embedResponse(i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) *discordgo.InteractionResponseData {
+	content, embeds := contentFor(getInteractionUserID(i), "", []*discordgo.MessageEmbed{embed})
+	return &discordgo.InteractionResponseData{Content: content, Embeds: embeds}
+}
+
+// contentFor returns a message's content and embeds the way a member prefers them: as they are, or for
+// plain text as one text without emoji and no embeds, so edits clear the embeds a message had
+func contentFor(userID, content string, embeds []*discordgo.MessageEmbed) (string, []*discordgo.MessageEmbed) {
+	if !Preferences.PlainText(userID) {
+		return content, embeds
+	}
+
+	var parts []string
+	if text := stripEmoji(content); text != "" {
+		parts = append(parts, text)
+	}
+	for _, embed := range embeds {
+		parts = append(parts, plainTextEmbed(embed))
+	}
+	return truncateText(strings.Join(parts, "\n\n"), maxMessageLength), []*discordgo.MessageEmbed{}
+}
+
+// respondWithEmbed responds to an interaction with embed, shown the way the member prefers
+func respondWithEmbed(s SessionInterface, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: embedResponse(i, embed),
+	})
+}
+
+// respondWithEphemeralEmbed responds with embed only the member sees, shown the way they prefer
+func respondWithEphemeralEmbed(s SessionInterface, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) error {
+	data := embedResponse(i, embed)
+	data.Flags = discordgo.MessageFlagsEphemeral
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+}
+
+// editWithEmbed answers a deferred interaction with embed, shown the way the member prefers
+func editWithEmbed(s SessionInterface, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) error {
+	data := embedResponse(i, embed)
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &data.Content, Embeds: &data.Embeds})
+	return err
+}
+
+// messageFor returns a text response as the member prefers it, without emoji for plain text
+func messageFor(i *discordgo.InteractionCreate, message string) string {
+	if !Preferences.PlainText(getInteractionUserID(i)) {
+		return message
+	}
+	return stripEmoji(message)
+}
+
+// plainTextEmbed writes an embed's title, description, fields and footer as lines of text without emoji,
+// so screen readers read it top to bottom without announcing decorations
+func plainTextEmbed(embed *discordgo.MessageEmbed) string {
+	var lines []string
+	add := func(text string) {
+		if text = stripEmoji(text); text != "" {
+			lines = append(lines, text)
+		}
+	}
+
+	add(embed.Title)
+	add(embed.Description)
+	for _, field := range embed.Fields {
+		name, value := stripEmoji(field.Name), stripEmoji(field.Value)
+		switch {
+		case name == "":
+			add(value)
+		case strings.Contains(value, "\n"):
+			lines = append(lines, name+":", value)
+		default:
+			lines = append(lines, name+": "+value)
+		}
+	}
+	if embed.Footer != nil {
+		add(embed.Footer.Text)
+	}
+
+	return truncateText(strings.Join(lines, "\n"), maxMessageLength)
+}
+
+// stripEmoji removes emoji and the joiners and variation selectors they are built from, and tidies the
+// spaces they leave behind on each line. Symbols such as ° and € stay.
+func stripEmoji(text string) string {
+	stripped := strings.Map(func(r rune) rune {
+		if isEmoji(r) {
+			return -1
+		}
+		return r
+	}, text)
+
+	lines := strings.Split(stripped, "\n")
+	for n, line := range lines {
+		lines[n] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// isEmoji reports whether a rune is part of an emoji
+func isEmoji(r rune) bool {
+	switch {
+	case r == 0x200D, r == 0x20E3, r == 0xFE0E, r == 0xFE0F: // Joiner, keycap and variation selectors
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // Skin tones
+		return true
+	case r >= 0x2190 && unicode.Is(unicode.So, r): // Arrows, dingbats, pictographs and the rest
+		return true
+	}
+	return false
+}
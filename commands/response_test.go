@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/testutils"
+)
+
+func TestStripEmoji(t *testing.T) {
+	assert.Equal(t, "Weather in Oslo, NO", stripEmoji("☀️ Weather in Oslo, NO"))
+	assert.Equal(t, "Temperature 12.5°C", stripEmoji("🌡️ Temperature 12.5°C"))
+	assert.Equal(t, "Live\nNow Playing", stripEmoji("🔴 Live \n 🎵 Now 👍🏽 Playing"))
+	assert.Equal(t, "No emoji here · 5€", stripEmoji("No emoji here · 5€"))
+}
+
+func TestPlainTextEmbed(t *testing.T) {
+	embed := &discordgo.MessageEmbed{
+		Title:       "🎱 Magic 8-Ball",
+		Description: "Shaking...",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Question", Value: "Will it rain?"},
+			{Name: "📅 Today", Value: "🌧️ Rain\n💧 80% humidity"},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: "Powered by OpenWeatherMap"},
+	}
+
+	assert.Equal(t, "Magic 8-Ball\nShaking...\nQuestion: Will it rain?\nToday:\nRain\n80% humidity\nPowered by OpenWeatherMap", plainTextEmbed(embed))
+
+	long := plainTextEmbed(&discordgo.MessageEmbed{Description: strings.Repeat("a", maxMessageLength+10)})
+	assert.Len(t, []rune(long), maxMessageLength)
+}
+
+func TestResponsesFollowPlainTextPreference(t *testing.T) {
+	store := usePreferences(t)
+	require.NoError(t, store.SetPlainText("user_1", true))
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("coinflip", nil)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user_1"}}
+
+	require.NoError(t, HandleCoinFlipCommand(mockSession, interaction))
+	assert.Empty(t, mockSession.RespondData.Embeds)
+	assert.Contains(t, mockSession.RespondData.Content, "Coin Flip\nThe coin landed on")
+	assert.NotContains(t, mockSession.RespondData.Content, "🪙")
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandlePeepeeCommand(mockSession, interaction))
+	assert.Empty(t, mockSession.RespondData.Embeds)
+	assert.NotEmpty(t, mockSession.RespondData.Content)
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, respondWithEphemeral(mockSession, interaction, "❌ Nothing is playing"))
+	assert.Equal(t, "Nothing is playing", mockSession.RespondData.Content)
+
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user_2"}}
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleCoinFlipCommand(mockSession, interaction))
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Empty(t, mockSession.RespondData.Content)
+}
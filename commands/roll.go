@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"fmt"
 	"math/rand"
 	"time"
 
@@ -31,16 +30,10 @@ func HandleRollCommand(s SessionInterface, i *discordgo.InteractionCreate) error
 
 	result := rollDice(max)
 
-	embed := &discordgo.MessageEmbed{
-		Title:       "🎲 Dice Roll",
-		Description: fmt.Sprintf("You rolled **%d** (1-%d)", result, max),
-		Color:       0x00ff00,
-	}
+	embed := NewEmbed("🎲 Dice Roll").
+		Descriptionf("You rolled **%d** (1-%d)", result, max).
+		Color(0x00ff00).
+		Build()
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	return respondWithEmbed(s, i, embed)
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
 )
 
 // HandleServerCommand handles the server slash command
@@ -16,41 +18,15 @@ func HandleServerCommand(s SessionInterface, i *discordgo.InteractionCreate) err
 	memberCount := guild.MemberCount
 	createdAt, _ := discordgo.SnowflakeTimestamp(guild.ID)
 
-	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("📊 %s Server Info", guild.Name),
-		Description: fmt.Sprintf("Here's some information about **%s**", guild.Name),
-		Color:       0x2ecc71, // ColorGreen
-		Thumbnail: &discordgo.MessageEmbedThumbnail{
-			URL: guild.IconURL("256"),
-		},
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "👥 Members",
-				Value:  fmt.Sprintf("%d", memberCount),
-				Inline: true,
-			},
-			{
-				Name:   "🆔 Server ID",
-				Value:  guild.ID,
-				Inline: true,
-			},
-			{
-				Name:   "👑 Owner",
-				Value:  fmt.Sprintf("<@%s>", guild.OwnerID),
-				Inline: true,
-			},
-			{
-				Name:   "🗓️ Created",
-				Value:  fmt.Sprintf("<t:%d:F>", createdAt.Unix()),
-				Inline: false,
-			},
-		},
-	}
+	embed := NewEmbed(fmt.Sprintf("📊 %s Server Info", guild.Name)).
+		Color(utils.ColorGreen).
+		Descriptionf("Here's some information about **%s**", guild.Name).
+		Thumbnail(guild.IconURL("256")).
+		InlineField("👥 Members", fmt.Sprintf("%d", memberCount)).
+		InlineField("🆔 Server ID", guild.ID).
+		InlineField("👑 Owner", fmt.Sprintf("<@%s>", guild.OwnerID)).
+		Field("🗓️ Created", fmt.Sprintf("<t:%d:F>", createdAt.Unix())).
+		Build()
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	return respondWithEmbed(s, i, embed)
 }
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/i18n"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/utils"
+)
+
+// GuildSettings holds each server's settings, set with /settings. The music player keeps its settings in
+// the same store.
+var GuildSettings = settings.New()
+
+// InitializeGuildSettings loads saved guild settings from MUSIC_SETTINGS_FILE
+func InitializeGuildSettings() {
+	store, err := settings.Load(MusicSettingsPath())
+	if err != nil {
+		utils.LogWarn("Guild settings will not be saved this run: %v", err)
+		store = settings.New()
+	}
+	GuildSettings = store
+}
+
+// responseLanguage returns the language to answer an interaction in: the server's in a server, the
+// member's Discord language in DMs
+func responseLanguage(i *discordgo.InteractionCreate) string {
+	if i.GuildID == "" {
+		return i18n.FromLocale(i.Locale)
+	}
+	return GuildSettings.Get(i.GuildID).ResponseLanguage()
+}
+
+// translate returns a message from the catalog in the language of an interaction
+func translate(i *discordgo.InteractionCreate, key string, args ...any) string {
+	return i18n.T(responseLanguage(i), key, args...)
+}
+
+// hasDJRole reports whether the member behind an interaction has the server's DJ role
+func hasDJRole(i *discordgo.InteractionCreate) bool {
+	role := GuildSettings.Get(i.GuildID).DJRoleID
+	return role != "" && i.Member != nil && slices.Contains(i.Member.Roles, role)
+}
+
+// HandleSettingsCommand handles /settings: show lists the server's settings, the other subcommands change
+// one and show the result
+func HandleSettingsCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if i.GuildID == "" {
+		return respondWithEphemeral(s, i, translate(i, "settings.server_only"))
+	}
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return respondWithEphemeral(s, i, translate(i, "settings.unknown"))
+	}
+	subcommand := options[0]
+	values := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(subcommand.Options))
+	for _, option := range subcommand.Options {
+		values[option.Name] = option
+	}
+
+	var err error
+	switch subcommand.Name {
+	case "show":
+	case "volume", "alone_timeout", "autoplay":
+		if SimplePlayer == nil {
+			return respondWithEphemeral(s, i, translate(i, "settings.music_unavailable"))
+		}
+		switch subcommand.Name {
+		case "volume":
+			err = SimplePlayer.SetVolume(i.GuildID, int(values["percent"].IntValue()))
+		case "alone_timeout":
+			err = SimplePlayer.SetAloneTimeout(i.GuildID, time.Duration(values["seconds"].IntValue())*time.Second)
+		case "autoplay":
+			err = SimplePlayer.SetAutoDJ(i.GuildID, values["enabled"].BoolValue())
+		}
+	case "dj_role":
+		roleID := ""
+		if option := values["role"]; option != nil {
+			roleID = option.RoleValue(nil, "").ID
+		}
+		_, err = GuildSettings.Update(i.GuildID, func(g *settings.Guild) { g.DJRoleID = roleID })
+	case "announcements":
+		channelID := ""
+		if option := values["channel"]; option != nil {
+			channelID = option.ChannelValue(nil).ID
+		}
+		_, err = GuildSettings.Update(i.GuildID, func(g *settings.Guild) { g.AnnouncementChannelID = channelID })
+		if err == nil && SimplePlayer != nil {
+			NowPlaying.follow(i.GuildID, i.ChannelID)
+		}
+	case "language":
+		language := values["language"].StringValue()
+		if _, supported := settings.Languages[language]; !supported {
+			return respondWithEphemeral(s, i, translate(i, "settings.unsupported_language", language))
+		}
+		if language == settings.DefaultLanguage {
+			language = "" // Guilds with default settings aren't saved
+		}
+		_, err = GuildSettings.Update(i.GuildID, func(g *settings.Guild) { g.Language = language })
+	case "summaries":
+		enabled := values["enabled"].BoolValue()
+		_, err = GuildSettings.Update(i.GuildID, func(g *settings.Guild) { g.SummariesDisabled = !enabled })
+	default:
+		return respondWithEphemeral(s, i, translate(i, "settings.unknown"))
+	}
+
+	embed := createSettingsEmbed(collectSettings(i.GuildID))
+	if err != nil {
+		utils.LogWarnContext(RequestContext(i), "Failed to change %s setting: %v", subcommand.Name, err)
+		embed.Description = translate(i, "settings.not_saved")
+	}
+	return respondWithEmbed(s, i, embed)
+}
+
+// settingsView is what /settings shows
+type settingsView struct {
+	Music        bool // Whether the music system runs, without it the music settings aren't shown
+	Volume       int
+	AloneTimeout time.Duration
+	Autoplay     bool
+	Guild        settings.Guild
+}
+
+// collectSettings reads a server's settings from the guild settings and the music player
+func collectSettings(guildID string) settingsView {
+	view := settingsView{Guild: GuildSettings.Get(guildID)}
+	if SimplePlayer != nil {
+		view.Music = true
+		view.Volume = SimplePlayer.Volume(guildID)
+		view.AloneTimeout, _ = SimplePlayer.Timeouts(guildID)
+		view.Autoplay = SimplePlayer.AutoDJ(guildID)
+	}
+	return view
+}
+
+// createSettingsEmbed lists a server's settings in the server's language
+func createSettingsEmbed(view settingsView) *discordgo.MessageEmbed {
+	language := view.Guild.ResponseLanguage()
+	t := func(key string) string { return i18n.T(language, key) }
+	onOff := func(enabled bool) string {
+		if enabled {
+			return t("on")
+		}
+		return t("off")
+	}
+
+	djRole := t("settings.dj_role_none")
+	if view.Guild.DJRoleID != "" {
+		djRole = "<@&" + view.Guild.DJRoleID + ">"
+	}
+	announcements := t("settings.announcements_follow")
+	if view.Guild.AnnouncementChannelID != "" {
+		announcements = "<#" + view.Guild.AnnouncementChannelID + ">"
+	}
+
+	embed := NewEmbed(t("settings.title"))
+	if view.Music {
+		embed.InlineField(t("settings.volume"), fmt.Sprintf("%d%%", view.Volume)).
+			InlineField(t("settings.alone_timeout"), view.AloneTimeout.String()).
+			InlineField(t("settings.autoplay"), onOff(view.Autoplay))
+	}
+	return embed.
+		InlineField(t("settings.dj_role"), djRole).
+		InlineField(t("settings.announcements"), announcements).
+		InlineField(t("settings.language"), settings.Languages[language]).
+		InlineField(t("settings.summaries"), onOff(!view.Guild.SummariesDisabled)).
+		Footer(t("settings.footer")).
+		Build()
+}
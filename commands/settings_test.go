@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/i18n"
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/testutils"
+)
+
+// useGuildSettings installs an in-memory guild settings store for the duration of a test
+func useGuildSettings(t *testing.T) *settings.Store {
+	original := GuildSettings
+	GuildSettings = settings.New()
+	t.Cleanup(func() { GuildSettings = original })
+	return GuildSettings
+}
+
+// settingsSubcommand creates a /settings interaction running a subcommand with options
+func settingsSubcommand(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return testutils.CreateTestInteraction("settings", []*discordgo.ApplicationCommandInteractionDataOption{{
+		Name:    name,
+		Type:    discordgo.ApplicationCommandOptionSubCommand,
+		Options: options,
+	}})
+}
+
+func TestHandleSettingsCommand(t *testing.T) {
+	store := useGuildSettings(t)
+	originalPlayer := SimplePlayer
+	SimplePlayer = nil
+	t.Cleanup(func() { SimplePlayer = originalPlayer })
+
+	role := &discordgo.ApplicationCommandInteractionDataOption{Name: "role", Type: discordgo.ApplicationCommandOptionRole, Value: "role_dj"}
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleSettingsCommand(mockSession, settingsSubcommand("dj_role", role)))
+	assert.Equal(t, "role_dj", store.Get("guild_id_123").DJRoleID)
+	require.Len(t, mockSession.RespondData.Embeds, 1)
+	assert.Equal(t, "<@&role_dj>", mockSession.RespondData.Embeds[0].Fields[0].Value, "music settings are left out without the music system")
+
+	require.NoError(t, HandleSettingsCommand(&testutils.MockSession{}, settingsSubcommand("language", testutils.CreateStringOption("language", "de"))))
+	assert.Equal(t, "de", store.Get("guild_id_123").ResponseLanguage())
+
+	require.NoError(t, HandleSettingsCommand(&testutils.MockSession{}, settingsSubcommand("language", testutils.CreateStringOption("language", "en"))))
+	assert.Empty(t, store.Get("guild_id_123").Language, "the default language isn't saved")
+
+	require.NoError(t, HandleSettingsCommand(&testutils.MockSession{}, settingsSubcommand("dj_role")))
+	assert.Equal(t, settings.Guild{}, store.Get("guild_id_123"), "leaving out the role removes it")
+
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleSettingsCommand(mockSession, settingsSubcommand("volume", &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "percent", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(50),
+	})))
+	assert.Contains(t, mockSession.RespondData.Content, "Music system is not available")
+}
+
+func TestSettingsEmbedUsesServerLanguage(t *testing.T) {
+	embed := createSettingsEmbed(settingsView{Guild: settings.Guild{Language: "de"}})
+	assert.Equal(t, "⚙️ Servereinstellungen", embed.Title)
+	require.Len(t, embed.Fields, 4)
+	assert.Equal(t, "Sprache", embed.Fields[2].Name)
+	assert.Equal(t, "Deutsch", embed.Fields[2].Value)
+	assert.Equal(t, "An", embed.Fields[3].Value)
+
+	for code := range settings.Languages {
+		assert.True(t, i18n.Supported(code), "%s has no catalog", code)
+	}
+}
+
+func TestDJRoleModeratesQueue(t *testing.T) {
+	store := useGuildSettings(t)
+
+	interaction := testutils.CreateTestInteraction("music", nil)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user_1"}, Roles: []string{"role_dj"}}
+	assert.False(t, isQueueModerator(interaction))
+
+	_, err := store.Update("guild_id_123", func(g *settings.Guild) { g.DJRoleID = "role_dj" })
+	require.NoError(t, err)
+	assert.True(t, isQueueModerator(interaction))
+}
+
+func TestSettingsShareOneStore(t *testing.T) {
+	store := useGuildSettings(t)
+	originalPlayer := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	SimplePlayer.UseSettings(store)
+	t.Cleanup(func() { SimplePlayer = originalPlayer })
+
+	require.NoError(t, HandleSettingsCommand(&testutils.MockSession{}, settingsSubcommand("volume", &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "percent", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(50),
+	})))
+	require.NoError(t, HandleSettingsCommand(&testutils.MockSession{}, settingsSubcommand("language", testutils.CreateStringOption("language", "fr"))))
+	assert.Equal(t, settings.Guild{Volume: 50, Language: "fr"}, store.Get("guild_id_123"), "player and server settings are saved together")
+}
+
+func TestNowPlayingFollowsAnnouncementChannel(t *testing.T) {
+	store := useGuildSettings(t)
+	board := newNowPlayingBoard()
+
+	board.follow("guild1", "requests")
+	assert.Equal(t, "requests", board.channels["guild1"])
+
+	_, err := store.Update("guild1", func(g *settings.Guild) { g.AnnouncementChannelID = "announcements" })
+	require.NoError(t, err)
+	board.follow("guild1", "requests")
+	assert.Equal(t, "announcements", board.channels["guild1"])
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/storage"
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultStorageDir is where the local backend keeps assets unless STORAGE_DIR says otherwise
+const DefaultStorageDir = "data/storage"
+
+// backupLinkLifetime is how long the download link /admin backup create shows stays valid
+const backupLinkLifetime = time.Hour
+
+// backupUploadTimeout bounds copying a backup to storage
+const backupUploadTimeout = 10 * time.Minute
+
+// Storage keeps large assets on disk or in an S3-compatible bucket, nil when STORAGE_BACKEND is unset
+var Storage storage.Store
+
+// InitializeStorage sets up the STORAGE_BACKEND, local or s3. A misconfigured backend is logged and left
+// off rather than stopping the bot.
+func InitializeStorage() {
+	store, err := LoadStorage()
+	if err != nil {
+		utils.LogError("Asset storage disabled: %v", err)
+		return
+	}
+	if store != nil {
+		utils.LogInfo("Storing assets in %s", store.Describe())
+	}
+	Storage = store
+}
+
+// LoadStorage creates the store STORAGE_BACKEND names from the environment, nil when it is unset
+func LoadStorage() (storage.Store, error) {
+	switch backend := strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_BACKEND"))); backend {
+	case "":
+		return nil, nil
+	case "local":
+		dir := strings.TrimSpace(os.Getenv("STORAGE_DIR"))
+		if dir == "" {
+			dir = DefaultStorageDir
+		}
+		secret := []byte(os.Getenv("STORAGE_URL_SECRET"))
+		if len(secret) == 0 {
+			// Links still work, but only until the bot restarts
+			secret = make([]byte, 32)
+			rand.Read(secret)
+		}
+		return storage.NewLocal(dir, strings.TrimSpace(os.Getenv("STORAGE_PUBLIC_URL")), secret), nil
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:        strings.TrimSpace(os.Getenv("S3_ENDPOINT")),
+			Region:          strings.TrimSpace(os.Getenv("S3_REGION")),
+			Bucket:          strings.TrimSpace(os.Getenv("S3_BUCKET")),
+			AccessKeyID:     strings.TrimSpace(os.Getenv("S3_ACCESS_KEY_ID")),
+			SecretAccessKey: strings.TrimSpace(os.Getenv("S3_SECRET_ACCESS_KEY")),
+			PathStyle:       strings.EqualFold(strings.TrimSpace(os.Getenv("S3_PATH_STYLE")), "true"),
+		}, nil)
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q, expected local or s3", backend)
+	}
+}
+
+// uploadBackup copies a backup archive to Storage, so it survives losing the bot's disk
+func uploadBackup(name string) error {
+	if Storage == nil || Backups == nil {
+		return nil
+	}
+	path := filepath.Join(Backups.Dir(), name)
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backupUploadTimeout)
+	defer cancel()
+	return Storage.Put(ctx, storage.Key(storage.KindBackups, name), file, info.Size(), "application/gzip")
+}
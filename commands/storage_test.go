@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/storage"
+)
+
+func TestLoadStorage(t *testing.T) {
+	t.Setenv("STORAGE_BACKEND", "")
+	store, err := LoadStorage()
+	require.NoError(t, err)
+	assert.Nil(t, store)
+
+	t.Setenv("STORAGE_BACKEND", "local")
+	t.Setenv("STORAGE_DIR", t.TempDir())
+	store, err = LoadStorage()
+	require.NoError(t, err)
+	assert.IsType(t, &storage.Local{}, store)
+
+	t.Setenv("STORAGE_BACKEND", "s3")
+	t.Setenv("S3_ENDPOINT", "https://s3.us-east-1.amazonaws.com")
+	t.Setenv("S3_BUCKET", "assets")
+	_, err = LoadStorage()
+	assert.Error(t, err, "credentials are required")
+	t.Setenv("S3_ACCESS_KEY_ID", "key")
+	t.Setenv("S3_SECRET_ACCESS_KEY", "secret")
+	store, err = LoadStorage()
+	require.NoError(t, err)
+	assert.Equal(t, "S3 bucket assets at s3.us-east-1.amazonaws.com", store.Describe())
+
+	t.Setenv("STORAGE_BACKEND", "ftp")
+	_, err = LoadStorage()
+	assert.Error(t, err)
+}
+
+func TestRunBackupCopiesToStorage(t *testing.T) {
+	useBackups(t)
+	previous := Storage
+	local := storage.NewLocal(t.TempDir(), "https://bot.example.com/files", []byte("secret"))
+	Storage = local
+	defer func() { Storage = previous }()
+
+	info, _, err := RunBackup()
+	require.NoError(t, err)
+
+	body, err := local.Get(context.Background(), storage.Key(storage.KindBackups, info.Name))
+	require.NoError(t, err)
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.EqualValues(t, info.Size, len(data))
+	assert.Contains(t, backupLink(info.Name), "https://bot.example.com/files/backups/"+info.Name+"?")
+}
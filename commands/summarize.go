@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/utils"
+)
+
+const (
+	summarizeTimeout        = 3 * time.Minute // Long transcripts are summarized in several requests
+	DefaultSummaryMessages  = 100             // Messages /summarize reads without a count or since
+	MaxSummaryMessages      = 500             // Most messages /summarize reads, also with since
+	maxSummarySince         = 24 * time.Hour  // Furthest back /summarize looks
+	summaryMessagesPageSize = 100             // Most messages Discord returns at once
+)
+
+// MessageContent reports whether the bot receives the content of messages (BOT_INTENT_MESSAGE_CONTENT).
+// Without it Discord leaves the content of other members' messages empty, so there is nothing to summarize.
+var MessageContent bool
+
+// HandleSummarizeCommand handles /summarize: it reads the channel's recent messages, the last count or those
+// since a while ago, and answers with a summary by the language model in the server's language
+func HandleSummarizeCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if LLM == nil {
+		return respondWithEphemeral(s, i, translate(i, "ai.not_set_up"))
+	}
+	if i.GuildID == "" {
+		return respondWithEphemeral(s, i, translate(i, "summarize.server_only"))
+	}
+	if GuildSettings.Get(i.GuildID).SummariesDisabled {
+		return respondWithEphemeral(s, i, translate(i, "summarize.disabled"))
+	}
+	if !MessageContent {
+		return respondWithEphemeral(s, i, translate(i, "summarize.no_intent"))
+	}
+	if !LLM.Personas().Get(i.GuildID).Allows(i.ChannelID) {
+		return respondWithEphemeral(s, i, translate(i, "ai.channel_not_allowed"))
+	}
+	// Members only get a summary of what they could scroll back to themselves
+	if i.Member == nil || i.Member.Permissions&discordgo.PermissionReadMessageHistory == 0 {
+		return respondWithEphemeral(s, i, translate(i, "summarize.member_history"))
+	}
+	if i.AppPermissions&discordgo.PermissionReadMessageHistory == 0 {
+		return respondWithEphemeral(s, i, translate(i, "summarize.bot_history"))
+	}
+
+	count, since := DefaultSummaryMessages, time.Duration(0)
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "count":
+			count = int(option.IntValue())
+		case "since":
+			since, _ = time.ParseDuration(strings.TrimSpace(option.StringValue()))
+			if since > maxSummarySince {
+				return respondWithEphemeral(s, i, translate(i, "summarize.since_too_long", int(maxSummarySince.Hours())))
+			}
+		}
+	}
+	if since > 0 && !hasOption(i, "count") {
+		count = MaxSummaryMessages
+	}
+
+	var after time.Time
+	if since > 0 {
+		after = time.Now().Add(-since)
+	}
+	messages, err := recentMessages(s, i.ChannelID, count, after)
+	if err != nil {
+		utils.LogWarnContext(RequestContext(i), "Failed to read messages of channel %s: %v", i.ChannelID, err)
+		return respondWithError(s, i, translate(i, "summarize.read_failed"))
+	}
+	if len(messages) == 0 {
+		return respondWithError(s, i, translate(i, "summarize.nothing"))
+	}
+
+	ctx, cancel := context.WithTimeout(RequestContext(i), summarizeTimeout)
+	defer cancel()
+	language := settings.Languages[GuildSettings.Get(i.GuildID).ResponseLanguage()]
+	summary, err := LLM.Summarize(ctx, language, transcriptLines(messages))
+	if err != nil {
+		utils.LogWarnContext(ctx, "Failed to summarize channel %s: %v", i.ChannelID, err)
+		return respondWithError(s, i, translate(i, "summarize.failed"))
+	}
+
+	return editWithEmbed(s, i, createSummaryEmbed(summary, messages))
+}
+
+// hasOption reports whether a top-level option was given
+func hasOption(i *discordgo.InteractionCreate, name string) bool {
+	return slices.ContainsFunc(i.ApplicationCommandData().Options, func(option *discordgo.ApplicationCommandInteractionDataOption) bool {
+		return option.Name == name
+	})
+}
+
+// recentMessages returns up to count of a channel's latest messages by members, oldest first, stopping at
+// messages sent before after unless it is zero. Messages of bots and without text are left out.
+func recentMessages(s SessionInterface, channelID string, count int, after time.Time) ([]*discordgo.Message, error) {
+	var messages []*discordgo.Message
+	before := ""
+	for len(messages) < count {
+		page, err := s.ChannelMessages(channelID, summaryMessagesPageSize, before, "", "")
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range page {
+			if !after.IsZero() && message.Timestamp.Before(after) {
+				page = nil
+				break
+			}
+			if message.Author != nil && !message.Author.Bot && strings.TrimSpace(message.Content) != "" {
+				messages = append(messages, message)
+				if len(messages) == count {
+					break
+				}
+			}
+		}
+		if len(page) < summaryMessagesPageSize {
+			break
+		}
+		before = page[len(page)-1].ID
+	}
+	slices.Reverse(messages)
+	return messages, nil
+}
+
+// transcriptLines writes messages as "[15:04] name: content" lines for the model
+func transcriptLines(messages []*discordgo.Message) []string {
+	lines := make([]string, len(messages))
+	for n, message := range messages {
+		name := message.Author.DisplayName()
+		if message.Member != nil && message.Member.Nick != "" {
+			name = message.Member.Nick
+		}
+		lines[n] = fmt.Sprintf("[%s] %s: %s", message.Timestamp.UTC().Format("15:04"), name, message.Content)
+	}
+	return lines
+}
+
+// createSummaryEmbed shows a summary of messages, oldest first
+func createSummaryEmbed(summary string, messages []*discordgo.Message) *discordgo.MessageEmbed {
+	return NewEmbed(fmt.Sprintf("📝 Summary of the last %d messages", len(messages))).
+		Description(summary).
+		Footer("Written by AI from the messages since then, it can get things wrong").
+		Timestamp(messages[0].Timestamp).
+		Build()
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/testutils"
+)
+
+// testHistory creates a channel history of count messages a minute apart, newest first, every third by a bot
+func testHistory(count int, newest time.Time) []*discordgo.Message {
+	messages := make([]*discordgo.Message, count)
+	for n := range messages {
+		messages[n] = &discordgo.Message{
+			ID:        fmt.Sprintf("message_%d", n),
+			Content:   fmt.Sprintf("message %d", n),
+			Author:    &discordgo.User{ID: "user_1", Username: "alice", Bot: n%3 == 2},
+			Timestamp: newest.Add(-time.Duration(n) * time.Minute),
+		}
+	}
+	return messages
+}
+
+// summarizeInteraction creates a /summarize interaction by a member who may read the channel's history
+func summarizeInteraction(options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	interaction := testutils.CreateTestInteraction("summarize", options)
+	interaction.Member = testutils.CreateTestMember(testutils.CreateTestUser("user_id", "member", ""))
+	interaction.Member.Permissions = discordgo.PermissionViewChannel | discordgo.PermissionReadMessageHistory
+	interaction.AppPermissions = discordgo.PermissionReadMessageHistory
+	return interaction
+}
+
+func TestRecentMessages(t *testing.T) {
+	now := time.Now()
+	mockSession := &testutils.MockSession{ChannelMessagesReturn: testHistory(300, now)}
+
+	messages, err := recentMessages(mockSession, "channel_id_123", 150, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, messages, 150)
+	assert.Equal(t, 3, mockSession.ChannelMessagesCalls, "messages are read a page at a time")
+	assert.Equal(t, "message 0", messages[len(messages)-1].Content, "the newest message comes last")
+	for _, message := range messages {
+		assert.False(t, message.Author.Bot)
+	}
+
+	mockSession.ChannelMessagesCalls = 0
+	messages, err = recentMessages(mockSession, "channel_id_123", 500, now.Add(-10*time.Minute+time.Second))
+	require.NoError(t, err)
+	assert.Len(t, messages, 7, "messages from before since are left out")
+	assert.Equal(t, 1, mockSession.ChannelMessagesCalls)
+}
+
+func TestHandleSummarizeCommand(t *testing.T) {
+	var transcript string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct{ Content string } `json:"messages"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		transcript = body.Messages[len(body.Messages)-1].Content
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"- Alice counted messages"}}]}`)
+	}))
+	defer server.Close()
+	useLLM(t, server.URL)
+	store := useGuildSettings(t)
+	original := MessageContent
+	MessageContent = true
+	t.Cleanup(func() { MessageContent = original })
+
+	count := &discordgo.ApplicationCommandInteractionDataOption{Name: "count", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(10)}
+	mockSession := &testutils.MockSession{ChannelMessagesReturn: testHistory(30, time.Now())}
+	require.NoError(t, HandleSummarizeCommand(mockSession, summarizeInteraction(count)))
+	assert.Equal(t, 10, strings.Count(transcript, "alice: message"))
+	require.True(t, mockSession.InteractionResponseEditCalled)
+
+	// Servers can opt out
+	_, err := store.Update("guild_id_123", func(g *settings.Guild) { g.SummariesDisabled = true })
+	require.NoError(t, err)
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleSummarizeCommand(mockSession, summarizeInteraction()))
+	assert.Contains(t, mockSession.RespondData.Content, "turned off in this server")
+	assert.Zero(t, mockSession.ChannelMessagesCalls)
+}
+
+func TestHandleSummarizeCommandRefusals(t *testing.T) {
+	useLLM(t, "http://localhost")
+	useGuildSettings(t)
+	original := MessageContent
+	t.Cleanup(func() { MessageContent = original })
+
+	MessageContent = false
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleSummarizeCommand(mockSession, summarizeInteraction()))
+	assert.Contains(t, mockSession.RespondData.Content, "Message Content intent")
+
+	MessageContent = true
+	interaction := summarizeInteraction()
+	interaction.Member.Permissions = discordgo.PermissionViewChannel
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleSummarizeCommand(mockSession, interaction))
+	assert.Contains(t, mockSession.RespondData.Content, "You need the Read Message History permission")
+
+	interaction = summarizeInteraction()
+	interaction.AppPermissions = 0
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleSummarizeCommand(mockSession, interaction))
+	assert.Contains(t, mockSession.RespondData.Content, "I need the Read Message History permission")
+}
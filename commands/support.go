@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/i18n"
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/premium"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/utils"
+)
+
+// SupportBundle is the diagnostics file /support attaches, for members to hand to the support server
+type SupportBundle struct {
+	GeneratedAt  time.Time         `json:"generated_at"`
+	GuildID      string            `json:"guild_id"`
+	Settings     *settings.Guild   `json:"settings,omitempty"`
+	Premium      *premium.Status   `json:"premium,omitempty"`
+	Player       *music.GuildState `json:"player,omitempty"`
+	Permissions  []SupportAudit    `json:"permissions"`
+	RecentErrors []string          `json:"recent_errors"` // Warnings and errors logged for this server's commands
+}
+
+// SupportAudit is the bot's permissions in one channel
+type SupportAudit struct {
+	ChannelID string          `json:"channel_id"`
+	Channel   string          `json:"channel,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Missing   []string        `json:"missing"`
+	Checks    map[string]bool `json:"checks,omitempty"`
+}
+
+// supportServerURL returns the invite to the support server from SUPPORT_SERVER_URL, empty when unset or
+// not a link, since Discord refuses a whole message with an invalid link button
+func supportServerURL() string {
+	raw := strings.TrimSpace(os.Getenv("SUPPORT_SERVER_URL"))
+	if raw == "" {
+		return ""
+	}
+	if parsed, err := url.Parse(raw); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		utils.LogWarn("Ignoring SUPPORT_SERVER_URL %q, expected an https:// invite link", raw)
+		return ""
+	}
+	return raw
+}
+
+// HandleSupportCommand handles the /support command: it attaches a diagnostics bundle for the server and
+// links to the support server, so a bug report starts with the details support would ask for
+func HandleSupportCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if i.GuildID == "" {
+		return respondWithEphemeral(s, i, translate(i, "support.server_only"))
+	}
+
+	bundle := collectSupportBundle(s, i)
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return respondWithEphemeral(s, i, translate(i, "support.failed", err))
+	}
+
+	invite := supportServerURL()
+	response := &discordgo.InteractionResponseData{
+		Content: summarizeSupportBundle(bundle, invite != ""),
+		Files: []*discordgo.File{{
+			Name:        fmt.Sprintf("support-%s-%s.json", i.GuildID, bundle.GeneratedAt.Format("20060102-150405")),
+			ContentType: "application/json",
+			Reader:      bytes.NewReader(data),
+		}},
+		Flags: discordgo.MessageFlagsEphemeral,
+	}
+	if invite != "" {
+		response.Components = []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.Button{Label: "Support Server", Style: discordgo.LinkButton, URL: invite},
+			}},
+		}
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: response,
+	})
+}
+
+// collectSupportBundle gathers the server's settings, player state, permissions and recent errors
+func collectSupportBundle(s SessionInterface, i *discordgo.InteractionCreate) SupportBundle {
+	bundle := SupportBundle{
+		GeneratedAt:  time.Now().UTC(),
+		GuildID:      i.GuildID,
+		RecentErrors: guildLogLines(utils.RecentLogs(utils.LogLevelWarn, ""), i.GuildID),
+	}
+
+	channelIDs := []string{i.ChannelID}
+	if SimplePlayer != nil {
+		guildSettings := SimplePlayer.Settings(i.GuildID)
+		status := SimplePlayer.Premium().Status(i.GuildID)
+		state := SimplePlayer.DumpState(i.GuildID)
+		bundle.Settings, bundle.Premium, bundle.Player = &guildSettings, &status, &state
+
+		// The bot's voice channel is where music permissions matter
+		if state.Player != nil && state.Player.Voice.ChannelID != "" {
+			channelIDs = append(channelIDs, state.Player.Voice.ChannelID)
+		}
+	}
+
+	for _, channelID := range channelIDs {
+		bundle.Permissions = append(bundle.Permissions, auditChannel(s, channelID))
+	}
+	return bundle
+}
+
+// auditChannel checks the bot's permissions in a channel like /checkperms does
+func auditChannel(s SessionInterface, channelID string) SupportAudit {
+	audit := SupportAudit{ChannelID: channelID, Missing: []string{}}
+
+	channel, permissions, err := botChannelPermissions(s, channelID)
+	if err != nil {
+		audit.Error = err.Error()
+		return audit
+	}
+
+	audit.Channel = channel.Name
+	audit.Checks = make(map[string]bool)
+	for _, result := range checkPermissions(botPermissionRequirements(channel.GuildID), permissions, isVoiceChannel(channel)) {
+		// The bundle is read by the bot's maintainers, so it is in English
+		name := i18n.T(i18n.Default, result.Requirement.Name)
+		audit.Checks[name] = result.Granted
+		if !result.Granted && !result.Requirement.Optional {
+			audit.Missing = append(audit.Missing, name)
+		}
+	}
+	return audit
+}
+
+// guildLogLines formats the log entries logged while handling a guild's interactions
+func guildLogLines(entries []utils.LogEntry, guildID string) []string {
+	lines := []string{}
+	for _, entry := range entries {
+		if entry.Request.GuildID == guildID {
+			lines = append(lines, strings.TrimSuffix(string(formatLogEntries([]utils.LogEntry{entry})), "\n"))
+		}
+	}
+	return lines
+}
+
+// summarizeSupportBundle is the message shown above the attached bundle, pointing out problems found
+func summarizeSupportBundle(bundle SupportBundle, linked bool) string {
+	var summary strings.Builder
+	summary.WriteString("🛟 Here is a diagnostics file for this server. ")
+	if linked {
+		summary.WriteString("Join the support server with the button below and attach it to your report.")
+	} else {
+		summary.WriteString("Attach it when you report the problem to the bot owner.")
+	}
+
+	for _, audit := range bundle.Permissions {
+		switch {
+		case audit.Error != "":
+			fmt.Fprintf(&summary, "\n⚠️ Permissions in <#%s> could not be checked", audit.ChannelID)
+		case len(audit.Missing) > 0:
+			fmt.Fprintf(&summary, "\n❌ Missing in <#%s>: %s", audit.ChannelID, strings.Join(audit.Missing, ", "))
+		}
+	}
+	if count := len(bundle.RecentErrors); count > 0 {
+		fmt.Fprintf(&summary, "\n📋 %d recent warnings and errors included", count)
+	}
+	summary.WriteString("\nIt holds this server's settings, queue and errors with the user IDs of requesters, but no messages.")
+	return summary.String()
+}
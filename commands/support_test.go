@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/testutils"
+	"pxnx-discord-bot/utils"
+)
+
+func TestHandleSupportCommand(t *testing.T) {
+	t.Setenv("SUPPORT_SERVER_URL", "https://discord.gg/example")
+	mockSession := &testutils.MockSession{}
+
+	require.NoError(t, HandleSupportCommand(mockSession, testutils.CreateTestInteraction("support", nil)))
+	require.True(t, mockSession.RespondCalled)
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+	assert.Contains(t, mockSession.RespondData.Content, "Join the support server")
+	assert.Contains(t, mockSession.RespondData.Content, "could not be checked")
+
+	require.Len(t, mockSession.RespondData.Files, 1)
+	data, err := io.ReadAll(mockSession.RespondData.Files[0].Reader)
+	require.NoError(t, err)
+	var bundle SupportBundle
+	require.NoError(t, json.Unmarshal(data, &bundle))
+	assert.Equal(t, "guild_id_123", bundle.GuildID)
+	require.Len(t, bundle.Permissions, 1)
+	assert.Equal(t, "channel_id_123", bundle.Permissions[0].ChannelID)
+	assert.NotEmpty(t, bundle.Permissions[0].Error)
+
+	require.Len(t, mockSession.RespondData.Components, 1)
+	button := mockSession.RespondData.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	assert.Equal(t, "https://discord.gg/example", button.URL)
+}
+
+func TestHandleSupportCommandWithoutSupportServer(t *testing.T) {
+	t.Setenv("SUPPORT_SERVER_URL", "discord.gg/example")
+	mockSession := &testutils.MockSession{}
+
+	require.NoError(t, HandleSupportCommand(mockSession, testutils.CreateTestInteraction("support", nil)))
+	assert.Contains(t, mockSession.RespondData.Content, "report the problem to the bot owner")
+	assert.Empty(t, mockSession.RespondData.Components, "links without https:// are ignored")
+	assert.Len(t, mockSession.RespondData.Files, 1)
+}
+
+func TestGuildLogLines(t *testing.T) {
+	logged := time.Date(2025, time.March, 1, 12, 30, 0, 0, time.UTC)
+	entries := []utils.LogEntry{
+		{Time: logged, Level: utils.LogLevelError, Module: "music", Message: "Playback failed",
+			Request: utils.RequestInfo{GuildID: "guild_1", Command: "play"}},
+		{Time: logged, Level: utils.LogLevelWarn, Module: "music", Message: "Someone else's problem",
+			Request: utils.RequestInfo{GuildID: "guild_2"}},
+		{Time: logged, Level: utils.LogLevelWarn, Module: "bot", Message: "Not from a command"},
+	}
+
+	assert.Equal(t, []string{"2025-03-01 12:30:00 [ERROR] music: Playback failed [command=play guild=guild_1]"}, guildLogLines(entries, "guild_1"))
+	assert.Equal(t, []string{}, guildLogLines(entries, "guild_3"))
+}
+
+func TestSummarizeSupportBundle(t *testing.T) {
+	summary := summarizeSupportBundle(SupportBundle{
+		Permissions: []SupportAudit{
+			{ChannelID: "text_1", Missing: []string{}},
+			{ChannelID: "voice_1", Missing: []string{"Connect", "Speak"}},
+		},
+		RecentErrors: []string{"one", "two"},
+	}, true)
+
+	assert.Contains(t, summary, "❌ Missing in <#voice_1>: Connect, Speak")
+	assert.NotContains(t, summary, "text_1")
+	assert.Contains(t, summary, "📋 2 recent warnings and errors included")
+}
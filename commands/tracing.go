@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/tracing"
+)
+
+// interactionSpans holds the span of each interaction being handled by ID, so RequestContext continues
+// its trace into the player and services
+var interactionSpans sync.Map
+
+// TraceInteraction starts the span of an interaction, named like "/play", which RequestContext continues
+// until the returned function ends it with the handler's outcome and error
+func TraceInteraction(i *discordgo.InteractionCreate, name string) (end func(outcome string, err error)) {
+	info := requestInfo(i)
+	_, span := tracing.Start(context.Background(), name, tracing.KindServer,
+		"command", info.Command, "guild_id", info.GuildID, "interaction_id", info.InteractionID)
+	if span == nil {
+		return func(string, error) {}
+	}
+	interactionSpans.Store(i.ID, span)
+
+	return func(outcome string, err error) {
+		interactionSpans.Delete(i.ID)
+		span.SetAttributes("outcome", outcome)
+		span.RecordError(err)
+		span.End()
+	}
+}
+
+// interactionSpan returns the span of an interaction being handled, nil when it isn't traced
+func interactionSpan(i *discordgo.InteractionCreate) *tracing.Span {
+	if i == nil || i.Interaction == nil {
+		return nil
+	}
+	span, _ := interactionSpans.Load(i.ID)
+	traced, _ := span.(*tracing.Span)
+	return traced
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/direct"
+	"pxnx-discord-bot/services/stt"
+	"pxnx-discord-bot/utils"
+)
+
+// transcribeTimeout bounds downloading and transcribing one audio file
+const transcribeTimeout = 3 * time.Minute
+
+// tooLargeToTranscribe answers audio files larger than the transcription backends accept
+func tooLargeToTranscribe(i *discordgo.InteractionCreate) string {
+	return translate(i, "transcribe.too_large", stt.MaxFileSize>>20)
+}
+
+// Transcriber turns voice messages into text for the Transcribe message command, nil when STT_BACKEND is not set
+var Transcriber *stt.Transcriber
+
+// InitializeTranscriber sets up transcription with the backend STT_BACKEND chooses, whisper.cpp or an API
+func InitializeTranscriber() {
+	backend, err := stt.New(stt.ConfigFromEnv())
+	if err != nil {
+		utils.LogWarn("Transcription is off: %v", err)
+		return
+	}
+	if backend == nil {
+		return
+	}
+	Transcriber = stt.NewTranscriber(backend)
+	utils.LogInfo("Transcribing voice messages with %s", backend.Name())
+}
+
+// HandleTranscribeCommand handles the Transcribe message command: it transcribes the voice message or first
+// audio file of the message it was used on and answers with the text and its detected language
+func HandleTranscribeCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	if Transcriber == nil {
+		return respondWithEphemeral(s, i, translate(i, "transcribe.not_set_up"))
+	}
+	data := i.ApplicationCommandData()
+	var message *discordgo.Message
+	if data.Resolved != nil {
+		message = data.Resolved.Messages[data.TargetID]
+	}
+	attachment := audioAttachment(message)
+	if attachment == nil {
+		return respondWithEphemeral(s, i, translate(i, "transcribe.no_audio"))
+	}
+	if attachment.Size > stt.MaxFileSize {
+		return respondWithEphemeral(s, i, "❌ "+tooLargeToTranscribe(i))
+	}
+
+	ctx, cancel := context.WithTimeout(RequestContext(i), transcribeTimeout)
+	defer cancel()
+	transcript, err := Transcriber.TranscribeURL(ctx, attachment.URL, attachment.Filename)
+	var tooLarge *stt.TooLargeError
+	switch {
+	case errors.Is(err, stt.ErrNoSpeech):
+		return respondWithError(s, i, translate(i, "transcribe.no_speech"))
+	case errors.As(err, &tooLarge):
+		return respondWithError(s, i, tooLargeToTranscribe(i))
+	case err != nil:
+		utils.LogWarnContext(ctx, "Failed to transcribe %s: %v", attachment.Filename, err)
+		return respondWithError(s, i, translate(i, "transcribe.failed"))
+	}
+	return editWithEmbed(s, i, createTranscriptEmbed(transcript, i.GuildID, message))
+}
+
+// audioAttachment returns a message's voice message or first audio file, nil when it has neither
+func audioAttachment(message *discordgo.Message) *discordgo.MessageAttachment {
+	if message == nil {
+		return nil
+	}
+	for _, attachment := range message.Attachments {
+		if message.Flags&discordgo.MessageFlagsIsVoiceMessage != 0 || direct.IsAudio(attachment.ContentType, attachment.Filename) {
+			return attachment
+		}
+	}
+	return nil
+}
+
+// transcriptLanguage names a detected language for display, backends report codes such as "en" or names
+// such as "english"
+func transcriptLanguage(language string) string {
+	switch {
+	case language == "":
+		return "Unknown"
+	case len(language) <= 3:
+		return strings.ToUpper(language)
+	default:
+		return strings.ToUpper(language[:1]) + language[1:]
+	}
+}
+
+// createTranscriptEmbed shows the transcript of a message's audio
+func createTranscriptEmbed(transcript stt.Transcript, guildID string, message *discordgo.Message) *discordgo.MessageEmbed {
+	if guildID == "" {
+		guildID = "@me"
+	}
+	link := fmt.Sprintf("https://discord.com/channels/%s/%s/%s", guildID, message.ChannelID, message.ID)
+	author := "Unknown"
+	if message.Author != nil {
+		author = message.Author.Mention()
+	}
+
+	return NewEmbed("📝 Transcript").
+		Description(transcript.Text).
+		InlineField("Language", transcriptLanguage(transcript.Language)).
+		InlineField("Message", fmt.Sprintf("%s, [jump to it](%s)", author, link)).
+		Footer("Transcribed automatically, it can get words wrong").
+		Build()
+}
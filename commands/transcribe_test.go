@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/services/stt"
+	"pxnx-discord-bot/testutils"
+)
+
+// fixedTranscript is a transcription backend answering every file with the same transcript
+type fixedTranscript stt.Transcript
+
+func (f fixedTranscript) Transcribe(ctx context.Context, path string) (stt.Transcript, error) {
+	return stt.Transcript(f), nil
+}
+
+func (f fixedTranscript) Name() string { return "fixed" }
+
+// transcribeInteraction creates a Transcribe interaction on a message
+func transcribeInteraction(message *discordgo.Message) *discordgo.InteractionCreate {
+	interaction := testutils.CreateTestInteraction("Transcribe", nil)
+	data := interaction.ApplicationCommandData()
+	data.CommandType = discordgo.MessageApplicationCommand
+	data.TargetID = message.ID
+	data.Resolved = &discordgo.ApplicationCommandInteractionDataResolved{
+		Messages: map[string]*discordgo.Message{message.ID: message},
+	}
+	interaction.Data = data
+	return interaction
+}
+
+func TestAudioAttachment(t *testing.T) {
+	assert.Nil(t, audioAttachment(nil))
+
+	image := &discordgo.MessageAttachment{Filename: "cat.png", ContentType: "image/png"}
+	song := &discordgo.MessageAttachment{Filename: "song.mp3", ContentType: "audio/mpeg"}
+	assert.Nil(t, audioAttachment(&discordgo.Message{Attachments: []*discordgo.MessageAttachment{image}}))
+	assert.Equal(t, song, audioAttachment(&discordgo.Message{Attachments: []*discordgo.MessageAttachment{image, song}}))
+
+	voice := &discordgo.MessageAttachment{Filename: "voice-message.ogg"}
+	assert.Equal(t, voice, audioAttachment(&discordgo.Message{Flags: discordgo.MessageFlagsIsVoiceMessage, Attachments: []*discordgo.MessageAttachment{voice}}))
+}
+
+func TestTranscriptLanguage(t *testing.T) {
+	assert.Equal(t, "EN", transcriptLanguage("en"))
+	assert.Equal(t, "English", transcriptLanguage("english"))
+	assert.Equal(t, "Unknown", transcriptLanguage(""))
+}
+
+func TestHandleTranscribeCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("voice message"))
+	}))
+	defer server.Close()
+	original := Transcriber
+	t.Cleanup(func() { Transcriber = original })
+
+	message := &discordgo.Message{
+		ID:          "message_1",
+		ChannelID:   "channel_id_123",
+		Author:      &discordgo.User{ID: "user_1"},
+		Flags:       discordgo.MessageFlagsIsVoiceMessage,
+		Attachments: []*discordgo.MessageAttachment{{Filename: "voice-message.ogg", URL: server.URL + "/voice-message.ogg", Size: 1024}},
+	}
+
+	Transcriber = nil
+	mockSession := &testutils.MockSession{}
+	require.NoError(t, HandleTranscribeCommand(mockSession, transcribeInteraction(message)))
+	assert.Contains(t, mockSession.RespondData.Content, "not set up")
+
+	Transcriber = stt.NewTranscriber(fixedTranscript{Text: "See you at eight", Language: "en"})
+	mockSession = &testutils.MockSession{InteractionResponseEditReturn: &discordgo.Message{}}
+	require.NoError(t, HandleTranscribeCommand(mockSession, transcribeInteraction(message)))
+	assert.True(t, mockSession.InteractionResponseEditCalled)
+
+	// Messages without audio and files too large to transcribe are refused before downloading
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleTranscribeCommand(mockSession, transcribeInteraction(&discordgo.Message{ID: "message_2"})))
+	assert.Contains(t, mockSession.RespondData.Content, "no voice message or audio file")
+
+	message.Attachments[0].Size = stt.MaxFileSize + 1
+	mockSession = &testutils.MockSession{}
+	require.NoError(t, HandleTranscribeCommand(mockSession, transcribeInteraction(message)))
+	assert.Contains(t, mockSession.RespondData.Content, "too large")
+}
+
+func TestCreateTranscriptEmbed(t *testing.T) {
+	message := &discordgo.Message{ID: "message_1", ChannelID: "channel_1", Author: &discordgo.User{ID: "user_1"}}
+	embed := createTranscriptEmbed(stt.Transcript{Text: "See you at eight", Language: "english"}, "guild_1", message)
+	assert.Equal(t, "See you at eight", embed.Description)
+	assert.Equal(t, "English", embed.Fields[0].Value)
+	assert.Equal(t, "<@user_1>, [jump to it](https://discord.com/channels/guild_1/channel_1/message_1)", embed.Fields[1].Value)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
 )
 
 // HandleUserCommand handles the user slash command
@@ -21,36 +23,14 @@ func HandleUserCommand(s SessionInterface, i *discordgo.InteractionCreate) error
 	avatarURL := getUserAvatarURL(targetUser)
 	userCreated, _ := discordgo.SnowflakeTimestamp(targetUser.ID)
 
-	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("👤 %s's Profile", targetUser.Username),
-		Description: fmt.Sprintf("Here's some information about **%s**", targetUser.Mention()),
-		Color:       0xe74c3c, // ColorRed
-		Thumbnail: &discordgo.MessageEmbedThumbnail{
-			URL: avatarURL,
-		},
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "🏷️ Username",
-				Value:  targetUser.Username,
-				Inline: true,
-			},
-			{
-				Name:   "🆔 User ID",
-				Value:  targetUser.ID,
-				Inline: true,
-			},
-			{
-				Name:   "🗓️ Account Created",
-				Value:  fmt.Sprintf("<t:%d:F>", userCreated.Unix()),
-				Inline: false,
-			},
-		},
-	}
+	embed := NewEmbed(fmt.Sprintf("👤 %s's Profile", targetUser.Username)).
+		Color(utils.ColorRed).
+		Descriptionf("Here's some information about **%s**", targetUser.Mention()).
+		Thumbnail(avatarURL).
+		InlineField("🏷️ Username", targetUser.Username).
+		InlineField("🆔 User ID", targetUser.ID).
+		Field("🗓️ Account Created", fmt.Sprintf("<t:%d:F>", userCreated.Unix())).
+		Build()
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	return respondWithEmbed(s, i, embed)
 }
@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/i18n"
+)
+
+// optionFormat is a check on a string option that Discord has no option setting for
+type optionFormat int
+
+const (
+	formatNotBlank optionFormat = iota + 1 // More than whitespace
+	formatURL                              // An http or https link
+	formatDuration                         // A positive duration such as 30m or 2h
+)
+
+// optionFormats lists the format checks of string options by command and option path, such as
+// "admin logs module" for an option of a subcommand
+var optionFormats = map[string]optionFormat{
+	"8ball question":           formatNotBlank,
+	"weather city":             formatNotBlank,
+	"music radio station":      formatNotBlank,
+	"summarize since":          formatDuration,
+	"music settings wake_word": formatNotBlank,
+}
+
+// OptionError explains why an option value was refused, worded for the user who gave it
+type OptionError struct {
+	Option string
+	Reason string // Catalog key of the reason
+	Args   []any  // Values the reason is formatted with
+}
+
+func (e *OptionError) Error() string {
+	return e.Message(i18n.Default)
+}
+
+// Message explains the error in a language
+func (e *OptionError) Message(language string) string {
+	return fmt.Sprintf("`%s` %s", e.Option, i18n.T(language, e.Reason, e.Args...))
+}
+
+// CheckOptions is the command middleware for option values. It checks them against the command's
+// definition: the ranges, lengths and choices Discord enforces in its client, which commands registered
+// before a change and other clients can get around, plus the format checks in optionFormats. Invalid
+// values are answered with an ephemeral message and false is returned so the handler doesn't run.
+func CheckOptions(s SessionInterface, i *discordgo.InteractionCreate, command *discordgo.ApplicationCommand) (bool, error) {
+	if command == nil || i.Type != discordgo.InteractionApplicationCommand {
+		return true, nil
+	}
+
+	err := ValidateOptions(command, i.ApplicationCommandData())
+	if err == nil {
+		return true, nil
+	}
+	return false, respondWithEphemeral(s, i, "❌ "+err.Message(responseLanguage(i)))
+}
+
+// ValidateOptions checks an interaction's option values against the command's definition
+func ValidateOptions(command *discordgo.ApplicationCommand, data discordgo.ApplicationCommandInteractionData) *OptionError {
+	return validateOptionList(command.Name, command.Options, data.Options)
+}
+
+// validateOptionList checks the given options against their definitions, descending into subcommands.
+// Options the definition doesn't know are left alone, they come from an older registration.
+func validateOptionList(path string, defined []*discordgo.ApplicationCommandOption, given []*discordgo.ApplicationCommandInteractionDataOption) *OptionError {
+	for _, option := range given {
+		definition := findOption(defined, option.Name)
+		if definition == nil {
+			continue
+		}
+		optionPath := path + " " + option.Name
+
+		switch option.Type {
+		case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+			if err := validateOptionList(optionPath, definition.Options, option.Options); err != nil {
+				return err
+			}
+		case discordgo.ApplicationCommandOptionInteger, discordgo.ApplicationCommandOptionNumber:
+			if err := validateNumber(definition, option); err != nil {
+				return err
+			}
+		case discordgo.ApplicationCommandOptionString:
+			if err := validateString(definition, option, optionFormats[optionPath]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// findOption returns the definition of a named option
+func findOption(options []*discordgo.ApplicationCommandOption, name string) *discordgo.ApplicationCommandOption {
+	for _, option := range options {
+		if option.Name == name {
+			return option
+		}
+	}
+	return nil
+}
+
+// validateNumber checks an integer or number option against its range and choices
+func validateNumber(definition *discordgo.ApplicationCommandOption, option *discordgo.ApplicationCommandInteractionDataOption) *OptionError {
+	value, ok := numericValue(option.Value)
+	if !ok {
+		return &OptionError{Option: option.Name, Reason: "option.number"}
+	}
+
+	if len(definition.Choices) > 0 {
+		return validateChoice(definition, option.Name, func(choice any) bool {
+			number, ok := numericValue(choice)
+			return ok && number == value
+		})
+	}
+
+	minValue, hasMin := 0.0, definition.MinValue != nil
+	if hasMin {
+		minValue = *definition.MinValue
+	}
+	// A max_value of 0 is left out when the command is registered, so it means there is no maximum
+	maxValue, hasMax := definition.MaxValue, definition.MaxValue != 0
+
+	switch {
+	case hasMin && hasMax && (value < minValue || value > maxValue):
+		return &OptionError{Option: option.Name, Reason: "option.between", Args: []any{formatNumber(minValue), formatNumber(maxValue)}}
+	case hasMin && value < minValue:
+		return &OptionError{Option: option.Name, Reason: "option.at_least", Args: []any{formatNumber(minValue)}}
+	case hasMax && value > maxValue:
+		return &OptionError{Option: option.Name, Reason: "option.at_most", Args: []any{formatNumber(maxValue)}}
+	}
+	return nil
+}
+
+// validateString checks a string option against its length, choices and format
+func validateString(definition *discordgo.ApplicationCommandOption, option *discordgo.ApplicationCommandInteractionDataOption, format optionFormat) *OptionError {
+	value, ok := option.Value.(string)
+	if !ok {
+		return &OptionError{Option: option.Name, Reason: "option.text"}
+	}
+
+	if len(definition.Choices) > 0 {
+		return validateChoice(definition, option.Name, func(choice any) bool { return choice == value })
+	}
+
+	length := len([]rune(value))
+	if definition.MinLength != nil && length < *definition.MinLength {
+		return &OptionError{Option: option.Name, Reason: "option.min_length", Args: []any{*definition.MinLength}}
+	}
+	if definition.MaxLength > 0 && length > definition.MaxLength {
+		return &OptionError{Option: option.Name, Reason: "option.max_length", Args: []any{definition.MaxLength}}
+	}
+
+	switch format {
+	case formatNotBlank:
+		if strings.TrimSpace(value) == "" {
+			return &OptionError{Option: option.Name, Reason: "option.empty"}
+		}
+	case formatURL:
+		if parsed, err := url.Parse(strings.TrimSpace(value)); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return &OptionError{Option: option.Name, Reason: "option.url"}
+		}
+	case formatDuration:
+		if duration, err := time.ParseDuration(strings.TrimSpace(value)); err != nil || duration <= 0 {
+			return &OptionError{Option: option.Name, Reason: "option.duration"}
+		}
+	}
+	return nil
+}
+
+// validateChoice checks that an option's value is one of its choices
+func validateChoice(definition *discordgo.ApplicationCommandOption, name string, matches func(choice any) bool) *OptionError {
+	names := make([]string, 0, len(definition.Choices))
+	for _, choice := range definition.Choices {
+		if matches(choice.Value) {
+			return nil
+		}
+		names = append(names, choice.Name)
+	}
+	return &OptionError{Option: name, Reason: "option.choice", Args: []any{strings.Join(names, ", ")}}
+}
+
+// numericValue reads a number option's value, which is a float64 once decoded from JSON
+func numericValue(value any) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case int64:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	}
+	return 0, false
+}
+
+// formatNumber prints a range bound without a trailing .0
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
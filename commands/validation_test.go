@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/testutils"
+)
+
+func validationTestCommand() *discordgo.ApplicationCommand {
+	minHours, maxHours := 1.0, 168.0
+	minLength := 1
+	return &discordgo.ApplicationCommand{
+		Name: "weather",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Type: discordgo.ApplicationCommandOptionString, Name: "city", MinLength: &minLength, MaxLength: 10},
+			{Type: discordgo.ApplicationCommandOptionInteger, Name: "hours", MinValue: &minHours, MaxValue: maxHours},
+			{Type: discordgo.ApplicationCommandOptionString, Name: "mode", Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "On", Value: "on"},
+				{Name: "Off", Value: "off"},
+			}},
+			{Type: discordgo.ApplicationCommandOptionSubCommand, Name: "seek", Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionInteger, Name: "seconds", MinValue: new(float64)},
+			}},
+		},
+	}
+}
+
+func stringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Type: discordgo.ApplicationCommandOptionString, Name: name, Value: value}
+}
+
+func integerOption(name string, value float64) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Type: discordgo.ApplicationCommandOptionInteger, Name: name, Value: value}
+}
+
+func TestValidateOptions(t *testing.T) {
+	command := validationTestCommand()
+	tests := []struct {
+		name    string
+		options []*discordgo.ApplicationCommandInteractionDataOption
+		want    string
+	}{
+		{name: "valid values", options: []*discordgo.ApplicationCommandInteractionDataOption{
+			stringOption("city", "Jazz FM"), integerOption("hours", 6), stringOption("mode", "on"),
+		}},
+		{name: "below range", options: []*discordgo.ApplicationCommandInteractionDataOption{integerOption("hours", 0)},
+			want: "`hours` must be between 1 and 168"},
+		{name: "above range", options: []*discordgo.ApplicationCommandInteractionDataOption{integerOption("hours", 169)},
+			want: "`hours` must be between 1 and 168"},
+		{name: "too long", options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("city", "Radio Paradise Main Mix")},
+			want: "`city` must be at most 10 characters"},
+		{name: "length counts characters", options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("city", "ÄÖÜ Radio")}},
+		{name: "blank", options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("city", "   ")},
+			want: "`city` can't be empty"},
+		{name: "not a choice", options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("mode", "maybe")},
+			want: "`mode` must be one of: On, Off"},
+		{name: "subcommand options", options: []*discordgo.ApplicationCommandInteractionDataOption{{
+			Type: discordgo.ApplicationCommandOptionSubCommand, Name: "seek",
+			Options: []*discordgo.ApplicationCommandInteractionDataOption{integerOption("seconds", -5)},
+		}}, want: "`seconds` must be at least 0"},
+		{name: "unknown options are left alone", options: []*discordgo.ApplicationCommandInteractionDataOption{stringOption("legacy", "")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOptions(command, discordgo.ApplicationCommandInteractionData{Options: tt.options})
+			if tt.want == "" {
+				assert.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestValidateStringFormats(t *testing.T) {
+	definition := &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionString, Name: "link"}
+
+	assert.Nil(t, validateString(definition, stringOption("link", "https://example.com/stream.mp3"), formatURL))
+	for _, value := range []string{"example.com", "ftp://example.com/file", "https://", "not a link"} {
+		err := validateString(definition, stringOption("link", value), formatURL)
+		require.NotNil(t, err, value)
+		assert.Equal(t, "`link` must be a link starting with http:// or https://", err.Error())
+	}
+
+	assert.Nil(t, validateString(definition, stringOption("since", "90m"), formatDuration))
+	for _, value := range []string{"2 hours", "-1h", "0s"} {
+		require.NotNil(t, validateString(definition, stringOption("since", value), formatDuration), value)
+	}
+}
+
+func TestCheckOptions(t *testing.T) {
+	command := validationTestCommand()
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("weather", []*discordgo.ApplicationCommandInteractionDataOption{integerOption("hours", 500)})
+	valid, err := CheckOptions(mockSession, interaction, command)
+	require.NoError(t, err)
+	assert.False(t, valid)
+	require.NotNil(t, mockSession.RespondData)
+	assert.Equal(t, "❌ `hours` must be between 1 and 168", mockSession.RespondData.Content)
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+
+	interaction = testutils.CreateTestInteraction("weather", []*discordgo.ApplicationCommandInteractionDataOption{integerOption("hours", 5)})
+	valid, err = CheckOptions(&testutils.MockSession{}, interaction, command)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, _ = CheckOptions(&testutils.MockSession{}, interaction, nil)
+	assert.True(t, valid, "commands without a definition run unchecked")
+}
+
+func TestCheckOptionsAnswersInServerLanguage(t *testing.T) {
+	store := useGuildSettings(t)
+	_, err := store.Update("guild_id_123", func(g *settings.Guild) { g.Language = "de" })
+	require.NoError(t, err)
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("weather", []*discordgo.ApplicationCommandInteractionDataOption{integerOption("hours", 500)})
+	_, err = CheckOptions(mockSession, interaction, validationTestCommand())
+	require.NoError(t, err)
+	assert.Equal(t, "❌ `hours` muss zwischen 1 und 168 liegen", mockSession.RespondData.Content)
+}
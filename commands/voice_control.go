@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/music/voicecontrol"
+	"pxnx-discord-bot/services/stt"
+	"pxnx-discord-bot/utils"
+)
+
+// voiceCommandTimeout bounds transcribing and running one spoken command
+const voiceCommandTimeout = 45 * time.Second
+
+// volumeStep is how much "louder" and "quieter" change the volume, in percent
+const volumeStep = 20
+
+// errVoiceQuota is returned for spoken song requests beyond the member's hourly quota
+var errVoiceQuota = errors.New("the member used up their song requests")
+
+// runVoiceCommand transcribes what a member said in the voice channel and runs the command following the
+// wake word. Speech without one is dropped without being logged.
+func runVoiceCommand(guildID, wakeWord string, utterance voicecontrol.Utterance) {
+	request := utils.RequestInfo{GuildID: guildID, UserID: utterance.UserID, Command: "voice control"}
+	ctx, cancel := context.WithTimeout(utils.WithRequest(context.Background(), request), voiceCommandTimeout)
+	defer cancel()
+
+	transcript, err := Transcriber.TranscribeAudio(ctx, utterance.Audio, ".ogg")
+	if errors.Is(err, stt.ErrNoSpeech) {
+		return
+	}
+	if err != nil {
+		utils.LogWarnContext(ctx, "Failed to transcribe speech for voice control: %v", err)
+		return
+	}
+	action, ok := voicecontrol.Parse(transcript.Text, wakeWord)
+	if !ok {
+		return
+	}
+
+	utils.LogInfoContext(ctx, "Voice command: %q", transcript.Text)
+	if err := runVoiceAction(ctx, guildID, utterance.UserID, action); err != nil {
+		utils.LogWarnContext(ctx, "Voice command %q failed: %v", transcript.Text, err)
+	}
+}
+
+// runVoiceAction runs a spoken command with the player action of the slash command of the same name
+func runVoiceAction(ctx context.Context, guildID, userID string, action voicecontrol.Action) error {
+	player, connected := SimplePlayer.GetPlayer(guildID)
+	if !connected {
+		return music.ErrNotConnected
+	}
+
+	switch action.Kind {
+	case voicecontrol.Skip:
+		player.Skip()
+	case voicecontrol.Pause:
+		player.Pause()
+	case voicecontrol.Resume:
+		player.Resume()
+	case voicecontrol.Stop:
+		player.Stop()
+	case voicecontrol.Shuffle:
+		player.ShuffleQueue()
+	case voicecontrol.Volume:
+		return SimplePlayer.SetVolume(guildID, clampVolume(action.Volume))
+	case voicecontrol.Louder:
+		return SimplePlayer.SetVolume(guildID, clampVolume(SimplePlayer.Volume(guildID)+volumeStep))
+	case voicecontrol.Quieter:
+		return SimplePlayer.SetVolume(guildID, clampVolume(SimplePlayer.Volume(guildID)-volumeStep))
+	case voicecontrol.Play:
+		// Spoken requests count towards the same hourly quota as /play
+		if _, ok := MusicQuota.Take(userID, Votes.HasPerk(userID)); !ok {
+			return errVoiceQuota
+		}
+		track, err := SimplePlayer.Play(guildID, action.Query, music.TrackRequest{RequestedBy: userID})
+		if err != nil {
+			return err
+		}
+		utils.LogInfoContext(ctx, "Queued %s by voice", track.Title)
+	default:
+		return fmt.Errorf("unknown voice command %d", action.Kind)
+	}
+	return nil
+}
+
+// clampVolume keeps a spoken volume within what the player accepts
+func clampVolume(volume int) int {
+	return max(1, min(volume, settings.MaxVolume))
+}
@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/music/voicecontrol"
+)
+
+func TestRunVoiceActionNotConnected(t *testing.T) {
+	original := SimplePlayer
+	SimplePlayer = music.NewSimplePlayer(nil)
+	defer func() { SimplePlayer = original }()
+
+	err := runVoiceAction(t.Context(), "guild_id_123", "user_id", voicecontrol.Action{Kind: voicecontrol.Skip})
+	assert.ErrorIs(t, err, music.ErrNotConnected)
+}
+
+func TestClampVolume(t *testing.T) {
+	assert.Equal(t, 1, clampVolume(-20))
+	assert.Equal(t, 80, clampVolume(80))
+	assert.Equal(t, settings.MaxVolume, clampVolume(500))
+}
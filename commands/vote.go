@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
+	"pxnx-discord-bot/votes"
+)
+
+// maxVoteLinks is how many link buttons fit in one row of a message
+const maxVoteLinks = 5
+
+// VoteLink is a bot list page members vote on
+type VoteLink struct {
+	Name string
+	URL  string
+}
+
+var (
+	// Votes records members' votes on bot lists, received by the vote webhook
+	Votes = votes.New()
+
+	// VoteLinks are the bot list pages /vote links to, from VOTE_URLS
+	VoteLinks []VoteLink
+)
+
+// InitializeVotes loads saved votes from VOTES_FILE and the bot list pages from VOTE_URLS
+func InitializeVotes() {
+	path := VotesPath()
+	store, err := votes.Load(path)
+	if err != nil {
+		utils.LogWarn("Votes will not be saved this run: %v", err)
+		store = votes.New()
+	}
+	Votes = store
+	VoteLinks = ParseVoteLinks(os.Getenv("VOTE_URLS"))
+}
+
+// VotesPath returns where votes are saved, VOTES_FILE or the default
+func VotesPath() string {
+	if path := strings.TrimSpace(os.Getenv("VOTES_FILE")); path != "" {
+		return path
+	}
+	return votes.DefaultPath
+}
+
+// ParseVoteLinks reads a comma separated list of name=url pairs, such as
+// "top.gg=https://top.gg/bot/123/vote". Invalid entries are skipped with a warning.
+func ParseVoteLinks(raw string) []VoteLink {
+	var links []VoteLink
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, link, found := strings.Cut(entry, "=")
+		name, link = strings.TrimSpace(name), strings.TrimSpace(link)
+		parsed, err := url.Parse(link)
+		if !found || name == "" || err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			utils.LogWarn("Ignoring invalid VOTE_URLS entry %q, expected name=https://...", entry)
+			continue
+		}
+		if len(links) == maxVoteLinks {
+			utils.LogWarn("Ignoring VOTE_URLS entries after the first %d", maxVoteLinks)
+			break
+		}
+		links = append(links, VoteLink{Name: name, URL: link})
+	}
+	return links
+}
+
+// HandleVoteCommand handles the /vote command: it links to the bot list pages and shows the member's
+// votes and what voting unlocks
+func HandleVoteCommand(s SessionInterface, i *discordgo.InteractionCreate) error {
+	userID := getInteractionUserID(i)
+	vote, voted := Votes.Get(userID)
+
+	data := embedResponse(i, createVoteEmbed(VoteLinks, vote, voted, Votes.HasPerk(userID), MusicQuota))
+	data.Flags = discordgo.MessageFlagsEphemeral
+	if len(VoteLinks) > 0 {
+		buttons := make([]discordgo.MessageComponent, 0, len(VoteLinks))
+		for _, link := range VoteLinks {
+			buttons = append(buttons, discordgo.Button{Label: link.Name, Style: discordgo.LinkButton, URL: link.URL})
+		}
+		data.Components = []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}}
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	})
+}
+
+// createVoteEmbed describes voting, the perk it unlocks and the member's own votes
+func createVoteEmbed(links []VoteLink, vote votes.Vote, voted, perk bool, quota *RequestQuota) *discordgo.MessageEmbed {
+	if len(links) == 0 {
+		return NewEmbed("🗳️ Vote").
+			Style(StyleMuted).
+			Description("This bot isn't listed on any bot lists yet.").
+			Build()
+	}
+
+	description := "Voting helps more people find the bot, thank you! Votes show up here within a minute."
+	if limit := quota.Limit(false); limit > 0 {
+		description += fmt.Sprintf("\n\nFor %d hours after voting you can request **%d** songs an hour instead of %d.",
+			int(votes.PerkDuration.Hours()), quota.Limit(true), limit)
+	}
+
+	status := "You haven't voted yet"
+	if voted {
+		status = fmt.Sprintf("Voted %d times, last on %s <t:%d:R>", vote.Total, vote.Site, vote.Last.Unix())
+		if perk {
+			status += fmt.Sprintf("\n⭐ Perks active until <t:%d:t>", vote.PerkExpires().Unix())
+		}
+	}
+
+	embed := NewEmbed("🗳️ Vote")
+	if perk {
+		embed.Color(0xf1c40f) // Gold
+	}
+	return embed.Description(description).Field("Your Votes", status).Build()
+}
+
+// quotaExceededMessage tells a member they are out of song requests and when they can request again
+func quotaExceededMessage(quota *RequestQuota, voter bool, retryAt int64) string {
+	message := fmt.Sprintf("You can request %d songs an hour, try again <t:%d:R>", quota.Limit(voter), retryAt)
+	if !voter && len(VoteLinks) > 0 {
+		message += fmt.Sprintf(", or `/vote` for the bot to get %d", quota.Limit(true))
+	}
+	return message
+}
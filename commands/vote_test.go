@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/testutils"
+	"pxnx-discord-bot/votes"
+)
+
+// useVotes installs an in-memory vote store and bot list links for the duration of a test
+func useVotes(t *testing.T, links []VoteLink) *votes.Store {
+	originalVotes, originalLinks := Votes, VoteLinks
+	Votes, VoteLinks = votes.New(), links
+	t.Cleanup(func() { Votes, VoteLinks = originalVotes, originalLinks })
+	return Votes
+}
+
+func TestParseVoteLinks(t *testing.T) {
+	links := ParseVoteLinks(" top.gg=https://top.gg/bot/123/vote , nonsense, empty=, ftp=ftp://example.com,Discord Bot List=https://discordbotlist.com/bots/pxnx/upvote,")
+
+	assert.Equal(t, []VoteLink{
+		{Name: "top.gg", URL: "https://top.gg/bot/123/vote"},
+		{Name: "Discord Bot List", URL: "https://discordbotlist.com/bots/pxnx/upvote"},
+	}, links)
+	assert.Empty(t, ParseVoteLinks(""))
+}
+
+func TestHandleVoteCommand(t *testing.T) {
+	store := useVotes(t, []VoteLink{{Name: "top.gg", URL: "https://top.gg/bot/123/vote"}})
+	_, err := store.Record("user_1", "top.gg")
+	require.NoError(t, err)
+
+	mockSession := &testutils.MockSession{}
+	interaction := testutils.CreateTestInteraction("vote", nil)
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "user_1"}}
+
+	require.NoError(t, HandleVoteCommand(mockSession, interaction))
+	require.True(t, mockSession.RespondCalled)
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, mockSession.RespondData.Flags)
+	assert.Contains(t, mockSession.RespondData.Embeds[0].Fields[0].Value, "Voted 1 times, last on top.gg")
+	assert.Contains(t, mockSession.RespondData.Embeds[0].Fields[0].Value, "Perks active")
+
+	require.Len(t, mockSession.RespondData.Components, 1)
+	button := mockSession.RespondData.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.Button)
+	assert.Equal(t, discordgo.LinkButton, button.Style)
+	assert.Equal(t, "https://top.gg/bot/123/vote", button.URL)
+}
+
+func TestCreateVoteEmbed(t *testing.T) {
+	links := []VoteLink{{Name: "top.gg", URL: "https://top.gg/bot/123/vote"}}
+
+	unlisted := createVoteEmbed(nil, votes.Vote{}, false, false, NewRequestQuota(10))
+	assert.Contains(t, unlisted.Description, "isn't listed")
+	assert.Empty(t, unlisted.Fields)
+
+	embed := createVoteEmbed(links, votes.Vote{}, false, false, NewRequestQuota(10))
+	assert.Contains(t, embed.Description, "request **20** songs an hour instead of 10")
+	assert.Equal(t, "You haven't voted yet", embed.Fields[0].Value)
+
+	noQuota := createVoteEmbed(links, votes.Vote{Last: time.Now(), Site: "top.gg", Total: 3}, true, false, NewRequestQuota(0))
+	assert.NotContains(t, noQuota.Description, "songs an hour")
+	assert.NotContains(t, noQuota.Fields[0].Value, "Perks active")
+}
+
+func TestQuotaExceededMessage(t *testing.T) {
+	useVotes(t, nil)
+	quota := NewRequestQuota(10)
+	assert.Equal(t, "You can request 10 songs an hour, try again <t:1740830400:R>", quotaExceededMessage(quota, false, 1740830400))
+
+	VoteLinks = []VoteLink{{Name: "top.gg", URL: "https://top.gg/bot/123/vote"}}
+	assert.Contains(t, quotaExceededMessage(quota, false, 1740830400), "or `/vote` for the bot to get 20")
+	assert.Equal(t, "You can request 20 songs an hour, try again <t:1740830400:R>", quotaExceededMessage(quota, true, 1740830400))
+}
@@ -33,19 +33,12 @@ func getWeatherIcon(condition string) string {
 	}
 }
 
-// createErrorEmbed creates a standardized error embed
-func createErrorEmbed(title, description, errorMsg string) *discordgo.MessageEmbed {
-	return &discordgo.MessageEmbed{
-		Title:       title,
-		Description: description,
-		Color:       0xe74c3c, // ColorRed
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:  "Error",
-				Value: errorMsg,
-			},
-		},
-	}
+// weatherFooter credits the weather data's source
+const weatherFooter = "Powered by OpenWeatherMap"
+
+// createErrorEmbed starts a standardized error embed
+func createErrorEmbed(title, description, errorMsg string) *EmbedBuilder {
+	return ErrorEmbed(title).Description(description).Field("Error", errorMsg)
 }
 
 // HandleWeatherCommand handles the weather slash command
@@ -80,17 +73,9 @@ func handleCurrentWeather(s SessionInterface, i *discordgo.InteractionCreate, ci
 			"❌ Weather Error",
 			fmt.Sprintf("Unable to fetch weather data for **%s**", city),
 			"City not found or API error. Please check the city name and try again.",
-		)
-		errorEmbed.Footer = &discordgo.MessageEmbedFooter{
-			Text: "Powered by OpenWeatherMap",
-		}
+		).Footer(weatherFooter).Build()
 
-		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Embeds: []*discordgo.MessageEmbed{errorEmbed},
-			},
-		})
+		return respondWithEmbed(s, i, errorEmbed)
 	}
 
 	// Format temperature
@@ -112,48 +97,20 @@ func handleCurrentWeather(s SessionInterface, i *discordgo.InteractionCreate, ci
 		location = fmt.Sprintf("%s, %s", weatherData.Name, weatherData.Sys.Country)
 	}
 
-	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("%s Weather in %s", weatherIcon, location),
-		Description: description,
-		Color:       0x3498db, // ColorBlue
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "🌡️ Temperature",
-				Value:  temp,
-				Inline: true,
-			},
-			{
-				Name:   "🤏 Feels Like",
-				Value:  feelsLike,
-				Inline: true,
-			},
-			{
-				Name:   "💧 Humidity",
-				Value:  fmt.Sprintf("%d%%", weatherData.Main.Humidity),
-				Inline: true,
-			},
-		},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Powered by OpenWeatherMap",
-		},
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
+	embed := NewEmbed(fmt.Sprintf("%s Weather in %s", weatherIcon, location)).
+		Description(description).
+		InlineField("🌡️ Temperature", temp).
+		InlineField("🤏 Feels Like", feelsLike).
+		InlineField("💧 Humidity", fmt.Sprintf("%d%%", weatherData.Main.Humidity)).
+		Footer(weatherFooter).
+		Timestamp(time.Now())
 
 	// Add wind information if available
 	if weatherData.Wind.Speed > 0 {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   "💨 Wind Speed",
-			Value:  fmt.Sprintf("%.1f m/s", weatherData.Wind.Speed),
-			Inline: true,
-		})
+		embed.InlineField("💨 Wind Speed", fmt.Sprintf("%.1f m/s", weatherData.Wind.Speed))
 	}
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	return respondWithEmbed(s, i, embed.Build())
 }
 
 // handleForecast handles forecast requests (1-day or multi-day)
@@ -165,17 +122,9 @@ func handleForecast(s SessionInterface, i *discordgo.InteractionCreate, city str
 			"❌ Forecast Error",
 			fmt.Sprintf("Unable to fetch forecast data for **%s**", city),
 			"City not found or API error. Please check the city name and try again.",
-		)
-		errorEmbed.Footer = &discordgo.MessageEmbedFooter{
-			Text: "Powered by OpenWeatherMap",
-		}
+		).Footer(weatherFooter).Build()
 
-		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Embeds: []*discordgo.MessageEmbed{errorEmbed},
-			},
-		})
+		return respondWithEmbed(s, i, errorEmbed)
 	}
 
 	// Process forecast data into daily summaries
@@ -198,16 +147,10 @@ func handleForecast(s SessionInterface, i *discordgo.InteractionCreate, city str
 		durationText = fmt.Sprintf("%d-Day", days)
 	}
 
-	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("📅 %s Forecast for %s", durationText, location),
-		Description: fmt.Sprintf("Weather forecast for the next %d day(s)", len(dailyForecasts)),
-		Color:       0x3498db, // ColorBlue
-		Fields:      []*discordgo.MessageEmbedField{},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Powered by OpenWeatherMap",
-		},
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
+	embed := NewEmbed(fmt.Sprintf("📅 %s Forecast for %s", durationText, location)).
+		Descriptionf("Weather forecast for the next %d day(s)", len(dailyForecasts)).
+		Footer(weatherFooter).
+		Timestamp(time.Now())
 
 	// Add daily forecast fields
 	for i, daily := range dailyForecasts {
@@ -232,17 +175,13 @@ func handleForecast(s SessionInterface, i *discordgo.InteractionCreate, city str
 			value += fmt.Sprintf("\n💨 %.1f m/s", daily.WindSpeed)
 		}
 
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   dateStr,
-			Value:  value,
-			Inline: days == 1, // For 1-day forecast, don't inline to show more detail
-		})
+		// For 1-day forecast, don't inline to show more detail
+		if days == 1 {
+			embed.InlineField(dateStr, value)
+		} else {
+			embed.Field(dateStr, value)
+		}
 	}
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-		},
-	})
+	return respondWithEmbed(s, i, embed.Build())
 }
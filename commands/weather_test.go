@@ -79,7 +79,7 @@ func TestCreateErrorEmbed(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			embed := createErrorEmbed(tt.title, tt.description, tt.errorMsg)
+			embed := createErrorEmbed(tt.title, tt.description, tt.errorMsg).Build()
 
 			if embed.Title != tt.title {
 				t.Errorf("Expected title '%s', got '%s'", tt.title, embed.Title)
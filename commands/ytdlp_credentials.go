@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/utils"
+)
+
+// LoadYtdlpCredentials reads the cookies and PO token yt-dlp signs in to YouTube with from the
+// environment. YTDLP_PO_TOKEN_FILE is read each time, so a rotated token is picked up on reload.
+func LoadYtdlpCredentials() (ytdlp.Credentials, error) {
+	credentials := ytdlp.Credentials{
+		CookiesFile:        strings.TrimSpace(os.Getenv("YTDLP_COOKIES_FILE")),
+		CookiesFromBrowser: strings.TrimSpace(os.Getenv("YTDLP_COOKIES_FROM_BROWSER")),
+		POToken:            strings.TrimSpace(os.Getenv("YTDLP_PO_TOKEN")),
+	}
+	if path := strings.TrimSpace(os.Getenv("YTDLP_PO_TOKEN_FILE")); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return ytdlp.Credentials{}, fmt.Errorf("PO token file can't be read: %w", err)
+		}
+		credentials.POToken = strings.TrimSpace(string(data))
+	}
+	if err := credentials.Validate(); err != nil {
+		return ytdlp.Credentials{}, err
+	}
+	return credentials, nil
+}
+
+// LoadYtdlpProxies reads the proxies yt-dlp runs take turns between from YTDLP_PROXY, a single proxy,
+// and YTDLP_PROXIES, a comma separated list. Invalid proxies are skipped with a warning.
+func LoadYtdlpProxies() []string {
+	config := ytdlp.ServiceConfig{
+		Proxy:   strings.TrimSpace(os.Getenv("YTDLP_PROXY")),
+		Proxies: ytdlp.ParseProxyList(os.Getenv("YTDLP_PROXIES")),
+	}
+
+	var proxies []string
+	for _, proxy := range config.ProxyList() {
+		if err := ytdlp.ValidateProxy(proxy); err != nil {
+			utils.LogWarn("Skipping yt-dlp proxy: %v", err)
+			continue
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
+
+// maskSecret hides all but the last four characters of a token
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("•", len(secret))
+	}
+	return strings.Repeat("•", 8) + secret[len(secret)-4:]
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/testutils"
+)
+
+func TestLoadYtdlpCredentials(t *testing.T) {
+	dir := t.TempDir()
+	cookies := filepath.Join(dir, "cookies.txt")
+	require.NoError(t, os.WriteFile(cookies, []byte("# Netscape HTTP Cookie File\n"), 0o600))
+	tokenFile := filepath.Join(dir, "po_token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("web.gvs+from_file\n"), 0o600))
+
+	t.Setenv("YTDLP_COOKIES_FILE", cookies)
+	t.Setenv("YTDLP_COOKIES_FROM_BROWSER", "")
+	t.Setenv("YTDLP_PO_TOKEN", "from_env")
+	t.Setenv("YTDLP_PO_TOKEN_FILE", tokenFile)
+
+	credentials, err := LoadYtdlpCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, ytdlp.Credentials{CookiesFile: cookies, POToken: "web.gvs+from_file"}, credentials)
+
+	t.Setenv("YTDLP_COOKIES_FROM_BROWSER", "firefox")
+	_, err = LoadYtdlpCredentials()
+	assert.ErrorContains(t, err, "not both")
+
+	t.Setenv("YTDLP_COOKIES_FROM_BROWSER", "")
+	t.Setenv("YTDLP_PO_TOKEN_FILE", filepath.Join(dir, "missing"))
+	_, err = LoadYtdlpCredentials()
+	assert.ErrorContains(t, err, "PO token file")
+}
+
+func TestHandleAdminCredentialsRequiresOwner(t *testing.T) {
+	SetBotOwners([]string{"owner_id"})
+	defer SetBotOwners(nil)
+
+	mockSession := &testutils.MockSession{}
+	interaction := newAdminInteraction("credentials")
+	interaction.Member = &discordgo.Member{User: &discordgo.User{ID: "someone_else"}}
+
+	require.NoError(t, HandleAdminCommand(mockSession, interaction))
+	assert.Equal(t, "❌ Only the bot owner can manage yt-dlp credentials", mockSession.RespondData.Content)
+}
+
+func TestCreateCredentialsEmbed(t *testing.T) {
+	embed := createCredentialsEmbed(ytdlp.Credentials{CookiesFromBrowser: "firefox", POToken: "web.gvs+secret1234"}, true)
+	assert.Equal(t, "From firefox", embed.Fields[0].Value)
+	assert.Equal(t, "`••••••••1234`", embed.Fields[1].Value)
+	assert.NotContains(t, embed.Fields[1].Value, "secret")
+	assert.Contains(t, embed.Description, "Reloaded")
+	assert.Nil(t, embed.Footer)
+
+	missing := createCredentialsEmbed(ytdlp.Credentials{CookiesFile: filepath.Join(t.TempDir(), "gone.txt")}, false)
+	assert.Contains(t, missing.Fields[0].Value, "Can't be read")
+	assert.Equal(t, "None", missing.Fields[1].Value)
+
+	empty := createCredentialsEmbed(ytdlp.Credentials{}, false)
+	assert.Equal(t, "None", empty.Fields[0].Value)
+	require.NotNil(t, empty.Footer)
+}
+
+func TestLoadYtdlpProxies(t *testing.T) {
+	t.Setenv("YTDLP_PROXY", "http://single:3128")
+	t.Setenv("YTDLP_PROXIES", "socks5://a:1080, not-a-proxy,socks5://b:1080")
+
+	assert.Equal(t, []string{"http://single:3128", "socks5://a:1080", "socks5://b:1080"}, LoadYtdlpProxies())
+
+	t.Setenv("YTDLP_PROXY", "")
+	t.Setenv("YTDLP_PROXIES", "")
+	assert.Empty(t, LoadYtdlpProxies())
+}
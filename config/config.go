@@ -0,0 +1,314 @@
+// Package config loads the bot's settings into one typed Config: built-in defaults, then a YAML file,
+// then environment variables, so a secret or a one-off change in the environment wins over the file.
+// Settings that the running bot can change are reloaded on SIGHUP; the others, tagged reload:"restart",
+// keep their value until the bot restarts.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"pxnx-discord-bot/music"
+	"pxnx-discord-bot/music/premium"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/services/llm"
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultPath is the file loaded when CONFIG_FILE and -config aren't set, if it exists
+const DefaultPath = "config.yaml"
+
+// Config is every setting of the bot. The yaml tag is the key in the file, env the variable overriding it.
+type Config struct {
+	Discord     Discord `yaml:"discord"`
+	Log         Log     `yaml:"log"`
+	Music       Music   `yaml:"music"`
+	AI          AI      `yaml:"ai"`
+	WorkerPools string  `yaml:"worker_pools" env:"WORKER_POOLS" reload:"restart"` // Such as prefetch=8,playlist=2
+
+	// Environment holds any other variable, such as MUSIC_BACKEND or METRICS_ADDR, set only when the
+	// environment doesn't set it already
+	Environment map[string]string `yaml:"environment" reload:"restart"`
+}
+
+// Discord is how the bot connects
+type Discord struct {
+	Token         string `yaml:"token" env:"DISCORD_BOT_TOKEN" reload:"restart"`
+	CommandGuilds string `yaml:"command_guilds" env:"DISCORD_COMMAND_GUILDS" reload:"restart"` // Guild IDs --register-commands registers in, such as 123,456; empty registers globally
+}
+
+// CommandGuildIDs returns the guilds commands are registered in, none to register them globally
+func (d Discord) CommandGuildIDs() ([]string, error) {
+	var ids []string
+	for _, id := range strings.Split(d.CommandGuilds, ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			return nil, fmt.Errorf("%q is not a guild ID", id)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Log is what the bot logs and how long log files are kept
+type Log struct {
+	Level         string `yaml:"level" env:"LOG_LEVEL"`                              // error, warn, info or debug
+	Modules       string `yaml:"modules" env:"LOG_MODULES"`                          // Such as music=debug,services/ytdlp=warn
+	Format        string `yaml:"format" env:"LOG_FORMAT" reload:"restart"`           // text or json
+	BufferSize    int    `yaml:"buffer_size" env:"LOG_BUFFER_SIZE" reload:"restart"` // Warnings and errors kept for /admin logs
+	MaxSizeMB     int    `yaml:"max_size_mb" env:"LOG_MAX_SIZE_MB"`                  // 0 rotates at midnight only
+	RetentionDays int    `yaml:"retention_days" env:"LOG_RETENTION_DAYS"`            // 0 keeps rotated files forever
+	MaxFiles      int    `yaml:"max_files" env:"LOG_MAX_FILES"`                      // 0 for no limit
+	Compress      bool   `yaml:"compress" env:"LOG_COMPRESS"`
+}
+
+// Music is how the player behaves in guilds that didn't choose otherwise
+type Music struct {
+	DefaultVolume   int           `yaml:"default_volume" env:"MUSIC_DEFAULT_VOLUME"`       // Percent, 1 to 200
+	AloneTimeout    time.Duration `yaml:"alone_timeout" env:"MUSIC_ALONE_TIMEOUT"`         // Wait before leaving an empty channel
+	ResolveTimeout  time.Duration `yaml:"resolve_timeout" env:"MUSIC_RESOLVE_TIMEOUT"`     // Limit on resolving a single track
+	MaxQueue        int           `yaml:"max_queue" env:"MUSIC_MAX_QUEUE"`                 // Longest queue of free guilds, 0 for no limit
+	PremiumMaxQueue int           `yaml:"premium_max_queue" env:"MUSIC_PREMIUM_MAX_QUEUE"` // Longest queue of premium guilds
+}
+
+// AI bounds what every guild's persona can make the /ask language model do
+type AI struct {
+	SafetyPrompt       string        `yaml:"safety_prompt" env:"AI_SAFETY_PROMPT"`             // Instructions before every persona
+	DefaultTemperature float64       `yaml:"default_temperature" env:"AI_DEFAULT_TEMPERATURE"` // Of guilds that didn't choose one
+	MaxTemperature     float64       `yaml:"max_temperature" env:"AI_MAX_TEMPERATURE"`         // Highest a guild can choose, 0 to 2
+	MaxTokens          int           `yaml:"max_tokens" env:"AI_MAX_TOKENS"`                   // Longest answer, 0 leaves it to the API
+	MaxPersonaLength   int           `yaml:"max_persona_length" env:"AI_MAX_PERSONA_LENGTH"`   // Characters, 0 turns personas off
+	MemoryExchanges    int           `yaml:"memory_exchanges" env:"AI_MEMORY_EXCHANGES"`       // Of a channel's conversation sent with a question, 0 for none
+	MemoryTTL          time.Duration `yaml:"memory_ttl" env:"AI_MEMORY_TTL"`                   // How long a conversation is remembered
+}
+
+// Default returns the settings the bot uses when nothing else is configured
+func Default() Config {
+	rotation := utils.DefaultLogRotation
+	ai := llm.DefaultLimits()
+	return Config{
+		Log: Log{
+			Level:         "info",
+			Format:        "text",
+			BufferSize:    utils.DefaultLogBufferSize,
+			MaxSizeMB:     int(rotation.MaxSize >> 20),
+			RetentionDays: int(rotation.MaxAge / (24 * time.Hour)),
+			MaxFiles:      rotation.MaxFiles,
+			Compress:      rotation.Compress,
+		},
+		Music: Music{
+			DefaultVolume:   settings.DefaultVolume,
+			AloneTimeout:    settings.DefaultAloneTimeout,
+			ResolveTimeout:  music.DefaultResolveTimeout,
+			MaxQueue:        premium.DefaultFreeMaxQueue,
+			PremiumMaxQueue: premium.DefaultPremiumMaxQueue,
+		},
+		AI: AI{
+			SafetyPrompt:       ai.SafetyPrompt,
+			DefaultTemperature: ai.DefaultTemperature,
+			MaxTemperature:     ai.MaxTemperature,
+			MaxTokens:          ai.MaxTokens,
+			MaxPersonaLength:   ai.MaxPersonaLength,
+			MemoryExchanges:    ai.MemoryExchanges,
+			MemoryTTL:          ai.MemoryTTL,
+		},
+	}
+}
+
+// FilePath returns the config file to load: path when set, else $CONFIG_FILE, else DefaultPath when it
+// exists, else none
+func FilePath(path string) string {
+	if path = strings.TrimSpace(path); path != "" {
+		return path
+	}
+	if path = strings.TrimSpace(os.Getenv("CONFIG_FILE")); path != "" {
+		return path
+	}
+	if _, err := os.Stat(DefaultPath); err == nil {
+		return DefaultPath
+	}
+	return ""
+}
+
+// Load reads the defaults, the file at path and the environment. An empty path reads no file. The
+// config is usable even with an error: settings that failed to parse or validate keep their default,
+// and the error lists them. A file that can't be read or parsed returns nil.
+func Load(path string) (*Config, error) {
+	return load(path, nil)
+}
+
+// load reads a config like Load, changing it with override, if not nil, before validating it
+func load(path string, override func(*Config)) (*Config, error) {
+	config := Default()
+	if path != "" {
+		if err := config.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+	envErr := applyEnv(&config)
+	if override != nil {
+		override(&config)
+	}
+	return &config, errors.Join(envErr, config.validate())
+}
+
+// readFile decodes a YAML (or JSON) file over the config, rejecting keys the config doesn't have
+func (c *Config) readFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return fmt.Errorf("config file %s: unsupported format, expected .yaml, .yml or .json", path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// validate resets settings out of range to their default and reports them
+func (c *Config) validate() error {
+	defaults := Default()
+	var errs []error
+	invalid := func(name string, value any, expected string) {
+		errs = append(errs, fmt.Errorf("%s: invalid value %v, expected %s", name, value, expected))
+	}
+
+	if _, err := c.Discord.CommandGuildIDs(); err != nil {
+		errs = append(errs, fmt.Errorf("discord.command_guilds: %w", err))
+		c.Discord.CommandGuilds = defaults.Discord.CommandGuilds
+	}
+	if _, err := utils.ParseLogLevel(c.Log.Level); err != nil {
+		invalid("log.level", c.Log.Level, "error, warn, info or debug")
+		c.Log.Level = defaults.Log.Level
+	}
+	if _, err := utils.ParseModuleLevels(c.Log.Modules); err != nil {
+		errs = append(errs, fmt.Errorf("log.modules: %w", err))
+		c.Log.Modules = defaults.Log.Modules
+	}
+	if _, err := utils.ParseLogFormat(c.Log.Format); err != nil {
+		errs = append(errs, fmt.Errorf("log.format: %w", err))
+		c.Log.Format = defaults.Log.Format
+	}
+	if c.Log.BufferSize < 1 {
+		invalid("log.buffer_size", c.Log.BufferSize, "at least 1")
+		c.Log.BufferSize = defaults.Log.BufferSize
+	}
+	for _, setting := range []struct {
+		name  string
+		value *int
+	}{
+		{"log.max_size_mb", &c.Log.MaxSizeMB},
+		{"log.retention_days", &c.Log.RetentionDays},
+		{"log.max_files", &c.Log.MaxFiles},
+		{"music.max_queue", &c.Music.MaxQueue},
+		{"music.premium_max_queue", &c.Music.PremiumMaxQueue},
+		{"ai.max_tokens", &c.AI.MaxTokens},
+		{"ai.max_persona_length", &c.AI.MaxPersonaLength},
+		{"ai.memory_exchanges", &c.AI.MemoryExchanges},
+	} {
+		if *setting.value < 0 {
+			invalid(setting.name, *setting.value, "0 or more")
+			*setting.value = 0
+		}
+	}
+	if c.Music.DefaultVolume < 1 || c.Music.DefaultVolume > settings.MaxVolume {
+		invalid("music.default_volume", c.Music.DefaultVolume, fmt.Sprintf("1 to %d", settings.MaxVolume))
+		c.Music.DefaultVolume = defaults.Music.DefaultVolume
+	}
+	if c.Music.AloneTimeout <= 0 {
+		invalid("music.alone_timeout", c.Music.AloneTimeout, "a duration such as 15s")
+		c.Music.AloneTimeout = defaults.Music.AloneTimeout
+	}
+	if c.Music.ResolveTimeout <= 0 {
+		invalid("music.resolve_timeout", c.Music.ResolveTimeout, "a duration such as 30s")
+		c.Music.ResolveTimeout = defaults.Music.ResolveTimeout
+	}
+	// The safety prompt can be replaced but not removed
+	if strings.TrimSpace(c.AI.SafetyPrompt) == "" {
+		c.AI.SafetyPrompt = defaults.AI.SafetyPrompt
+	}
+	if c.AI.MaxTemperature < 0 || c.AI.MaxTemperature > llm.MaxTemperature {
+		invalid("ai.max_temperature", c.AI.MaxTemperature, fmt.Sprintf("0 to %g", llm.MaxTemperature))
+		c.AI.MaxTemperature = defaults.AI.MaxTemperature
+	}
+	if c.AI.DefaultTemperature < 0 || c.AI.DefaultTemperature > c.AI.MaxTemperature {
+		invalid("ai.default_temperature", c.AI.DefaultTemperature, fmt.Sprintf("0 to ai.max_temperature (%g)", c.AI.MaxTemperature))
+		c.AI.DefaultTemperature = min(defaults.AI.DefaultTemperature, c.AI.MaxTemperature)
+	}
+	if c.AI.MemoryTTL <= 0 {
+		invalid("ai.memory_ttl", c.AI.MemoryTTL, "a duration such as 15m")
+		c.AI.MemoryTTL = defaults.AI.MemoryTTL
+	}
+	if _, err := utils.ParsePoolSizes(c.WorkerPools); err != nil {
+		errs = append(errs, fmt.Errorf("worker_pools: %w", err))
+		c.WorkerPools = defaults.WorkerPools
+	}
+	return errors.Join(errs...)
+}
+
+// LogRotation returns when the log file rotates and how long rotated files are kept
+func (c *Config) LogRotation() utils.LogRotation {
+	return utils.LogRotation{
+		MaxSize:  int64(c.Log.MaxSizeMB) << 20,
+		MaxAge:   time.Duration(c.Log.RetentionDays) * 24 * time.Hour,
+		MaxFiles: c.Log.MaxFiles,
+		Compress: c.Log.Compress,
+	}
+}
+
+// SetEnvironment sets the variables of the environment section the environment doesn't set already, for
+// the settings still read from the environment
+func (c *Config) SetEnvironment() error {
+	var errs []error
+	for name, value := range c.Environment {
+		if _, set := os.LookupEnv(name); set {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			errs = append(errs, fmt.Errorf("environment %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply puts the settings the running bot can change into effect, at startup and after every reload.
+// Restart-only settings are applied by whoever starts the bot.
+func (c *Config) Apply() {
+	utils.SetLogLevel(utils.GetLogLevelFromString(c.Log.Level))
+	levels, _ := utils.ParseModuleLevels(c.Log.Modules)
+	utils.SetModuleLevels(levels)
+	utils.SetLogRotation(c.LogRotation())
+
+	settings.SetDefaults(settings.Defaults{Volume: c.Music.DefaultVolume, AloneTimeout: c.Music.AloneTimeout})
+	music.SetResolveTimeout(c.Music.ResolveTimeout)
+	premium.SetMaxQueue(premium.Free, c.Music.MaxQueue)
+	premium.SetMaxQueue(premium.Premium, c.Music.PremiumMaxQueue)
+
+	llm.SetLimits(llm.Limits{
+		SafetyPrompt:       c.AI.SafetyPrompt,
+		DefaultTemperature: c.AI.DefaultTemperature,
+		MaxTemperature:     c.AI.MaxTemperature,
+		MaxTokens:          c.AI.MaxTokens,
+		MaxPersonaLength:   c.AI.MaxPersonaLength,
+		MemoryExchanges:    c.AI.MemoryExchanges,
+		MemoryTTL:          c.AI.MemoryTTL,
+	})
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/utils"
+)
+
+// writeConfig writes a config file for the test and returns its path
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	config, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), *config)
+	assert.Equal(t, utils.DefaultLogRotation, config.LogRotation())
+}
+
+func TestLoadFileThenEnvironment(t *testing.T) {
+	path := writeConfig(t, `
+discord:
+  token: from-file
+log:
+  level: debug
+  retention_days: 30
+  compress: false
+music:
+  default_volume: 80
+  alone_timeout: 2m
+ai:
+  max_temperature: 0.9
+environment:
+  MUSIC_BACKEND: lavalink
+`)
+	t.Setenv("DISCORD_BOT_TOKEN", "from-env")
+	t.Setenv("MUSIC_ALONE_TIMEOUT", "45s")
+
+	config, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", config.Discord.Token, "the environment wins over the file")
+	assert.Equal(t, "debug", config.Log.Level)
+	assert.Equal(t, 45*time.Second, config.Music.AloneTimeout)
+	assert.Equal(t, 80, config.Music.DefaultVolume)
+	assert.Equal(t, 0.9, config.AI.MaxTemperature)
+	assert.Equal(t, utils.LogRotation{MaxSize: 100 << 20, MaxAge: 30 * 24 * time.Hour}, config.LogRotation())
+	assert.Equal(t, map[string]string{"MUSIC_BACKEND": "lavalink"}, config.Environment)
+}
+
+func TestLoadRejectsUnknownKeysAndFormats(t *testing.T) {
+	_, err := Load(writeConfig(t, "music:\n  defualt_volume: 80\n"))
+	assert.ErrorContains(t, err, "defualt_volume")
+
+	_, err = Load(filepath.Join(t.TempDir(), "config.toml"))
+	assert.ErrorContains(t, err, "unsupported format")
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLoadResetsInvalidSettings(t *testing.T) {
+	path := writeConfig(t, `
+log:
+  level: loud
+music:
+  default_volume: 900
+  max_queue: 50
+`)
+	t.Setenv("LOG_MAX_SIZE_MB", "lots")
+	t.Setenv("MUSIC_RESOLVE_TIMEOUT", "-5s")
+	t.Setenv("AI_MAX_TEMPERATURE", "5")
+	t.Setenv("AI_MEMORY_TTL", "0s")
+	t.Setenv("DISCORD_COMMAND_GUILDS", "123,test-server")
+
+	config, err := Load(path)
+	require.NotNil(t, config)
+	assert.ErrorContains(t, err, "log.level")
+	assert.ErrorContains(t, err, "music.default_volume")
+	assert.ErrorContains(t, err, "LOG_MAX_SIZE_MB")
+	assert.ErrorContains(t, err, "music.resolve_timeout")
+	assert.ErrorContains(t, err, "ai.max_temperature")
+	assert.ErrorContains(t, err, "ai.memory_ttl")
+	assert.ErrorContains(t, err, "discord.command_guilds")
+
+	defaults := Default()
+	assert.Equal(t, defaults.Log.Level, config.Log.Level)
+	assert.Equal(t, defaults.Music.DefaultVolume, config.Music.DefaultVolume)
+	assert.Equal(t, defaults.Log.MaxSizeMB, config.Log.MaxSizeMB)
+	assert.Equal(t, defaults.Music.ResolveTimeout, config.Music.ResolveTimeout)
+	assert.Equal(t, defaults.AI.MaxTemperature, config.AI.MaxTemperature)
+	assert.Equal(t, defaults.AI.MemoryTTL, config.AI.MemoryTTL)
+	assert.Equal(t, 50, config.Music.MaxQueue, "valid settings are kept")
+	assert.Empty(t, config.Discord.CommandGuilds)
+}
+
+func TestCommandGuildIDs(t *testing.T) {
+	ids, err := Discord{CommandGuilds: " 123, 456,, "}.CommandGuildIDs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"123", "456"}, ids)
+
+	ids, err = Discord{}.CommandGuildIDs()
+	require.NoError(t, err)
+	assert.Empty(t, ids, "commands are registered globally")
+}
+
+func TestSetEnvironmentKeepsExistingVariables(t *testing.T) {
+	t.Setenv("CONFIG_TEST_SET", "from-env")
+	t.Setenv("CONFIG_TEST_UNSET", "")
+	os.Unsetenv("CONFIG_TEST_UNSET")
+
+	config := Config{Environment: map[string]string{"CONFIG_TEST_SET": "from-file", "CONFIG_TEST_UNSET": "from-file"}}
+	require.NoError(t, config.SetEnvironment())
+	assert.Equal(t, "from-env", os.Getenv("CONFIG_TEST_SET"))
+	assert.Equal(t, "from-file", os.Getenv("CONFIG_TEST_UNSET"))
+}
+
+func TestReload(t *testing.T) {
+	path := writeConfig(t, "discord:\n  token: first\nmusic:\n  max_queue: 50\n")
+	loader := NewLoader(path, func(c *Config) { c.Log.Level = "warn" })
+	config, err := loader.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "warn", config.Log.Level, "the override applies")
+
+	var reloaded []*Config
+	loader.OnReload(func(c *Config) { reloaded = append(reloaded, c) })
+
+	require.NoError(t, os.WriteFile(path, []byte("discord:\n  token: second\nmusic:\n  max_queue: 75\n"), 0o644))
+	needsRestart, err := loader.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"discord.token"}, needsRestart)
+	require.Len(t, reloaded, 1)
+	assert.Equal(t, 75, loader.Current().Music.MaxQueue)
+	assert.Equal(t, "first", loader.Current().Discord.Token, "restart-only settings keep their value")
+	assert.Equal(t, "warn", loader.Current().Log.Level)
+
+	require.NoError(t, os.WriteFile(path, []byte("music:\n  max_queue: -1\n"), 0o644))
+	_, err = loader.Reload()
+	assert.ErrorContains(t, err, "music.max_queue")
+	assert.Equal(t, 75, loader.Current().Music.MaxQueue, "an invalid config is rejected whole")
+	assert.Len(t, reloaded, 1)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// eachSetting calls fn with every setting of a config type: fields that aren't sections, with their
+// path of yaml keys, such as "music.alone_timeout", and their index for reflect.Value.FieldByIndex
+func eachSetting(t reflect.Type, prefix string, index []int, fn func(path string, field reflect.StructField, index []int)) {
+	for n := range t.NumField() {
+		field := t.Field(n)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		fieldIndex := append(slices.Clone(index), n)
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			eachSetting(field.Type, prefix+key+".", fieldIndex, fn)
+			continue
+		}
+		fn(prefix+key, field, fieldIndex)
+	}
+}
+
+// applyEnv overrides settings with the environment variables of their env tags. Values that don't
+// parse are reported and leave the setting as it was.
+func applyEnv(config *Config) error {
+	var errs []error
+	v := reflect.ValueOf(config).Elem()
+	eachSetting(v.Type(), "", nil, func(_ string, field reflect.StructField, index []int) {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return
+		}
+		raw, set := os.LookupEnv(name)
+		if raw = strings.TrimSpace(raw); !set || raw == "" {
+			return
+		}
+		if err := setValue(v.FieldByIndex(index), raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %q, %v", name, raw, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// setValue parses raw into a setting
+func setValue(value reflect.Value, raw string) error {
+	switch {
+	case value.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return errors.New("expected a duration such as 30s")
+		}
+		value.SetInt(int64(d))
+	case value.Kind() == reflect.String:
+		value.SetString(raw)
+	case value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return errors.New("expected a number")
+		}
+		value.SetInt(int64(n))
+	case value.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return errors.New("expected a number")
+		}
+		value.SetFloat(f)
+	case value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errors.New("expected true or false")
+		}
+		value.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported setting type %s", value.Type())
+	}
+	return nil
+}
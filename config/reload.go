@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+
+	"pxnx-discord-bot/utils"
+)
+
+// Loader keeps the config in effect and reloads it from the same file and environment
+type Loader struct {
+	path     string
+	override func(*Config) // Applied after every load, such as command line flags
+
+	mu       sync.Mutex
+	current  *Config
+	onReload []func(*Config)
+}
+
+// NewLoader creates a loader reading the file at path, or no file when path is empty. override, if
+// not nil, changes every loaded config before it is validated.
+func NewLoader(path string, override func(*Config)) *Loader {
+	return &Loader{path: path, override: override}
+}
+
+// Path returns the file the config is read from, empty when there is none
+func (l *Loader) Path() string {
+	return l.path
+}
+
+// Load reads the config the bot starts with. Like the package's Load, the config is usable even with
+// an error as long as it isn't nil.
+func (l *Loader) Load() (*Config, error) {
+	config, err := l.read()
+	if config != nil {
+		l.mu.Lock()
+		l.current = config
+		l.mu.Unlock()
+	}
+	return config, err
+}
+
+// read loads the file and environment and applies the override
+func (l *Loader) read() (*Config, error) {
+	return load(l.path, l.override)
+}
+
+// Current returns the config in effect, nil before Load
+func (l *Loader) Current() *Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current
+}
+
+// OnReload adds a function called with every config Reload puts into effect
+func (l *Loader) OnReload(fn func(*Config)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onReload = append(l.onReload, fn)
+}
+
+// Reload reads the config again and puts it into effect. A config with any invalid setting is rejected
+// whole and the current one kept, so a typo never half-applies. Changed restart-only settings keep
+// their current value and are returned so the caller can say a restart is needed.
+func (l *Loader) Reload() (needsRestart []string, err error) {
+	next, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	if l.current != nil {
+		needsRestart = keepRestartSettings(l.current, next)
+	}
+	l.current = next
+	callbacks := l.onReload
+	l.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(next)
+	}
+	return needsRestart, nil
+}
+
+// ReloadOnHangup reloads the config whenever the process receives SIGHUP, logging the outcome
+func (l *Loader) ReloadOnHangup() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	utils.SafeGo("config-reload", func() {
+		for range hangup {
+			needsRestart, err := l.Reload()
+			switch {
+			case err != nil:
+				utils.LogError("Keeping the current configuration, reloading failed: %v", err)
+			case len(needsRestart) > 0:
+				utils.LogWarn("Reloaded configuration; %s only change after a restart", strings.Join(needsRestart, ", "))
+			default:
+				utils.LogInfo("Reloaded configuration")
+			}
+		}
+	})
+}
+
+// keepRestartSettings copies the restart-only settings of running into next and returns the paths of
+// those that differed
+func keepRestartSettings(running, next *Config) []string {
+	var changed []string
+	runningValue, nextValue := reflect.ValueOf(running).Elem(), reflect.ValueOf(next).Elem()
+	eachSetting(runningValue.Type(), "", nil, func(path string, field reflect.StructField, index []int) {
+		if field.Tag.Get("reload") != "restart" {
+			return
+		}
+		was, now := runningValue.FieldByIndex(index), nextValue.FieldByIndex(index)
+		if !reflect.DeepEqual(was.Interface(), now.Interface()) {
+			changed = append(changed, path)
+			now.Set(was)
+		}
+	})
+	return changed
+}
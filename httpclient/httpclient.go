@@ -0,0 +1,312 @@
+// Package httpclient creates the HTTP clients the bot calls other services with. Every client shares one
+// connection pool and sends the configured user agent and headers; clients for outside services also go
+// through the configured proxy and keep to per-host rate limits.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultUserAgent identifies the bot to the services it calls, some ask for a speaking one
+const DefaultUserAgent = "pxnx-discord-bot/1.0"
+
+// DefaultTimeout bounds a request of a client created without a timeout of its own
+const DefaultTimeout = 30 * time.Second
+
+// Config is how outbound requests are made
+type Config struct {
+	UserAgent  string               // Sent unless a request sets its own, DefaultUserAgent when empty
+	Headers    http.Header          // Added to every request that doesn't set them
+	Proxy      *url.URL             // Proxy for outside services; nil uses HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+	Timeout    time.Duration        // Timeout of clients created without one, DefaultTimeout when 0
+	RateLimits map[string]RateLimit // Requests allowed per host, such as api.openweathermap.org
+}
+
+// RateLimit allows Requests per Interval to a host, all of them at once at most
+type RateLimit struct {
+	Requests int
+	Interval time.Duration
+}
+
+// current is the configuration every client reads on each request, so clients created before Configure
+// pick it up too
+var current atomic.Pointer[settings]
+
+// settings is a Config prepared for use
+type settings struct {
+	config  Config
+	limiter *hostLimiter
+}
+
+func init() {
+	current.Store(&settings{limiter: newHostLimiter(nil)})
+}
+
+// Configure changes how every client makes requests from now on
+func Configure(config Config) {
+	current.Store(&settings{config: config, limiter: newHostLimiter(config.RateLimits)})
+}
+
+// Current returns the configuration in use
+func Current() Config {
+	return current.Load().config
+}
+
+// Shared transports keep connections to a host open between clients
+var (
+	external = newTransport(func(req *http.Request) (*url.URL, error) {
+		if proxy := current.Load().config.Proxy; proxy != nil {
+			return proxy, nil
+		}
+		return http.ProxyFromEnvironment(req)
+	})
+	internal = newTransport(nil)
+)
+
+// newTransport creates a pooled transport using proxy
+func newTransport(proxy func(*http.Request) (*url.URL, error)) *http.Transport {
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// New creates a client for an outside service such as an API or a file host, timing requests out after
+// timeout (the configured default when 0)
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: clientTimeout(timeout), Transport: &roundTripper{base: external, limited: true}}
+}
+
+// NewInternal creates a client for a service the bot runs next to it, such as the yt-dlp service or a
+// Lavalink node: it sends the user agent and headers but never goes through the proxy or rate limits
+func NewInternal(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: clientTimeout(timeout), Transport: &roundTripper{base: internal}}
+}
+
+// NewInternalUnix creates a client like NewInternal for a service listening on a unix socket. Requests
+// connect to the socket whatever host their URL names, such as http://ytdlp/health.
+func NewInternalUnix(socket string, timeout time.Duration) *http.Client {
+	transport := newTransport(nil)
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socket)
+	}
+	return &http.Client{Timeout: clientTimeout(timeout), Transport: &roundTripper{base: transport}}
+}
+
+// clientTimeout returns timeout, or the configured default when it is 0
+func clientTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	if configured := current.Load().config.Timeout; configured > 0 {
+		return configured
+	}
+	return DefaultTimeout
+}
+
+// roundTripper adds the configured headers and waits for the host's rate limit
+type roundTripper struct {
+	base    http.RoundTripper
+	limited bool
+}
+
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	settings := current.Load()
+	if t.limited {
+		if err := settings.limiter.wait(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	// A RoundTripper must not change the caller's request
+	req = req.Clone(req.Context())
+	if req.Header.Get("User-Agent") == "" {
+		userAgent := settings.config.UserAgent
+		if userAgent == "" {
+			userAgent = DefaultUserAgent
+		}
+		req.Header.Set("User-Agent", userAgent)
+	}
+	for name, values := range settings.config.Headers {
+		if _, set := req.Header[name]; !set {
+			req.Header[name] = values
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// hostLimiter keeps a token bucket per rate limited host
+type hostLimiter struct {
+	limits  map[string]RateLimit
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// bucket holds the requests a host has left, refilled over its interval
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newHostLimiter(limits map[string]RateLimit) *hostLimiter {
+	return &hostLimiter{limits: limits, buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// lookup finds the limit for host, or for the closest parent domain that has one, so a limit on
+// radio-browser.info also covers all.api.radio-browser.info. It returns the configured domain, whose
+// bucket the matching hosts share.
+func (l *hostLimiter) lookup(host string) (string, RateLimit, bool) {
+	domain := strings.TrimSuffix(strings.ToLower(host), ".")
+	for domain != "" {
+		if limit, ok := l.limits[domain]; ok {
+			return domain, limit, true
+		}
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+	return "", RateLimit{}, false
+}
+
+// wait blocks until a request to host is allowed, or ctx ends
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	domain, limit, ok := l.lookup(host)
+	if !ok || limit.Requests <= 0 || limit.Interval <= 0 {
+		return nil
+	}
+	for {
+		delay := l.reserve(domain, limit)
+		if delay <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("rate limit of %s: %w", host, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a request from the host's bucket, or returns how long until one is available
+func (l *hostLimiter) reserve(host string, limit RateLimit) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), last: now}
+		l.buckets[host] = b
+	}
+	perToken := max(limit.Interval/time.Duration(limit.Requests), time.Nanosecond)
+	b.tokens = min(float64(limit.Requests), b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(perToken))
+}
+
+// ConfigFromEnv reads OUTBOUND_USER_AGENT, OUTBOUND_HEADERS, OUTBOUND_PROXY, OUTBOUND_TIMEOUT and
+// OUTBOUND_RATE_LIMITS. Settings that don't parse are left at their default and reported together.
+func ConfigFromEnv() (Config, error) {
+	config := Config{UserAgent: strings.TrimSpace(os.Getenv("OUTBOUND_USER_AGENT"))}
+	var errs []error
+
+	if headers, err := ParseHeaders(os.Getenv("OUTBOUND_HEADERS")); err != nil {
+		errs = append(errs, fmt.Errorf("OUTBOUND_HEADERS: %w", err))
+	} else if len(headers) > 0 {
+		config.Headers = headers
+	}
+	if raw := strings.TrimSpace(os.Getenv("OUTBOUND_PROXY")); raw != "" {
+		proxy, err := url.Parse(raw)
+		if err != nil || proxy.Host == "" {
+			errs = append(errs, fmt.Errorf("OUTBOUND_PROXY: invalid proxy URL %q", raw))
+		} else {
+			config.Proxy = proxy
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("OUTBOUND_TIMEOUT")); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			errs = append(errs, fmt.Errorf("OUTBOUND_TIMEOUT: invalid duration %q", raw))
+		} else {
+			config.Timeout = timeout
+		}
+	}
+	if limits, err := ParseRateLimits(os.Getenv("OUTBOUND_RATE_LIMITS")); err != nil {
+		errs = append(errs, fmt.Errorf("OUTBOUND_RATE_LIMITS: %w", err))
+	} else if len(limits) > 0 {
+		config.RateLimits = limits
+	}
+	return config, errors.Join(errs...)
+}
+
+// ParseRateLimits reads limits like "api.openweathermap.org=60/1m,radio-browser.info=5/1s". A limit on a
+// domain also applies to its subdomains, which share one budget.
+func ParseRateLimits(raw string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, rate, found := strings.Cut(entry, "=")
+		requests, interval, hasInterval := strings.Cut(rate, "/")
+		n, err := strconv.Atoi(strings.TrimSpace(requests))
+		if !found || !hasInterval || err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid rate limit %q, expected host=requests/interval such as api.example.com=60/1m", entry)
+		}
+		every, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil || every <= 0 {
+			return nil, fmt.Errorf("invalid interval in rate limit %q", entry)
+		}
+		limits[strings.ToLower(strings.TrimSpace(host))] = RateLimit{Requests: n, Interval: every}
+	}
+	return limits, nil
+}
+
+// ParseHeaders reads headers like "X-Contact: ops@example.com; X-Env: prod"
+func ParseHeaders(raw string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, found := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q, expected Name: value", entry)
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+	return headers, nil
+}
@@ -0,0 +1,185 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useConfig applies a configuration for the test
+func useConfig(t *testing.T, config Config) {
+	previous := current.Load()
+	Configure(config)
+	t.Cleanup(func() { current.Store(previous) })
+}
+
+func TestClientsSendUserAgentAndHeaders(t *testing.T) {
+	received := make(chan http.Header, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client := New(0)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, DefaultUserAgent, (<-received).Get("User-Agent"))
+
+	useConfig(t, Config{UserAgent: "mybot/2.0", Headers: http.Header{"X-Contact": {"ops@example.com"}}})
+	for _, c := range []*http.Client{client, NewInternal(time.Second)} {
+		resp, err := c.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		headers := <-received
+		assert.Equal(t, "mybot/2.0", headers.Get("User-Agent"), "clients created before Configure follow it")
+		assert.Equal(t, "ops@example.com", headers.Get("X-Contact"))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("X-Contact", "someone@example.com")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	headers := <-received
+	assert.Equal(t, "Mozilla/5.0", headers.Get("User-Agent"), "a request's own headers win")
+	assert.Equal(t, "someone@example.com", headers.Get("X-Contact"))
+	assert.Equal(t, "Mozilla/5.0", req.Header.Get("User-Agent"))
+}
+
+func TestClientTimeout(t *testing.T) {
+	assert.Equal(t, DefaultTimeout, New(0).Timeout)
+	assert.Equal(t, 5*time.Second, New(5*time.Second).Timeout)
+
+	useConfig(t, Config{Timeout: time.Minute})
+	assert.Equal(t, time.Minute, NewInternal(0).Timeout)
+}
+
+func TestProxyAppliesToOutsideServicesOnly(t *testing.T) {
+	proxy, _ := url.Parse("http://proxy.internal:3128")
+	useConfig(t, Config{Proxy: proxy})
+
+	req := httptest.NewRequest(http.MethodGet, "https://api.openweathermap.org/data", nil)
+	got, err := external.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, proxy, got)
+	assert.Nil(t, internal.Proxy)
+}
+
+func TestHostLimiter(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newHostLimiter(map[string]RateLimit{"api.example.com": {Requests: 2, Interval: time.Second}})
+	limiter.now = func() time.Time { return now }
+	limit := limiter.limits["api.example.com"]
+
+	assert.Zero(t, limiter.reserve("api.example.com", limit))
+	assert.Zero(t, limiter.reserve("api.example.com", limit), "a full bucket allows a burst")
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve("api.example.com", limit))
+
+	now = now.Add(500 * time.Millisecond)
+	assert.Zero(t, limiter.reserve("api.example.com", limit), "one request refills per half second")
+
+	assert.NoError(t, limiter.wait(context.Background(), "other.example.com"), "unlisted hosts aren't limited")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, limiter.wait(ctx, "API.example.com"), context.Canceled)
+}
+
+func TestHostLimiterMatchesParentDomains(t *testing.T) {
+	limiter := newHostLimiter(map[string]RateLimit{
+		"radio-browser.info":     {Requests: 5, Interval: time.Second},
+		"api.radio-browser.info": {Requests: 1, Interval: time.Second},
+	})
+
+	domain, limit, ok := limiter.lookup("de1.Radio-Browser.info.")
+	assert.True(t, ok)
+	assert.Equal(t, "radio-browser.info", domain)
+	assert.Equal(t, 5, limit.Requests)
+
+	domain, limit, ok = limiter.lookup("all.api.radio-browser.info")
+	assert.True(t, ok)
+	assert.Equal(t, "api.radio-browser.info", domain, "the closest configured domain wins")
+	assert.Equal(t, 1, limit.Requests)
+
+	_, _, ok = limiter.lookup("notradio-browser.info")
+	assert.False(t, ok, "only whole labels match")
+	_, _, ok = limiter.lookup("info")
+	assert.False(t, ok)
+}
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits(" API.openweathermap.org=60/1m, radio-browser.info=5/1s,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]RateLimit{
+		"api.openweathermap.org": {Requests: 60, Interval: time.Minute},
+		"radio-browser.info":     {Requests: 5, Interval: time.Second},
+	}, limits)
+
+	for _, invalid := range []string{"example.com", "example.com=5", "example.com=0/1s", "example.com=5/soon"} {
+		_, err := ParseRateLimits(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("X-Contact: ops@example.com; X-Env:prod;")
+	require.NoError(t, err)
+	assert.Equal(t, "ops@example.com", headers.Get("X-Contact"))
+	assert.Equal(t, "prod", headers.Get("X-Env"))
+
+	_, err = ParseHeaders("no colon")
+	assert.Error(t, err)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OUTBOUND_USER_AGENT", "mybot/2.0")
+	t.Setenv("OUTBOUND_HEADERS", "X-Contact: ops@example.com")
+	t.Setenv("OUTBOUND_PROXY", "http://proxy.internal:3128")
+	t.Setenv("OUTBOUND_TIMEOUT", "20s")
+	t.Setenv("OUTBOUND_RATE_LIMITS", "api.openweathermap.org=60/1m")
+
+	config, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "mybot/2.0", config.UserAgent)
+	assert.Equal(t, "ops@example.com", config.Headers.Get("X-Contact"))
+	assert.Equal(t, "proxy.internal:3128", config.Proxy.Host)
+	assert.Equal(t, 20*time.Second, config.Timeout)
+	assert.Len(t, config.RateLimits, 1)
+
+	t.Setenv("OUTBOUND_TIMEOUT", "soon")
+	t.Setenv("OUTBOUND_PROXY", "")
+	config, err = ConfigFromEnv()
+	assert.ErrorContains(t, err, "OUTBOUND_TIMEOUT")
+	assert.Zero(t, config.Timeout)
+	assert.Equal(t, "mybot/2.0", config.UserAgent, "valid settings are kept")
+}
+
+func TestNewInternalUnix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "service.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Host + " " + r.URL.Path + " " + r.Header.Get("User-Agent")))
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	resp, err := NewInternalUnix(socket, time.Second).Get("http://ytdlp/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ytdlp /health "+DefaultUserAgent, string(body))
+}
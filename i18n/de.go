@@ -0,0 +1,360 @@
+package i18n
+
+var german = Catalog{
+	"list.and": " und ",
+	"on":       "An",
+	"off":      "Aus",
+
+	// Command checks
+	"check.server_only":         "❌ Dieser Befehl kann nur auf einem Server verwendet werden",
+	"check.missing_permission":  "❌ Du brauchst die Berechtigung %s, um /%s zu verwenden",
+	"check.missing_permissions": "❌ Du brauchst die Berechtigungen %s, um /%s zu verwenden",
+	"check.more_permissions":    "❌ Du brauchst mehr Berechtigungen, um /%s zu verwenden",
+	"check.cooldown":            "⏳ Warte noch %d Sekunden, bevor du /%s wieder verwendest",
+
+	"permission.administrator":            "Administrator",
+	"permission.manage_server":            "Server verwalten",
+	"permission.manage_messages":          "Nachrichten verwalten",
+	"permission.manage_roles":             "Rollen verwalten",
+	"permission.manage_channels":          "Kanäle verwalten",
+	"permission.view_channel":             "Kanal ansehen",
+	"permission.send_messages":            "Nachrichten senden",
+	"permission.embed_links":              "Links einbetten",
+	"permission.add_reactions":            "Reaktionen hinzufügen",
+	"permission.use_external_emojis":      "Externe Emojis verwenden",
+	"permission.read_message_history":     "Nachrichtenverlauf anzeigen",
+	"permission.connect":                  "Verbinden",
+	"permission.speak":                    "Sprechen",
+	"permission.attach_files":             "Dateien anhängen",
+	"permission.change_nickname":          "Nickname ändern",
+	"permission.set_voice_channel_status": "Sprachkanalstatus festlegen",
+
+	// Premium
+	"premium.ask_owner":              "⭐ „%s“ ist eine Premium-Funktion, frag einen Bot-Besitzer nach einem Upgrade für diesen Server",
+	"premium.subscribe":              "⭐ „%s“ ist eine Premium-Funktion, abonniere, um sie für diesen Server freizuschalten",
+	"premium.feature.filters":        "Audiofilter",
+	"premium.feature.stay_connected": "24/7-Modus",
+
+	// Option values, following the option's name
+	"option.number":     "muss eine Zahl sein",
+	"option.between":    "muss zwischen %s und %s liegen",
+	"option.at_least":   "muss mindestens %s sein",
+	"option.at_most":    "darf höchstens %s sein",
+	"option.text":       "muss Text sein",
+	"option.min_length": "muss mindestens %d Zeichen lang sein",
+	"option.max_length": "darf höchstens %d Zeichen lang sein",
+	"option.empty":      "darf nicht leer sein",
+	"option.url":        "muss ein Link sein, der mit http:// oder https:// beginnt",
+	"option.duration":   "muss eine Dauer wie 30m oder 2h sein",
+	"option.choice":     "muss eines davon sein: %s",
+
+	// /settings
+	"settings.server_only":          "❌ Einstellungen können nur auf einem Server geändert werden",
+	"settings.unknown":              "❌ Unbekannter Einstellungsbefehl",
+	"settings.music_unavailable":    "❌ Das Musiksystem ist nicht verfügbar",
+	"settings.unsupported_language": "❌ Nicht unterstützte Sprache %q",
+	"settings.not_saved":            "⚠️ Die Änderung konnte nicht gespeichert werden und geht beim Neustart des Bots verloren",
+	"settings.title":                "⚙️ Servereinstellungen",
+	"settings.volume":               "Lautstärke",
+	"settings.alone_timeout":        "Allein-Timeout",
+	"settings.autoplay":             "Autoplay",
+	"settings.dj_role":              "DJ-Rolle",
+	"settings.dj_role_none":         "Keine, die Warteschlange moderiert, wer Nachrichten verwalten darf",
+	"settings.announcements":        "Ankündigungen",
+	"settings.announcements_follow": "Kanal, in dem Musik angefragt wird",
+	"settings.language":             "Sprache",
+	"settings.summaries":            "Zusammenfassungen",
+	"settings.footer":               "Mit /settings änderbar",
+
+	// /music
+	"music.unavailable":                 "Das Musiksystem ist nicht verfügbar",
+	"music.not_connected":               "Nicht mit einem Sprachkanal verbunden",
+	"music.nothing_playing":             "Gerade läuft nichts",
+	"music.too_few_to_shuffle":          "Nicht genug Songs in der Warteschlange zum Mischen",
+	"music.join_first":                  "Ich muss zuerst in einem Sprachkanal sein. Verwende `/music join`",
+	"music.setting_not_saved":           "%s\n⚠️ Die Einstellung konnte nicht gespeichert werden und wird beim Neustart des Bots zurückgesetzt",
+	"music.unknown":                     "❌ Unbekannter Musikbefehl",
+	"music.settings_need_manage_server": "❌ Zum Ändern der Musikeinstellungen brauchst du die Berechtigung Server verwalten",
+	"music.join_first_retry":            "❌ Ich muss zuerst in einem Sprachkanal sein, verwende /music join und versuch es noch einmal",
+	"music.stopped":                     "⏹️ Wiedergabe gestoppt und Warteschlange geleert",
+	"music.skipped":                     "⏭️ Übersprungen! Der nächste Song läuft... (%d Songs übrig)",
+	"music.skipped_last":                "⏭️ Übersprungen! Keine weiteren Songs in der Warteschlange",
+
+	// /music queue
+	"queue.nothing_to_undo":   "Nichts zum Rückgängigmachen",
+	"queue.undo_failed":       "❌ Rückgängigmachen fehlgeschlagen: %v",
+	"queue.undone":            "↩️ Letzte Aktion rückgängig gemacht: %s (%d Songs in der Warteschlange)",
+	"queue.shuffled":          "🔀 %d Songs gemischt (Seed `%d`)",
+	"queue.not_shuffled":      "Die Warteschlange ist nicht gemischt",
+	"queue.unshuffle_failed":  "❌ Die Reihenfolge konnte nicht wiederhergestellt werden: %v",
+	"queue.unshuffled":        "↪️ Ursprüngliche Reihenfolge wiederhergestellt (%d Songs in der Warteschlange)",
+	"queue.cleanup_denied":    "❌ Du brauchst die Berechtigung Nachrichten verwalten oder die DJ-Rolle, um die Warteschlange aufzuräumen",
+	"queue.no_duplicates":     "Keine doppelten Songs in der Warteschlange",
+	"queue.deduped":           "🧹 %d doppelte Songs entfernt (%d Songs in der Warteschlange, /music queue undo stellt sie wieder her)",
+	"queue.choose_user":       "❌ Wähle, wessen Songs entfernt werden sollen",
+	"queue.user_has_none":     "%s hat keine Songs in der Warteschlange",
+	"queue.user_removed":      "🧹 %d Songs von %s entfernt (%d Songs in der Warteschlange, /music queue undo stellt sie wieder her)",
+	"queue.already_empty":     "Die Warteschlange ist schon leer",
+	"queue.invalid_control":   "❌ Ungültiges Warteschlangen-Bedienelement",
+	"queue.invalid_page":      "❌ Ungültige Warteschlangenseite",
+	"queue.moved":             "↕️ **%s** an Position %d verschoben",
+	"queue.swapped":           "🔄 **%s** und **%s** getauscht",
+	"queue.cleared":           "🗑️ %d Songs aus der Warteschlange entfernt",
+	"queue.clear_title":       "⚠️ Warteschlange leeren",
+	"queue.clear_description": "Damit werden **%d** Songs aus der Warteschlange entfernt:",
+	"queue.clear_tracks":      "Zu entfernende Songs",
+	"queue.clear_changed":     "⚠️ Die Warteschlange hat sich seit der Vorschau geändert, es wurde nichts geleert. Führe den Befehl erneut aus, um sie zu prüfen",
+
+	// /music join
+	"join.no_server":           "Die Serverinformationen konnten nicht abgerufen werden",
+	"join.member_not_in_voice": "Du musst zuerst in einem Sprachkanal sein!",
+	"join.failed":              "Beitritt zum Sprachkanal fehlgeschlagen: %v",
+	"join.joined":              "✅ **%s** beigetreten",
+
+	// /music leave
+	"leave.failed":       "Verlassen des Sprachkanals fehlgeschlagen: %v",
+	"leave.left_247_off": "👋 Sprachkanal verlassen und Warteschlange geleert, der 24/7-Modus ist jetzt aus",
+	"leave.left":         "👋 Sprachkanal verlassen und Warteschlange geleert",
+
+	// /play
+	"play.song_or_file":          "Gib entweder einen Song oder eine Audiodatei an, nicht beides",
+	"play.missing_query":         "Bitte gib einen Songnamen, eine YouTube-URL oder eine Audiodatei an",
+	"play.listening_along":       "Dieser Server hört einer Übertragung zu, verwende `/music broadcast leave`, um eigene Songs einzureihen",
+	"play.failed":                "Musik konnte nicht abgespielt werden: %v",
+	"play.queued":                "🎵 Zur Warteschlange hinzugefügt (Position %d)",
+	"play.priority_queued":       "⭐ Zur Prioritäts-Warteschlange hinzugefügt (Position %d)",
+	"play.now_playing":           "🎵 Läuft jetzt",
+	"play.extraction_queue_full": "Zu viele Songs von diesem Server warten schon darauf, nachgeschlagen zu werden, versuch es gleich noch einmal",
+	"play.extraction_waiting":    "⏳ Dein Song wartet darauf, nachgeschlagen zu werden (Position %d in der Reihe)...",
+
+	// Track and playlist embeds
+	"track.queued":          "Zur Warteschlange hinzugefügt",
+	"track.now_playing":     "Läuft gerade",
+	"track.duration":        "Dauer",
+	"track.provider":        "Quelle",
+	"track.provider_file":   "Audiodatei",
+	"track.provider_radio":  "Internetradio",
+	"track.requested_by":    "Angefragt von",
+	"track.station":         "Sender",
+	"track.footer":          "Steuere die Wiedergabe mit den Schaltflächen der Anzeige, /skip oder /stop",
+	"track.more":            "... und %d weitere Songs",
+	"playlist.loading":      "📜 Playlist wird geladen (bis zu %d Songs)...",
+	"playlist.load_failed":  "❌ Playlist konnte nicht geladen werden: %v",
+	"playlist.adding":       "📥 %d Songs werden zur Warteschlange hinzugefügt...",
+	"playlist.all_repeats":  "🚫 Alle %d Songs liefen vor Kurzem und dieser Server erlaubt keine Wiederholungen",
+	"playlist.queue_failed": "❌ Playlist konnte nicht eingereiht werden: %v",
+	"playlist.title":        "📜 Playlist eingereiht",
+	"playlist.description":  "**%d** Songs aus [dieser Playlist](%s) hinzugefügt",
+	"playlist.tracks":       "Songs",
+	"playlist.footer":       "Mit /music queue show siehst du die ganze Warteschlange",
+
+	// /play search results
+	"search.failed":           "Suche fehlgeschlagen: %v",
+	"search.nothing_found":    "Nichts gefunden für „%s“",
+	"search.invalid_control":  "❌ Ungültiges Such-Bedienelement",
+	"search.not_yours":        "Nur wer gesucht hat, kann ein Ergebnis wählen. Verwende `/play`, um selbst zu suchen",
+	"search.nothing_selected": "❌ Kein Song ausgewählt",
+
+	// Now playing controls
+	"nowplaying.invalid_control": "❌ Ungültiges Wiedergabe-Bedienelement",
+	"nowplaying.not_paused":      "Die Wiedergabe ist nicht pausiert",
+
+	// /music filters
+	"filters.toggled": "🎛️ %s **%s** (aktive Filter: %s)",
+	"filters.cleared": "🎛️ Alle Audiofilter ausgeschaltet",
+
+	// /music replay
+	"history.replay_failed": "❌ Eintrag %d konnte nicht erneut abgespielt werden: %v",
+	"history.replayed":      "🔁 **%s** wieder in die Warteschlange gestellt",
+
+	// /music radio
+	"radio.not_found":     "Kein funktionierender Radiosender für **%s** gefunden",
+	"radio.search_failed": "Radiosender konnten nicht gesucht werden: %v",
+	"radio.play_failed":   "Der Sender konnte nicht abgespielt werden: %v",
+	"radio.now_playing":   "📻 Läuft jetzt",
+	"radio.queued":        "📻 Zur Warteschlange hinzugefügt (Position %d), der Sender läuft, bis er übersprungen wird",
+	"radio.other_matches": "Nicht der richtige? Weitere Treffer",
+
+	// /music soundcheck
+	"soundcheck.busy":     "Gerade läuft etwas, stoppe es oder lass die Warteschlange vor einem Soundcheck auslaufen",
+	"soundcheck.lavalink": "Der Soundcheck braucht den eingebauten Player, hier läuft Musik über Lavalink",
+
+	// /music broadcast
+	"broadcast.unknown":          "❌ Unbekannter Übertragungsbefehl",
+	"broadcast.none":             "📡 Es läuft keine Übertragung",
+	"broadcast.start_owner_only": "❌ Nur der Bot-Besitzer kann eine Übertragung starten",
+	"broadcast.started":          "📡 Die Musik dieses Servers wird übertragen, andere Server können mit `/music broadcast join` mithören",
+	"broadcast.stop_owner_only":  "❌ Nur der Bot-Besitzer kann die Übertragung beenden",
+	"broadcast.not_running":      "❌ Es läuft keine Übertragung",
+	"broadcast.ended":            "📡 Übertragung beendet, %s hören mit und behalten ihre aktuellen Songs",
+	"broadcast.join_denied":      "❌ Zum Mithören brauchst du die Berechtigung Server verwalten",
+	"broadcast.joined":           "📡 Dieser Server hört mit und spielt jetzt die Songs der Übertragung. Verwende `/music broadcast leave`, um wieder eigene einzureihen",
+	"broadcast.leave_denied":     "❌ Zum Verlassen der Übertragung brauchst du die Berechtigung Server verwalten",
+	"broadcast.not_listening":    "❌ Dieser Server hört keiner Übertragung zu",
+	"broadcast.left":             "📡 Mithören beendet, die aktuellen Songs laufen weiter",
+
+	// /music circuit
+	"circuit.owner_only":     "❌ Nur der Bot-Besitzer kann den yt-dlp-Schutzschalter verwalten",
+	"circuit.unknown":        "❌ Unbekannter Schutzschalter-Befehl",
+	"circuit.already_closed": "✅ Der Schutzschalter war schon geschlossen, seine Fehlerzählung beginnt von vorn",
+	"circuit.closed":         "✅ Schutzschalter geschlossen (er war %s), Extraktionen gehen wieder an den yt-dlp-Dienst",
+
+	// /music diag
+	"diag.owner_only": "❌ Nur der Bot-Besitzer kann die yt-dlp-Diagnose sehen",
+	"diag.no_service": "ℹ️ Kein yt-dlp-Dienst eingerichtet, Extraktionen laufen über das yt-dlp-Programm. Setze `YTDLP_SERVICE_URL`, um einen Dienst zu überwachen.",
+
+	// /checkperms
+	"checkperms.title":                       "🔐 Berechtigungsprüfung",
+	"checkperms.description":                 "Berechtigungen des Bots in <#%s>",
+	"checkperms.permissions":                 "Berechtigungen",
+	"checkperms.missing":                     "❌ %s – nötig für %s",
+	"checkperms.fix":                         "• Gib der Rolle des Bots **%s** in <#%s> (oder in den Rolleneinstellungen des Servers)",
+	"checkperms.how_to_fix":                  "So behebst du es (%d fehlen)",
+	"checkperms.all_granted":                 "Alle nötigen Berechtigungen sind erteilt",
+	"checkperms.no_state":                    "Der Zustand des Bots ist noch nicht verfügbar, versuch es gleich noch einmal",
+	"checkperms.no_channel":                  "Dieser Kanal wurde nicht gefunden",
+	"checkperms.failed":                      "Die Berechtigungen für <#%s> konnten nicht ermittelt werden: %v",
+	"checkperms.optional":                    "➖ %s – optional: %s",
+	"checkperms.feature.all":                 "alle Befehle",
+	"checkperms.feature.embeds":              "Wetter-, Musik- und Info-Embeds",
+	"checkperms.feature.reactions":           "Reaktionen von /peepee",
+	"checkperms.feature.music":               "Musikwiedergabe",
+	"checkperms.feature.files":               "Dateien von /admin logs, /debug und /support",
+	"checkperms.feature.reactions_summaries": "Reaktionen von /peepee und /summarize",
+	"checkperms.feature.channel_status":      "Sprachkanalstatus",
+	"checkperms.feature.nickname":            "Now-Playing-Nickname",
+	"checkperms.feature.unused":              "keine aktivierte Funktion braucht sie, sie kann fehlen",
+
+	// /admin
+	"admin.unknown":                  "❌ Unbekannter Admin-Befehl",
+	"admin.logs_owner_only":          "❌ Nur der Bot-Besitzer kann die Logs lesen",
+	"admin.premium_owner_only":       "❌ Nur der Bot-Besitzer kann Premium vergeben oder entziehen",
+	"admin.premium_off":              "❌ Premium-Stufen sind aus, setze PREMIUM_ENABLED=true, um sie zu verwenden",
+	"admin.premium_grant_not_saved":  "⚠️ Premium wurde vergeben, konnte aber nicht gespeichert werden und endet beim Neustart des Bots: %v",
+	"admin.premium_revoke_not_saved": "⚠️ Premium wurde entzogen, aber die Änderung konnte nicht gespeichert werden: %v",
+	"admin.cache_owner_only":         "❌ Nur der Bot-Besitzer kann den Cache leeren",
+	"admin.cache_not_saved":          "⚠️ Der Cache wurde geleert, aber die Datei konnte nicht gespeichert werden: %v",
+	"admin.credentials_owner_only":   "❌ Nur der Bot-Besitzer kann die yt-dlp-Zugangsdaten verwalten",
+	"admin.credentials_kept":         "❌ Die bisherigen Zugangsdaten wurden behalten: %v",
+
+	// /admin backup
+	"backup.owner_only":    "❌ Nur der Bot-Besitzer kann Sicherungen verwalten",
+	"backup.off":           "❌ Sicherungen sind aus, setze BACKUP_DIR, um sie einzuschalten",
+	"backup.none":          "❌ Es gibt noch keine Sicherungen",
+	"backup.failed":        "❌ Sicherung fehlgeschlagen: %v",
+	"backup.not_restoring": "❌ Keine Wiederherstellung: %v",
+
+	// /ask and /ai
+	"ai.not_set_up":                "❌ Der KI-Chat ist auf diesem Bot nicht eingerichtet",
+	"ai.channel_not_allowed":       "❌ Der KI-Chat ist in diesem Kanal nicht erlaubt, frag eine Serververwaltung, in welchen Kanälen er an ist",
+	"ai.unknown_ask":               "❌ Unbekannter ask-Befehl",
+	"ai.nothing_to_forget":         "💬 In diesem Kanal gibt es kein Gespräch zum Vergessen",
+	"ai.forgotten":                 "🧹 Gespräch vergessen, die nächste Frage fängt von vorn an",
+	"ai.channel_not_allowed_short": "Der KI-Chat ist in diesem Kanal nicht erlaubt",
+	"ai.no_answer":                 "Mir ist gerade keine Antwort eingefallen, versuch es gleich noch einmal",
+	"ai.server_only":               "❌ KI-Einstellungen können nur auf einem Server geändert werden",
+	"ai.unknown":                   "❌ Unbekannter KI-Befehl",
+	"ai.personas_off":              "❌ Personas sind auf diesem Bot ausgeschaltet",
+	"ai.persona_too_long":          "❌ Die Persona ist %d Zeichen lang, dieser Bot erlaubt höchstens %d",
+	"ai.temperature_too_high":      "❌ Dieser Bot erlaubt eine Temperatur von höchstens %g",
+
+	// /summarize
+	"summarize.server_only":    "❌ Kanäle können nur auf einem Server zusammengefasst werden",
+	"summarize.disabled":       "❌ Zusammenfassungen sind auf diesem Server aus, eine Serververwaltung kann sie mit `/settings summaries` einschalten",
+	"summarize.no_intent":      "❌ Zusammenfassungen brauchen den Message Content Intent, den dieser Bot nicht anfordert",
+	"summarize.member_history": "❌ Du brauchst die Berechtigung Nachrichtenverlauf anzeigen in diesem Kanal, um ihn zusammenzufassen",
+	"summarize.bot_history":    "❌ Ich brauche die Berechtigung Nachrichtenverlauf anzeigen in diesem Kanal, um ihn zusammenzufassen",
+	"summarize.since_too_long": "❌ `since` darf höchstens %d Stunden sein",
+	"summarize.read_failed":    "Ich konnte die Nachrichten dieses Kanals nicht lesen, prüfe, ob ich den Kanal sehen kann",
+	"summarize.nothing":        "Es gibt keine Nachrichten zum Zusammenfassen",
+	"summarize.failed":         "Ich konnte diesen Kanal gerade nicht zusammenfassen, versuch es gleich noch einmal",
+
+	// Transcribe message command
+	"transcribe.not_set_up": "❌ Die Transkription ist auf diesem Bot nicht eingerichtet",
+	"transcribe.no_audio":   "❌ Diese Nachricht hat keine Sprachnachricht oder Audiodatei zum Transkribieren",
+	"transcribe.no_speech":  "Ich konnte in dieser Aufnahme keine Sprache hören",
+	"transcribe.failed":     "Ich konnte diese Aufnahme gerade nicht transkribieren, versuch es gleich noch einmal",
+	"transcribe.too_large":  "Diese Audiodatei ist zu groß, höchstens %d MB können transkribiert werden",
+
+	// /preferences
+	"preferences.unknown_user":        "❌ Ich konnte nicht erkennen, wer du bist",
+	"preferences.plain_text_on":       "Antworten als reiner Text sind an. Schalte sie mit `/preferences plain_text:False` aus.",
+	"preferences.embeds":              "ℹ️ Antworten verwenden Embeds. Schalte Antworten als reinen Text ohne Emoji mit `/preferences plain_text:True` ein.",
+	"preferences.plain_text_enabled":  "Antworten als reiner Text sind an. Der Bot antwortet dir ab jetzt ohne Embeds oder Emoji.",
+	"preferences.plain_text_disabled": "✅ Antworten als reiner Text sind aus, Antworten verwenden wieder Embeds",
+
+	// /support
+	"support.server_only": "❌ Verwende diesen Befehl auf einem Server",
+	"support.failed":      "❌ Die Diagnosedatei konnte nicht erstellt werden: %v",
+
+	// /debug
+	"debug.owner_only": "❌ Nur der Bot-Besitzer kann diesen Befehl verwenden",
+	"debug.failed":     "❌ Der Zustand des Players konnte nicht serialisiert werden: %v",
+
+	// /botinfo
+	"botinfo.unavailable": "❌ Bot-Informationen sind nicht verfügbar",
+
+	// Confirmation buttons
+	"confirm.invalid":   "❌ Ungültige Bestätigungsschaltfläche",
+	"confirm.not_yours": "Nur wer diese Aktion gestartet hat, kann sie bestätigen",
+	"confirm.dry_run":   "Probelauf – nichts wurde geändert",
+	"confirm.footer":    "Bestätige innerhalb von %d Sekunden, um fortzufahren",
+	"confirm.confirm":   "Bestätigen",
+	"confirm.cancel":    "Abbrechen",
+	"confirm.expired":   "⌛ Diese Bestätigung ist abgelaufen, nichts wurde geändert",
+	"confirm.cancelled": "❎ Abgebrochen, nichts wurde geändert",
+
+	// Buttons and menus
+	"component.expired": "⌛ Dieses Bedienelement ist nicht mehr verfügbar (%s)",
+
+	// Commands
+	"command.ping.description":        "Antwortet mit Pong!",
+	"command.peepee.description":      "Zeit für die PeePee-Inspektion!",
+	"command.8ball.description":       "Stelle dem magischen 8-Ball eine Frage",
+	"command.coinflip.description":    "Wirf eine Münze und wähle Kopf oder Zahl",
+	"command.server.description":      "Zeigt Informationen über den Server",
+	"command.user.description":        "Zeigt Informationen über einen Nutzer",
+	"command.weather.description":     "Zeigt die Wettervorhersage für eine Stadt",
+	"command.roll.description":        "Würfle bis zu einem Höchstwert (Standard: 100)",
+	"command.play.description":        "Spielt Musik von einer URL oder Suchanfrage",
+	"command.skip.description":        "Überspringt den aktuellen Song",
+	"command.music.description":       "Spiele Musik und verwalte Warteschlange, Lautstärke, Filter und Einstellungen",
+	"command.checkperms.description":  "Prüft die Berechtigungen des Bots in einem Kanal",
+	"command.premium.description":     "Zeigt Premium-Vorteile und abonniert sie für diesen Server",
+	"command.vote.description":        "Stimme auf Botlisten für den Bot ab und sieh, was das freischaltet",
+	"command.preferences.description": "Zeigt oder ändert, wie der Bot dir antwortet",
+	"command.admin.description":       "Werkzeuge zur Verwaltung des Bots",
+	"command.ask.description":         "Stelle der KI des Bots eine Frage",
+	"command.summarize.description":   "Fasst die letzten Nachrichten dieses Kanals mit der KI des Bots zusammen",
+	"command.Transcribe.name":         "Transkribieren",
+	"command.ai.description":          "Zeigt oder ändert, wie die KI des Bots /ask auf diesem Server beantwortet",
+	"command.debug.description":       "Hängt einen Zustand des Musikplayers für einen Fehlerbericht an (nur Bot-Besitzer)",
+	"command.botinfo.description":     "Zeigt die Server des Bots und den Zustand seiner Gateway-Shards",
+	"command.support.description":     "Erhalte eine Diagnosedatei für diesen Server und einen Link zum Support-Server",
+
+	"command.music join.description":        "Tritt deinem Sprachkanal bei, um Musik zu spielen",
+	"command.music leave.description":       "Verlässt den Sprachkanal und stoppt die Musik",
+	"command.music queue clear.description": "Leert die Warteschlange (mit Bestätigung)",
+	"command.music queue move.description":  "Verschiebt einen Song an eine andere Stelle der Warteschlange",
+	"command.music queue swap.description":  "Tauscht zwei Songs der Warteschlange",
+	"command.music history.description":     "Zeigt kürzlich gespielte Songs",
+	"command.music replay.description":      "Stellt einen kürzlich gespielten Song erneut in die Warteschlange",
+	"command.music antirepeat.description":  "Lehnt kürzlich gespielte Songs ab oder warnt davor (Server verwalten)",
+	"command.music loudnorm.description":    "Spielt jeden Song in ähnlicher Lautstärke (Server verwalten)",
+	"command.music fairqueue.description":   "Wechselt beim Einreihen zwischen den Anfragenden ab (Server verwalten)",
+	"command.music 247.description":         "Bleibt rund um die Uhr im Sprachkanal (Server verwalten)",
+	"command.music radio.description":       "Spielt einen Internetradiosender",
+	"command.music soundcheck.description":  "Spielt einen kurzen Testton, um den Ton des Bots zu prüfen",
+	"command.music stats.description":       "Zeigt die meistgespielten und meistübersprungenen Songs des Servers",
+	"command.music autodj.description":      "Hält die Musik mit den Favoriten des Servers am Laufen (Server verwalten)",
+
+	"command.settings.description":               "Zeigt oder ändert die Einstellungen dieses Servers",
+	"command.settings show.description":          "Zeigt die Einstellungen dieses Servers",
+	"command.settings volume.description":        "Lautstärke, in der Songs gespielt werden",
+	"command.settings alone_timeout.description": "Wie lange der Bot in einem Sprachkanal bleibt, nachdem alle gegangen sind",
+	"command.settings autoplay.description":      "Hält die Musik mit den Favoriten des Servers am Laufen, wenn die Warteschlange leer ist",
+	"command.settings dj_role.description":       "Rolle, die die Warteschlange wie Moderatoren aufräumen darf",
+	"command.settings announcements.description": "Kanal, in dem Nachrichten zum aktuellen Song gepostet werden",
+	"command.settings language.description":      "Sprache, in der der Bot antwortet",
+	"command.settings summaries.description":     "Ob Mitglieder Kanäle mit /summarize zusammenfassen können",
+}
@@ -0,0 +1,310 @@
+package i18n
+
+// english is the catalog every other one translates, and what they fall back to
+var english = Catalog{
+	"list.and": " and ",
+	"on":       "On",
+	"off":      "Off",
+
+	// Command checks
+	"check.server_only":         "❌ This command can only be used in a server",
+	"check.missing_permission":  "❌ You need the %s permission to use /%s",
+	"check.missing_permissions": "❌ You need the %s permissions to use /%s",
+	"check.more_permissions":    "❌ You need more permissions to use /%s",
+	"check.cooldown":            "⏳ Wait %d more seconds before using /%s again",
+
+	"permission.administrator":            "Administrator",
+	"permission.manage_server":            "Manage Server",
+	"permission.manage_messages":          "Manage Messages",
+	"permission.manage_roles":             "Manage Roles",
+	"permission.manage_channels":          "Manage Channels",
+	"permission.view_channel":             "View Channel",
+	"permission.send_messages":            "Send Messages",
+	"permission.embed_links":              "Embed Links",
+	"permission.add_reactions":            "Add Reactions",
+	"permission.use_external_emojis":      "Use External Emojis",
+	"permission.read_message_history":     "Read Message History",
+	"permission.connect":                  "Connect",
+	"permission.speak":                    "Speak",
+	"permission.attach_files":             "Attach Files",
+	"permission.change_nickname":          "Change Nickname",
+	"permission.set_voice_channel_status": "Set Voice Channel Status",
+
+	// Premium
+	"premium.ask_owner":              "⭐ %s is a premium feature, ask a bot owner about upgrading this server",
+	"premium.subscribe":              "⭐ %s is a premium feature, subscribe to unlock it for this server",
+	"premium.feature.filters":        "Audio filters",
+	"premium.feature.stay_connected": "24/7 mode",
+
+	// Option values, following the option's name
+	"option.number":     "must be a number",
+	"option.between":    "must be between %s and %s",
+	"option.at_least":   "must be at least %s",
+	"option.at_most":    "must be at most %s",
+	"option.text":       "must be text",
+	"option.min_length": "must be at least %d characters",
+	"option.max_length": "must be at most %d characters",
+	"option.empty":      "can't be empty",
+	"option.url":        "must be a link starting with http:// or https://",
+	"option.duration":   "must be a duration such as 30m or 2h",
+	"option.choice":     "must be one of: %s",
+
+	// /settings
+	"settings.server_only":          "❌ Settings can only be changed in a server",
+	"settings.unknown":              "❌ Unknown settings subcommand",
+	"settings.music_unavailable":    "❌ Music system is not available",
+	"settings.unsupported_language": "❌ Unsupported language %q",
+	"settings.not_saved":            "⚠️ The change could not be saved and resets when the bot restarts",
+	"settings.title":                "⚙️ Server Settings",
+	"settings.volume":               "Volume",
+	"settings.alone_timeout":        "Alone Timeout",
+	"settings.autoplay":             "Autoplay",
+	"settings.dj_role":              "DJ Role",
+	"settings.dj_role_none":         "None, queue moderation needs Manage Messages",
+	"settings.announcements":        "Announcements",
+	"settings.announcements_follow": "Channel music is requested from",
+	"settings.language":             "Language",
+	"settings.summaries":            "Summaries",
+	"settings.footer":               "Change these with /settings",
+
+	// /music
+	"music.unavailable":                 "Music system is not available",
+	"music.not_connected":               "Not connected to a voice channel",
+	"music.nothing_playing":             "Nothing is currently playing",
+	"music.too_few_to_shuffle":          "Not enough songs in the queue to shuffle",
+	"music.join_first":                  "I need to be in a voice channel first. Use `/music join`",
+	"music.setting_not_saved":           "%s\n⚠️ The setting could not be saved and resets when the bot restarts",
+	"music.unknown":                     "❌ Unknown music subcommand",
+	"music.settings_need_manage_server": "❌ Changing music settings needs the Manage Server permission",
+	"music.join_first_retry":            "❌ I need to be in a voice channel first, use /music join and try again",
+	"music.stopped":                     "⏹️ Stopped playback and cleared queue",
+	"music.skipped":                     "⏭️ Skipped! Playing next track... (%d songs remaining)",
+	"music.skipped_last":                "⏭️ Skipped! No more songs in queue",
+
+	// /music queue
+	"queue.nothing_to_undo":   "Nothing to undo",
+	"queue.undo_failed":       "❌ Could not undo: %v",
+	"queue.undone":            "↩️ Undid the last %s (%d songs in queue)",
+	"queue.shuffled":          "🔀 Shuffled %d songs (seed `%d`)",
+	"queue.not_shuffled":      "The queue is not shuffled",
+	"queue.unshuffle_failed":  "❌ Could not unshuffle: %v",
+	"queue.unshuffled":        "↪️ Restored the original order (%d songs in queue)",
+	"queue.cleanup_denied":    "❌ You need the Manage Messages permission or the DJ role to clean up the queue",
+	"queue.no_duplicates":     "No duplicate songs in the queue",
+	"queue.deduped":           "🧹 Removed %d duplicate songs (%d songs in queue, /music queue undo restores them)",
+	"queue.choose_user":       "❌ Choose whose songs to remove",
+	"queue.user_has_none":     "%s has no songs in the queue",
+	"queue.user_removed":      "🧹 Removed %d songs queued by %s (%d songs in queue, /music queue undo restores them)",
+	"queue.already_empty":     "Queue is already empty",
+	"queue.invalid_control":   "❌ Invalid queue control",
+	"queue.invalid_page":      "❌ Invalid queue page",
+	"queue.moved":             "↕️ Moved **%s** to position %d",
+	"queue.swapped":           "🔄 Swapped **%s** and **%s**",
+	"queue.cleared":           "🗑️ Cleared %d tracks from the queue",
+	"queue.clear_title":       "⚠️ Clear Queue",
+	"queue.clear_description": "This will remove **%d** tracks from the queue:",
+	"queue.clear_tracks":      "Tracks to remove",
+	"queue.clear_changed":     "⚠️ The queue changed since the preview, nothing was cleared. Run the command again to review it",
+
+	// /music join
+	"join.no_server":           "Failed to get server information",
+	"join.member_not_in_voice": "You need to be in a voice channel first!",
+	"join.failed":              "Failed to join voice channel: %v",
+	"join.joined":              "✅ Joined **%s**",
+
+	// /music leave
+	"leave.failed":       "Failed to leave voice channel: %v",
+	"leave.left_247_off": "👋 Left voice channel and cleared queue, 24/7 mode is now off",
+	"leave.left":         "👋 Left voice channel and cleared queue",
+
+	// /play
+	"play.song_or_file":          "Give either a song or an audio file, not both",
+	"play.missing_query":         "Please provide a song name, YouTube URL or audio file",
+	"play.listening_along":       "This server is listening along to a broadcast, use `/music broadcast leave` to queue your own songs",
+	"play.failed":                "Failed to play music: %v",
+	"play.queued":                "🎵 Added to queue (position %d)",
+	"play.priority_queued":       "⭐ Added to the priority queue (position %d)",
+	"play.now_playing":           "🎵 Now playing",
+	"play.extraction_queue_full": "Too many songs from this server are already waiting to be looked up, try again in a moment",
+	"play.extraction_waiting":    "⏳ Waiting to look up your song (position %d in line)...",
+
+	// Track and playlist embeds
+	"track.queued":          "Added to Queue",
+	"track.now_playing":     "Now Playing",
+	"track.duration":        "Duration",
+	"track.provider":        "Provider",
+	"track.provider_file":   "Audio file",
+	"track.provider_radio":  "Internet radio",
+	"track.requested_by":    "Requested by",
+	"track.station":         "Station",
+	"track.footer":          "Use the now-playing buttons, /skip, or /stop to control playback",
+	"track.more":            "... and %d more tracks",
+	"playlist.loading":      "📜 Loading playlist (up to %d tracks)...",
+	"playlist.load_failed":  "❌ Failed to load playlist: %v",
+	"playlist.adding":       "📥 Adding %d tracks to the queue...",
+	"playlist.all_repeats":  "🚫 All %d tracks were played recently and this server doesn't allow repeats",
+	"playlist.queue_failed": "❌ Failed to queue playlist: %v",
+	"playlist.title":        "📜 Playlist Queued",
+	"playlist.description":  "Added **%d** tracks from [this playlist](%s)",
+	"playlist.tracks":       "Tracks",
+	"playlist.footer":       "Use /music queue show to see the full queue",
+
+	// /play search results
+	"search.failed":           "Search failed: %v",
+	"search.nothing_found":    "Nothing found for \"%s\"",
+	"search.invalid_control":  "❌ Invalid search control",
+	"search.not_yours":        "Only the user who searched can pick a result. Run `/play` to search yourself",
+	"search.nothing_selected": "❌ No track selected",
+
+	// Now playing controls
+	"nowplaying.invalid_control": "❌ Invalid playback control",
+	"nowplaying.not_paused":      "Playback is not paused",
+
+	// /music filters
+	"filters.toggled": "🎛️ %s **%s** (active filters: %s)",
+	"filters.cleared": "🎛️ Cleared all audio filters",
+
+	// /music replay
+	"history.replay_failed": "❌ Could not replay entry %d: %v",
+	"history.replayed":      "🔁 Added **%s** back to the queue",
+
+	// /music radio
+	"radio.not_found":     "No working radio station found for **%s**",
+	"radio.search_failed": "Failed to search radio stations: %v",
+	"radio.play_failed":   "Failed to play the station: %v",
+	"radio.now_playing":   "📻 Now playing",
+	"radio.queued":        "📻 Added to queue (position %d), the station plays until it is skipped",
+	"radio.other_matches": "Not the one? Other matches",
+
+	// /music soundcheck
+	"soundcheck.busy":     "Something is playing, stop it or let the queue finish before a soundcheck",
+	"soundcheck.lavalink": "The soundcheck needs the built-in player, music is played through Lavalink here",
+
+	// /music broadcast
+	"broadcast.unknown":          "❌ Unknown broadcast subcommand",
+	"broadcast.none":             "📡 No broadcast is running",
+	"broadcast.start_owner_only": "❌ Only the bot owner can start a broadcast",
+	"broadcast.started":          "📡 Broadcasting this server's music, other servers can listen along with `/music broadcast join`",
+	"broadcast.stop_owner_only":  "❌ Only the bot owner can stop the broadcast",
+	"broadcast.not_running":      "❌ No broadcast is running",
+	"broadcast.ended":            "📡 Broadcast ended, %s listening along keep their current songs",
+	"broadcast.join_denied":      "❌ Listening along needs the Manage Server permission",
+	"broadcast.joined":           "📡 Listening along, this server now plays the broadcast's songs. Use `/music broadcast leave` to queue your own again",
+	"broadcast.leave_denied":     "❌ Leaving the broadcast needs the Manage Server permission",
+	"broadcast.not_listening":    "❌ This server isn't listening along to a broadcast",
+	"broadcast.left":             "📡 Stopped listening along, the current songs keep playing",
+
+	// /music circuit
+	"circuit.owner_only":     "❌ Only the bot owner can manage the yt-dlp circuit breaker",
+	"circuit.unknown":        "❌ Unknown circuit subcommand",
+	"circuit.already_closed": "✅ The circuit breaker was already closed, its failure count starts over",
+	"circuit.closed":         "✅ Closed the circuit breaker (it was %s), extractions go to the yt-dlp service again",
+
+	// /music diag
+	"diag.owner_only": "❌ Only the bot owner can see yt-dlp diagnostics",
+	"diag.no_service": "ℹ️ No yt-dlp service is configured, extractions run the yt-dlp binary. Set `YTDLP_SERVICE_URL` to monitor a service.",
+
+	// /checkperms
+	"checkperms.title":                       "🔐 Permission Check",
+	"checkperms.description":                 "Bot permissions in <#%s>",
+	"checkperms.permissions":                 "Permissions",
+	"checkperms.missing":                     "❌ %s — needed for %s",
+	"checkperms.fix":                         "• Grant **%s** to the bot's role in <#%s> (or in the server role settings)",
+	"checkperms.how_to_fix":                  "How to fix (%d missing)",
+	"checkperms.all_granted":                 "All required permissions are granted",
+	"checkperms.no_state":                    "Bot state is not available yet, please try again in a moment",
+	"checkperms.no_channel":                  "Could not find that channel",
+	"checkperms.failed":                      "Could not compute permissions for <#%s>: %v",
+	"checkperms.optional":                    "➖ %s — optional: %s",
+	"checkperms.feature.all":                 "all commands",
+	"checkperms.feature.embeds":              "weather, music and info embeds",
+	"checkperms.feature.reactions":           "/peepee reactions",
+	"checkperms.feature.music":               "music playback",
+	"checkperms.feature.files":               "/admin logs, /debug and /support files",
+	"checkperms.feature.reactions_summaries": "/peepee reactions and /summarize",
+	"checkperms.feature.channel_status":      "the voice channel status",
+	"checkperms.feature.nickname":            "the now playing nickname",
+	"checkperms.feature.unused":              "no enabled feature needs it, it can be left off",
+
+	// /admin
+	"admin.unknown":                  "❌ Unknown admin subcommand",
+	"admin.logs_owner_only":          "❌ Only the bot owner can read the logs",
+	"admin.premium_owner_only":       "❌ Only the bot owner can grant or revoke premium",
+	"admin.premium_off":              "❌ Premium tiers are off, set PREMIUM_ENABLED=true to use them",
+	"admin.premium_grant_not_saved":  "⚠️ Premium was granted but could not be saved and ends when the bot restarts: %v",
+	"admin.premium_revoke_not_saved": "⚠️ Premium was revoked but the change could not be saved: %v",
+	"admin.cache_owner_only":         "❌ Only the bot owner can clear the cache",
+	"admin.cache_not_saved":          "⚠️ The cache was cleared but the file could not be saved: %v",
+	"admin.credentials_owner_only":   "❌ Only the bot owner can manage yt-dlp credentials",
+	"admin.credentials_kept":         "❌ Kept the previous credentials: %v",
+
+	// /admin backup
+	"backup.owner_only":    "❌ Only the bot owner can manage backups",
+	"backup.off":           "❌ Backups are off, set BACKUP_DIR to turn them on",
+	"backup.none":          "❌ There are no backups yet",
+	"backup.failed":        "❌ Backup failed: %v",
+	"backup.not_restoring": "❌ Not restoring: %v",
+
+	// /ask and /ai
+	"ai.not_set_up":                "❌ AI chat is not set up on this bot",
+	"ai.channel_not_allowed":       "❌ AI chat is not allowed in this channel, ask a server manager which channels it is on in",
+	"ai.unknown_ask":               "❌ Unknown ask subcommand",
+	"ai.nothing_to_forget":         "💬 There is no conversation to forget in this channel",
+	"ai.forgotten":                 "🧹 Conversation forgotten, the next question starts over",
+	"ai.channel_not_allowed_short": "AI chat is not allowed in this channel",
+	"ai.no_answer":                 "I couldn't come up with an answer right now, try again in a moment",
+	"ai.server_only":               "❌ AI settings can only be changed in a server",
+	"ai.unknown":                   "❌ Unknown AI subcommand",
+	"ai.personas_off":              "❌ Personas are turned off on this bot",
+	"ai.persona_too_long":          "❌ The persona is %d characters long, this bot allows at most %d",
+	"ai.temperature_too_high":      "❌ This bot allows a temperature of at most %g",
+
+	// /summarize
+	"summarize.server_only":    "❌ Channels can only be summarized in a server",
+	"summarize.disabled":       "❌ Summaries are turned off in this server, a server manager can turn them on with `/settings summaries`",
+	"summarize.no_intent":      "❌ Summaries need the Message Content intent, which this bot doesn't request",
+	"summarize.member_history": "❌ You need the Read Message History permission in this channel to summarize it",
+	"summarize.bot_history":    "❌ I need the Read Message History permission in this channel to summarize it",
+	"summarize.since_too_long": "❌ `since` can be at most %d hours",
+	"summarize.read_failed":    "I couldn't read this channel's messages, check that I can see the channel",
+	"summarize.nothing":        "There are no messages to summarize",
+	"summarize.failed":         "I couldn't summarize this channel right now, try again in a moment",
+
+	// Transcribe message command
+	"transcribe.not_set_up": "❌ Transcription is not set up on this bot",
+	"transcribe.no_audio":   "❌ That message has no voice message or audio file to transcribe",
+	"transcribe.no_speech":  "I couldn't hear any speech in that audio",
+	"transcribe.failed":     "I couldn't transcribe that audio right now, try again in a moment",
+	"transcribe.too_large":  "That audio file is too large, at most %d MB can be transcribed",
+
+	// /preferences
+	"preferences.unknown_user":        "❌ Could not tell who you are",
+	"preferences.plain_text_on":       "Plain text responses are on. Turn them off with `/preferences plain_text:False`.",
+	"preferences.embeds":              "ℹ️ Responses use embeds. Turn on plain text responses without emoji with `/preferences plain_text:True`.",
+	"preferences.plain_text_enabled":  "Plain text responses are on. The bot answers you without embeds or emoji from now on.",
+	"preferences.plain_text_disabled": "✅ Plain text responses are off, responses use embeds again",
+
+	// /support
+	"support.server_only": "❌ Use this command in a server",
+	"support.failed":      "❌ Could not create the diagnostics file: %v",
+
+	// /debug
+	"debug.owner_only": "❌ Only the bot owner can use this command",
+	"debug.failed":     "❌ Could not serialize player state: %v",
+
+	// /botinfo
+	"botinfo.unavailable": "❌ Bot info is not available",
+
+	// Confirmation buttons
+	"confirm.invalid":   "❌ Invalid confirmation button",
+	"confirm.not_yours": "Only the user who started this action can confirm it",
+	"confirm.dry_run":   "Dry run — nothing was changed",
+	"confirm.footer":    "Confirm within %d seconds to proceed",
+	"confirm.confirm":   "Confirm",
+	"confirm.cancel":    "Cancel",
+	"confirm.expired":   "⌛ This confirmation has expired, nothing was changed",
+	"confirm.cancelled": "❎ Cancelled, nothing was changed",
+
+	// Buttons and menus
+	"component.expired": "⌛ This control is no longer available (%s)",
+}
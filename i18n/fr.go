@@ -0,0 +1,360 @@
+package i18n
+
+var french = Catalog{
+	"list.and": " et ",
+	"on":       "Activé",
+	"off":      "Désactivé",
+
+	// Command checks
+	"check.server_only":         "❌ Cette commande ne peut être utilisée que sur un serveur",
+	"check.missing_permission":  "❌ Il te faut la permission %s pour utiliser /%s",
+	"check.missing_permissions": "❌ Il te faut les permissions %s pour utiliser /%s",
+	"check.more_permissions":    "❌ Il te faut plus de permissions pour utiliser /%s",
+	"check.cooldown":            "⏳ Attends encore %d secondes avant d'utiliser /%s à nouveau",
+
+	"permission.administrator":            "Administrateur",
+	"permission.manage_server":            "Gérer le serveur",
+	"permission.manage_messages":          "Gérer les messages",
+	"permission.manage_roles":             "Gérer les rôles",
+	"permission.manage_channels":          "Gérer les salons",
+	"permission.view_channel":             "Voir le salon",
+	"permission.send_messages":            "Envoyer des messages",
+	"permission.embed_links":              "Intégrer des liens",
+	"permission.add_reactions":            "Ajouter des réactions",
+	"permission.use_external_emojis":      "Utiliser des émojis externes",
+	"permission.read_message_history":     "Voir les anciens messages",
+	"permission.connect":                  "Se connecter",
+	"permission.speak":                    "Parler",
+	"permission.attach_files":             "Joindre des fichiers",
+	"permission.change_nickname":          "Changer le pseudo",
+	"permission.set_voice_channel_status": "Définir le statut du salon vocal",
+
+	// Premium
+	"premium.ask_owner":              "⭐ « %s » est une fonctionnalité premium, demande à un propriétaire du bot de passer ce serveur en premium",
+	"premium.subscribe":              "⭐ « %s » est une fonctionnalité premium, abonne-toi pour la débloquer sur ce serveur",
+	"premium.feature.filters":        "Filtres audio",
+	"premium.feature.stay_connected": "Mode 24/7",
+
+	// Option values, following the option's name
+	"option.number":     "doit être un nombre",
+	"option.between":    "doit être entre %s et %s",
+	"option.at_least":   "doit être au moins %s",
+	"option.at_most":    "doit être au plus %s",
+	"option.text":       "doit être du texte",
+	"option.min_length": "doit faire au moins %d caractères",
+	"option.max_length": "doit faire au plus %d caractères",
+	"option.empty":      "ne peut pas être vide",
+	"option.url":        "doit être un lien commençant par http:// ou https://",
+	"option.duration":   "doit être une durée comme 30m ou 2h",
+	"option.choice":     "doit être l'un de : %s",
+
+	// /settings
+	"settings.server_only":          "❌ Les paramètres ne peuvent être modifiés que sur un serveur",
+	"settings.unknown":              "❌ Sous-commande de paramètres inconnue",
+	"settings.music_unavailable":    "❌ Le système de musique n'est pas disponible",
+	"settings.unsupported_language": "❌ Langue non prise en charge %q",
+	"settings.not_saved":            "⚠️ La modification n'a pas pu être enregistrée et sera perdue au redémarrage du bot",
+	"settings.title":                "⚙️ Paramètres du serveur",
+	"settings.volume":               "Volume",
+	"settings.alone_timeout":        "Délai seul",
+	"settings.autoplay":             "Lecture auto",
+	"settings.dj_role":              "Rôle DJ",
+	"settings.dj_role_none":         "Aucun, la modération de la file demande Gérer les messages",
+	"settings.announcements":        "Annonces",
+	"settings.announcements_follow": "Salon où la musique est demandée",
+	"settings.language":             "Langue",
+	"settings.summaries":            "Résumés",
+	"settings.footer":               "Modifiables avec /settings",
+
+	// /music
+	"music.unavailable":                 "Le système de musique n'est pas disponible",
+	"music.not_connected":               "Pas connecté à un salon vocal",
+	"music.nothing_playing":             "Rien n'est en cours de lecture",
+	"music.too_few_to_shuffle":          "Pas assez de morceaux dans la file pour mélanger",
+	"music.join_first":                  "Je dois d'abord être dans un salon vocal. Utilise `/music join`",
+	"music.setting_not_saved":           "%s\n⚠️ Le paramètre n'a pas pu être enregistré et sera réinitialisé au redémarrage du bot",
+	"music.unknown":                     "❌ Sous-commande de musique inconnue",
+	"music.settings_need_manage_server": "❌ Modifier les paramètres de musique nécessite la permission Gérer le serveur",
+	"music.join_first_retry":            "❌ Je dois d'abord être dans un salon vocal, utilise /music join et réessaie",
+	"music.stopped":                     "⏹️ Lecture arrêtée et file vidée",
+	"music.skipped":                     "⏭️ Passé ! Lecture du morceau suivant... (%d morceaux restants)",
+	"music.skipped_last":                "⏭️ Passé ! Plus aucun morceau dans la file",
+
+	// /music queue
+	"queue.nothing_to_undo":   "Rien à annuler",
+	"queue.undo_failed":       "❌ Impossible d'annuler : %v",
+	"queue.undone":            "↩️ Dernière action annulée : %s (%d morceaux dans la file)",
+	"queue.shuffled":          "🔀 %d morceaux mélangés (graine `%d`)",
+	"queue.not_shuffled":      "La file n'est pas mélangée",
+	"queue.unshuffle_failed":  "❌ Impossible de rétablir l'ordre : %v",
+	"queue.unshuffled":        "↪️ Ordre d'origine rétabli (%d morceaux dans la file)",
+	"queue.cleanup_denied":    "❌ Il te faut la permission Gérer les messages ou le rôle DJ pour nettoyer la file",
+	"queue.no_duplicates":     "Aucun morceau en double dans la file",
+	"queue.deduped":           "🧹 %d morceaux en double retirés (%d morceaux dans la file, /music queue undo les rétablit)",
+	"queue.choose_user":       "❌ Choisis de qui retirer les morceaux",
+	"queue.user_has_none":     "%s n'a aucun morceau dans la file",
+	"queue.user_removed":      "🧹 %d morceaux ajoutés par %s retirés (%d morceaux dans la file, /music queue undo les rétablit)",
+	"queue.already_empty":     "La file est déjà vide",
+	"queue.invalid_control":   "❌ Commande de file invalide",
+	"queue.invalid_page":      "❌ Page de file invalide",
+	"queue.moved":             "↕️ **%s** déplacé en position %d",
+	"queue.swapped":           "🔄 **%s** et **%s** échangés",
+	"queue.cleared":           "🗑️ %d morceaux retirés de la file",
+	"queue.clear_title":       "⚠️ Vider la file",
+	"queue.clear_description": "Cela retirera **%d** morceaux de la file :",
+	"queue.clear_tracks":      "Morceaux à retirer",
+	"queue.clear_changed":     "⚠️ La file a changé depuis l'aperçu, rien n'a été vidé. Relance la commande pour la vérifier",
+
+	// /music join
+	"join.no_server":           "Impossible d'obtenir les informations du serveur",
+	"join.member_not_in_voice": "Tu dois d'abord être dans un salon vocal !",
+	"join.failed":              "Impossible de rejoindre le salon vocal : %v",
+	"join.joined":              "✅ **%s** rejoint",
+
+	// /music leave
+	"leave.failed":       "Impossible de quitter le salon vocal : %v",
+	"leave.left_247_off": "👋 Salon vocal quitté et file vidée, le mode 24/7 est maintenant désactivé",
+	"leave.left":         "👋 Salon vocal quitté et file vidée",
+
+	// /play
+	"play.song_or_file":          "Donne soit un morceau soit un fichier audio, pas les deux",
+	"play.missing_query":         "Indique un nom de morceau, une URL YouTube ou un fichier audio",
+	"play.listening_along":       "Ce serveur écoute une diffusion, utilise `/music broadcast leave` pour ajouter tes propres morceaux",
+	"play.failed":                "Impossible de jouer la musique : %v",
+	"play.queued":                "🎵 Ajouté à la file (position %d)",
+	"play.priority_queued":       "⭐ Ajouté à la file prioritaire (position %d)",
+	"play.now_playing":           "🎵 Lecture en cours",
+	"play.extraction_queue_full": "Trop de morceaux de ce serveur attendent déjà d'être recherchés, réessaie dans un instant",
+	"play.extraction_waiting":    "⏳ Ton morceau attend d'être recherché (position %d dans la file)...",
+
+	// Track and playlist embeds
+	"track.queued":          "Ajouté à la file",
+	"track.now_playing":     "En cours de lecture",
+	"track.duration":        "Durée",
+	"track.provider":        "Source",
+	"track.provider_file":   "Fichier audio",
+	"track.provider_radio":  "Radio Internet",
+	"track.requested_by":    "Demandé par",
+	"track.station":         "Station",
+	"track.footer":          "Contrôle la lecture avec les boutons du lecteur, /skip ou /stop",
+	"track.more":            "... et %d autres morceaux",
+	"playlist.loading":      "📜 Chargement de la playlist (jusqu'à %d morceaux)...",
+	"playlist.load_failed":  "❌ Impossible de charger la playlist : %v",
+	"playlist.adding":       "📥 Ajout de %d morceaux à la file...",
+	"playlist.all_repeats":  "🚫 Les %d morceaux ont tous été joués récemment et ce serveur n'autorise pas les répétitions",
+	"playlist.queue_failed": "❌ Impossible d'ajouter la playlist à la file : %v",
+	"playlist.title":        "📜 Playlist ajoutée",
+	"playlist.description":  "**%d** morceaux ajoutés depuis [cette playlist](%s)",
+	"playlist.tracks":       "Morceaux",
+	"playlist.footer":       "Utilise /music queue show pour voir toute la file",
+
+	// /play search results
+	"search.failed":           "La recherche a échoué : %v",
+	"search.nothing_found":    "Rien trouvé pour « %s »",
+	"search.invalid_control":  "❌ Commande de recherche invalide",
+	"search.not_yours":        "Seule la personne qui a cherché peut choisir un résultat. Utilise `/play` pour chercher toi-même",
+	"search.nothing_selected": "❌ Aucun morceau sélectionné",
+
+	// Now playing controls
+	"nowplaying.invalid_control": "❌ Commande de lecture invalide",
+	"nowplaying.not_paused":      "La lecture n'est pas en pause",
+
+	// /music filters
+	"filters.toggled": "🎛️ %s **%s** (filtres actifs : %s)",
+	"filters.cleared": "🎛️ Tous les filtres audio ont été retirés",
+
+	// /music replay
+	"history.replay_failed": "❌ Impossible de rejouer l'entrée %d : %v",
+	"history.replayed":      "🔁 **%s** remis dans la file",
+
+	// /music radio
+	"radio.not_found":     "Aucune station de radio fonctionnelle trouvée pour **%s**",
+	"radio.search_failed": "Impossible de rechercher des stations de radio : %v",
+	"radio.play_failed":   "Impossible de jouer la station : %v",
+	"radio.now_playing":   "📻 Lecture en cours",
+	"radio.queued":        "📻 Ajouté à la file (position %d), la station joue jusqu'à ce qu'elle soit passée",
+	"radio.other_matches": "Pas la bonne ? Autres résultats",
+
+	// /music soundcheck
+	"soundcheck.busy":     "Quelque chose est en cours de lecture, arrête-le ou laisse la file se terminer avant un test du son",
+	"soundcheck.lavalink": "Le test du son nécessite le lecteur intégré, la musique passe ici par Lavalink",
+
+	// /music broadcast
+	"broadcast.unknown":          "❌ Sous-commande de diffusion inconnue",
+	"broadcast.none":             "📡 Aucune diffusion en cours",
+	"broadcast.start_owner_only": "❌ Seul le propriétaire du bot peut lancer une diffusion",
+	"broadcast.started":          "📡 La musique de ce serveur est diffusée, d'autres serveurs peuvent l'écouter avec `/music broadcast join`",
+	"broadcast.stop_owner_only":  "❌ Seul le propriétaire du bot peut arrêter la diffusion",
+	"broadcast.not_running":      "❌ Aucune diffusion en cours",
+	"broadcast.ended":            "📡 Diffusion terminée, %s à l'écoute gardent leurs morceaux actuels",
+	"broadcast.join_denied":      "❌ Écouter la diffusion nécessite la permission Gérer le serveur",
+	"broadcast.joined":           "📡 Ce serveur écoute la diffusion et joue maintenant ses morceaux. Utilise `/music broadcast leave` pour ajouter à nouveau les tiens",
+	"broadcast.leave_denied":     "❌ Quitter la diffusion nécessite la permission Gérer le serveur",
+	"broadcast.not_listening":    "❌ Ce serveur n'écoute aucune diffusion",
+	"broadcast.left":             "📡 Écoute de la diffusion arrêtée, les morceaux actuels continuent",
+
+	// /music circuit
+	"circuit.owner_only":     "❌ Seul le propriétaire du bot peut gérer le disjoncteur yt-dlp",
+	"circuit.unknown":        "❌ Sous-commande de disjoncteur inconnue",
+	"circuit.already_closed": "✅ Le disjoncteur était déjà fermé, son compteur d'échecs repart de zéro",
+	"circuit.closed":         "✅ Disjoncteur fermé (il était %s), les extractions repassent par le service yt-dlp",
+
+	// /music diag
+	"diag.owner_only": "❌ Seul le propriétaire du bot peut voir le diagnostic yt-dlp",
+	"diag.no_service": "ℹ️ Aucun service yt-dlp n'est configuré, les extractions utilisent le programme yt-dlp. Définis `YTDLP_SERVICE_URL` pour surveiller un service.",
+
+	// /checkperms
+	"checkperms.title":                       "🔐 Vérification des permissions",
+	"checkperms.description":                 "Permissions du bot dans <#%s>",
+	"checkperms.permissions":                 "Permissions",
+	"checkperms.missing":                     "❌ %s — nécessaire pour %s",
+	"checkperms.fix":                         "• Accorde **%s** au rôle du bot dans <#%s> (ou dans les paramètres des rôles du serveur)",
+	"checkperms.how_to_fix":                  "Comment corriger (%d manquantes)",
+	"checkperms.all_granted":                 "Toutes les permissions nécessaires sont accordées",
+	"checkperms.no_state":                    "L'état du bot n'est pas encore disponible, réessaie dans un instant",
+	"checkperms.no_channel":                  "Impossible de trouver ce salon",
+	"checkperms.failed":                      "Impossible de calculer les permissions pour <#%s> : %v",
+	"checkperms.optional":                    "➖ %s — facultatif : %s",
+	"checkperms.feature.all":                 "toutes les commandes",
+	"checkperms.feature.embeds":              "les embeds météo, musique et infos",
+	"checkperms.feature.reactions":           "les réactions de /peepee",
+	"checkperms.feature.music":               "la lecture de musique",
+	"checkperms.feature.files":               "les fichiers de /admin logs, /debug et /support",
+	"checkperms.feature.reactions_summaries": "les réactions de /peepee et /summarize",
+	"checkperms.feature.channel_status":      "le statut du salon vocal",
+	"checkperms.feature.nickname":            "le pseudo avec le titre en cours",
+	"checkperms.feature.unused":              "aucune fonction activée n'en a besoin, elle peut rester désactivée",
+
+	// /admin
+	"admin.unknown":                  "❌ Sous-commande d'administration inconnue",
+	"admin.logs_owner_only":          "❌ Seul le propriétaire du bot peut lire les journaux",
+	"admin.premium_owner_only":       "❌ Seul le propriétaire du bot peut accorder ou retirer le premium",
+	"admin.premium_off":              "❌ Les niveaux premium sont désactivés, définis PREMIUM_ENABLED=true pour les utiliser",
+	"admin.premium_grant_not_saved":  "⚠️ Le premium a été accordé mais n'a pas pu être enregistré et prendra fin au redémarrage du bot : %v",
+	"admin.premium_revoke_not_saved": "⚠️ Le premium a été retiré mais la modification n'a pas pu être enregistrée : %v",
+	"admin.cache_owner_only":         "❌ Seul le propriétaire du bot peut vider le cache",
+	"admin.cache_not_saved":          "⚠️ Le cache a été vidé mais le fichier n'a pas pu être enregistré : %v",
+	"admin.credentials_owner_only":   "❌ Seul le propriétaire du bot peut gérer les identifiants yt-dlp",
+	"admin.credentials_kept":         "❌ Les identifiants précédents ont été conservés : %v",
+
+	// /admin backup
+	"backup.owner_only":    "❌ Seul le propriétaire du bot peut gérer les sauvegardes",
+	"backup.off":           "❌ Les sauvegardes sont désactivées, définis BACKUP_DIR pour les activer",
+	"backup.none":          "❌ Il n'y a encore aucune sauvegarde",
+	"backup.failed":        "❌ La sauvegarde a échoué : %v",
+	"backup.not_restoring": "❌ Pas de restauration : %v",
+
+	// /ask and /ai
+	"ai.not_set_up":                "❌ Le chat IA n'est pas configuré sur ce bot",
+	"ai.channel_not_allowed":       "❌ Le chat IA n'est pas autorisé dans ce salon, demande à un gestionnaire du serveur dans quels salons il est actif",
+	"ai.unknown_ask":               "❌ Sous-commande ask inconnue",
+	"ai.nothing_to_forget":         "💬 Il n'y a aucune conversation à oublier dans ce salon",
+	"ai.forgotten":                 "🧹 Conversation oubliée, la prochaine question repart de zéro",
+	"ai.channel_not_allowed_short": "Le chat IA n'est pas autorisé dans ce salon",
+	"ai.no_answer":                 "Je n'ai pas trouvé de réponse pour le moment, réessaie dans un instant",
+	"ai.server_only":               "❌ Les paramètres de l'IA ne peuvent être modifiés que sur un serveur",
+	"ai.unknown":                   "❌ Sous-commande IA inconnue",
+	"ai.personas_off":              "❌ Les personas sont désactivés sur ce bot",
+	"ai.persona_too_long":          "❌ Le persona fait %d caractères, ce bot en autorise au plus %d",
+	"ai.temperature_too_high":      "❌ Ce bot autorise une température d'au plus %g",
+
+	// /summarize
+	"summarize.server_only":    "❌ Les salons ne peuvent être résumés que sur un serveur",
+	"summarize.disabled":       "❌ Les résumés sont désactivés sur ce serveur, un gestionnaire peut les activer avec `/settings summaries`",
+	"summarize.no_intent":      "❌ Les résumés nécessitent l'intent Message Content, que ce bot ne demande pas",
+	"summarize.member_history": "❌ Il te faut la permission Voir les anciens messages dans ce salon pour le résumer",
+	"summarize.bot_history":    "❌ J'ai besoin de la permission Voir les anciens messages dans ce salon pour le résumer",
+	"summarize.since_too_long": "❌ `since` peut être au plus %d heures",
+	"summarize.read_failed":    "Je n'ai pas pu lire les messages de ce salon, vérifie que je peux voir le salon",
+	"summarize.nothing":        "Il n'y a aucun message à résumer",
+	"summarize.failed":         "Je n'ai pas pu résumer ce salon pour le moment, réessaie dans un instant",
+
+	// Transcribe message command
+	"transcribe.not_set_up": "❌ La transcription n'est pas configurée sur ce bot",
+	"transcribe.no_audio":   "❌ Ce message n'a aucun message vocal ni fichier audio à transcrire",
+	"transcribe.no_speech":  "Je n'ai entendu aucune parole dans cet audio",
+	"transcribe.failed":     "Je n'ai pas pu transcrire cet audio pour le moment, réessaie dans un instant",
+	"transcribe.too_large":  "Ce fichier audio est trop volumineux, au plus %d Mo peuvent être transcrits",
+
+	// /preferences
+	"preferences.unknown_user":        "❌ Impossible de savoir qui tu es",
+	"preferences.plain_text_on":       "Les réponses en texte brut sont activées. Désactive-les avec `/preferences plain_text:False`.",
+	"preferences.embeds":              "ℹ️ Les réponses utilisent des embeds. Active les réponses en texte brut sans emoji avec `/preferences plain_text:True`.",
+	"preferences.plain_text_enabled":  "Les réponses en texte brut sont activées. Le bot te répond désormais sans embeds ni emoji.",
+	"preferences.plain_text_disabled": "✅ Les réponses en texte brut sont désactivées, les réponses utilisent à nouveau des embeds",
+
+	// /support
+	"support.server_only": "❌ Utilise cette commande sur un serveur",
+	"support.failed":      "❌ Impossible de créer le fichier de diagnostic : %v",
+
+	// /debug
+	"debug.owner_only": "❌ Seul le propriétaire du bot peut utiliser cette commande",
+	"debug.failed":     "❌ Impossible de sérialiser l'état du lecteur : %v",
+
+	// /botinfo
+	"botinfo.unavailable": "❌ Les informations du bot ne sont pas disponibles",
+
+	// Confirmation buttons
+	"confirm.invalid":   "❌ Bouton de confirmation invalide",
+	"confirm.not_yours": "Seule la personne qui a lancé cette action peut la confirmer",
+	"confirm.dry_run":   "Simulation — rien n'a été modifié",
+	"confirm.footer":    "Confirme dans les %d secondes pour continuer",
+	"confirm.confirm":   "Confirmer",
+	"confirm.cancel":    "Annuler",
+	"confirm.expired":   "⌛ Cette confirmation a expiré, rien n'a été modifié",
+	"confirm.cancelled": "❎ Annulé, rien n'a été modifié",
+
+	// Buttons and menus
+	"component.expired": "⌛ Cette commande n'est plus disponible (%s)",
+
+	// Commands
+	"command.ping.description":        "Répond Pong !",
+	"command.peepee.description":      "C'est l'heure de l'inspection PeePee !",
+	"command.8ball.description":       "Pose une question à la boule magique",
+	"command.coinflip.description":    "Lance une pièce et choisis pile ou face",
+	"command.server.description":      "Affiche des informations sur le serveur",
+	"command.user.description":        "Affiche des informations sur un utilisateur",
+	"command.weather.description":     "Affiche la météo d'une ville",
+	"command.roll.description":        "Lance un dé jusqu'à une valeur maximale (par défaut : 100)",
+	"command.play.description":        "Joue de la musique depuis une URL ou une recherche",
+	"command.skip.description":        "Passe le morceau en cours",
+	"command.music.description":       "Joue de la musique et gère la file, le volume, les filtres et les paramètres",
+	"command.checkperms.description":  "Vérifie les permissions du bot dans un salon",
+	"command.premium.description":     "Affiche les avantages premium et abonne ce serveur",
+	"command.vote.description":        "Vote pour le bot sur les listes de bots et vois ce que ça débloque",
+	"command.preferences.description": "Affiche ou modifie la façon dont le bot te répond",
+	"command.admin.description":       "Outils d'administration du bot",
+	"command.ask.description":         "Pose une question à l'IA du bot",
+	"command.summarize.description":   "Résume les messages récents de ce salon avec l'IA du bot",
+	"command.Transcribe.name":         "Transcrire",
+	"command.ai.description":          "Affiche ou modifie comment l'IA du bot répond à /ask sur ce serveur",
+	"command.debug.description":       "Joint un état du lecteur de musique pour un rapport de bug (propriétaire du bot)",
+	"command.botinfo.description":     "Affiche les serveurs du bot et l'état de ses shards",
+	"command.support.description":     "Obtiens un fichier de diagnostic pour ce serveur et un lien vers le serveur d'aide",
+
+	"command.music join.description":        "Rejoint ton salon vocal pour jouer de la musique",
+	"command.music leave.description":       "Quitte le salon vocal et arrête la musique",
+	"command.music queue clear.description": "Vide la file d'attente (avec confirmation)",
+	"command.music queue move.description":  "Déplace un morceau de la file à une autre position",
+	"command.music queue swap.description":  "Échange deux morceaux de la file",
+	"command.music history.description":     "Affiche les morceaux joués récemment",
+	"command.music replay.description":      "Remet un morceau joué récemment dans la file",
+	"command.music antirepeat.description":  "Refuse les morceaux joués récemment ou avertit (Gérer le serveur)",
+	"command.music loudnorm.description":    "Joue chaque morceau à un volume similaire (Gérer le serveur)",
+	"command.music fairqueue.description":   "Alterne entre les demandeurs lors de l'ajout de morceaux (Gérer le serveur)",
+	"command.music 247.description":         "Reste dans le salon vocal jour et nuit (Gérer le serveur)",
+	"command.music radio.description":       "Joue une station de radio en ligne",
+	"command.music soundcheck.description":  "Joue un court son de test pour vérifier l'audio du bot",
+	"command.music stats.description":       "Affiche les morceaux les plus joués et les plus passés du serveur",
+	"command.music autodj.description":      "Garde la musique avec une rotation des favoris du serveur (Gérer le serveur)",
+
+	"command.settings.description":               "Affiche ou modifie les paramètres de ce serveur",
+	"command.settings show.description":          "Affiche les paramètres de ce serveur",
+	"command.settings volume.description":        "Volume de lecture des morceaux",
+	"command.settings alone_timeout.description": "Combien de temps le bot reste dans un salon vocal une fois tout le monde parti",
+	"command.settings autoplay.description":      "Garde la musique avec les favoris du serveur quand la file est vide",
+	"command.settings dj_role.description":       "Rôle qui peut nettoyer la file comme les modérateurs",
+	"command.settings announcements.description": "Salon où sont publiés les messages du morceau en cours",
+	"command.settings language.description":      "Langue dans laquelle le bot répond",
+	"command.settings summaries.description":     "Si les membres peuvent résumer les salons avec /summarize",
+}
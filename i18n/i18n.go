@@ -0,0 +1,96 @@
+// Package i18n translates what the bot says. Messages are looked up by key in a catalog per language,
+// keys a catalog lacks fall back to English, so a new message only has to be written in English first.
+//
+// Keys starting with "command." are not responses but the names and descriptions Discord shows for the
+// bot's commands, such as "command.settings language.description"; English ones are in the command
+// definitions themselves and only the other catalogs have them.
+package i18n
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Default is the language of every message, which the other catalogs translate
+const Default = "en"
+
+// Catalog holds the messages of one language by key. Messages are fmt formats.
+type Catalog map[string]string
+
+// catalogs are the translations by language code
+var catalogs = map[string]Catalog{
+	"en": english,
+	"de": german,
+	"fr": french,
+}
+
+// locales are the Discord locales each language is shown in, for command names and descriptions
+var locales = map[string][]discordgo.Locale{
+	"de": {discordgo.German},
+	"fr": {discordgo.French},
+}
+
+// T returns the message of key in a language, formatted with args. Unsupported languages and messages
+// not translated yet are English, and unknown keys are returned as they are so they stand out.
+func T(language, key string, args ...any) string {
+	message, found := catalogs[language][key]
+	if !found {
+		message, found = catalogs[Default][key]
+	}
+	if !found {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Supported reports whether there is a catalog for a language code
+func Supported(language string) bool {
+	_, supported := catalogs[language]
+	return supported
+}
+
+// Languages returns the codes of every supported language, sorted
+func Languages() []string {
+	return slices.Sorted(maps.Keys(catalogs))
+}
+
+// FromLocale returns the language of a Discord locale such as "de" or "en-GB", Default for locales
+// without a catalog
+func FromLocale(locale discordgo.Locale) string {
+	language, _, _ := strings.Cut(string(locale), "-")
+	if !Supported(language) {
+		return Default
+	}
+	return language
+}
+
+// Keys returns the keys of a language's catalog, sorted
+func Keys(language string) []string {
+	return slices.Sorted(maps.Keys(catalogs[language]))
+}
+
+// Localizations returns the translations of key by Discord locale, for the localization fields of
+// commands. It is nil when no catalog translates key.
+func Localizations(key string) map[discordgo.Locale]string {
+	var localized map[discordgo.Locale]string
+	for language, languageLocales := range locales {
+		message, found := catalogs[language][key]
+		if !found {
+			continue
+		}
+		if localized == nil {
+			localized = make(map[discordgo.Locale]string)
+		}
+		for _, locale := range languageLocales {
+			localized[locale] = message
+		}
+	}
+	return localized
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "⏳ Wait 3 more seconds before using /weather again", T("en", "check.cooldown", 3, "weather"))
+	assert.Equal(t, "⏳ Warte noch 3 Sekunden, bevor du /weather wieder verwendest", T("de", "check.cooldown", 3, "weather"))
+	assert.Equal(t, "Manage Server", T("xx", "permission.manage_server"), "unsupported languages are English")
+	assert.Equal(t, "no.such.key", T("de", "no.such.key"), "unknown keys stand out")
+
+	english["test.english_only"] = "English only"
+	defer delete(english, "test.english_only")
+	assert.Equal(t, "English only", T("fr", "test.english_only"), "messages not translated yet are English")
+}
+
+func TestFromLocale(t *testing.T) {
+	assert.Equal(t, "de", FromLocale(discordgo.German))
+	assert.Equal(t, "fr", FromLocale(discordgo.French))
+	assert.Equal(t, "en", FromLocale(discordgo.EnglishGB))
+	assert.Equal(t, Default, FromLocale(discordgo.Japanese))
+	assert.Equal(t, Default, FromLocale(""))
+}
+
+func TestLocalizations(t *testing.T) {
+	localized := Localizations("command.ping.description")
+	require.NotNil(t, localized)
+	assert.Equal(t, "Antwortet mit Pong!", localized[discordgo.German])
+	assert.Equal(t, "Répond Pong !", localized[discordgo.French])
+
+	assert.Nil(t, Localizations("command.nothing.description"))
+	assert.NotContains(t, localized, discordgo.EnglishUS, "English is the definition's own description")
+}
+
+// formatVerbs finds the fmt verbs of a message, which every translation must use the same way
+var formatVerbs = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	assert.Equal(t, []string{"de", "en", "fr"}, Languages())
+
+	for language, catalog := range catalogs {
+		if language == Default {
+			continue
+		}
+		_, hasLocale := locales[language]
+		assert.True(t, hasLocale, "%s has no Discord locale", language)
+
+		for key, message := range catalog {
+			if strings.HasPrefix(key, "command.") {
+				continue // English command descriptions are in the definitions
+			}
+			english, found := catalogs[Default][key]
+			if !assert.True(t, found, "%s has %q, which English doesn't", language, key) {
+				continue
+			}
+			assert.Equal(t, formatVerbs.FindAllString(english, -1), formatVerbs.FindAllString(message, -1), "%s %q", language, key)
+		}
+	}
+}
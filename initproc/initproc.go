@@ -0,0 +1,65 @@
+// Package initproc lets the bot be PID 1 of a container. PID 1 inherits every orphaned process, such as
+// ffmpeg or yt-dlp children left behind when the yt-dlp service crashes, and must reap them or they stay
+// zombies. The kernel also doesn't apply default signal actions to PID 1, so a SIGTERM the bot doesn't
+// handle is ignored. Rather than reap inside the bot, where it would steal the exit status of processes
+// os/exec waits for, Run starts the bot again as its only child and does nothing but reap and forward
+// signals, as tini or docker run --init would.
+package initproc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// childEnv marks the bot started by Run, so it runs normally instead of starting another copy
+const childEnv = "PXNX_INIT_CHILD"
+
+// Mode says when Run supervises the bot
+type Mode string
+
+const (
+	ModeAuto Mode = "auto" // When the bot is PID 1
+	ModeOn   Mode = "on"   // Always, also reaping orphans of the bot's children where Linux allows it
+	ModeOff  Mode = "off"  // Never, such as under docker run --init
+)
+
+// ModeFromEnv reads INIT_MODE, auto when unset
+func ModeFromEnv() (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(os.Getenv("INIT_MODE")))); mode {
+	case "":
+		return ModeAuto, nil
+	case ModeAuto, ModeOn, ModeOff:
+		return mode, nil
+	default:
+		return ModeAuto, fmt.Errorf("invalid INIT_MODE %q, expected auto, on or off", mode)
+	}
+}
+
+// Run supervises the bot as INIT_MODE says. It returns supervised false in the bot itself, which goes on
+// starting; otherwise it returns once the bot exited, with the exit code to exit with.
+func Run() (exitCode int, supervised bool) {
+	if os.Getenv(childEnv) != "" {
+		return 0, false
+	}
+	mode, err := ModeFromEnv()
+	if err != nil {
+		// The logger isn't set up this early
+		fmt.Fprintf(os.Stderr, "Ignoring %v\n", err)
+	}
+	if mode == ModeOff || (mode == ModeAuto && os.Getpid() != 1) {
+		return 0, false
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find the bot's executable to supervise: %v\n", err)
+		return 0, false
+	}
+	code, err := supervise(self, os.Args[1:], append(os.Environ(), childEnv+"=1"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to supervise the bot: %v\n", err)
+		return 1, true
+	}
+	return code, true
+}
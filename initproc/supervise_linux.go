@@ -0,0 +1,71 @@
+package initproc
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// prSetChildSubreaper is PR_SET_CHILD_SUBREAPER from linux/prctl.h, missing from package syscall
+const prSetChildSubreaper = 36
+
+// forwarded are the signals passed on to the bot; SIGKILL and SIGSTOP can't be caught
+var forwarded = []os.Signal{
+	syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGUSR1, syscall.SIGUSR2,
+}
+
+// supervise starts path as a child, forwards signals to it and reaps every process that exits until the
+// child itself did, returning its exit code. Processes orphaned by the child are reaped too when this
+// process is PID 1, or a child subreaper as supervise makes it otherwise.
+func supervise(path string, args, env []string) (int, error) {
+	// Orphans are reparented to the nearest subreaper instead of PID 1
+	if os.Getpid() != 1 {
+		syscall.RawSyscall(syscall.SYS_PRCTL, prSetChildSubreaper, 1, 0)
+	}
+
+	signals := make(chan os.Signal, 16)
+	signal.Notify(signals, append([]os.Signal{syscall.SIGCHLD}, forwarded...)...)
+	defer signal.Stop(signals)
+
+	cmd := exec.Command(path, args...)
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	child := cmd.Process.Pid
+
+	// The child may have exited before SIGCHLD was noticed, so reap once before waiting for signals
+	for {
+		if code, exited := reap(child); exited {
+			return code, nil
+		}
+		sig := <-signals
+		if sig != syscall.SIGCHLD {
+			cmd.Process.Signal(sig)
+		}
+	}
+}
+
+// reap collects every exited process without blocking and reports whether child was among them, with its
+// exit code, 128 plus the signal when a signal ended it
+func reap(child int) (code int, exited bool) {
+	for {
+		var status syscall.WaitStatus
+		pid, err := syscall.Wait4(-1, &status, syscall.WNOHANG, nil)
+		if err == syscall.EINTR {
+			continue
+		}
+		if pid <= 0 || err != nil {
+			return 0, false
+		}
+		if pid != child {
+			continue
+		}
+		if status.Signaled() {
+			return 128 + int(status.Signal()), true
+		}
+		return status.ExitStatus(), true
+	}
+}
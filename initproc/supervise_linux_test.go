@@ -0,0 +1,79 @@
+package initproc
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelperProcess is the bot supervised by the tests, acting as INITPROC_HELPER says
+func TestHelperProcess(t *testing.T) {
+	switch os.Getenv("INITPROC_HELPER") {
+	case "exit":
+		code, _ := strconv.Atoi(os.Getenv("INITPROC_EXIT"))
+		os.Exit(code)
+	case "signal":
+		terminate := make(chan os.Signal, 1)
+		signal.Notify(terminate, syscall.SIGTERM)
+		os.WriteFile(os.Getenv("INITPROC_READY"), nil, 0o644)
+		<-terminate
+		os.Exit(7)
+	}
+}
+
+// superviseHelper supervises this test binary running TestHelperProcess
+func superviseHelper(env ...string) (int, error) {
+	return supervise(os.Args[0], []string{"-test.run=^TestHelperProcess$"}, append(os.Environ(), env...))
+}
+
+func TestSuperviseReturnsExitCode(t *testing.T) {
+	code, err := superviseHelper("INITPROC_HELPER=exit", "INITPROC_EXIT=3")
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+}
+
+func TestSuperviseForwardsSignals(t *testing.T) {
+	ready := filepath.Join(t.TempDir(), "ready")
+	exited := make(chan int, 1)
+	go func() {
+		code, err := superviseHelper("INITPROC_HELPER=signal", "INITPROC_READY="+ready)
+		assert.NoError(t, err)
+		exited <- code
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(ready)
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+	select {
+	case code := <-exited:
+		assert.Equal(t, 7, code, "the bot handled SIGTERM sent to its supervisor")
+	case <-time.After(10 * time.Second):
+		t.Fatal("the bot didn't exit")
+	}
+}
+
+func TestModeFromEnv(t *testing.T) {
+	t.Setenv("INIT_MODE", "")
+	mode, err := ModeFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, ModeAuto, mode)
+
+	t.Setenv("INIT_MODE", " On ")
+	mode, err = ModeFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, ModeOn, mode)
+
+	t.Setenv("INIT_MODE", "always")
+	_, err = ModeFromEnv()
+	assert.Error(t, err)
+}
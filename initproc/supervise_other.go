@@ -0,0 +1,10 @@
+//go:build !linux
+
+package initproc
+
+import "errors"
+
+// supervise is only needed in Linux containers
+func supervise(string, []string, []string) (int, error) {
+	return 0, errors.New("supervising the bot is only supported on Linux")
+}
@@ -1,22 +1,39 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 
 	"pxnx-discord-bot/bot"
+	"pxnx-discord-bot/config"
+	"pxnx-discord-bot/httpclient"
+	"pxnx-discord-bot/initproc"
+	"pxnx-discord-bot/tracing"
 	"pxnx-discord-bot/utils"
 )
 
 func main() {
+	// As PID 1 of a container, run the bot as a child that this process reaps orphans and forwards signals for
+	if code, supervised := initproc.Run(); supervised {
+		os.Exit(code)
+	}
+
 	// Parse command line flags
-	registerCommands := flag.Bool("register-commands", false, "Register bot commands with Discord (cleans up existing commands first)")
-	logLevel := flag.String("log-level", "info", "Set log level (error, warn, info, debug)")
+	registerCommands := flag.Bool("register-commands", false, "Register bot commands with Discord, only sending new and changed ones")
+	commandGuilds := flag.String("command-guilds", "", "Guild IDs to register commands in instead of globally, e.g. 123,456; takes effect instantly (default $DISCORD_COMMAND_GUILDS)")
+	pruneCommands := flag.Bool("prune-commands", true, "Delete registered commands the bot no longer defines when registering")
+	configPath := flag.String("config", "", "YAML config file, overridden by environment variables (default $CONFIG_FILE, else config.yaml if it exists)")
+	logLevel := flag.String("log-level", "", "Set log level (error, warn, info, debug), overriding the config (default $LOG_LEVEL, else info)")
+	logModules := flag.String("log-modules", "", "Per-module log levels overriding -log-level, e.g. music=debug,services/ytdlp=warn (default $LOG_MODULES)")
+	logFormat := flag.String("log-format", "", "Log line format, text or json for log aggregation (default $LOG_FORMAT, else text)")
 	flag.Parse()
 
 	// Initialize logger
@@ -30,14 +47,81 @@ func main() {
 		utils.LogInfo("No .env file found, using system environment variables")
 	}
 
-	token := os.Getenv("DISCORD_BOT_TOKEN")
+	// Settings come from the config file, then the environment, then the flags
+	loader := config.NewLoader(config.FilePath(*configPath), func(c *config.Config) {
+		if *commandGuilds != "" {
+			c.Discord.CommandGuilds = *commandGuilds
+		}
+		if *logLevel != "" {
+			c.Log.Level = *logLevel
+		}
+		if *logModules != "" {
+			c.Log.Modules = *logModules
+		}
+		if *logFormat != "" {
+			c.Log.Format = *logFormat
+		}
+	})
+	cfg, err := loader.Load()
+	if cfg == nil {
+		utils.LogError("%v", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		utils.LogWarn("Ignoring invalid settings: %v", err)
+	}
+	if loader.Path() != "" {
+		utils.LogInfo("Loaded configuration from %s", loader.Path())
+	}
+	if err := cfg.SetEnvironment(); err != nil {
+		utils.LogWarn("Ignoring %v", err)
+	}
+
+	// JSON lines carry guild, user, command and track as fields for log aggregation
+	format, _ := utils.ParseLogFormat(cfg.Log.Format)
+	utils.SetLogFormat(format)
+	if cfg.Log.BufferSize != utils.DefaultLogBufferSize {
+		utils.SetLogBufferSize(cfg.Log.BufferSize)
+	}
+	// Background work of each feature runs on a bounded pool of goroutines
+	sizes, _ := utils.ParsePoolSizes(cfg.WorkerPools)
+	utils.ConfigureWorkerPools(sizes)
+
+	// Log levels, log rotation and music defaults follow the config file on SIGHUP
+	cfg.Apply()
+	loader.OnReload((*config.Config).Apply)
+	loader.ReloadOnHangup()
+
+	// Requests to other services share one user agent, proxy and per-host rate limits
+	outbound, err := httpclient.ConfigFromEnv()
+	if err != nil {
+		utils.LogWarn("Ignoring outbound HTTP settings: %v", err)
+	}
+	httpclient.Configure(outbound)
+	// Spans of commands, extractions and playback go to an OpenTelemetry collector when one is set
+	tracingConfig, err := tracing.ConfigFromEnv("pxnx-discord-bot")
+	if err != nil {
+		utils.LogWarn("Ignoring tracing settings: %v", err)
+	}
+	if tracingConfig.Endpoint != "" {
+		utils.LogInfo("Exporting traces to %s", tracingConfig.Endpoint)
+	}
+	shutdownTracing := tracing.Configure(tracingConfig)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownTracing(ctx)
+	}()
+	token := cfg.Discord.Token
 	if token == "" {
-		utils.LogError("DISCORD_BOT_TOKEN environment variable is required")
+		utils.LogError("DISCORD_BOT_TOKEN environment variable or discord.token in the config file is required")
 		os.Exit(1)
 	}
 
 	// Set global flag for command registration
 	bot.SetShouldRegisterCommands(*registerCommands)
+	guildIDs, _ := cfg.Discord.CommandGuildIDs()
+	bot.SetCommandRegistration(bot.CommandRegistration{Guilds: guildIDs, Prune: *pruneCommands})
 
 	// Create new bot instance
 	botInstance, err := bot.New(token)
@@ -64,7 +148,8 @@ func main() {
 	fmt.Println("Bot is running. Press CTRL+C to exit.")
 
 	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
+	// docker stop and Kubernetes send SIGTERM
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 	<-stop
 
 	utils.LogInfo("Gracefully shutting down")
@@ -0,0 +1,47 @@
+package metrics
+
+import "time"
+
+// Default is the registry of the bot's metrics, served on METRICS_ADDR
+var Default = NewRegistry()
+
+// The bot's metrics. Label values come from a small fixed set (command names, outcomes, event types) so
+// series stay few; never label with guild or user IDs.
+var (
+	CommandInvocations = Default.NewCounter("pxnx_command_invocations_total",
+		"Slash commands and components handled, by command and outcome", "command", "outcome")
+	CommandDuration = Default.NewHistogram("pxnx_command_duration_seconds",
+		"Time taken to handle slash commands and components", DefaultBuckets, "command")
+
+	VoiceConnections = Default.NewGauge("pxnx_voice_connections",
+		"Voice channels the bot is connected to")
+	QueuedTracks = Default.NewGauge("pxnx_queued_tracks",
+		"Tracks waiting in every server's queue")
+	PlaybackErrors = Default.NewCounter("pxnx_playback_errors_total",
+		"Tracks that failed to play, by the step that failed", "stage")
+
+	ExtractionDuration = Default.NewHistogram("pxnx_ytdlp_extraction_duration_seconds",
+		"Time taken by yt-dlp to resolve or search, not counting time waiting for a worker", DefaultBuckets, "operation", "outcome")
+
+	GatewayEvents = Default.NewCounter("pxnx_gateway_events_total",
+		"Events received from the Discord gateway, by type", "type")
+)
+
+// Outcome labels of commands and extractions
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// OutcomeOf returns the outcome label of an error
+func OutcomeOf(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeOK
+}
+
+// Since returns the seconds passed since start, for observing durations
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// render writes a registry's metrics to a string
+func render(r *Registry) string {
+	var out strings.Builder
+	r.Write(&out)
+	return out.String()
+}
+
+func TestCounter(t *testing.T) {
+	r := NewRegistry()
+	counter := r.NewCounter("test_commands_total", "Commands handled", "command", "outcome")
+	counter.Inc("play", OutcomeOK)
+	counter.Inc("play", OutcomeOK)
+	counter.Add(0.5, "skip", OutcomeError)
+	counter.Add(-1, "skip", OutcomeError)
+
+	assert.Equal(t, 2.0, counter.Value("play", OutcomeOK))
+	assert.Equal(t, `# HELP test_commands_total Commands handled
+# TYPE test_commands_total counter
+test_commands_total{command="play",outcome="ok"} 2
+test_commands_total{command="skip",outcome="error"} 0.5
+`, render(r))
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	histogram := r.NewHistogram("test_duration_seconds", "Time taken", []float64{1, 0.1}, "operation")
+	histogram.Observe(0.05, "extract")
+	histogram.Observe(0.1, "extract")
+	histogram.Observe(0.5, "extract")
+	histogram.Observe(3, "extract")
+
+	assert.EqualValues(t, 4, histogram.Count("extract"))
+	assert.Equal(t, `# HELP test_duration_seconds Time taken
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{operation="extract",le="0.1"} 2
+test_duration_seconds_bucket{operation="extract",le="1"} 3
+test_duration_seconds_bucket{operation="extract",le="+Inf"} 4
+test_duration_seconds_sum{operation="extract"} 3.65
+test_duration_seconds_count{operation="extract"} 4
+`, render(r))
+}
+
+func TestGauge(t *testing.T) {
+	r := NewRegistry()
+	gauge := r.NewGauge("test_connections", "Connections")
+	gauge.Set(3)
+	assert.Equal(t, 3.0, gauge.Value())
+
+	connections := 5
+	gauge.Track(func() float64 { return float64(connections) })
+	connections = 7
+	assert.Contains(t, render(r), "test_connections 7\n", "tracked gauges are read when scraped")
+}
+
+func TestRegistrySortsAndEscapes(t *testing.T) {
+	r := NewRegistry()
+	r.NewGauge("test_b", "Second")
+	r.NewCounter("test_a", "First\nline", "type").Inc("quote\" back\\slash\nbreak")
+
+	out := render(r)
+	assert.Less(t, strings.Index(out, "test_a"), strings.Index(out, "test_b"))
+	assert.Contains(t, out, `# HELP test_a First\nline`)
+	assert.Contains(t, out, `test_a{type="quote\" back\\slash\nbreak"} 1`)
+
+	assert.Panics(t, func() { r.NewGauge("test_a", "Taken") })
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("test_events_total", "Events", "type").Inc("READY")
+
+	recorder := httptest.NewRecorder()
+	r.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Contains(t, recorder.Body.String(), `test_events_total{type="READY"} 1`)
+
+	recorder = httptest.NewRecorder()
+	r.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
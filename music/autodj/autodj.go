@@ -0,0 +1,128 @@
+package autodj
+
+import (
+	"math"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/music/stats"
+	"pxnx-discord-bot/music/types"
+)
+
+const (
+	// RotationSize is how many tracks the auto-DJ queues each time the queue runs dry
+	RotationSize = 10
+
+	// SeedTracks is how many of the top tracks are used to look up related recommendations
+	SeedTracks = 3
+
+	// RecencyHalfLife is how long it takes for a play to count half as much
+	RecencyHalfLife = 72 * time.Hour
+)
+
+// Candidate is a track the auto-DJ may queue, with its selection weight
+type Candidate struct {
+	Track  types.AudioSource
+	Weight float64
+}
+
+// HistoryWeight scores a track from the guild's listening history. More and more recent plays weigh
+// more, and tracks that are often skipped early weigh less.
+func HistoryWeight(track stats.TrackStats, now time.Time) float64 {
+	age := now.Sub(track.LastPlayed)
+	if age < 0 {
+		age = 0
+	}
+	recency := math.Pow(0.5, age.Hours()/RecencyHalfLife.Hours())
+
+	// Old favourites keep a quarter of their weight, early skips take away up to 90%
+	return float64(track.Plays) * (0.25 + 0.75*recency) * (1 - 0.9*track.SkipRate())
+}
+
+// Candidates merges history and related recommendations. Related tracks the guild has not played get
+// the average history weight so both sources are mixed in the rotation.
+func Candidates(history []stats.TrackStats, related []types.AudioSource, now time.Time) []Candidate {
+	candidates := make([]Candidate, 0, len(history)+len(related))
+	seen := make(map[string]bool, len(history)+len(related))
+
+	total := 0.0
+	for _, track := range history {
+		weight := HistoryWeight(track, now)
+		candidates = append(candidates, Candidate{Track: track.Track, Weight: weight})
+		seen[track.Track.URL] = true
+		total += weight
+	}
+
+	relatedWeight := 1.0
+	if len(history) > 0 && total > 0 {
+		relatedWeight = total / float64(len(history))
+	}
+
+	for _, track := range related {
+		if track.URL == "" || seen[track.URL] {
+			continue
+		}
+		seen[track.URL] = true
+		candidates = append(candidates, Candidate{Track: track, Weight: relatedWeight})
+	}
+	return candidates
+}
+
+// Pick draws up to n tracks by weight without replacement, leaving out excluded URLs and zero weights
+func Pick(candidates []Candidate, n int, rng *rand.Rand, exclude func(url string) bool) []types.AudioSource {
+	pool := make([]Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Weight <= 0 || (exclude != nil && exclude(candidate.Track.URL)) {
+			continue
+		}
+		pool = append(pool, candidate)
+	}
+
+	picked := make([]types.AudioSource, 0, n)
+	for len(picked) < n && len(pool) > 0 {
+		total := 0.0
+		for _, candidate := range pool {
+			total += candidate.Weight
+		}
+
+		target := rng.Float64() * total
+		index := len(pool) - 1
+		for i, candidate := range pool {
+			target -= candidate.Weight
+			if target < 0 {
+				index = i
+				break
+			}
+		}
+
+		picked = append(picked, pool[index].Track)
+		pool = append(pool[:index], pool[index+1:]...)
+	}
+	return picked
+}
+
+// MixURL returns the YouTube mix playlist seeded by a video, which lists related tracks
+func MixURL(trackURL string) (string, bool) {
+	parsed, err := url.Parse(trackURL)
+	if err != nil {
+		return "", false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	host = strings.TrimPrefix(host, "music.")
+
+	videoID := ""
+	switch host {
+	case "youtube.com":
+		videoID = parsed.Query().Get("v")
+	case "youtu.be":
+		videoID = strings.Trim(parsed.Path, "/")
+	}
+	if videoID == "" {
+		return "", false
+	}
+	return "https://www.youtube.com/watch?v=" + videoID + "&list=RD" + videoID, true
+}
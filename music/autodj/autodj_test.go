@@ -0,0 +1,111 @@
+package autodj
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/stats"
+	"pxnx-discord-bot/music/types"
+)
+
+func createTrackStats(id string, plays, skips int, lastPlayed time.Time) stats.TrackStats {
+	return stats.TrackStats{
+		Track:      types.AudioSource{Title: id, URL: "https://www.youtube.com/watch?v=" + id},
+		Plays:      plays,
+		Skips:      skips,
+		LastPlayed: lastPlayed,
+	}
+}
+
+func TestHistoryWeight(t *testing.T) {
+	now := time.Now()
+
+	fresh := HistoryWeight(createTrackStats("a", 4, 0, now), now)
+	assert.InDelta(t, 4.0, fresh, 0.0001)
+
+	halfLife := HistoryWeight(createTrackStats("a", 4, 0, now.Add(-RecencyHalfLife)), now)
+	assert.InDelta(t, 4*(0.25+0.75*0.5), halfLife, 0.0001)
+
+	ancient := HistoryWeight(createTrackStats("a", 4, 0, now.Add(-100*RecencyHalfLife)), now)
+	assert.InDelta(t, 1.0, ancient, 0.0001, "old favourites keep a floor")
+
+	skipped := HistoryWeight(createTrackStats("a", 4, 4, now), now)
+	assert.InDelta(t, 0.4, skipped, 0.0001)
+	assert.Less(t, skipped, fresh)
+}
+
+func TestCandidatesMergesSources(t *testing.T) {
+	now := time.Now()
+	history := []stats.TrackStats{
+		createTrackStats("a", 2, 0, now),
+		createTrackStats("b", 4, 0, now),
+	}
+	related := []types.AudioSource{
+		{Title: "a again", URL: "https://www.youtube.com/watch?v=a"},
+		{Title: "c", URL: "https://www.youtube.com/watch?v=c"},
+		{Title: "no url"},
+	}
+
+	candidates := Candidates(history, related, now)
+	require.Len(t, candidates, 3)
+	assert.Equal(t, "c", candidates[2].Track.Title)
+	assert.InDelta(t, 3.0, candidates[2].Weight, 0.0001, "related tracks get the average history weight")
+
+	onlyRelated := Candidates(nil, related[1:2], now)
+	require.Len(t, onlyRelated, 1)
+	assert.Equal(t, 1.0, onlyRelated[0].Weight)
+}
+
+func TestPick(t *testing.T) {
+	candidates := []Candidate{
+		{Track: types.AudioSource{URL: "a"}, Weight: 5},
+		{Track: types.AudioSource{URL: "b"}, Weight: 1},
+		{Track: types.AudioSource{URL: "c"}, Weight: 0},
+		{Track: types.AudioSource{URL: "d"}, Weight: 2},
+	}
+	exclude := func(url string) bool { return url == "d" }
+
+	picked := Pick(candidates, 10, rand.New(rand.NewPCG(1, 2)), exclude)
+	urls := make([]string, len(picked))
+	for i, track := range picked {
+		urls[i] = track.URL
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, urls, "zero weights and excluded tracks are never picked")
+
+	assert.Len(t, Pick(candidates, 1, rand.New(rand.NewPCG(1, 2)), nil), 1)
+}
+
+func TestPickFavoursHeavierTracks(t *testing.T) {
+	candidates := []Candidate{
+		{Track: types.AudioSource{URL: "heavy"}, Weight: 9},
+		{Track: types.AudioSource{URL: "light"}, Weight: 1},
+	}
+
+	rng := rand.New(rand.NewPCG(7, 7))
+	heavyFirst := 0
+	for i := 0; i < 1000; i++ {
+		if Pick(candidates, 1, rng, nil)[0].URL == "heavy" {
+			heavyFirst++
+		}
+	}
+	assert.InDelta(t, 900, heavyFirst, 60)
+}
+
+func TestMixURL(t *testing.T) {
+	mix, ok := MixURL("https://www.youtube.com/watch?v=abc123&t=10")
+	assert.True(t, ok)
+	assert.Equal(t, "https://www.youtube.com/watch?v=abc123&list=RDabc123", mix)
+
+	mix, ok = MixURL("https://youtu.be/abc123")
+	assert.True(t, ok)
+	assert.Equal(t, "https://www.youtube.com/watch?v=abc123&list=RDabc123", mix)
+
+	_, ok = MixURL("https://example.com/watch?v=abc123")
+	assert.False(t, ok)
+	_, ok = MixURL("https://www.youtube.com/playlist?list=PL123")
+	assert.False(t, ok)
+}
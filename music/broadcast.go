@@ -0,0 +1,177 @@
+package music
+
+import (
+	"errors"
+	"time"
+
+	"pxnx-discord-bot/music/broadcast"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// ErrListeningAlong is returned when a guild listening along to a broadcast queues tracks, its queue
+// follows the broadcast's
+var ErrListeningAlong = errors.New("this server is listening along to a broadcast")
+
+// StartBroadcast lets other guilds listen along to what a guild's player plays
+func (sp *SimplePlayer) StartBroadcast(guildID, startedBy string) error {
+	if _, connected := sp.GetPlayer(guildID); !connected {
+		return ErrNotConnected
+	}
+	if sp.broadcasts.Following(guildID) {
+		return ErrListeningAlong
+	}
+	if err := sp.broadcasts.Start(guildID, startedBy); err != nil {
+		return err
+	}
+	utils.LogInfo("Guild %s started broadcasting, started by %s", guildID, startedBy)
+	return nil
+}
+
+// StopBroadcast ends the running broadcast. Listeners keep playing the queue they had.
+func (sp *SimplePlayer) StopBroadcast() (broadcast.Broadcast, bool) {
+	ended, stopped := sp.broadcasts.Stop()
+	if stopped {
+		utils.LogInfo("Broadcast from guild %s stopped, %d servers were listening along", ended.SourceGuildID, len(ended.Followers))
+	}
+	return ended, stopped
+}
+
+// JoinBroadcast makes a guild's player mirror the broadcast, switching to the source's current track
+// at the source's position
+func (sp *SimplePlayer) JoinBroadcast(guildID string) error {
+	follower, connected := sp.GetPlayer(guildID)
+	if !connected {
+		return ErrNotConnected
+	}
+	if err := sp.broadcasts.Join(guildID); err != nil {
+		return err
+	}
+
+	current, _ := sp.broadcasts.Current()
+	if source, ok := sp.GetPlayer(current.SourceGuildID); ok {
+		utils.SafeGo("music.broadcastJoin", func() { mirrorPlayer(source, follower) })
+	}
+	utils.LogInfo("Guild %s is listening along to guild %s", guildID, current.SourceGuildID)
+	return nil
+}
+
+// LeaveBroadcast stops a guild listening along, it keeps playing what it has. It reports whether
+// the guild was listening along.
+func (sp *SimplePlayer) LeaveBroadcast(guildID string) bool {
+	return sp.broadcasts.Leave(guildID)
+}
+
+// Broadcast returns the running broadcast
+func (sp *SimplePlayer) Broadcast() (broadcast.Broadcast, bool) {
+	return sp.broadcasts.Current()
+}
+
+// ListeningAlong reports whether a guild's queue follows a broadcast
+func (sp *SimplePlayer) ListeningAlong(guildID string) bool {
+	return sp.broadcasts.Following(guildID)
+}
+
+// syncBroadcast brings the listeners in line after the source started a track, paused, resumed or ran
+// out of tracks
+func (sp *SimplePlayer) syncBroadcast(guildID string) {
+	if !sp.broadcasts.IsSource(guildID) {
+		return
+	}
+	source, connected := sp.GetPlayer(guildID)
+	if !connected {
+		return
+	}
+
+	current, _ := sp.broadcasts.Current()
+	for _, followerID := range current.Followers {
+		if follower, ok := sp.GetPlayer(followerID); ok {
+			mirrorPlayer(source, follower)
+		}
+	}
+}
+
+// forgetBroadcast ends the broadcast when its source leaves voice, or drops a listener that left
+func (sp *SimplePlayer) forgetBroadcast(guildID string) {
+	if sp.broadcasts.IsSource(guildID) {
+		if ended, stopped := sp.broadcasts.Stop(); stopped {
+			utils.LogInfo("Broadcast from guild %s ended as it left voice, %d servers were listening along", guildID, len(ended.Followers))
+		}
+		return
+	}
+	sp.broadcasts.Leave(guildID)
+}
+
+// mirrorPlayer makes follower play source's track at source's position with source's queue. The
+// follower's track only changes when the source's did, otherwise just the queue and pause are copied.
+func mirrorPlayer(source, follower *VoicePlayer) {
+	current := source.GetCurrent()
+	if current == nil {
+		follower.Stop()
+		return
+	}
+
+	upcoming := source.GetQueue()
+	for i := range upcoming {
+		upcoming[i] = localCopy(upcoming[i])
+	}
+
+	if playing := follower.GetCurrent(); playing == nil || prefetchKey(*playing) != prefetchKey(*current) {
+		follower.switchTo(localCopy(*current), time.Now().Add(-source.Position()), upcoming)
+	} else {
+		follower.replaceQueue(upcoming)
+	}
+
+	if source.IsPaused() {
+		follower.Pause()
+	} else {
+		follower.Resume()
+	}
+}
+
+// localCopy drops a track's stream URL when it can be resolved again, so each guild extracts its own
+// stream instead of sharing one that may expire or be tied to the source's connection
+func localCopy(track types.AudioSource) types.AudioSource {
+	if refreshable(track) {
+		track.StreamURL, track.StreamExpires = "", time.Time{}
+	}
+	return track
+}
+
+// switchTo replaces the current track and queue with a broadcast's. The track starts as far in as the
+// time since startedAt; Lavalink players start it from the beginning.
+func (vp *VoicePlayer) switchTo(track types.AudioSource, startedAt time.Time, upcoming []types.AudioSource) {
+	vp.mu.Lock()
+	vp.queue.Replace(append([]types.AudioSource{track}, upcoming...))
+	vp.joinAt = startedAt
+	playing := vp.playing
+	if playing {
+		// Moving to the broadcast's track is no skip, it isn't recorded as one
+		vp.restart = false
+		vp.clearPause()
+		close(vp.skipChan)
+		vp.skipChan = make(chan struct{})
+	} else {
+		vp.playing = true
+	}
+	vp.mu.Unlock()
+
+	vp.prefetch.Discard()
+	if !playing {
+		utils.SafeGo("music.playNext", vp.playNext)
+	}
+}
+
+// replaceQueue swaps the queue for a broadcast's, re-targeting the warmed encoder when the next track changed
+func (vp *VoicePlayer) replaceQueue(upcoming []types.AudioSource) {
+	vp.mu.Lock()
+	next, err := vp.queue.Get(0)
+	changed := err != nil || len(upcoming) == 0 || prefetchKey(*next) != prefetchKey(upcoming[0])
+	vp.queue.Replace(upcoming)
+	vp.mu.Unlock()
+
+	if changed {
+		vp.prefetch.Discard()
+		vp.refreshPrefetch()
+	}
+}
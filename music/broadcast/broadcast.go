@@ -0,0 +1,134 @@
+// Package broadcast tracks the listen along broadcast: one guild's player is the source and the players
+// of guilds that opted in mirror its queue and timing.
+package broadcast
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRunning is returned by Start while a broadcast is already running
+	ErrRunning = errors.New("a broadcast is already running")
+
+	// ErrNotRunning is returned by Join when no broadcast is running
+	ErrNotRunning = errors.New("no broadcast is running")
+
+	// ErrSource is returned by Join for the guild the broadcast comes from
+	ErrSource = errors.New("this server is the broadcast's source")
+)
+
+// Broadcast describes the running broadcast
+type Broadcast struct {
+	SourceGuildID string    `json:"source_guild_id"`
+	StartedBy     string    `json:"started_by"` // Bot owner who approved the broadcast
+	StartedAt     time.Time `json:"started_at"`
+	Followers     []string  `json:"followers"` // Guilds listening along, sorted
+}
+
+// Registry holds the running broadcast and the guilds listening along. One broadcast runs at a time,
+// it is a network-wide event rather than a per-server feature.
+type Registry struct {
+	mu        sync.RWMutex
+	source    string // Empty while no broadcast runs
+	startedBy string
+	startedAt time.Time
+	followers map[string]bool
+	now       func() time.Time
+}
+
+// NewRegistry creates a registry with no broadcast running
+func NewRegistry() *Registry {
+	return &Registry{followers: make(map[string]bool), now: time.Now}
+}
+
+// Start begins broadcasting a guild's player
+func (r *Registry) Start(sourceGuildID, startedBy string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.source != "" {
+		return ErrRunning
+	}
+	r.source, r.startedBy, r.startedAt = sourceGuildID, startedBy, r.now()
+	r.followers = make(map[string]bool)
+	return nil
+}
+
+// Stop ends the broadcast and returns it with the guilds that were listening along
+func (r *Registry) Stop() (Broadcast, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.source == "" {
+		return Broadcast{}, false
+	}
+	ended := r.current()
+	r.source, r.startedBy, r.startedAt = "", "", time.Time{}
+	r.followers = make(map[string]bool)
+	return ended, true
+}
+
+// Join adds a guild to the listeners of the running broadcast
+func (r *Registry) Join(guildID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case r.source == "":
+		return ErrNotRunning
+	case r.source == guildID:
+		return ErrSource
+	}
+	r.followers[guildID] = true
+	return nil
+}
+
+// Leave removes a guild from the listeners and reports whether it was listening along
+func (r *Registry) Leave(guildID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.followers[guildID] {
+		return false
+	}
+	delete(r.followers, guildID)
+	return true
+}
+
+// Current returns the running broadcast
+func (r *Registry) Current() (Broadcast, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.source == "" {
+		return Broadcast{}, false
+	}
+	return r.current(), true
+}
+
+// IsSource reports whether a guild's player is being broadcast
+func (r *Registry) IsSource(guildID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.source != "" && r.source == guildID
+}
+
+// Following reports whether a guild is listening along
+func (r *Registry) Following(guildID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.followers[guildID]
+}
+
+// current describes the running broadcast (caller holds the lock)
+func (r *Registry) current() Broadcast {
+	followers := make([]string, 0, len(r.followers))
+	for guildID := range r.followers {
+		followers = append(followers, guildID)
+	}
+	sort.Strings(followers)
+	return Broadcast{SourceGuildID: r.source, StartedBy: r.startedBy, StartedAt: r.startedAt, Followers: followers}
+}
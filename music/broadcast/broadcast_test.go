@@ -0,0 +1,45 @@
+package broadcast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryLifecycle(t *testing.T) {
+	started := time.Date(2025, time.June, 1, 20, 0, 0, 0, time.UTC)
+	registry := NewRegistry()
+	registry.now = func() time.Time { return started }
+
+	_, running := registry.Current()
+	assert.False(t, running)
+	assert.ErrorIs(t, registry.Join("guild_b"), ErrNotRunning)
+
+	require.NoError(t, registry.Start("guild_a", "owner"))
+	assert.ErrorIs(t, registry.Start("guild_c", "owner"), ErrRunning)
+	assert.True(t, registry.IsSource("guild_a"))
+
+	assert.ErrorIs(t, registry.Join("guild_a"), ErrSource)
+	require.NoError(t, registry.Join("guild_c"))
+	require.NoError(t, registry.Join("guild_b"))
+	require.NoError(t, registry.Join("guild_b"), "joining twice is fine")
+	assert.True(t, registry.Following("guild_b"))
+
+	current, running := registry.Current()
+	require.True(t, running)
+	assert.Equal(t, Broadcast{SourceGuildID: "guild_a", StartedBy: "owner", StartedAt: started, Followers: []string{"guild_b", "guild_c"}}, current)
+
+	assert.True(t, registry.Leave("guild_c"))
+	assert.False(t, registry.Leave("guild_c"))
+
+	ended, stopped := registry.Stop()
+	require.True(t, stopped)
+	assert.Equal(t, []string{"guild_b"}, ended.Followers)
+	assert.False(t, registry.Following("guild_b"))
+	assert.False(t, registry.IsSource("guild_a"))
+
+	_, stopped = registry.Stop()
+	assert.False(t, stopped)
+}
@@ -0,0 +1,204 @@
+// Package direct resolves links straight to audio files, such as Discord attachments, which FFmpeg can
+// stream as they are instead of going through yt-dlp.
+package direct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+	"pxnx-discord-bot/music/types"
+)
+
+// ProviderName is the provider of tracks resolved from direct links
+const ProviderName = "direct"
+
+// DefaultMaxSize is the largest file played when no other limit is set
+const DefaultMaxSize = 100 << 20
+
+// probeTimeout bounds the request that checks a link before it is queued
+const probeTimeout = 10 * time.Second
+
+// extensions are the audio files recognised from a link alone
+var extensions = map[string]bool{
+	".mp3":  true,
+	".ogg":  true,
+	".oga":  true,
+	".opus": true,
+	".wav":  true,
+	".flac": true,
+	".m4a":  true,
+}
+
+// ErrUnsupportedType is returned for links that don't serve an audio file
+var ErrUnsupportedType = errors.New("the link is not a supported audio file (mp3, ogg, opus, wav, flac or m4a)")
+
+// TooLargeError is returned for files bigger than the resolver's limit
+type TooLargeError struct {
+	Size  int64
+	Limit int64
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("the file is %d MB, files can be up to %d MB", e.Size>>20, e.Limit>>20)
+}
+
+// IsAudioURL reports whether a link points at an audio file by its extension
+func IsAudioURL(rawURL string) bool {
+	parsed, ok := parseHTTP(rawURL)
+	return ok && extensions[strings.ToLower(path.Ext(parsed.Path))]
+}
+
+// IsAttachmentURL reports whether a link is a file uploaded to Discord
+func IsAttachmentURL(rawURL string) bool {
+	parsed, ok := parseHTTP(rawURL)
+	if !ok {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	return (host == "cdn.discordapp.com" || host == "media.discordapp.net") && strings.HasPrefix(parsed.Path, "/attachments/")
+}
+
+// Supports reports whether a link should be resolved here rather than by yt-dlp
+func Supports(rawURL string) bool {
+	return IsAudioURL(rawURL) || IsAttachmentURL(rawURL)
+}
+
+// parseHTTP parses an absolute http or https link
+func parseHTTP(rawURL string) (*url.URL, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, false
+	}
+	return parsed, true
+}
+
+// Resolver checks direct links before they are queued: the file must be audio and within the size limit
+type Resolver struct {
+	client  *http.Client
+	maxSize int64
+}
+
+// NewResolver creates a resolver for files up to maxSize bytes, DefaultMaxSize when maxSize is 0 or less
+func NewResolver(maxSize int64) *Resolver {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &Resolver{
+		client:  httpclient.New(probeTimeout),
+		maxSize: maxSize,
+	}
+}
+
+// MaxSize returns the largest file the resolver accepts
+func (r *Resolver) MaxSize() int64 {
+	return r.maxSize
+}
+
+// Resolve checks the type and size of a linked file and returns it as a track streamed from the link
+func (r *Resolver) Resolve(ctx context.Context, rawURL string) (*types.AudioSource, error) {
+	parsed, ok := parseHTTP(rawURL)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an http link", ErrUnsupportedType)
+	}
+	link := parsed.String()
+
+	resp, err := r.probe(ctx, link, http.MethodHead)
+	if err == nil && resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusNotFound {
+		// Some hosts refuse HEAD, ask for the first byte instead
+		resp, err = r.probe(ctx, link, http.MethodGet)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the file: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("failed to check the file: %s", resp.Status)
+	}
+
+	if !IsAudio(resp.Header.Get("Content-Type"), parsed.Path) {
+		return nil, ErrUnsupportedType
+	}
+	if size := contentSize(resp); size > r.maxSize {
+		return nil, &TooLargeError{Size: size, Limit: r.maxSize}
+	}
+
+	return &types.AudioSource{
+		Title:     fileTitle(resp.Header.Get("Content-Disposition"), parsed.Path),
+		URL:       link,
+		StreamURL: link,
+		Uploader:  parsed.Hostname(),
+		Provider:  ProviderName,
+	}, nil
+}
+
+// probe requests a link's headers; GET requests only ask for the first byte
+func (r *Resolver) probe(ctx context.Context, link, method string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// IsAudio reports whether a content type is audio. Generic binary types count when the file name has an
+// audio extension, which is how some hosts serve every download.
+func IsAudio(contentType, filePath string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "audio/"), mediaType == "application/ogg":
+		return true
+	case mediaType == "", mediaType == "application/octet-stream", mediaType == "binary/octet-stream":
+		return extensions[strings.ToLower(path.Ext(filePath))]
+	default:
+		return false
+	}
+}
+
+// contentSize returns the size of the whole file, -1 when the host doesn't say
+func contentSize(resp *http.Response) int64 {
+	// Range responses carry the full size after the slash: bytes 0-0/12345
+	if contentRange := resp.Header.Get("Content-Range"); contentRange != "" {
+		if slash := strings.LastIndex(contentRange, "/"); slash >= 0 {
+			if size, err := strconv.ParseInt(contentRange[slash+1:], 10, 64); err == nil {
+				return size
+			}
+		}
+		return -1
+	}
+	return resp.ContentLength
+}
+
+// fileTitle names a track after its file, from Content-Disposition or the link, without the extension
+func fileTitle(disposition, filePath string) string {
+	name := path.Base(filePath)
+	if _, params, err := mime.ParseMediaType(disposition); err == nil && params["filename"] != "" {
+		name = params["filename"]
+	}
+
+	name = strings.TrimSuffix(name, path.Ext(name))
+	name = strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+	if name == "" || name == "." || name == "/" {
+		return "Audio file"
+	}
+	return name
+}
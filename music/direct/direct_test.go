@@ -0,0 +1,108 @@
+package direct
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupports(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://example.com/music/song.mp3", true},
+		{"https://example.com/song.FLAC?token=abc", true},
+		{"http://example.com/a/b.opus", true},
+		{"https://cdn.discordapp.com/attachments/1/2/recording?ex=1", true},
+		{"https://media.discordapp.net/attachments/1/2/voice.ogg", true},
+		{"https://cdn.discordapp.com/emojis/123.png", false},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", false},
+		{"https://example.com/page.html", false},
+		{"ftp://example.com/song.mp3", false},
+		{"song.mp3", false},
+		{"never gonna give you up", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Supports(tt.url), tt.url)
+	}
+}
+
+// fileServer serves a file's headers the way hosts do, optionally refusing HEAD requests
+func fileServer(t *testing.T, contentType string, size int64, refuseHead bool, header http.Header) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && refuseHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		for name, values := range header {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Content-Type", contentType)
+		if r.Header.Get("Range") == "bytes=0-0" {
+			w.Header().Set("Content-Range", "bytes 0-0/"+strconv.FormatInt(size, 10))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte{0})
+			return
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResolve(t *testing.T) {
+	server := fileServer(t, "audio/mpeg", 5<<20, false, nil)
+	track, err := NewResolver(0).Resolve(context.Background(), server.URL+"/uploads/my_song.mp3")
+	require.NoError(t, err)
+
+	assert.Equal(t, "my song", track.Title)
+	assert.Equal(t, server.URL+"/uploads/my_song.mp3", track.URL)
+	assert.Equal(t, track.URL, track.StreamURL)
+	assert.Equal(t, ProviderName, track.Provider)
+	assert.Equal(t, "127.0.0.1", track.Uploader)
+}
+
+func TestResolveFallsBackToRangeRequest(t *testing.T) {
+	server := fileServer(t, "application/octet-stream", 200<<20, true, nil)
+
+	_, err := NewResolver(0).Resolve(context.Background(), server.URL+"/big.wav")
+	var tooLarge *TooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	assert.Equal(t, int64(200<<20), tooLarge.Size)
+	assert.Equal(t, "the file is 200 MB, files can be up to 100 MB", err.Error())
+
+	_, err = NewResolver(300<<20).Resolve(context.Background(), server.URL+"/big.wav")
+	assert.NoError(t, err)
+}
+
+func TestResolveChecksContentType(t *testing.T) {
+	page := fileServer(t, "text/html; charset=utf-8", 1000, false, nil)
+	_, err := NewResolver(0).Resolve(context.Background(), page.URL+"/song.mp3")
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	binary := fileServer(t, "application/octet-stream", 1000, false, nil)
+	_, err = NewResolver(0).Resolve(context.Background(), binary.URL+"/download")
+	assert.ErrorIs(t, err, ErrUnsupportedType, "generic types need an audio extension")
+
+	attachment := fileServer(t, "audio/ogg", 1000, false, http.Header{"Content-Disposition": {`attachment; filename="Voice Message.ogg"`}})
+	track, err := NewResolver(0).Resolve(context.Background(), attachment.URL+"/attachments/1/2/file")
+	require.NoError(t, err)
+	assert.Equal(t, "Voice Message", track.Title)
+}
+
+func TestResolveReportsMissingFiles(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := NewResolver(0).Resolve(context.Background(), server.URL+"/gone.mp3")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+	assert.False(t, errors.Is(err, ErrUnsupportedType))
+}
@@ -0,0 +1,113 @@
+package music
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// AnnouncementProvider is what DJ intros' bandwidth is counted under
+const AnnouncementProvider = "dj"
+
+// announceTimeout bounds speaking an intro, the track waits for it
+const announceTimeout = 15 * time.Second
+
+// Announcer speaks the intro of a track in a guild and returns the path of the audio file, which the
+// player removes after playing it
+type Announcer func(ctx context.Context, guildID string, track types.AudioSource) (string, error)
+
+// UseAnnouncer lets guilds that turn DJ intros on hear a short spoken intro before each track
+func (sp *SimplePlayer) UseAnnouncer(announcer Announcer) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.announcer = announcer
+}
+
+// DJIntrosAvailable reports whether intros can be spoken, guilds can turn DJ intros on either way
+func (sp *SimplePlayer) DJIntrosAvailable() bool {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.announcer != nil
+}
+
+// SetDJIntros turns DJ intros on or off for a guild and saves the setting, from the next track
+func (sp *SimplePlayer) SetDJIntros(guildID string, enabled bool) error {
+	sp.mu.RLock()
+	store := sp.settings
+	sp.mu.RUnlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.DJIntros = enabled })
+	return err
+}
+
+// DJIntros reports whether DJ intros are on for a guild
+func (sp *SimplePlayer) DJIntros(guildID string) bool {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.settings.Get(guildID).DJIntros
+}
+
+// introduce speaks the intro of a track, an empty path when the guild doesn't use DJ intros
+func (sp *SimplePlayer) introduce(ctx context.Context, guildID string, track types.AudioSource) (string, error) {
+	sp.mu.RLock()
+	announcer := sp.announcer
+	enabled := sp.settings.Get(guildID).DJIntros
+	sp.mu.RUnlock()
+
+	if announcer == nil || !enabled {
+		return "", nil
+	}
+	return announcer(ctx, guildID, track)
+}
+
+// announce plays the DJ intro of a track before it starts, and reports whether the track should still
+// play: a skip or stop during the intro applies to the track
+func (vp *VoicePlayer) announce(ctx context.Context, track types.AudioSource) bool {
+	// A Lavalink node only streams tracks it can load itself
+	if vp.introduce == nil || vp.remote != nil {
+		return true
+	}
+
+	vp.mu.RLock()
+	skipChan := vp.skipChan
+	vp.mu.RUnlock()
+
+	introCtx, cancel := context.WithTimeout(ctx, announceTimeout)
+	path, err := vp.introduce(introCtx, track)
+	cancel()
+	if err != nil {
+		// The track plays without its intro
+		utils.LogWarnContext(ctx, "Failed to speak the intro of %s: %v", track.Title, err)
+		return true
+	}
+	if path == "" {
+		return true
+	}
+	defer os.Remove(path)
+
+	// Filters would distort the voice, the volume and normalization keep it as loud as the music
+	intro := types.AudioSource{Title: "DJ intro", Provider: AnnouncementProvider}
+	_, normalize, volume := vp.audioFilters()
+	enc, err := startEncoder(intro, path, 0, nil, normalize, volume, vp.tierLimits().Bitrate)
+	if err == nil {
+		_, err = vp.playEncoder(enc)
+	}
+	if err != nil {
+		utils.LogWarnContext(ctx, "Failed to play the intro of %s: %v", track.Title, err)
+	}
+
+	// Settings changed during the intro need no restart, the track starts with them
+	_, restarted := vp.takeRestart(0)
+	select {
+	case <-skipChan:
+		if !restarted {
+			return false
+		}
+	default:
+	}
+	return vp.IsPlaying()
+}
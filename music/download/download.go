@@ -0,0 +1,222 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+const (
+	// DefaultThreshold is how many streaming failures switch a track to being downloaded first
+	DefaultThreshold = 2
+
+	// EarlyEndTolerance is how far before its known length a stream may end and still count as finished
+	EarlyEndTolerance = 10 * time.Second
+
+	// Timeout bounds a single download and conversion
+	Timeout = 5 * time.Minute
+
+	// StaleAge is how old a downloaded track gets before PruneStale treats it as left behind by a crash
+	StaleAge = 24 * time.Hour
+)
+
+// Failure counts are kept for a while so a track that broke once is downloaded when it is queued again
+const (
+	trackerSize = 500
+	trackerTTL  = 6 * time.Hour
+)
+
+// Tracker counts streaming failures per track and reports which tracks should be downloaded instead
+type Tracker struct {
+	threshold int
+
+	mu       sync.Mutex
+	failures *utils.LRUCache[string, int]
+}
+
+// NewTracker creates a tracker that selects downloading once a track failed threshold times
+func NewTracker(threshold int) *Tracker {
+	return &Tracker{
+		threshold: max(1, threshold),
+		failures:  utils.NewLRUCache[string, int](trackerSize, trackerTTL),
+	}
+}
+
+// RecordFailure counts a streaming failure for the track and returns its total
+func (t *Tracker) RecordFailure(key string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count, _ := t.failures.Get(key)
+	count++
+	t.failures.Add(key, count)
+	return count
+}
+
+// ShouldDownload reports whether the track has failed often enough to be played from a local copy
+func (t *Tracker) ShouldDownload(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count, _ := t.failures.Get(key)
+	return count >= t.threshold
+}
+
+// Len returns how many tracks have recorded failures
+func (t *Tracker) Len() int {
+	return t.failures.Len()
+}
+
+// EndedEarly reports whether a stream that stopped at position broke off before the track's end.
+// Tracks without a known length, such as live streams, never end early.
+func EndedEarly(position time.Duration, length types.Duration) bool {
+	if !length.Known() {
+		return false
+	}
+	return position < length.Std()-EarlyEndTolerance
+}
+
+// Runner runs yt-dlp with the given arguments and returns its standard output
+type Runner func(ctx context.Context, args ...string) ([]byte, error)
+
+// Downloader fetches a track's full audio with yt-dlp and converts it to Opus in a temporary directory
+type Downloader struct {
+	dir   string
+	run   Runner
+	extra func() []string // Options added to every download, such as cookies
+}
+
+// NewDownloader creates a downloader that keeps files under dir and runs the yt-dlp binary
+func NewDownloader(dir string) *Downloader {
+	return NewDownloaderWithRunner(dir, func(ctx context.Context, args ...string) ([]byte, error) {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "yt-dlp", args...)
+		cmd.Stderr = &stderr
+
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("yt-dlp download failed: %w (stderr: %s)", err, strings.TrimSpace(stderr.String()))
+		}
+		return output, nil
+	})
+}
+
+// NewDownloaderWithRunner creates a downloader that runs yt-dlp through run
+func NewDownloaderWithRunner(dir string, run Runner) *Downloader {
+	return &Downloader{dir: dir, run: run}
+}
+
+// UseExtraArgs sets a function whose options are passed to yt-dlp on every download. It is called per
+// download so options that change while the bot runs, like rotated cookies, take effect.
+func (d *Downloader) UseExtraArgs(extra func() []string) {
+	d.extra = extra
+}
+
+// DefaultDir is where downloaded tracks are kept while they play
+func DefaultDir() string {
+	return filepath.Join(os.TempDir(), "pxnx-audio")
+}
+
+// Download fetches the audio of url and returns the path of the converted file. The caller removes
+// the file with Remove once it has been played.
+func (d *Downloader) Download(ctx context.Context, url string) (string, error) {
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	// Each download gets its own directory, so the file name yt-dlp picks after conversion doesn't matter
+	trackDir, err := os.MkdirTemp(d.dir, "track-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	args := []string{
+		"--no-playlist",
+		"--no-warnings",
+		"--format", "bestaudio/best",
+		"--extract-audio",
+		"--audio-format", "opus",
+		"--output", filepath.Join(trackDir, "audio.%(ext)s"),
+		"--print", "after_move:filepath",
+	}
+	if d.extra != nil {
+		args = append(args, d.extra()...)
+	}
+	output, err := d.run(ctx, append(args, "--", url)...)
+	if err != nil {
+		os.RemoveAll(trackDir)
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	path := strings.TrimSpace(lines[len(lines)-1])
+	if path == "" || filepath.Dir(path) != trackDir {
+		os.RemoveAll(trackDir)
+		return "", fmt.Errorf("yt-dlp did not report the downloaded file (output: %s)", strings.TrimSpace(string(output)))
+	}
+	if _, err := os.Stat(path); err != nil {
+		os.RemoveAll(trackDir)
+		return "", fmt.Errorf("downloaded file is missing: %w", err)
+	}
+
+	utils.LogInfo("Downloaded %s to %s", url, path)
+	return path, nil
+}
+
+// Remove deletes a file returned by Download along with its directory
+func (d *Downloader) Remove(path string) {
+	if path == "" {
+		return
+	}
+
+	trackDir := filepath.Dir(path)
+	if filepath.Dir(trackDir) != filepath.Clean(d.dir) {
+		utils.LogWarn("Refusing to remove %s outside the download directory", path)
+		return
+	}
+	if err := os.RemoveAll(trackDir); err != nil {
+		utils.LogWarn("Failed to remove downloaded track %s: %v", path, err)
+	}
+}
+
+// PruneStale removes track directories under dir older than maxAge, which a crash or kill left behind
+// instead of Remove, and returns how many were removed
+func PruneStale(dir string, maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read download directory: %w", err)
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "track-") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			utils.LogWarn("Failed to remove stale download %s: %v", entry.Name(), err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
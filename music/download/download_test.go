@@ -0,0 +1,191 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker(2)
+
+	assert.False(t, tracker.ShouldDownload("song1"))
+	assert.Equal(t, 1, tracker.RecordFailure("song1"))
+	assert.False(t, tracker.ShouldDownload("song1"))
+	assert.Equal(t, 2, tracker.RecordFailure("song1"))
+	assert.True(t, tracker.ShouldDownload("song1"))
+
+	assert.False(t, tracker.ShouldDownload("song2"), "failures are counted per track")
+	assert.Equal(t, 1, tracker.Len())
+
+	// A threshold below one still needs a failure
+	eager := NewTracker(0)
+	assert.False(t, eager.ShouldDownload("song1"))
+	eager.RecordFailure("song1")
+	assert.True(t, eager.ShouldDownload("song1"))
+}
+
+func TestEndedEarly(t *testing.T) {
+	tests := []struct {
+		name     string
+		position time.Duration
+		duration types.Duration
+		expected bool
+	}{
+		{"finished", 212 * time.Second, types.Seconds(212), false},
+		{"within tolerance", 205 * time.Second, types.Seconds(212), false},
+		{"broke off", 95 * time.Second, types.Seconds(212), true},
+		{"unknown length", time.Second, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EndedEarly(tt.position, tt.duration))
+		})
+	}
+}
+
+// fakeDownload writes the converted file where yt-dlp would and prints its path
+func fakeDownload(t *testing.T, calls *[][]string) Runner {
+	return func(ctx context.Context, args ...string) ([]byte, error) {
+		*calls = append(*calls, args)
+
+		var template string
+		for index, arg := range args {
+			if arg == "--output" {
+				template = args[index+1]
+			}
+		}
+		path := strings.Replace(template, "%(ext)s", "opus", 1)
+		require.NoError(t, os.WriteFile(path, []byte("audio"), 0o644))
+		return []byte("[youtube] Extracting URL\n" + path + "\n"), nil
+	}
+}
+
+func TestDownloaderDownloadAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	var calls [][]string
+	downloader := NewDownloaderWithRunner(dir, fakeDownload(t, &calls))
+
+	path, err := downloader.Download(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.FileExists(t, path)
+	assert.Equal(t, ".opus", filepath.Ext(path))
+	assert.Equal(t, dir, filepath.Dir(filepath.Dir(path)))
+
+	require.Len(t, calls, 1)
+	assert.Equal(t, []string{"--", "https://youtu.be/dQw4w9WgXcQ"}, calls[0][len(calls[0])-2:])
+	assert.Contains(t, calls[0], "--extract-audio")
+
+	downloader.Remove(path)
+	assert.NoDirExists(t, filepath.Dir(path))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDownloaderExtraArgs(t *testing.T) {
+	var calls [][]string
+	downloader := NewDownloaderWithRunner(t.TempDir(), fakeDownload(t, &calls))
+	cookies := "first.txt"
+	downloader.UseExtraArgs(func() []string { return []string{"--cookies", cookies} })
+
+	_, err := downloader.Download(context.Background(), "https://youtu.be/abc")
+	require.NoError(t, err)
+	cookies = "second.txt"
+	_, err = downloader.Download(context.Background(), "https://youtu.be/abc")
+	require.NoError(t, err)
+
+	require.Len(t, calls, 2)
+	assert.Equal(t, []string{"--cookies", "first.txt", "--", "https://youtu.be/abc"}, calls[0][len(calls[0])-4:])
+	assert.Equal(t, []string{"--cookies", "second.txt", "--", "https://youtu.be/abc"}, calls[1][len(calls[1])-4:], "options are read per download")
+}
+
+func TestDownloaderFailureCleansUp(t *testing.T) {
+	tests := []struct {
+		name   string
+		runner Runner
+	}{
+		{
+			name: "yt-dlp error",
+			runner: func(ctx context.Context, args ...string) ([]byte, error) {
+				return nil, errors.New("ERROR: Video unavailable")
+			},
+		},
+		{
+			name: "no file reported",
+			runner: func(ctx context.Context, args ...string) ([]byte, error) {
+				return []byte("\n"), nil
+			},
+		},
+		{
+			name: "reported file missing",
+			runner: func(ctx context.Context, args ...string) ([]byte, error) {
+				for index, arg := range args {
+					if arg == "--output" {
+						return []byte(strings.Replace(args[index+1], "%(ext)s", "opus", 1)), nil
+					}
+				}
+				return nil, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			_, err := NewDownloaderWithRunner(dir, tt.runner).Download(context.Background(), "https://youtu.be/abc")
+			assert.Error(t, err)
+
+			entries, readErr := os.ReadDir(dir)
+			require.NoError(t, readErr)
+			assert.Empty(t, entries, "a failed download leaves nothing behind")
+		})
+	}
+}
+
+func TestDownloaderRemoveOutsideDirectory(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "keep", "audio.opus")
+	require.NoError(t, os.MkdirAll(filepath.Dir(outside), 0o755))
+	require.NoError(t, os.WriteFile(outside, []byte("audio"), 0o644))
+
+	NewDownloaderWithRunner(t.TempDir(), nil).Remove(outside)
+	assert.FileExists(t, outside)
+
+	// Nothing to remove is fine
+	NewDownloaderWithRunner(t.TempDir(), nil).Remove("")
+}
+
+func TestPruneStale(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "track-stale")
+	fresh := filepath.Join(dir, "track-fresh")
+	other := filepath.Join(dir, "other")
+	for _, path := range []string{stale, fresh, other} {
+		require.NoError(t, os.MkdirAll(path, 0o755))
+	}
+	old := time.Now().Add(-2 * StaleAge)
+	require.NoError(t, os.Chtimes(stale, old, old))
+	require.NoError(t, os.Chtimes(other, old, old))
+
+	removed, err := PruneStale(dir, StaleAge)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.NoDirExists(t, stale)
+	assert.DirExists(t, fresh)
+	assert.DirExists(t, other, "only track directories are removed")
+
+	removed, err = PruneStale(filepath.Join(dir, "missing"), StaleAge)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
@@ -0,0 +1,198 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Preset is a named FFmpeg audio filter users can enable
+type Preset struct {
+	Name        string
+	Description string
+	Expression  string  // FFmpeg -af expression
+	Speed       float64 // Playback speed factor, 1 when the filter keeps the tempo
+	Group       string  // Presets in the same group replace each other
+}
+
+// Presets are the available filters, in the order they are applied
+var Presets = []Preset{
+	{
+		Name:        "bassboost",
+		Description: "Boost low frequencies",
+		Expression:  "bass=g=10:f=110:w=0.6",
+		Speed:       1,
+	},
+	{
+		Name:        "nightcore",
+		Description: "Faster with higher pitch",
+		Expression:  "aresample=48000,asetrate=60000,aresample=48000",
+		Speed:       1.25,
+		Group:       "speed",
+	},
+	{
+		Name:        "vaporwave",
+		Description: "Slower with lower pitch",
+		Expression:  "aresample=48000,asetrate=38400,aresample=48000",
+		Speed:       0.8,
+		Group:       "speed",
+	},
+	{
+		Name:        "reverb",
+		Description: "Add a room echo",
+		Expression:  "aecho=0.8:0.88:60:0.4",
+		Speed:       1,
+	},
+}
+
+// Lookup finds a preset by name
+func Lookup(name string) (Preset, bool) {
+	for _, preset := range Presets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// Chain is the set of enabled presets for a guild, always kept in Presets order
+type Chain []string
+
+// Toggle enables the preset if it is off, or disables it if it is on, and reports whether it is now enabled.
+// Enabling a preset disables others in its group.
+func (c Chain) Toggle(name string) (Chain, bool, error) {
+	preset, ok := Lookup(name)
+	if !ok {
+		return c, false, fmt.Errorf("unknown filter %q", name)
+	}
+
+	if c.Has(name) {
+		return c.without(func(p Preset) bool { return p.Name == name }), false, nil
+	}
+
+	next := c
+	if preset.Group != "" {
+		next = c.without(func(p Preset) bool { return p.Group == preset.Group })
+	}
+	next = append(append(Chain(nil), next...), name)
+	return next.sorted(), true, nil
+}
+
+// Has reports whether a preset is enabled
+func (c Chain) Has(name string) bool {
+	for _, enabled := range c {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Expression joins the enabled presets into a single FFmpeg filter graph
+func (c Chain) Expression() string {
+	parts := make([]string, 0, len(c))
+	for _, name := range c {
+		if preset, ok := Lookup(name); ok {
+			parts = append(parts, preset.Expression)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Speed returns the combined playback speed factor of the chain
+func (c Chain) Speed() float64 {
+	speed := 1.0
+	for _, name := range c {
+		if preset, ok := Lookup(name); ok {
+			speed *= preset.Speed
+		}
+	}
+	return speed
+}
+
+// String lists the enabled presets for display
+func (c Chain) String() string {
+	if len(c) == 0 {
+		return "none"
+	}
+	return strings.Join(c, ", ")
+}
+
+// without returns the chain minus presets matching drop
+func (c Chain) without(drop func(Preset) bool) Chain {
+	result := Chain{}
+	for _, name := range c {
+		if preset, ok := Lookup(name); ok && drop(preset) {
+			continue
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+// sorted orders the chain like Presets so the same set always builds the same filter graph
+func (c Chain) sorted() Chain {
+	result := Chain{}
+	for _, preset := range Presets {
+		if c.Has(preset.Name) {
+			result = append(result, preset.Name)
+		}
+	}
+	return result
+}
+
+// DefaultBitrate is the Opus bitrate in kbps the encoder uses unless told otherwise
+const DefaultBitrate = 128
+
+// Loudnorm is the EBU R128 loudness normalization filter, targeting -16 LUFS like most streaming services
+const Loudnorm = "loudnorm=I=-16:TP=-1.5:LRA=11"
+
+// EncoderArgs builds the FFmpeg arguments that stream a track from offset through the filter chain as Opus
+// at bitrate kbps, DefaultBitrate when it is 0.
+// The input is a stream URL or a downloaded file. With normalize set, loudness is normalized after the
+// chain's effects. Volume is in percent and applied last; 0 and 100 leave it unchanged.
+func EncoderArgs(streamURL string, offset time.Duration, chain Chain, normalize bool, volume, bitrate int) []string {
+	if bitrate <= 0 {
+		bitrate = DefaultBitrate
+	}
+
+	var args []string
+	if strings.Contains(streamURL, "://") {
+		// Reconnect options only exist for network inputs, FFmpeg rejects them for files
+		args = append(args,
+			"-reconnect", "1",
+			"-reconnect_streamed", "1",
+			"-reconnect_delay_max", "2",
+		)
+	}
+	if offset > 0 {
+		// Seeking before -i skips the input without decoding it
+		args = append(args, "-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64))
+	}
+	args = append(args, "-i", streamURL)
+	expression := chain.Expression()
+	if normalize {
+		if expression != "" {
+			expression += ","
+		}
+		expression += Loudnorm
+	}
+	if volume > 0 && volume != 100 {
+		if expression != "" {
+			expression += ","
+		}
+		expression += "volume=" + strconv.FormatFloat(float64(volume)/100, 'f', 2, 64)
+	}
+	if expression != "" {
+		args = append(args, "-af", expression)
+	}
+	return append(args,
+		"-f", "opus",
+		"-ar", "48000",
+		"-ac", "2",
+		"-b:a", strconv.Itoa(bitrate)+"k",
+		"-vn",
+		"pipe:1",
+	)
+}
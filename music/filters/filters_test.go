@@ -0,0 +1,118 @@
+package filters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainToggle(t *testing.T) {
+	var chain Chain
+
+	chain, enabled, err := chain.Toggle("reverb")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+
+	chain, enabled, err = chain.Toggle("bassboost")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	assert.Equal(t, Chain{"bassboost", "reverb"}, chain, "chain follows preset order")
+
+	chain, enabled, err = chain.Toggle("reverb")
+	require.NoError(t, err)
+	assert.False(t, enabled)
+	assert.Equal(t, Chain{"bassboost"}, chain)
+
+	_, _, err = chain.Toggle("chipmunk")
+	assert.Error(t, err)
+}
+
+func TestChainToggleReplacesGroup(t *testing.T) {
+	chain, _, _ := Chain{}.Toggle("nightcore")
+	chain, enabled, err := chain.Toggle("vaporwave")
+	require.NoError(t, err)
+	assert.True(t, enabled)
+	assert.Equal(t, Chain{"vaporwave"}, chain, "speed presets replace each other")
+}
+
+func TestChainToggleDoesNotAlias(t *testing.T) {
+	original := make(Chain, 1, 4)
+	original[0] = "bassboost"
+
+	first, _, _ := original.Toggle("reverb")
+	second, _, _ := original.Toggle("nightcore")
+	assert.Equal(t, Chain{"bassboost", "reverb"}, first)
+	assert.Equal(t, Chain{"bassboost", "nightcore"}, second)
+}
+
+func TestChainExpressionAndSpeed(t *testing.T) {
+	chain := Chain{"bassboost", "nightcore"}
+	assert.Equal(t, "bass=g=10:f=110:w=0.6,aresample=48000,asetrate=60000,aresample=48000", chain.Expression())
+	assert.InDelta(t, 1.25, chain.Speed(), 0.0001)
+
+	assert.Equal(t, "", Chain{}.Expression())
+	assert.Equal(t, 1.0, Chain{}.Speed())
+	assert.Equal(t, "none", Chain{}.String())
+	assert.Equal(t, "bassboost, nightcore", chain.String())
+}
+
+func TestEncoderArgs(t *testing.T) {
+	args := EncoderArgs("https://stream", 0, nil, false, 0, 0)
+	assert.NotContains(t, args, "-ss")
+	assert.NotContains(t, args, "-af")
+	assert.Equal(t, "pipe:1", args[len(args)-1])
+
+	args = EncoderArgs("https://stream", 90500*time.Millisecond, Chain{"reverb"}, false, 0, 0)
+	assert.Contains(t, args, "-ss")
+	ssIndex := indexOf(args, "-ss")
+	inputIndex := indexOf(args, "-i")
+	assert.Less(t, ssIndex, inputIndex, "seek before the input for fast seeking")
+	assert.Equal(t, "90.500", args[ssIndex+1])
+	assert.Equal(t, "aecho=0.8:0.88:60:0.4", args[indexOf(args, "-af")+1])
+}
+
+func TestEncoderArgsBitrate(t *testing.T) {
+	args := EncoderArgs("https://stream", 0, nil, false, 0, 0)
+	assert.Equal(t, "128k", args[indexOf(args, "-b:a")+1])
+
+	args = EncoderArgs("https://stream", 0, nil, false, 0, 96)
+	assert.Equal(t, "96k", args[indexOf(args, "-b:a")+1])
+}
+
+func TestEncoderArgsLocalFile(t *testing.T) {
+	assert.Contains(t, EncoderArgs("https://stream", 0, nil, false, 0, 0), "-reconnect")
+
+	args := EncoderArgs("/tmp/pxnx-audio/track-1/audio.opus", 30*time.Second, nil, false, 0, 0)
+	assert.NotContains(t, args, "-reconnect")
+	assert.Equal(t, "/tmp/pxnx-audio/track-1/audio.opus", args[indexOf(args, "-i")+1])
+	assert.Equal(t, "30.000", args[indexOf(args, "-ss")+1])
+}
+
+func TestEncoderArgsLoudnorm(t *testing.T) {
+	args := EncoderArgs("https://stream", 0, nil, true, 0, 0)
+	assert.Equal(t, Loudnorm, args[indexOf(args, "-af")+1])
+
+	args = EncoderArgs("https://stream", 0, Chain{"reverb"}, true, 0, 0)
+	assert.Equal(t, "aecho=0.8:0.88:60:0.4,"+Loudnorm, args[indexOf(args, "-af")+1], "normalization runs after the effects")
+}
+
+func TestEncoderArgsVolume(t *testing.T) {
+	assert.NotContains(t, EncoderArgs("https://stream", 0, nil, false, 100, 0), "-af")
+
+	args := EncoderArgs("https://stream", 0, nil, false, 50, 0)
+	assert.Equal(t, "volume=0.50", args[indexOf(args, "-af")+1])
+
+	args = EncoderArgs("https://stream", 0, nil, true, 150, 0)
+	assert.Equal(t, Loudnorm+",volume=1.50", args[indexOf(args, "-af")+1], "volume applies after normalization")
+}
+
+func indexOf(args []string, value string) int {
+	for i, arg := range args {
+		if arg == value {
+			return i
+		}
+	}
+	return -1
+}
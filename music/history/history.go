@@ -0,0 +1,84 @@
+package history
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/music/types"
+)
+
+// DefaultSize is the number of played tracks remembered per guild
+const DefaultSize = 25
+
+// Entry is a track that started playing
+type Entry struct {
+	Track    types.AudioSource
+	PlayedAt time.Time
+}
+
+// History is a thread-safe, bounded list of recently played tracks. Repeats are checked against
+// PlayTimes instead, which looks back further.
+type History struct {
+	entries []Entry // Oldest first
+	size    int
+	mu      sync.RWMutex
+}
+
+// New creates a history that keeps the last size tracks
+func New(size int) *History {
+	if size < 1 {
+		size = 1
+	}
+	return &History{size: size}
+}
+
+// Add records a track as played, dropping the oldest entry when full.
+// Stream URLs expire, so they are not kept.
+func (h *History) Add(track types.AudioSource) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	track.StreamURL = ""
+	h.entries = append(h.entries, Entry{Track: track, PlayedAt: time.Now()})
+	if len(h.entries) > h.size {
+		h.entries = h.entries[len(h.entries)-h.size:]
+	}
+}
+
+// Entries returns the recorded tracks, most recently played first
+func (h *History) Entries() []Entry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	result := make([]Entry, len(h.entries))
+	for i, entry := range h.entries {
+		result[len(h.entries)-1-i] = entry
+	}
+	return result
+}
+
+// Get returns the nth most recently played track, where 1 is the latest
+func (h *History) Get(n int) (Entry, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if n < 1 || n > len(h.entries) {
+		return Entry{}, fmt.Errorf("history entry %d out of range (history size: %d)", n, len(h.entries))
+	}
+	return h.entries[len(h.entries)-n], nil
+}
+
+// Len returns the number of recorded tracks
+func (h *History) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.entries)
+}
+
+// Clear forgets all recorded tracks
+func (h *History) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = nil
+}
@@ -0,0 +1,72 @@
+package history
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+func createTestTrack(title string) types.AudioSource {
+	return types.AudioSource{
+		Title:     title,
+		URL:       "https://youtube.com/watch?v=" + title,
+		StreamURL: "https://stream.example.com/" + title,
+	}
+}
+
+func TestHistoryNewestFirst(t *testing.T) {
+	h := New(DefaultSize)
+	h.Add(createTestTrack("song1"))
+	h.Add(createTestTrack("song2"))
+	h.Add(createTestTrack("song3"))
+
+	entries := h.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "song3", entries[0].Track.Title)
+	assert.Equal(t, "song1", entries[2].Track.Title)
+	assert.False(t, entries[0].PlayedAt.IsZero())
+}
+
+func TestHistoryBounded(t *testing.T) {
+	h := New(3)
+	for i := 0; i < 5; i++ {
+		h.Add(createTestTrack(fmt.Sprintf("song%d", i)))
+	}
+
+	assert.Equal(t, 3, h.Len())
+	oldest, err := h.Get(3)
+	require.NoError(t, err)
+	assert.Equal(t, "song2", oldest.Track.Title)
+}
+
+func TestHistoryGet(t *testing.T) {
+	h := New(DefaultSize)
+	h.Add(createTestTrack("song1"))
+	h.Add(createTestTrack("song2"))
+
+	latest, err := h.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, "song2", latest.Track.Title)
+	assert.Empty(t, latest.Track.StreamURL, "expiring stream URLs should not be kept")
+	assert.NotEmpty(t, latest.Track.URL)
+
+	_, err = h.Get(0)
+	assert.Error(t, err)
+	_, err = h.Get(3)
+	assert.Error(t, err)
+}
+
+func TestHistoryClear(t *testing.T) {
+	h := New(0)
+	h.Add(createTestTrack("song1"))
+	h.Add(createTestTrack("song2"))
+	assert.Equal(t, 1, h.Len(), "size is at least one")
+
+	h.Clear()
+	assert.Equal(t, 0, h.Len())
+	assert.Empty(t, h.Entries())
+}
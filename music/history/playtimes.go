@@ -0,0 +1,55 @@
+package history
+
+import (
+	"time"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// Play times are kept for longer than the displayed history so repeats can be detected over hours
+const (
+	playTimesSize = 1000
+	MaxLookback   = 24 * time.Hour
+)
+
+// PlayTimes remembers when a guild last played each track, up to MaxLookback. Unlike History it
+// outlives a voice session, so leaving and rejoining doesn't reset the anti-repeat window.
+type PlayTimes struct {
+	times *utils.LRUCache[string, time.Time] // Last play time per track URL
+}
+
+// NewPlayTimes creates an empty record of play times
+func NewPlayTimes() *PlayTimes {
+	return &PlayTimes{times: utils.NewLRUCache[string, time.Time](playTimesSize, MaxLookback)}
+}
+
+// Record notes that a track started playing at playedAt
+func (p *PlayTimes) Record(track types.AudioSource, playedAt time.Time) {
+	if track.URL == "" {
+		return
+	}
+	p.times.Add(track.URL, playedAt)
+}
+
+// LastPlayed returns when the track with the given URL was last played, looking back up to MaxLookback
+func (p *PlayTimes) LastPlayed(url string) (time.Time, bool) {
+	if url == "" {
+		return time.Time{}, false
+	}
+	return p.times.Get(url)
+}
+
+// PlayedWithin reports whether the track with the given URL was played within window of now
+func (p *PlayTimes) PlayedWithin(url string, window time.Duration, now time.Time) (time.Time, bool) {
+	playedAt, ok := p.LastPlayed(url)
+	if !ok || now.Sub(playedAt) > window {
+		return time.Time{}, false
+	}
+	return playedAt, true
+}
+
+// Len returns the number of tracks with a play time
+func (p *PlayTimes) Len() int {
+	return p.times.Len()
+}
@@ -0,0 +1,32 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+func TestPlayTimesPlayedWithin(t *testing.T) {
+	played := NewPlayTimes()
+	playedAt := time.Now()
+	played.Record(createTestTrack("song1"), playedAt)
+	played.Record(types.AudioSource{Title: "No link"}, playedAt)
+	assert.Equal(t, 1, played.Len(), "tracks without a URL can't be looked up")
+
+	got, ok := played.LastPlayed("https://youtube.com/watch?v=song1")
+	require.True(t, ok)
+	assert.Equal(t, playedAt, got)
+
+	_, ok = played.PlayedWithin("https://youtube.com/watch?v=song1", time.Hour, playedAt.Add(30*time.Minute))
+	assert.True(t, ok)
+	_, ok = played.PlayedWithin("https://youtube.com/watch?v=song1", time.Hour, playedAt.Add(2*time.Hour))
+	assert.False(t, ok)
+	_, ok = played.PlayedWithin("https://youtube.com/watch?v=unknown", time.Hour, playedAt)
+	assert.False(t, ok)
+	_, ok = played.LastPlayed("")
+	assert.False(t, ok)
+}
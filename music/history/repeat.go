@@ -0,0 +1,36 @@
+package history
+
+import "time"
+
+// RepeatMode controls what happens when a recently played track is requested again
+type RepeatMode string
+
+const (
+	RepeatAllow  RepeatMode = "off"    // Repeats are queued as usual
+	RepeatWarn   RepeatMode = "warn"   // Repeats are queued with a warning
+	RepeatRefuse RepeatMode = "refuse" // Repeats are not queued
+)
+
+// RepeatPolicy is a guild's anti-repeat setting
+type RepeatPolicy struct {
+	Mode   RepeatMode
+	Window time.Duration // How long after playing a track counts as a repeat, up to MaxLookback
+}
+
+// Enabled reports whether the policy checks for repeats at all
+func (p RepeatPolicy) Enabled() bool {
+	return (p.Mode == RepeatWarn || p.Mode == RepeatRefuse) && p.Window > 0
+}
+
+// Check returns when the track was last played if requesting it now counts as a repeat
+func (p RepeatPolicy) Check(played *PlayTimes, url string, now time.Time) (time.Time, bool) {
+	if !p.Enabled() || played == nil {
+		return time.Time{}, false
+	}
+
+	window := p.Window
+	if window > MaxLookback {
+		window = MaxLookback
+	}
+	return played.PlayedWithin(url, window, now)
+}
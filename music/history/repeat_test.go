@@ -0,0 +1,38 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatPolicyEnabled(t *testing.T) {
+	assert.False(t, RepeatPolicy{}.Enabled())
+	assert.False(t, RepeatPolicy{Mode: RepeatAllow, Window: time.Hour}.Enabled())
+	assert.False(t, RepeatPolicy{Mode: RepeatRefuse}.Enabled(), "a zero window disables the check")
+	assert.True(t, RepeatPolicy{Mode: RepeatWarn, Window: time.Hour}.Enabled())
+	assert.True(t, RepeatPolicy{Mode: RepeatRefuse, Window: time.Hour}.Enabled())
+}
+
+func TestRepeatPolicyCheck(t *testing.T) {
+	played := NewPlayTimes()
+	playedAt := time.Now()
+	played.Record(createTestTrack("song1"), playedAt)
+	url := "https://youtube.com/watch?v=song1"
+
+	policy := RepeatPolicy{Mode: RepeatRefuse, Window: 2 * time.Hour}
+
+	got, ok := policy.Check(played, url, playedAt.Add(time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, playedAt, got)
+
+	_, ok = policy.Check(played, url, playedAt.Add(3*time.Hour))
+	assert.False(t, ok, "plays outside the window are not repeats")
+
+	_, ok = RepeatPolicy{Mode: RepeatAllow, Window: 2 * time.Hour}.Check(played, url, playedAt)
+	assert.False(t, ok)
+
+	_, ok = policy.Check(nil, url, playedAt)
+	assert.False(t, ok)
+}
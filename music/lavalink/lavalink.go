@@ -0,0 +1,436 @@
+// Package lavalink plays music through a Lavalink v4 node instead of encoding it in the bot. The node
+// loads tracks, encodes them and streams them to Discord's voice servers itself; the bot only joins voice
+// channels on the gateway and forwards its voice session to the node.
+package lavalink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"pxnx-discord-bot/httpclient"
+	"pxnx-discord-bot/utils"
+)
+
+// clientName is sent to the node to identify the bot in its logs
+const clientName = "pxnx-discord-bot"
+
+// Timeouts and retry delays of the node connection
+const (
+	requestTimeout = 15 * time.Second
+	reconnectDelay = 5 * time.Second
+	maxReconnect   = time.Minute
+)
+
+// ErrNotConnected is returned when the node has no session yet, before the first connection or while
+// reconnecting
+var ErrNotConnected = errors.New("lavalink node is not connected")
+
+// ErrNoMatches is returned when the node finds nothing to play for an identifier
+var ErrNoMatches = errors.New("lavalink found nothing to play")
+
+// Config is how to reach a Lavalink node
+type Config struct {
+	URL      string // Node address such as http://localhost:2333, ws(s) is derived from it
+	Password string // The node's lavalink.server.password
+}
+
+// TrackInfo describes a track loaded by the node
+type TrackInfo struct {
+	Identifier string `json:"identifier"`
+	IsSeekable bool   `json:"isSeekable"`
+	Author     string `json:"author"`
+	Length     int64  `json:"length"` // Milliseconds
+	IsStream   bool   `json:"isStream"`
+	Position   int64  `json:"position"`
+	Title      string `json:"title"`
+	URI        string `json:"uri"`
+	ArtworkURL string `json:"artworkUrl"`
+	SourceName string `json:"sourceName"`
+}
+
+// Track is a loaded track, played by handing its encoded form back to the node
+type Track struct {
+	Encoded string    `json:"encoded"`
+	Info    TrackInfo `json:"info"`
+}
+
+// Exception is an error reported by the node
+type Exception struct {
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+	Cause    string `json:"cause"`
+}
+
+func (e *Exception) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("lavalink %s error: %s", e.Severity, e.Cause)
+	}
+	return fmt.Sprintf("lavalink %s error: %s", e.Severity, e.Message)
+}
+
+// loadResult is the response of /v4/loadtracks, Data depends on LoadType
+type loadResult struct {
+	LoadType string          `json:"loadType"` // track, playlist, search, empty or error
+	Data     json.RawMessage `json:"data"`
+}
+
+// voiceState is the bot's voice session in a guild, which the node connects to the voice server with
+type voiceState struct {
+	Token     string `json:"token"`
+	Endpoint  string `json:"endpoint"`
+	SessionID string `json:"sessionId"`
+}
+
+// complete reports whether the node has everything it needs to connect
+func (v voiceState) complete() bool {
+	return v.Token != "" && v.Endpoint != "" && v.SessionID != ""
+}
+
+// trackUpdate sets the playing track; a nil Encoded is sent as null and stops playback
+type trackUpdate struct {
+	Encoded *string `json:"encoded"`
+}
+
+// playerUpdate is the body of a player PATCH, only the set fields change
+type playerUpdate struct {
+	Track    *trackUpdate `json:"track,omitempty"`
+	Position *int64       `json:"position,omitempty"`
+	Paused   *bool        `json:"paused,omitempty"`
+	Volume   *int         `json:"volume,omitempty"`
+	Voice    *voiceState  `json:"voice,omitempty"`
+}
+
+// message is an op sent by the node over the websocket
+type message struct {
+	Op        string `json:"op"` // ready, playerUpdate, stats or event
+	SessionID string `json:"sessionId"`
+	Resumed   bool   `json:"resumed"`
+	GuildID   string `json:"guildId"`
+	State     struct {
+		Position  int64 `json:"position"`
+		Connected bool  `json:"connected"`
+	} `json:"state"`
+	Type      string     `json:"type"` // Event type
+	Track     *Track     `json:"track"`
+	Reason    string     `json:"reason"`
+	Exception *Exception `json:"exception"`
+	Code      int        `json:"code"`
+}
+
+// Node is a connection to one Lavalink node. It keeps its websocket open, reconnecting when it drops,
+// and routes player events to the guild's Player.
+type Node struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	userID    string
+	sessionID string
+	players   map[string]*Player
+	stop      chan struct{}
+}
+
+// NewNode creates a node client; Connect opens the connection once the bot's user ID is known
+func NewNode(config Config) *Node {
+	config.URL = strings.TrimRight(strings.TrimSpace(config.URL), "/")
+	return &Node{
+		config:  config,
+		client:  httpclient.NewInternal(requestTimeout),
+		players: make(map[string]*Player),
+	}
+}
+
+// Connect starts keeping the websocket to the node open for the bot user. Calling it again, such as on
+// every gateway Ready, does nothing while the connection loop runs.
+func (n *Node) Connect(userID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.stop != nil {
+		return
+	}
+	n.userID = userID
+	n.stop = make(chan struct{})
+	stop := n.stop
+	utils.SafeGoWithRestart("lavalink.node", utils.RestartPolicy{MaxRestarts: -1, Backoff: reconnectDelay}, func() {
+		n.run(stop)
+	})
+}
+
+// Close stops the connection loop; players keep their state on the node until it times them out
+func (n *Node) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.stop != nil {
+		close(n.stop)
+		n.stop = nil
+	}
+	n.sessionID = ""
+}
+
+// Connected reports whether the node has a session that players can use
+func (n *Node) Connected() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.sessionID != ""
+}
+
+// Player returns the guild's player, creating it on first use
+func (n *Node) Player(guildID string) *Player {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	player, exists := n.players[guildID]
+	if !exists {
+		player = newPlayer(n, guildID)
+		n.players[guildID] = player
+	}
+	return player
+}
+
+// run dials the node and reads from it until stop is closed, waiting longer after each failed attempt
+func (n *Node) run(stop <-chan struct{}) {
+	delay := reconnectDelay
+	for {
+		err := n.listen(stop)
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		// A connection that got as far as a session starts the backoff over
+		n.mu.Lock()
+		if n.sessionID != "" {
+			delay = reconnectDelay
+		}
+		n.sessionID = ""
+		n.mu.Unlock()
+		utils.LogWarn("Lavalink connection lost, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnect)
+	}
+}
+
+// listen holds one websocket connection and handles its messages until it closes
+func (n *Node) listen(stop <-chan struct{}) error {
+	n.mu.Lock()
+	header := http.Header{}
+	header.Set("Authorization", n.config.Password)
+	header.Set("User-Id", n.userID)
+	header.Set("Client-Name", clientName)
+	n.mu.Unlock()
+
+	wsURL := strings.Replace(n.config.URL, "http", "ws", 1) + "/v4/websocket"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", wsURL, err)
+	}
+	defer conn.Close()
+
+	// Closing the connection ends the read loop when the node is closed
+	done := make(chan struct{})
+	defer close(done)
+	utils.SafeGo("lavalink.closer", func() {
+		select {
+		case <-stop:
+			conn.Close()
+		case <-done:
+		}
+	})
+
+	for {
+		var msg message
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+		n.handle(msg)
+	}
+}
+
+// handle routes a node message
+func (n *Node) handle(msg message) {
+	switch msg.Op {
+	case "ready":
+		n.mu.Lock()
+		n.sessionID = msg.SessionID
+		players := make([]*Player, 0, len(n.players))
+		for _, player := range n.players {
+			players = append(players, player)
+		}
+		n.mu.Unlock()
+
+		utils.LogInfo("Connected to Lavalink node %s (session %s)", n.config.URL, msg.SessionID)
+		// A new session has no players, the ones that were playing continue where they were
+		if !msg.Resumed {
+			for _, player := range players {
+				utils.SafeGo("lavalink.restore", player.restore)
+			}
+		}
+	case "playerUpdate":
+		if player := n.existingPlayer(msg.GuildID); player != nil {
+			player.updateState(msg.State.Position, msg.State.Connected)
+		}
+	case "event":
+		if player := n.existingPlayer(msg.GuildID); player != nil {
+			player.handleEvent(msg)
+		}
+	}
+}
+
+// existingPlayer returns a guild's player without creating one
+func (n *Node) existingPlayer(guildID string) *Player {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.players[guildID]
+}
+
+// forget drops a destroyed player
+func (n *Node) forget(guildID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.players, guildID)
+}
+
+// LoadTrack loads the first track for an identifier: a link, or a search like "ytsearch:lofi" when the
+// node has a source for it
+func (n *Node) LoadTrack(ctx context.Context, identifier string) (*Track, error) {
+	var result loadResult
+	path := "/v4/loadtracks?identifier=" + url.QueryEscape(identifier)
+	if err := n.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	switch result.LoadType {
+	case "track":
+		var track Track
+		if err := json.Unmarshal(result.Data, &track); err != nil {
+			return nil, fmt.Errorf("failed to parse lavalink track: %w", err)
+		}
+		return &track, nil
+	case "playlist":
+		var playlist struct {
+			Info struct {
+				SelectedTrack int `json:"selectedTrack"`
+			} `json:"info"`
+			Tracks []Track `json:"tracks"`
+		}
+		if err := json.Unmarshal(result.Data, &playlist); err != nil {
+			return nil, fmt.Errorf("failed to parse lavalink playlist: %w", err)
+		}
+		if len(playlist.Tracks) == 0 {
+			return nil, ErrNoMatches
+		}
+		selected := playlist.Info.SelectedTrack
+		if selected < 0 || selected >= len(playlist.Tracks) {
+			selected = 0
+		}
+		return &playlist.Tracks[selected], nil
+	case "search":
+		var tracks []Track
+		if err := json.Unmarshal(result.Data, &tracks); err != nil {
+			return nil, fmt.Errorf("failed to parse lavalink search: %w", err)
+		}
+		if len(tracks) == 0 {
+			return nil, ErrNoMatches
+		}
+		return &tracks[0], nil
+	case "error":
+		var exception Exception
+		if err := json.Unmarshal(result.Data, &exception); err != nil {
+			return nil, fmt.Errorf("failed to parse lavalink error: %w", err)
+		}
+		return nil, &exception
+	default:
+		return nil, ErrNoMatches
+	}
+}
+
+// updatePlayer changes a guild's player on the node
+func (n *Node) updatePlayer(ctx context.Context, guildID string, update playerUpdate) error {
+	sessionID, err := n.session()
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/v4/sessions/%s/players/%s", sessionID, guildID)
+	return n.request(ctx, http.MethodPatch, path, update, nil)
+}
+
+// destroyPlayer removes a guild's player from the node, which also leaves its voice server
+func (n *Node) destroyPlayer(ctx context.Context, guildID string) error {
+	sessionID, err := n.session()
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/v4/sessions/%s/players/%s", sessionID, guildID)
+	return n.request(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// session returns the current session ID
+func (n *Node) session() (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.sessionID == "" {
+		return "", ErrNotConnected
+	}
+	return n.sessionID, nil
+}
+
+// request calls the node's REST API, decoding the response into out when it is not nil
+func (n *Node) request(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode lavalink request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, n.config.URL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build lavalink request: %w", err)
+	}
+	req.Header.Set("Authorization", n.config.Password)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("lavalink request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&failure)
+		return fmt.Errorf("lavalink %s %s returned %s: %s", method, strings.SplitN(path, "?", 2)[0], resp.Status, failure.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse lavalink response: %w", err)
+	}
+	return nil
+}
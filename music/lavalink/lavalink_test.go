@@ -0,0 +1,158 @@
+package lavalink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPassword = "youshallnotpass"
+
+// fakeNode serves the parts of the Lavalink v4 API the client uses and records player updates
+type fakeNode struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	headers http.Header
+	updates []map[string]any
+	tracks  map[string]loadResult
+}
+
+func newFakeNode(t *testing.T) *fakeNode {
+	f := &fakeNode{t: t, tracks: make(map[string]loadResult)}
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v4/websocket", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		f.mu.Lock()
+		f.conn, f.headers = conn, r.Header.Clone()
+		f.mu.Unlock()
+		f.send(map[string]any{"op": "ready", "resumed": false, "sessionId": "session-1"})
+	})
+	mux.HandleFunc("/v4/loadtracks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != testPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		result, exists := f.tracks[r.URL.Query().Get("identifier")]
+		f.mu.Unlock()
+		if !exists {
+			result = loadResult{LoadType: "empty", Data: json.RawMessage(`{}`)}
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("/v4/sessions/session-1/players/guild-1", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		update := map[string]any{"method": r.Method}
+		if len(body) > 0 {
+			require.NoError(t, json.Unmarshal(body, &update))
+			update["method"] = r.Method
+		}
+		f.mu.Lock()
+		f.updates = append(f.updates, update)
+		f.mu.Unlock()
+		w.Write([]byte(`{}`))
+	})
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// addTrack makes an identifier load a single track
+func (f *fakeNode) addTrack(identifier, encoded, title string) {
+	data, _ := json.Marshal(Track{Encoded: encoded, Info: TrackInfo{Title: title, URI: identifier}})
+	f.mu.Lock()
+	f.tracks[identifier] = loadResult{LoadType: "track", Data: data}
+	f.mu.Unlock()
+}
+
+// send writes a websocket message to the client
+func (f *fakeNode) send(msg map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.NoError(f.t, f.conn.WriteJSON(msg))
+}
+
+// lastUpdate returns the most recent player update
+func (f *fakeNode) lastUpdate() map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.NotEmpty(f.t, f.updates)
+	return f.updates[len(f.updates)-1]
+}
+
+func (f *fakeNode) updateCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.updates)
+}
+
+// connectNode connects a client to the fake node and waits for its session
+func connectNode(t *testing.T, f *fakeNode) *Node {
+	node := NewNode(Config{URL: f.server.URL + "/", Password: testPassword})
+	node.Connect("bot-user")
+	t.Cleanup(node.Close)
+
+	require.Eventually(t, node.Connected, time.Second, time.Millisecond)
+	return node
+}
+
+func TestNodeConnects(t *testing.T) {
+	f := newFakeNode(t)
+	connectNode(t, f)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assert.Equal(t, testPassword, f.headers.Get("Authorization"))
+	assert.Equal(t, "bot-user", f.headers.Get("User-Id"))
+	assert.Equal(t, clientName, f.headers.Get("Client-Name"))
+}
+
+func TestLoadTrack(t *testing.T) {
+	f := newFakeNode(t)
+	node := NewNode(Config{URL: f.server.URL, Password: testPassword})
+	f.addTrack("https://youtu.be/one", "encoded-one", "Song One")
+
+	track, err := node.LoadTrack(context.Background(), "https://youtu.be/one")
+	require.NoError(t, err)
+	assert.Equal(t, "encoded-one", track.Encoded)
+	assert.Equal(t, "Song One", track.Info.Title)
+
+	_, err = node.LoadTrack(context.Background(), "https://youtu.be/missing")
+	assert.ErrorIs(t, err, ErrNoMatches)
+
+	f.mu.Lock()
+	f.tracks["broken"] = loadResult{LoadType: "error", Data: json.RawMessage(`{"message":"Video unavailable","severity":"common"}`)}
+	f.mu.Unlock()
+	_, err = node.LoadTrack(context.Background(), "broken")
+	var exception *Exception
+	require.ErrorAs(t, err, &exception)
+	assert.Equal(t, "Video unavailable", exception.Message)
+
+	_, err = NewNode(Config{URL: f.server.URL, Password: "wrong"}).LoadTrack(context.Background(), "anything")
+	assert.ErrorContains(t, err, "401")
+}
+
+func TestPlayerNeedsSession(t *testing.T) {
+	f := newFakeNode(t)
+	node := NewNode(Config{URL: f.server.URL, Password: testPassword})
+	f.addTrack("https://youtu.be/one", "encoded-one", "Song One")
+
+	assert.ErrorIs(t, node.Player("guild-1").Pause(), ErrNotConnected)
+	assert.NoError(t, node.Player("guild-1").Destroy(context.Background()), "there is nothing to destroy without a session")
+}
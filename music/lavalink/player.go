@@ -0,0 +1,370 @@
+package lavalink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// Reasons a track ended, as reported by the node; ReasonStuck is added for tracks that stopped sending audio
+const (
+	ReasonFinished   = "finished"
+	ReasonLoadFailed = "loadFailed"
+	ReasonStopped    = "stopped"
+	ReasonReplaced   = "replaced"
+	ReasonCleanup    = "cleanup"
+	ReasonStuck      = "stuck"
+)
+
+// defaultVolume is the node's volume for unchanged audio
+const defaultVolume = 100
+
+// maxVolume is the highest volume the node accepts
+const maxVolume = 1000
+
+// End reports how a track stopped playing on the node
+type End struct {
+	Reason string // One of the Reason constants
+	Err    error  // Why the track failed, set for loadFailed and stuck tracks
+}
+
+// Failed reports whether the track ended because it couldn't be played
+func (e End) Failed() bool {
+	return e.Reason == ReasonLoadFailed || e.Reason == ReasonStuck
+}
+
+// Player controls a guild's playback on the node. It implements types.AudioPlayer; the voice session
+// it plays into is forwarded from the gateway with SetVoiceSession and SetVoiceServer.
+type Player struct {
+	node    *Node
+	guildID string
+
+	mu         sync.Mutex
+	voice      voiceState
+	channelID  string
+	source     *types.AudioSource
+	track      *Track
+	ended      chan End
+	failure    error // Exception reported for the current track, sent with its end
+	paused     bool
+	volume     int
+	position   time.Duration // Last position the node reported
+	positionAt time.Time     // When position was reported or playback (re)started
+	connected  bool          // The node is connected to the voice server
+}
+
+// newPlayer creates a guild's player for a node
+func newPlayer(node *Node, guildID string) *Player {
+	return &Player{node: node, guildID: guildID, volume: defaultVolume}
+}
+
+// Play loads a track on the node and starts it, replacing the current one. The page link is loaded
+// first so the node picks its own stream, then the resolved stream URL.
+func (p *Player) Play(ctx context.Context, source types.AudioSource) error {
+	var track *Track
+	var err error
+	for _, identifier := range []string{source.URL, source.StreamURL} {
+		if identifier == "" {
+			continue
+		}
+		if track, err = p.node.LoadTrack(ctx, identifier); err == nil {
+			break
+		}
+	}
+	if track == nil {
+		if err == nil {
+			err = ErrNoMatches
+		}
+		return fmt.Errorf("failed to load %s: %w", source.Title, err)
+	}
+
+	p.mu.Lock()
+	volume := p.volume
+	p.mu.Unlock()
+
+	paused := false
+	encoded := track.Encoded
+	if err := p.node.updatePlayer(ctx, p.guildID, playerUpdate{
+		Track:  &trackUpdate{Encoded: &encoded},
+		Paused: &paused,
+		Volume: &volume,
+	}); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.source, p.track, p.failure = &source, track, nil
+	p.ended = make(chan End, 1)
+	p.paused = false
+	p.position, p.positionAt = 0, time.Now()
+	return nil
+}
+
+// Ended returns a channel that receives how the track started by the last Play ended
+func (p *Player) Ended() <-chan End {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.ended
+}
+
+// Pause holds playback on the node
+func (p *Player) Pause() error {
+	return p.setPaused(true)
+}
+
+// Resume continues paused playback
+func (p *Player) Resume() error {
+	return p.setPaused(false)
+}
+
+func (p *Player) setPaused(paused bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := p.node.updatePlayer(ctx, p.guildID, playerUpdate{Paused: &paused}); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if paused != p.paused {
+		p.position, p.positionAt = p.currentPosition(), time.Now()
+		p.paused = paused
+	}
+	return nil
+}
+
+// Stop ends the current track
+func (p *Player) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := p.node.updatePlayer(ctx, p.guildID, playerUpdate{Track: &trackUpdate{}}); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.source, p.track, p.paused = nil, nil, false
+	return nil
+}
+
+// SetVolume changes the volume, 100 plays tracks unchanged
+func (p *Player) SetVolume(volume int) error {
+	volume = max(0, min(volume, maxVolume))
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := p.node.updatePlayer(ctx, p.guildID, playerUpdate{Volume: &volume}); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.volume = volume
+	return nil
+}
+
+// GetVolume returns the volume
+func (p *Player) GetVolume() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.volume
+}
+
+// IsPlaying reports whether a track is loaded, also while paused
+func (p *Player) IsPlaying() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.source != nil
+}
+
+// IsPaused reports whether playback is paused
+func (p *Player) IsPaused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// GetCurrentSource returns the playing track, nil when nothing plays
+func (p *Player) GetCurrentSource() *types.AudioSource {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.source == nil {
+		return nil
+	}
+	source := *p.source
+	return &source
+}
+
+// Position returns how far into the current track playback is, estimated between the node's reports
+func (p *Player) Position() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.currentPosition()
+}
+
+// currentPosition estimates the position (caller holds the lock)
+func (p *Player) currentPosition() time.Duration {
+	if p.source == nil {
+		return 0
+	}
+	if p.paused || p.positionAt.IsZero() {
+		return p.position
+	}
+	return p.position + time.Since(p.positionAt)
+}
+
+// ChannelID returns the voice channel the bot was last seen in
+func (p *Player) ChannelID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.channelID
+}
+
+// SetChannel records the voice channel the bot was asked to join, before Discord confirms it
+func (p *Player) SetChannel(channelID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.channelID = channelID
+}
+
+// Connected reports whether the node is connected to the guild's voice server
+func (p *Player) Connected() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.connected
+}
+
+// SetVoiceSession records the bot's voice session from its own voice state update
+func (p *Player) SetVoiceSession(sessionID, channelID string) {
+	p.mu.Lock()
+	p.voice.SessionID = sessionID
+	if channelID != "" {
+		p.channelID = channelID
+	}
+	p.mu.Unlock()
+	p.sendVoice()
+}
+
+// SetVoiceServer records the voice server Discord assigned to the guild
+func (p *Player) SetVoiceServer(token, endpoint string) {
+	p.mu.Lock()
+	p.voice.Token, p.voice.Endpoint = token, endpoint
+	p.mu.Unlock()
+	p.sendVoice()
+}
+
+// sendVoice hands the voice session to the node once the session and the server are both known
+func (p *Player) sendVoice() {
+	p.mu.Lock()
+	voice := p.voice
+	p.mu.Unlock()
+	if !voice.complete() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := p.node.updatePlayer(ctx, p.guildID, playerUpdate{Voice: &voice}); err != nil {
+		utils.LogWarn("Failed to send the voice session of guild %s to Lavalink: %v", p.guildID, err)
+	}
+}
+
+// Destroy removes the player from the node, which leaves the voice server
+func (p *Player) Destroy(ctx context.Context) error {
+	p.node.forget(p.guildID)
+
+	p.mu.Lock()
+	p.source, p.track = nil, nil
+	p.mu.Unlock()
+
+	err := p.node.destroyPlayer(ctx, p.guildID)
+	if errors.Is(err, ErrNotConnected) {
+		return nil // A node without a session holds no players
+	}
+	return err
+}
+
+// restore recreates the player on a new node session with its voice session and current track
+func (p *Player) restore() {
+	p.mu.Lock()
+	voice := p.voice
+	update := playerUpdate{Voice: &voice}
+	if p.track != nil {
+		encoded := p.track.Encoded
+		position := p.currentPosition().Milliseconds()
+		paused, volume := p.paused, p.volume
+		update.Track = &trackUpdate{Encoded: &encoded}
+		update.Position, update.Paused, update.Volume = &position, &paused, &volume
+	}
+	p.mu.Unlock()
+	if !voice.complete() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := p.node.updatePlayer(ctx, p.guildID, update); err != nil {
+		utils.LogWarn("Failed to restore the Lavalink player of guild %s: %v", p.guildID, err)
+	}
+}
+
+// updateState records a position report from the node
+func (p *Player) updateState(positionMs int64, connected bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.position, p.positionAt = time.Duration(positionMs)*time.Millisecond, time.Now()
+	p.connected = connected
+}
+
+// handleEvent handles a player event for the guild
+func (p *Player) handleEvent(msg message) {
+	switch msg.Type {
+	case "TrackExceptionEvent":
+		if msg.Exception != nil && p.isCurrent(msg.Track) {
+			p.mu.Lock()
+			p.failure = msg.Exception
+			p.mu.Unlock()
+		}
+	case "TrackStuckEvent":
+		if p.isCurrent(msg.Track) {
+			p.finish(End{Reason: ReasonStuck, Err: errors.New("the track stopped sending audio")})
+		}
+	case "TrackEndEvent":
+		// A track replaced by Play already handed over to the next one
+		if msg.Reason != ReasonReplaced && p.isCurrent(msg.Track) {
+			p.finish(End{Reason: msg.Reason})
+		}
+	case "WebSocketClosedEvent":
+		utils.LogWarn("Lavalink voice connection of guild %s closed: %d %s", p.guildID, msg.Code, msg.Reason)
+	}
+}
+
+// isCurrent reports whether an event is about the playing track
+func (p *Player) isCurrent(track *Track) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.track != nil && (track == nil || track.Encoded == p.track.Encoded)
+}
+
+// finish reports the end of the current track to whoever waits on Ended
+func (p *Player) finish(end End) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if end.Failed() && end.Err == nil {
+		end.Err = p.failure
+	}
+	if end.Failed() && end.Err == nil {
+		end.Err = errors.New("the track failed to play")
+	}
+	p.source, p.track, p.paused = nil, nil, false
+	select {
+	case p.ended <- end:
+	default:
+	}
+}
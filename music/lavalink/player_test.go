@@ -0,0 +1,131 @@
+package lavalink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+func TestPlayerPlaysAndEnds(t *testing.T) {
+	f := newFakeNode(t)
+	node := connectNode(t, f)
+	f.addTrack("https://youtu.be/one", "encoded-one", "Song One")
+	player := node.Player("guild-1")
+
+	source := types.AudioSource{Title: "Song One", URL: "https://youtu.be/one"}
+	require.NoError(t, player.Play(context.Background(), source))
+	update := f.lastUpdate()
+	assert.Equal(t, "PATCH", update["method"])
+	assert.Equal(t, map[string]any{"encoded": "encoded-one"}, update["track"])
+	assert.Equal(t, false, update["paused"])
+	assert.True(t, player.IsPlaying())
+	assert.Equal(t, "Song One", player.GetCurrentSource().Title)
+
+	// Events for other tracks are ignored
+	f.send(map[string]any{"op": "event", "type": "TrackEndEvent", "guildId": "guild-1", "track": map[string]any{"encoded": "other"}, "reason": "finished"})
+	f.send(map[string]any{"op": "playerUpdate", "guildId": "guild-1", "state": map[string]any{"position": 30000, "connected": true}})
+	require.Eventually(t, player.Connected, time.Second, time.Millisecond)
+	assert.GreaterOrEqual(t, player.Position(), 30*time.Second)
+
+	f.send(map[string]any{"op": "event", "type": "TrackEndEvent", "guildId": "guild-1", "track": map[string]any{"encoded": "encoded-one"}, "reason": "finished"})
+	select {
+	case end := <-player.Ended():
+		assert.Equal(t, ReasonFinished, end.Reason)
+		assert.False(t, end.Failed())
+	case <-time.After(time.Second):
+		t.Fatal("the track end was never reported")
+	}
+	assert.False(t, player.IsPlaying())
+}
+
+func TestPlayerReportsFailures(t *testing.T) {
+	f := newFakeNode(t)
+	node := connectNode(t, f)
+	f.addTrack("https://stream.example/live", "encoded-live", "Live")
+	player := node.Player("guild-1")
+
+	// The stream URL is tried when the page link loads nothing
+	source := types.AudioSource{Title: "Live", URL: "https://example.com/page", StreamURL: "https://stream.example/live"}
+	require.NoError(t, player.Play(context.Background(), source))
+
+	track := map[string]any{"encoded": "encoded-live"}
+	f.send(map[string]any{"op": "event", "type": "TrackExceptionEvent", "guildId": "guild-1", "track": track,
+		"exception": map[string]any{"message": "403 Forbidden", "severity": "suspicious"}})
+	f.send(map[string]any{"op": "event", "type": "TrackEndEvent", "guildId": "guild-1", "track": track, "reason": "loadFailed"})
+
+	select {
+	case end := <-player.Ended():
+		assert.True(t, end.Failed())
+		assert.ErrorContains(t, end.Err, "403 Forbidden")
+	case <-time.After(time.Second):
+		t.Fatal("the track end was never reported")
+	}
+
+	err := player.Play(context.Background(), types.AudioSource{Title: "Nothing", URL: "https://example.com/missing"})
+	assert.ErrorIs(t, err, ErrNoMatches)
+}
+
+func TestPlayerControls(t *testing.T) {
+	f := newFakeNode(t)
+	node := connectNode(t, f)
+	player := node.Player("guild-1")
+
+	require.NoError(t, player.Pause())
+	assert.Equal(t, true, f.lastUpdate()["paused"])
+	assert.True(t, player.IsPaused())
+
+	require.NoError(t, player.Resume())
+	assert.Equal(t, false, f.lastUpdate()["paused"])
+
+	require.NoError(t, player.SetVolume(2000))
+	assert.Equal(t, float64(maxVolume), f.lastUpdate()["volume"])
+	assert.Equal(t, maxVolume, player.GetVolume())
+
+	require.NoError(t, player.Stop())
+	assert.Equal(t, map[string]any{"encoded": nil}, f.lastUpdate()["track"], "stopping sends a null track")
+
+	require.NoError(t, player.Destroy(context.Background()))
+	assert.Equal(t, "DELETE", f.lastUpdate()["method"])
+}
+
+func TestPlayerForwardsVoiceOnceComplete(t *testing.T) {
+	f := newFakeNode(t)
+	node := connectNode(t, f)
+	player := node.Player("guild-1")
+
+	player.SetVoiceSession("voice-session", "channel-1")
+	assert.Zero(t, f.updateCount(), "the voice server is still missing")
+	assert.Equal(t, "channel-1", player.ChannelID())
+
+	player.SetVoiceServer("token", "us-east1.discord.media")
+	assert.Equal(t, map[string]any{
+		"token":     "token",
+		"endpoint":  "us-east1.discord.media",
+		"sessionId": "voice-session",
+	}, f.lastUpdate()["voice"])
+}
+
+func TestPlayerIsRestoredOnNewSession(t *testing.T) {
+	f := newFakeNode(t)
+	node := connectNode(t, f)
+	f.addTrack("https://youtu.be/one", "encoded-one", "Song One")
+	player := node.Player("guild-1")
+	player.SetVoiceSession("voice-session", "channel-1")
+	player.SetVoiceServer("token", "endpoint")
+	require.NoError(t, player.Play(context.Background(), types.AudioSource{Title: "Song One", URL: "https://youtu.be/one"}))
+	count := f.updateCount()
+
+	// The node restarted and gave out a new session
+	f.send(map[string]any{"op": "ready", "resumed": false, "sessionId": "session-1"})
+	require.Eventually(t, func() bool { return f.updateCount() > count }, time.Second, time.Millisecond)
+
+	update := f.lastUpdate()
+	assert.Equal(t, map[string]any{"encoded": "encoded-one"}, update["track"])
+	assert.NotNil(t, update["voice"])
+	assert.Contains(t, update, "position")
+}
@@ -0,0 +1,47 @@
+package playlist
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultLimit is the number of playlist entries enqueued when no limit is given
+const DefaultLimit = 100
+
+// MaxLimit caps how many entries a single playlist import may enqueue
+const MaxLimit = 500
+
+// IsPlaylistURL reports whether a query is a YouTube playlist URL rather than a single video or search
+func IsPlaylistURL(query string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(query))
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	host = strings.TrimPrefix(host, "music.")
+	if host != "youtube.com" && host != "youtu.be" {
+		return false
+	}
+
+	if parsed.Path == "/playlist" {
+		return true
+	}
+
+	// Watch URLs with a list parameter are treated as playlists, except auto-generated mixes
+	// which are endless and personalised
+	list := parsed.Query().Get("list")
+	return list != "" && !strings.HasPrefix(list, "RD")
+}
+
+// ClampLimit returns a usable entry limit for a requested value
+func ClampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
@@ -0,0 +1,36 @@
+package playlist
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPlaylistURL(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"https://www.youtube.com/playlist?list=PL1234567890", true},
+		{"https://youtube.com/watch?v=abc123&list=PL1234567890", true},
+		{"https://music.youtube.com/playlist?list=OLAK5uy_abc", true},
+		{"https://www.youtube.com/watch?v=abc123&list=RDabc123", false}, // Mix
+		{"https://www.youtube.com/watch?v=abc123", false},
+		{"https://youtu.be/abc123", false},
+		{"https://example.com/playlist?list=PL1234567890", false},
+		{"lofi hip hop playlist", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsPlaylistURL(tt.query))
+		})
+	}
+}
+
+func TestClampLimit(t *testing.T) {
+	assert.Equal(t, DefaultLimit, ClampLimit(0))
+	assert.Equal(t, DefaultLimit, ClampLimit(-5))
+	assert.Equal(t, 25, ClampLimit(25))
+	assert.Equal(t, MaxLimit, ClampLimit(MaxLimit+1))
+}
@@ -0,0 +1,129 @@
+package prefetch
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"pxnx-discord-bot/utils"
+)
+
+// errPreparationAborted marks a preparation that panicked or never ran before producing a result
+var errPreparationAborted = errors.New("preparation aborted")
+
+// Pool runs the preparations of every buffer, so warming up next tracks in many guilds at once is bounded
+var Pool = utils.NewWorkerPool("prefetch", 8, 64)
+
+// Buffer prepares one item ahead of time, such as a resolved and started encoder for the next track.
+// Only the most recently requested key is kept; anything else is released.
+type Buffer[T any] struct {
+	release func(T) // Frees a prepared item that will not be used
+
+	mu      sync.Mutex
+	pending *slot[T]
+}
+
+// slot is a single preparation in flight or finished
+type slot[T any] struct {
+	key    string
+	cancel context.CancelFunc
+	done   chan struct{}
+	item   T
+	err    error
+}
+
+// New creates a buffer that calls release on prepared items that are discarded
+func New[T any](release func(T)) *Buffer[T] {
+	return &Buffer[T]{release: release}
+}
+
+// Start prepares the item for key in the background, replacing any preparation for a different key.
+// Calling Start again for the key already being prepared is a no-op.
+func (b *Buffer[T]) Start(key string, prepare func(ctx context.Context) (T, error)) {
+	b.mu.Lock()
+	if b.pending != nil && b.pending.key == key {
+		b.mu.Unlock()
+		return
+	}
+	previous := b.pending
+
+	ctx, cancel := context.WithCancel(context.Background())
+	current := &slot[T]{key: key, cancel: cancel, done: make(chan struct{})}
+	b.pending = current
+	b.mu.Unlock()
+
+	b.discard(previous)
+
+	err := Pool.Submit(func() {
+		defer close(current.done)
+		current.err = errPreparationAborted
+		current.item, current.err = prepare(ctx)
+	})
+	if err != nil {
+		// The track is prepared when it starts instead
+		utils.LogDebug("Not prefetching %s: %v", key, err)
+		current.err = errPreparationAborted
+		close(current.done)
+	}
+}
+
+// Take returns the prepared item for key, waiting for an in-flight preparation to finish.
+// It reports false when nothing was prepared for key or the preparation failed; a buffered item
+// for another key is released.
+func (b *Buffer[T]) Take(key string) (T, bool) {
+	b.mu.Lock()
+	current := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	var zero T
+	if current == nil {
+		return zero, false
+	}
+	if current.key != key {
+		b.discard(current)
+		return zero, false
+	}
+
+	<-current.done
+	if current.err != nil {
+		return zero, false
+	}
+	return current.item, true
+}
+
+// Pending returns the key being prepared, if any
+func (b *Buffer[T]) Pending() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending == nil {
+		return "", false
+	}
+	return b.pending.key, true
+}
+
+// Discard cancels any preparation and releases a prepared item
+func (b *Buffer[T]) Discard() {
+	b.mu.Lock()
+	current := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	b.discard(current)
+}
+
+// discard cancels a slot and releases its item once preparation finishes, without blocking the caller
+func (b *Buffer[T]) discard(s *slot[T]) {
+	if s == nil {
+		return
+	}
+	s.cancel()
+
+	go func() {
+		<-s.done
+		if s.err == nil && b.release != nil {
+			b.release(s.item)
+		}
+	}()
+}
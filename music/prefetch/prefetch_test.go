@@ -0,0 +1,160 @@
+package prefetch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// releaseRecorder collects released items so tests can wait for asynchronous releases
+type releaseRecorder struct {
+	mu       sync.Mutex
+	released []string
+}
+
+func (r *releaseRecorder) release(item string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.released = append(r.released, item)
+}
+
+func (r *releaseRecorder) items() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.released...)
+}
+
+func prepareValue(value string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) { return value, nil }
+}
+
+func TestBufferTakeMatchingKey(t *testing.T) {
+	recorder := &releaseRecorder{}
+	buffer := New(recorder.release)
+
+	buffer.Start("song1", prepareValue("encoder1"))
+	key, ok := buffer.Pending()
+	assert.True(t, ok)
+	assert.Equal(t, "song1", key)
+
+	item, ok := buffer.Take("song1")
+	assert.True(t, ok)
+	assert.Equal(t, "encoder1", item)
+
+	_, ok = buffer.Pending()
+	assert.False(t, ok, "a taken item leaves the buffer empty")
+	assert.Empty(t, recorder.items())
+}
+
+func TestBufferTakeWaitsForPreparation(t *testing.T) {
+	buffer := New[string](nil)
+	release := make(chan struct{})
+
+	buffer.Start("song1", func(ctx context.Context) (string, error) {
+		<-release
+		return "encoder1", nil
+	})
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	item, ok := buffer.Take("song1")
+	assert.True(t, ok)
+	assert.Equal(t, "encoder1", item)
+}
+
+func TestBufferTakeMismatchReleases(t *testing.T) {
+	recorder := &releaseRecorder{}
+	buffer := New(recorder.release)
+
+	buffer.Start("song1", prepareValue("encoder1"))
+	_, ok := buffer.Take("song2")
+	assert.False(t, ok)
+
+	assert.Eventually(t, func() bool { return len(recorder.items()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"encoder1"}, recorder.items())
+}
+
+func TestBufferStartReplacesOtherKey(t *testing.T) {
+	recorder := &releaseRecorder{}
+	buffer := New(recorder.release)
+
+	canceled := make(chan struct{})
+	buffer.Start("song1", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		close(canceled)
+		return "", ctx.Err()
+	})
+	buffer.Start("song2", prepareValue("encoder2"))
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("replaced preparation was not canceled")
+	}
+
+	item, ok := buffer.Take("song2")
+	assert.True(t, ok)
+	assert.Equal(t, "encoder2", item)
+	assert.Empty(t, recorder.items(), "failed preparations have nothing to release")
+}
+
+func TestBufferStartSameKeyIsNoop(t *testing.T) {
+	buffer := New[string](nil)
+	calls := 0
+	var mu sync.Mutex
+	prepare := func(ctx context.Context) (string, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return "encoder1", nil
+	}
+
+	buffer.Start("song1", prepare)
+	buffer.Start("song1", prepare)
+	_, ok := buffer.Take("song1")
+	assert.True(t, ok)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, calls)
+}
+
+func TestBufferTakeFailedPreparation(t *testing.T) {
+	buffer := New[string](nil)
+	buffer.Start("song1", func(ctx context.Context) (string, error) {
+		return "", errors.New("resolve failed")
+	})
+
+	_, ok := buffer.Take("song1")
+	assert.False(t, ok)
+}
+
+func TestBufferTakePanickedPreparation(t *testing.T) {
+	buffer := New[string](nil)
+	buffer.Start("song1", func(ctx context.Context) (string, error) {
+		panic("boom")
+	})
+
+	_, ok := buffer.Take("song1")
+	assert.False(t, ok)
+}
+
+func TestBufferDiscard(t *testing.T) {
+	recorder := &releaseRecorder{}
+	buffer := New(recorder.release)
+
+	buffer.Discard()
+	buffer.Start("song1", prepareValue("encoder1"))
+	buffer.Discard()
+
+	assert.Eventually(t, func() bool { return len(recorder.items()) == 1 }, time.Second, time.Millisecond)
+	_, ok := buffer.Take("song1")
+	assert.False(t, ok)
+}
@@ -0,0 +1,290 @@
+package premium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultPath is where manual premium grants are saved when PREMIUM_FILE is not set
+const DefaultPath = "data/premium.json"
+
+// Tier is the service level of a guild
+type Tier string
+
+const (
+	Free    Tier = "free"
+	Premium Tier = "premium"
+)
+
+// Feature is something only some tiers may use
+type Feature string
+
+const (
+	FeatureFilters       Feature = "audio filters"
+	FeatureStayConnected Feature = "24/7 mode"
+)
+
+// Limits are what a tier allows a guild
+type Limits struct {
+	MaxQueue      int  // Longest queue, 0 for no limit
+	Bitrate       int  // Opus bitrate in kbps
+	Filters       bool // Audio filter presets
+	StayConnected bool // 24/7 mode
+}
+
+// Default queue lengths of the tiers
+const (
+	DefaultFreeMaxQueue    = 100
+	DefaultPremiumMaxQueue = 1000
+)
+
+var (
+	tierLimitsMu sync.RWMutex
+	tierLimits   = map[Tier]Limits{
+		Free:    {MaxQueue: DefaultFreeMaxQueue, Bitrate: 96, Filters: false, StayConnected: false},
+		Premium: {MaxQueue: DefaultPremiumMaxQueue, Bitrate: 128, Filters: true, StayConnected: true},
+	}
+)
+
+// LimitsFor returns the limits of a tier; unknown tiers get the free limits
+func LimitsFor(tier Tier) Limits {
+	tierLimitsMu.RLock()
+	defer tierLimitsMu.RUnlock()
+	if limits, exists := tierLimits[tier]; exists {
+		return limits
+	}
+	return tierLimits[Free]
+}
+
+// SetMaxQueue changes the longest queue a tier allows, 0 for no limit. Queues already longer keep
+// their tracks but take no more.
+func SetMaxQueue(tier Tier, maxQueue int) {
+	tierLimitsMu.Lock()
+	defer tierLimitsMu.Unlock()
+	if limits, exists := tierLimits[tier]; exists {
+		limits.MaxQueue = maxQueue
+		tierLimits[tier] = limits
+	}
+}
+
+// Allows reports whether the limits include a feature
+func (l Limits) Allows(feature Feature) bool {
+	switch feature {
+	case FeatureFilters:
+		return l.Filters
+	case FeatureStayConnected:
+		return l.StayConnected
+	default:
+		return true
+	}
+}
+
+// RequiredError is returned when a guild uses a feature its tier doesn't include
+type RequiredError struct {
+	Feature Feature
+}
+
+func (e *RequiredError) Error() string {
+	return fmt.Sprintf("%s needs premium", e.Feature)
+}
+
+// Source says where a guild's premium comes from
+type Source string
+
+const (
+	SourceNone         Source = ""
+	SourceGrant        Source = "grant"        // Given by a bot owner with /admin premium
+	SourceSubscription Source = "subscription" // A Discord SKU subscription
+)
+
+// Status describes a guild's tier and where it comes from
+type Status struct {
+	Tier    Tier
+	Source  Source
+	Expires time.Time // Zero when premium doesn't expire or the guild is free
+}
+
+// Grant is premium given to a guild by hand
+type Grant struct {
+	Expires time.Time `json:"expires,omitempty"` // Zero for no end
+	Note    string    `json:"note,omitempty"`
+}
+
+// Entitlements decides each guild's tier from manual grants, saved to a JSON file, and Discord SKU
+// subscriptions, which Discord reports with every interaction and in entitlement events.
+// When gating is disabled every guild is premium, so self-hosted bots keep every feature.
+type Entitlements struct {
+	enabled bool
+	skuID   string
+	path    string
+	now     func() time.Time
+
+	mu            sync.RWMutex
+	grants        map[string]Grant
+	subscriptions map[string]time.Time // Guild subscriptions by guild ID, zero end for none
+}
+
+// New creates entitlements with gating disabled: every guild gets the premium limits
+func New() *Entitlements {
+	return &Entitlements{
+		now:           time.Now,
+		grants:        make(map[string]Grant),
+		subscriptions: make(map[string]time.Time),
+	}
+}
+
+// Load enables gating with grants saved at path and subscriptions to skuID counting as premium.
+// A missing file starts without grants; an empty skuID ignores subscriptions.
+func Load(path, skuID string) (*Entitlements, error) {
+	e := New()
+	e.enabled = true
+	e.path = path
+	e.skuID = skuID
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return e, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read premium grants: %w", err)
+	}
+	if err := json.Unmarshal(data, &e.grants); err != nil {
+		return nil, fmt.Errorf("failed to parse premium grants %s: %w", path, err)
+	}
+	if e.grants == nil {
+		e.grants = make(map[string]Grant)
+	}
+	return e, nil
+}
+
+// Enabled reports whether tiers are enforced
+func (e *Entitlements) Enabled() bool {
+	return e.enabled
+}
+
+// SKUID returns the SKU whose guild subscriptions count as premium
+func (e *Entitlements) SKUID() string {
+	return e.skuID
+}
+
+// Status returns a guild's tier and where it comes from. A grant wins over a subscription when both apply.
+func (e *Entitlements) Status(guildID string) Status {
+	if !e.enabled {
+		return Status{Tier: Premium}
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	now := e.now()
+	if grant, exists := e.grants[guildID]; exists && (grant.Expires.IsZero() || now.Before(grant.Expires)) {
+		return Status{Tier: Premium, Source: SourceGrant, Expires: grant.Expires}
+	}
+	if ends, exists := e.subscriptions[guildID]; exists && (ends.IsZero() || now.Before(ends)) {
+		return Status{Tier: Premium, Source: SourceSubscription, Expires: ends}
+	}
+	return Status{Tier: Free}
+}
+
+// Limits returns what a guild's tier allows
+func (e *Entitlements) Limits(guildID string) Limits {
+	return LimitsFor(e.Status(guildID).Tier)
+}
+
+// Grant gives a guild premium until expires, or for good when expires is zero, and saves it
+func (e *Entitlements) Grant(guildID string, expires time.Time, note string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.grants[guildID] = Grant{Expires: expires, Note: note}
+	return e.save()
+}
+
+// Revoke removes a guild's grant and saves the change; subscriptions are left alone
+func (e *Entitlements) Revoke(guildID string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.grants[guildID]; !exists {
+		return nil
+	}
+	delete(e.grants, guildID)
+	return e.save()
+}
+
+// Grants returns a copy of the manual grants, expired ones included
+func (e *Entitlements) Grants() map[string]Grant {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	grants := make(map[string]Grant, len(e.grants))
+	for guildID, grant := range e.grants {
+		grants[guildID] = grant
+	}
+	return grants
+}
+
+// ImportGrants sets or, for nil entries, revokes the grants of the given guilds and saves them once
+func (e *Entitlements) ImportGrants(grants map[string]*Grant) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for guildID, grant := range grants {
+		if grant == nil {
+			delete(e.grants, guildID)
+		} else {
+			e.grants[guildID] = *grant
+		}
+	}
+	return e.save()
+}
+
+// ApplySubscription records a Discord entitlement. Only guild entitlements for the premium SKU count;
+// deleted ones end the guild's subscription.
+func (e *Entitlements) ApplySubscription(guildID, skuID string, endsAt *time.Time, deleted bool) {
+	if guildID == "" || e.skuID == "" || skuID != e.skuID {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if deleted {
+		delete(e.subscriptions, guildID)
+		return
+	}
+	var ends time.Time
+	if endsAt != nil {
+		ends = *endsAt
+	}
+	e.subscriptions[guildID] = ends
+}
+
+// Forget drops what is known about a guild's subscription; manual grants are kept
+func (e *Entitlements) Forget(guildID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.subscriptions, guildID)
+}
+
+// save persists the manual grants; subscriptions come from Discord and aren't saved (caller holds the lock)
+func (e *Entitlements) save() error {
+	if e.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(e.grants, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode premium grants: %w", err)
+	}
+	if err := utils.WriteFileAtomic(e.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save premium grants: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package premium
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisabledGivesEveryonePremium(t *testing.T) {
+	e := New()
+	assert.False(t, e.Enabled())
+	assert.Equal(t, Premium, e.Status("guild1").Tier)
+	assert.True(t, e.Limits("guild1").Allows(FeatureFilters))
+}
+
+func TestLimits(t *testing.T) {
+	free := LimitsFor(Free)
+	assert.False(t, free.Allows(FeatureFilters))
+	assert.False(t, free.Allows(FeatureStayConnected))
+	assert.True(t, free.Allows(Feature("anything else")))
+
+	premium := LimitsFor(Premium)
+	assert.True(t, premium.Allows(FeatureFilters))
+	assert.True(t, premium.Allows(FeatureStayConnected))
+	assert.Greater(t, premium.MaxQueue, free.MaxQueue)
+	assert.Greater(t, premium.Bitrate, free.Bitrate)
+
+	assert.Equal(t, free, LimitsFor(Tier("gold")))
+	assert.EqualError(t, &RequiredError{Feature: FeatureFilters}, "audio filters needs premium")
+}
+
+func TestSetMaxQueue(t *testing.T) {
+	defer SetMaxQueue(Free, DefaultFreeMaxQueue)
+
+	SetMaxQueue(Free, 20)
+	assert.Equal(t, 20, LimitsFor(Free).MaxQueue)
+	assert.Equal(t, 96, LimitsFor(Free).Bitrate, "other limits are kept")
+	assert.Equal(t, DefaultPremiumMaxQueue, LimitsFor(Premium).MaxQueue)
+}
+
+func TestGrantsAreSavedAndExpire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "premium.json")
+	e, err := Load(path, "")
+	require.NoError(t, err)
+	assert.True(t, e.Enabled())
+	assert.Equal(t, Free, e.Status("guild1").Tier)
+
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+
+	expires := now.Add(30 * 24 * time.Hour)
+	require.NoError(t, e.Grant("guild1", expires, "trial"))
+	require.NoError(t, e.Grant("guild2", time.Time{}, ""))
+	assert.Equal(t, Status{Tier: Premium, Source: SourceGrant, Expires: expires}, e.Status("guild1"))
+
+	reloaded, err := Load(path, "")
+	require.NoError(t, err)
+	reloaded.now = e.now
+	assert.Equal(t, Premium, reloaded.Status("guild1").Tier)
+	assert.Equal(t, Premium, reloaded.Status("guild2").Tier)
+
+	now = expires
+	assert.Equal(t, Free, reloaded.Status("guild1").Tier, "grants end at their expiry")
+	assert.Equal(t, Premium, reloaded.Status("guild2").Tier, "grants without expiry never end")
+
+	require.NoError(t, reloaded.Revoke("guild2"))
+	require.NoError(t, reloaded.Revoke("guild3"))
+	assert.Equal(t, Free, reloaded.Status("guild2").Tier)
+}
+
+func TestImportGrants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "premium.json")
+	e, err := Load(path, "")
+	require.NoError(t, err)
+	require.NoError(t, e.Grant("guild1", time.Time{}, "old"))
+	require.NoError(t, e.Grant("guild2", time.Time{}, ""))
+
+	expires := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, e.ImportGrants(map[string]*Grant{
+		"guild1": nil,
+		"guild3": {Expires: expires, Note: "migrated"},
+	}))
+
+	reloaded, err := Load(path, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Grant{
+		"guild2": {},
+		"guild3": {Expires: expires, Note: "migrated"},
+	}, reloaded.Grants())
+}
+
+func TestSubscriptions(t *testing.T) {
+	e, err := Load(filepath.Join(t.TempDir(), "premium.json"), "sku1")
+	require.NoError(t, err)
+
+	e.ApplySubscription("guild1", "other-sku", nil, false)
+	e.ApplySubscription("", "sku1", nil, false)
+	assert.Equal(t, Free, e.Status("guild1").Tier, "only guild subscriptions to the premium SKU count")
+
+	e.ApplySubscription("guild1", "sku1", nil, false)
+	assert.Equal(t, Status{Tier: Premium, Source: SourceSubscription}, e.Status("guild1"))
+
+	ended := time.Now().Add(-time.Hour)
+	e.ApplySubscription("guild2", "sku1", &ended, false)
+	assert.Equal(t, Free, e.Status("guild2").Tier)
+
+	e.ApplySubscription("guild1", "sku1", nil, true)
+	assert.Equal(t, Free, e.Status("guild1").Tier)
+
+	e.ApplySubscription("guild3", "sku1", nil, false)
+	e.Forget("guild3")
+	assert.Equal(t, Free, e.Status("guild3").Tier)
+}
+
+func TestLoadRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "premium.json")
+	e, err := Load(path, "")
+	require.NoError(t, err)
+	require.NoError(t, e.Grant("guild1", time.Time{}, ""))
+
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o644))
+	_, err = Load(path, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+// Package providers keeps the audio providers a player can resolve and search tracks with, in priority
+// order, and routes links to the first provider that supports them.
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"pxnx-discord-bot/music/types"
+)
+
+// ErrUnknownProvider is returned for provider names that were never registered
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// Registry holds providers by name in priority order. Registration order is the default priority.
+type Registry struct {
+	mu        sync.RWMutex
+	providers []types.AudioProvider
+}
+
+// NewRegistry creates a registry with the given providers, highest priority first
+func NewRegistry(providers ...types.AudioProvider) *Registry {
+	r := &Registry{}
+	for _, provider := range providers {
+		r.Register(provider)
+	}
+	return r
+}
+
+// Register adds a provider at the lowest priority, replacing a provider with the same name in its place
+func (r *Registry) Register(provider types.AudioProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for index, existing := range r.providers {
+		if existing.GetProviderName() == provider.GetProviderName() {
+			r.providers[index] = provider
+			return
+		}
+	}
+	r.providers = append(r.providers, provider)
+}
+
+// Get returns the provider with a name
+func (r *Registry) Get(name string) (types.AudioProvider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, provider := range r.providers {
+		if provider.GetProviderName() == name {
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+}
+
+// Names returns the provider names, highest priority first
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.providers))
+	for index, provider := range r.providers {
+		names[index] = provider.GetProviderName()
+	}
+	return names
+}
+
+// ForURL returns the highest priority provider that supports a link
+func (r *Registry) ForURL(rawURL string) (types.AudioProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, provider := range r.providers {
+		if provider.SupportsURL(rawURL) {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+// SetOrder moves the named providers to the front in the given order; the rest keep their order after them
+func (r *Registry) SetOrder(names []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ordered := make([]types.AudioProvider, 0, len(r.providers))
+	placed := make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if placed[name] {
+			continue
+		}
+		found := false
+		for _, provider := range r.providers {
+			if provider.GetProviderName() == name {
+				ordered = append(ordered, provider)
+				placed[name], found = true, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+		}
+	}
+	for _, provider := range r.providers {
+		if !placed[provider.GetProviderName()] {
+			ordered = append(ordered, provider)
+		}
+	}
+	r.providers = ordered
+	return nil
+}
+
+// ParseOrder splits a comma separated list of provider names, such as "twitch,direct,youtube"
+func ParseOrder(raw string) []string {
+	var names []string
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+// fakeProvider supports links that start with its prefix
+type fakeProvider struct {
+	name         string
+	prefix       string
+	capabilities types.ProviderCapabilities
+}
+
+func (f *fakeProvider) GetAudioSource(ctx context.Context, query string) (*types.AudioSource, error) {
+	return &types.AudioSource{Title: query, Provider: f.name}, nil
+}
+
+func (f *fakeProvider) Search(ctx context.Context, query string, maxResults int) ([]types.AudioSource, error) {
+	return nil, nil
+}
+
+func (f *fakeProvider) SupportsURL(url string) bool {
+	return strings.HasPrefix(url, f.prefix)
+}
+
+func (f *fakeProvider) GetProviderName() string {
+	return f.name
+}
+
+func (f *fakeProvider) Capabilities() types.ProviderCapabilities {
+	return f.capabilities
+}
+
+func TestRegistryRoutesByPriority(t *testing.T) {
+	registry := NewRegistry(
+		&fakeProvider{name: "twitch", prefix: "https://twitch.tv/"},
+		&fakeProvider{name: "youtube", prefix: "https://"},
+	)
+	assert.Equal(t, []string{"twitch", "youtube"}, registry.Names())
+
+	provider, ok := registry.ForURL("https://twitch.tv/streamer")
+	require.True(t, ok)
+	assert.Equal(t, "twitch", provider.GetProviderName())
+
+	provider, ok = registry.ForURL("https://example.com/song")
+	require.True(t, ok)
+	assert.Equal(t, "youtube", provider.GetProviderName())
+
+	_, ok = registry.ForURL("lofi beats")
+	assert.False(t, ok)
+
+	require.NoError(t, registry.SetOrder([]string{"YouTube"}))
+	assert.Equal(t, []string{"youtube", "twitch"}, registry.Names())
+	provider, _ = registry.ForURL("https://twitch.tv/streamer")
+	assert.Equal(t, "youtube", provider.GetProviderName(), "the first provider that supports a link wins")
+}
+
+func TestRegistryGetAndReplace(t *testing.T) {
+	registry := NewRegistry(&fakeProvider{name: "radio"}, &fakeProvider{name: "youtube"})
+
+	provider, err := registry.Get(" Radio ")
+	require.NoError(t, err)
+	assert.Equal(t, "radio", provider.GetProviderName())
+
+	_, err = registry.Get("spotify")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+
+	replacement := &fakeProvider{name: "radio", prefix: "http://"}
+	registry.Register(replacement)
+	assert.Equal(t, []string{"radio", "youtube"}, registry.Names(), "replacing keeps the priority")
+	provider, _ = registry.Get("radio")
+	assert.Same(t, replacement, provider)
+}
+
+func TestSetOrderRejectsUnknownProviders(t *testing.T) {
+	registry := NewRegistry(&fakeProvider{name: "radio"}, &fakeProvider{name: "youtube"})
+
+	assert.ErrorIs(t, registry.SetOrder([]string{"youtube", "spotify"}), ErrUnknownProvider)
+	assert.Equal(t, []string{"radio", "youtube"}, registry.Names(), "a rejected order changes nothing")
+}
+
+func TestParseOrder(t *testing.T) {
+	assert.Equal(t, []string{"twitch", "direct", "youtube"}, ParseOrder(" Twitch, direct,,youtube "))
+	assert.Empty(t, ParseOrder(""))
+}
@@ -0,0 +1,341 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"pxnx-discord-bot/utils"
+)
+
+// ErrQueueFull is returned when a guild already has as many extractions waiting as it may
+var ErrQueueFull = errors.New("too many songs are already waiting to be looked up for this server")
+
+// Limits bound how many extractions run at once and how many may wait
+type Limits struct {
+	Workers       int // Extractions running at once across all guilds
+	PerGuild      int // Extractions running at once for one guild
+	QueuePerGuild int // Extractions one guild may have waiting, 0 refuses any that can't start right away
+}
+
+// DefaultLimits run 4 extractions at once, at most 2 for one guild, with up to 10 more waiting per guild
+func DefaultLimits() Limits {
+	return Limits{Workers: 4, PerGuild: 2, QueuePerGuild: 10}
+}
+
+// ParseLimits reads limits like "workers=4,guild=2,queue=10". Limits left out keep their default.
+func ParseLimits(raw string) (Limits, error) {
+	limits := DefaultLimits()
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(pair, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || err != nil || n < 0 {
+			return Limits{}, fmt.Errorf("invalid limit %q, expected name=number", pair)
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "workers":
+			limits.Workers = n
+		case "guild":
+			limits.PerGuild = n
+		case "queue":
+			limits.QueuePerGuild = n
+		default:
+			return Limits{}, fmt.Errorf("unknown limit %q, expected workers, guild or queue", name)
+		}
+	}
+	if limits.Workers < 1 || limits.PerGuild < 1 {
+		return Limits{}, fmt.Errorf("workers and guild must be at least 1")
+	}
+	return limits, nil
+}
+
+// QueueListener is told a waiting extraction's position in the queue, 1 being next, whenever it changes
+type QueueListener func(position int)
+
+type queueListenerKey struct{}
+
+// WithQueueListener returns a context whose extractions report their queue position to listener while
+// they wait for a worker
+func WithQueueListener(ctx context.Context, listener QueueListener) context.Context {
+	return context.WithValue(ctx, queueListenerKey{}, listener)
+}
+
+// ticket is one extraction waiting for a worker
+type ticket struct {
+	guildID  string
+	granted  chan struct{} // Closed once the extraction may run
+	listener QueueListener
+	reported int // Position last reported to the listener, guarded by the scheduler's lock
+
+	deliverMu sync.Mutex // Keeps the listener's calls in order
+	delivered uint64     // Update the listener was last told
+}
+
+// deliver tells the listener a position unless a later one was already told or the extraction started
+func (t *ticket) deliver(position int, seq uint64) {
+	t.deliverMu.Lock()
+	defer t.deliverMu.Unlock()
+
+	select {
+	case <-t.granted:
+		return
+	default:
+	}
+	if seq <= t.delivered {
+		return
+	}
+	t.delivered = seq
+	t.listener(position)
+}
+
+// Scheduler shares extraction workers fairly between guilds. Each guild runs a limited number of
+// extractions at once, and waiting extractions are served one guild at a time in turn, so a guild
+// queueing many songs doesn't hold up the others.
+type Scheduler struct {
+	mu      sync.Mutex
+	limits  Limits
+	running int
+	active  map[string]int       // Running extractions by guild
+	waiting map[string][]*ticket // Waiting extractions by guild, oldest first
+	turns   []string             // Guilds with waiting extractions, next to be served first
+	updates uint64               // Queue position updates handed out, to deliver them in order
+}
+
+// NewScheduler creates a scheduler with the given limits
+func NewScheduler(limits Limits) *Scheduler {
+	return &Scheduler{
+		limits:  normalizeLimits(limits),
+		active:  make(map[string]int),
+		waiting: make(map[string][]*ticket),
+	}
+}
+
+// normalizeLimits makes sure at least one extraction can run
+func normalizeLimits(limits Limits) Limits {
+	limits.Workers = max(limits.Workers, 1)
+	limits.PerGuild = max(limits.PerGuild, 1)
+	limits.QueuePerGuild = max(limits.QueuePerGuild, 0)
+	return limits
+}
+
+// SetLimits changes the limits; waiting extractions start right away if the new limits allow
+func (s *Scheduler) SetLimits(limits Limits) {
+	s.mu.Lock()
+	s.limits = normalizeLimits(limits)
+	updates := s.dispatch()
+	s.mu.Unlock()
+	report(updates)
+}
+
+// Limits returns the current limits
+func (s *Scheduler) Limits() Limits {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limits
+}
+
+// Acquire waits until a guild may run an extraction and returns the function that ends it. The guild
+// comes from the request in ctx; extractions without one share a guild of their own. It fails with
+// ErrQueueFull when the guild has too many extractions waiting, or with ctx's error when ctx ends first.
+func (s *Scheduler) Acquire(ctx context.Context) (release func(), err error) {
+	request, _ := utils.RequestFrom(ctx)
+	guildID := request.GuildID
+	listener, _ := ctx.Value(queueListenerKey{}).(QueueListener)
+
+	s.mu.Lock()
+	if len(s.waiting[guildID]) == 0 && s.canRun(guildID) {
+		s.start(guildID)
+		s.mu.Unlock()
+		return s.releaser(guildID), nil
+	}
+	if len(s.waiting[guildID]) >= s.limits.QueuePerGuild {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w (%d)", ErrQueueFull, s.limits.QueuePerGuild)
+	}
+
+	t := &ticket{guildID: guildID, granted: make(chan struct{}), listener: listener}
+	if len(s.waiting[guildID]) == 0 {
+		s.turns = append(s.turns, guildID)
+	}
+	s.waiting[guildID] = append(s.waiting[guildID], t)
+	updates := s.positions()
+	s.mu.Unlock()
+	report(updates)
+
+	select {
+	case <-t.granted:
+		return s.releaser(guildID), nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	select {
+	case <-t.granted:
+		// Granted while ctx ended, hand the worker on
+		s.finish(guildID)
+	default:
+		s.remove(t)
+	}
+	updates = s.dispatch()
+	s.mu.Unlock()
+	report(updates)
+	return nil, ctx.Err()
+}
+
+// Do runs task once the guild in ctx may run an extraction
+func (s *Scheduler) Do(ctx context.Context, task func() error) error {
+	release, err := s.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return task()
+}
+
+// Stats returns how many extractions are running and waiting
+func (s *Scheduler) Stats() (running, waiting int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tickets := range s.waiting {
+		waiting += len(tickets)
+	}
+	return s.running, waiting
+}
+
+// releaser returns a function ending a running extraction once, then starting waiting ones
+func (s *Scheduler) releaser(guildID string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.finish(guildID)
+			updates := s.dispatch()
+			s.mu.Unlock()
+			report(updates)
+		})
+	}
+}
+
+// canRun reports whether a guild may start another extraction now (caller holds the lock)
+func (s *Scheduler) canRun(guildID string) bool {
+	return s.running < s.limits.Workers && s.active[guildID] < s.limits.PerGuild
+}
+
+// start counts a running extraction (caller holds the lock)
+func (s *Scheduler) start(guildID string) {
+	s.running++
+	s.active[guildID]++
+}
+
+// finish counts an ended extraction (caller holds the lock)
+func (s *Scheduler) finish(guildID string) {
+	s.running--
+	if s.active[guildID]--; s.active[guildID] <= 0 {
+		delete(s.active, guildID)
+	}
+}
+
+// dispatch starts waiting extractions while workers are free, taking guilds in turn and skipping guilds
+// at their own limit, and returns the queue positions that changed (caller holds the lock)
+func (s *Scheduler) dispatch() []positionUpdate {
+	for s.running < s.limits.Workers {
+		index := -1
+		for n, guildID := range s.turns {
+			if s.active[guildID] < s.limits.PerGuild {
+				index = n
+				break
+			}
+		}
+		if index < 0 {
+			break
+		}
+
+		guildID := s.turns[index]
+		t := s.waiting[guildID][0]
+		s.waiting[guildID] = s.waiting[guildID][1:]
+		s.turns = append(s.turns[:index], s.turns[index+1:]...)
+		if len(s.waiting[guildID]) > 0 {
+			// The guild goes to the back of the line for its next extraction
+			s.turns = append(s.turns, guildID)
+		} else {
+			delete(s.waiting, guildID)
+		}
+
+		s.start(guildID)
+		close(t.granted)
+	}
+	return s.positions()
+}
+
+// remove takes a waiting extraction out of the queue (caller holds the lock)
+func (s *Scheduler) remove(t *ticket) {
+	tickets := s.waiting[t.guildID]
+	for n, waiting := range tickets {
+		if waiting == t {
+			tickets = append(tickets[:n], tickets[n+1:]...)
+			break
+		}
+	}
+	if len(tickets) > 0 {
+		s.waiting[t.guildID] = tickets
+		return
+	}
+
+	delete(s.waiting, t.guildID)
+	for n, guildID := range s.turns {
+		if guildID == t.guildID {
+			s.turns = append(s.turns[:n], s.turns[n+1:]...)
+			break
+		}
+	}
+}
+
+// positionUpdate is a queue position to report to a waiting extraction's listener
+type positionUpdate struct {
+	ticket   *ticket
+	position int
+	seq      uint64
+}
+
+// positions works out where each waiting extraction is in line, serving guilds in turn, and returns the
+// positions that changed since they were last reported (caller holds the lock)
+func (s *Scheduler) positions() []positionUpdate {
+	var updates []positionUpdate
+	position := 0
+	for round := 0; ; round++ {
+		served := false
+		for _, guildID := range s.turns {
+			tickets := s.waiting[guildID]
+			if round >= len(tickets) {
+				continue
+			}
+			served = true
+			position++
+
+			t := tickets[round]
+			if t.listener != nil && t.reported != position {
+				t.reported = position
+				s.updates++
+				updates = append(updates, positionUpdate{ticket: t, position: position, seq: s.updates})
+			}
+		}
+		if !served {
+			return updates
+		}
+	}
+}
+
+// report tells listeners their new positions, outside the lock since listeners may edit messages
+func report(updates []positionUpdate) {
+	for _, update := range updates {
+		update.ticket.deliver(update.position, update.seq)
+	}
+}
@@ -0,0 +1,121 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/utils"
+)
+
+// guildCtx returns a context of a request from a guild
+func guildCtx(guildID string) context.Context {
+	return utils.WithRequest(context.Background(), utils.RequestInfo{GuildID: guildID})
+}
+
+// waitInLine starts acquiring for a guild in the background and waits until the request is queued. The
+// request's name is sent on granted once it may run, and its queue positions are recorded in positions.
+func waitInLine(t *testing.T, s *Scheduler, ctx context.Context, name string, granted chan<- string, positions *sync.Map) {
+	t.Helper()
+	_, before := s.Stats()
+	ctx = WithQueueListener(ctx, func(position int) { positions.Store(name, position) })
+	go func() {
+		release, err := s.Acquire(ctx)
+		if err != nil {
+			return
+		}
+		granted <- name
+		release()
+	}()
+	require.Eventually(t, func() bool {
+		_, waiting := s.Stats()
+		return waiting == before+1
+	}, time.Second, time.Millisecond)
+}
+
+func TestSchedulerServesGuildsInTurn(t *testing.T) {
+	s := NewScheduler(Limits{Workers: 1, PerGuild: 1, QueuePerGuild: 5})
+	release, err := s.Acquire(guildCtx("spammy"))
+	require.NoError(t, err)
+
+	granted := make(chan string, 3)
+	var positions sync.Map
+	waitInLine(t, s, guildCtx("spammy"), "spammy-2", granted, &positions)
+	waitInLine(t, s, guildCtx("spammy"), "spammy-3", granted, &positions)
+	waitInLine(t, s, guildCtx("quiet"), "quiet-1", granted, &positions)
+
+	// The quiet guild goes between the spammy guild's requests, not after all of them. Listeners are told
+	// after the queue changed, so give them a moment.
+	assert.Eventually(t, func() bool {
+		for name, want := range map[string]int{"spammy-2": 1, "quiet-1": 2, "spammy-3": 3} {
+			if position, _ := positions.Load(name); position != want {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+
+	release()
+	assert.Equal(t, "spammy-2", <-granted)
+	assert.Equal(t, "quiet-1", <-granted)
+	assert.Equal(t, "spammy-3", <-granted)
+
+	running, waiting := s.Stats()
+	assert.Zero(t, running)
+	assert.Zero(t, waiting)
+}
+
+func TestSchedulerLimitsEachGuild(t *testing.T) {
+	s := NewScheduler(Limits{Workers: 3, PerGuild: 2, QueuePerGuild: 1})
+
+	for range 2 {
+		_, err := s.Acquire(guildCtx("busy"))
+		require.NoError(t, err)
+	}
+
+	// Another guild still gets the free worker right away
+	release, err := s.Acquire(guildCtx("other"))
+	require.NoError(t, err)
+	release()
+
+	granted := make(chan string, 1)
+	var positions sync.Map
+	waitInLine(t, s, guildCtx("busy"), "busy-3", granted, &positions)
+
+	_, err = s.Acquire(guildCtx("busy"))
+	assert.ErrorIs(t, err, ErrQueueFull, "the guild's wait queue holds one request")
+}
+
+func TestSchedulerGivesUpWhenTheRequestEnds(t *testing.T) {
+	s := NewScheduler(Limits{Workers: 1, PerGuild: 1, QueuePerGuild: 5})
+	release, err := s.Acquire(guildCtx("guild"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(guildCtx("guild"), 20*time.Millisecond)
+	defer cancel()
+	_, err = s.Acquire(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, waiting := s.Stats()
+	assert.Zero(t, waiting)
+
+	release()
+	release() // Releasing twice frees the worker once
+	running, _ := s.Stats()
+	assert.Zero(t, running)
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(" workers=8 , queue=0")
+	require.NoError(t, err)
+	assert.Equal(t, Limits{Workers: 8, PerGuild: DefaultLimits().PerGuild, QueuePerGuild: 0}, limits)
+
+	for _, raw := range []string{"workers", "workers=-1", "guild=0", "threads=2"} {
+		_, err := ParseLimits(raw)
+		assert.Error(t, err, raw)
+	}
+}
@@ -2,17 +2,73 @@ package queue
 
 import (
 	"crypto/rand"
+	"encoding/binary"
+	"errors"
 	"fmt"
-	"math/big"
+	mathrand "math/rand/v2"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	"pxnx-discord-bot/music/types"
 )
 
-// SimpleQueue implements the Queue interface with thread-safe operations
+// journalSize is the number of queue mutations kept for undo
+const journalSize = 10
+
+// Queue operations recorded in the journal
+const (
+	OperationClear     = "clear"
+	OperationRemove    = "remove"
+	OperationShuffle   = "shuffle"
+	OperationUnshuffle = "unshuffle"
+	OperationMove      = "move"
+	OperationSwap      = "swap"
+	OperationDedupe    = "dedupe"
+	OperationRemoveBy  = "remove-user"
+	OperationFair      = "fair"
+)
+
+// MaxSeed is the largest shuffle seed, kept within the range Discord integer options can carry
+const MaxSeed = 1<<53 - 1
+
+var (
+	// ErrNothingToUndo is returned by Undo when the journal is empty
+	ErrNothingToUndo = errors.New("nothing to undo")
+
+	// ErrNotShuffled is returned by Unshuffle when the queue is already in its original order
+	ErrNotShuffled = errors.New("queue is not shuffled")
+
+	// ErrCrossesPriority is returned by Move and Swap when an entry would leave its tier
+	ErrCrossesPriority = errors.New("priority requests stay ahead of normal requests")
+)
+
+// JournalEntry records how to undo a queue mutation: the tracks it removed and where they were, or the
+// order of the queue before it reordered it. Tracks added or played since are left alone by an undo.
+type JournalEntry struct {
+	Operation string
+	Items     []types.AudioSource // Tracks the operation removed, in queue order
+	Time      time.Time
+
+	seqs      []uint64 // Sequence numbers of Items
+	positions []int    // Positions of Items before they were removed
+	order     []uint64 // Sequence numbers in queue order before a reorder, nil for removals
+	shuffled  bool
+}
+
+// SimpleQueue implements the Queue interface with thread-safe operations.
+// Items form two tiers: priority entries are kept ahead of normal ones, each tier in request order,
+// or interleaved by requester in fair mode.
+// Each item carries its insertion sequence number so a shuffle can be reverted.
 type SimpleQueue struct {
-	items []types.AudioSource
-	mu    sync.RWMutex
+	items    []types.AudioSource
+	seqs     []uint64 // Insertion order of items, parallel to items
+	nextSeq  uint64
+	shuffled bool
+	fair     bool // New items are interleaved round-robin by requester
+	journal  []JournalEntry
+	mu       sync.RWMutex
 }
 
 // NewQueue creates a new empty queue
@@ -22,11 +78,130 @@ func NewQueue() *SimpleQueue {
 	}
 }
 
-// Add adds an audio source to the end of the queue
+// Add adds an audio source to the end of its tier: priority entries go behind other
+// priority entries but ahead of all normal ones. In fair mode it goes behind the tracks of
+// the same round instead (see SetFair).
 func (q *SimpleQueue) Add(source types.AudioSource) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.items = append(q.items, source)
+
+	position := len(q.items)
+	if source.Priority {
+		position = q.priorityCount()
+	}
+	if q.fair {
+		position = q.fairPosition(source)
+	}
+
+	q.items = slices.Insert(q.items, position, source)
+	q.seqs = slices.Insert(q.seqs, position, q.nextSeq)
+	q.nextSeq++
+}
+
+// SetFair turns fair mode on or off. In fair mode the queue plays requesters in turn: a track's round
+// is how many tracks its requester already has ahead of it in the tier, and tracks are ordered by
+// round, so one member's playlist can't hold up everyone else. Turning it on reorders the queued
+// tracks that way as one journaled operation; turning it off keeps the current order.
+func (q *SimpleQueue) SetFair(enabled bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if enabled == q.fair {
+		return
+	}
+	q.fair = enabled
+	if enabled {
+		q.rebalance()
+	}
+}
+
+// IsFair reports whether the queue interleaves tracks by requester
+func (q *SimpleQueue) IsFair() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.fair
+}
+
+// fairPosition finds where a new item goes in fair mode: behind every item of its tier whose round
+// is not later than its own (caller holds the lock)
+func (q *SimpleQueue) fairPosition(source types.AudioSource) int {
+	start, end := q.priorityCount(), len(q.items)
+	if source.Priority {
+		start, end = 0, start
+	}
+
+	rounds := tierRounds(q.items[start:end])
+	round := 0
+	for _, item := range q.items[start:end] {
+		if item.RequestedBy == source.RequestedBy {
+			round++
+		}
+	}
+
+	position := start
+	for index, itemRound := range rounds {
+		if itemRound <= round {
+			position = start + index + 1
+		}
+	}
+	return position
+}
+
+// rebalance orders each tier by round, keeping the current order within a round (caller holds the write lock)
+func (q *SimpleQueue) rebalance() {
+	priority := q.priorityCount()
+	var order []int
+	for _, tier := range [][2]int{{0, priority}, {priority, len(q.items)}} {
+		start, end := tier[0], tier[1]
+		rounds := tierRounds(q.items[start:end])
+		indexes := make([]int, end-start)
+		for index := range indexes {
+			indexes[index] = index
+		}
+		sort.SliceStable(indexes, func(i, j int) bool { return rounds[indexes[i]] < rounds[indexes[j]] })
+		for _, index := range indexes {
+			order = append(order, start+index)
+		}
+	}
+
+	if sort.IntsAreSorted(order) {
+		return
+	}
+	q.recordOrder(OperationFair)
+
+	items := make([]types.AudioSource, len(q.items))
+	seqs := make([]uint64, len(q.seqs))
+	for position, index := range order {
+		items[position], seqs[position] = q.items[index], q.seqs[index]
+	}
+	q.items, q.seqs = items, seqs
+}
+
+// tierRounds returns the round of each item: how many earlier items in the tier share its requester
+func tierRounds(items []types.AudioSource) []int {
+	counts := make(map[string]int)
+	rounds := make([]int, len(items))
+	for index, item := range items {
+		rounds[index] = counts[item.RequestedBy]
+		counts[item.RequestedBy]++
+	}
+	return rounds
+}
+
+// PriorityCount returns the number of priority entries at the front of the queue
+func (q *SimpleQueue) PriorityCount() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.priorityCount()
+}
+
+// priorityCount counts the priority tier (caller holds the lock)
+func (q *SimpleQueue) priorityCount() int {
+	count := 0
+	for count < len(q.items) && q.items[count].Priority {
+		count++
+	}
+	return count
 }
 
 // Remove removes an item at the specified position (0-indexed)
@@ -38,11 +213,133 @@ func (q *SimpleQueue) Remove(position int) error {
 		return fmt.Errorf("position %d out of range (queue size: %d)", position, len(q.items))
 	}
 
+	q.recordRemoval(OperationRemove, []int{position})
+
 	// Remove item at position
 	q.items = append(q.items[:position], q.items[position+1:]...)
+	q.seqs = append(q.seqs[:position], q.seqs[position+1:]...)
+	return nil
+}
+
+// Move moves the item at position from to position to (0-indexed), shifting the items in between
+func (q *SimpleQueue) Move(from, to int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.checkReorder(from, to); err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+
+	q.recordOrder(OperationMove)
+
+	item, seq := q.items[from], q.seqs[from]
+	q.items = slices.Insert(slices.Delete(q.items, from, from+1), to, item)
+	q.seqs = slices.Insert(slices.Delete(q.seqs, from, from+1), to, seq)
+	return nil
+}
+
+// Swap exchanges the items at positions a and b (0-indexed)
+func (q *SimpleQueue) Swap(a, b int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.checkReorder(a, b); err != nil {
+		return err
+	}
+	if a == b {
+		return nil
+	}
+
+	q.recordOrder(OperationSwap)
+
+	q.items[a], q.items[b] = q.items[b], q.items[a]
+	q.seqs[a], q.seqs[b] = q.seqs[b], q.seqs[a]
+	return nil
+}
+
+// checkReorder validates two positions for Move and Swap, which must stay within one tier (caller holds the lock)
+func (q *SimpleQueue) checkReorder(a, b int) error {
+	for _, position := range []int{a, b} {
+		if position < 0 || position >= len(q.items) {
+			return fmt.Errorf("position %d out of range (queue size: %d)", position, len(q.items))
+		}
+	}
+
+	priority := q.priorityCount()
+	if (a < priority) != (b < priority) {
+		return ErrCrossesPriority
+	}
 	return nil
 }
 
+// Deduplicate removes later copies of tracks that are already queued, keeping each track at its
+// earliest position, and returns how many were removed. Tracks are matched by URL.
+func (q *SimpleQueue) Deduplicate() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	seen := make(map[string]bool, len(q.items))
+	return q.removeWhere(OperationDedupe, func(item types.AudioSource) bool {
+		key := item.URL
+		if key == "" {
+			key = item.StreamURL
+		}
+		if key == "" {
+			return false
+		}
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		return false
+	})
+}
+
+// RemoveByRequester removes every track queued by the given user and returns how many were removed
+func (q *SimpleQueue) RemoveByRequester(userID string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if userID == "" {
+		return 0
+	}
+	return q.removeWhere(OperationRemoveBy, func(item types.AudioSource) bool {
+		return item.RequestedBy == userID
+	})
+}
+
+// removeWhere removes the items drop selects, visiting them in queue order, as one journaled operation
+// and returns how many were removed (caller holds the write lock)
+func (q *SimpleQueue) removeWhere(operation string, drop func(item types.AudioSource) bool) int {
+	dropped := make([]bool, len(q.items))
+	var removed []int
+	for index, item := range q.items {
+		if drop(item) {
+			dropped[index] = true
+			removed = append(removed, index)
+		}
+	}
+	if len(removed) == 0 {
+		return 0
+	}
+
+	q.recordRemoval(operation, removed)
+
+	kept := 0
+	for index := range q.items {
+		if !dropped[index] {
+			q.items[kept], q.seqs[kept] = q.items[index], q.seqs[index]
+			kept++
+		}
+	}
+	q.items = q.items[:kept]
+	q.seqs = q.seqs[:kept]
+	return len(removed)
+}
+
 // Get retrieves an item at the specified position without removing it
 func (q *SimpleQueue) Get(position int) (*types.AudioSource, error) {
 	q.mu.RLock()
@@ -70,26 +367,147 @@ func (q *SimpleQueue) GetAll() []types.AudioSource {
 func (q *SimpleQueue) Clear() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.items = q.items[:0] // Clear slice but keep capacity
+
+	q.clear()
 }
 
-// Shuffle randomly reorders all items in the queue using crypto/rand for security
-func (q *SimpleQueue) Shuffle() {
+// ClearIfUnchanged removes all items only when the queue still holds the expected tracks in the same
+// order, and reports whether it did, so a clear confirmed later can't take songs queued after its preview
+func (q *SimpleQueue) ClearIfUnchanged(expected []types.AudioSource) bool {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	// Fisher-Yates shuffle algorithm with crypto/rand
-	for i := len(q.items) - 1; i > 0; i-- {
-		// Generate cryptographically secure random number
-		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
-		if err != nil {
-			// Fallback to simple swap if crypto/rand fails
-			// This should rarely happen, but we need to handle it gracefully
-			continue
+	if len(q.items) != len(expected) {
+		return false
+	}
+	for index, item := range q.items {
+		if !sameTrack(item, expected[index]) {
+			return false
 		}
-		j := int(n.Int64())
-		q.items[i], q.items[j] = q.items[j], q.items[i]
 	}
+	q.clear()
+	return true
+}
+
+// sameTrack reports whether two queue entries are the same request of the same track
+func sameTrack(a, b types.AudioSource) bool {
+	return a.Title == b.Title && a.URL == b.URL && a.StreamURL == b.StreamURL &&
+		a.RequestedBy == b.RequestedBy && a.Priority == b.Priority
+}
+
+// clear empties the queue as one journaled operation (caller holds the write lock)
+func (q *SimpleQueue) clear() {
+	if len(q.items) > 0 {
+		q.recordRemoval(OperationClear, allPositions(len(q.items)))
+	}
+	q.items = q.items[:0] // Clear slice but keep capacity
+	q.seqs = q.seqs[:0]
+	q.shuffled = false
+}
+
+// Reset empties the queue and forgets its journal without recording anything, for when playback
+// stops and there is nothing left to undo
+func (q *SimpleQueue) Reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.items = q.items[:0]
+	q.seqs = q.seqs[:0]
+	q.shuffled = false
+	q.journal = nil
+}
+
+// Replace swaps the queue's items for the given ones in their order, such as the queue of a broadcast
+// being mirrored. It isn't journaled, the next mirror would overwrite an undone queue anyway.
+func (q *SimpleQueue) Replace(items []types.AudioSource) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.items = append(q.items[:0], items...)
+	q.seqs = q.seqs[:0]
+	for range items {
+		q.seqs = append(q.seqs, q.nextSeq)
+		q.nextSeq++
+	}
+	q.shuffled = false
+}
+
+// Shuffle randomly reorders all items in the queue with a seed drawn from crypto/rand and returns the seed.
+// Passing the seed to ShuffleWithSeed on the same queue reproduces the order.
+func (q *SimpleQueue) Shuffle() uint64 {
+	seed := RandomSeed()
+	q.ShuffleWithSeed(seed)
+	return seed
+}
+
+// ShuffleWithSeed reorders all items in the queue deterministically for the given seed
+func (q *SimpleQueue) ShuffleWithSeed(seed uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) < 2 {
+		return
+	}
+	q.recordOrder(OperationShuffle)
+
+	// Each tier is shuffled on its own so priority entries stay ahead
+	rng := mathrand.New(mathrand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	priority := q.priorityCount()
+	for _, tier := range [][2]int{{0, priority}, {priority, len(q.items)}} {
+		start, end := tier[0], tier[1]
+		rng.Shuffle(end-start, func(i, j int) {
+			i, j = start+i, start+j
+			q.items[i], q.items[j] = q.items[j], q.items[i]
+			q.seqs[i], q.seqs[j] = q.seqs[j], q.seqs[i]
+		})
+	}
+	q.shuffled = true
+}
+
+// Unshuffle restores the order in which the remaining items were added, keeping the priority tier first
+func (q *SimpleQueue) Unshuffle() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.shuffled {
+		return ErrNotShuffled
+	}
+	q.recordOrder(OperationUnshuffle)
+
+	sort.Sort(bySeq{q})
+	q.shuffled = false
+	return nil
+}
+
+// IsShuffled reports whether the queue has been shuffled since it was last in insertion order
+func (q *SimpleQueue) IsShuffled() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.shuffled
+}
+
+// bySeq sorts queue items back into insertion order within their tier (caller must hold the write lock)
+type bySeq struct{ q *SimpleQueue }
+
+func (b bySeq) Len() int { return len(b.q.items) }
+func (b bySeq) Less(i, j int) bool {
+	if b.q.items[i].Priority != b.q.items[j].Priority {
+		return b.q.items[i].Priority
+	}
+	return b.q.seqs[i] < b.q.seqs[j]
+}
+func (b bySeq) Swap(i, j int) {
+	b.q.items[i], b.q.items[j] = b.q.items[j], b.q.items[i]
+	b.q.seqs[i], b.q.seqs[j] = b.q.seqs[j], b.q.seqs[i]
+}
+
+// RandomSeed returns a shuffle seed in [0, MaxSeed] from crypto/rand, falling back to the clock if it fails
+func RandomSeed() uint64 {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return uint64(time.Now().UnixNano()) & MaxSeed
+	}
+	return binary.LittleEndian.Uint64(buf[:]) & MaxSeed
 }
 
 // Next removes and returns the first item in the queue (FIFO)
@@ -106,6 +524,7 @@ func (q *SimpleQueue) Next() (*types.AudioSource, bool) {
 
 	// Remove it from the queue
 	q.items = q.items[1:]
+	q.seqs = q.seqs[1:]
 
 	return &item, true
 }
@@ -123,3 +542,107 @@ func (q *SimpleQueue) IsEmpty() bool {
 	defer q.mu.RUnlock()
 	return len(q.items) == 0
 }
+
+// Undo reverts the most recent clear, remove, shuffle, unshuffle, move, swap, dedupe, remove-user or fair and returns the name
+// of the undone operation.
+// Only that operation is reverted: removed tracks go back where they were, reordered tracks back into their
+// earlier order, and tracks added or played since stay as they are.
+func (q *SimpleQueue) Undo() (string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.journal) == 0 {
+		return "", ErrNothingToUndo
+	}
+
+	entry := q.journal[len(q.journal)-1]
+	q.journal = q.journal[:len(q.journal)-1]
+	if entry.order != nil {
+		q.restoreOrder(entry.order)
+	} else {
+		q.restoreRemoved(entry)
+	}
+	q.shuffled = entry.shuffled
+	return entry.Operation, nil
+}
+
+// restoreOrder puts the items that were queued before a reorder back into their order then, within the
+// positions they hold now (caller must hold the write lock)
+func (q *SimpleQueue) restoreOrder(order []uint64) {
+	rank := make(map[uint64]int, len(order))
+	for index, seq := range order {
+		rank[seq] = index
+	}
+	var positions []int
+	for index, seq := range q.seqs {
+		if _, known := rank[seq]; known {
+			positions = append(positions, index)
+		}
+	}
+	sorted := slices.Clone(positions)
+	slices.SortFunc(sorted, func(a, b int) int { return rank[q.seqs[a]] - rank[q.seqs[b]] })
+
+	items, seqs := slices.Clone(q.items), slices.Clone(q.seqs)
+	for n, position := range positions {
+		items[position], seqs[position] = q.items[sorted[n]], q.seqs[sorted[n]]
+	}
+	q.items, q.seqs = items, seqs
+}
+
+// restoreRemoved inserts removed items back at their earlier positions, kept within their tier (caller must
+// hold the write lock)
+func (q *SimpleQueue) restoreRemoved(entry JournalEntry) {
+	for n, item := range entry.Items {
+		start, end := 0, q.priorityCount()
+		if !item.Priority {
+			start, end = end, len(q.items)
+		}
+		position := min(max(entry.positions[n], start), end)
+		q.items = slices.Insert(q.items, position, item)
+		q.seqs = slices.Insert(q.seqs, position, entry.seqs[n])
+	}
+}
+
+// Journal returns a copy of the recorded mutations, oldest first
+func (q *SimpleQueue) Journal() []JournalEntry {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	result := make([]JournalEntry, len(q.journal))
+	copy(result, q.journal)
+	return result
+}
+
+// recordRemoval journals the items at positions, in ascending order, before an operation removes them
+// (caller must hold the write lock)
+func (q *SimpleQueue) recordRemoval(operation string, positions []int) {
+	entry := JournalEntry{Operation: operation, positions: positions, shuffled: q.shuffled}
+	for _, position := range positions {
+		entry.Items = append(entry.Items, q.items[position])
+		entry.seqs = append(entry.seqs, q.seqs[position])
+	}
+	q.record(entry)
+}
+
+// recordOrder journals the order of the queue before an operation reorders it (caller must hold the write lock)
+func (q *SimpleQueue) recordOrder(operation string) {
+	q.record(JournalEntry{Operation: operation, order: slices.Clone(q.seqs), shuffled: q.shuffled})
+}
+
+// record adds an entry to the journal, dropping the oldest beyond journalSize (caller must hold the write lock)
+func (q *SimpleQueue) record(entry JournalEntry) {
+	entry.Time = time.Now()
+	q.journal = append(q.journal, entry)
+	if len(q.journal) > journalSize {
+		q.journal = q.journal[len(q.journal)-journalSize:]
+	}
+}
+
+// allPositions lists the positions of a queue of the given length
+func allPositions(length int) []int {
+	positions := make([]int, length)
+	for index := range positions {
+		positions[index] = index
+	}
+	return positions
+}
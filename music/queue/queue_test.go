@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"pxnx-discord-bot/music/types"
 )
@@ -15,7 +16,7 @@ func createTestSource(title string) types.AudioSource {
 	return types.AudioSource{
 		Title:       title,
 		URL:         "https://example.com/" + title,
-		Duration:    "3:45",
+		Duration:    types.Seconds(225),
 		Thumbnail:   "https://example.com/thumb.jpg",
 		Provider:    "test",
 		RequestedBy: "user123",
@@ -23,6 +24,15 @@ func createTestSource(title string) types.AudioSource {
 	}
 }
 
+// titles lists the titles of queued items in order
+func titles(items []types.AudioSource) []string {
+	result := make([]string, len(items))
+	for index, item := range items {
+		result[index] = item.Title
+	}
+	return result
+}
+
 func TestNewQueue(t *testing.T) {
 	q := NewQueue()
 	assert.NotNil(t, q)
@@ -79,6 +89,154 @@ func TestQueueRemove(t *testing.T) {
 	assert.Contains(t, err.Error(), "out of range")
 }
 
+func TestQueueMove(t *testing.T) {
+	q := NewQueue()
+	for _, title := range []string{"song1", "song2", "song3", "song4"} {
+		q.Add(createTestSource(title))
+	}
+
+	// Move forward and back, shifting the items in between
+	assert.NoError(t, q.Move(0, 2))
+	assert.Equal(t, []string{"song2", "song3", "song1", "song4"}, queueTitles(q))
+
+	assert.NoError(t, q.Move(3, 0))
+	assert.Equal(t, []string{"song4", "song2", "song3", "song1"}, queueTitles(q))
+
+	// Moving in place changes nothing and is not journaled
+	journaled := len(q.Journal())
+	assert.NoError(t, q.Move(1, 1))
+	assert.Len(t, q.Journal(), journaled)
+
+	operation, err := q.Undo()
+	assert.NoError(t, err)
+	assert.Equal(t, OperationMove, operation)
+	assert.Equal(t, []string{"song2", "song3", "song1", "song4"}, queueTitles(q))
+
+	// Test error cases
+	err = q.Move(-1, 0)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "out of range")
+
+	err = q.Move(0, 4)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "out of range")
+}
+
+func TestQueueSwap(t *testing.T) {
+	q := NewQueue()
+	for _, title := range []string{"song1", "song2", "song3"} {
+		q.Add(createTestSource(title))
+	}
+
+	assert.NoError(t, q.Swap(0, 2))
+	assert.Equal(t, []string{"song3", "song2", "song1"}, queueTitles(q))
+
+	operation, err := q.Undo()
+	assert.NoError(t, err)
+	assert.Equal(t, OperationSwap, operation)
+	assert.Equal(t, []string{"song1", "song2", "song3"}, queueTitles(q))
+
+	err = q.Swap(1, 3)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "out of range")
+	assert.Equal(t, []string{"song1", "song2", "song3"}, queueTitles(q))
+}
+
+func TestQueueReorderKeepsPriorityTier(t *testing.T) {
+	q := NewQueue()
+	q.Add(createPrioritySource("priority1"))
+	q.Add(createPrioritySource("priority2"))
+	q.Add(createTestSource("normal1"))
+	q.Add(createTestSource("normal2"))
+
+	assert.ErrorIs(t, q.Move(0, 3), ErrCrossesPriority)
+	assert.ErrorIs(t, q.Move(2, 0), ErrCrossesPriority)
+	assert.ErrorIs(t, q.Swap(1, 2), ErrCrossesPriority)
+	assert.Empty(t, q.Journal(), "refused reorders are not journaled")
+
+	// Reordering within a tier is fine
+	assert.NoError(t, q.Swap(0, 1))
+	assert.NoError(t, q.Move(3, 2))
+	assert.Equal(t, []string{"priority2", "priority1", "normal2", "normal1"}, queueTitles(q))
+	assert.Equal(t, 2, q.PriorityCount())
+}
+
+func TestQueueUnshuffleAfterMove(t *testing.T) {
+	q := NewQueue()
+	for _, title := range []string{"song1", "song2", "song3"} {
+		q.Add(createTestSource(title))
+	}
+
+	// Items keep their insertion order through moves
+	q.ShuffleWithSeed(7)
+	assert.NoError(t, q.Move(2, 0))
+	assert.NoError(t, q.Unshuffle())
+	assert.Equal(t, []string{"song1", "song2", "song3"}, queueTitles(q))
+}
+
+func TestQueueDeduplicate(t *testing.T) {
+	q := NewQueue()
+	for _, title := range []string{"song1", "song2", "song1", "song3", "song2", "song1"} {
+		q.Add(createTestSource(title))
+	}
+	noURL := createTestSource("upload")
+	noURL.URL, noURL.StreamURL = "", ""
+	q.Add(noURL)
+	q.Add(noURL)
+
+	assert.Equal(t, 3, q.Deduplicate())
+	assert.Equal(t, []string{"song1", "song2", "song3", "upload", "upload"}, queueTitles(q), "tracks without a URL are never duplicates")
+
+	// Nothing left to remove is not journaled
+	journaled := len(q.Journal())
+	assert.Equal(t, 0, q.Deduplicate())
+	assert.Len(t, q.Journal(), journaled)
+
+	operation, err := q.Undo()
+	assert.NoError(t, err)
+	assert.Equal(t, OperationDedupe, operation)
+	assert.Equal(t, 8, q.Size())
+
+	// After a shuffle the copy that now plays first is kept
+	q.ShuffleWithSeed(3)
+	first := queueTitles(q)[0]
+	assert.Equal(t, 3, q.Deduplicate())
+	assert.Equal(t, first, queueTitles(q)[0])
+	assert.ElementsMatch(t, []string{"song1", "song2", "song3", "upload", "upload"}, queueTitles(q))
+}
+
+func TestQueueDeduplicateKeepsPriorityCopy(t *testing.T) {
+	q := NewQueue()
+	q.Add(createTestSource("song1"))
+	q.Add(createPrioritySource("song1"))
+
+	assert.Equal(t, 1, q.Deduplicate())
+	item, err := q.Get(0)
+	assert.NoError(t, err)
+	assert.True(t, item.Priority, "the copy that plays first is kept")
+}
+
+func TestQueueRemoveByRequester(t *testing.T) {
+	q := NewQueue()
+	for index, title := range []string{"song1", "spam1", "song2", "spam2", "spam3"} {
+		source := createTestSource(title)
+		if index == 1 || index > 2 {
+			source.RequestedBy = "spammer"
+		}
+		q.Add(source)
+	}
+
+	assert.Equal(t, 3, q.RemoveByRequester("spammer"))
+	assert.Equal(t, []string{"song1", "song2"}, queueTitles(q))
+	assert.Equal(t, 0, q.RemoveByRequester("spammer"))
+	assert.Equal(t, 0, q.RemoveByRequester(""), "tracks without a requester are not matched")
+
+	operation, err := q.Undo()
+	assert.NoError(t, err)
+	assert.Equal(t, OperationRemoveBy, operation)
+	assert.Equal(t, []string{"song1", "spam1", "song2", "spam2", "spam3"}, queueTitles(q))
+}
+
 func TestQueueGet(t *testing.T) {
 	q := NewQueue()
 	source1 := createTestSource("song1")
@@ -146,6 +304,200 @@ func TestQueueClear(t *testing.T) {
 	assert.Empty(t, q.GetAll())
 }
 
+func TestQueueClearIfUnchanged(t *testing.T) {
+	q := NewQueue()
+	q.Add(createTestSource("song1"))
+	q.Add(createTestSource("song2"))
+	preview := q.GetAll()
+
+	q.Add(createTestSource("song3"))
+	assert.False(t, q.ClearIfUnchanged(preview), "a track queued after the preview should stop the clear")
+	assert.Equal(t, 3, q.Size())
+
+	require.NoError(t, q.Remove(2))
+	require.NoError(t, q.Swap(0, 1))
+	assert.False(t, q.ClearIfUnchanged(preview), "a reordered queue should stop the clear")
+
+	require.NoError(t, q.Swap(0, 1))
+	assert.True(t, q.ClearIfUnchanged(preview))
+	assert.True(t, q.IsEmpty())
+}
+
+func TestQueueReplace(t *testing.T) {
+	q := NewQueue()
+	q.SetFair(true)
+	q.Add(createTestSource("old1"))
+	q.Add(createTestSource("old2"))
+	q.ShuffleWithSeed(1)
+	journaled := len(q.Journal())
+
+	priority := createTestSource("priority")
+	priority.Priority = true
+	q.Replace([]types.AudioSource{createTestSource("song1"), createTestSource("song2"), priority})
+
+	titles := []string{}
+	for _, item := range q.GetAll() {
+		titles = append(titles, item.Title)
+	}
+	assert.Equal(t, []string{"song1", "song2", "priority"}, titles, "the given order is kept as is")
+	assert.False(t, q.IsShuffled())
+	assert.Len(t, q.Journal(), journaled, "replacing isn't journaled")
+
+	next, ok := q.Next()
+	assert.True(t, ok)
+	assert.Equal(t, "song1", next.Title)
+}
+
+func TestQueueUndo(t *testing.T) {
+	t.Run("nothing to undo", func(t *testing.T) {
+		q := NewQueue()
+		_, err := q.Undo()
+		assert.ErrorIs(t, err, ErrNothingToUndo)
+	})
+
+	t.Run("undo clear restores items", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(createTestSource("song1"))
+		q.Add(createTestSource("song2"))
+		q.Clear()
+
+		operation, err := q.Undo()
+		assert.NoError(t, err)
+		assert.Equal(t, OperationClear, operation)
+		items := q.GetAll()
+		assert.Len(t, items, 2)
+		assert.Equal(t, "song1", items[0].Title)
+		assert.Equal(t, "song2", items[1].Title)
+	})
+
+	t.Run("undo remove restores order", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(createTestSource("song1"))
+		q.Add(createTestSource("song2"))
+		q.Add(createTestSource("song3"))
+		assert.NoError(t, q.Remove(1))
+
+		operation, err := q.Undo()
+		assert.NoError(t, err)
+		assert.Equal(t, OperationRemove, operation)
+		items := q.GetAll()
+		assert.Equal(t, []string{"song1", "song2", "song3"}, []string{items[0].Title, items[1].Title, items[2].Title})
+	})
+
+	t.Run("undo shuffle restores order", func(t *testing.T) {
+		q := NewQueue()
+		for i := 0; i < 10; i++ {
+			q.Add(createTestSource(fmt.Sprintf("song%d", i)))
+		}
+		original := q.GetAll()
+		q.Shuffle()
+
+		operation, err := q.Undo()
+		assert.NoError(t, err)
+		assert.Equal(t, OperationShuffle, operation)
+		assert.Equal(t, original, q.GetAll())
+	})
+
+	t.Run("undo walks back multiple operations", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(createTestSource("song1"))
+		q.Add(createTestSource("song2"))
+		assert.NoError(t, q.Remove(0))
+		q.Clear()
+
+		_, err := q.Undo()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, q.Size())
+
+		_, err = q.Undo()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, q.Size())
+
+		_, err = q.Undo()
+		assert.ErrorIs(t, err, ErrNothingToUndo)
+	})
+
+	t.Run("undo shuffle keeps tracks added since", func(t *testing.T) {
+		q := NewQueue()
+		for i := 0; i < 10; i++ {
+			q.Add(createTestSource(fmt.Sprintf("song%d", i)))
+		}
+		q.ShuffleWithSeed(42)
+		q.Add(createTestSource("added"))
+
+		_, err := q.Undo()
+		assert.NoError(t, err)
+		items := q.GetAll()
+		require.Len(t, items, 11)
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, fmt.Sprintf("song%d", i), items[i].Title)
+		}
+		assert.Equal(t, "added", items[10].Title)
+	})
+
+	t.Run("undo does not bring back played tracks", func(t *testing.T) {
+		q := NewQueue()
+		for i := 0; i < 4; i++ {
+			q.Add(createTestSource(fmt.Sprintf("song%d", i)))
+		}
+		require.NoError(t, q.Swap(0, 3))
+		played, _ := q.Next()
+		assert.Equal(t, "song3", played.Title)
+		require.NoError(t, q.Remove(0))
+
+		_, err := q.Undo()
+		assert.NoError(t, err)
+		_, err = q.Undo()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"song0", "song1", "song2"}, titles(q.GetAll()), "the swap is undone among the remaining tracks")
+	})
+
+	t.Run("undo clear keeps tracks added since", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(createTestSource("song1"))
+		q.Add(createTestSource("song2"))
+		q.Clear()
+		q.Add(createTestSource("song3"))
+
+		_, err := q.Undo()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"song1", "song2", "song3"}, titles(q.GetAll()))
+	})
+
+	t.Run("reset is not journaled", func(t *testing.T) {
+		q := NewQueue()
+		q.Add(createTestSource("song1"))
+		q.Add(createTestSource("song2"))
+		require.NoError(t, q.Remove(0))
+		q.Reset()
+
+		assert.True(t, q.IsEmpty())
+		_, err := q.Undo()
+		assert.ErrorIs(t, err, ErrNothingToUndo, "a stopped queue has nothing to undo")
+	})
+
+	t.Run("no-op mutations are not journaled", func(t *testing.T) {
+		q := NewQueue()
+		q.Clear()
+		q.Add(createTestSource("song1"))
+		q.Shuffle()
+		assert.Empty(t, q.Journal())
+	})
+}
+
+func TestQueueJournalLimit(t *testing.T) {
+	q := NewQueue()
+	for i := 0; i < journalSize+5; i++ {
+		q.Add(createTestSource(fmt.Sprintf("song%d", i)))
+		q.Clear()
+	}
+
+	journal := q.Journal()
+	assert.Len(t, journal, journalSize)
+	// The oldest entries are dropped first
+	assert.Equal(t, "song5", journal[0].Items[0].Title)
+}
+
 func TestQueueShuffle(t *testing.T) {
 	q := NewQueue()
 
@@ -196,6 +548,262 @@ func TestQueueShuffle(t *testing.T) {
 	// but we can test that shuffle doesn't break the queue structure
 }
 
+func queueTitles(q *SimpleQueue) []string {
+	items := q.GetAll()
+	titles := make([]string, len(items))
+	for i, item := range items {
+		titles[i] = item.Title
+	}
+	return titles
+}
+
+func newNumberedQueue(n int) *SimpleQueue {
+	q := NewQueue()
+	for i := 0; i < n; i++ {
+		q.Add(createTestSource(fmt.Sprintf("song%d", i)))
+	}
+	return q
+}
+
+func TestQueueShuffleWithSeedIsDeterministic(t *testing.T) {
+	q1 := newNumberedQueue(20)
+	q2 := newNumberedQueue(20)
+
+	q1.ShuffleWithSeed(42)
+	q2.ShuffleWithSeed(42)
+	assert.Equal(t, queueTitles(q1), queueTitles(q2), "same seed should produce the same order")
+
+	q3 := newNumberedQueue(20)
+	q3.ShuffleWithSeed(43)
+	assert.NotEqual(t, queueTitles(q1), queueTitles(q3), "different seeds should produce different orders")
+}
+
+func TestQueueShuffleIsPermutation(t *testing.T) {
+	for seed := uint64(0); seed < 50; seed++ {
+		q := newNumberedQueue(15)
+		original := queueTitles(q)
+		q.ShuffleWithSeed(seed)
+		shuffled := queueTitles(q)
+
+		assert.ElementsMatch(t, original, shuffled, "seed %d lost or duplicated items", seed)
+	}
+}
+
+func TestQueueShuffleCoversAllPositions(t *testing.T) {
+	// Over many seeds every item should land in every position of a small queue
+	const size = 4
+	seen := make(map[string]map[int]bool)
+	for seed := uint64(0); seed < 500; seed++ {
+		q := newNumberedQueue(size)
+		q.ShuffleWithSeed(seed)
+		for position, title := range queueTitles(q) {
+			if seen[title] == nil {
+				seen[title] = make(map[int]bool)
+			}
+			seen[title][position] = true
+		}
+	}
+
+	for title, positions := range seen {
+		assert.Len(t, positions, size, "%s never reached some positions", title)
+	}
+}
+
+func TestQueueShuffleReturnsReproducibleSeed(t *testing.T) {
+	q1 := newNumberedQueue(10)
+	seed := q1.Shuffle()
+	assert.LessOrEqual(t, seed, uint64(MaxSeed))
+
+	q2 := newNumberedQueue(10)
+	q2.ShuffleWithSeed(seed)
+	assert.Equal(t, queueTitles(q1), queueTitles(q2))
+}
+
+func TestQueueUnshuffle(t *testing.T) {
+	t.Run("not shuffled", func(t *testing.T) {
+		q := newNumberedQueue(3)
+		assert.ErrorIs(t, q.Unshuffle(), ErrNotShuffled)
+	})
+
+	t.Run("restores insertion order", func(t *testing.T) {
+		q := newNumberedQueue(10)
+		original := queueTitles(q)
+		q.ShuffleWithSeed(7)
+		q.ShuffleWithSeed(8)
+		assert.True(t, q.IsShuffled())
+
+		assert.NoError(t, q.Unshuffle())
+		assert.Equal(t, original, queueTitles(q))
+		assert.False(t, q.IsShuffled())
+	})
+
+	t.Run("keeps changes made after the shuffle", func(t *testing.T) {
+		q := newNumberedQueue(5)
+		q.ShuffleWithSeed(1)
+		q.Next()
+		q.Add(createTestSource("late"))
+
+		remaining := queueTitles(q)
+		assert.NoError(t, q.Unshuffle())
+
+		unshuffled := queueTitles(q)
+		assert.ElementsMatch(t, remaining, unshuffled)
+		assert.Equal(t, "late", unshuffled[len(unshuffled)-1], "items added after the shuffle stay at the end")
+		for i := 1; i < len(unshuffled)-1; i++ {
+			assert.Less(t, unshuffled[i-1], unshuffled[i], "original items should be back in insertion order")
+		}
+	})
+
+	t.Run("can be undone", func(t *testing.T) {
+		q := newNumberedQueue(6)
+		q.ShuffleWithSeed(3)
+		shuffled := queueTitles(q)
+		assert.NoError(t, q.Unshuffle())
+
+		operation, err := q.Undo()
+		assert.NoError(t, err)
+		assert.Equal(t, OperationUnshuffle, operation)
+		assert.Equal(t, shuffled, queueTitles(q))
+		assert.True(t, q.IsShuffled())
+	})
+
+	t.Run("clear resets shuffle state", func(t *testing.T) {
+		q := newNumberedQueue(3)
+		q.ShuffleWithSeed(3)
+		q.Clear()
+		assert.ErrorIs(t, q.Unshuffle(), ErrNotShuffled)
+	})
+}
+
+func createPrioritySource(title string) types.AudioSource {
+	source := createTestSource(title)
+	source.Priority = true
+	return source
+}
+
+func TestQueuePriorityTier(t *testing.T) {
+	q := NewQueue()
+	q.Add(createTestSource("normal1"))
+	q.Add(createPrioritySource("priority1"))
+	q.Add(createTestSource("normal2"))
+	q.Add(createPrioritySource("priority2"))
+
+	assert.Equal(t, []string{"priority1", "priority2", "normal1", "normal2"}, queueTitles(q),
+		"priority entries jump normal ones but stay behind earlier priority entries")
+	assert.Equal(t, 2, q.PriorityCount())
+
+	next, ok := q.Next()
+	assert.True(t, ok)
+	assert.Equal(t, "priority1", next.Title)
+	assert.Equal(t, 1, q.PriorityCount())
+}
+
+func TestQueuePriorityShuffleKeepsTiers(t *testing.T) {
+	for seed := uint64(0); seed < 20; seed++ {
+		q := NewQueue()
+		for i := 0; i < 5; i++ {
+			q.Add(createTestSource(fmt.Sprintf("normal%d", i)))
+			q.Add(createPrioritySource(fmt.Sprintf("priority%d", i)))
+		}
+
+		q.ShuffleWithSeed(seed)
+		items := q.GetAll()
+		for i, item := range items {
+			assert.Equal(t, i < 5, item.Priority, "seed %d moved %s out of its tier", seed, item.Title)
+		}
+
+		assert.NoError(t, q.Unshuffle())
+		assert.Equal(t, []string{
+			"priority0", "priority1", "priority2", "priority3", "priority4",
+			"normal0", "normal1", "normal2", "normal3", "normal4",
+		}, queueTitles(q))
+	}
+}
+
+// createRequestedSource creates a test audio source queued by the given user
+func createRequestedSource(title, requester string) types.AudioSource {
+	source := createTestSource(title)
+	source.RequestedBy = requester
+	return source
+}
+
+func TestQueueFairAdd(t *testing.T) {
+	q := NewQueue()
+	q.SetFair(true)
+	assert.True(t, q.IsFair())
+
+	// A playlist from alice, then single requests from bob and carol
+	for i := 0; i < 3; i++ {
+		q.Add(createRequestedSource(fmt.Sprintf("alice%d", i), "alice"))
+	}
+	q.Add(createRequestedSource("bob0", "bob"))
+	q.Add(createRequestedSource("carol0", "carol"))
+	q.Add(createRequestedSource("bob1", "bob"))
+
+	assert.Equal(t, []string{"alice0", "bob0", "carol0", "alice1", "bob1", "alice2"}, queueTitles(q))
+
+	// Rounds count from the queue's head, so a track queued later waits behind the tracks of its round
+	next, ok := q.Next()
+	assert.True(t, ok)
+	assert.Equal(t, "alice0", next.Title)
+	q.Add(createRequestedSource("carol1", "carol"))
+	q.Add(createRequestedSource("dave0", "dave"))
+	assert.Equal(t, []string{"bob0", "carol0", "alice1", "dave0", "bob1", "alice2", "carol1"}, queueTitles(q))
+}
+
+func TestQueueFairAddKeepsPriorityTier(t *testing.T) {
+	q := NewQueue()
+	q.SetFair(true)
+	q.Add(createRequestedSource("alice0", "alice"))
+	q.Add(createRequestedSource("alice1", "alice"))
+
+	booster := createRequestedSource("bob0", "bob")
+	booster.Priority = true
+	q.Add(booster)
+	q.Add(createRequestedSource("carol0", "carol"))
+
+	assert.Equal(t, []string{"bob0", "alice0", "carol0", "alice1"}, queueTitles(q))
+	assert.Equal(t, 1, q.PriorityCount())
+}
+
+func TestQueueSetFairRebalances(t *testing.T) {
+	q := NewQueue()
+	for _, source := range []types.AudioSource{
+		createRequestedSource("alice0", "alice"),
+		createRequestedSource("alice1", "alice"),
+		createRequestedSource("alice2", "alice"),
+		createRequestedSource("bob0", "bob"),
+		createRequestedSource("bob1", "bob"),
+		createRequestedSource("carol0", "carol"),
+	} {
+		q.Add(source)
+	}
+
+	q.SetFair(true)
+	assert.Equal(t, []string{"alice0", "bob0", "carol0", "alice1", "bob1", "alice2"}, queueTitles(q))
+
+	operation, err := q.Undo()
+	assert.NoError(t, err)
+	assert.Equal(t, OperationFair, operation)
+	assert.Equal(t, []string{"alice0", "alice1", "alice2", "bob0", "bob1", "carol0"}, queueTitles(q))
+
+	// Turning fair mode off keeps the order, and new tracks go to the end again
+	q.SetFair(false)
+	assert.False(t, q.IsFair())
+	q.Add(createRequestedSource("bob2", "bob"))
+	assert.Equal(t, "bob2", queueTitles(q)[6])
+}
+
+func TestQueueSetFairWithoutReorder(t *testing.T) {
+	q := NewQueue()
+	q.Add(createRequestedSource("alice0", "alice"))
+	q.Add(createRequestedSource("bob0", "bob"))
+
+	q.SetFair(true)
+	assert.Equal(t, []string{"alice0", "bob0"}, queueTitles(q))
+	assert.Empty(t, q.Journal(), "an order that is already fair is not journaled")
+}
+
 func TestQueueNext(t *testing.T) {
 	q := NewQueue()
 
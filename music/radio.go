@@ -0,0 +1,23 @@
+package music
+
+import (
+	"context"
+
+	"pxnx-discord-bot/music/radio"
+)
+
+// SearchRadio looks up up to limit internet radio stations by name, most popular first
+func (sp *SimplePlayer) SearchRadio(ctx context.Context, name string, limit int) ([]radio.Station, error) {
+	sp.mu.RLock()
+	client := sp.radio
+	sp.mu.RUnlock()
+
+	return client.Search(ctx, name, limit)
+}
+
+// SetRadioDirectory points station searches at another radio-browser.info server; "" restores the default
+func (sp *SimplePlayer) SetRadioDirectory(baseURL string) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.radio = radio.NewClient(baseURL)
+}
@@ -0,0 +1,175 @@
+// Package radio finds internet radio stations in the radio-browser.info directory and turns them into
+// live tracks, which play until they are skipped and can't be seeked or downloaded.
+package radio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+	"pxnx-discord-bot/music/types"
+)
+
+// ProviderName is the provider of tracks played from radio stations
+const ProviderName = "radio"
+
+// DefaultBaseURL is the radio-browser.info API used when no other server is set; it resolves to one of
+// the community run mirrors
+const DefaultBaseURL = "https://all.api.radio-browser.info"
+
+// DefaultLimit is how many stations a search returns when no limit is given
+const DefaultLimit = 5
+
+// requestTimeout bounds a directory search
+const requestTimeout = 10 * time.Second
+
+// metadataStation is the AudioSource metadata key holding the Station a live track plays
+const metadataStation = "radio_station"
+
+// ErrNoStations is returned when a search finds no working station
+var ErrNoStations = errors.New("no radio station found")
+
+// Station is a radio-browser.info directory entry
+type Station struct {
+	UUID        string `json:"stationuuid"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	ResolvedURL string `json:"url_resolved"` // Stream behind playlist files such as .pls and .m3u
+	Homepage    string `json:"homepage"`
+	Favicon     string `json:"favicon"`
+	Tags        string `json:"tags"` // Comma separated
+	Country     string `json:"country"`
+	Language    string `json:"language"`
+	Codec       string `json:"codec"`
+	Bitrate     int    `json:"bitrate"` // kbps, 0 when unknown
+}
+
+// StreamURL returns the address to play the station from
+func (s Station) StreamURL() string {
+	if s.ResolvedURL != "" {
+		return s.ResolvedURL
+	}
+	return s.URL
+}
+
+// TagList returns the station's tags, at most max of them
+func (s Station) TagList(max int) []string {
+	var tags []string
+	for _, tag := range strings.Split(s.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+		if len(tags) == max {
+			break
+		}
+	}
+	return tags
+}
+
+// Track returns a live track playing the station
+func (s Station) Track() types.AudioSource {
+	return types.AudioSource{
+		Title:     strings.TrimSpace(s.Name),
+		URL:       s.StreamURL(),
+		StreamURL: s.StreamURL(),
+		Thumbnail: s.Favicon,
+		Uploader:  s.Country,
+		Provider:  ProviderName,
+		Live:      true,
+		Metadata:  map[string]interface{}{metadataStation: s},
+	}
+}
+
+// StationOf returns the station a track plays, if it is a radio track
+func StationOf(track types.AudioSource) (Station, bool) {
+	station, ok := track.Metadata[metadataStation].(Station)
+	return station, ok
+}
+
+// Client searches the radio-browser.info directory
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient creates a client for the radio-browser.info API at baseURL, DefaultBaseURL when empty
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  httpclient.New(requestTimeout),
+	}
+}
+
+// ByURL returns the directory entry of a station streaming from streamURL
+func (c *Client) ByURL(ctx context.Context, streamURL string) (Station, error) {
+	stations, err := c.query(ctx, "/json/stations/byurl", url.Values{"url": {strings.TrimSpace(streamURL)}})
+	if err != nil {
+		return Station{}, err
+	}
+	return stations[0], nil
+}
+
+// Search returns up to limit working stations whose name contains name, most popular first
+func (c *Client) Search(ctx context.Context, name string, limit int) ([]Station, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrNoStations
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	return c.query(ctx, "/json/stations/search", url.Values{
+		"name":       {name},
+		"limit":      {strconv.Itoa(limit)},
+		"hidebroken": {"true"},
+		"order":      {"clickcount"},
+		"reverse":    {"true"},
+	})
+}
+
+// query requests a station list from the directory and returns the playable stations in it
+func (c *Client) query(ctx context.Context, endpoint string, query url.Values) ([]Station, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build radio search: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search radio stations: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to search radio stations: %s", resp.Status)
+	}
+
+	var stations []Station
+	if err := json.NewDecoder(resp.Body).Decode(&stations); err != nil {
+		return nil, fmt.Errorf("failed to parse radio stations: %w", err)
+	}
+
+	// Entries without a stream can't be played
+	playable := stations[:0]
+	for _, station := range stations {
+		if station.StreamURL() != "" && strings.TrimSpace(station.Name) != "" {
+			playable = append(playable, station)
+		}
+	}
+	if len(playable) == 0 {
+		return nil, ErrNoStations
+	}
+	return playable, nil
+}
@@ -0,0 +1,103 @@
+package radio
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+func TestSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/json/stations/search", r.URL.Path)
+		assert.Equal(t, "jazz", r.URL.Query().Get("name"))
+		assert.Equal(t, "3", r.URL.Query().Get("limit"))
+		assert.Equal(t, "true", r.URL.Query().Get("hidebroken"))
+		assert.NotEmpty(t, r.Header.Get("User-Agent"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"stationuuid": "1", "name": "Jazz FM", "url": "http://jazz.example/listen.pls", "url_resolved": "http://jazz.example/stream",
+			 "homepage": "https://jazz.example", "tags": "jazz, smooth jazz,,", "country": "Latvia", "codec": "MP3", "bitrate": 128},
+			{"stationuuid": "2", "name": "Broken", "url": "", "url_resolved": ""},
+			{"stationuuid": "3", "name": "Jazz Radio", "url": "http://radio.example/jazz"}
+		]`))
+	}))
+	defer server.Close()
+
+	stations, err := NewClient(server.URL+"/").Search(context.Background(), " jazz ", 3)
+	require.NoError(t, err)
+	require.Len(t, stations, 2, "stations without a stream are dropped")
+
+	assert.Equal(t, "http://jazz.example/stream", stations[0].StreamURL())
+	assert.Equal(t, "http://radio.example/jazz", stations[1].StreamURL(), "falls back to the listed url")
+	assert.Equal(t, []string{"jazz", "smooth jazz"}, stations[0].TagList(5))
+	assert.Equal(t, []string{"jazz"}, stations[0].TagList(1))
+}
+
+func TestSearchFindsNothing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	_, err := client.Search(context.Background(), "nothing", 0)
+	assert.ErrorIs(t, err, ErrNoStations)
+
+	_, err = client.Search(context.Background(), "  ", 0)
+	assert.ErrorIs(t, err, ErrNoStations)
+}
+
+func TestSearchReportsServerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL).Search(context.Background(), "jazz", 1)
+	assert.ErrorContains(t, err, "503")
+}
+
+func TestTrackIsLive(t *testing.T) {
+	station := Station{UUID: "1", Name: " Jazz FM ", URL: "http://jazz.example/stream", Favicon: "https://jazz.example/icon.png", Country: "Latvia"}
+	track := station.Track()
+
+	assert.Equal(t, "Jazz FM", track.Title)
+	assert.Equal(t, "http://jazz.example/stream", track.StreamURL)
+	assert.Equal(t, ProviderName, track.Provider)
+	assert.False(t, track.Duration.Known())
+	assert.True(t, track.Live)
+
+	found, ok := StationOf(track)
+	require.True(t, ok)
+	assert.Equal(t, station, found)
+
+	_, ok = StationOf(types.AudioSource{Title: "Song", Duration: types.Seconds(180)})
+	assert.False(t, ok)
+}
+
+func TestByURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/json/stations/byurl", r.URL.Path)
+		if r.URL.Query().Get("url") != "http://jazz.example/stream" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"stationuuid": "1", "name": "Jazz FM", "url_resolved": "http://jazz.example/stream"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	station, err := client.ByURL(context.Background(), "http://jazz.example/stream")
+	require.NoError(t, err)
+	assert.Equal(t, "Jazz FM", station.Name)
+
+	_, err = client.ByURL(context.Background(), "http://unknown.example/stream")
+	assert.ErrorIs(t, err, ErrNoStations)
+}
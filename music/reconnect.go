@@ -0,0 +1,144 @@
+package music
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/utils"
+)
+
+// Voice server moves: discordgo closes the connection, which takes about a second, and reopens it
+const (
+	voiceDropWait         = 2 * time.Second  // How long to watch for the old connection to close
+	voiceReconnectTimeout = 15 * time.Second // How long the new connection gets to become ready
+	voicePollInterval     = 100 * time.Millisecond
+)
+
+// HandleVoiceServerUpdate recovers playback when Discord moves a guild's voice connection to another
+// voice server, such as after a region change. discordgo reopens the connection by itself but the bot
+// is no longer speaking on it, so the current track is continued from where the connection dropped;
+// when the connection doesn't come back the channel is joined again. With a Lavalink node the voice
+// server is handed to the node, which connects to it and keeps playing.
+func (sp *SimplePlayer) HandleVoiceServerUpdate(guildID, token, endpoint string) {
+	sp.mu.Lock()
+	player, exists := sp.connections[guildID]
+	if exists && player.remote != nil {
+		sp.mu.Unlock()
+		player.remote.SetVoiceServer(token, endpoint)
+		return
+	}
+	moved := exists && sp.voiceServers[guildID]
+	deaf := !sp.listening(guildID)
+	if exists {
+		sp.voiceServers[guildID] = true
+	}
+	sp.mu.Unlock()
+
+	// The first voice server of a connection is part of joining
+	if !moved {
+		return
+	}
+
+	utils.LogInfo("Voice server changed in guild %s, reconnecting", guildID)
+	player.markVoiceLost()
+
+	if !waitForVoice(player.conn, voiceReconnectTimeout) {
+		utils.LogWarn("Voice connection in guild %s did not come back, joining the channel again", guildID)
+		if _, err := sp.sessionFor(guildID).ChannelVoiceJoin(guildID, player.conn.ChannelID, false, deaf); err != nil {
+			utils.LogError("Failed to reconnect to voice in guild %s, leaving: %v", guildID, err)
+			if err := sp.LeaveChannel(guildID); err != nil {
+				utils.LogWarn("Failed to leave voice channel in guild %s: %v", guildID, err)
+			}
+			return
+		}
+	}
+
+	player.resumeVoice()
+	utils.LogInfo("Voice reconnected in guild %s", guildID)
+}
+
+// HandleBotVoiceState keeps track of the channel the bot is in when a moderator moves it, which Discord
+// reports as a voice state update of the bot itself. A Lavalink node is also given the voice session.
+func (sp *SimplePlayer) HandleBotVoiceState(guildID, channelID, sessionID string) {
+	// Leaving is reported with an empty channel, also while JoinChannel switches channels
+	if channelID == "" {
+		return
+	}
+
+	sp.mu.RLock()
+	player, exists := sp.connections[guildID]
+	store := sp.settings
+	sp.mu.RUnlock()
+	if !exists {
+		return
+	}
+
+	var moved bool
+	switch {
+	case player.remote != nil:
+		moved = player.remote.ChannelID() != channelID
+		player.remote.SetVoiceSession(sessionID, channelID)
+	case player.conn != nil:
+		player.conn.Lock()
+		moved = player.conn.ChannelID != channelID
+		player.conn.ChannelID = channelID
+		player.conn.Unlock()
+	}
+	if !moved {
+		return
+	}
+
+	utils.LogInfo("Moved to voice channel %s in guild %s", channelID, guildID)
+	if guild := store.Get(guildID); guild.StayConnected && guild.LastChannelID != channelID {
+		if _, err := store.Update(guildID, func(guild *settings.Guild) { guild.LastChannelID = channelID }); err != nil {
+			utils.LogWarn("Failed to save the 24/7 channel of guild %s: %v", guildID, err)
+		}
+	}
+}
+
+// waitForVoice waits for a voice connection that is being moved to close and become ready again, and
+// reports whether it did within timeout. A connection that stays ready was reopened between checks.
+func waitForVoice(conn *discordgo.VoiceConnection, timeout time.Duration) bool {
+	start := time.Now()
+	dropped := false
+	for time.Since(start) < timeout {
+		conn.RLock()
+		ready := conn.Ready
+		conn.RUnlock()
+
+		if !ready {
+			dropped = true
+		} else if dropped || time.Since(start) >= voiceDropWait {
+			return true
+		}
+		time.Sleep(voicePollInterval)
+	}
+	return false
+}
+
+// markVoiceLost remembers the track and position playing when the voice connection dropped
+func (vp *VoicePlayer) markVoiceLost() {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	if vp.playing && vp.lostTrack == nil {
+		vp.lostTrack, vp.lostAt = vp.current, vp.position()
+	}
+}
+
+// resumeVoice restarts the current track on a reconnected voice connection from where it dropped,
+// which also tells Discord the bot is speaking again
+func (vp *VoicePlayer) resumeVoice() {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	if !vp.playing {
+		vp.lostTrack = nil
+		return
+	}
+	vp.restart = true
+	close(vp.skipChan)
+	vp.skipChan = make(chan struct{})
+}
@@ -0,0 +1,190 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"pxnx-discord-bot/metrics"
+	"pxnx-discord-bot/music/lavalink"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// Playback backends
+const (
+	BackendFFmpeg   = "ffmpeg"   // Tracks are encoded in the bot and sent over its own voice connections
+	BackendLavalink = "lavalink" // A Lavalink node encodes tracks and connects to voice servers itself
+)
+
+// ErrSoundcheckRemote is returned for soundchecks while a Lavalink node plays the audio
+var ErrSoundcheckRemote = errors.New("the soundcheck tests the bot's own audio, but music is played by a Lavalink node")
+
+// UseLavalink plays music through a Lavalink node instead of FFmpeg. It applies to channels joined
+// afterwards; the node connects once ConnectBackend is given the bot's user ID.
+func (sp *SimplePlayer) UseLavalink(node *lavalink.Node) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.lavalink = node
+}
+
+// Backend returns which backend plays music
+func (sp *SimplePlayer) Backend() string {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	if sp.lavalink != nil {
+		return BackendLavalink
+	}
+	return BackendFFmpeg
+}
+
+// ConnectBackend connects the Lavalink node for the bot user, when music is played through one
+func (sp *SimplePlayer) ConnectBackend(userID string) {
+	sp.mu.RLock()
+	node := sp.lavalink
+	sp.mu.RUnlock()
+	if node != nil {
+		node.Connect(userID)
+	}
+}
+
+// joinRemote asks Discord to put the bot in a voice channel on the gateway and returns the guild's
+// Lavalink player, which connects once the voice session is forwarded to it (caller holds sp.mu)
+func (sp *SimplePlayer) joinRemote(guildID, channelID string) (*lavalink.Player, error) {
+	if err := sp.sessionFor(guildID).ChannelVoiceJoinManual(guildID, channelID, false, true); err != nil {
+		return nil, err
+	}
+	remote := sp.lavalink.Player(guildID)
+	remote.SetChannel(channelID)
+	return remote, nil
+}
+
+// leaveRemote leaves the voice channel on the gateway and drops the guild's player from the node
+func (sp *SimplePlayer) leaveRemote(guildID string, remote *lavalink.Player) {
+	if err := sp.sessionFor(guildID).ChannelVoiceJoinManual(guildID, "", false, true); err != nil {
+		utils.LogWarn("Failed to leave voice channel in guild %s: %v", guildID, err)
+	}
+	utils.SafeGo("music.lavalinkDestroy", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), currentResolveTimeout())
+		defer cancel()
+		if err := remote.Destroy(ctx); err != nil {
+			utils.LogWarn("Failed to remove the Lavalink player of guild %s: %v", guildID, err)
+		}
+	})
+}
+
+// playRemote plays a track on the Lavalink node until it ends, is skipped or stopped. The node streams
+// the audio, so downloads, prefetching, filters and bandwidth metering don't apply.
+func (vp *VoicePlayer) playRemote(track *types.AudioSource) {
+	vp.mu.RLock()
+	stopChan, skipChan, volume := vp.stopChan, vp.skipChan, vp.volume
+	vp.mu.RUnlock()
+
+	// A new node player starts at full volume, the guild's saved volume replaces it before the first track
+	if volume != vp.remote.GetVolume() {
+		if err := vp.remote.SetVolume(volume); err != nil {
+			utils.LogWarn("Failed to set the Lavalink volume of guild %s: %v", vp.guildID, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), currentResolveTimeout())
+	err := vp.remote.Play(ctx, *track)
+	cancel()
+	if err != nil {
+		utils.LogError("Failed to play track %s on Lavalink: %v", track.Title, err)
+		metrics.PlaybackErrors.Inc("lavalink")
+		vp.mu.Lock()
+		vp.current = nil
+		vp.mu.Unlock()
+		return
+	}
+
+	vp.mu.Lock()
+	vp.started, vp.offset, vp.speed = time.Now(), 0, 1
+	paused := vp.pauseChan != nil
+	if paused {
+		// Paused before the track started, nothing has played yet
+		vp.pausedAt = vp.started
+	}
+	vp.mu.Unlock()
+	if paused {
+		if err := vp.remote.Pause(); err != nil {
+			utils.LogWarn("Failed to pause Lavalink in guild %s: %v", vp.guildID, err)
+		}
+	}
+
+	// Skipped tracks still count as played
+	vp.history.Add(*track)
+	vp.playTimes.Record(*track, time.Now())
+	vp.stats.RecordPlay(vp.guildID, *track, time.Now())
+	vp.notifyChange()
+
+	ended := vp.remote.Ended()
+	for {
+		select {
+		case end := <-ended:
+			if end.Failed() {
+				utils.LogError("Failed to play track %s on Lavalink: %v", track.Title, end.Err)
+				metrics.PlaybackErrors.Inc("lavalink")
+			}
+			return
+		case <-stopChan:
+			if err := vp.remote.Stop(); err != nil {
+				utils.LogWarn("Failed to stop Lavalink in guild %s: %v", vp.guildID, err)
+			}
+			return
+		case <-skipChan:
+			// Filter changes restart tracks in the FFmpeg player, the node keeps playing
+			if _, restart := vp.takeRestart(0); restart {
+				vp.mu.RLock()
+				skipChan = vp.skipChan
+				vp.mu.RUnlock()
+				continue
+			}
+			if err := vp.remote.Stop(); err != nil {
+				utils.LogWarn("Failed to stop Lavalink in guild %s: %v", vp.guildID, err)
+			}
+			return
+		}
+	}
+}
+
+// channelID returns the voice channel the player is in
+func (vp *VoicePlayer) channelID() string {
+	if vp.remote != nil {
+		return vp.remote.ChannelID()
+	}
+	if vp.conn == nil {
+		return ""
+	}
+	vp.conn.RLock()
+	defer vp.conn.RUnlock()
+	return vp.conn.ChannelID
+}
+
+// voiceReady reports whether the player's voice connection is up
+func (vp *VoicePlayer) voiceReady() bool {
+	if vp.remote != nil {
+		return vp.remote.Connected()
+	}
+	if vp.conn == nil {
+		return false
+	}
+	vp.conn.RLock()
+	defer vp.conn.RUnlock()
+	return vp.conn.Ready
+}
+
+// pauseRemote pauses or resumes the node's player after the VoicePlayer's pause state changed
+func (vp *VoicePlayer) pauseRemote(paused bool) {
+	if vp.remote == nil {
+		return
+	}
+	control, action := vp.remote.Resume, "resume"
+	if paused {
+		control, action = vp.remote.Pause, "pause"
+	}
+	if err := control(); err != nil {
+		utils.LogWarn("Failed to %s Lavalink in guild %s: %v", action, vp.guildID, err)
+	}
+}
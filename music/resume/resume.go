@@ -0,0 +1,57 @@
+// Package resume decides when a track whose stream URL the CDN refused mid-playback continues from
+// where it stopped with a freshly extracted URL, instead of counting as a broken stream.
+package resume
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// MaxRefreshes is how often one playback of a track resolves its stream URL again after a refusal
+	MaxRefreshes = 3
+
+	// MinProgress is how far a fresh URL must play before a refusal is resumed again. A URL refused
+	// right away points at something a new one won't fix, such as a blocked address.
+	MinProgress = 5 * time.Second
+)
+
+// forbiddenMarkers are the ways FFmpeg reports an HTTP 403 in its error output, when opening the
+// stream and when reconnecting to it mid-track
+var forbiddenMarkers = []string{"403 Forbidden", "HTTP error 403", "Server returned 403"}
+
+// Forbidden reports whether FFmpeg's error output shows the server refused the stream with 403,
+// which YouTube does once the signature in a stream URL has expired
+func Forbidden(stderr string) bool {
+	for _, marker := range forbiddenMarkers {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Budget limits the stream refreshes of one playback of a track. The zero value is ready to use.
+type Budget struct {
+	used int
+	last time.Duration // Position the last refresh resumed at
+}
+
+// Allow reports whether a track refused at position may be resumed with a fresh URL, and counts the
+// refresh when it may
+func (b *Budget) Allow(position time.Duration) bool {
+	if b.used >= MaxRefreshes {
+		return false
+	}
+	if b.used > 0 && position-b.last < MinProgress {
+		return false
+	}
+	b.used++
+	b.last = position
+	return true
+}
+
+// Used returns how many refreshes were allowed
+func (b *Budget) Used() int {
+	return b.used
+}
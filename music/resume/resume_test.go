@@ -0,0 +1,26 @@
+package resume
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForbidden(t *testing.T) {
+	assert.True(t, Forbidden("[https @ 0x55] HTTP error 403 Forbidden\n"))
+	assert.True(t, Forbidden("https://rr1.googlevideo.com/videoplayback: Server returned 403 Forbidden (access denied)"))
+	assert.True(t, Forbidden("[tls @ 0x1] Will reconnect at 1048576 in 0 second(s), error=Server returned 403."))
+	assert.False(t, Forbidden("Server returned 404 Not Found"))
+	assert.False(t, Forbidden(""))
+}
+
+func TestBudget(t *testing.T) {
+	var budget Budget
+	assert.True(t, budget.Allow(0), "a URL refused before it played is refreshed once")
+	assert.False(t, budget.Allow(2*time.Second), "the fresh URL was refused too quickly")
+	assert.True(t, budget.Allow(3*time.Minute))
+	assert.True(t, budget.Allow(6*time.Minute))
+	assert.False(t, budget.Allow(9*time.Minute), "the budget is used up")
+	assert.Equal(t, MaxRefreshes, budget.Used())
+}
@@ -0,0 +1,272 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pxnx-discord-bot/music/direct"
+	"pxnx-discord-bot/music/providers"
+	"pxnx-discord-bot/music/radio"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/tracing"
+	"pxnx-discord-bot/utils"
+)
+
+// YouTubeProvider is the provider of tracks resolved and searched with yt-dlp
+const YouTubeProvider = "youtube"
+
+// ErrSearchUnsupported is returned when searching a provider that only plays links
+var ErrSearchUnsupported = errors.New("this provider can't be searched, give it a link")
+
+// newProviderRegistry registers the built-in providers in their default priority: providers for
+// specific links first, yt-dlp last since it takes any link
+func newProviderRegistry(sp *SimplePlayer) *providers.Registry {
+	return providers.NewRegistry(
+		sp.twitch,
+		&directProvider{sp: sp},
+		&radioProvider{sp: sp},
+		&youtubeProvider{sp: sp},
+	)
+}
+
+// Providers returns the provider names in routing priority
+func (sp *SimplePlayer) Providers() []string {
+	return sp.providers.Names()
+}
+
+// SetProviderOrder changes which provider a link goes to when several support it
+func (sp *SimplePlayer) SetProviderOrder(names []string) error {
+	return sp.providers.SetOrder(names)
+}
+
+// ResolveWith resolves a link or search query with a named provider instead of routing it. Failures are
+// logged for the request in ctx.
+func (sp *SimplePlayer) ResolveWith(ctx context.Context, providerName, query string) (*types.AudioSource, error) {
+	ctx, span := tracing.Start(ctx, "music.resolve", tracing.KindInternal, "provider", providerName, "query", query)
+	defer span.End()
+	track, err := sp.resolveWith(ctx, providerName, query)
+	span.RecordError(err)
+	return track, err
+}
+
+// resolveWith resolves a query for ResolveWith
+func (sp *SimplePlayer) resolveWith(ctx context.Context, providerName, query string) (*types.AudioSource, error) {
+	if providerName == "" {
+		track, err := sp.resolveTrack(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract track info: %w", err)
+		}
+		return track, nil
+	}
+	provider, err := sp.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	track, err := resolveWithin(ctx, provider, query)
+	if err != nil {
+		utils.LogWarnContext(ctx, "Provider %s failed to resolve %q: %v", provider.GetProviderName(), query, err)
+		return nil, fmt.Errorf("failed to extract track info: %w", err)
+	}
+	return track, nil
+}
+
+// ProviderCapabilities returns what a named provider supports, false for providers that aren't registered
+func (sp *SimplePlayer) ProviderCapabilities(providerName string) (types.ProviderCapabilities, bool) {
+	provider, err := sp.providers.Get(providerName)
+	if err != nil {
+		return types.ProviderCapabilities{}, false
+	}
+	return provider.Capabilities(), true
+}
+
+// SearchWith returns up to maxResults search results from a named provider
+func (sp *SimplePlayer) SearchWith(ctx context.Context, providerName, query string, maxResults int) ([]types.AudioSource, error) {
+	provider, err := sp.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	if !provider.Capabilities().Search {
+		return nil, fmt.Errorf("%s: %w", provider.GetProviderName(), ErrSearchUnsupported)
+	}
+	return provider.Search(ctx, query, maxResults)
+}
+
+// SearchProvider returns the provider a guild's search queries go to
+func (sp *SimplePlayer) SearchProvider(guildID string) string {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	if name := sp.settings.Get(guildID).SearchProvider; name != "" {
+		return name
+	}
+	return YouTubeProvider
+}
+
+// SetSearchProvider changes the provider a guild's search queries go to and saves the setting
+func (sp *SimplePlayer) SetSearchProvider(guildID, providerName string) error {
+	provider, err := sp.providers.Get(providerName)
+	if err != nil {
+		return err
+	}
+	if !provider.Capabilities().Search {
+		return fmt.Errorf("%s: %w", provider.GetProviderName(), ErrSearchUnsupported)
+	}
+
+	sp.mu.RLock()
+	store := sp.settings
+	sp.mu.RUnlock()
+
+	name := provider.GetProviderName()
+	if name == YouTubeProvider {
+		name = ""
+	}
+	_, err = store.Update(guildID, func(guild *settings.Guild) { guild.SearchProvider = name })
+	return err
+}
+
+// resolveTrack resolves a link with the highest priority provider that supports it; other queries are
+// searched on YouTube. yt-dlp extractions are scheduled by the guild of the request in ctx.
+func (sp *SimplePlayer) resolveTrack(ctx context.Context, query string) (*types.AudioSource, error) {
+	provider, ok := sp.providers.ForURL(query)
+	if !ok {
+		return sp.extractTrackInfo(ctx, query)
+	}
+	return resolveWithin(ctx, provider, query)
+}
+
+// resolveWithin resolves a query with a provider within currentResolveTimeout. yt-dlp extractions set their own
+// limit once they get a worker, so time spent waiting for one doesn't count.
+func resolveWithin(ctx context.Context, provider types.AudioProvider, query string) (*types.AudioSource, error) {
+	if _, scheduled := provider.(*youtubeProvider); !scheduled {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, currentResolveTimeout())
+		defer cancel()
+	}
+	return provider.GetAudioSource(ctx, query)
+}
+
+// guildContext returns a context carrying a guild, so extractions made for it outside a command are
+// scheduled with the guild's other extractions
+func guildContext(guildID string) context.Context {
+	return utils.WithRequest(context.Background(), utils.RequestInfo{GuildID: guildID})
+}
+
+// SetExtractionLimits changes how many yt-dlp extractions run at once, overall and per guild, and how
+// many may wait per guild
+func (sp *SimplePlayer) SetExtractionLimits(limits providers.Limits) {
+	sp.extractions.SetLimits(limits)
+}
+
+// ExtractionLimits returns the limits yt-dlp extractions run under
+func (sp *SimplePlayer) ExtractionLimits() providers.Limits {
+	return sp.extractions.Limits()
+}
+
+// youtubeConfig runs the yt-dlp binary on PATH and prefers WebM audio, which FFmpeg reads without remuxing
+func youtubeConfig() *ytdlp.ServiceConfig {
+	config := ytdlp.DefaultServiceConfig()
+	config.Format = "bestaudio[ext=webm]/bestaudio"
+	return config
+}
+
+// youtubeProvider resolves links and searches with yt-dlp, which supports YouTube and many other sites
+type youtubeProvider struct {
+	sp *SimplePlayer
+}
+
+func (p *youtubeProvider) GetProviderName() string { return YouTubeProvider }
+
+func (p *youtubeProvider) Capabilities() types.ProviderCapabilities {
+	return types.ProviderCapabilities{Search: true, Playlists: true, Seek: true, Live: true}
+}
+
+func (p *youtubeProvider) SupportsURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
+
+func (p *youtubeProvider) GetAudioSource(ctx context.Context, query string) (*types.AudioSource, error) {
+	return p.sp.extractTrackInfo(ctx, query)
+}
+
+func (p *youtubeProvider) Search(ctx context.Context, query string, maxResults int) ([]types.AudioSource, error) {
+	return p.sp.Search(ctx, query, maxResults)
+}
+
+// directProvider plays links straight to audio files
+type directProvider struct {
+	sp *SimplePlayer
+}
+
+func (p *directProvider) GetProviderName() string { return direct.ProviderName }
+
+func (p *directProvider) Capabilities() types.ProviderCapabilities {
+	return types.ProviderCapabilities{Seek: true}
+}
+
+func (p *directProvider) SupportsURL(url string) bool { return direct.Supports(url) }
+
+func (p *directProvider) GetAudioSource(ctx context.Context, query string) (*types.AudioSource, error) {
+	p.sp.mu.RLock()
+	files := p.sp.files
+	p.sp.mu.RUnlock()
+	return files.Resolve(ctx, query)
+}
+
+func (p *directProvider) Search(ctx context.Context, query string, maxResults int) ([]types.AudioSource, error) {
+	return nil, ErrSearchUnsupported
+}
+
+// radioProvider searches internet radio stations. Stream links aren't recognisable, so links only go
+// here when the provider is picked.
+type radioProvider struct {
+	sp *SimplePlayer
+}
+
+func (p *radioProvider) GetProviderName() string { return radio.ProviderName }
+
+func (p *radioProvider) Capabilities() types.ProviderCapabilities {
+	return types.ProviderCapabilities{Search: true, Live: true}
+}
+
+func (p *radioProvider) SupportsURL(url string) bool { return false }
+
+// GetAudioSource plays a station's stream link, with the station's details when the directory lists it,
+// or the most popular station matching a name
+func (p *radioProvider) GetAudioSource(ctx context.Context, query string) (*types.AudioSource, error) {
+	if !strings.HasPrefix(query, "http://") && !strings.HasPrefix(query, "https://") {
+		stations, err := p.sp.SearchRadio(ctx, query, 1)
+		if err != nil {
+			return nil, err
+		}
+		track := stations[0].Track()
+		return &track, nil
+	}
+
+	p.sp.mu.RLock()
+	client := p.sp.radio
+	p.sp.mu.RUnlock()
+
+	station, err := client.ByURL(ctx, query)
+	if err != nil {
+		station = radio.Station{Name: "Internet radio", URL: query}
+	}
+	track := station.Track()
+	return &track, nil
+}
+
+func (p *radioProvider) Search(ctx context.Context, query string, maxResults int) ([]types.AudioSource, error) {
+	stations, err := p.sp.SearchRadio(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	tracks := make([]types.AudioSource, len(stations))
+	for index, station := range stations {
+		tracks[index] = station.Track()
+	}
+	return tracks, nil
+}
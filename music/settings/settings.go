@@ -0,0 +1,237 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultPath is where guild settings are saved when MUSIC_SETTINGS_FILE is not set
+const DefaultPath = "data/music-settings.json"
+
+// DefaultLanguage is the language of guilds that didn't choose one
+const DefaultLanguage = "en"
+
+// Languages are the languages a guild can choose, by code
+var Languages = map[string]string{
+	"en": "English",
+	"de": "Deutsch",
+	"fr": "Français",
+}
+
+// Guild holds the settings a guild keeps across restarts: the music player's and the server-wide ones
+// /settings changes
+type Guild struct {
+	Loudnorm            bool   `json:"loudnorm,omitempty"`              // EBU R128 loudness normalization
+	FairQueue           bool   `json:"fair_queue,omitempty"`            // Interleave queued tracks by requester
+	StayConnected       bool   `json:"stay_connected,omitempty"`        // 24/7 mode: never leave an empty channel
+	LastChannelID       string `json:"last_channel_id,omitempty"`       // Voice channel 24/7 mode rejoins
+	AloneTimeoutSeconds int    `json:"alone_timeout_seconds,omitempty"` // Wait before leaving an empty channel, 0 for the default
+	IdleTimeoutMinutes  int    `json:"idle_timeout_minutes,omitempty"`  // Leave after nothing played this long, 0 to never
+	SearchProvider      string `json:"search_provider,omitempty"`       // Provider search queries go to, empty for YouTube
+	Volume              int    `json:"volume,omitempty"`                // Playback volume in percent, 0 for the default
+	ChannelStatus       bool   `json:"channel_status,omitempty"`        // Show the current track as the voice channel's status
+	AutoDJ              bool   `json:"auto_dj,omitempty"`               // Refill an empty queue from the guild's listening history
+	VoiceControl        bool   `json:"voice_control,omitempty"`         // Listen in voice for spoken commands after the wake word
+	WakeWord            string `json:"wake_word,omitempty"`             // Starts spoken commands, empty for the default
+	DJIntros            bool   `json:"dj_intros,omitempty"`             // Speak a short intro before each track
+	NowPlayingNickname  bool   `json:"now_playing_nickname,omitempty"`  // Show the current track in the bot's nickname
+	RepeatMode          string `json:"repeat_mode,omitempty"`           // Anti-repeat: "warn" or "refuse" recent tracks, empty for off
+	RepeatWindowHours   int    `json:"repeat_window_hours,omitempty"`   // How long a played track counts as recent
+
+	DJRoleID              string `json:"dj_role_id,omitempty"`              // Role that may moderate the music queue
+	AnnouncementChannelID string `json:"announcement_channel_id,omitempty"` // Channel of now-playing messages, empty to follow requests
+	Language              string `json:"language,omitempty"`                // Language code, empty for DefaultLanguage
+	SummariesDisabled     bool   `json:"summaries_disabled,omitempty"`      // Opted out of /summarize
+}
+
+// DefaultVolume plays tracks at their original volume
+const DefaultVolume = 100
+
+// MaxVolume is the loudest playback volume in percent, louder than that mostly adds clipping
+const MaxVolume = 200
+
+// DefaultAloneTimeout is how long the bot stays in a voice channel after everyone else left
+const DefaultAloneTimeout = 15 * time.Second
+
+// Defaults are what guilds that didn't choose a setting get
+type Defaults struct {
+	Volume       int           // Playback volume in percent, 1 to MaxVolume
+	AloneTimeout time.Duration // Wait before leaving an empty channel
+}
+
+// defaults may change while the bot runs, when its configuration is reloaded
+var defaults atomic.Pointer[Defaults]
+
+func init() {
+	defaults.Store(&Defaults{Volume: DefaultVolume, AloneTimeout: DefaultAloneTimeout})
+}
+
+// SetDefaults changes what guilds that didn't choose a setting get, from their next track or empty channel
+func SetDefaults(d Defaults) {
+	defaults.Store(&d)
+}
+
+// CurrentDefaults returns what guilds that didn't choose a setting get
+func CurrentDefaults() Defaults {
+	return *defaults.Load()
+}
+
+// PlaybackVolume returns the playback volume in percent
+func (g Guild) PlaybackVolume() int {
+	if g.Volume <= 0 {
+		return CurrentDefaults().Volume
+	}
+	return min(g.Volume, MaxVolume)
+}
+
+// AloneTimeout returns how long the bot stays in a voice channel after everyone else left
+func (g Guild) AloneTimeout() time.Duration {
+	if g.AloneTimeoutSeconds <= 0 {
+		return CurrentDefaults().AloneTimeout
+	}
+	return time.Duration(g.AloneTimeoutSeconds) * time.Second
+}
+
+// ResponseLanguage returns the language a guild is answered in
+func (g Guild) ResponseLanguage() string {
+	if _, supported := Languages[g.Language]; !supported {
+		return DefaultLanguage
+	}
+	return g.Language
+}
+
+// IdleTimeout returns how long the bot stays connected with nothing playing, 0 when it never leaves for that
+func (g Guild) IdleTimeout() time.Duration {
+	return time.Duration(max(0, g.IdleTimeoutMinutes)) * time.Minute
+}
+
+// Store keeps per-guild settings in memory and writes them to a JSON file on every change
+type Store struct {
+	path   string
+	mu     sync.RWMutex
+	guilds map[string]Guild
+}
+
+// New creates a store that only lives in memory
+func New() *Store {
+	return &Store{guilds: make(map[string]Guild)}
+}
+
+// Load opens the settings file at path; a missing file starts with no settings
+func Load(path string) (*Store, error) {
+	store := New()
+	store.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read music settings: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.guilds); err != nil {
+		return nil, fmt.Errorf("failed to parse music settings %s: %w", path, err)
+	}
+	if store.guilds == nil {
+		store.guilds = make(map[string]Guild)
+	}
+	return store, nil
+}
+
+// Get returns a guild's settings, the zero value when none were saved
+func (s *Store) Get(guildID string) Guild {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.guilds[guildID]
+}
+
+// Update changes a guild's settings and saves them. Guilds left with default settings are not stored.
+func (s *Store) Update(guildID string, change func(*Guild)) (Guild, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	guild := s.guilds[guildID]
+	change(&guild)
+	if guild == (Guild{}) {
+		delete(s.guilds, guildID)
+	} else {
+		s.guilds[guildID] = guild
+	}
+	return guild, s.save()
+}
+
+// Forget drops a guild's settings and saves the change
+func (s *Store) Forget(guildID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.guilds[guildID]; !exists {
+		return nil
+	}
+	delete(s.guilds, guildID)
+	return s.save()
+}
+
+// Guilds returns the guilds with saved settings
+func (s *Store) Guilds() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	guildIDs := make([]string, 0, len(s.guilds))
+	for guildID := range s.guilds {
+		guildIDs = append(guildIDs, guildID)
+	}
+	return guildIDs
+}
+
+// All returns a copy of every guild's saved settings
+func (s *Store) All() map[string]Guild {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	guilds := make(map[string]Guild, len(s.guilds))
+	for guildID, guild := range s.guilds {
+		guilds[guildID] = guild
+	}
+	return guilds
+}
+
+// Import replaces the settings of the given guilds and saves them once; other guilds are kept
+func (s *Store) Import(guilds map[string]Guild) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for guildID, guild := range guilds {
+		if guild == (Guild{}) {
+			delete(s.guilds, guildID)
+		} else {
+			s.guilds[guildID] = guild
+		}
+	}
+	return s.save()
+}
+
+// save writes the guild settings to the store's file, if it has one (caller holds the lock)
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.guilds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode music settings: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save music settings: %w", err)
+	}
+	return nil
+}
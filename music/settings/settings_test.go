@@ -0,0 +1,132 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorePersistsUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "settings.json")
+
+	store, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Guild{}, store.Get("guild_1"))
+
+	guild, err := store.Update("guild_1", func(g *Guild) { g.Loudnorm = true })
+	require.NoError(t, err)
+	assert.True(t, guild.Loudnorm)
+
+	reloaded, err := Load(path)
+	require.NoError(t, err)
+	assert.True(t, reloaded.Get("guild_1").Loudnorm)
+	assert.Equal(t, []string{"guild_1"}, reloaded.Guilds())
+}
+
+func TestStoreDropsDefaultSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	store, err := Load(path)
+	require.NoError(t, err)
+
+	_, err = store.Update("guild_1", func(g *Guild) { g.Loudnorm = true })
+	require.NoError(t, err)
+	_, err = store.Update("guild_1", func(g *Guild) { g.Loudnorm = false })
+	require.NoError(t, err)
+	assert.Empty(t, store.Guilds())
+
+	_, err = store.Update("guild_2", func(g *Guild) { g.Loudnorm = true })
+	require.NoError(t, err)
+	require.NoError(t, store.Forget("guild_2"))
+
+	reloaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, reloaded.Guilds())
+}
+
+func TestStoreImport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	store, err := Load(path)
+	require.NoError(t, err)
+	_, err = store.Update("guild_1", func(g *Guild) { g.Loudnorm = true })
+	require.NoError(t, err)
+	_, err = store.Update("guild_2", func(g *Guild) { g.FairQueue = true })
+	require.NoError(t, err)
+
+	require.NoError(t, store.Import(map[string]Guild{
+		"guild_2": {},
+		"guild_3": {StayConnected: true, LastChannelID: "voice_1"},
+	}))
+
+	reloaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Guild{
+		"guild_1": {Loudnorm: true},
+		"guild_3": {StayConnected: true, LastChannelID: "voice_1"},
+	}, reloaded.All(), "imported guilds are replaced, default settings dropped and others kept")
+}
+
+func TestGuildTimeouts(t *testing.T) {
+	assert.Equal(t, DefaultAloneTimeout, Guild{}.AloneTimeout())
+	assert.Zero(t, Guild{}.IdleTimeout(), "idle disconnects are off by default")
+
+	guild := Guild{AloneTimeoutSeconds: 120, IdleTimeoutMinutes: 30}
+	assert.Equal(t, 2*time.Minute, guild.AloneTimeout())
+	assert.Equal(t, 30*time.Minute, guild.IdleTimeout())
+}
+
+func TestGuildPlaybackVolume(t *testing.T) {
+	assert.Equal(t, DefaultVolume, Guild{}.PlaybackVolume())
+	assert.Equal(t, 40, Guild{Volume: 40}.PlaybackVolume())
+	assert.Equal(t, MaxVolume, Guild{Volume: 900}.PlaybackVolume())
+}
+
+func TestSetDefaults(t *testing.T) {
+	previous := CurrentDefaults()
+	defer SetDefaults(previous)
+
+	SetDefaults(Defaults{Volume: 80, AloneTimeout: time.Minute})
+	assert.Equal(t, 80, Guild{}.PlaybackVolume())
+	assert.Equal(t, time.Minute, Guild{}.AloneTimeout())
+	assert.Equal(t, 120, Guild{Volume: 120}.PlaybackVolume(), "a guild's own volume wins")
+}
+
+func TestLoadRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o644))
+
+	_, err := Load(path)
+	assert.Error(t, err)
+}
+
+func TestMemoryStoreDoesNotWrite(t *testing.T) {
+	store := New()
+	_, err := store.Update("guild_1", func(g *Guild) { g.Loudnorm = true })
+	require.NoError(t, err)
+	assert.True(t, store.Get("guild_1").Loudnorm)
+}
+
+func TestStorePersistsServerSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	store, err := Load(path)
+	require.NoError(t, err)
+
+	_, err = store.Update("guild1", func(g *Guild) {
+		g.DJRoleID = "role1"
+		g.Language = "de"
+	})
+	require.NoError(t, err)
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, Guild{DJRoleID: "role1", Language: "de"}, loaded.Get("guild1"))
+}
+
+func TestResponseLanguage(t *testing.T) {
+	assert.Equal(t, DefaultLanguage, Guild{}.ResponseLanguage())
+	assert.Equal(t, "fr", Guild{Language: "fr"}.ResponseLanguage())
+	assert.Equal(t, DefaultLanguage, Guild{Language: "xx"}.ResponseLanguage(), "unsupported languages fall back")
+}
@@ -0,0 +1,17 @@
+package music
+
+import "github.com/bwmarrin/discordgo"
+
+// UseShards makes the player join voice and read guild state through the session of the shard each guild is
+// on, as voice joins only work on the guild's own gateway connection. Call it before the bot connects.
+func (sp *SimplePlayer) UseShards(sessionFor func(guildID string) *discordgo.Session) {
+	sp.shardSession = sessionFor
+}
+
+// sessionFor returns the session of the shard a guild is on, the player's session without sharding
+func (sp *SimplePlayer) sessionFor(guildID string) *discordgo.Session {
+	if sp.shardSession == nil {
+		return sp.session
+	}
+	return sp.shardSession(guildID)
+}
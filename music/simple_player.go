@@ -2,14 +2,41 @@ package music
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"pxnx-discord-bot/metrics"
+	"pxnx-discord-bot/music/autodj"
+	"pxnx-discord-bot/music/broadcast"
+	"pxnx-discord-bot/music/direct"
+	"pxnx-discord-bot/music/download"
+	"pxnx-discord-bot/music/filters"
+	"pxnx-discord-bot/music/history"
+	"pxnx-discord-bot/music/lavalink"
+	"pxnx-discord-bot/music/playlist"
+	"pxnx-discord-bot/music/premium"
+	"pxnx-discord-bot/music/prefetch"
+	"pxnx-discord-bot/music/providers"
+	"pxnx-discord-bot/music/queue"
+	"pxnx-discord-bot/music/radio"
+	"pxnx-discord-bot/music/resume"
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/music/speaking"
+	"pxnx-discord-bot/music/stats"
+	"pxnx-discord-bot/music/twitch"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/music/usage"
+	"pxnx-discord-bot/music/voicecontrol"
+	"pxnx-discord-bot/services/ytdlp"
+	"pxnx-discord-bot/tracing"
 	"pxnx-discord-bot/utils"
 )
 
@@ -17,317 +44,1986 @@ import (
 // that replaces the complex DCA-based implementation with direct FFmpeg streaming
 type SimplePlayer struct {
 	session       *discordgo.Session
+	shardSession  func(guildID string) *discordgo.Session // Session of the shard a guild is on, nil with a single session
 	connections   map[string]*VoicePlayer
 	mu            sync.RWMutex
 	disconnectTimers map[string]*time.Timer
+	idleTimers       map[string]*time.Timer // Idle disconnects of guilds with nothing playing
+	voiceServers     map[string]bool        // Guilds whose voice connection got its first voice server
+	searchCache      *utils.LRUCache[string, []types.AudioSource]
+	playTimes        map[string]*history.PlayTimes // When tracks last played, kept across voice sessions for anti-repeat
+	filterChains     map[string]filters.Chain      // Audio filters, kept across voice sessions
+	stats            *stats.Store
+	settings         *settings.Store      // Guild settings that survive restarts
+	trackListener    func(guildID string) // Told when a guild's track, pause state or connection changes
+	failures         *download.Tracker    // Streaming failures per track, shared by all guilds
+	downloader       *download.Downloader // Fetches tracks whose streams keep failing
+	usage            *usage.Meter         // Audio bytes streamed per guild and provider
+	speakers         *speaking.Tracker    // Members talking in the bot's voice channels
+	files            *direct.Resolver     // Checks links straight to audio files
+	radio            *radio.Client        // Searches the internet radio directory
+	twitch           *twitch.Provider     // Twitch channels, videos and clips
+	youtube          *ytdlp.CLIProvider   // yt-dlp run directly for YouTube and other sites, no service needed
+	ytdlpService     atomic.Pointer[ytdlp.FailoverProvider] // yt-dlp service tried before youtube, nil to only run the binary
+	providers        *providers.Registry  // Providers links are routed to, in priority order
+	extractions      *providers.Scheduler // Shares yt-dlp extractions and searches fairly between guilds
+	premium          atomic.Pointer[premium.Entitlements] // Tier limits per guild, read without sp.mu from player code
+	lavalink         *lavalink.Node                       // Node that plays music instead of FFmpeg, nil for the built-in player
+	broadcasts       *broadcast.Registry                  // Guild whose player others listen along to
+	voiceHandler     VoiceHandler                         // Runs spoken commands, nil while voice control is unavailable
+	announcer        Announcer                            // Speaks DJ intros, nil while speech is unavailable
+}
+
+// ErrNotConnected is returned when a guild setting needs the bot to be in a voice channel
+var ErrNotConnected = errors.New("not connected to a voice channel")
+
+// ErrQueueFull is returned when adding tracks would make a queue longer than the guild's tier allows
+var ErrQueueFull = errors.New("the queue is full")
+
+// ErrBandwidthCap is returned when a guild has streamed its monthly bandwidth allowance
+var ErrBandwidthCap = errors.New("this server has used up its monthly music bandwidth")
+
+// RecentlyPlayedError is returned when a guild refuses tracks played within its anti-repeat window
+type RecentlyPlayedError struct {
+	Track    types.AudioSource
+	PlayedAt time.Time
+}
+
+func (e *RecentlyPlayedError) Error() string {
+	return fmt.Sprintf("%s was already played %s ago", e.Track.Title, time.Since(e.PlayedAt).Round(time.Minute))
+}
+
+// Search result cache bounds; results are metadata only, so they stay valid for a while
+const (
+	searchCacheSize = 200
+	searchCacheTTL  = 30 * time.Minute
+)
+
+// playlistPool bounds how many playlists are expanded with yt-dlp at once
+var playlistPool = utils.NewWorkerPool("playlist", 2, 32)
+
+// DefaultResolveTimeout bounds resolving a single track until SetResolveTimeout
+const DefaultResolveTimeout = 30 * time.Second
+
+// resolveTimeout overrides DefaultResolveTimeout when set, in nanoseconds
+var resolveTimeout atomic.Int64
+
+// SetResolveTimeout changes how long resolving a single track may take, from the next track resolved
+func SetResolveTimeout(timeout time.Duration) {
+	resolveTimeout.Store(int64(timeout))
+}
+
+// currentResolveTimeout bounds resolving a single track
+func currentResolveTimeout() time.Duration {
+	if timeout := resolveTimeout.Load(); timeout > 0 {
+		return time.Duration(timeout)
+	}
+	return DefaultResolveTimeout
+}
+
+// AutoDJRequester is shown as the requester of tracks queued by the auto-DJ
+const AutoDJRequester = "Auto-DJ"
+
+// MemoryStats reports the size of the player's per-guild maps and caches
+type MemoryStats struct {
+	Players             int
+	DisconnectTimers    int // Pending disconnects from empty voice channels
+	IdleTimers          int // Pending disconnects with nothing playing
+	PlayTimes           int // Guilds with remembered play times for anti-repeat
+	FilterChains        int // Guilds with audio filters on
+	QueuedTracks        int
+	JournalEntries      int
+	HistoryEntries      int
+	StatsTracks         int
+	SearchCacheEntries  int
+	SearchCacheCapacity int
+}
+
+// TrackRequest describes who queued tracks and whether they go into the priority tier
+type TrackRequest struct {
+	RequestedBy string
+	Priority    bool
+	Trace       tracing.SpanContext // Trace of the command, continued when the track starts
+}
+
+// apply tags a track with the request details
+func (r TrackRequest) apply(track *types.AudioSource) {
+	track.RequestedBy = r.RequestedBy
+	track.Priority = r.Priority
+	track.Trace = r.Trace
 }
 
 // VoicePlayer handles audio playback for a single Discord server
 type VoicePlayer struct {
 	guildID    string
 	conn       *discordgo.VoiceConnection
-	queue      []AudioTrack
-	current    *AudioTrack
+	queue      *queue.SimpleQueue
+	current    *types.AudioSource
 	playing    bool
 	stopChan   chan struct{}
 	skipChan   chan struct{}
 	mu         sync.RWMutex
 	ffmpegCmd  *exec.Cmd
+	resolve    func(query string) (*types.AudioSource, error) // Resolves stream URLs for queued playlist entries
+	forget     func(query string)                             // Drops a cached extraction whose stream URL stopped working
+	provider   func(name string) (types.ProviderCapabilities, bool) // Looks up what a track's provider supports
+	history    *history.History
+	playTimes  *history.PlayTimes // The guild's play times, which outlive this player
+	prefetch   *prefetch.Buffer[*encoder] // Encoder warmed up for the next queued track
+	filters    filters.Chain
+	normalize  bool // EBU R128 loudness normalization
+	volume     int  // Playback volume in percent
+	restart    bool // Re-encode the current track from its position instead of moving on
+	lostTrack  *types.AudioSource // Track that was playing when the voice connection dropped
+	lostAt     time.Duration      // Position of lostTrack when the voice connection dropped
+	stats      *stats.Store
+	refill     func() []types.AudioSource // Supplies auto-DJ tracks when the queue runs dry
+	started    time.Time     // When the current encoder started sending audio
+	offset     time.Duration // Track position the current encoder started at
+	speed      float64       // Playback speed of the current encoder's filters
+	pauseChan  chan struct{} // Closed on resume, nil while not paused
+	pausedAt   time.Time
+	notify     func() // Reports track and pause changes to the player's listener
+	failures   *download.Tracker
+	downloader *download.Downloader
+	usage      *usage.Meter
+	limits     func() premium.Limits // The guild's tier limits, looked up when they apply
+	remote     *lavalink.Player      // Lavalink player that streams instead of conn, nil for the built-in player
+	joinAt     time.Time             // When the broadcast source started the next track, it starts that far in
+	listener   *voicecontrol.Listener // Voice control on conn, nil when the guild doesn't use it
+	introduce  func(ctx context.Context, track types.AudioSource) (string, error) // Speaks a track's DJ intro, an empty path without one
+}
+
+// NewSimplePlayer creates a new simplified music player
+func NewSimplePlayer(session *discordgo.Session) *SimplePlayer {
+	sp := &SimplePlayer{
+		session:          session,
+		connections:      make(map[string]*VoicePlayer),
+		disconnectTimers: make(map[string]*time.Timer),
+		idleTimers:       make(map[string]*time.Timer),
+		voiceServers:     make(map[string]bool),
+		searchCache:      utils.NewLRUCache[string, []types.AudioSource](searchCacheSize, searchCacheTTL),
+		playTimes:        make(map[string]*history.PlayTimes),
+		filterChains:     make(map[string]filters.Chain),
+		stats:            stats.NewStore(stats.DefaultTracksPerGuild),
+		settings:         settings.New(),
+		failures:         download.NewTracker(download.DefaultThreshold),
+		downloader:       download.NewDownloader(download.DefaultDir()),
+		usage:            usage.NewMeter(0),
+		speakers:         speaking.NewTracker(),
+		files:            direct.NewResolver(0),
+		radio:            radio.NewClient(""),
+		twitch:           twitch.NewProvider(),
+		youtube:          ytdlp.NewCLIProvider(youtubeConfig()),
+		broadcasts:       broadcast.NewRegistry(),
+		extractions:      providers.NewScheduler(providers.DefaultLimits()),
+	}
+	sp.providers = newProviderRegistry(sp)
+	sp.premium.Store(premium.New())
+	sp.downloader.UseExtraArgs(sp.youtube.ExtraArgs)
+	return sp
+}
+
+// UsePremium replaces the default entitlements, which give every guild premium, with ones that enforce tiers
+func (sp *SimplePlayer) UsePremium(entitlements *premium.Entitlements) {
+	sp.premium.Store(entitlements)
+}
+
+// Premium returns the entitlements that decide each guild's tier
+func (sp *SimplePlayer) Premium() *premium.Entitlements {
+	return sp.premium.Load()
+}
+
+// Limits returns what a guild's tier allows
+func (sp *SimplePlayer) Limits(guildID string) premium.Limits {
+	return sp.Premium().Limits(guildID)
+}
+
+// UseSettings replaces the in-memory guild settings with a store that is saved to disk
+func (sp *SimplePlayer) UseSettings(store *settings.Store) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.settings = store
+}
+
+// Settings returns the settings a guild keeps across restarts
+func (sp *SimplePlayer) Settings(guildID string) settings.Guild {
+	return sp.settings.Get(guildID)
+}
+
+// JoinChannel connects to a voice channel
+func (sp *SimplePlayer) JoinChannel(guildID, channelID string) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	// Check if already connected
+	if player, exists := sp.connections[guildID]; exists {
+		if player.channelID() == channelID {
+			return nil // Already connected to the same channel
+		}
+		// A Lavalink node follows the bot to the new channel and keeps playing
+		if player.remote != nil {
+			sp.speakers.Clear(guildID)
+			if _, err := sp.joinRemote(guildID, channelID); err != nil {
+				return fmt.Errorf("failed to join voice channel: %w", err)
+			}
+			sp.rememberStayChannel(guildID, channelID)
+			return nil
+		}
+		// Disconnect from current channel
+		player.stopListening()
+		if player.conn != nil {
+			player.conn.Disconnect()
+		}
+		sp.speakers.Clear(guildID)
+	}
+
+	// The voice server Discord assigns to the new connection is not a move
+	delete(sp.voiceServers, guildID)
+
+	var conn *discordgo.VoiceConnection
+	var listener *voicecontrol.Listener
+	var remote *lavalink.Player
+	var err error
+	if sp.lavalink != nil {
+		remote, err = sp.joinRemote(guildID, channelID)
+		if err != nil {
+			return fmt.Errorf("failed to join voice channel: %w", err)
+		}
+	} else if conn, listener, err = sp.joinVoice(guildID, channelID); err != nil {
+		return err
+	}
+
+	// Create voice player
+	player := &VoicePlayer{
+		guildID:   guildID,
+		conn:      conn,
+		listener:  listener,
+		remote:    remote,
+		queue:     queue.NewQueue(),
+		stopChan:  make(chan struct{}),
+		skipChan:  make(chan struct{}),
+		resolve:   func(query string) (*types.AudioSource, error) { return sp.resolveTrack(guildContext(guildID), query) },
+		history:   history.New(history.DefaultSize),
+		playTimes: sp.guildPlayTimes(guildID),
+		prefetch:  prefetch.New(func(enc *encoder) { enc.close(); sp.downloader.Remove(enc.localFile) }),
+		filters:   sp.filterChains[guildID],
+		normalize: sp.settings.Get(guildID).Loudnorm,
+		volume:    sp.settings.Get(guildID).PlaybackVolume(),
+		stats:     sp.stats,
+	}
+	player.failures, player.downloader, player.usage = sp.failures, sp.downloader, sp.usage
+	player.queue.SetFair(sp.settings.Get(guildID).FairQueue)
+	player.limits = func() premium.Limits { return sp.Limits(guildID) }
+	player.refill = func() []types.AudioSource { return sp.autoDJTracks(guildID) }
+	player.notify = func() { sp.notifyTrackChange(guildID) }
+	player.introduce = func(ctx context.Context, track types.AudioSource) (string, error) {
+		return sp.introduce(ctx, guildID, track)
+	}
+	player.forget = func(query string) { sp.youtube.Cache().Forget(query) }
+	player.provider = sp.ProviderCapabilities
+
+	sp.connections[guildID] = player
+
+	// A player that was joined but never given anything to play counts as idle
+	utils.SafeGo("music.idleTimer", func() { sp.updateIdleTimer(guildID) })
+
+	sp.rememberStayChannel(guildID, channelID)
+	return nil
+}
+
+// joinVoice opens the bot's own voice connection to a channel and waits for it to be ready. Guilds using
+// voice control get a listener on it, the bot is deafened in the others.
+func (sp *SimplePlayer) joinVoice(guildID, channelID string) (*discordgo.VoiceConnection, *voicecontrol.Listener, error) {
+	conn, err := sp.sessionFor(guildID).ChannelVoiceJoin(guildID, channelID, false, !sp.listening(guildID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to join voice channel: %w", err)
+	}
+
+	// Wait for connection to be ready
+	for i := 0; i < 50; i++ { // 5 second timeout
+		if conn.Ready {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if !conn.Ready {
+		conn.Disconnect()
+		return nil, nil, fmt.Errorf("voice connection timeout")
+	}
+	listener := sp.listen(guildID, conn)
+	conn.AddHandler(func(_ *discordgo.VoiceConnection, update *discordgo.VoiceSpeakingUpdate) {
+		sp.speakers.Update(guildID, update.UserID, update.Speaking)
+		if listener != nil {
+			listener.SetSpeaker(uint32(update.SSRC), update.UserID)
+		}
+	})
+	return conn, listener, nil
+}
+
+// rememberStayChannel saves the channel 24/7 mode follows the bot to, whichever it was last asked to join
+// (caller holds sp.mu)
+func (sp *SimplePlayer) rememberStayChannel(guildID, channelID string) {
+	if guild := sp.settings.Get(guildID); guild.StayConnected && guild.LastChannelID != channelID {
+		if _, err := sp.settings.Update(guildID, func(guild *settings.Guild) { guild.LastChannelID = channelID }); err != nil {
+			utils.LogWarn("Failed to save the 24/7 channel of guild %s: %v", guildID, err)
+		}
+	}
+}
+
+// LeaveChannel disconnects from voice channel
+func (sp *SimplePlayer) LeaveChannel(guildID string) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	player, exists := sp.connections[guildID]
+	if !exists {
+		return nil
+	}
+
+	// Stop current playback
+	player.Stop()
+	sp.forgetBroadcast(guildID)
+
+	// Disconnect voice connection
+	player.stopListening()
+	if player.conn != nil {
+		player.conn.Disconnect()
+	}
+	if player.remote != nil {
+		sp.leaveRemote(guildID, player.remote)
+	}
+
+	// Remove from connections, history only lasts for the session while play times stay for anti-repeat
+	player.history.Clear()
+	delete(sp.connections, guildID)
+
+	// A pending auto-disconnect has nothing left to do
+	if timer, exists := sp.disconnectTimers[guildID]; exists {
+		timer.Stop()
+		delete(sp.disconnectTimers, guildID)
+	}
+	if timer, exists := sp.idleTimers[guildID]; exists {
+		timer.Stop()
+		delete(sp.idleTimers, guildID)
+	}
+	sp.speakers.Clear(guildID)
+	delete(sp.voiceServers, guildID)
+
+	sp.notifyTrackChange(guildID)
+	return nil
+}
+
+// SetTrackListener registers a function called whenever a guild starts a track, pauses, resumes,
+// runs out of tracks or disconnects. It runs in its own goroutine, so it should read the current
+// player state rather than assume the order of calls.
+func (sp *SimplePlayer) SetTrackListener(listener func(guildID string)) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.trackListener = listener
+}
+
+// notifyTrackChange calls the track listener in the background, safe to call with sp.mu held
+func (sp *SimplePlayer) notifyTrackChange(guildID string) {
+	utils.SafeGo("music.trackListener", func() {
+		sp.updateIdleTimer(guildID)
+		sp.syncBroadcast(guildID)
+
+		sp.mu.RLock()
+		listener := sp.trackListener
+		sp.mu.RUnlock()
+
+		if listener != nil {
+			listener(guildID)
+		}
+	})
+}
+
+// CleanupGuild releases the player, queue, timers and cached state held for a guild. Its saved settings
+// are kept, so a server that invites the bot back finds them as it left them.
+func (sp *SimplePlayer) CleanupGuild(guildID string) {
+	if err := sp.LeaveChannel(guildID); err != nil {
+		utils.LogWarn("Failed to leave voice channel during cleanup of guild %s: %v", guildID, err)
+	}
+
+	sp.mu.Lock()
+	delete(sp.playTimes, guildID)
+	delete(sp.filterChains, guildID)
+	sp.mu.Unlock()
+
+	sp.stats.Forget(guildID)
+	sp.Premium().Forget(guildID)
+}
+
+// Loudnorm reports whether loudness normalization is on for a guild
+func (sp *SimplePlayer) Loudnorm(guildID string) bool {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.settings.Get(guildID).Loudnorm
+}
+
+// SetLoudnorm turns loudness normalization on or off for a guild and saves the setting.
+// A playing track is re-encoded from its current position.
+func (sp *SimplePlayer) SetLoudnorm(guildID string, enabled bool) error {
+	sp.mu.RLock()
+	store := sp.settings
+	player := sp.connections[guildID]
+	sp.mu.RUnlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.Loudnorm = enabled })
+	if player != nil {
+		player.applyLoudnorm(enabled)
+	}
+	return err
+}
+
+// Volume returns a guild's playback volume in percent
+func (sp *SimplePlayer) Volume(guildID string) int {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.settings.Get(guildID).PlaybackVolume()
+}
+
+// SetVolume changes a guild's playback volume in percent and saves it. A playing track changes volume
+// from its current position.
+func (sp *SimplePlayer) SetVolume(guildID string, volume int) error {
+	if volume < 1 || volume > settings.MaxVolume {
+		return fmt.Errorf("volume must be between 1 and %d%%", settings.MaxVolume)
+	}
+
+	sp.mu.RLock()
+	store := sp.settings
+	player := sp.connections[guildID]
+	sp.mu.RUnlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) {
+		guild.Volume = volume
+		if volume == settings.CurrentDefaults().Volume {
+			guild.Volume = 0 // Guilds with default settings aren't saved
+		}
+	})
+	if player != nil {
+		player.applyVolume(volume)
+	}
+	return err
+}
+
+// Filters returns the audio filters enabled for a guild
+func (sp *SimplePlayer) Filters(guildID string) filters.Chain {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.filterChains[guildID]
+}
+
+// ToggleFilter turns an audio filter on or off for a guild and reports whether it is now enabled.
+// A playing track is re-encoded from its current position with the new filters.
+func (sp *SimplePlayer) ToggleFilter(guildID, name string) (filters.Chain, bool, error) {
+	sp.mu.Lock()
+	chain, enabled, err := sp.filterChains[guildID].Toggle(name)
+	if err != nil {
+		sp.mu.Unlock()
+		return nil, false, err
+	}
+	if enabled && !sp.Limits(guildID).Filters {
+		sp.mu.Unlock()
+		return nil, false, &premium.RequiredError{Feature: premium.FeatureFilters}
+	}
+	sp.setFilterChain(guildID, chain)
+	player := sp.connections[guildID]
+	sp.mu.Unlock()
+
+	if player != nil {
+		player.applyFilters(chain)
+	}
+	return chain, enabled, nil
+}
+
+// ClearFilters turns off all audio filters for a guild
+func (sp *SimplePlayer) ClearFilters(guildID string) {
+	sp.mu.Lock()
+	sp.setFilterChain(guildID, nil)
+	player := sp.connections[guildID]
+	sp.mu.Unlock()
+
+	if player != nil {
+		player.applyFilters(nil)
+	}
+}
+
+// setFilterChain stores a guild's chain, dropping empty ones (caller holds the lock)
+func (sp *SimplePlayer) setFilterChain(guildID string, chain filters.Chain) {
+	if len(chain) == 0 {
+		delete(sp.filterChains, guildID)
+		return
+	}
+	sp.filterChains[guildID] = chain
+}
+
+// SetAutoDJ turns the auto-DJ on or off for a guild and saves the setting. Turning it on while nothing
+// plays starts a rotation.
+func (sp *SimplePlayer) SetAutoDJ(guildID string, enabled bool) error {
+	sp.mu.RLock()
+	store := sp.settings
+	player := sp.connections[guildID]
+	sp.mu.RUnlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.AutoDJ = enabled })
+	if enabled && player != nil && !player.IsPlaying() {
+		utils.SafeGo("music.autoDJ", player.startRefill)
+	}
+	return err
+}
+
+// AutoDJ reports whether the auto-DJ is on for a guild
+func (sp *SimplePlayer) AutoDJ(guildID string) bool {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.settings.Get(guildID).AutoDJ
+}
+
+// Stats returns a guild's listening statistics, most played first
+func (sp *SimplePlayer) Stats(guildID string) []stats.TrackStats {
+	return sp.stats.Tracks(guildID)
+}
+
+// MostSkipped returns up to n tracks a guild skipped early, most skips first
+func (sp *SimplePlayer) MostSkipped(guildID string, n int) []stats.TrackStats {
+	return sp.stats.MostSkipped(guildID, n)
+}
+
+// UseMetadataCache replaces the in-memory cache of yt-dlp extractions, such as with one saved to disk
+func (sp *SimplePlayer) UseMetadataCache(cache *ytdlp.MetadataCache) {
+	sp.youtube.UseCache(cache)
+}
+
+// MetadataCache returns the cache of yt-dlp extractions
+func (sp *SimplePlayer) MetadataCache() *ytdlp.MetadataCache {
+	return sp.youtube.Cache()
+}
+
+// UseYtdlpService resolves and searches through the yt-dlp service at config, retrying failed requests
+// as retry allows, with the binary taking over while the service is unavailable. Playlists are still
+// listed with the binary.
+func (sp *SimplePlayer) UseYtdlpService(config *ytdlp.ServiceConfig, retry *ytdlp.RetryConfig) *ytdlp.FailoverProvider {
+	client := ytdlp.NewResilientClientWithConfigs(config, ytdlp.DefaultCircuitBreakerConfig(), retry)
+	service := ytdlp.NewServiceProvider(client, youtubeConfig().Format)
+	failover := ytdlp.NewFailoverProvider(service, sp.youtube)
+	sp.ytdlpService.Store(failover)
+	return failover
+}
+
+// YtdlpCircuit returns the state and counters of the yt-dlp service's circuit breaker, false when
+// extractions don't go through a service
+func (sp *SimplePlayer) YtdlpCircuit() (ytdlp.CircuitBreakerMetrics, bool) {
+	service := sp.ytdlpService.Load()
+	if service == nil {
+		return ytdlp.CircuitBreakerMetrics{}, false
+	}
+	return service.Circuit(), true
+}
+
+// ResetYtdlpCircuit closes the yt-dlp service's circuit breaker and returns the state it was in, false
+// when extractions don't go through a service
+func (sp *SimplePlayer) ResetYtdlpCircuit() (ytdlp.CircuitBreakerState, bool) {
+	service := sp.ytdlpService.Load()
+	if service == nil {
+		return ytdlp.StateClosed, false
+	}
+	return service.ResetCircuit(), true
+}
+
+// extractor returns what YouTube tracks are resolved and searched with
+func (sp *SimplePlayer) extractor() types.AudioProvider {
+	if service := sp.ytdlpService.Load(); service != nil {
+		return service
+	}
+	return sp.youtube
+}
+
+// UseYtdlpCredentials replaces the cookies and PO token yt-dlp runs with for extractions and downloads
+func (sp *SimplePlayer) UseYtdlpCredentials(credentials ytdlp.Credentials) {
+	sp.youtube.UseCredentials(credentials)
+}
+
+// YtdlpCredentials returns the cookies and PO token yt-dlp runs with
+func (sp *SimplePlayer) YtdlpCredentials() ytdlp.Credentials {
+	return sp.youtube.Credentials()
+}
+
+// UseYtdlpProxies makes yt-dlp extractions and downloads take turns between the given proxies
+func (sp *SimplePlayer) UseYtdlpProxies(proxies []string) {
+	sp.youtube.UseProxies(proxies...)
+}
+
+// PlaysToday returns how many tracks all guilds played today, in UTC
+func (sp *SimplePlayer) PlaysToday() int {
+	return sp.stats.PlaysToday(time.Now())
+}
+
+// autoDJTracks builds a rotation from the guild's most played tracks and related recommendations,
+// leaving out tracks from the session history and the anti-repeat window
+func (sp *SimplePlayer) autoDJTracks(guildID string) []types.AudioSource {
+	sp.mu.RLock()
+	guildSettings := sp.settings.Get(guildID)
+	player := sp.connections[guildID]
+	sp.mu.RUnlock()
+	enabled, policy := guildSettings.AutoDJ, repeatPolicy(guildSettings)
+
+	if !enabled || player == nil {
+		return nil
+	}
+
+	played := sp.stats.Tracks(guildID)
+	var related []types.AudioSource
+	for i := 0; i < len(played) && i < autodj.SeedTracks; i++ {
+		related = append(related, sp.relatedTracks(played[i].Track.URL)...)
+	}
+
+	recent := make(map[string]bool)
+	for _, entry := range player.history.Entries() {
+		recent[entry.Track.URL] = true
+	}
+
+	now := time.Now()
+	exclude := func(url string) bool {
+		if recent[url] {
+			return true
+		}
+		_, repeat := policy.Check(player.playTimes, url, now)
+		return repeat
+	}
+
+	rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	tracks := autodj.Pick(autodj.Candidates(played, related, now), autodj.RotationSize, rng, exclude)
+	utils.LogInfo("Auto-DJ picked %d tracks for guild %s from %d played and %d related", len(tracks), guildID, len(played), len(related))
+	return tracks
+}
+
+// relatedTracks lists recommendations for a track from its YouTube mix, sharing the search cache
+func (sp *SimplePlayer) relatedTracks(trackURL string) []types.AudioSource {
+	mixURL, ok := autodj.MixURL(trackURL)
+	if !ok {
+		return nil
+	}
+
+	cacheKey := "mix:" + mixURL
+	if cached, ok := sp.searchCache.Get(cacheKey); ok {
+		return cached
+	}
+
+	tracks, err := sp.runFlatExtraction(context.Background(), mixURL, autodj.RotationSize)
+	if err != nil {
+		utils.LogWarn("Failed to fetch related tracks for %s: %v", trackURL, err)
+		return nil
+	}
+
+	sp.searchCache.Add(cacheKey, tracks)
+	return tracks
+}
+
+// SetRepeatPolicy sets a guild's anti-repeat setting and saves it
+func (sp *SimplePlayer) SetRepeatPolicy(guildID string, policy history.RepeatPolicy) error {
+	sp.mu.RLock()
+	store := sp.settings
+	sp.mu.RUnlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) {
+		guild.RepeatMode, guild.RepeatWindowHours = "", 0
+		if policy.Enabled() {
+			guild.RepeatMode, guild.RepeatWindowHours = string(policy.Mode), int(policy.Window/time.Hour)
+		}
+	})
+	return err
+}
+
+// RepeatPolicy returns a guild's anti-repeat setting
+func (sp *SimplePlayer) RepeatPolicy(guildID string) history.RepeatPolicy {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return repeatPolicy(sp.settings.Get(guildID))
+}
+
+// repeatPolicy reads the anti-repeat setting out of a guild's settings
+func repeatPolicy(guild settings.Guild) history.RepeatPolicy {
+	policy := history.RepeatPolicy{
+		Mode:   history.RepeatMode(guild.RepeatMode),
+		Window: time.Duration(guild.RepeatWindowHours) * time.Hour,
+	}
+	if !policy.Enabled() {
+		return history.RepeatPolicy{Mode: history.RepeatAllow}
+	}
+	return policy
+}
+
+// guildPlayTimes returns when a guild's tracks last played, creating the record on first use.
+// It must be called with sp.mu held for writing.
+func (sp *SimplePlayer) guildPlayTimes(guildID string) *history.PlayTimes {
+	played, exists := sp.playTimes[guildID]
+	if !exists {
+		played = history.NewPlayTimes()
+		sp.playTimes[guildID] = played
+	}
+	return played
+}
+
+// CheckRepeat returns when a track was last played if queueing it counts as a repeat under the guild's
+// setting. Plays from earlier voice sessions count too.
+func (sp *SimplePlayer) CheckRepeat(guildID string, track types.AudioSource) (time.Time, bool) {
+	sp.mu.RLock()
+	played := sp.playTimes[guildID]
+	policy := repeatPolicy(sp.settings.Get(guildID))
+	sp.mu.RUnlock()
+
+	return policy.Check(played, track.URL, time.Now())
+}
+
+// MemoryStats returns the current sizes of the player's maps and caches
+func (sp *SimplePlayer) MemoryStats() MemoryStats {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	stats := MemoryStats{
+		Players:             len(sp.connections),
+		DisconnectTimers:    len(sp.disconnectTimers),
+		IdleTimers:          len(sp.idleTimers),
+		PlayTimes:           len(sp.playTimes),
+		FilterChains:        len(sp.filterChains),
+		StatsTracks:         sp.stats.Len(),
+		SearchCacheEntries:  sp.searchCache.Len(),
+		SearchCacheCapacity: sp.searchCache.Cap(),
+	}
+	for _, player := range sp.connections {
+		stats.QueuedTracks += player.queue.Size()
+		stats.JournalEntries += len(player.queue.Journal())
+		stats.HistoryEntries += player.history.Len()
+	}
+	return stats
+}
+
+// GuildIDs returns the guilds that currently hold a player or a pending disconnect timer
+func (sp *SimplePlayer) GuildIDs() []string {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	seen := make(map[string]bool, len(sp.connections)+len(sp.disconnectTimers))
+	for guildID := range sp.connections {
+		seen[guildID] = true
+	}
+	for guildID := range sp.disconnectTimers {
+		seen[guildID] = true
+	}
+	for guildID := range sp.idleTimers {
+		seen[guildID] = true
+	}
+	for guildID := range sp.playTimes {
+		seen[guildID] = true
+	}
+	for guildID := range sp.filterChains {
+		seen[guildID] = true
+	}
+	for _, guildID := range sp.stats.Guilds() {
+		seen[guildID] = true
+	}
+
+	guildIDs := make([]string, 0, len(seen))
+	for guildID := range seen {
+		guildIDs = append(guildIDs, guildID)
+	}
+	return guildIDs
+}
+
+// CurrentTracks returns the track playing in each guild, paused players are left out
+func (sp *SimplePlayer) CurrentTracks() map[string]types.AudioSource {
+	sp.mu.RLock()
+	players := make(map[string]*VoicePlayer, len(sp.connections))
+	for guildID, player := range sp.connections {
+		players[guildID] = player
+	}
+	sp.mu.RUnlock()
+
+	tracks := make(map[string]types.AudioSource)
+	for guildID, player := range players {
+		if player.IsPaused() {
+			continue
+		}
+		if track := player.GetCurrent(); track != nil {
+			tracks[guildID] = *track
+		}
+	}
+	return tracks
+}
+
+// StayConnected reports whether a guild is in 24/7 mode
+func (sp *SimplePlayer) StayConnected(guildID string) bool {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.settings.Get(guildID).StayConnected
+}
+
+// SetStayConnected turns 24/7 mode on or off for a guild and saves the setting. In 24/7 mode the bot
+// stays in its voice channel when everyone leaves and rejoins it after restarts and gateway reconnects.
+// Turning it on needs the bot to be in a voice channel, which becomes the channel it rejoins.
+func (sp *SimplePlayer) SetStayConnected(guildID string, enabled bool) error {
+	sp.mu.Lock()
+	store := sp.settings
+	channelID := ""
+	if player, exists := sp.connections[guildID]; exists {
+		channelID = player.channelID()
+	}
+	if enabled && !sp.Limits(guildID).StayConnected {
+		sp.mu.Unlock()
+		return &premium.RequiredError{Feature: premium.FeatureStayConnected}
+	}
+	if enabled && channelID == "" {
+		sp.mu.Unlock()
+		return ErrNotConnected
+	}
+	if timer, exists := sp.disconnectTimers[guildID]; exists && enabled {
+		timer.Stop()
+		delete(sp.disconnectTimers, guildID)
+	}
+	sp.mu.Unlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) {
+		guild.StayConnected = enabled
+		guild.LastChannelID = ""
+		if enabled {
+			guild.LastChannelID = channelID
+		}
+	})
+	return err
+}
+
+// RejoinStayConnected connects guilds in 24/7 mode back to their channel when the bot has no voice
+// connection there, such as after a restart or a gateway reconnect that dropped the connection
+func (sp *SimplePlayer) RejoinStayConnected() {
+	sp.mu.RLock()
+	store := sp.settings
+	sp.mu.RUnlock()
+
+	for _, guildID := range store.Guilds() {
+		guild := store.Get(guildID)
+		if !guild.StayConnected || guild.LastChannelID == "" || !sp.Limits(guildID).StayConnected {
+			continue
+		}
+
+		sp.mu.RLock()
+		player, exists := sp.connections[guildID]
+		connected := exists && player.voiceReady() && player.channelID() == guild.LastChannelID
+		sp.mu.RUnlock()
+		if connected {
+			continue
+		}
+
+		if exists {
+			// A dead connection is replaced, so the player starts over in the same channel
+			if err := sp.LeaveChannel(guildID); err != nil {
+				utils.LogWarn("Failed to drop the stale voice connection of guild %s: %v", guildID, err)
+			}
+		}
+		if err := sp.JoinChannel(guildID, guild.LastChannelID); err != nil {
+			utils.LogWarn("Failed to rejoin voice channel %s in guild %s for 24/7 mode: %v", guild.LastChannelID, guildID, err)
+			continue
+		}
+		utils.LogInfo("Rejoined voice channel %s in guild %s for 24/7 mode", guild.LastChannelID, guildID)
+	}
+}
+
+// FairQueue reports whether a guild's queue interleaves tracks by requester
+func (sp *SimplePlayer) FairQueue(guildID string) bool {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.settings.Get(guildID).FairQueue
+}
+
+// SetFairQueue turns fair queue mode on or off for a guild and saves the setting.
+// Turning it on reorders the tracks already queued (see queue.SimpleQueue.SetFair).
+func (sp *SimplePlayer) SetFairQueue(guildID string, enabled bool) error {
+	sp.mu.RLock()
+	store := sp.settings
+	player := sp.connections[guildID]
+	sp.mu.RUnlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.FairQueue = enabled })
+	if player != nil {
+		player.queue.SetFair(enabled)
+	}
+	return err
+}
+
+// ChannelStatus reports whether a guild shows the current track as its voice channel's status
+func (sp *SimplePlayer) ChannelStatus(guildID string) bool {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.settings.Get(guildID).ChannelStatus
+}
+
+// SetChannelStatus turns the voice channel status for a guild on or off and saves the setting. The
+// track listener is told, so the status is set or cleared right away.
+func (sp *SimplePlayer) SetChannelStatus(guildID string, enabled bool) error {
+	sp.mu.RLock()
+	store := sp.settings
+	sp.mu.RUnlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.ChannelStatus = enabled })
+	sp.notifyTrackChange(guildID)
+	return err
+}
+
+// NowPlayingNickname reports whether a guild shows the current track in the bot's nickname
+func (sp *SimplePlayer) NowPlayingNickname(guildID string) bool {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.settings.Get(guildID).NowPlayingNickname
+}
+
+// SetNowPlayingNickname turns the now playing nickname for a guild on or off and saves the setting. The
+// track listener is told, so the nickname changes or is reverted right away.
+func (sp *SimplePlayer) SetNowPlayingNickname(guildID string, enabled bool) error {
+	sp.mu.RLock()
+	store := sp.settings
+	sp.mu.RUnlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.NowPlayingNickname = enabled })
+	sp.notifyTrackChange(guildID)
+	return err
+}
+
+// UseUsageMeter replaces the in-memory traffic counters, such as with ones saved to disk. Players keep
+// the meter they were created with, so call it before guilds join voice channels.
+func (sp *SimplePlayer) UseUsageMeter(meter *usage.Meter) {
+	sp.usage = meter
+}
+
+// SaveUsage saves the traffic counted since the counters were last saved
+func (sp *SimplePlayer) SaveUsage() error {
+	return sp.usage.Save()
+}
+
+// SetBandwidthCap limits how many bytes each guild may stream per calendar month; 0 removes the limit
+func (sp *SimplePlayer) SetBandwidthCap(monthlyBytes int64) {
+	sp.usage.SetMonthlyCap(monthlyBytes)
+}
+
+// Usage reports the audio bytes streamed per guild and provider
+func (sp *SimplePlayer) Usage() usage.Report {
+	return sp.usage.Report()
+}
+
+// GuildUsage returns what a guild streamed this month
+func (sp *SimplePlayer) GuildUsage(guildID string) int64 {
+	return sp.usage.GuildBytes(guildID)
+}
+
+// Play adds a track to the queue and starts playback if not already playing
+func (sp *SimplePlayer) Play(guildID string, query string, request TrackRequest) (*types.AudioSource, error) {
+	sp.mu.RLock()
+	player, exists := sp.connections[guildID]
+	sp.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("not connected to voice channel")
+	}
+	if sp.usage.Exceeded(guildID) {
+		return nil, ErrBandwidthCap
+	}
+	if sp.broadcasts.Following(guildID) {
+		return nil, ErrListeningAlong
+	}
+
+	// Extract track information using yt-dlp
+	track, err := sp.resolveTrack(guildContext(guildID), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract track info: %w", err)
+	}
+	request.apply(track)
+
+	if playedAt, repeat := sp.CheckRepeat(guildID, *track); repeat && sp.RepeatPolicy(guildID).Mode == history.RepeatRefuse {
+		return nil, &RecentlyPlayedError{Track: *track, PlayedAt: playedAt}
+	}
+
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	if err := checkQueueLimit(player, sp.Limits(guildID), 1); err != nil {
+		return nil, err
+	}
+
+	// Add to queue
+	player.queue.Add(*track)
+
+	// Start playback if not already playing, otherwise warm up the next track
+	if !player.playing {
+		utils.SafeGo("music.playNext", player.playNext)
+	} else {
+		player.prefetchNext()
+	}
+
+	return track, nil
+}
+
+// Enqueue adds several tracks to the queue at once and starts playback if not already playing
+func (sp *SimplePlayer) Enqueue(guildID string, tracks []types.AudioSource, request TrackRequest) error {
+	sp.mu.RLock()
+	player, exists := sp.connections[guildID]
+	sp.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("not connected to voice channel")
+	}
+	if sp.usage.Exceeded(guildID) {
+		return ErrBandwidthCap
+	}
+	if sp.broadcasts.Following(guildID) {
+		return ErrListeningAlong
+	}
+
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	if err := checkQueueLimit(player, sp.Limits(guildID), len(tracks)); err != nil {
+		return err
+	}
+
+	for _, track := range tracks {
+		request.apply(&track)
+		player.queue.Add(track)
+	}
+
+	if !player.playing && len(tracks) > 0 {
+		utils.SafeGo("music.playNext", player.playNext)
+	} else if len(tracks) > 0 {
+		player.prefetchNext()
+	}
+
+	return nil
+}
+
+// checkQueueLimit refuses adding tracks beyond the queue length the guild's tier allows (caller holds player.mu)
+func checkQueueLimit(player *VoicePlayer, limits premium.Limits, adding int) error {
+	if limits.MaxQueue > 0 && player.queue.Size()+adding > limits.MaxQueue {
+		return fmt.Errorf("%w, this server's queue holds up to %d songs", ErrQueueFull, limits.MaxQueue)
+	}
+	return nil
+}
+
+// Resolve extracts track information for a query without queueing it
+func (sp *SimplePlayer) Resolve(query string) (*types.AudioSource, error) {
+	track, err := sp.resolveTrack(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract track info: %w", err)
+	}
+	return track, nil
+}
+
+// Replay re-enqueues the nth most recently played track (1 is the latest) and returns it
+func (sp *SimplePlayer) Replay(guildID string, n int, request TrackRequest) (*types.AudioSource, error) {
+	sp.mu.RLock()
+	player, exists := sp.connections[guildID]
+	sp.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("not connected to voice channel")
+	}
+
+	entry, err := player.history.Get(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sp.Enqueue(guildID, []types.AudioSource{entry.Track}, request); err != nil {
+		return nil, err
+	}
+	request.apply(&entry.Track)
+	return &entry.Track, nil
+}
+
+// GetPlaylist uses yt-dlp flat-playlist extraction to list up to limit entries of a playlist.
+// Entries carry metadata only; stream URLs are resolved when each track starts playing.
+func (sp *SimplePlayer) GetPlaylist(ctx context.Context, playlistURL string, limit int) ([]types.AudioSource, error) {
+	limit = playlist.ClampLimit(limit)
+	utils.LogInfo("Starting yt-dlp playlist extraction for %s (limit %d)", playlistURL, limit)
+
+	// Large playlists keep yt-dlp busy for a while, so only a few are expanded at once
+	var tracks []types.AudioSource
+	var err error
+	if poolErr := playlistPool.Do(ctx, func() { tracks, err = sp.runFlatExtraction(ctx, playlistURL, limit) }); poolErr != nil {
+		return nil, fmt.Errorf("failed to load playlist: %w", poolErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, fmt.Errorf("playlist has no playable videos")
+	}
+
+	utils.LogInfo("Extracted %d playlist entries from %s", len(tracks), playlistURL)
+	return tracks, nil
+}
+
+// Search returns up to maxResults YouTube search results without resolving stream URLs
+func (sp *SimplePlayer) Search(ctx context.Context, query string, maxResults int) ([]types.AudioSource, error) {
+	cacheKey := fmt.Sprintf("%d:%s", maxResults, strings.ToLower(strings.TrimSpace(query)))
+	if cached, ok := sp.searchCache.Get(cacheKey); ok {
+		utils.LogDebug("Search cache hit for query: %s", query)
+		return cached, nil
+	}
+
+	utils.LogInfo("Starting yt-dlp search for query: %s", query)
+
+	// Waiting for a worker doesn't count towards the search's time limit
+	var results []types.AudioSource
+	err := sp.extractions.Do(ctx, func() (err error) {
+		ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		defer cancel()
+		start := time.Now()
+		results, err = sp.extractor().Search(ctx, query, maxResults)
+		metrics.ExtractionDuration.Observe(metrics.Since(start), "search", metrics.OutcomeOf(err))
+		return err
+	})
+	if err != nil {
+		utils.LogErrorContext(ctx, "yt-dlp search failed: %v", err)
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no results found for %q", query)
+	}
+
+	sp.searchCache.Add(cacheKey, results)
+	return results, nil
+}
+
+// runFlatExtraction lists entries of a playlist or search with yt-dlp without extracting each video
+func (sp *SimplePlayer) runFlatExtraction(ctx context.Context, target string, limit int) ([]types.AudioSource, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	tracks, err := sp.youtube.Playlist(ctx, target, limit)
+	if err != nil {
+		utils.LogErrorContext(ctx, "yt-dlp flat extraction failed: %v", err)
+		return nil, err
+	}
+	return tracks, nil
+}
+
+// SetMaxFileSize limits the size of audio files played from direct links; 0 restores the default
+func (sp *SimplePlayer) SetMaxFileSize(bytes int64) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.files = direct.NewResolver(bytes)
+}
+
+// MaxFileSize returns the largest audio file played from a direct link
+func (sp *SimplePlayer) MaxFileSize() int64 {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.files.MaxSize()
+}
+
+// extractTrackInfo uses yt-dlp to extract track information and stream URL
+func (sp *SimplePlayer) extractTrackInfo(ctx context.Context, query string) (*types.AudioSource, error) {
+	utils.LogInfo("Starting yt-dlp extraction for query: %s", query)
+
+	// Waiting for a worker doesn't count towards the extraction's time limit
+	var track *types.AudioSource
+	err := sp.extractions.Do(ctx, func() (err error) {
+		ctx, cancel := context.WithTimeout(ctx, currentResolveTimeout())
+		defer cancel()
+		// The gap before this span in a trace is the time spent waiting for a worker
+		ctx, span := tracing.Start(ctx, "ytdlp.extract", tracing.KindInternal, "query", query)
+		defer span.End()
+		start := time.Now()
+		track, err = sp.extractor().GetAudioSource(ctx, query)
+		metrics.ExtractionDuration.Observe(metrics.Since(start), "extract", metrics.OutcomeOf(err))
+		span.RecordError(err)
+		return err
+	})
+	if err != nil {
+		utils.LogErrorContext(ctx, "yt-dlp extraction failed for %s: %v", query, err)
+		return nil, err
+	}
+
+	utils.LogDebug("yt-dlp stream URL: %s", utils.ScrubURL(track.StreamURL))
+	utils.LogInfo("Successfully extracted track: %s by %s (%s)", track.Title, track.Uploader, track.Duration)
+	return track, nil
+}
+
+// packetLogInterval is how many audio packets pass between debug log lines of the send loop, about 20 seconds
+const packetLogInterval = 1000
+
+// liveReconnectMinimum is how long a live stream must have played before a drop is reconnected
+const liveReconnectMinimum = 10 * time.Second
+
+// streamRefreshMargin is how long a stream URL must still work for a track to start with it. FFmpeg keeps
+// the connection it opened, so the URL only has to work when playback starts.
+const streamRefreshMargin = 2 * time.Minute
+
+// stderrTailSize is how much of FFmpeg's error output is kept to tell why it failed
+const stderrTailSize = 4096
+
+var (
+	// errStreamFailed marks playback that broke off before the track's end without a stop or skip
+	errStreamFailed = errors.New("stream failed")
+
+	// errStreamForbidden marks a stream the CDN refused with 403 Forbidden, usually because its URL expired
+	errStreamForbidden = errors.New("stream URL refused")
+)
+
+// encoder is a started FFmpeg process producing Opus audio for one track
+type encoder struct {
+	track     types.AudioSource // Track with its stream URL resolved
+	localFile string            // Downloaded copy the encoder reads instead of the stream, if any
+	offset    time.Duration     // Position in the track the encoder started at
+	speed     float64           // Playback speed of the filter chain, to map elapsed time to track position
+	cmd       *exec.Cmd
+	stdout    io.ReadCloser
+	stderr    *tailBuffer // End of FFmpeg's error output
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// close stops an encoder that will not be played and waits for FFmpeg to exit
+func (e *encoder) close() {
+	e.cancel()
+	e.cmd.Wait()
+}
+
+// tailBuffer keeps the last bytes written to it
+type tailBuffer struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if excess := len(b.data) - stderrTailSize; excess > 0 {
+		b.data = b.data[excess:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
+
+// streamExpiring reports whether a track's stream URL stops working too soon to start playing it
+func streamExpiring(track types.AudioSource, now time.Time) bool {
+	return !track.StreamExpires.IsZero() && !now.Add(streamRefreshMargin).Before(track.StreamExpires)
+}
+
+// prefetchKey identifies a queued track for matching it with a warmed encoder
+func prefetchKey(track types.AudioSource) string {
+	if track.URL != "" {
+		return track.URL
+	}
+	return track.StreamURL
+}
+
+// playNext plays the next track in the queue
+func (vp *VoicePlayer) playNext() {
+	// A guild over its bandwidth cap finishes the current track but starts no new one; the queue is kept
+	if vp.usage.Exceeded(vp.guildID) {
+		utils.LogWarn("Guild %s reached its monthly bandwidth cap, holding the queue", vp.guildID)
+		vp.mu.Lock()
+		vp.playing = false
+		vp.current = nil
+		vp.clearPause()
+		vp.mu.Unlock()
+		vp.prefetch.Discard()
+		vp.notifyChange()
+		return
+	}
+
+	vp.mu.Lock()
+	track, ok := vp.queue.Next()
+	if !ok {
+		// Only a track that played hands over to the auto-DJ, never a stop or a failed track
+		continuing := vp.playing && vp.current != nil
+		vp.playing = false
+		vp.clearPause()
+		vp.mu.Unlock()
+
+		if continuing {
+			vp.startRefill()
+		}
+		if !vp.IsPlaying() {
+			vp.notifyChange()
+		}
+		return
+	}
+
+	vp.current = track
+	vp.playing = true
+	vp.started = time.Time{}
+	joinAt := vp.joinAt
+	vp.joinAt = time.Time{}
+	vp.mu.Unlock()
+
+	// Playback errors are logged with the server and track as fields
+	logCtx := utils.WithLogFields(utils.WithRequest(context.Background(), utils.RequestInfo{GuildID: vp.guildID}), "track", track.Title)
+
+	// The DJ intro plays first, a track joined from a broadcast is already under way
+	if joinAt.IsZero() && !vp.announce(logCtx, *track) {
+		utils.SafeGo("music.playNext", vp.playNext)
+		return
+	}
+
+	// Starting the track continues the trace of the command that queued it, until the encoder is ready
+	startCtx, startSpan := tracing.Start(tracing.ContextWithSpanContext(logCtx, track.Trace), "playback.start",
+		tracing.KindInternal, "guild_id", vp.guildID, "provider", track.Provider)
+
+	// A Lavalink node fetches and encodes the track itself
+	if vp.remote != nil {
+		startSpan.SetAttributes("backend", "lavalink")
+		startSpan.End()
+		vp.playRemote(track)
+		utils.SafeGo("music.playNext", vp.playNext)
+		return
+	}
+
+	// Use the encoder warmed up while the previous track played, or start one now. A track joined
+	// from a broadcast starts where the source is, which the warmed encoder doesn't.
+	var enc *encoder
+	warmed := false
+	if joinAt.IsZero() {
+		enc, warmed = vp.prefetch.Take(prefetchKey(*track))
+	}
+	startSpan.SetAttributes("prefetched", warmed)
+	if !warmed {
+		var err error
+		enc, err = vp.prepareTrack(startCtx, *track, joinAt)
+		startSpan.RecordError(err)
+		if err != nil {
+			startSpan.End()
+			utils.LogErrorContext(logCtx, "Failed to prepare track %s: %v", track.Title, err)
+			metrics.PlaybackErrors.Inc("prepare")
+			vp.mu.Lock()
+			vp.current = nil
+			vp.mu.Unlock()
+			utils.SafeGo("music.playNext", vp.playNext)
+			return
+		}
+	}
+
+	startSpan.End()
+
+	// The prepared track carries the resolved stream URL and thumbnail
+	vp.mu.Lock()
+	*track = enc.track
+	vp.mu.Unlock()
+
+	// Skipped tracks still count as played
+	vp.history.Add(*track)
+	vp.playTimes.Record(*track, time.Now())
+	vp.stats.RecordPlay(vp.guildID, *track, time.Now())
+	vp.notifyChange()
+
+	// Warm up the following track so it starts without a gap
+	vp.prefetchNext()
+
+	// A downloaded copy stays until the track is done, filter restarts and recoveries read it too
+	localFile := enc.localFile
+	var refreshes resume.Budget
+	for {
+		elapsed, err := vp.playEncoder(enc)
+		if err != nil {
+			utils.LogErrorContext(logCtx, "Failed to play track %s: %v", track.Title, err)
+			metrics.PlaybackErrors.Inc("play")
+		}
+		position := enc.offset + time.Duration(float64(elapsed)*enc.speed)
+
+		if resumeAt, restart := vp.takeRestart(position); restart {
+			// Filters changed or the voice connection moved, continue the same track from where it was.
+			// Live streams can't seek, they pick the broadcast up where it is now.
+			if !vp.seekable(*track) {
+				resumeAt = 0
+			}
+			chain, normalize, volume := vp.audioFilters()
+			enc, err = startEncoder(*track, localFile, resumeAt, chain, normalize, volume, vp.tierLimits().Bitrate)
+			if err != nil {
+				utils.LogErrorContext(logCtx, "Failed to restart track %s: %v", track.Title, err)
+				metrics.PlaybackErrors.Inc("restart")
+				break
+			}
+			continue
+		}
+		// Stop kills FFmpeg directly, which can look like a failed stream
+		if !errors.Is(err, errStreamFailed) || !vp.IsPlaying() {
+			break
+		}
+
+		switch {
+		case track.Live:
+			enc, err = vp.reconnectLive(*track, elapsed)
+		case errors.Is(err, errStreamForbidden) && localFile == "" && refreshable(*track) && refreshes.Allow(position):
+			// An expired URL is resolved again without counting as a broken stream, the track
+			// continues where it was refused
+			enc, err = vp.refreshStream(*track, position)
+			if err == nil {
+				vp.mu.Lock()
+				*track = enc.track
+				vp.mu.Unlock()
+				break
+			}
+			utils.LogWarnContext(logCtx, "Failed to refresh the stream URL of %s, recovering the stream instead: %v", track.Title, err)
+			enc, err = vp.recoverStream(*track, &localFile, position)
+		default:
+			enc, err = vp.recoverStream(*track, &localFile, position)
+		}
+		if err != nil {
+			utils.LogErrorContext(logCtx, "Failed to recover track %s: %v", track.Title, err)
+			metrics.PlaybackErrors.Inc("recover")
+			break
+		}
+	}
+	vp.downloader.Remove(localFile)
+
+	// Continue with next track
+	utils.SafeGo("music.playNext", vp.playNext)
 }
 
-// AudioTrack represents a playable audio track
-type AudioTrack struct {
-	Title     string `json:"title"`
-	URL       string `json:"url"`
-	Duration  string `json:"duration"`
-	Uploader  string `json:"uploader"`
-	Thumbnail string `json:"thumbnail"`
+// startRefill queues an auto-DJ rotation and starts playing it if the player is still idle
+func (vp *VoicePlayer) startRefill() {
+	if vp.refill == nil {
+		return
+	}
+
+	tracks := vp.refill()
+	if len(tracks) == 0 {
+		return
+	}
+
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	// Someone queued a track while the rotation was being built
+	if vp.playing || vp.queue.Size() > 0 {
+		return
+	}
+	for _, track := range tracks {
+		track.RequestedBy = AutoDJRequester
+		vp.queue.Add(track)
+	}
+	vp.playing = true
+	utils.SafeGo("music.playNext", vp.playNext)
 }
 
-// NewSimplePlayer creates a new simplified music player
-func NewSimplePlayer(session *discordgo.Session) *SimplePlayer {
-	return &SimplePlayer{
-		session:          session,
-		connections:      make(map[string]*VoicePlayer),
-		disconnectTimers: make(map[string]*time.Timer),
+// prefetchNext resolves and starts the encoder for the first queued track in the background
+func (vp *VoicePlayer) prefetchNext() {
+	if vp.remote != nil {
+		return // The node streams tracks as they start
+	}
+	next, err := vp.queue.Get(0)
+	if err != nil {
+		vp.prefetch.Discard()
+		return
 	}
+
+	track := *next
+	vp.prefetch.Start(prefetchKey(track), func(ctx context.Context) (*encoder, error) {
+		return vp.prepareTrack(ctx, track, time.Time{})
+	})
 }
 
-// JoinChannel connects to a voice channel
-func (sp *SimplePlayer) JoinChannel(guildID, channelID string) error {
-	sp.mu.Lock()
-	defer sp.mu.Unlock()
+// refreshPrefetch re-targets the warmed encoder after the queue order changed during playback
+func (vp *VoicePlayer) refreshPrefetch() {
+	vp.mu.RLock()
+	playing := vp.playing
+	vp.mu.RUnlock()
 
-	// Check if already connected
-	if player, exists := sp.connections[guildID]; exists {
-		if player.conn != nil && player.conn.ChannelID == channelID {
-			return nil // Already connected to the same channel
+	if playing {
+		vp.prefetchNext()
+	}
+}
+
+// prepareTrack resolves the stream URL if needed and starts an FFmpeg encoder for the track, from the
+// beginning or, when startedAt is set, as far in as the time since then
+func (vp *VoicePlayer) prepareTrack(ctx context.Context, track types.AudioSource, startedAt time.Time) (*encoder, error) {
+	// Playlist entries are queued without a stream URL, and tracks that waited in a long queue may have
+	// one that is about to expire; resolve a fresh one now
+	if track.StreamURL == "" || (streamExpiring(track, time.Now()) && refreshable(track)) {
+		if track.StreamURL != "" {
+			utils.LogDebug("Stream URL of %s expires at %s, resolving it again", track.Title, track.StreamExpires.Format(time.RFC3339))
 		}
-		// Disconnect from current channel
-		if player.conn != nil {
-			player.conn.Disconnect()
+		resolved, err := vp.resolve(track.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve stream: %w", err)
+		}
+		track.StreamURL, track.StreamExpires = resolved.StreamURL, resolved.StreamExpires
+		if track.Thumbnail == "" {
+			track.Thumbnail = resolved.Thumbnail
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Tracks whose streams keep breaking off are played from a local copy, live streams never end
+	var localFile string
+	if !track.Live && vp.failures.ShouldDownload(prefetchKey(track)) {
+		path, err := vp.downloader.Download(ctx, downloadURL(track))
+		if err != nil {
+			utils.LogWarn("Failed to download %s, streaming it instead: %v", track.Title, err)
+		} else {
+			localFile = path
 		}
 	}
 
-	// Connect to voice channel
-	conn, err := sp.session.ChannelVoiceJoin(guildID, channelID, false, true)
+	// Resolving takes a while, so the offset is only worked out now
+	var offset time.Duration
+	if !startedAt.IsZero() && vp.seekable(track) {
+		offset = time.Since(startedAt)
+	}
+
+	chain, normalize, volume := vp.audioFilters()
+	enc, err := startEncoder(track, localFile, offset, chain, normalize, volume, vp.tierLimits().Bitrate)
 	if err != nil {
-		return fmt.Errorf("failed to join voice channel: %w", err)
+		vp.downloader.Remove(localFile)
+		return nil, err
 	}
+	return enc, nil
+}
 
-	// Wait for connection to be ready
-	for i := 0; i < 50; i++ { // 5 second timeout
-		if conn.Ready {
-			break
+// recoverStream continues a track whose stream broke off at position. Streams are retried with a fresh
+// URL until the track has failed download.DefaultThreshold times, then it is downloaded and played from
+// the local file, which is stored in localFile.
+func (vp *VoicePlayer) recoverStream(track types.AudioSource, localFile *string, position time.Duration) (*encoder, error) {
+	if *localFile != "" {
+		return nil, fmt.Errorf("playback of the downloaded file failed")
+	}
+
+	key := prefetchKey(track)
+	failures := vp.failures.RecordFailure(key)
+
+	// A stop or skip while recovering abandons the track
+	ctx, cancel := vp.controlContext()
+	defer cancel()
+
+	if vp.failures.ShouldDownload(key) {
+		utils.LogInfo("Stream of %s failed %d times, downloading it", track.Title, failures)
+		path, err := vp.downloader.Download(ctx, downloadURL(track))
+		if err != nil {
+			return nil, fmt.Errorf("failed to download track: %w", err)
+		}
+		*localFile = path
+	} else {
+		utils.LogWarn("Stream of %s broke off at %s, reconnecting", track.Title, position.Round(time.Second))
+		// Stream URLs expire, resolve a fresh one
+		if track.URL != "" {
+			vp.forget(track.URL)
+			if resolved, err := vp.resolve(track.URL); err == nil {
+				track.StreamURL, track.StreamExpires = resolved.StreamURL, resolved.StreamExpires
+			}
 		}
-		time.Sleep(100 * time.Millisecond)
 	}
 
-	if !conn.Ready {
-		conn.Disconnect()
-		return fmt.Errorf("voice connection timeout")
+	if err := ctx.Err(); err != nil {
+		vp.downloader.Remove(*localFile)
+		*localFile = ""
+		return nil, err
 	}
+	chain, normalize, volume := vp.audioFilters()
+	return startEncoder(track, *localFile, position, chain, normalize, volume, vp.tierLimits().Bitrate)
+}
 
-	// Create voice player
-	player := &VoicePlayer{
-		guildID:  guildID,
-		conn:     conn,
-		queue:    make([]AudioTrack, 0),
-		stopChan: make(chan struct{}),
-		skipChan: make(chan struct{}),
+// seekable reports whether a track can be started part of the way in. Live streams can't, and neither can
+// tracks of providers that only play from the start; tracks of unknown providers are tried.
+func (vp *VoicePlayer) seekable(track types.AudioSource) bool {
+	if track.Live {
+		return false
+	}
+	if vp.provider == nil {
+		return true
 	}
+	capabilities, ok := vp.provider(track.Provider)
+	return !ok || capabilities.Seek
+}
 
-	sp.connections[guildID] = player
-	return nil
+// refreshable reports whether a track's stream URL can be resolved again from its page
+func refreshable(track types.AudioSource) bool {
+	return track.URL != "" && track.URL != track.StreamURL
 }
 
-// LeaveChannel disconnects from voice channel
-func (sp *SimplePlayer) LeaveChannel(guildID string) error {
-	sp.mu.Lock()
-	defer sp.mu.Unlock()
+// refreshStream continues a track at position with a freshly resolved stream URL, after the CDN refused
+// the old one. The cached extraction is dropped so it isn't handed out again.
+func (vp *VoicePlayer) refreshStream(track types.AudioSource, position time.Duration) (*encoder, error) {
+	utils.LogWarn("Stream URL of %s was refused, resolving it again", track.Title)
+	vp.forget(track.URL)
 
-	player, exists := sp.connections[guildID]
-	if !exists {
-		return nil
+	resolved, err := vp.resolve(track.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh stream URL: %w", err)
 	}
+	track.StreamURL, track.StreamExpires = resolved.StreamURL, resolved.StreamExpires
 
-	// Stop current playback
-	player.Stop()
+	chain, normalize, volume := vp.audioFilters()
+	return startEncoder(track, "", position, chain, normalize, volume, vp.tierLimits().Bitrate)
+}
 
-	// Disconnect voice connection
-	if player.conn != nil {
-		player.conn.Disconnect()
+// reconnectLive rejoins a live stream that dropped after playing for elapsed. Streams that drop again
+// right away are given up on, the station is most likely off the air.
+func (vp *VoicePlayer) reconnectLive(track types.AudioSource, elapsed time.Duration) (*encoder, error) {
+	if elapsed < liveReconnectMinimum {
+		return nil, fmt.Errorf("live stream stopped after %s", elapsed.Round(time.Second))
 	}
 
-	// Remove from connections
-	delete(sp.connections, guildID)
-	return nil
+	utils.LogWarn("Live stream %s dropped after %s, reconnecting", track.Title, elapsed.Round(time.Second))
+	// Streams behind a page, such as Twitch broadcasts, get a fresh stream URL and fail once they are over
+	if refreshable(track) {
+		resolved, err := vp.resolve(track.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rejoin live stream: %w", err)
+		}
+		track.StreamURL = resolved.StreamURL
+	}
+
+	chain, normalize, volume := vp.audioFilters()
+	return startEncoder(track, "", 0, chain, normalize, volume, vp.tierLimits().Bitrate)
 }
 
-// Play adds a track to the queue and starts playback if not already playing
-func (sp *SimplePlayer) Play(guildID string, query string) (*AudioTrack, error) {
-	sp.mu.RLock()
-	player, exists := sp.connections[guildID]
-	sp.mu.RUnlock()
+// controlContext returns a context that is cancelled by the next stop or skip
+func (vp *VoicePlayer) controlContext() (context.Context, context.CancelFunc) {
+	vp.mu.RLock()
+	stopChan, skipChan := vp.stopChan, vp.skipChan
+	vp.mu.RUnlock()
 
-	if !exists {
-		return nil, fmt.Errorf("not connected to voice channel")
+	ctx, cancel := context.WithCancel(context.Background())
+	utils.SafeGo("music.controlContext", func() {
+		select {
+		case <-stopChan:
+			cancel()
+		case <-skipChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	})
+	return ctx, cancel
+}
+
+// downloadURL is the address yt-dlp downloads a track from, its page when known
+func downloadURL(track types.AudioSource) string {
+	if track.URL != "" {
+		return track.URL
 	}
+	return track.StreamURL
+}
 
-	// Extract track information using yt-dlp
-	track, err := sp.extractTrackInfo(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to extract track info: %w", err)
+// startEncoder starts FFmpeg for a track from offset through the filter chain and optional loudness
+// normalization at bitrate kbps, reading localFile instead of the stream when set; audio is buffered
+// in the pipe until it is read
+func startEncoder(track types.AudioSource, localFile string, offset time.Duration, chain filters.Chain, normalize bool, volume, bitrate int) (*encoder, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	input := track.StreamURL
+	if localFile != "" {
+		input = localFile
 	}
 
-	player.mu.Lock()
-	defer player.mu.Unlock()
+	// Enhanced FFmpeg command with Opus output for Discord
+	cmd := exec.CommandContext(ctx, "ffmpeg", filters.EncoderArgs(input, offset, chain, normalize, volume, bitrate)...)
 
-	// Add to queue
-	player.queue = append(player.queue, *track)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderr := &tailBuffer{}
+	cmd.Stderr = stderr
 
-	// Start playback if not already playing
-	if !player.playing {
-		go player.playNext()
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
 	}
 
-	return track, nil
+	return &encoder{
+		track:     track,
+		localFile: localFile,
+		offset:    offset,
+		speed:     chain.Speed(),
+		cmd:       cmd,
+		stdout:    stdout,
+		stderr:    stderr,
+		ctx:       ctx,
+		cancel:    cancel,
+	}, nil
 }
 
-// extractTrackInfo uses yt-dlp to extract track information and stream URL
-func (sp *SimplePlayer) extractTrackInfo(query string) (*AudioTrack, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	utils.LogInfo("Starting yt-dlp extraction for query: %s", query)
+// playEncoder streams an encoder's audio to Discord until it finishes, is skipped or stopped,
+// and returns how long it played
+func (vp *VoicePlayer) playEncoder(enc *encoder) (time.Duration, error) {
+	defer enc.cancel()
 
-	// Use yt-dlp to extract information with correct syntax
-	cmd := exec.CommandContext(ctx, "yt-dlp",
-		"--default-search", "ytsearch",
-		"--format", "bestaudio[ext=webm]/bestaudio",
-		"--print", "title",
-		"--print", "url",
-		"--print", "duration",
-		"--print", "thumbnail",
-		"--print", "uploader",
-		"--no-download",
-		query,
-	)
-
-	utils.LogDebug("Running yt-dlp command: %v", cmd.Args)
-
-	// Capture both stdout and stderr for better debugging
-	var stdout, stderr strings.Builder
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
+	// Start speaking
+	err := vp.conn.Speaking(true)
 	if err != nil {
-		utils.LogError("yt-dlp command failed with exit code: %v", err)
-		utils.LogError("yt-dlp stderr: %s", stderr.String())
-		utils.LogError("yt-dlp stdout: %s", stdout.String())
+		return 0, fmt.Errorf("failed to start speaking: %w", err)
+	}
+	defer vp.conn.Speaking(false)
+
+	vp.mu.Lock()
+	vp.ffmpegCmd = enc.cmd
+	vp.started, vp.offset, vp.speed = time.Now(), enc.offset, enc.speed
+	if vp.pauseChan != nil {
+		// Paused before this encoder started, nothing has played yet
+		vp.pausedAt = vp.started
+	}
+	stopChan, skipChan := vp.stopChan, vp.skipChan
+	vp.mu.Unlock()
 
-		// Check if yt-dlp is installed
-		if _, lookupErr := exec.LookPath("yt-dlp"); lookupErr != nil {
-			return nil, fmt.Errorf("yt-dlp not found in PATH - please install yt-dlp: %w", lookupErr)
+	// Stop and skip cancel the encoder, which ends the read loop below
+	done := make(chan struct{})
+	defer close(done)
+	utils.SafeGo("music.playbackControl", func() {
+		select {
+		case <-stopChan:
+			enc.cancel()
+		case <-skipChan:
+			enc.cancel()
+		case <-done:
+		}
+	})
+
+	// Every byte sent is counted towards the guild's bandwidth
+	provider := enc.track.Provider
+	reader := usage.NewReader(enc.stdout, func(n int64) { vp.usage.Add(vp.guildID, provider, n) })
+
+	// Create a buffer for Opus audio data
+	buffer := make([]byte, 4096) // Buffer for Opus packets
+	started := time.Now()
+	var paused time.Duration
+	var sent, dropped int
+	packetLog := utils.NewSampler(packetLogInterval)
+
+	for {
+		// Hold the encoder output while paused, FFmpeg blocks on the full pipe
+		if resume := vp.pauseSignal(); resume != nil {
+			vp.conn.Speaking(false)
+			pausedFrom := time.Now()
+			select {
+			case <-resume:
+			case <-enc.ctx.Done():
+			}
+			paused += time.Since(pausedFrom)
+			vp.conn.Speaking(true)
 		}
 
-		return nil, fmt.Errorf("yt-dlp extraction failed: %w (stderr: %s)", err, stderr.String())
+		n, err := reader.Read(buffer)
+		if n > 0 {
+			// Send Opus audio data to Discord voice connection
+			select {
+			case vp.conn.OpusSend <- buffer[:n]:
+				sent++
+			case <-time.After(time.Millisecond * 100):
+				// Drop frame if channel is full
+				dropped++
+			}
+			// Runs for every packet, so it logs a sample and checks the level before formatting
+			if packetLog.Allow() && utils.LogEnabled(utils.LogLevelDebug) {
+				utils.LogDebug("Guild %s: sent %d audio packets, dropped %d", vp.guildID, sent, dropped)
+			}
+		}
+		if err != nil {
+			if err != io.EOF && enc.ctx.Err() == nil {
+				utils.LogError("Error reading audio data: %v", err)
+			}
+			break
+		}
 	}
 
-	output := stdout.String()
-	utils.LogDebug("yt-dlp output: %s", output)
+	elapsed := time.Since(started) - paused
+	interrupted := enc.ctx.Err() != nil
 
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-	utils.LogDebug("yt-dlp output parsed into %d lines", len(lines))
+	// All output has been read, wait for FFmpeg to exit
+	err = enc.cmd.Wait()
+	forbidden := enc.stderr != nil && resume.Forbidden(enc.stderr.String())
+	if err != nil && !interrupted {
+		if forbidden {
+			return elapsed, fmt.Errorf("ffmpeg process failed: %w: %w: %w", errStreamFailed, errStreamForbidden, err)
+		}
+		return elapsed, fmt.Errorf("ffmpeg process failed: %w: %w", errStreamFailed, err)
+	}
 
-	for i, line := range lines {
-		utils.LogDebug("Line %d: %s", i, line)
+	// Streams that drop mid-track usually end with a clean EOF long before the track's end,
+	// live streams only end when they drop
+	position := enc.offset + time.Duration(float64(elapsed)*enc.speed)
+	if !interrupted && enc.track.Live {
+		return elapsed, fmt.Errorf("%w: live stream ended after %s", errStreamFailed, elapsed.Round(time.Second))
+	}
+	if !interrupted && download.EndedEarly(position, enc.track.Duration) {
+		// FFmpeg gives up quietly when reconnecting to an expired URL is refused
+		if forbidden {
+			return elapsed, fmt.Errorf("%w: %w at %s of %s", errStreamFailed, errStreamForbidden, position.Round(time.Second), enc.track.Duration)
+		}
+		return elapsed, fmt.Errorf("%w: ended at %s of %s", errStreamFailed, position.Round(time.Second), enc.track.Duration)
 	}
 
-	if len(lines) < 5 {
-		utils.LogError("Invalid yt-dlp output: expected 5 lines, got %d", len(lines))
-		return nil, fmt.Errorf("invalid yt-dlp output: expected 5 lines, got %d. Output was: %s", len(lines), output)
+	return elapsed, nil
+}
+
+// audioFilters returns the filter chain, loudness normalization and volume new encoders are built with
+func (vp *VoicePlayer) audioFilters() (filters.Chain, bool, int) {
+	vp.mu.RLock()
+	defer vp.mu.RUnlock()
+
+	// Filters stay saved but are not applied while the guild's tier doesn't include them
+	if !vp.tierLimits().Filters {
+		return nil, vp.normalize, vp.volume
 	}
+	return vp.filters, vp.normalize, vp.volume
+}
 
-	track := &AudioTrack{
-		Title:     lines[0],
-		URL:       lines[1],
-		Duration:  lines[2],
-		Thumbnail: lines[3],
-		Uploader:  lines[4],
+// tierLimits returns the guild's tier limits, the premium ones for players created outside JoinChannel
+func (vp *VoicePlayer) tierLimits() premium.Limits {
+	if vp.limits == nil {
+		return premium.LimitsFor(premium.Premium)
 	}
+	return vp.limits()
+}
 
-	utils.LogInfo("Successfully extracted track: %s by %s (%s)", track.Title, track.Uploader, track.Duration)
-	return track, nil
+// applyFilters switches the filter chain, re-encoding the current track and the prefetched next one
+func (vp *VoicePlayer) applyFilters(chain filters.Chain) {
+	vp.reencode(func() { vp.filters = chain })
 }
 
-// playNext plays the next track in the queue
-func (vp *VoicePlayer) playNext() {
-	vp.mu.Lock()
-	if len(vp.queue) == 0 {
-		vp.playing = false
+// applyLoudnorm switches loudness normalization, re-encoding the current track and the prefetched next one
+func (vp *VoicePlayer) applyLoudnorm(enabled bool) {
+	vp.reencode(func() { vp.normalize = enabled })
+}
+
+// applyVolume changes the playback volume. A Lavalink node changes it right away, the built-in player
+// re-encodes the current track and the prefetched next one.
+func (vp *VoicePlayer) applyVolume(volume int) {
+	if vp.remote != nil {
+		vp.mu.Lock()
+		vp.volume = volume
 		vp.mu.Unlock()
+		if err := vp.remote.SetVolume(volume); err != nil {
+			utils.LogWarn("Failed to change the Lavalink volume of guild %s: %v", vp.guildID, err)
+		}
 		return
 	}
+	vp.reencode(func() { vp.volume = volume })
+}
 
-	track := vp.queue[0]
-	vp.queue = vp.queue[1:]
-	vp.current = &track
-	vp.playing = true
+// reencode applies a change to the encoder settings and restarts the current track from its position
+func (vp *VoicePlayer) reencode(change func()) {
+	vp.mu.Lock()
+	change()
+	if vp.playing {
+		vp.restart = true
+		close(vp.skipChan)
+		vp.skipChan = make(chan struct{})
+	}
 	vp.mu.Unlock()
 
-	// Play the track
-	err := vp.playTrack(track)
-	if err != nil {
-		utils.LogError("Failed to play track %s: %v", track.Title, err)
-	}
+	// The warmed encoder used the old settings
+	vp.prefetch.Discard()
+	vp.refreshPrefetch()
+}
 
-	// Continue with next track
-	go vp.playNext()
+// Position returns how far into the current track playback is
+func (vp *VoicePlayer) Position() time.Duration {
+	vp.mu.RLock()
+	defer vp.mu.RUnlock()
+	return vp.position()
 }
 
-// playTrack streams audio using FFmpeg directly to Discord
-func (vp *VoicePlayer) playTrack(track AudioTrack) error {
-	// Start speaking
-	err := vp.conn.Speaking(true)
-	if err != nil {
-		return fmt.Errorf("failed to start speaking: %w", err)
+// position computes the playback position (caller holds the lock)
+func (vp *VoicePlayer) position() time.Duration {
+	if !vp.playing || vp.started.IsZero() {
+		return 0
 	}
-	defer vp.conn.Speaking(false)
 
-	// Create FFmpeg command for direct streaming
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	now := time.Now()
+	if vp.pauseChan != nil {
+		now = vp.pausedAt
+	}
+	return vp.offset + time.Duration(float64(now.Sub(vp.started))*vp.speed)
+}
 
-	// Enhanced FFmpeg command with Opus output for Discord
-	vp.ffmpegCmd = exec.CommandContext(ctx, "ffmpeg",
-		"-reconnect", "1",
-		"-reconnect_streamed", "1",
-		"-reconnect_delay_max", "2",
-		"-i", track.URL,
-		"-f", "opus",
-		"-ar", "48000",
-		"-ac", "2",
-		"-b:a", "128k",
-		"-vn",
-		"pipe:1",
-	)
-
-	stdout, err := vp.ffmpegCmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+// Pause holds playback of the current track and reports whether it was playing
+func (vp *VoicePlayer) Pause() bool {
+	vp.mu.Lock()
+	if !vp.playing || vp.current == nil || vp.pauseChan != nil {
+		vp.mu.Unlock()
+		return false
 	}
+	vp.pauseChan = make(chan struct{})
+	vp.pausedAt = time.Now()
+	vp.mu.Unlock()
 
-	err = vp.ffmpegCmd.Start()
-	if err != nil {
-		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	vp.pauseRemote(true)
+	vp.notifyChange()
+	return true
+}
+
+// Resume continues a paused track and reports whether it was paused
+func (vp *VoicePlayer) Resume() bool {
+	vp.mu.Lock()
+	if vp.pauseChan == nil {
+		vp.mu.Unlock()
+		return false
 	}
+	vp.clearPause()
+	vp.mu.Unlock()
+
+	vp.pauseRemote(false)
+	vp.notifyChange()
+	return true
+}
 
-	// Stream audio to Discord
-	go func() {
-		defer stdout.Close()
+// IsPaused returns whether playback is paused
+func (vp *VoicePlayer) IsPaused() bool {
+	vp.mu.RLock()
+	defer vp.mu.RUnlock()
+	return vp.pauseChan != nil
+}
 
-		// Create a buffer for Opus audio data
-		buffer := make([]byte, 4096) // Buffer for Opus packets
+// pauseSignal returns a channel closed on resume, or nil when not paused
+func (vp *VoicePlayer) pauseSignal() <-chan struct{} {
+	vp.mu.RLock()
+	defer vp.mu.RUnlock()
+	return vp.pauseChan
+}
 
-		for {
-			select {
-			case <-vp.stopChan:
-				cancel()
-				return
-			case <-vp.skipChan:
-				cancel()
-				return
-			default:
-				// Read audio data
-				n, err := stdout.Read(buffer)
-				if err != nil {
-					if err != io.EOF {
-						utils.LogError("Error reading audio data: %v", err)
-					}
-					return
-				}
-
-				if n > 0 {
-					// Send Opus audio data to Discord voice connection
-					select {
-					case vp.conn.OpusSend <- buffer[:n]:
-					case <-time.After(time.Millisecond * 100):
-						// Drop frame if channel is full
-					}
-				}
-			}
-		}
-	}()
+// clearPause releases a paused read loop and moves the start time past the pause (caller holds the lock)
+func (vp *VoicePlayer) clearPause() {
+	if vp.pauseChan == nil {
+		return
+	}
+	if !vp.started.IsZero() {
+		vp.started = vp.started.Add(time.Since(vp.pausedAt))
+	}
+	close(vp.pauseChan)
+	vp.pauseChan = nil
+}
 
-	// Wait for FFmpeg to complete or be cancelled
-	err = vp.ffmpegCmd.Wait()
-	if err != nil && ctx.Err() == nil {
-		return fmt.Errorf("ffmpeg process failed: %w", err)
+// notifyChange reports a track or pause change to the player's listener
+func (vp *VoicePlayer) notifyChange() {
+	if vp.notify != nil {
+		vp.notify()
 	}
+}
 
-	return nil
+// takeRestart reports and clears a pending restart of the current track and where to continue it:
+// from position, or from where the voice connection dropped when it is resumed after a reconnect
+func (vp *VoicePlayer) takeRestart(position time.Duration) (time.Duration, bool) {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	restart := vp.restart && vp.playing
+	if restart && vp.lostTrack != nil && vp.lostTrack == vp.current {
+		position = vp.lostAt
+	}
+	vp.restart, vp.lostTrack = false, nil
+	return position, restart
 }
 
 // Stop stops current playback
@@ -340,8 +2036,10 @@ func (vp *VoicePlayer) Stop() {
 		vp.stopChan = make(chan struct{})
 		vp.playing = false
 		vp.current = nil
-		vp.queue = vp.queue[:0] // Clear queue
+		vp.queue.Reset() // Nothing to undo once playback stopped
 	}
+	vp.clearPause()
+	vp.prefetch.Discard()
 
 	// Kill FFmpeg process if running
 	if vp.ffmpegCmd != nil && vp.ffmpegCmd.Process != nil {
@@ -355,23 +2053,112 @@ func (vp *VoicePlayer) Skip() {
 	defer vp.mu.Unlock()
 
 	if vp.playing {
+		// Only early skips say something about the track
+		if vp.current != nil {
+			if length := vp.current.Duration; length.Known() && stats.IsEarlySkip(vp.position(), length.Std()) {
+				vp.stats.RecordSkip(vp.guildID, vp.current.URL)
+			}
+		}
+
+		// A skip wins over a pending filter restart
+		vp.restart = false
+		vp.clearPause()
 		close(vp.skipChan)
 		vp.skipChan = make(chan struct{})
 	}
 }
 
+// ClearQueue removes all queued tracks without stopping the current one and returns how many were removed
+func (vp *VoicePlayer) ClearQueue() int {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	removed := vp.queue.Size()
+	vp.queue.Clear()
+	vp.prefetch.Discard()
+	return removed
+}
+
+// ClearQueueIfUnchanged clears the queue only when it still holds the expected tracks, see
+// queue.SimpleQueue.ClearIfUnchanged
+func (vp *VoicePlayer) ClearQueueIfUnchanged(expected []types.AudioSource) bool {
+	vp.mu.Lock()
+	defer vp.mu.Unlock()
+
+	if !vp.queue.ClearIfUnchanged(expected) {
+		return false
+	}
+	vp.prefetch.Discard()
+	return true
+}
+
 // GetQueue returns current queue
-func (vp *VoicePlayer) GetQueue() []AudioTrack {
-	vp.mu.RLock()
-	defer vp.mu.RUnlock()
+func (vp *VoicePlayer) GetQueue() []types.AudioSource {
+	return vp.queue.GetAll()
+}
+
+// MoveInQueue moves the queued track at position from to position to (0-indexed)
+func (vp *VoicePlayer) MoveInQueue(from, to int) error {
+	err := vp.queue.Move(from, to)
+	vp.refreshPrefetch()
+	return err
+}
+
+// SwapInQueue exchanges the queued tracks at positions a and b (0-indexed)
+func (vp *VoicePlayer) SwapInQueue(a, b int) error {
+	err := vp.queue.Swap(a, b)
+	vp.refreshPrefetch()
+	return err
+}
+
+// DeduplicateQueue removes repeated copies of queued tracks and returns how many were removed
+func (vp *VoicePlayer) DeduplicateQueue() int {
+	removed := vp.queue.Deduplicate()
+	vp.refreshPrefetch()
+	return removed
+}
+
+// RemoveQueuedBy removes every track the user queued and returns how many were removed
+func (vp *VoicePlayer) RemoveQueuedBy(userID string) int {
+	removed := vp.queue.RemoveByRequester(userID)
+	vp.refreshPrefetch()
+	return removed
+}
+
+// UndoQueue reverts the most recent queue mutation (see queue.SimpleQueue.Undo) and returns the undone operation
+func (vp *VoicePlayer) UndoQueue() (string, error) {
+	operation, err := vp.queue.Undo()
+	vp.refreshPrefetch()
+	return operation, err
+}
+
+// ShuffleQueue shuffles the queue with a random seed and returns the seed
+func (vp *VoicePlayer) ShuffleQueue() uint64 {
+	seed := vp.queue.Shuffle()
+	vp.refreshPrefetch()
+	return seed
+}
+
+// ShuffleQueueWithSeed shuffles the queue reproducibly for the given seed
+func (vp *VoicePlayer) ShuffleQueueWithSeed(seed uint64) {
+	vp.queue.ShuffleWithSeed(seed)
+	vp.refreshPrefetch()
+}
+
+// UnshuffleQueue restores the queue to the order tracks were added in
+func (vp *VoicePlayer) UnshuffleQueue() error {
+	err := vp.queue.Unshuffle()
+	vp.refreshPrefetch()
+	return err
+}
 
-	queue := make([]AudioTrack, len(vp.queue))
-	copy(queue, vp.queue)
-	return queue
+// GetHistory returns the recently played tracks, most recent first
+func (vp *VoicePlayer) GetHistory() []history.Entry {
+	return vp.history.Entries()
 }
 
 // GetCurrent returns currently playing track
-func (vp *VoicePlayer) GetCurrent() *AudioTrack {
+func (vp *VoicePlayer) GetCurrent() *types.AudioSource {
 	vp.mu.RLock()
 	defer vp.mu.RUnlock()
 
@@ -383,6 +2170,11 @@ func (vp *VoicePlayer) GetCurrent() *AudioTrack {
 	return &current
 }
 
+// ChannelID returns the voice channel the player is connected to
+func (vp *VoicePlayer) ChannelID() string {
+	return vp.channelID()
+}
+
 // IsPlaying returns whether player is currently playing
 func (vp *VoicePlayer) IsPlaying() bool {
 	vp.mu.RLock()
@@ -410,15 +2202,25 @@ func (sp *SimplePlayer) HandleVoiceStateUpdate(guildID string) {
 		return
 	}
 
+	// 24/7 mode never leaves, even when the channel is empty
+	if sp.settings.Get(guildID).StayConnected && sp.Limits(guildID).StayConnected {
+		if timer, exists := sp.disconnectTimers[guildID]; exists {
+			timer.Stop()
+			delete(sp.disconnectTimers, guildID)
+		}
+		return
+	}
+
 	// Get current guild state
-	guild, err := sp.session.State.Guild(guildID)
+	session := sp.sessionFor(guildID)
+	guild, err := session.State.Guild(guildID)
 	if err != nil {
 		utils.LogDebug("Failed to get guild state for auto-disconnect check: %v", err)
 		return
 	}
 
 	// Count non-bot users in the bot's voice channel
-	botUserID := sp.session.State.User.ID
+	botUserID := session.State.User.ID
 	var botChannelID string
 	humanCount := 0
 
@@ -435,7 +2237,8 @@ func (sp *SimplePlayer) HandleVoiceStateUpdate(guildID string) {
 
 	// If no humans in voice channel, start disconnect timer
 	if humanCount == 0 && botChannelID != "" {
-		utils.LogDebug("No humans in voice channel, starting 15-second disconnect timer for guild %s", guildID)
+		timeout := sp.settings.Get(guildID).AloneTimeout()
+		utils.LogDebug("No humans in voice channel, starting %s disconnect timer for guild %s", timeout, guildID)
 
 		// Cancel existing timer if any
 		if timer, exists := sp.disconnectTimers[guildID]; exists {
@@ -443,14 +2246,10 @@ func (sp *SimplePlayer) HandleVoiceStateUpdate(guildID string) {
 		}
 
 		// Start new timer
-		sp.disconnectTimers[guildID] = time.AfterFunc(15*time.Second, func() {
+		sp.disconnectTimers[guildID] = time.AfterFunc(timeout, func() {
 			utils.LogInfo("Auto-disconnecting from empty voice channel in guild %s", guildID)
+			// Leaving also removes this timer from the map
 			sp.LeaveChannel(guildID)
-
-			// Clean up timer
-			sp.mu.Lock()
-			delete(sp.disconnectTimers, guildID)
-			sp.mu.Unlock()
 		})
 	} else if humanCount > 0 {
 		// Humans joined, cancel disconnect timer
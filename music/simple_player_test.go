@@ -10,6 +10,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/queue"
+	"pxnx-discord-bot/music/types"
 )
 
 // MockDiscordSession mocks the Discord session for testing
@@ -124,7 +127,7 @@ func TestSimplePlayer_Play_NotConnected(t *testing.T) {
 	session := &MockDiscordSession{}
 	player := NewSimplePlayer(&session.Session)
 
-	_, err := player.Play("test-guild", "test query")
+	_, err := player.Play("test-guild", "test query", TrackRequest{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not connected")
 }
@@ -134,7 +137,7 @@ func TestVoicePlayer_QueueManagement(t *testing.T) {
 	player := &VoicePlayer{
 		guildID:  "test-guild",
 		conn:     mockConn,
-		queue:    make([]AudioTrack, 0),
+		queue:    queue.NewQueue(),
 		stopChan: make(chan struct{}),
 		skipChan: make(chan struct{}),
 	}
@@ -145,11 +148,11 @@ func TestVoicePlayer_QueueManagement(t *testing.T) {
 	assert.Empty(t, player.GetQueue())
 
 	// Add some tracks to queue (without actually playing)
-	player.queue = append(player.queue, AudioTrack{
+	player.queue.Add(types.AudioSource{
 		Title: "Test Track 1",
 		URL:   "http://example.com/1",
 	})
-	player.queue = append(player.queue, AudioTrack{
+	player.queue.Add(types.AudioSource{
 		Title: "Test Track 2",
 		URL:   "http://example.com/2",
 	})
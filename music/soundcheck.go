@@ -0,0 +1,84 @@
+package music
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"pxnx-discord-bot/music/tone"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// Soundcheck tone: an A4 at half volume, loud enough to hear without startling anyone
+const (
+	SoundcheckFrequency = 440
+	SoundcheckLength    = 5 * time.Second
+	soundcheckVolume    = 0.5
+)
+
+// SoundcheckProvider is what the tone's bandwidth is counted under
+const SoundcheckProvider = "soundcheck"
+
+// ErrPlaying is returned when a soundcheck would interrupt playback
+var ErrPlaying = errors.New("something is already playing")
+
+// Soundcheck plays a generated test tone in a guild's voice channel through the same FFmpeg encoder and
+// voice connection as music, and returns how long it played. Tracks queued meanwhile start after it.
+func (sp *SimplePlayer) Soundcheck(guildID string) (time.Duration, error) {
+	player, exists := sp.GetPlayer(guildID)
+	if !exists {
+		return 0, ErrNotConnected
+	}
+	return player.Soundcheck()
+}
+
+// Soundcheck plays the test tone unless something is playing, and returns how long it played
+func (vp *VoicePlayer) Soundcheck() (time.Duration, error) {
+	if vp.remote != nil {
+		return 0, ErrSoundcheckRemote
+	}
+	file, err := os.CreateTemp("", "soundcheck-*.wav")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create soundcheck file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(tone.WAV(tone.Sine(SoundcheckFrequency, SoundcheckLength, soundcheckVolume)))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write soundcheck file: %w", err)
+	}
+
+	track := &types.AudioSource{Title: "Soundcheck", Provider: SoundcheckProvider}
+
+	// The tone counts as the current track, so songs requested meanwhile queue behind it
+	vp.mu.Lock()
+	if vp.playing {
+		vp.mu.Unlock()
+		return 0, ErrPlaying
+	}
+	vp.playing, vp.current, vp.started = true, track, time.Time{}
+	vp.mu.Unlock()
+
+	// Filters would distort the tone and say nothing about the connection
+	var played time.Duration
+	enc, err := startEncoder(*track, file.Name(), 0, nil, false, 0, vp.tierLimits().Bitrate)
+	if err == nil {
+		played, err = vp.playEncoder(enc)
+	}
+
+	vp.mu.Lock()
+	if vp.current == track {
+		vp.current = nil
+	}
+	vp.mu.Unlock()
+	utils.SafeGo("music.playNext", vp.playNext)
+
+	if err != nil {
+		return played, fmt.Errorf("soundcheck failed: %w", err)
+	}
+	return played, nil
+}
@@ -0,0 +1,21 @@
+package music
+
+import "pxnx-discord-bot/music/speaking"
+
+// Speakers returns who is talking in the guild's voice channel, longest talking first. Discord reports
+// speaking over the voice connection, so it is empty while the bot is not connected.
+func (sp *SimplePlayer) Speakers(guildID string) []speaking.Speaker {
+	return sp.speakers.Speakers(guildID)
+}
+
+// SubscribeSpeaking returns a channel of the guild's speaking events and a function that ends the
+// subscription. Subscriptions outlive the voice connection, so they see the turns of the next one.
+func (sp *SimplePlayer) SubscribeSpeaking(guildID string) (<-chan speaking.Event, func()) {
+	return sp.speakers.Subscribe(guildID)
+}
+
+// StopSpeaking marks a member as no longer talking, for when they leave or move out of the voice channel
+// without Discord reporting the end of their turn
+func (sp *SimplePlayer) StopSpeaking(guildID, userID string) {
+	sp.speakers.Update(guildID, userID, false)
+}
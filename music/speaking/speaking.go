@@ -0,0 +1,145 @@
+// Package speaking tracks who is talking in the voice channels the bot is connected to, from the
+// speaking updates Discord sends over the voice connection.
+package speaking
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Speaker is a member talking in a guild's voice channel
+type Speaker struct {
+	UserID string    `json:"user_id"`
+	Since  time.Time `json:"since"` // When they started talking
+}
+
+// Event is a member starting or stopping to talk
+type Event struct {
+	GuildID  string    `json:"guild_id"`
+	UserID   string    `json:"user_id"`
+	Speaking bool      `json:"speaking"`
+	Time     time.Time `json:"time"`
+}
+
+// subscriberBuffer is how many events a subscriber may fall behind before further ones are dropped
+const subscriberBuffer = 64
+
+// Tracker holds the members currently talking in each guild
+type Tracker struct {
+	mu          sync.RWMutex
+	guilds      map[string]map[string]time.Time    // Start of each speaker's current turn by guild and user ID
+	subscribers map[string]map[chan Event]struct{} // Channels told about each guild's turns
+	now         func() time.Time
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		guilds:      make(map[string]map[string]time.Time),
+		subscribers: make(map[string]map[chan Event]struct{}),
+		now:         time.Now,
+	}
+}
+
+// Subscribe returns a channel of a guild's speaking events and a function that ends the subscription
+// and closes the channel. Events a subscriber doesn't keep up with are dropped rather than holding up
+// the voice connection.
+func (t *Tracker) Subscribe(guildID string) (<-chan Event, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := make(chan Event, subscriberBuffer)
+	if t.subscribers[guildID] == nil {
+		t.subscribers[guildID] = make(map[chan Event]struct{})
+	}
+	t.subscribers[guildID][events] = struct{}{}
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			delete(t.subscribers[guildID], events)
+			if len(t.subscribers[guildID]) == 0 {
+				delete(t.subscribers, guildID)
+			}
+			close(events)
+		})
+	}
+}
+
+// publish tells a guild's subscribers about a turn starting or ending, with t.mu held
+func (t *Tracker) publish(guildID, userID string, speaking bool, at time.Time) {
+	event := Event{GuildID: guildID, UserID: userID, Speaking: speaking, Time: at}
+	for events := range t.subscribers[guildID] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// Update records a member starting or stopping to talk. Repeated starts keep the original start time.
+func (t *Tracker) Update(guildID, userID string, speaking bool) {
+	if userID == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	speakers := t.guilds[guildID]
+	_, talking := speakers[userID]
+	if !speaking {
+		if !talking {
+			return
+		}
+		delete(speakers, userID)
+		if len(speakers) == 0 {
+			delete(t.guilds, guildID)
+		}
+		t.publish(guildID, userID, false, t.now())
+		return
+	}
+	if talking {
+		return
+	}
+	if speakers == nil {
+		speakers = make(map[string]time.Time)
+		t.guilds[guildID] = speakers
+	}
+	speakers[userID] = t.now()
+	t.publish(guildID, userID, true, speakers[userID])
+}
+
+// Speakers returns who is talking in a guild, longest talking first
+func (t *Tracker) Speakers(guildID string) []Speaker {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	speakers := make([]Speaker, 0, len(t.guilds[guildID]))
+	for userID, since := range t.guilds[guildID] {
+		speakers = append(speakers, Speaker{UserID: userID, Since: since})
+	}
+	sort.Slice(speakers, func(a, b int) bool {
+		if !speakers[a].Since.Equal(speakers[b].Since) {
+			return speakers[a].Since.Before(speakers[b].Since)
+		}
+		return speakers[a].UserID < speakers[b].UserID
+	})
+	return speakers
+}
+
+// Clear forgets everyone talking in a guild, for when the bot leaves its voice channel. Subscribers are
+// told each of them stopped.
+func (t *Tracker) Clear(guildID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	for userID := range t.guilds[guildID] {
+		t.publish(guildID, userID, false, now)
+	}
+	delete(t.guilds, guildID)
+}
@@ -0,0 +1,88 @@
+package speaking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackerUpdates(t *testing.T) {
+	now := time.Date(2025, time.May, 1, 20, 0, 0, 0, time.UTC)
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Update("guild1", "user2", true)
+	now = now.Add(time.Second)
+	tracker.Update("guild1", "user1", true)
+	tracker.Update("guild2", "user3", true)
+	tracker.Update("guild1", "", true)
+
+	start := now.Add(-time.Second)
+	now = now.Add(time.Second)
+	tracker.Update("guild1", "user2", true)
+	assert.Equal(t, []Speaker{{"user2", start}, {"user1", start.Add(time.Second)}}, tracker.Speakers("guild1"),
+		"repeated starts keep the original start time")
+
+	tracker.Update("guild1", "user2", false)
+	assert.Equal(t, []Speaker{{"user1", start.Add(time.Second)}}, tracker.Speakers("guild1"))
+
+	tracker.Update("guild1", "user1", false)
+	assert.Empty(t, tracker.Speakers("guild1"))
+
+	tracker.Clear("guild2")
+	assert.Empty(t, tracker.Speakers("guild2"))
+}
+
+func TestTrackerSubscribe(t *testing.T) {
+	now := time.Date(2025, time.May, 1, 20, 0, 0, 0, time.UTC)
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+
+	events, cancel := tracker.Subscribe("guild1")
+	tracker.Update("guild1", "user1", true)
+	tracker.Update("guild1", "user1", true)
+	tracker.Update("guild2", "user2", true)
+	tracker.Update("guild1", "user3", false)
+	tracker.Update("guild1", "user1", false)
+	tracker.Update("guild1", "user4", true)
+	tracker.Clear("guild1")
+
+	assert.Equal(t, []Event{
+		{GuildID: "guild1", UserID: "user1", Speaking: true, Time: now},
+		{GuildID: "guild1", UserID: "user1", Speaking: false, Time: now},
+		{GuildID: "guild1", UserID: "user4", Speaking: true, Time: now},
+		{GuildID: "guild1", UserID: "user4", Speaking: false, Time: now},
+	}, drain(events), "only changes of the subscribed guild are sent")
+
+	cancel()
+	cancel()
+	_, open := <-events
+	assert.False(t, open, "ending the subscription closes the channel")
+	tracker.Update("guild1", "user1", true)
+	assert.Empty(t, tracker.subscribers)
+}
+
+func TestTrackerDropsEventsOfSlowSubscribers(t *testing.T) {
+	tracker := NewTracker()
+	events, cancel := tracker.Subscribe("guild1")
+	defer cancel()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		tracker.Update("guild1", "user1", i%2 == 0)
+	}
+	assert.Len(t, drain(events), subscriberBuffer)
+}
+
+// drain returns the events waiting on a channel
+func drain(events <-chan Event) []Event {
+	var received []Event
+	for {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		default:
+			return received
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package music
+
+import (
+	"time"
+
+	"pxnx-discord-bot/music/speaking"
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// dumpQueueHead is how many upcoming tracks a state dump lists
+const dumpQueueHead = 5
+
+// GuildState is a snapshot of everything the player holds for a guild, for bug reports
+type GuildState struct {
+	GuildID           string       `json:"guild_id"`
+	GeneratedAt       time.Time    `json:"generated_at"`
+	Connected         bool         `json:"connected"`
+	DisconnectPending bool         `json:"disconnect_pending"`
+	AutoDJ            bool         `json:"auto_dj"`
+	RepeatPolicy      string       `json:"repeat_policy"`
+	Filters           []string     `json:"filters"`
+	Loudnorm          bool         `json:"loudnorm"`
+	FairQueue         bool         `json:"fair_queue"`
+	StayConnected     bool         `json:"stay_connected"`
+	StatsTracks       int          `json:"stats_tracks"`
+	StreamedBytes     int64        `json:"streamed_bytes_month"`
+	Player            *PlayerState `json:"player,omitempty"`
+	Memory            MemoryStats  `json:"memory"`
+}
+
+// PlayerState is a snapshot of a voice player's playback, queue, encoder and voice connection
+type PlayerState struct {
+	Playing        bool         `json:"playing"`
+	Position       string       `json:"position"`
+	Current        *TrackState  `json:"current,omitempty"`
+	QueueSize      int          `json:"queue_size"`
+	QueueHead      []TrackState `json:"queue_head"`
+	PriorityQueued int          `json:"priority_queued"`
+	Shuffled       bool         `json:"shuffled"`
+	HistorySize    int          `json:"history_size"`
+	Encoder        EncoderState `json:"encoder"`
+	Voice          VoiceState   `json:"voice"`
+}
+
+// TrackState describes a track with its stream URL scrubbed of signatures
+type TrackState struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	StreamURL   string `json:"stream_url,omitempty"`
+	Duration    string `json:"duration,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"`
+	Priority    bool   `json:"priority,omitempty"`
+}
+
+// EncoderState describes the options the current and next FFmpeg encoders run with
+type EncoderState struct {
+	Filters         []string `json:"filters"`
+	Speed           float64  `json:"speed"`
+	Loudnorm        bool     `json:"loudnorm"`
+	Volume          int      `json:"volume"`
+	StartOffset     string   `json:"start_offset"`
+	ProcessID       int      `json:"process_id,omitempty"` // Last FFmpeg process started for this player
+	RestartPending  bool     `json:"restart_pending"`
+	PrefetchPending bool     `json:"prefetch_pending"`
+	PrefetchTrack   string   `json:"prefetch_track,omitempty"`
+}
+
+// VoiceState describes the health of the voice connection
+type VoiceState struct {
+	ChannelID     string `json:"channel_id"`
+	Ready         bool   `json:"ready"`
+	OpusQueued    int    `json:"opus_queued"`
+	OpusQueueSize int    `json:"opus_queue_size"`
+
+	Speakers []speaking.Speaker `json:"speakers"` // Members talking in the channel
+}
+
+// newTrackState snapshots a track, scrubbing signatures from its URLs
+func newTrackState(track types.AudioSource) TrackState {
+	return TrackState{
+		Title:       track.Title,
+		URL:         utils.ScrubURL(track.URL),
+		StreamURL:   utils.ScrubURL(track.StreamURL),
+		Duration:    track.Duration.String(),
+		RequestedBy: track.RequestedBy,
+		Priority:    track.Priority,
+	}
+}
+
+// DumpState snapshots everything the player holds for a guild
+func (sp *SimplePlayer) DumpState(guildID string) GuildState {
+	sp.mu.RLock()
+	player := sp.connections[guildID]
+	_, disconnectPending := sp.disconnectTimers[guildID]
+	chain := sp.filterChains[guildID]
+	guildSettings := sp.settings.Get(guildID)
+	policy := repeatPolicy(guildSettings)
+	autoDJ := guildSettings.AutoDJ
+	sp.mu.RUnlock()
+
+	repeatPolicy := "off"
+	if policy.Enabled() {
+		repeatPolicy = string(policy.Mode) + " within " + policy.Window.String()
+	}
+
+	state := GuildState{
+		GuildID:           guildID,
+		GeneratedAt:       time.Now().UTC(),
+		Connected:         player != nil,
+		DisconnectPending: disconnectPending,
+		AutoDJ:            autoDJ,
+		RepeatPolicy:      repeatPolicy,
+		Filters:           append([]string{}, chain...),
+		Loudnorm:          guildSettings.Loudnorm,
+		FairQueue:         guildSettings.FairQueue,
+		StayConnected:     guildSettings.StayConnected,
+		StatsTracks:       len(sp.stats.Tracks(guildID)),
+		StreamedBytes:     sp.GuildUsage(guildID),
+		Memory:            sp.MemoryStats(),
+	}
+	if player != nil {
+		playerState := player.DumpState()
+		playerState.Voice.Speakers = sp.Speakers(guildID)
+		state.Player = &playerState
+	}
+	return state
+}
+
+// DumpState snapshots the player's playback, queue, encoder options and voice connection
+func (vp *VoicePlayer) DumpState() PlayerState {
+	vp.mu.RLock()
+	state := PlayerState{
+		Playing:        vp.playing,
+		Position:       vp.position().Round(time.Second).String(),
+		QueueSize:      vp.queue.Size(),
+		PriorityQueued: vp.queue.PriorityCount(),
+		Shuffled:       vp.queue.IsShuffled(),
+		HistorySize:    vp.history.Len(),
+		Encoder: EncoderState{
+			Filters:        append([]string{}, vp.filters...),
+			Speed:          vp.filters.Speed(),
+			Loudnorm:       vp.normalize,
+			Volume:         vp.volume,
+			StartOffset:    vp.offset.Round(time.Second).String(),
+			RestartPending: vp.restart,
+		},
+	}
+	if vp.ffmpegCmd != nil && vp.ffmpegCmd.Process != nil {
+		state.Encoder.ProcessID = vp.ffmpegCmd.Process.Pid
+	}
+	if vp.current != nil {
+		current := newTrackState(*vp.current)
+		state.Current = &current
+	}
+	if vp.conn != nil {
+		state.Voice = VoiceState{
+			ChannelID:     vp.conn.ChannelID,
+			Ready:         vp.conn.Ready,
+			OpusQueued:    len(vp.conn.OpusSend),
+			OpusQueueSize: cap(vp.conn.OpusSend),
+		}
+	}
+	vp.mu.RUnlock()
+
+	state.QueueHead = []TrackState{}
+	for i, track := range vp.queue.GetAll() {
+		if i == dumpQueueHead {
+			break
+		}
+		state.QueueHead = append(state.QueueHead, newTrackState(track))
+	}
+	state.Encoder.PrefetchTrack, state.Encoder.PrefetchPending = vp.prefetch.Pending()
+	state.Encoder.PrefetchTrack = utils.ScrubURL(state.Encoder.PrefetchTrack)
+	return state
+}
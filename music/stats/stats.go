@@ -0,0 +1,204 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/music/types"
+)
+
+const (
+	// DefaultTracksPerGuild bounds how many distinct tracks are tracked per guild
+	DefaultTracksPerGuild = 500
+
+	// EarlySkipThreshold is the share of a track below which a skip counts against it
+	EarlySkipThreshold = 0.3
+)
+
+// TrackStats is how a guild has listened to one track
+type TrackStats struct {
+	Track      types.AudioSource
+	Plays      int
+	Skips      int // Skips before EarlySkipThreshold of the track
+	LastPlayed time.Time
+}
+
+// SkipRate returns the share of plays that were skipped early
+func (t TrackStats) SkipRate() float64 {
+	if t.Plays == 0 {
+		return 0
+	}
+	return float64(t.Skips) / float64(t.Plays)
+}
+
+// dayLayout keys the day plays are counted for, a calendar day in UTC
+const dayLayout = "2006-01-02"
+
+// Store keeps per-guild listening statistics in memory, evicting the least recently played tracks
+type Store struct {
+	perGuild int
+	mu       sync.RWMutex
+	guilds   map[string]map[string]*TrackStats // Guild ID -> track URL -> stats
+	day      string                            // Day today counts, "2006-01-02"
+	today    int                               // Plays across all guilds on day
+}
+
+// NewStore creates a store that tracks up to perGuild tracks for each guild
+func NewStore(perGuild int) *Store {
+	if perGuild < 1 {
+		perGuild = 1
+	}
+	return &Store{
+		perGuild: perGuild,
+		guilds:   make(map[string]map[string]*TrackStats),
+	}
+}
+
+// RecordPlay counts a track as played in a guild
+func (s *Store) RecordPlay(guildID string, track types.AudioSource, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if day := at.UTC().Format(dayLayout); day != s.day {
+		s.day, s.today = day, 0
+	}
+	s.today++
+
+	if track.URL == "" {
+		return
+	}
+
+	tracks, exists := s.guilds[guildID]
+	if !exists {
+		tracks = make(map[string]*TrackStats)
+		s.guilds[guildID] = tracks
+	}
+
+	entry, exists := tracks[track.URL]
+	if !exists {
+		if len(tracks) >= s.perGuild {
+			evictOldest(tracks)
+		}
+		entry = &TrackStats{}
+		tracks[track.URL] = entry
+	}
+
+	track.StreamURL = ""
+	entry.Track = track
+	entry.Plays++
+	entry.LastPlayed = at
+}
+
+// PlaysToday returns how many tracks were played across all guilds on now's day in UTC
+func (s *Store) PlaysToday(now time.Time) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if now.UTC().Format(dayLayout) != s.day {
+		return 0
+	}
+	return s.today
+}
+
+// RecordSkip counts an early skip of a track that was played in a guild
+func (s *Store) RecordSkip(guildID, url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, exists := s.guilds[guildID][url]; exists {
+		entry.Skips++
+	}
+}
+
+// Tracks returns a copy of a guild's statistics, most played first
+func (s *Store) Tracks(guildID string) []TrackStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]TrackStats, 0, len(s.guilds[guildID]))
+	for _, entry := range s.guilds[guildID] {
+		result = append(result, *entry)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Plays != result[j].Plays {
+			return result[i].Plays > result[j].Plays
+		}
+		return result[i].LastPlayed.After(result[j].LastPlayed)
+	})
+	return result
+}
+
+// MostSkipped returns up to n tracks of a guild that were skipped early, most skips first
+func (s *Store) MostSkipped(guildID string, n int) []TrackStats {
+	skipped := make([]TrackStats, 0)
+	for _, track := range s.Tracks(guildID) {
+		if track.Skips > 0 {
+			skipped = append(skipped, track)
+		}
+	}
+
+	sort.SliceStable(skipped, func(i, j int) bool {
+		if skipped[i].Skips != skipped[j].Skips {
+			return skipped[i].Skips > skipped[j].Skips
+		}
+		return skipped[i].SkipRate() > skipped[j].SkipRate()
+	})
+	if len(skipped) > n {
+		skipped = skipped[:n]
+	}
+	return skipped
+}
+
+// Guilds returns the guilds with recorded statistics
+func (s *Store) Guilds() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	guildIDs := make([]string, 0, len(s.guilds))
+	for guildID := range s.guilds {
+		guildIDs = append(guildIDs, guildID)
+	}
+	return guildIDs
+}
+
+// Forget drops all statistics of a guild
+func (s *Store) Forget(guildID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.guilds, guildID)
+}
+
+// Len returns the number of tracked tracks across all guilds
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0
+	for _, tracks := range s.guilds {
+		total += len(tracks)
+	}
+	return total
+}
+
+// evictOldest removes the least recently played track (caller holds the lock)
+func evictOldest(tracks map[string]*TrackStats) {
+	oldestURL := ""
+	var oldest time.Time
+	for url, entry := range tracks {
+		if oldestURL == "" || entry.LastPlayed.Before(oldest) {
+			oldestURL, oldest = url, entry.LastPlayed
+		}
+	}
+	delete(tracks, oldestURL)
+}
+
+// IsEarlySkip reports whether a skip at position came before EarlySkipThreshold of a track's length.
+// Tracks of unknown length, such as live streams, never count.
+func IsEarlySkip(position, length time.Duration) bool {
+	if length <= 0 {
+		return false
+	}
+	return float64(position) < EarlySkipThreshold*float64(length)
+}
@@ -0,0 +1,117 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+func createTestTrack(id string) types.AudioSource {
+	return types.AudioSource{
+		Title:     "Song " + id,
+		URL:       "https://youtube.com/watch?v=" + id,
+		StreamURL: "https://stream.example.com/" + id,
+	}
+}
+
+func TestStoreRecordPlayAndSkip(t *testing.T) {
+	store := NewStore(DefaultTracksPerGuild)
+	now := time.Now()
+
+	store.RecordPlay("guild_1", createTestTrack("a"), now)
+	store.RecordPlay("guild_1", createTestTrack("a"), now.Add(time.Minute))
+	store.RecordPlay("guild_1", createTestTrack("b"), now)
+	store.RecordSkip("guild_1", "https://youtube.com/watch?v=a")
+	store.RecordSkip("guild_1", "https://youtube.com/watch?v=unknown")
+
+	tracks := store.Tracks("guild_1")
+	require.Len(t, tracks, 2)
+	assert.Equal(t, "Song a", tracks[0].Track.Title, "most played first")
+	assert.Equal(t, 2, tracks[0].Plays)
+	assert.Equal(t, 1, tracks[0].Skips)
+	assert.Equal(t, 0.5, tracks[0].SkipRate())
+	assert.Equal(t, now.Add(time.Minute), tracks[0].LastPlayed)
+	assert.Empty(t, tracks[0].Track.StreamURL, "expiring stream URLs are not kept")
+
+	assert.Empty(t, store.Tracks("guild_2"), "guilds are separate")
+}
+
+func TestStoreEvictsLeastRecentlyPlayed(t *testing.T) {
+	store := NewStore(2)
+	now := time.Now()
+
+	store.RecordPlay("guild_1", createTestTrack("old"), now)
+	store.RecordPlay("guild_1", createTestTrack("mid"), now.Add(time.Minute))
+	store.RecordPlay("guild_1", createTestTrack("new"), now.Add(2*time.Minute))
+
+	tracks := store.Tracks("guild_1")
+	require.Len(t, tracks, 2)
+	for _, track := range tracks {
+		assert.NotEqual(t, "Song old", track.Track.Title)
+	}
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestStoreForget(t *testing.T) {
+	store := NewStore(0)
+	store.RecordPlay("guild_1", createTestTrack("a"), time.Now())
+	store.RecordPlay("guild_1", types.AudioSource{Title: "no url"}, time.Now())
+	assert.Equal(t, []string{"guild_1"}, store.Guilds())
+	assert.Equal(t, 1, store.Len(), "tracks without a URL are not tracked")
+
+	store.Forget("guild_1")
+	assert.Empty(t, store.Guilds())
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestStorePlaysToday(t *testing.T) {
+	store := NewStore(DefaultTracksPerGuild)
+	day := time.Date(2025, time.March, 1, 23, 0, 0, 0, time.UTC)
+
+	store.RecordPlay("guild_1", createTestTrack("a"), day)
+	store.RecordPlay("guild_2", types.AudioSource{Title: "no url"}, day.Add(30*time.Minute))
+	store.Forget("guild_1")
+	assert.Equal(t, 2, store.PlaysToday(day.Add(59*time.Minute)), "every play counts, forgotten guilds included")
+
+	assert.Equal(t, 0, store.PlaysToday(day.Add(time.Hour)), "the count starts over at midnight UTC")
+	store.RecordPlay("guild_1", createTestTrack("b"), day.Add(time.Hour))
+	assert.Equal(t, 1, store.PlaysToday(day.Add(time.Hour)))
+}
+
+func TestSkipRateWithoutPlays(t *testing.T) {
+	assert.Equal(t, 0.0, TrackStats{}.SkipRate())
+}
+
+func TestMostSkipped(t *testing.T) {
+	store := NewStore(DefaultTracksPerGuild)
+	now := time.Now()
+
+	for _, id := range []string{"a", "b", "c"} {
+		store.RecordPlay("guild_1", createTestTrack(id), now)
+		store.RecordPlay("guild_1", createTestTrack(id), now)
+	}
+	store.RecordPlay("guild_1", createTestTrack("b"), now)
+	store.RecordSkip("guild_1", createTestTrack("a").URL)
+	store.RecordSkip("guild_1", createTestTrack("b").URL)
+	store.RecordSkip("guild_1", createTestTrack("b").URL)
+	store.RecordSkip("guild_1", createTestTrack("c").URL)
+
+	skipped := store.MostSkipped("guild_1", 2)
+	require.Len(t, skipped, 2)
+	assert.Equal(t, "Song b", skipped[0].Track.Title, "most skips first")
+	assert.Equal(t, 1, skipped[1].Skips)
+
+	assert.Len(t, store.MostSkipped("guild_1", 10), 3)
+	assert.Empty(t, store.MostSkipped("guild_2", 10))
+}
+
+func TestIsEarlySkip(t *testing.T) {
+	assert.True(t, IsEarlySkip(10*time.Second, time.Minute))
+	assert.False(t, IsEarlySkip(18*time.Second, time.Minute), "30% is no longer early")
+	assert.False(t, IsEarlySkip(50*time.Second, time.Minute))
+	assert.False(t, IsEarlySkip(time.Second, 0), "unknown length never counts")
+}
@@ -0,0 +1,99 @@
+package music
+
+import (
+	"time"
+
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/utils"
+)
+
+// Timeouts returns how long a guild's player stays in an empty voice channel and how long it stays
+// connected with nothing playing, 0 when it never leaves for being idle
+func (sp *SimplePlayer) Timeouts(guildID string) (alone, idle time.Duration) {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	guild := sp.settings.Get(guildID)
+	return guild.AloneTimeout(), guild.IdleTimeout()
+}
+
+// SetAloneTimeout changes how long a guild's player stays in an empty voice channel and saves the setting.
+// It applies from the next time the channel empties.
+func (sp *SimplePlayer) SetAloneTimeout(guildID string, timeout time.Duration) error {
+	sp.mu.RLock()
+	store := sp.settings
+	sp.mu.RUnlock()
+
+	seconds := int(timeout / time.Second)
+	if timeout == settings.CurrentDefaults().AloneTimeout {
+		seconds = 0
+	}
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.AloneTimeoutSeconds = seconds })
+	return err
+}
+
+// SetIdleTimeout changes how long a guild's player stays connected with nothing playing, 0 to never leave
+// for being idle, and saves the setting. A running idle timer starts over with the new timeout.
+func (sp *SimplePlayer) SetIdleTimeout(guildID string, timeout time.Duration) error {
+	sp.mu.Lock()
+	store := sp.settings
+	if timer, exists := sp.idleTimers[guildID]; exists {
+		timer.Stop()
+		delete(sp.idleTimers, guildID)
+	}
+	sp.mu.Unlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.IdleTimeoutMinutes = int(timeout / time.Minute) })
+	sp.updateIdleTimer(guildID)
+	return err
+}
+
+// updateIdleTimer starts the idle disconnect timer when a guild's player has nothing playing and stops it
+// once something plays. Call it without sp.mu held.
+func (sp *SimplePlayer) updateIdleTimer(guildID string) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	timeout := sp.settings.Get(guildID).IdleTimeout()
+	idle := timeout > 0 && sp.isIdle(guildID)
+
+	timer, running := sp.idleTimers[guildID]
+	if !idle {
+		if running {
+			timer.Stop()
+			delete(sp.idleTimers, guildID)
+		}
+		return
+	}
+	if running {
+		return
+	}
+
+	utils.LogDebug("Nothing playing, starting %s idle disconnect timer for guild %s", timeout, guildID)
+	sp.idleTimers[guildID] = time.AfterFunc(timeout, func() {
+		sp.mu.Lock()
+		delete(sp.idleTimers, guildID)
+		idle := sp.isIdle(guildID)
+		sp.mu.Unlock()
+
+		if idle {
+			utils.LogInfo("Disconnecting from voice in guild %s after %s with nothing playing", guildID, timeout)
+			if err := sp.LeaveChannel(guildID); err != nil {
+				utils.LogWarn("Failed to leave voice channel in idle guild %s: %v", guildID, err)
+			}
+		}
+	})
+}
+
+// isIdle reports whether a guild's player is connected with nothing playing, or paused, outside 24/7 mode
+// (caller holds sp.mu)
+func (sp *SimplePlayer) isIdle(guildID string) bool {
+	player, exists := sp.connections[guildID]
+	if !exists {
+		return false
+	}
+	if sp.settings.Get(guildID).StayConnected && sp.Limits(guildID).StayConnected {
+		return false
+	}
+	return !player.IsPlaying() || player.IsPaused()
+}
@@ -0,0 +1,66 @@
+// Package tone synthesizes test tones, so the audio pipeline can be checked without any network input.
+package tone
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// Output format, the same as Discord voice
+const (
+	SampleRate     = 48000
+	Channels       = 2
+	bytesPerSample = 2 // 16-bit signed little-endian
+)
+
+// fadeLength ramps the tone in and out so it starts and stops without a click
+const fadeLength = 20 * time.Millisecond
+
+// Sine returns a sine wave of frequency Hz lasting duration as interleaved 16-bit little-endian stereo PCM
+// at SampleRate. volume scales the peak between 0 (silence) and 1 (full scale).
+func Sine(frequency float64, duration time.Duration, volume float64) []byte {
+	volume = math.Max(0, math.Min(1, volume))
+	frames := int(duration.Seconds() * SampleRate)
+	fadeFrames := int(fadeLength.Seconds() * SampleRate)
+	if fadeFrames > frames/2 {
+		fadeFrames = frames / 2
+	}
+
+	pcm := make([]byte, frames*Channels*bytesPerSample)
+	for frame := 0; frame < frames; frame++ {
+		gain := volume
+		if edge := min(frame, frames-1-frame); edge < fadeFrames {
+			gain *= float64(edge) / float64(fadeFrames)
+		}
+		value := int16(math.Round(gain * math.MaxInt16 * math.Sin(2*math.Pi*frequency*float64(frame)/SampleRate)))
+		for channel := 0; channel < Channels; channel++ {
+			offset := (frame*Channels + channel) * bytesPerSample
+			binary.LittleEndian.PutUint16(pcm[offset:], uint16(value))
+		}
+	}
+	return pcm
+}
+
+// WAV wraps PCM from Sine in a WAV header so FFmpeg can read it like any audio file
+func WAV(pcm []byte) []byte {
+	const headerSize = 44
+	wav := make([]byte, headerSize, headerSize+len(pcm))
+
+	copy(wav[0:], "RIFF")
+	binary.LittleEndian.PutUint32(wav[4:], uint32(headerSize-8+len(pcm)))
+	copy(wav[8:], "WAVE")
+
+	copy(wav[12:], "fmt ")
+	binary.LittleEndian.PutUint32(wav[16:], 16) // Format chunk size
+	binary.LittleEndian.PutUint16(wav[20:], 1)  // PCM
+	binary.LittleEndian.PutUint16(wav[22:], Channels)
+	binary.LittleEndian.PutUint32(wav[24:], SampleRate)
+	binary.LittleEndian.PutUint32(wav[28:], SampleRate*Channels*bytesPerSample) // Byte rate
+	binary.LittleEndian.PutUint16(wav[32:], Channels*bytesPerSample)            // Block align
+	binary.LittleEndian.PutUint16(wav[34:], bytesPerSample*8)                   // Bits per sample
+
+	copy(wav[36:], "data")
+	binary.LittleEndian.PutUint32(wav[40:], uint32(len(pcm)))
+	return append(wav, pcm...)
+}
@@ -0,0 +1,60 @@
+package tone
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// samples decodes the left channel of PCM from Sine
+func samples(pcm []byte) []int16 {
+	left := make([]int16, 0, len(pcm)/(Channels*bytesPerSample))
+	for offset := 0; offset < len(pcm); offset += Channels * bytesPerSample {
+		left = append(left, int16(binary.LittleEndian.Uint16(pcm[offset:])))
+	}
+	return left
+}
+
+func TestSine(t *testing.T) {
+	pcm := Sine(440, time.Second, 0.5)
+	require.Len(t, pcm, SampleRate*Channels*bytesPerSample)
+
+	left := samples(pcm)
+	assert.Zero(t, left[0], "the tone fades in")
+	assert.Zero(t, left[len(left)-1], "the tone fades out")
+
+	var peak int16
+	for _, sample := range left {
+		peak = max(peak, sample)
+	}
+	assert.InDelta(t, 0.5*32767, float64(peak), 50)
+
+	// Both channels carry the same signal
+	frame := SampleRate / 2
+	assert.Equal(t, pcm[frame*4:frame*4+2], pcm[frame*4+2:frame*4+4])
+}
+
+func TestSineClampsVolume(t *testing.T) {
+	for _, sample := range samples(Sine(440, 100*time.Millisecond, -1)) {
+		require.Zero(t, sample)
+	}
+	assert.NotPanics(t, func() { Sine(440, 100*time.Millisecond, 3) })
+	assert.Empty(t, Sine(440, 0, 1))
+}
+
+func TestWAV(t *testing.T) {
+	pcm := Sine(1000, 10*time.Millisecond, 1)
+	wav := WAV(pcm)
+
+	require.Len(t, wav, 44+len(pcm))
+	assert.Equal(t, "RIFF", string(wav[0:4]))
+	assert.Equal(t, uint32(36+len(pcm)), binary.LittleEndian.Uint32(wav[4:]))
+	assert.Equal(t, "WAVE", string(wav[8:12]))
+	assert.Equal(t, uint16(Channels), binary.LittleEndian.Uint16(wav[22:]))
+	assert.Equal(t, uint32(SampleRate), binary.LittleEndian.Uint32(wav[24:]))
+	assert.Equal(t, uint32(len(pcm)), binary.LittleEndian.Uint32(wav[40:]))
+	assert.Equal(t, pcm, wav[44:])
+}
@@ -0,0 +1,193 @@
+// Package twitch plays the audio of Twitch channels, past broadcasts and clips through yt-dlp.
+// Channel links play the broadcast that is live now.
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"pxnx-discord-bot/music/types"
+)
+
+// ProviderName is the provider of tracks played from Twitch
+const ProviderName = "twitch"
+
+// Kind is what a Twitch link points at
+type Kind int
+
+const (
+	KindLive Kind = iota // A channel, which plays its current broadcast
+	KindVOD              // A past broadcast or highlight
+	KindClip             // A clip
+)
+
+// ErrOffline is returned for channels that aren't broadcasting
+var ErrOffline = errors.New("the channel is not live right now")
+
+// ErrSearchUnsupported is returned by Search, Twitch is only played from links
+var ErrSearchUnsupported = errors.New("twitch can't be searched, use a channel, video or clip link")
+
+var (
+	channelName = regexp.MustCompile(`^[A-Za-z0-9_]{3,25}$`)
+	videoID     = regexp.MustCompile(`^v?[0-9]+$`)
+	clipSlug    = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// reservedPaths are twitch.tv pages that look like channel names
+var reservedPaths = map[string]bool{
+	"directory": true, "downloads": true, "drops": true, "friends": true, "inventory": true,
+	"jobs": true, "login": true, "messages": true, "payments": true, "search": true,
+	"settings": true, "signup": true, "subscriptions": true, "turbo": true, "wallet": true,
+}
+
+// Link is a parsed Twitch link
+type Link struct {
+	Kind    Kind
+	Channel string // Set for channel links and channel clip links
+	ID      string // Video ID or clip slug
+}
+
+// ParseURL recognises links to Twitch channels, videos and clips
+func ParseURL(rawURL string) (Link, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return Link{}, false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+
+	if host == "clips.twitch.tv" {
+		if len(parts) == 1 && clipSlug.MatchString(parts[0]) {
+			return Link{Kind: KindClip, ID: parts[0]}, true
+		}
+		return Link{}, false
+	}
+
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "www."), "m.")
+	if host != "twitch.tv" {
+		return Link{}, false
+	}
+
+	switch {
+	case len(parts) == 2 && parts[0] == "videos" && videoID.MatchString(parts[1]):
+		return Link{Kind: KindVOD, ID: strings.TrimPrefix(parts[1], "v")}, true
+	case len(parts) == 3 && parts[1] == "clip" && channelName.MatchString(parts[0]) && clipSlug.MatchString(parts[2]):
+		return Link{Kind: KindClip, Channel: strings.ToLower(parts[0]), ID: parts[2]}, true
+	case len(parts) == 3 && parts[1] == "v" && channelName.MatchString(parts[0]) && videoID.MatchString(parts[2]):
+		return Link{Kind: KindVOD, Channel: strings.ToLower(parts[0]), ID: parts[2]}, true
+	case len(parts) == 1 && channelName.MatchString(parts[0]) && !reservedPaths[strings.ToLower(parts[0])]:
+		return Link{Kind: KindLive, Channel: strings.ToLower(parts[0])}, true
+	default:
+		return Link{}, false
+	}
+}
+
+// Runner runs yt-dlp with the given arguments and returns its standard output
+type Runner func(ctx context.Context, args ...string) ([]byte, error)
+
+// Provider resolves Twitch links to audio streams with yt-dlp
+type Provider struct {
+	run Runner
+}
+
+// NewProvider creates a provider that runs the yt-dlp binary
+func NewProvider() *Provider {
+	return NewProviderWithRunner(func(ctx context.Context, args ...string) ([]byte, error) {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "yt-dlp", args...)
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return output, nil
+	})
+}
+
+// NewProviderWithRunner creates a provider that runs yt-dlp through run
+func NewProviderWithRunner(run Runner) *Provider {
+	return &Provider{run: run}
+}
+
+// GetProviderName returns the provider's name
+func (p *Provider) GetProviderName() string {
+	return ProviderName
+}
+
+// Capabilities reports that Twitch plays live channels and seekable videos and clips, but can't be searched
+func (p *Provider) Capabilities() types.ProviderCapabilities {
+	return types.ProviderCapabilities{Seek: true, Live: true}
+}
+
+// SupportsURL reports whether a link is a Twitch channel, video or clip
+func (p *Provider) SupportsURL(rawURL string) bool {
+	_, ok := ParseURL(rawURL)
+	return ok
+}
+
+// Search is not supported, Twitch content is only played from links
+func (p *Provider) Search(ctx context.Context, query string, maxResults int) ([]types.AudioSource, error) {
+	return nil, ErrSearchUnsupported
+}
+
+// GetAudioSource resolves a Twitch link to its audio stream. Channel links play the live broadcast and
+// fail with ErrOffline when there is none.
+func (p *Provider) GetAudioSource(ctx context.Context, rawURL string) (*types.AudioSource, error) {
+	link, ok := ParseURL(rawURL)
+	if !ok {
+		return nil, fmt.Errorf("not a Twitch channel, video or clip link: %s", rawURL)
+	}
+
+	output, err := p.run(ctx,
+		// Twitch offers an audio-only rendition of broadcasts and videos, clips only have video
+		"--format", "audio_only/bestaudio/worst",
+		"--print", "title",
+		"--print", "url",
+		"--print", "duration",
+		"--print", "thumbnail",
+		"--print", "uploader",
+		"--print", "webpage_url",
+		"--print", "is_live",
+		"--no-playlist",
+		"--no-download",
+		strings.TrimSpace(rawURL),
+	)
+	if err != nil {
+		if link.Kind == KindLive && strings.Contains(err.Error(), "not currently live") {
+			return nil, fmt.Errorf("%s: %w", link.Channel, ErrOffline)
+		}
+		return nil, fmt.Errorf("yt-dlp extraction failed: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 7 {
+		return nil, fmt.Errorf("invalid yt-dlp output: expected 7 lines, got %d", len(lines))
+	}
+
+	track := &types.AudioSource{
+		Title:     lines[0],
+		StreamURL: lines[1],
+		Thumbnail: lines[3],
+		Uploader:  lines[4],
+		URL:       lines[5],
+		Provider:  ProviderName,
+		Live:      lines[6] == "True",
+	}
+	if link.Kind == KindLive && !track.Live {
+		return nil, fmt.Errorf("%s: %w", link.Channel, ErrOffline)
+	}
+	// yt-dlp prints the length in seconds, "NA" for broadcasts
+	if !track.Live {
+		track.Duration, _ = types.ParseDuration(lines[2])
+	}
+	if track.Thumbnail == "NA" {
+		track.Thumbnail = ""
+	}
+	return track, nil
+}
@@ -0,0 +1,107 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+var _ types.AudioProvider = (*Provider)(nil)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		link Link
+	}{
+		{"https://www.twitch.tv/SomeStreamer", Link{Kind: KindLive, Channel: "somestreamer"}},
+		{"https://m.twitch.tv/some_streamer/", Link{Kind: KindLive, Channel: "some_streamer"}},
+		{"https://twitch.tv/videos/1234567890", Link{Kind: KindVOD, ID: "1234567890"}},
+		{"https://www.twitch.tv/videos/v1234?t=1h2m", Link{Kind: KindVOD, ID: "1234"}},
+		{"https://www.twitch.tv/streamer/v/987", Link{Kind: KindVOD, Channel: "streamer", ID: "987"}},
+		{"https://clips.twitch.tv/FunnyClipSlug-abc_123", Link{Kind: KindClip, ID: "FunnyClipSlug-abc_123"}},
+		{"https://www.twitch.tv/streamer/clip/FunnyClipSlug", Link{Kind: KindClip, Channel: "streamer", ID: "FunnyClipSlug"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			link, ok := ParseURL(tt.url)
+			require.True(t, ok)
+			assert.Equal(t, tt.link, link)
+		})
+	}
+
+	for _, unsupported := range []string{
+		"https://www.twitch.tv/",
+		"https://www.twitch.tv/directory",
+		"https://www.twitch.tv/settings",
+		"https://www.twitch.tv/videos/abc",
+		"https://www.twitch.tv/streamer/videos",
+		"https://www.twitch.tv/ab",
+		"https://www.youtube.com/watch?v=abc",
+		"https://nottwitch.tv/streamer",
+		"ftp://twitch.tv/streamer",
+		"streamer",
+	} {
+		_, ok := ParseURL(unsupported)
+		assert.False(t, ok, unsupported)
+	}
+}
+
+func fakeRunner(output string, err error) (Runner, *[]string) {
+	var args []string
+	return func(ctx context.Context, a ...string) ([]byte, error) {
+		args = a
+		return []byte(output), err
+	}, &args
+}
+
+func TestGetAudioSourceLive(t *testing.T) {
+	run, args := fakeRunner("Streamer live 2025-03-01\nhttps://video.example/live.m3u8\nNA\nhttps://img.example/thumb.jpg\nStreamer\nhttps://www.twitch.tv/streamer\nTrue\n", nil)
+	provider := NewProviderWithRunner(run)
+	assert.True(t, provider.SupportsURL("https://www.twitch.tv/streamer"))
+	assert.Equal(t, ProviderName, provider.GetProviderName())
+
+	track, err := provider.GetAudioSource(context.Background(), "https://www.twitch.tv/streamer")
+	require.NoError(t, err)
+	assert.True(t, track.Live)
+	assert.Empty(t, track.Duration, "live broadcasts have no length")
+	assert.Equal(t, "https://video.example/live.m3u8", track.StreamURL)
+	assert.Equal(t, "https://www.twitch.tv/streamer", track.URL)
+	assert.Equal(t, ProviderName, track.Provider)
+	assert.Contains(t, *args, "audio_only/bestaudio/worst")
+}
+
+func TestGetAudioSourceVOD(t *testing.T) {
+	run, _ := fakeRunner("Past broadcast\nhttps://video.example/vod.m3u8\n7265.5\nNA\nStreamer\nhttps://www.twitch.tv/videos/123\nFalse\n", nil)
+
+	track, err := NewProviderWithRunner(run).GetAudioSource(context.Background(), "https://www.twitch.tv/videos/123")
+	require.NoError(t, err)
+	assert.False(t, track.Live)
+	assert.Equal(t, types.Seconds(7265.5), track.Duration)
+	assert.Empty(t, track.Thumbnail)
+}
+
+func TestGetAudioSourceOffline(t *testing.T) {
+	run, _ := fakeRunner("", errors.New("exit status 1: ERROR: [twitch:stream] streamer: The channel is not currently live"))
+	_, err := NewProviderWithRunner(run).GetAudioSource(context.Background(), "https://www.twitch.tv/streamer")
+	assert.ErrorIs(t, err, ErrOffline)
+
+	run, _ = fakeRunner("Rerun\nhttps://video.example/vod.m3u8\n60\nNA\nStreamer\nhttps://www.twitch.tv/streamer\nFalse\n", nil)
+	_, err = NewProviderWithRunner(run).GetAudioSource(context.Background(), "https://www.twitch.tv/streamer")
+	assert.ErrorIs(t, err, ErrOffline, "channel links only play live broadcasts")
+}
+
+func TestGetAudioSourceRejectsOtherLinks(t *testing.T) {
+	run, _ := fakeRunner("", nil)
+	provider := NewProviderWithRunner(run)
+
+	_, err := provider.GetAudioSource(context.Background(), "https://www.youtube.com/watch?v=abc")
+	assert.Error(t, err)
+
+	_, err = provider.Search(context.Background(), "streamer", 5)
+	assert.ErrorIs(t, err, ErrSearchUnsupported)
+}
@@ -0,0 +1,105 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is the length of a track, zero when it isn't known (live streams, files without metadata).
+// It is saved as seconds like yt-dlp reports them, and read back from seconds or a clock such as "3:45",
+// so tracks saved while durations were display strings still load.
+type Duration time.Duration
+
+// Seconds converts a length in seconds, as yt-dlp and most APIs give it, into a Duration
+func Seconds(seconds float64) Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return Duration(seconds * float64(time.Second))
+}
+
+// ParseDuration reads a length given in seconds ("213", "7265.5") or as a clock ("3:33", "1:02:03").
+// Empty values and yt-dlp's "NA" are not known.
+func ParseDuration(value string) (Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "NA" {
+		return 0, false
+	}
+
+	if !strings.Contains(value, ":") {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds < 0 {
+			return 0, false
+		}
+		return Seconds(seconds), true
+	}
+
+	total := 0
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		total = total*60 + n
+	}
+	return Duration(time.Duration(total) * time.Second), true
+}
+
+// Known reports whether the length is known
+func (d Duration) Known() bool {
+	return d > 0
+}
+
+// Std returns the length as a time.Duration for arithmetic with playback positions
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// String shows the length as a clock, "" when it isn't known
+func (d Duration) String() string {
+	if !d.Known() {
+		return ""
+	}
+	return FormatClock(d.Std())
+}
+
+// FormatClock shows a length or playback position as "m:ss", or "h:mm:ss" from an hour on
+func FormatClock(d time.Duration) string {
+	seconds := max(int(d.Round(time.Second).Seconds()), 0)
+	hours, minutes, seconds := seconds/3600, seconds/60%60, seconds%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
+
+// MarshalJSON saves the length in seconds
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).Seconds())
+}
+
+// UnmarshalJSON reads a length in seconds, or a string in any form ParseDuration reads. Unreadable
+// strings are an unknown length rather than an error, like they always were.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*d = 0
+		return nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*d = Seconds(seconds)
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be seconds or a string: %w", err)
+	}
+	*d, _ = ParseDuration(value)
+	return nil
+}
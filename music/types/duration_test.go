@@ -0,0 +1,58 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"213", 213 * time.Second, true},
+		{"213.5", 213500 * time.Millisecond, true},
+		{"3:33", 213 * time.Second, true},
+		{"1:02:03", time.Hour + 2*time.Minute + 3*time.Second, true},
+		{"", 0, false},
+		{"NA", 0, false},
+		{"3:xx", 0, false},
+		{"-5", 0, false},
+	}
+
+	for _, tt := range tests {
+		duration, ok := ParseDuration(tt.value)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.Equal(t, tt.expected, duration.Std(), tt.value)
+	}
+}
+
+func TestDurationString(t *testing.T) {
+	assert.Equal(t, "", Duration(0).String(), "unknown lengths show nothing")
+	assert.Equal(t, "3:05", Seconds(185).String())
+	assert.Equal(t, "1:03:30", Seconds(3810).String())
+	assert.Equal(t, "0:00", FormatClock(0))
+	assert.Equal(t, "0:00", FormatClock(-time.Second))
+}
+
+func TestDurationJSON(t *testing.T) {
+	data, err := json.Marshal(AudioSource{Title: "Song", Duration: Seconds(212.5)})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Duration":212.5`)
+
+	var track AudioSource
+	require.NoError(t, json.Unmarshal(data, &track))
+	assert.Equal(t, Seconds(212.5), track.Duration)
+
+	// Tracks saved while durations were display strings
+	for saved, expected := range map[string]Duration{`"212"`: Seconds(212), `"3:33"`: Seconds(213), `""`: 0, `null`: 0} {
+		require.NoError(t, json.Unmarshal([]byte(`{"Duration":`+saved+`}`), &track), saved)
+		assert.Equal(t, expected, track.Duration, saved)
+	}
+	assert.Error(t, json.Unmarshal([]byte(`{"Duration":true}`), &track))
+}
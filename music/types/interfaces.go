@@ -2,8 +2,11 @@ package types
 
 import (
 	"context"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/tracing"
 )
 
 // SessionInterface defines the methods needed for music functionality specifically
@@ -73,13 +76,22 @@ type AudioPlayer interface {
 type Queue interface {
 	Add(source AudioSource)
 	Remove(position int) error
+	Move(from, to int) error
+	Swap(a, b int) error
+	Deduplicate() int
+	RemoveByRequester(userID string) int
+	SetFair(enabled bool)
 	Get(position int) (*AudioSource, error)
 	GetAll() []AudioSource
 	Clear()
-	Shuffle()
+	Reset()
+	Shuffle() uint64
+	ShuffleWithSeed(seed uint64)
+	Unshuffle() error
 	Next() (*AudioSource, bool)
 	Size() int
 	IsEmpty() bool
+	Undo() (string, error)
 }
 
 // AudioProvider defines the interface for audio source providers (YouTube, etc.)
@@ -88,6 +100,16 @@ type AudioProvider interface {
 	Search(ctx context.Context, query string, maxResults int) ([]AudioSource, error)
 	SupportsURL(url string) bool
 	GetProviderName() string
+	Capabilities() ProviderCapabilities
+}
+
+// ProviderCapabilities describes what an audio provider's tracks support, so callers can ask instead of
+// checking provider names
+type ProviderCapabilities struct {
+	Search    bool // Queries other than links can be searched
+	Playlists bool // Playlist links are expanded into their tracks
+	Seek      bool // Tracks can be started part of the way in
+	Live      bool // Tracks can be live streams without an end
 }
 
 // PlayerStatus represents the current state of a music player
@@ -123,14 +145,19 @@ func (s PlayerStatus) String() string {
 
 // AudioSource represents a playable audio source
 type AudioSource struct {
-	Title       string
-	URL         string
-	Duration    string
-	Thumbnail   string
-	Provider    string
-	RequestedBy string
-	StreamURL   string                 // The actual streaming URL for playback
-	Metadata    map[string]interface{} // Additional metadata for provider-specific data
+	Title         string
+	URL           string
+	Duration      Duration // Length of the track, zero when it isn't known
+	Thumbnail     string
+	Provider      string
+	Uploader      string
+	RequestedBy   string
+	Priority      bool                   // Queued ahead of normal requests (boosters, premium role)
+	StreamURL     string                 // The actual streaming URL for playback
+	StreamExpires time.Time              // When the stream URL stops working, zero when it doesn't say
+	Live          bool                   // Live stream without an end, it can't be seeked or downloaded
+	Metadata      map[string]interface{} // Additional metadata for provider-specific data
+	Trace         tracing.SpanContext    `json:"-"` // Trace of the request that queued the track, continued when it starts
 }
 
 // VoiceChannelError represents voice channel specific errors
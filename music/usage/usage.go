@@ -0,0 +1,294 @@
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// UnknownProvider is reported for tracks that don't name the provider they came from
+const UnknownProvider = "unknown"
+
+// DefaultPath is where the counters are saved when MUSIC_USAGE_FILE is not set
+const DefaultPath = "data/usage.json"
+
+// saveInterval is the longest counted traffic waits to be saved, so a crash loses at most this much
+const saveInterval = time.Minute
+
+// monthLayout keys the accounting period, a calendar month in UTC
+const monthLayout = "2006-01"
+
+// Entry is the traffic of one guild or provider in a report
+type Entry struct {
+	Key   string
+	Bytes int64
+}
+
+// Report is a snapshot of the traffic counters
+type Report struct {
+	Month      string  // Accounting period the monthly counters cover, "2006-01"
+	MonthBytes int64   // Streamed this month across all guilds
+	TotalBytes int64   // Streamed since the meter was created
+	Guilds     []Entry // This month's traffic per guild, largest first
+	Providers  []Entry // This month's traffic per provider, largest first
+	MonthlyCap int64   // Per-guild monthly limit, 0 when unlimited
+}
+
+// meterFile is the saved form of the counters
+type meterFile struct {
+	Month     string           `json:"month"`
+	Guilds    map[string]int64 `json:"guilds"`
+	Providers map[string]int64 `json:"providers"`
+	Total     int64            `json:"total"`
+}
+
+// Meter counts the audio bytes streamed per guild and per provider and enforces an optional
+// monthly per-guild cap. Counters are kept in memory and, when loaded from a file, saved to it at
+// most every saveInterval while traffic is counted and on Save.
+type Meter struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	path       string
+	saved      time.Time // When the counters were last saved
+	dirty      bool      // Counted since the last save
+	month      string
+	guilds     map[string]int64
+	providers  map[string]int64
+	total      int64
+	monthlyCap int64
+}
+
+// NewMeter creates a meter; a monthlyCap of 0 leaves guilds unlimited
+func NewMeter(monthlyCap int64) *Meter {
+	return newMeterWithClock(monthlyCap, time.Now)
+}
+
+func newMeterWithClock(monthlyCap int64, now func() time.Time) *Meter {
+	return &Meter{
+		now:        now,
+		month:      now().UTC().Format(monthLayout),
+		guilds:     make(map[string]int64),
+		providers:  make(map[string]int64),
+		monthlyCap: max(0, monthlyCap),
+	}
+}
+
+// LoadMeter opens the counters saved at path; a missing file starts empty. Counters of an earlier
+// month start over, the total is kept.
+func LoadMeter(path string, monthlyCap int64) (*Meter, error) {
+	return loadMeterWithClock(path, monthlyCap, time.Now)
+}
+
+func loadMeterWithClock(path string, monthlyCap int64, now func() time.Time) (*Meter, error) {
+	meter := newMeterWithClock(monthlyCap, now)
+	meter.path = path
+	meter.saved = now()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return meter, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage counters: %w", err)
+	}
+
+	var saved meterFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse usage counters %s: %w", path, err)
+	}
+	meter.total = saved.Total
+	if saved.Month == meter.month {
+		for guildID, bytes := range saved.Guilds {
+			meter.guilds[guildID] = bytes
+		}
+		for provider, bytes := range saved.Providers {
+			meter.providers[provider] = bytes
+		}
+	}
+	return meter, nil
+}
+
+// SetMonthlyCap changes the per-guild monthly limit; 0 removes it
+func (m *Meter) SetMonthlyCap(monthlyCap int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.monthlyCap = max(0, monthlyCap)
+}
+
+// Add counts n bytes streamed to a guild from a provider
+func (m *Meter) Add(guildID, provider string, n int64) {
+	if n <= 0 {
+		return
+	}
+	if provider == "" {
+		provider = UnknownProvider
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rollover()
+	m.guilds[guildID] += n
+	m.providers[provider] += n
+	m.total += n
+	m.dirty = true
+
+	if m.path != "" && m.now().Sub(m.saved) >= saveInterval {
+		if err := m.save(); err != nil {
+			utils.LogWarn("Music usage counters not saved: %v", err)
+		}
+	}
+}
+
+// Save writes counted traffic that isn't saved yet, such as before the bot shuts down
+func (m *Meter) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.path == "" || !m.dirty {
+		return nil
+	}
+	return m.save()
+}
+
+// save writes the counters and marks them saved (caller holds the lock)
+func (m *Meter) save() error {
+	// Later attempts wait for the next interval too, instead of retrying on every read of the stream
+	m.saved = m.now()
+
+	data, err := json.Marshal(meterFile{Month: m.month, Guilds: m.guilds, Providers: m.providers, Total: m.total})
+	if err != nil {
+		return fmt.Errorf("failed to encode usage counters: %w", err)
+	}
+	if err := utils.WriteFileAtomic(m.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save usage counters: %w", err)
+	}
+	m.dirty = false
+	return nil
+}
+
+// GuildBytes returns what a guild streamed this month
+func (m *Meter) GuildBytes(guildID string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rollover()
+	return m.guilds[guildID]
+}
+
+// Exceeded reports whether a guild has used up its monthly cap
+func (m *Meter) Exceeded(guildID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rollover()
+	return m.monthlyCap > 0 && m.guilds[guildID] >= m.monthlyCap
+}
+
+// Report returns a snapshot of the counters
+func (m *Meter) Report() Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rollover()
+	report := Report{
+		Month:      m.month,
+		TotalBytes: m.total,
+		Guilds:     sortedEntries(m.guilds),
+		Providers:  sortedEntries(m.providers),
+		MonthlyCap: m.monthlyCap,
+	}
+	for _, bytes := range m.guilds {
+		report.MonthBytes += bytes
+	}
+	return report
+}
+
+// rollover starts new monthly counters when the month changed (caller holds the lock)
+func (m *Meter) rollover() {
+	month := m.now().UTC().Format(monthLayout)
+	if month == m.month {
+		return
+	}
+	m.month = month
+	m.guilds = make(map[string]int64)
+	m.providers = make(map[string]int64)
+	m.dirty = true
+}
+
+func sortedEntries(counters map[string]int64) []Entry {
+	entries := make([]Entry, 0, len(counters))
+	for key, bytes := range counters {
+		entries = append(entries, Entry{Key: key, Bytes: bytes})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Bytes != entries[j].Bytes {
+			return entries[i].Bytes > entries[j].Bytes
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// countingReader reports the size of every read to a callback
+type countingReader struct {
+	reader io.Reader
+	count  func(n int64)
+}
+
+// NewReader wraps r so every read is reported to count, such as a Meter.Add for the track being played
+func NewReader(r io.Reader, count func(n int64)) io.Reader {
+	return &countingReader{reader: r, count: count}
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	if n > 0 {
+		c.count(int64(n))
+	}
+	return n, err
+}
+
+// sizeUnits are the suffixes ParseSize accepts, in binary multiples
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize reads a byte count such as "500MB", "20GB" or "1.5TB" (binary units); a bare number is bytes
+func ParseSize(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	if text == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(number * float64(multiplier)), nil
+}
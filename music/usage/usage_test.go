@@ -0,0 +1,144 @@
+package usage
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeterCountsPerGuildAndProvider(t *testing.T) {
+	meter := NewMeter(0)
+	meter.Add("guild1", "youtube", 300)
+	meter.Add("guild2", "youtube", 100)
+	meter.Add("guild2", "", 50)
+	meter.Add("guild1", "youtube", 0)
+
+	assert.Equal(t, int64(300), meter.GuildBytes("guild1"))
+	assert.Equal(t, int64(150), meter.GuildBytes("guild2"))
+	assert.Zero(t, meter.GuildBytes("guild3"))
+
+	report := meter.Report()
+	assert.Equal(t, int64(450), report.MonthBytes)
+	assert.Equal(t, int64(450), report.TotalBytes)
+	assert.Equal(t, []Entry{{"guild1", 300}, {"guild2", 150}}, report.Guilds)
+	assert.Equal(t, []Entry{{"youtube", 400}, {UnknownProvider, 50}}, report.Providers)
+	assert.Zero(t, report.MonthlyCap)
+}
+
+func TestMeterMonthlyCap(t *testing.T) {
+	meter := NewMeter(1000)
+	assert.False(t, meter.Exceeded("guild1"))
+
+	meter.Add("guild1", "youtube", 999)
+	assert.False(t, meter.Exceeded("guild1"))
+	meter.Add("guild1", "youtube", 1)
+	assert.True(t, meter.Exceeded("guild1"))
+	assert.False(t, meter.Exceeded("guild2"), "caps apply per guild")
+
+	meter.SetMonthlyCap(0)
+	assert.False(t, meter.Exceeded("guild1"), "no cap means unlimited")
+}
+
+func TestMeterStartsOverEachMonth(t *testing.T) {
+	now := time.Date(2025, time.January, 31, 23, 0, 0, 0, time.UTC)
+	meter := newMeterWithClock(500, func() time.Time { return now })
+
+	meter.Add("guild1", "youtube", 500)
+	assert.True(t, meter.Exceeded("guild1"))
+	assert.Equal(t, "2025-01", meter.Report().Month)
+
+	now = now.Add(2 * time.Hour)
+	assert.False(t, meter.Exceeded("guild1"))
+
+	report := meter.Report()
+	assert.Equal(t, "2025-02", report.Month)
+	assert.Zero(t, report.MonthBytes)
+	assert.Empty(t, report.Guilds)
+	assert.Equal(t, int64(500), report.TotalBytes, "the running total survives the new month")
+}
+
+func TestMeterSavesAndLoadsCounters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	now := time.Date(2025, time.January, 10, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	meter, err := loadMeterWithClock(path, 1000, clock)
+	require.NoError(t, err)
+	meter.Add("guild1", "youtube", 300)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "counting doesn't write the file on every read")
+
+	now = now.Add(saveInterval)
+	meter.Add("guild1", "youtube", 200)
+	reloaded, err := loadMeterWithClock(path, 1000, clock)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), reloaded.GuildBytes("guild1"))
+	assert.False(t, reloaded.Exceeded("guild1"))
+
+	meter.Add("guild2", "radio", 50)
+	require.NoError(t, meter.Save())
+	reloaded, err = loadMeterWithClock(path, 500, clock)
+	require.NoError(t, err)
+	report := reloaded.Report()
+	assert.Equal(t, []Entry{{"guild1", 500}, {"guild2", 50}}, report.Guilds)
+	assert.Equal(t, []Entry{{"youtube", 500}, {"radio", 50}}, report.Providers)
+	assert.Equal(t, int64(550), report.TotalBytes)
+	assert.True(t, reloaded.Exceeded("guild1"), "a restart doesn't lift the monthly cap")
+
+	// Counters of an earlier month start over when loaded, the total is kept
+	now = time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)
+	reloaded, err = loadMeterWithClock(path, 500, clock)
+	require.NoError(t, err)
+	report = reloaded.Report()
+	assert.Empty(t, report.Guilds)
+	assert.Equal(t, int64(550), report.TotalBytes)
+}
+
+func TestLoadMeterWithoutFile(t *testing.T) {
+	meter, err := LoadMeter(filepath.Join(t.TempDir(), "missing.json"), 0)
+	require.NoError(t, err)
+	assert.Zero(t, meter.Report().TotalBytes)
+	require.NoError(t, meter.Save(), "nothing counted, nothing to save")
+}
+
+func TestNewReader(t *testing.T) {
+	var counted int64
+	reader := NewReader(bytes.NewReader(make([]byte, 10000)), func(n int64) { counted += n })
+
+	read, err := io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10000), read)
+	assert.Equal(t, int64(10000), counted)
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"1024", 1024},
+		{"500MB", 500 << 20},
+		{"20gb", 20 << 30},
+		{"1.5 TB", 3 << 39},
+		{"64KB", 64 << 10},
+		{"10B", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			size, err := ParseSize(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+
+	for _, invalid := range []string{"", "lots", "-5GB", "GB"} {
+		_, err := ParseSize(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
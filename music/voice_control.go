@@ -0,0 +1,97 @@
+package music
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/music/settings"
+	"pxnx-discord-bot/music/voicecontrol"
+	"pxnx-discord-bot/utils"
+)
+
+// VoiceHandler runs what a member said in a guild's voice channel, given the guild's wake word
+type VoiceHandler func(guildID, wakeWord string, utterance voicecontrol.Utterance)
+
+// UseVoiceControl lets guilds that turn voice control on command the player by voice, handler transcribes
+// what members say and runs their commands. Without it the bot joins voice channels deafened.
+func (sp *SimplePlayer) UseVoiceControl(handler VoiceHandler) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	sp.voiceHandler = handler
+}
+
+// VoiceControlAvailable reports whether spoken commands can be understood, guilds can turn voice control
+// on either way
+func (sp *SimplePlayer) VoiceControlAvailable() bool {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	return sp.voiceHandler != nil
+}
+
+// SetVoiceControl turns voice control on or off for a guild and saves the setting. The bot only hears the
+// channel when it joins undeafened, so the setting applies from the next time it joins a voice channel.
+func (sp *SimplePlayer) SetVoiceControl(guildID string, enabled bool) error {
+	sp.mu.RLock()
+	store := sp.settings
+	sp.mu.RUnlock()
+
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.VoiceControl = enabled })
+	return err
+}
+
+// SetWakeWord changes the word that starts spoken commands in a guild and saves it, empty for the default
+func (sp *SimplePlayer) SetWakeWord(guildID, wakeWord string) error {
+	sp.mu.RLock()
+	store := sp.settings
+	sp.mu.RUnlock()
+
+	wakeWord = strings.ToLower(strings.TrimSpace(wakeWord))
+	if wakeWord == voicecontrol.DefaultWakeWord {
+		wakeWord = ""
+	}
+	_, err := store.Update(guildID, func(guild *settings.Guild) { guild.WakeWord = wakeWord })
+	return err
+}
+
+// VoiceControl reports whether voice control is on for a guild, and the word that starts its spoken commands
+func (sp *SimplePlayer) VoiceControl(guildID string) (bool, string) {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+	guild := sp.settings.Get(guildID)
+	if guild.WakeWord == "" {
+		return guild.VoiceControl, voicecontrol.DefaultWakeWord
+	}
+	return guild.VoiceControl, guild.WakeWord
+}
+
+// listening reports whether the bot listens in a guild's voice channel, it joins deafened otherwise
+// (caller holds sp.mu)
+func (sp *SimplePlayer) listening(guildID string) bool {
+	return sp.voiceHandler != nil && sp.settings.Get(guildID).VoiceControl
+}
+
+// listen starts voice control on a guild's voice connection, nil when the guild doesn't use it
+// (caller holds sp.mu)
+func (sp *SimplePlayer) listen(guildID string, conn *discordgo.VoiceConnection) *voicecontrol.Listener {
+	if !sp.listening(guildID) || conn.OpusRecv == nil {
+		return nil
+	}
+
+	handler := sp.voiceHandler
+	listener := voicecontrol.NewListener(func(utterance voicecontrol.Utterance) {
+		if enabled, wakeWord := sp.VoiceControl(guildID); enabled {
+			handler(guildID, wakeWord, utterance)
+		}
+	})
+	utils.SafeGo("voicecontrol.listen", func() { listener.Listen(conn.OpusRecv) })
+	utils.LogInfo("Listening for voice commands in guild %s", guildID)
+	return listener
+}
+
+// stopListening ends voice control on the player's voice connection
+func (vp *VoicePlayer) stopListening() {
+	if vp.listener != nil {
+		vp.listener.Close()
+	}
+}
@@ -0,0 +1,172 @@
+// Package voicecontrol listens to the members in the bot's voice channel and turns what they say after a
+// wake word into music player actions. The bot receives each member's audio as Opus frames, which are cut
+// into utterances at pauses and handed on as Ogg Opus files for transcription.
+package voicecontrol
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"pxnx-discord-bot/utils"
+)
+
+// Utterance bounds: a pause this long ends one, shorter ones are noise or coughs and longer ones are
+// conversation rather than commands
+const (
+	DefaultPause   = 800 * time.Millisecond
+	MinUtterance   = 400 * time.Millisecond
+	MaxUtterance   = 8 * time.Second
+	checkInterval  = 100 * time.Millisecond
+	pendingHandled = 2 // Utterances waiting for the handler, newer ones are dropped while it's busy
+)
+
+// silenceFrame is the Opus frame Discord clients send when a member stops talking
+var silenceFrame = []byte{0xf8, 0xff, 0xfe}
+
+// Utterance is what one member said without pausing
+type Utterance struct {
+	UserID   string
+	Audio    []byte // Ogg Opus
+	Duration time.Duration
+}
+
+// Listener cuts the audio of a voice connection into utterances and hands them to a handler one at a time
+type Listener struct {
+	handle  func(Utterance)
+	pause   time.Duration
+	now     func() time.Time
+	pending chan Utterance
+	stop    chan struct{}
+	once    sync.Once
+
+	mu       sync.Mutex
+	speakers map[uint32]string   // Member talking on each SSRC, from speaking updates
+	talking  map[uint32]*talking // Utterances in progress by SSRC
+}
+
+// talking is an utterance in progress
+type talking struct {
+	frames [][]byte
+	last   time.Time
+}
+
+// NewListener creates a listener handing utterances to handle, which runs in its own goroutine
+func NewListener(handle func(Utterance)) *Listener {
+	return &Listener{
+		handle:   handle,
+		pause:    DefaultPause,
+		now:      time.Now,
+		pending:  make(chan Utterance, pendingHandled),
+		stop:     make(chan struct{}),
+		speakers: make(map[uint32]string),
+		talking:  make(map[uint32]*talking),
+	}
+}
+
+// SetSpeaker records which member talks on an SSRC, Discord reports it when they start talking
+func (l *Listener) SetSpeaker(ssrc uint32, userID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.speakers[ssrc] = userID
+}
+
+// Listen reads packets until the listener is closed, handing utterances on as members pause
+func (l *Listener) Listen(packets <-chan *discordgo.Packet) {
+	// A handler panicking on one utterance doesn't stop the ones after it
+	utils.SafeGoWithRestart("voicecontrol.handle", utils.RestartPolicy{MaxRestarts: -1, Backoff: time.Second}, l.run)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case packet, ok := <-packets:
+			if !ok {
+				return
+			}
+			l.Add(packet.SSRC, packet.Opus)
+		case <-ticker.C:
+			l.Flush()
+		}
+	}
+}
+
+// Close stops listening, dropping utterances in progress
+func (l *Listener) Close() {
+	l.once.Do(func() { close(l.stop) })
+}
+
+// Add adds an Opus frame a member sent
+func (l *Listener) Add(ssrc uint32, frame []byte) {
+	if len(frame) == 0 || bytes.Equal(frame, silenceFrame) {
+		return
+	}
+
+	l.mu.Lock()
+	t := l.talking[ssrc]
+	if t == nil {
+		t = &talking{}
+		l.talking[ssrc] = t
+	}
+	t.frames = append(t.frames, bytes.Clone(frame))
+	t.last = l.now()
+	full := frameDuration(len(t.frames)) >= MaxUtterance
+	if full {
+		delete(l.talking, ssrc)
+	}
+	l.mu.Unlock()
+
+	// Commands are short, whoever talks this long isn't giving one
+	if full {
+		utils.LogDebug("Dropped an utterance longer than %s", MaxUtterance)
+	}
+}
+
+// Flush hands on the utterances of members who paused
+func (l *Listener) Flush() {
+	now := l.now()
+	var done []Utterance
+
+	l.mu.Lock()
+	for ssrc, t := range l.talking {
+		if now.Sub(t.last) < l.pause {
+			continue
+		}
+		delete(l.talking, ssrc)
+		duration := frameDuration(len(t.frames))
+		userID := l.speakers[ssrc]
+		if duration < MinUtterance || userID == "" {
+			continue
+		}
+		done = append(done, Utterance{UserID: userID, Audio: OggOpus(t.frames), Duration: duration})
+	}
+	l.mu.Unlock()
+
+	for _, utterance := range done {
+		select {
+		case l.pending <- utterance:
+		default:
+			utils.LogDebug("Dropped an utterance by %s while busy with earlier ones", utterance.UserID)
+		}
+	}
+}
+
+// run hands pending utterances to the handler until the listener is closed
+func (l *Listener) run() {
+	for {
+		select {
+		case <-l.stop:
+			return
+		case utterance := <-l.pending:
+			l.handle(utterance)
+		}
+	}
+}
+
+// frameDuration is how long count Opus frames play
+func frameDuration(count int) time.Duration {
+	return time.Duration(count) * time.Second * samplesPerFrame / sampleRate
+}
@@ -0,0 +1,122 @@
+package voicecontrol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testListener creates a listener with a fake clock whose handler records utterances
+func testListener() (*Listener, *time.Time, *[]Utterance) {
+	now := time.Date(2026, time.March, 1, 20, 0, 0, 0, time.UTC)
+	var handled []Utterance
+	listener := NewListener(func(utterance Utterance) { handled = append(handled, utterance) })
+	listener.now = func() time.Time { return now }
+	return listener, &now, &handled
+}
+
+// talk adds count frames on an SSRC, 20 ms apart
+func talk(l *Listener, now *time.Time, ssrc uint32, count int) {
+	for range count {
+		l.Add(ssrc, []byte{0xfc, 0x01, 0x02})
+		*now = now.Add(20 * time.Millisecond)
+	}
+}
+
+// drain runs the handler on the pending utterances
+func drain(l *Listener) {
+	for {
+		select {
+		case utterance := <-l.pending:
+			l.handle(utterance)
+		default:
+			return
+		}
+	}
+}
+
+func TestListenerCutsUtterancesAtPauses(t *testing.T) {
+	listener, now, handled := testListener()
+	listener.SetSpeaker(1, "user_1")
+	listener.SetSpeaker(2, "user_2")
+
+	talk(listener, now, 1, 50)
+	listener.Add(2, silenceFrame)
+	talk(listener, now, 2, 5) // Too short to be a command
+	listener.Flush()
+	drain(listener)
+	assert.Empty(t, *handled, "nobody paused yet")
+
+	*now = now.Add(DefaultPause)
+	listener.Flush()
+	drain(listener)
+	require.Len(t, *handled, 1)
+	assert.Equal(t, "user_1", (*handled)[0].UserID)
+	assert.Equal(t, time.Second, (*handled)[0].Duration)
+	assert.True(t, bytes.HasPrefix((*handled)[0].Audio, []byte("OggS")))
+
+	// Audio from members Discord hasn't named is dropped
+	talk(listener, now, 3, 50)
+	*now = now.Add(DefaultPause)
+	listener.Flush()
+	drain(listener)
+	assert.Len(t, *handled, 1)
+}
+
+func TestListenerDropsLongUtterances(t *testing.T) {
+	listener, now, handled := testListener()
+	listener.SetSpeaker(1, "user_1")
+
+	talk(listener, now, 1, int(MaxUtterance/(20*time.Millisecond))+10)
+	*now = now.Add(DefaultPause)
+	listener.Flush()
+	drain(listener)
+	assert.Empty(t, *handled, "only the tail after the limit remains, too short to be a command")
+}
+
+func TestOggOpus(t *testing.T) {
+	frames := make([][]byte, 300)
+	for n := range frames {
+		frames[n] = bytes.Repeat([]byte{byte(n)}, 100)
+	}
+	frames[10] = bytes.Repeat([]byte{1}, 600) // Takes three lacing values
+
+	data := OggOpus(frames)
+	var pages, packets int
+	var granule uint64
+	for len(data) > 0 {
+		require.True(t, bytes.HasPrefix(data, []byte("OggS")))
+		segments := int(data[26])
+		header := 27 + segments
+		size := 0
+		for _, lacing := range data[27:header] {
+			size += int(lacing)
+			if lacing < 255 {
+				packets++
+			}
+		}
+
+		page := bytes.Clone(data[:header+size])
+		checksum := binary.LittleEndian.Uint32(page[22:])
+		binary.LittleEndian.PutUint32(page[22:], 0)
+		var crc uint32
+		for _, b := range page {
+			crc = crc<<8 ^ oggCRC[byte(crc>>24)^b]
+		}
+		assert.Equal(t, checksum, crc, "page %d checksum", pages)
+
+		granule = binary.LittleEndian.Uint64(data[6:])
+		if pages == 0 {
+			assert.Equal(t, "OpusHead", string(data[header:header+8]))
+		}
+		data = data[header+size:]
+		pages++
+	}
+	assert.Equal(t, 302, packets, "the two headers and every frame")
+	assert.Equal(t, uint64(300*samplesPerFrame), granule)
+	assert.Greater(t, pages, 3)
+}
@@ -0,0 +1,125 @@
+package voicecontrol
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Discord sends stereo Opus in 20 ms frames at 48 kHz
+const (
+	sampleRate      = 48000
+	channels        = 2
+	samplesPerFrame = sampleRate / 50
+)
+
+// maxSegments is how many lacing values an Ogg page holds
+const maxSegments = 255
+
+// oggSerial identifies the single stream of the files written
+const oggSerial = 0x70786e78
+
+// oggCRC is the Ogg checksum table, CRC-32 with polynomial 0x04c11db7 and no bit reflection
+var oggCRC = func() (table [256]uint32) {
+	for n := range table {
+		crc := uint32(n) << 24
+		for range 8 {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+		table[n] = crc
+	}
+	return table
+}()
+
+// OggOpus wraps Opus frames in an Ogg Opus file, the format Discord voice messages use, which ffmpeg and
+// the transcription APIs read without decoding the audio first
+func OggOpus(frames [][]byte) []byte {
+	var out bytes.Buffer
+	w := oggWriter{out: &out}
+
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1 // Version
+	head[9] = channels
+	binary.LittleEndian.PutUint32(head[12:], sampleRate)
+	w.page([][]byte{head}, 0, 0x02)
+
+	vendor := "pxnx-discord-bot"
+	tags := make([]byte, 8+4+len(vendor)+4)
+	copy(tags, "OpusTags")
+	binary.LittleEndian.PutUint32(tags[8:], uint32(len(vendor)))
+	copy(tags[12:], vendor)
+	w.page([][]byte{tags}, 0, 0)
+
+	var granule int64
+	for len(frames) > 0 {
+		count, segments := 0, 0
+		for count < len(frames) && segments+lacingSize(frames[count]) <= maxSegments {
+			segments += lacingSize(frames[count])
+			count++
+		}
+		if count == 0 {
+			count = 1 // A frame too large for one page is cut short, Discord's frames are far smaller
+		}
+		granule += int64(count * samplesPerFrame)
+		headerType := byte(0)
+		if count == len(frames) {
+			headerType = 0x04
+		}
+		w.page(frames[:count], granule, headerType)
+		frames = frames[count:]
+	}
+	return out.Bytes()
+}
+
+// lacingSize is how many lacing values a packet takes
+func lacingSize(packet []byte) int {
+	return len(packet)/255 + 1
+}
+
+// oggWriter writes the pages of one logical stream
+type oggWriter struct {
+	out      *bytes.Buffer
+	sequence uint32
+}
+
+// page writes whole packets as one page
+func (w *oggWriter) page(packets [][]byte, granule int64, headerType byte) {
+	var lacing, body []byte
+	for _, packet := range packets {
+		if len(lacing)+lacingSize(packet) > maxSegments {
+			packet = packet[:(maxSegments-len(lacing)-1)*255]
+		}
+		for n := len(packet); ; n -= 255 {
+			if n < 255 {
+				lacing = append(lacing, byte(n))
+				break
+			}
+			lacing = append(lacing, 255)
+		}
+		body = append(body, packet...)
+	}
+
+	header := make([]byte, 27, 27+len(lacing))
+	copy(header, "OggS")
+	header[5] = headerType
+	binary.LittleEndian.PutUint64(header[6:], uint64(granule))
+	binary.LittleEndian.PutUint32(header[14:], oggSerial)
+	binary.LittleEndian.PutUint32(header[18:], w.sequence)
+	header[26] = byte(len(lacing))
+	header = append(header, lacing...)
+	w.sequence++
+
+	var crc uint32
+	for _, part := range [][]byte{header, body} {
+		for _, b := range part {
+			crc = crc<<8 ^ oggCRC[byte(crc>>24)^b]
+		}
+	}
+	binary.LittleEndian.PutUint32(header[22:], crc)
+	w.out.Write(header)
+	w.out.Write(body)
+}
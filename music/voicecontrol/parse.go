@@ -0,0 +1,110 @@
+package voicecontrol
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultWakeWord starts spoken commands in guilds that didn't choose their own, a word speech
+// recognition rarely gets wrong and people rarely say in passing
+const DefaultWakeWord = "computer"
+
+// Kind is what a spoken command does to the music player
+type Kind int
+
+// Spoken commands, each mapped to the player action of the slash command with the same name
+const (
+	Skip Kind = iota + 1
+	Pause
+	Resume
+	Stop
+	Shuffle
+	Play
+	Volume
+	Louder
+	Quieter
+)
+
+// Action is a spoken command
+type Action struct {
+	Kind   Kind
+	Query  string // Song to play, for Play
+	Volume int    // Volume in percent, for Volume
+}
+
+// phrases maps what members say after the wake word to actions, longest phrases are matched first
+var phrases = []struct {
+	words string
+	kind  Kind
+}{
+	{"skip this song", Skip},
+	{"next song", Skip},
+	{"volume up", Louder},
+	{"volume down", Quieter},
+	{"turn it up", Louder},
+	{"turn it down", Quieter},
+	{"skip", Skip},
+	{"next", Skip},
+	{"pause", Pause},
+	{"resume", Resume},
+	{"unpause", Resume},
+	{"continue", Resume},
+	{"stop", Stop},
+	{"shuffle", Shuffle},
+	{"louder", Louder},
+	{"quieter", Quieter},
+}
+
+// Parse finds a command in transcribed speech: the words after the wake word, such as "computer, skip" or
+// "computer play bohemian rhapsody". Speech without the wake word or a known command is ignored.
+func Parse(text, wakeWord string) (Action, bool) {
+	words := normalize(text)
+	wake := normalize(wakeWord)
+	if len(wake) == 0 {
+		wake = normalize(DefaultWakeWord)
+	}
+
+	start := -1
+	for n := 0; n+len(wake) <= len(words); n++ {
+		if slices.Equal(words[n:n+len(wake)], wake) {
+			start = n + len(wake)
+			break
+		}
+	}
+	if start < 0 || start == len(words) {
+		return Action{}, false
+	}
+	command := words[start:]
+
+	switch command[0] {
+	case "play":
+		if len(command) == 1 {
+			return Action{Kind: Resume}, true
+		}
+		return Action{Kind: Play, Query: strings.Join(command[1:], " ")}, true
+	case "volume", "set":
+		// "volume 50", "volume to 50 percent", "set volume to 50"
+		for _, word := range command[1:] {
+			if volume, err := strconv.Atoi(word); err == nil {
+				return Action{Kind: Volume, Volume: volume}, true
+			}
+		}
+	}
+	for _, phrase := range phrases {
+		words := strings.Fields(phrase.words)
+		if len(command) >= len(words) && slices.Equal(command[:len(words)], words) {
+			return Action{Kind: phrase.kind}, true
+		}
+	}
+	return Action{}, false
+}
+
+// normalize splits speech into lowercase words without punctuation, "Computer, skip!" reads as
+// "computer skip" and "50%" as "50"
+func normalize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
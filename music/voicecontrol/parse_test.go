@@ -0,0 +1,36 @@
+package voicecontrol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text     string
+		wakeWord string
+		action   Action
+		ok       bool
+	}{
+		{"Computer, skip!", "", Action{Kind: Skip}, true},
+		{"okay computer next song please", "computer", Action{Kind: Skip}, true},
+		{"Computer pause.", "computer", Action{Kind: Pause}, true},
+		{"computer play", "computer", Action{Kind: Resume}, true},
+		{"Computer, play Bohemian Rhapsody by Queen.", "computer", Action{Kind: Play, Query: "bohemian rhapsody by queen"}, true},
+		{"computer play don't stop me now", "computer", Action{Kind: Play, Query: "don't stop me now"}, true},
+		{"Computer, volume 50%.", "computer", Action{Kind: Volume, Volume: 50}, true},
+		{"computer set the volume to 80", "computer", Action{Kind: Volume, Volume: 80}, true},
+		{"computer turn it up", "computer", Action{Kind: Louder}, true},
+		{"Hey DJ, shuffle", "hey dj", Action{Kind: Shuffle}, true},
+		{"let's skip this one", "computer", Action{}, false},
+		{"computer", "computer", Action{}, false},
+		{"computer, what's the weather", "computer", Action{}, false},
+		{"computer volume", "computer", Action{}, false},
+	}
+	for _, test := range tests {
+		action, ok := Parse(test.text, test.wakeWord)
+		assert.Equal(t, test.ok, ok, test.text)
+		assert.Equal(t, test.action, action, test.text)
+	}
+}
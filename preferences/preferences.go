@@ -0,0 +1,99 @@
+package preferences
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultPath is where preferences are saved when PREFERENCES_FILE is not set
+const DefaultPath = "data/preferences.json"
+
+// Preferences are how a member wants the bot to respond to them
+type Preferences struct {
+	PlainText bool `json:"plain_text"` // Plain text instead of embeds, without emoji, for screen readers
+}
+
+// Store keeps the preferences of each member in memory and writes them to a JSON file on every change
+type Store struct {
+	path string
+
+	mu      sync.RWMutex
+	members map[string]Preferences
+}
+
+// New creates a store that only lives in memory
+func New() *Store {
+	return &Store{members: make(map[string]Preferences)}
+}
+
+// Load opens the preferences file at path; a missing file starts with everyone on the defaults
+func Load(path string) (*Store, error) {
+	store := New()
+	store.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preferences: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.members); err != nil {
+		return nil, fmt.Errorf("failed to parse preferences %s: %w", path, err)
+	}
+	if store.members == nil {
+		store.members = make(map[string]Preferences)
+	}
+	return store, nil
+}
+
+// Get returns a member's preferences, the defaults when they never set any
+func (s *Store) Get(userID string) Preferences {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.members[userID]
+}
+
+// PlainText reports whether a member wants plain text responses
+func (s *Store) PlainText(userID string) bool {
+	return s.Get(userID).PlainText
+}
+
+// SetPlainText turns plain text responses on or off for a member and saves the change. Members back on
+// every default are forgotten.
+func (s *Store) SetPlainText(userID string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefs := s.members[userID]
+	prefs.PlainText = enabled
+	if prefs == (Preferences{}) {
+		delete(s.members, userID)
+	} else {
+		s.members[userID] = prefs
+	}
+	return s.save()
+}
+
+// save writes the members' preferences to the file, if the store has one (caller holds the lock)
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.members, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return nil
+}
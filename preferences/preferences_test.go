@@ -0,0 +1,36 @@
+package preferences
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSavesPlainTextPreference(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "preferences.json")
+	store, err := Load(path)
+	require.NoError(t, err)
+	assert.False(t, store.PlainText("user_1"))
+
+	require.NoError(t, store.SetPlainText("user_1", true))
+	assert.True(t, store.PlainText("user_1"))
+	assert.False(t, store.PlainText("user_2"))
+
+	reloaded, err := Load(path)
+	require.NoError(t, err)
+	assert.True(t, reloaded.PlainText("user_1"))
+
+	require.NoError(t, reloaded.SetPlainText("user_1", false))
+	assert.Empty(t, reloaded.members, "members back on the defaults are forgotten")
+}
+
+func TestLoadRejectsCorruptPreferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	_, err := Load(path)
+	assert.Error(t, err)
+}
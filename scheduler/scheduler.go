@@ -0,0 +1,366 @@
+// Package scheduler runs recurring jobs on cron schedules and one-shot jobs at a set time. Jobs are
+// saved to a JSON file so they survive restarts, runs can be jittered so jobs on the same schedule don't
+// fire together, and runs missed while the bot was down can be caught up once on start.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultPath is where jobs are saved when SCHEDULER_FILE is not set
+const DefaultPath = "data/scheduler.json"
+
+// maxWait caps how long the scheduler sleeps, so wall clock changes are noticed
+const maxWait = time.Minute
+
+// retryDelay is how long a due job waits when the worker pool has no room for it
+const retryDelay = time.Minute
+
+// errJobPanicked is recorded as the last error of a run that panicked
+var errJobPanicked = errors.New("job panicked")
+
+// Handler runs a job. The context ends when the scheduler stops.
+type Handler func(ctx context.Context, job Job) error
+
+// Job is a scheduled task. Recurring jobs have a Spec; one-shot jobs run once At a time and are then removed.
+type Job struct {
+	ID      string          `json:"id"`
+	Kind    string          `json:"kind"`               // Handler that runs the job
+	Spec    string          `json:"spec,omitempty"`     // Cron expression, descriptor or @every interval
+	At      time.Time       `json:"at,omitzero"`        // When a one-shot job runs
+	Data    json.RawMessage `json:"data,omitempty"`     // Payload for the handler, such as what to remind about
+	Jitter  time.Duration   `json:"jitter,omitempty"`   // Up to this much random delay is added to each run
+	CatchUp bool            `json:"catch_up,omitempty"` // Run once on start when runs were missed while stopped
+
+	NextRun   time.Time `json:"next_run"`
+	LastRun   time.Time `json:"last_run,omitzero"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Recurring reports whether the job runs on a schedule rather than once
+func (j Job) Recurring() bool {
+	return j.Spec != ""
+}
+
+// Scheduler keeps jobs in memory, runs them on a worker pool when due and writes them to a JSON file on
+// every change
+type Scheduler struct {
+	path string
+	pool *utils.WorkerPool
+	now  func() time.Time
+
+	mu        sync.Mutex
+	jobs      map[string]*Job
+	schedules map[string]Schedule
+	handlers  map[string]Handler
+	running   map[string]bool
+
+	wake   chan struct{}
+	stop   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New creates a scheduler whose jobs only live in memory
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		pool:      utils.NewWorkerPool("scheduler", 4, 64),
+		now:       time.Now,
+		jobs:      make(map[string]*Job),
+		schedules: make(map[string]Schedule),
+		handlers:  make(map[string]Handler),
+		running:   make(map[string]bool),
+		wake:      make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Load opens the jobs file at path; a missing file starts with no jobs. Saved jobs whose schedule no
+// longer parses are dropped.
+func Load(path string) (*Scheduler, error) {
+	s := New()
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scheduled jobs: %w", err)
+	}
+
+	var jobs []Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse scheduled jobs %s: %w", path, err)
+	}
+	for _, job := range jobs {
+		if job.Recurring() {
+			schedule, err := ParseSchedule(job.Spec)
+			if err != nil {
+				utils.LogWarn("Dropping scheduled job %s: %v", job.ID, err)
+				continue
+			}
+			s.schedules[job.ID] = schedule
+		}
+		s.jobs[job.ID] = &job
+	}
+	return s, nil
+}
+
+// Handle sets the handler for jobs of a kind. Jobs without a handler wait until one is set.
+func (s *Scheduler) Handle(kind string, handler Handler) {
+	s.mu.Lock()
+	s.handlers[kind] = handler
+	s.mu.Unlock()
+	s.signal()
+}
+
+// Add schedules a job, replacing a job with the same ID. Re-adding a saved job with the same timing keeps
+// its next run, so jobs registered on every start resume where they left off.
+func (s *Scheduler) Add(job Job) error {
+	if job.ID == "" || job.Kind == "" {
+		return errors.New("scheduled jobs need an ID and a kind")
+	}
+	if job.Recurring() == !job.At.IsZero() {
+		return fmt.Errorf("job %s needs either a schedule or a time to run at", job.ID)
+	}
+
+	var schedule Schedule
+	if job.Recurring() {
+		var err error
+		if schedule, err = ParseSchedule(job.Spec); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.jobs[job.ID]
+	switch {
+	case exists && existing.Spec == job.Spec && existing.At.Equal(job.At) && existing.Jitter == job.Jitter:
+		job.NextRun, job.LastRun, job.LastError = existing.NextRun, existing.LastRun, existing.LastError
+	case job.Recurring():
+		job.NextRun = nextRun(schedule, s.now(), job.Jitter)
+	default:
+		job.NextRun = job.At
+	}
+
+	s.jobs[job.ID] = &job
+	delete(s.schedules, job.ID)
+	if schedule != nil {
+		s.schedules[job.ID] = schedule
+	}
+	s.signal()
+	return s.save()
+}
+
+// Remove cancels a job; a run already in progress finishes
+func (s *Scheduler) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[id]; !exists {
+		return nil
+	}
+	delete(s.jobs, id)
+	delete(s.schedules, id)
+	return s.save()
+}
+
+// Jobs returns a snapshot of the jobs, soonest first
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		if !jobs[a].NextRun.Equal(jobs[b].NextRun) {
+			return jobs[a].NextRun.Before(jobs[b].NextRun)
+		}
+		return jobs[a].ID < jobs[b].ID
+	})
+	return jobs
+}
+
+// Start skips runs that were missed while the scheduler was stopped, except for one-shot jobs and jobs
+// that catch up, which run once right away, then runs jobs as they come due until Stop
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	if s.stop != nil || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	stop := s.stop
+
+	now := s.now()
+	skipped := 0
+	for id, job := range s.jobs {
+		if job.Recurring() && !job.CatchUp && job.NextRun.Before(now) {
+			job.NextRun = nextRun(s.schedules[id], now, job.Jitter)
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		utils.LogInfo("Skipped missed runs of %d scheduled jobs", skipped)
+		if err := s.save(); err != nil {
+			utils.LogWarn("Failed to save scheduled jobs: %v", err)
+		}
+	}
+	s.mu.Unlock()
+
+	utils.SafeGoWithRestart("scheduler", utils.RestartPolicy{MaxRestarts: -1, Backoff: time.Minute}, func() {
+		s.run(stop)
+	})
+}
+
+// Stop ends the scheduler loop and cancels the context of running jobs. A stopped scheduler can't be
+// started again.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+	s.cancel()
+}
+
+// run starts due jobs and sleeps until the next one is due, a job changes or stop is closed
+func (s *Scheduler) run(stop <-chan struct{}) {
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+	for {
+		timer.Reset(s.runDue())
+		select {
+		case <-stop:
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// runDue submits every due job that has a handler and isn't running, and returns how long to sleep
+func (s *Scheduler) runDue() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	wait := maxWait
+	for id, job := range s.jobs {
+		handler, handled := s.handlers[job.Kind]
+		if !handled || s.running[id] || job.NextRun.IsZero() {
+			continue
+		}
+
+		if job.NextRun.After(now) {
+			wait = min(wait, job.NextRun.Sub(now))
+			continue
+		}
+
+		s.running[id] = true
+		snapshot := *job
+		if err := s.pool.Submit(func() { s.execute(snapshot, handler) }); err != nil {
+			utils.LogWarn("Delaying scheduled job %s: %v", id, err)
+			delete(s.running, id)
+			job.NextRun = now.Add(retryDelay)
+		}
+	}
+	return wait
+}
+
+// execute runs a job's handler and records the outcome, also when the handler panics
+func (s *Scheduler) execute(job Job, handler Handler) {
+	err := errJobPanicked
+	defer func() { s.finish(job.ID, err) }()
+	err = handler(s.ctx, job)
+}
+
+// finish records a run, removing one-shot jobs and scheduling the next run of recurring ones
+func (s *Scheduler) finish(id string, runErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.signal()
+
+	delete(s.running, id)
+	job, exists := s.jobs[id]
+	if !exists {
+		return
+	}
+
+	now := s.now()
+	job.LastRun = now
+	job.LastError = ""
+	if runErr != nil {
+		job.LastError = runErr.Error()
+		utils.LogWarn("Scheduled job %s failed: %v", id, runErr)
+	}
+
+	if job.Recurring() {
+		job.NextRun = nextRun(s.schedules[id], now, job.Jitter)
+	} else {
+		delete(s.jobs, id)
+	}
+	if err := s.save(); err != nil {
+		utils.LogWarn("Failed to save scheduled jobs: %v", err)
+	}
+}
+
+// signal wakes the loop to look at the jobs again
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// nextRun returns a schedule's next run after a time with up to jitter of random delay
+func nextRun(schedule Schedule, after time.Time, jitter time.Duration) time.Time {
+	next := schedule.Next(after)
+	if jitter > 0 && !next.IsZero() {
+		next = next.Add(rand.N(jitter))
+	}
+	return next
+}
+
+// save writes the jobs sorted by ID, so the file only changes where a job did (caller holds the lock)
+func (s *Scheduler) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].ID < jobs[b].ID })
+
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode scheduled jobs: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save scheduled jobs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForJob polls until a job matches, handlers finish on worker goroutines
+func waitForJob(t *testing.T, s *Scheduler, match func(jobs map[string]Job) bool) map[string]Job {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		jobs := make(map[string]Job)
+		for _, job := range s.Jobs() {
+			jobs[job.ID] = job
+		}
+		s.mu.Lock()
+		idle := len(s.running) == 0
+		s.mu.Unlock()
+		if idle && match(jobs) {
+			return jobs
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("jobs never matched, last %+v", jobs)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSchedulerRunsDueJobs(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	s := New()
+	s.now = func() time.Time { return now }
+
+	ran := make(chan string, 4)
+	s.Handle("remind", func(ctx context.Context, job Job) error {
+		ran <- job.ID + ":" + string(job.Data)
+		return nil
+	})
+	s.Handle("sweep", func(ctx context.Context, job Job) error {
+		ran <- job.ID
+		return errors.New("disk busy")
+	})
+
+	require.NoError(t, s.Add(Job{ID: "reminder", Kind: "remind", At: now.Add(-time.Second), Data: json.RawMessage(`"tea"`)}))
+	require.NoError(t, s.Add(Job{ID: "later", Kind: "remind", At: now.Add(time.Hour)}))
+	require.NoError(t, s.Add(Job{ID: "cleanup", Kind: "sweep", Spec: "@every 30m"}))
+	assert.Equal(t, now.Add(30*time.Minute), s.jobs["cleanup"].NextRun)
+
+	assert.Equal(t, maxWait, s.runDue(), "sleeps until the next due job, at most maxWait")
+	assert.Equal(t, "reminder:\"tea\"", <-ran)
+
+	jobs := waitForJob(t, s, func(jobs map[string]Job) bool { return len(jobs) == 2 })
+	assert.NotContains(t, jobs, "reminder", "one-shot jobs are removed after running")
+
+	now = now.Add(30 * time.Minute)
+	s.runDue()
+	assert.Equal(t, "cleanup", <-ran)
+	jobs = waitForJob(t, s, func(jobs map[string]Job) bool { return !jobs["cleanup"].LastRun.IsZero() })
+	assert.Equal(t, now.Add(30*time.Minute), jobs["cleanup"].NextRun)
+	assert.Equal(t, "disk busy", jobs["cleanup"].LastError, "failed runs still move on to the next run")
+}
+
+func TestSchedulerRecordsPanics(t *testing.T) {
+	s := New()
+	s.Handle("boom", func(ctx context.Context, job Job) error { panic("boom") })
+	require.NoError(t, s.Add(Job{ID: "boom", Kind: "boom", Spec: "@hourly"}))
+	s.jobs["boom"].NextRun = time.Now().Add(-time.Minute)
+
+	s.runDue()
+	jobs := waitForJob(t, s, func(jobs map[string]Job) bool { return jobs["boom"].LastError != "" })
+	assert.Equal(t, errJobPanicked.Error(), jobs["boom"].LastError)
+	assert.True(t, jobs["boom"].NextRun.After(time.Now()))
+}
+
+func TestSchedulerWaitsForHandlers(t *testing.T) {
+	s := New()
+	require.NoError(t, s.Add(Job{ID: "orphan", Kind: "unknown", At: time.Now().Add(-time.Minute)}))
+
+	s.runDue()
+	assert.Len(t, s.Jobs(), 1, "jobs without a handler stay until one is set")
+}
+
+func TestAddValidatesJobs(t *testing.T) {
+	s := New()
+	assert.Error(t, s.Add(Job{Kind: "remind", Spec: "@daily"}))
+	assert.Error(t, s.Add(Job{ID: "both", Kind: "remind", Spec: "@daily", At: time.Now()}))
+	assert.Error(t, s.Add(Job{ID: "neither", Kind: "remind"}))
+	assert.Error(t, s.Add(Job{ID: "invalid", Kind: "remind", Spec: "every day"}))
+}
+
+func TestLoadResumesSavedJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scheduler.json")
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	s.now = func() time.Time { return start }
+	require.NoError(t, s.Add(Job{ID: "briefing", Kind: "brief", Spec: "0 8 * * *", CatchUp: true}))
+	require.NoError(t, s.Add(Job{ID: "poll", Kind: "poll", Spec: "@every 10m"}))
+	require.NoError(t, s.Add(Job{ID: "reminder", Kind: "remind", At: start.Add(time.Hour)}))
+
+	// Two days later every saved run is in the past
+	restart := start.Add(48 * time.Hour)
+	s, err = Load(path)
+	require.NoError(t, err)
+	s.now = func() time.Time { return restart }
+	require.Len(t, s.Jobs(), 3)
+
+	require.NoError(t, s.Add(Job{ID: "poll", Kind: "poll", Spec: "@every 10m"}))
+	assert.Equal(t, start.Add(10*time.Minute), s.jobs["poll"].NextRun, "re-adding a job with the same schedule keeps its next run")
+
+	ran := make(chan string, 4)
+	for _, kind := range []string{"brief", "poll", "remind"} {
+		s.Handle(kind, func(ctx context.Context, job Job) error {
+			ran <- job.ID
+			return nil
+		})
+	}
+	s.Start()
+	defer s.Stop()
+
+	got := []string{<-ran, <-ran}
+	assert.ElementsMatch(t, []string{"briefing", "reminder"}, got, "catch-up jobs and missed one-shots run once on start")
+	jobs := waitForJob(t, s, func(jobs map[string]Job) bool { return len(jobs) == 2 && !jobs["briefing"].LastRun.IsZero() })
+	assert.Equal(t, restart.Add(10*time.Minute), jobs["poll"].NextRun, "missed runs are skipped without catch-up")
+	assert.Equal(t, time.Date(2025, 1, 18, 8, 0, 0, 0, time.UTC), jobs["briefing"].NextRun)
+	assert.Empty(t, ran)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "reminder")
+}
+
+func TestLoadDropsInvalidJobs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scheduler.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"id":"broken","kind":"poll","spec":"sometimes"}]`), 0o644))
+
+	s, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, s.Jobs())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0o644))
+	_, err = Load(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+// Package llm answers questions with a language model behind an OpenAI-compatible chat completions API,
+// such as OpenAI, OpenRouter, Groq or a local Ollama or llama.cpp server. Each guild can give the model a
+// persona of its own, which always stays within the Limits set for the whole bot.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+	"pxnx-discord-bot/tracing"
+)
+
+// DefaultModel is the model asked when AI_MODEL is not set
+const DefaultModel = "gpt-4o-mini"
+
+// requestTimeout bounds one answer; models can take a while on long ones
+const requestTimeout = 60 * time.Second
+
+// ErrNoAnswer is returned when the model responds without any text
+var ErrNoAnswer = errors.New("the model gave no answer")
+
+// Config is the API the bot asks
+type Config struct {
+	Endpoint string // Base URL of the API, such as https://api.openai.com/v1; AI chat is off when empty
+	APIKey   string // Sent as a bearer token, if set
+	Model    string
+}
+
+// ConfigFromEnv reads AI_ENDPOINT, AI_API_KEY and AI_MODEL
+func ConfigFromEnv() Config {
+	config := Config{
+		Endpoint: strings.TrimRight(strings.TrimSpace(os.Getenv("AI_ENDPOINT")), "/"),
+		APIKey:   strings.TrimSpace(os.Getenv("AI_API_KEY")),
+		Model:    strings.TrimSpace(os.Getenv("AI_MODEL")),
+	}
+	if config.Model == "" {
+		config.Model = DefaultModel
+	}
+	return config
+}
+
+// Role says who wrote a Message
+type Role string
+
+const (
+	RoleSystem    Role = "system"    // Instructions to the model
+	RoleUser      Role = "user"      // A member's question
+	RoleAssistant Role = "assistant" // The model's answer
+)
+
+// Message is one entry of a conversation with the model
+type Message struct {
+	Role    Role   `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request is a conversation to continue and how to answer it
+type Request struct {
+	Messages    []Message
+	Temperature float64
+	MaxTokens   int // 0 leaves the length of the answer to the API
+}
+
+// Client asks a chat completions API
+type Client struct {
+	config Config
+	client *http.Client
+}
+
+// NewClient creates a client for the API config names
+func NewClient(config Config) *Client {
+	return &Client{config: config, client: httpclient.New(requestTimeout)}
+}
+
+// Model returns the model the client asks
+func (c *Client) Model() string {
+	return c.config.Model
+}
+
+// completionRequest is the body of a chat completions request
+type completionRequest struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Temperature float64   `json:"temperature"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+}
+
+// completionResponse is the part of a chat completions response the bot reads, successful or not
+type completionResponse struct {
+	Choices []struct {
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Chat returns the model's answer to a conversation
+func (c *Client) Chat(ctx context.Context, request Request) (string, error) {
+	ctx, span := tracing.Start(ctx, "llm.chat", tracing.KindClient,
+		"llm.model", c.config.Model, "llm.messages", len(request.Messages))
+	defer span.End()
+
+	answer, err := c.chat(ctx, request)
+	span.RecordError(err)
+	return answer, err
+}
+
+// chat sends a chat completions request and reads the first choice
+func (c *Client) chat(ctx context.Context, request Request) (string, error) {
+	body, err := json.Marshal(completionRequest{
+		Model:       c.config.Model,
+		Messages:    request.Messages,
+		Temperature: request.Temperature,
+		MaxTokens:   request.MaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode chat request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.Endpoint+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build chat request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to ask the model: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var completion completionResponse
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&completion)
+	if resp.StatusCode != http.StatusOK {
+		if decodeErr == nil && completion.Error != nil && completion.Error.Message != "" {
+			return "", fmt.Errorf("model API responded %s: %s", resp.Status, completion.Error.Message)
+		}
+		return "", fmt.Errorf("model API responded %s", resp.Status)
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("failed to parse the model's answer: %w", decodeErr)
+	}
+	if len(completion.Choices) == 0 {
+		return "", ErrNoAnswer
+	}
+	answer := strings.TrimSpace(completion.Choices[0].Message.Content)
+	if answer == "" {
+		return "", ErrNoAnswer
+	}
+	return answer, nil
+}
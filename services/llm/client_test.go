@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var body completionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "test-model", body.Model)
+		assert.Equal(t, 0.5, body.Temperature)
+		assert.Equal(t, 100, body.MaxTokens)
+		assert.Equal(t, []Message{{Role: RoleUser, Content: "Hi?"}}, body.Messages)
+
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"  Hello!\n"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Endpoint: server.URL + "/v1", APIKey: "secret", Model: "test-model"})
+	answer, err := client.Chat(t.Context(), Request{
+		Messages:    []Message{{Role: RoleUser, Content: "Hi?"}},
+		Temperature: 0.5,
+		MaxTokens:   100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello!", answer)
+}
+
+func TestClientChatErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{"api error", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key"}}`, "model API responded 401 Unauthorized: Incorrect API key"},
+		{"no body", http.StatusBadGateway, ``, "model API responded 502 Bad Gateway"},
+		{"no choices", http.StatusOK, `{"choices":[]}`, ErrNoAnswer.Error()},
+		{"empty answer", http.StatusOK, `{"choices":[{"message":{"content":" "}}]}`, ErrNoAnswer.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := NewClient(Config{Endpoint: server.URL, Model: DefaultModel}).Chat(t.Context(), Request{})
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("AI_ENDPOINT", "http://localhost:11434/v1/")
+	t.Setenv("AI_API_KEY", "")
+	t.Setenv("AI_MODEL", "")
+
+	config := ConfigFromEnv()
+	assert.Equal(t, "http://localhost:11434/v1", config.Endpoint)
+	assert.Equal(t, DefaultModel, config.Model)
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"sync"
+	"time"
+)
+
+// exchange is a question and the model's answer to it
+type exchange struct {
+	Question string
+	Answer   string
+}
+
+// conversation is the recent exchanges of one channel
+type conversation struct {
+	exchanges []exchange
+	updated   time.Time // When the last exchange was remembered, the conversation expires MemoryTTL later
+}
+
+// Memory keeps the last exchanges of each channel's conversation for a while, so follow-up questions have
+// context. It lives in memory only: conversations are short-lived and start over when the bot restarts.
+type Memory struct {
+	mu       sync.Mutex
+	channels map[string]*conversation
+	now      func() time.Time
+}
+
+// NewMemory creates an empty conversation memory
+func NewMemory() *Memory {
+	return &Memory{channels: make(map[string]*conversation), now: time.Now}
+}
+
+// History returns a channel's conversation as messages, oldest first, at most the last exchanges limits allows
+// and none once it expired
+func (m *Memory) History(channelID string, limits Limits) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, exists := m.channels[channelID]
+	if !exists || limits.MemoryExchanges == 0 || m.expired(current, limits) {
+		return nil
+	}
+	exchanges := current.exchanges[max(0, len(current.exchanges)-limits.MemoryExchanges):]
+	messages := make([]Message, 0, 2*len(exchanges))
+	for _, e := range exchanges {
+		messages = append(messages,
+			Message{Role: RoleUser, Content: e.Question},
+			Message{Role: RoleAssistant, Content: e.Answer})
+	}
+	return messages
+}
+
+// Remember adds an exchange to a channel's conversation, dropping the oldest beyond the limit and any
+// conversation that expired
+func (m *Memory) Remember(channelID, question, answer string, limits Limits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, c := range m.channels {
+		if m.expired(c, limits) {
+			delete(m.channels, id)
+		}
+	}
+	if limits.MemoryExchanges == 0 {
+		return
+	}
+
+	current, exists := m.channels[channelID]
+	if !exists {
+		current = &conversation{}
+		m.channels[channelID] = current
+	}
+	current.exchanges = append(current.exchanges, exchange{Question: question, Answer: answer})
+	if excess := len(current.exchanges) - limits.MemoryExchanges; excess > 0 {
+		current.exchanges = append([]exchange(nil), current.exchanges[excess:]...)
+	}
+	current.updated = m.now()
+}
+
+// Reset forgets a channel's conversation, reporting whether there was one
+func (m *Memory) Reset(channelID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, exists := m.channels[channelID]
+	delete(m.channels, channelID)
+	return exists
+}
+
+// expired reports whether a conversation is older than MemoryTTL (caller holds the lock)
+func (m *Memory) expired(c *conversation, limits Limits) bool {
+	return m.now().Sub(c.updated) > limits.MemoryTTL
+}
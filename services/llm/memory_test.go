@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryKeepsRecentExchanges(t *testing.T) {
+	limits := Limits{MemoryExchanges: 2, MemoryTTL: time.Minute}
+	now := time.Now()
+	memory := NewMemory()
+	memory.now = func() time.Time { return now }
+
+	memory.Remember("channel1", "One?", "1", limits)
+	memory.Remember("channel1", "Two?", "2", limits)
+	memory.Remember("channel1", "Three?", "3", limits)
+	assert.Equal(t, []Message{
+		{Role: RoleUser, Content: "Two?"},
+		{Role: RoleAssistant, Content: "2"},
+		{Role: RoleUser, Content: "Three?"},
+		{Role: RoleAssistant, Content: "3"},
+	}, memory.History("channel1", limits), "only the last exchanges are kept")
+	assert.Empty(t, memory.History("channel2", limits), "channels have conversations of their own")
+
+	limits.MemoryExchanges = 1
+	assert.Len(t, memory.History("channel1", limits), 2, "a lowered limit applies to conversations already remembered")
+
+	now = now.Add(2 * time.Minute)
+	assert.Empty(t, memory.History("channel1", limits), "conversations expire after the TTL")
+	memory.Remember("channel2", "Hello?", "Hi", limits)
+	assert.NotContains(t, memory.channels, "channel1", "expired conversations are dropped")
+
+	assert.True(t, memory.Reset("channel2"))
+	assert.False(t, memory.Reset("channel2"))
+	assert.Empty(t, memory.History("channel2", limits))
+}
+
+func TestMemoryOff(t *testing.T) {
+	limits := Limits{MemoryExchanges: 0, MemoryTTL: time.Minute}
+	memory := NewMemory()
+	memory.Remember("channel1", "One?", "1", limits)
+	assert.Empty(t, memory.channels)
+}
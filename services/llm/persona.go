@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultPersonasPath is where guild personas are saved when AI_PERSONAS_FILE is not set
+const DefaultPersonasPath = "data/ai-personas.json"
+
+// Persona is how a guild wants the model to answer its members
+type Persona struct {
+	Prompt      string   `json:"prompt,omitempty"`      // Who the model is and how it talks, after the safety prompt
+	Temperature *float64 `json:"temperature,omitempty"` // Randomness of answers, nil for the default
+	Channels    []string `json:"channels,omitempty"`    // Channels the model answers in, every channel when empty
+}
+
+// IsZero reports whether a persona is all defaults
+func (p Persona) IsZero() bool {
+	return p.Prompt == "" && p.Temperature == nil && len(p.Channels) == 0
+}
+
+// Allows reports whether the model answers in a channel
+func (p Persona) Allows(channelID string) bool {
+	return len(p.Channels) == 0 || slices.Contains(p.Channels, channelID)
+}
+
+// PersonaStore keeps each guild's persona in memory and writes them to a JSON file on every change
+type PersonaStore struct {
+	path   string
+	mu     sync.RWMutex
+	guilds map[string]Persona
+}
+
+// NewPersonaStore creates a store that only lives in memory
+func NewPersonaStore() *PersonaStore {
+	return &PersonaStore{guilds: make(map[string]Persona)}
+}
+
+// LoadPersonas opens the personas file at path; a missing file starts with every guild on the defaults
+func LoadPersonas(path string) (*PersonaStore, error) {
+	store := NewPersonaStore()
+	store.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AI personas: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.guilds); err != nil {
+		return nil, fmt.Errorf("failed to parse AI personas %s: %w", path, err)
+	}
+	if store.guilds == nil {
+		store.guilds = make(map[string]Persona)
+	}
+	return store, nil
+}
+
+// Get returns a guild's persona, the zero value when it never set one
+func (s *PersonaStore) Get(guildID string) Persona {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	persona := s.guilds[guildID]
+	persona.Channels = slices.Clone(persona.Channels)
+	return persona
+}
+
+// Update changes a guild's persona and saves it. Guilds back on the defaults are forgotten.
+func (s *PersonaStore) Update(guildID string, change func(*Persona)) (Persona, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	persona := s.guilds[guildID]
+	persona.Channels = slices.Clone(persona.Channels)
+	change(&persona)
+	if persona.IsZero() {
+		delete(s.guilds, guildID)
+	} else {
+		s.guilds[guildID] = persona
+	}
+	return persona, s.save()
+}
+
+// save writes the guild personas to the file (caller holds the lock)
+func (s *PersonaStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.guilds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode AI personas: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save AI personas: %w", err)
+	}
+	return nil
+}
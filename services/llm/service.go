@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrChannelNotAllowed is returned for questions asked outside the channels a guild allows
+var ErrChannelNotAllowed = errors.New("AI chat is not allowed in this channel")
+
+// Service answers questions in the persona of the guild they are asked in, remembering each channel's
+// recent exchanges
+type Service struct {
+	client   *Client
+	personas *PersonaStore
+	memory   *Memory
+}
+
+// NewService creates a service asking client with the personas in store
+func NewService(client *Client, personas *PersonaStore) *Service {
+	return &Service{client: client, personas: personas, memory: NewMemory()}
+}
+
+// Personas returns the guilds' personas
+func (s *Service) Personas() *PersonaStore {
+	return s.personas
+}
+
+// Model returns the model answering questions
+func (s *Service) Model() string {
+	return s.client.Model()
+}
+
+// Ask answers a question asked in a guild's channel, following up on the channel's recent exchanges;
+// guildID is empty in direct messages, which get the default persona
+func (s *Service) Ask(ctx context.Context, guildID, channelID, question string) (string, error) {
+	persona := s.personas.Get(guildID)
+	if !persona.Allows(channelID) {
+		return "", ErrChannelNotAllowed
+	}
+	limits := CurrentLimits()
+	answer, err := s.client.Chat(ctx, buildRequest(limits, persona, s.memory.History(channelID, limits), question))
+	if err != nil {
+		return "", err
+	}
+	s.memory.Remember(channelID, question, answer, limits)
+	return answer, nil
+}
+
+// ResetConversation forgets a channel's recent exchanges, reporting whether there were any
+func (s *Service) ResetConversation(channelID string) bool {
+	return s.memory.Reset(channelID)
+}
+
+// buildRequest puts the safety prompt and the persona before the earlier exchanges and the question,
+// within the limits
+func buildRequest(limits Limits, persona Persona, history []Message, question string) Request {
+	system := limits.SafetyPrompt
+	if prompt := limits.PersonaPrompt(persona); prompt != "" {
+		system += "\n\nPersona:\n" + prompt
+	}
+	var messages []Message
+	if system != "" {
+		messages = append(messages, Message{Role: RoleSystem, Content: system})
+	}
+	messages = append(messages, history...)
+	return Request{
+		Messages:    append(messages, Message{Role: RoleUser, Content: question}),
+		Temperature: limits.Temperature(persona),
+		MaxTokens:   limits.MaxTokens,
+	}
+}
@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRequestStaysWithinLimits(t *testing.T) {
+	limits := Limits{SafetyPrompt: "Be safe.", DefaultTemperature: 0.7, MaxTemperature: 1, MaxTokens: 200, MaxPersonaLength: 10}
+
+	request := buildRequest(limits, Persona{}, nil, "Who are you?")
+	assert.Equal(t, []Message{
+		{Role: RoleSystem, Content: "Be safe."},
+		{Role: RoleUser, Content: "Who are you?"},
+	}, request.Messages)
+	assert.Equal(t, 0.7, request.Temperature)
+	assert.Equal(t, 200, request.MaxTokens)
+
+	hot := 1.8
+	request = buildRequest(limits, Persona{Prompt: "You are a pirate captain.", Temperature: &hot}, nil, "Who are you?")
+	assert.Equal(t, "Be safe.\n\nPersona:\nYou are a ", request.Messages[0].Content, "the persona is cut to MaxPersonaLength")
+	assert.Equal(t, 1.0, request.Temperature, "the temperature is capped at MaxTemperature")
+}
+
+func TestServiceAskInjectsPersona(t *testing.T) {
+	var system string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body completionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		system = body.Messages[0].Content
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"Arr!"}}]}`)
+	}))
+	defer server.Close()
+
+	personas := NewPersonaStore()
+	_, err := personas.Update("guild1", func(p *Persona) {
+		p.Prompt = "You are a pirate."
+		p.Channels = []string{"channel1"}
+	})
+	require.NoError(t, err)
+	service := NewService(NewClient(Config{Endpoint: server.URL}), personas)
+
+	answer, err := service.Ask(t.Context(), "guild1", "channel1", "Hello?")
+	require.NoError(t, err)
+	assert.Equal(t, "Arr!", answer)
+	assert.True(t, strings.HasPrefix(system, DefaultSafetyPrompt), "the safety prompt comes first")
+	assert.True(t, strings.HasSuffix(system, "You are a pirate."))
+
+	_, err = service.Ask(t.Context(), "guild1", "channel2", "Hello?")
+	assert.ErrorIs(t, err, ErrChannelNotAllowed)
+
+	_, err = service.Ask(t.Context(), "guild2", "channel2", "Hello?")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSafetyPrompt, system, "guilds without a persona get only the safety prompt")
+}
+
+func TestServiceAskFollowsUp(t *testing.T) {
+	var sent [][]Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body completionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		sent = append(sent, body.Messages)
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":"Answer %d"}}]}`, len(sent))
+	}))
+	defer server.Close()
+	service := NewService(NewClient(Config{Endpoint: server.URL}), NewPersonaStore())
+
+	_, err := service.Ask(t.Context(), "guild1", "channel1", "Who wrote Hamlet?")
+	require.NoError(t, err)
+	_, err = service.Ask(t.Context(), "guild1", "channel1", "When was he born?")
+	require.NoError(t, err)
+	assert.Equal(t, []Message{
+		{Role: RoleSystem, Content: DefaultSafetyPrompt},
+		{Role: RoleUser, Content: "Who wrote Hamlet?"},
+		{Role: RoleAssistant, Content: "Answer 1"},
+		{Role: RoleUser, Content: "When was he born?"},
+	}, sent[1], "follow-up questions come after the earlier exchange")
+
+	assert.True(t, service.ResetConversation("channel1"))
+	_, err = service.Ask(t.Context(), "guild1", "channel1", "When was he born?")
+	require.NoError(t, err)
+	assert.Len(t, sent[2], 2, "a reset conversation starts over")
+}
+
+func TestPersonaStoreSavesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ai-personas.json")
+	store, err := LoadPersonas(path)
+	require.NoError(t, err)
+
+	temperature := 0.2
+	_, err = store.Update("guild1", func(p *Persona) {
+		p.Prompt = "Be terse."
+		p.Temperature = &temperature
+	})
+	require.NoError(t, err)
+
+	loaded, err := LoadPersonas(path)
+	require.NoError(t, err)
+	persona := loaded.Get("guild1")
+	assert.Equal(t, "Be terse.", persona.Prompt)
+	require.NotNil(t, persona.Temperature)
+	assert.Equal(t, 0.2, *persona.Temperature)
+
+	_, err = loaded.Update("guild1", func(p *Persona) { *p = Persona{} })
+	require.NoError(t, err)
+	loaded, err = LoadPersonas(path)
+	require.NoError(t, err)
+	assert.True(t, loaded.Get("guild1").IsZero(), "a guild back on the defaults is forgotten")
+}
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkLines(t *testing.T) {
+	assert.Equal(t, []string{""}, chunkLines(nil, 10))
+	assert.Equal(t, []string{"abc\ndef"}, chunkLines([]string{"abc", "def"}, 7))
+	assert.Equal(t, []string{"abc", "def"}, chunkLines([]string{"abc", "def"}, 6))
+	assert.Equal(t, []string{"abcde", "fg"}, chunkLines([]string{"abcdefgh", "fg"}, 5), "lines longer than a chunk are cut")
+	assert.Equal(t, []string{"äöü", "ß"}, chunkLines([]string{"äöü", "ß"}, 3), "lengths are in characters")
+}
+
+func TestServiceSummarizeChunks(t *testing.T) {
+	var sent [][]Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body completionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		sent = append(sent, body.Messages)
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":"Summary %d"}}]}`, len(sent))
+	}))
+	defer server.Close()
+	service := NewService(NewClient(Config{Endpoint: server.URL}), NewPersonaStore())
+
+	summary, err := service.Summarize(t.Context(), "German", []string{"alice: hi", "bob: hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "Summary 1", summary)
+	require.Len(t, sent, 1)
+	assert.True(t, strings.HasPrefix(sent[0][0].Content, DefaultSafetyPrompt))
+	assert.True(t, strings.HasSuffix(sent[0][0].Content, "Answer in German."))
+	assert.Equal(t, "alice: hi\nbob: hello", sent[0][1].Content)
+
+	// Transcripts longer than a chunk are summarized in parts, then the parts combined
+	sent = nil
+	line := strings.Repeat("x", summaryChunkLength*2/3)
+	summary, err = service.Summarize(t.Context(), "English", []string{line, line})
+	require.NoError(t, err)
+	assert.Equal(t, "Summary 3", summary)
+	require.Len(t, sent, 3)
+	assert.Equal(t, "Part 1:\nSummary 1\n\nPart 2:\nSummary 2", sent[2][1].Content)
+}
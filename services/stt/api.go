@@ -0,0 +1,112 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+)
+
+// apiTimeout bounds one transcription, long voice notes take a while
+const apiTimeout = 2 * time.Minute
+
+// API transcribes with an OpenAI-compatible /audio/transcriptions endpoint
+type API struct {
+	endpoint string
+	apiKey   string
+	model    string
+	client   *http.Client
+}
+
+// NewAPI creates a backend asking the API at endpoint with model
+func NewAPI(endpoint, apiKey, model string) *API {
+	return &API{endpoint: endpoint, apiKey: apiKey, model: model, client: httpclient.New(apiTimeout)}
+}
+
+// Name describes the backend
+func (a *API) Name() string {
+	return a.model + " at " + a.endpoint
+}
+
+// transcriptionResponse is the part of a verbose_json transcription the bot reads, successful or not
+type transcriptionResponse struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+	Error    *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Transcribe uploads the audio at path and returns the API's transcript and detected language
+func (a *API) Transcribe(ctx context.Context, path string) (Transcript, error) {
+	body, contentType, err := transcriptionForm(path, a.model)
+	if err != nil {
+		return Transcript{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/audio/transcriptions", body)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to build transcription request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to ask the transcription API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var transcription transcriptionResponse
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&transcription)
+	if resp.StatusCode != http.StatusOK {
+		if decodeErr == nil && transcription.Error != nil && transcription.Error.Message != "" {
+			return Transcript{}, fmt.Errorf("transcription API responded %s: %s", resp.Status, transcription.Error.Message)
+		}
+		return Transcript{}, fmt.Errorf("transcription API responded %s", resp.Status)
+	}
+	if decodeErr != nil {
+		return Transcript{}, fmt.Errorf("failed to parse the transcription: %w", decodeErr)
+	}
+	return Transcript{Text: strings.TrimSpace(transcription.Text), Language: transcription.Language}, nil
+}
+
+// transcriptionForm builds the multipart form uploading the audio at path
+func transcriptionForm(path, model string) (io.Reader, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open audio: %w", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField("model", model)
+	_ = form.WriteField("response_format", "verbose_json")
+	// The API tells formats apart by the file name's extension, Discord voice messages are Ogg Opus
+	name := filepath.Base(path)
+	if filepath.Ext(name) == "" {
+		name += ".ogg"
+	}
+	part, err := form.CreateFormFile("file", name)
+	if err == nil {
+		_, err = io.Copy(part, file)
+	}
+	if err == nil {
+		err = form.Close()
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build transcription form: %w", err)
+	}
+	return &body, form.FormDataContentType(), nil
+}
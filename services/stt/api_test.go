@@ -0,0 +1,46 @@
+package stt
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITranscribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/audio/transcriptions", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "whisper-1", r.FormValue("model"))
+		assert.Equal(t, "verbose_json", r.FormValue("response_format"))
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		data, _ := io.ReadAll(file)
+		if string(data) == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"Invalid file format."}}`)
+			return
+		}
+		assert.Equal(t, ".ogg", filepath.Ext(header.Filename))
+		fmt.Fprint(w, `{"text":" Hello there. ","language":"english","duration":2.1}`)
+	}))
+	defer server.Close()
+	api := NewAPI(server.URL, "key", "whisper-1")
+
+	path := filepath.Join(t.TempDir(), "voice-message.ogg")
+	require.NoError(t, os.WriteFile(path, []byte("audio"), 0o644))
+	transcript, err := api.Transcribe(t.Context(), path)
+	require.NoError(t, err)
+	assert.Equal(t, Transcript{Text: "Hello there.", Language: "english"}, transcript)
+
+	require.NoError(t, os.WriteFile(path, []byte("fail"), 0o644))
+	_, err = api.Transcribe(t.Context(), path)
+	assert.ErrorContains(t, err, "Invalid file format.")
+}
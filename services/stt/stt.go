@@ -0,0 +1,226 @@
+// Package stt turns speech in audio files into text, with a local whisper.cpp binary or an
+// OpenAI-compatible transcriptions API such as OpenAI or Groq.
+package stt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+	"pxnx-discord-bot/tracing"
+)
+
+// Backends that can be chosen with STT_BACKEND
+const (
+	BackendWhisper = "whisper" // A local whisper.cpp binary
+	BackendAPI     = "api"     // An OpenAI-compatible /audio/transcriptions endpoint
+)
+
+// Defaults when the environment doesn't name them
+const (
+	DefaultWhisperBinary = "whisper-cli"
+	DefaultModel         = "whisper-1"
+)
+
+// MaxFileSize is the largest audio file transcribed, the upload limit of the common transcription APIs
+const MaxFileSize = 25 << 20
+
+// downloadTimeout bounds downloading an audio file before transcribing it
+const downloadTimeout = 30 * time.Second
+
+// ErrNoSpeech is returned for audio without any recognized speech
+var ErrNoSpeech = errors.New("no speech was recognized")
+
+// TooLargeError is returned for audio files larger than MaxFileSize
+type TooLargeError struct {
+	Size int64
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("the audio file is %d MB, at most %d MB can be transcribed", e.Size>>20, MaxFileSize>>20)
+}
+
+// Transcript is the text spoken in an audio file
+type Transcript struct {
+	Text     string
+	Language string // Detected language as the backend names it, a code such as "en" or a name such as "english"
+}
+
+// Backend transcribes audio files
+type Backend interface {
+	// Transcribe returns the speech in the audio file at path, detecting its language
+	Transcribe(ctx context.Context, path string) (Transcript, error)
+	// Name describes the backend for logs and diagnostics
+	Name() string
+}
+
+// Config chooses the backend transcribing audio
+type Config struct {
+	Backend       string // BackendWhisper or BackendAPI, transcription is off when empty
+	WhisperBinary string // whisper.cpp command line program
+	WhisperModel  string // Path of the ggml model whisper.cpp loads
+	Endpoint      string // Base URL of the API, such as https://api.openai.com/v1
+	APIKey        string // Sent as a bearer token, if set
+	Model         string // Model the API transcribes with
+}
+
+// ConfigFromEnv reads STT_BACKEND, STT_WHISPER_BINARY, STT_WHISPER_MODEL, STT_ENDPOINT, STT_API_KEY and STT_MODEL
+func ConfigFromEnv() Config {
+	config := Config{
+		Backend:       strings.ToLower(strings.TrimSpace(os.Getenv("STT_BACKEND"))),
+		WhisperBinary: strings.TrimSpace(os.Getenv("STT_WHISPER_BINARY")),
+		WhisperModel:  strings.TrimSpace(os.Getenv("STT_WHISPER_MODEL")),
+		Endpoint:      strings.TrimRight(strings.TrimSpace(os.Getenv("STT_ENDPOINT")), "/"),
+		APIKey:        strings.TrimSpace(os.Getenv("STT_API_KEY")),
+		Model:         strings.TrimSpace(os.Getenv("STT_MODEL")),
+	}
+	if config.WhisperBinary == "" {
+		config.WhisperBinary = DefaultWhisperBinary
+	}
+	if config.Model == "" {
+		config.Model = DefaultModel
+	}
+	return config
+}
+
+// New creates the backend config chooses, nil when transcription is off
+func New(config Config) (Backend, error) {
+	switch config.Backend {
+	case "":
+		return nil, nil
+	case BackendWhisper:
+		if config.WhisperModel == "" {
+			return nil, errors.New("STT_WHISPER_MODEL must name a whisper.cpp model file")
+		}
+		return NewWhisper(config.WhisperBinary, config.WhisperModel), nil
+	case BackendAPI:
+		if config.Endpoint == "" {
+			return nil, errors.New("STT_ENDPOINT must be set for the api backend")
+		}
+		return NewAPI(config.Endpoint, config.APIKey, config.Model), nil
+	default:
+		return nil, fmt.Errorf("unknown STT_BACKEND %q, expected %s or %s", config.Backend, BackendWhisper, BackendAPI)
+	}
+}
+
+// Transcriber downloads audio files and transcribes them with a backend
+type Transcriber struct {
+	backend Backend
+	client  *http.Client
+}
+
+// NewTranscriber creates a transcriber using backend
+func NewTranscriber(backend Backend) *Transcriber {
+	return &Transcriber{backend: backend, client: httpclient.New(downloadTimeout)}
+}
+
+// Backend returns the backend transcribing audio
+func (t *Transcriber) Backend() Backend {
+	return t.backend
+}
+
+// TranscribeURL downloads the audio file at link, such as a Discord attachment, and transcribes it. The
+// file name's extension tells backends the audio format.
+func (t *Transcriber) TranscribeURL(ctx context.Context, link, filename string) (Transcript, error) {
+	ctx, span := tracing.Start(ctx, "stt.transcribe", tracing.KindClient, "stt.backend", t.backend.Name())
+	defer span.End()
+
+	path, err := t.download(ctx, link, filepath.Ext(filename))
+	if err != nil {
+		span.RecordError(err)
+		return Transcript{}, err
+	}
+	defer os.Remove(path)
+
+	transcript, err := t.transcribe(ctx, path)
+	span.RecordError(err)
+	return transcript, err
+}
+
+// TranscribeAudio transcribes audio the bot already has, such as what a member said in a voice channel.
+// The extension, such as ".ogg", tells backends the audio format.
+func (t *Transcriber) TranscribeAudio(ctx context.Context, audio []byte, extension string) (Transcript, error) {
+	ctx, span := tracing.Start(ctx, "stt.transcribe", tracing.KindClient, "stt.backend", t.backend.Name())
+	defer span.End()
+
+	if len(audio) > MaxFileSize {
+		err := &TooLargeError{Size: int64(len(audio))}
+		span.RecordError(err)
+		return Transcript{}, err
+	}
+	file, err := os.CreateTemp("", "stt-*"+extension)
+	if err != nil {
+		err = fmt.Errorf("failed to create temporary file: %w", err)
+		span.RecordError(err)
+		return Transcript{}, err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(audio)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to write audio: %w", err)
+		span.RecordError(err)
+		return Transcript{}, err
+	}
+
+	transcript, err := t.transcribe(ctx, file.Name())
+	span.RecordError(err)
+	return transcript, err
+}
+
+// transcribe runs the backend on the audio file at path, audio without speech is ErrNoSpeech
+func (t *Transcriber) transcribe(ctx context.Context, path string) (Transcript, error) {
+	transcript, err := t.backend.Transcribe(ctx, path)
+	if err == nil && strings.TrimSpace(transcript.Text) == "" {
+		err = ErrNoSpeech
+	}
+	return transcript, err
+}
+
+// download saves the file at link to a temporary file with an extension, refusing files larger than MaxFileSize
+func (t *Transcriber) download(ctx context.Context, link, extension string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build download request: %w", err)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download audio: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("audio download responded %s", resp.Status)
+	}
+	if resp.ContentLength > MaxFileSize {
+		return "", &TooLargeError{Size: resp.ContentLength}
+	}
+
+	file, err := os.CreateTemp("", "stt-*"+extension)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	written, err := io.Copy(file, io.LimitReader(resp.Body, MaxFileSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	switch {
+	case err != nil:
+		err = fmt.Errorf("failed to download audio: %w", err)
+	case written > MaxFileSize:
+		err = &TooLargeError{Size: written}
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
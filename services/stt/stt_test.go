@@ -0,0 +1,100 @@
+package stt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend returns a transcript and records the file it was given
+type fakeBackend struct {
+	transcript Transcript
+	path       string
+	content    string
+}
+
+func (f *fakeBackend) Transcribe(ctx context.Context, path string) (Transcript, error) {
+	f.path = path
+	data, err := os.ReadFile(path)
+	f.content = string(data)
+	return f.transcript, err
+}
+
+func (f *fakeBackend) Name() string { return "fake" }
+
+func TestNew(t *testing.T) {
+	backend, err := New(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, backend, "transcription is off without a backend")
+
+	_, err = New(Config{Backend: BackendWhisper})
+	assert.Error(t, err, "whisper.cpp needs a model")
+	backend, err = New(Config{Backend: BackendWhisper, WhisperBinary: "whisper-cli", WhisperModel: "/models/ggml-base.bin"})
+	require.NoError(t, err)
+	assert.Equal(t, "whisper.cpp (ggml-base.bin)", backend.Name())
+
+	_, err = New(Config{Backend: BackendAPI})
+	assert.Error(t, err, "the API needs an endpoint")
+	_, err = New(Config{Backend: "vosk"})
+	assert.Error(t, err)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("STT_BACKEND", " API ")
+	t.Setenv("STT_ENDPOINT", "https://api.example.com/v1/")
+	t.Setenv("STT_MODEL", "")
+	config := ConfigFromEnv()
+	assert.Equal(t, BackendAPI, config.Backend)
+	assert.Equal(t, "https://api.example.com/v1", config.Endpoint)
+	assert.Equal(t, DefaultModel, config.Model)
+	assert.Equal(t, DefaultWhisperBinary, config.WhisperBinary)
+}
+
+func TestTranscribeURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/large.ogg" {
+			w.Write([]byte(strings.Repeat("x", MaxFileSize+1)))
+			return
+		}
+		w.Write([]byte("voice message"))
+	}))
+	defer server.Close()
+
+	backend := &fakeBackend{transcript: Transcript{Text: "Hello there", Language: "en"}}
+	transcriber := NewTranscriber(backend)
+	transcript, err := transcriber.TranscribeURL(t.Context(), server.URL+"/voice-message.ogg", "voice-message.ogg")
+	require.NoError(t, err)
+	assert.Equal(t, Transcript{Text: "Hello there", Language: "en"}, transcript)
+	assert.Equal(t, "voice message", backend.content)
+	assert.True(t, strings.HasSuffix(backend.path, ".ogg"), "the file keeps its extension")
+	assert.NoFileExists(t, backend.path, "the download is removed afterwards")
+
+	_, err = transcriber.TranscribeURL(t.Context(), server.URL+"/large.ogg", "large.ogg")
+	var tooLarge *TooLargeError
+	assert.ErrorAs(t, err, &tooLarge)
+
+	backend.transcript = Transcript{Text: "  "}
+	_, err = transcriber.TranscribeURL(t.Context(), server.URL+"/silence.ogg", "silence.ogg")
+	assert.ErrorIs(t, err, ErrNoSpeech)
+}
+
+func TestTranscribeAudio(t *testing.T) {
+	backend := &fakeBackend{transcript: Transcript{Text: "computer skip"}}
+	transcriber := NewTranscriber(backend)
+	transcript, err := transcriber.TranscribeAudio(t.Context(), []byte("utterance"), ".ogg")
+	require.NoError(t, err)
+	assert.Equal(t, "computer skip", transcript.Text)
+	assert.Equal(t, "utterance", backend.content)
+	assert.True(t, strings.HasSuffix(backend.path, ".ogg"))
+	assert.NoFileExists(t, backend.path, "the audio file is removed afterwards")
+
+	backend.transcript = Transcript{}
+	_, err = transcriber.TranscribeAudio(t.Context(), []byte("cough"), ".ogg")
+	assert.ErrorIs(t, err, ErrNoSpeech)
+}
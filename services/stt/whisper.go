@@ -0,0 +1,98 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Whisper transcribes with a local whisper.cpp binary. whisper.cpp reads 16 kHz WAV only, so ffmpeg converts
+// the audio first.
+type Whisper struct {
+	binary string
+	model  string
+	run    func(ctx context.Context, name string, args ...string) error // Runs a program, replaced in tests
+}
+
+// NewWhisper creates a backend running binary with the ggml model at model
+func NewWhisper(binary, model string) *Whisper {
+	return &Whisper{binary: binary, model: model, run: runProgram}
+}
+
+// Name describes the backend
+func (w *Whisper) Name() string {
+	return "whisper.cpp (" + filepath.Base(w.model) + ")"
+}
+
+// Transcribe converts the audio at path to WAV and runs whisper.cpp on it, detecting the language
+func (w *Whisper) Transcribe(ctx context.Context, path string) (Transcript, error) {
+	dir, err := os.MkdirTemp("", "whisper-*")
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	wav := filepath.Join(dir, "audio.wav")
+	if err := w.run(ctx, "ffmpeg", "-nostdin", "-loglevel", "error", "-i", path, "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", wav); err != nil {
+		return Transcript{}, fmt.Errorf("failed to convert audio: %w", err)
+	}
+	output := filepath.Join(dir, "transcript")
+	if err := w.run(ctx, w.binary, "-m", w.model, "-f", wav, "-l", "auto", "-np", "-nt", "-oj", "-of", output); err != nil {
+		return Transcript{}, fmt.Errorf("whisper.cpp failed: %w", err)
+	}
+
+	data, err := os.ReadFile(output + ".json")
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to read whisper.cpp output: %w", err)
+	}
+	return parseWhisperJSON(data)
+}
+
+// whisperOutput is the part of whisper.cpp's JSON output (-oj) the bot reads
+type whisperOutput struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []struct {
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+// parseWhisperJSON reads the transcript from whisper.cpp's JSON output, joining its segments
+func parseWhisperJSON(data []byte) (Transcript, error) {
+	var output whisperOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return Transcript{}, fmt.Errorf("failed to parse whisper.cpp output: %w", err)
+	}
+	segments := make([]string, 0, len(output.Transcription))
+	for _, segment := range output.Transcription {
+		if text := strings.TrimSpace(segment.Text); text != "" {
+			segments = append(segments, text)
+		}
+	}
+	return Transcript{Text: strings.Join(segments, " "), Language: output.Result.Language}, nil
+}
+
+// runProgram runs a program, returning its error output along with a failure
+func runProgram(ctx context.Context, name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, lastLine(message))
+		}
+		return err
+	}
+	return nil
+}
+
+// lastLine returns the last line of a program's output, where the error usually is
+func lastLine(text string) string {
+	return text[strings.LastIndex(text, "\n")+1:]
+}
@@ -0,0 +1,51 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// whisperJSON is whisper.cpp's JSON output for a short recording
+const whisperJSON = `{
+	"result": {"language": "de"},
+	"transcription": [
+		{"timestamps": {"from": "00:00:00,000", "to": "00:00:02,000"}, "text": " Hallo zusammen,"},
+		{"timestamps": {"from": "00:00:02,000", "to": "00:00:04,000"}, "text": " wie geht's?"}
+	]
+}`
+
+func TestParseWhisperJSON(t *testing.T) {
+	transcript, err := parseWhisperJSON([]byte(whisperJSON))
+	require.NoError(t, err)
+	assert.Equal(t, Transcript{Text: "Hallo zusammen, wie geht's?", Language: "de"}, transcript)
+
+	_, err = parseWhisperJSON([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestWhisperTranscribe(t *testing.T) {
+	var programs []string
+	whisper := NewWhisper("whisper-cli", "ggml-base.bin")
+	whisper.run = func(ctx context.Context, name string, args ...string) error {
+		programs = append(programs, name)
+		if name == "whisper-cli" {
+			// whisper.cpp writes its output next to the -of prefix
+			return os.WriteFile(args[len(args)-1]+".json", []byte(whisperJSON), 0o644)
+		}
+		return nil
+	}
+
+	transcript, err := whisper.Transcribe(t.Context(), "voice-message.ogg")
+	require.NoError(t, err)
+	assert.Equal(t, "de", transcript.Language)
+	assert.Equal(t, []string{"ffmpeg", "whisper-cli"}, programs, "the audio is converted to WAV first")
+
+	whisper.run = func(ctx context.Context, name string, args ...string) error { return errors.New("exit status 1") }
+	_, err = whisper.Transcribe(t.Context(), "voice-message.ogg")
+	assert.ErrorContains(t, err, "failed to convert audio")
+}
@@ -0,0 +1,106 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+)
+
+// apiTimeout bounds speaking one announcement
+const apiTimeout = 30 * time.Second
+
+// maxAudioSize is the largest audio file accepted from the API, a few sentences are far smaller
+const maxAudioSize = 10 << 20
+
+// API speaks with an OpenAI-compatible /audio/speech endpoint
+type API struct {
+	endpoint string
+	apiKey   string
+	model    string
+	voice    string
+	client   *http.Client
+}
+
+// NewAPI creates a backend asking the API at endpoint with model and voice
+func NewAPI(endpoint, apiKey, model, voice string) *API {
+	return &API{endpoint: endpoint, apiKey: apiKey, model: model, voice: voice, client: httpclient.New(apiTimeout)}
+}
+
+// Name describes the backend
+func (a *API) Name() string {
+	return a.model + " (" + a.voice + ") at " + a.endpoint
+}
+
+// speechRequest asks the API to speak text
+type speechRequest struct {
+	Model          string `json:"model"`
+	Input          string `json:"input"`
+	Voice          string `json:"voice"`
+	ResponseFormat string `json:"response_format"`
+}
+
+// errorResponse is how the API explains a failure
+type errorResponse struct {
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Synthesize asks the API to speak text, writing an MP3 file
+func (a *API) Synthesize(ctx context.Context, text string) (string, error) {
+	body, err := json.Marshal(speechRequest{Model: a.model, Input: text, Voice: a.voice, ResponseFormat: "mp3"})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode speech request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/audio/speech", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build speech request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to ask the speech API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure errorResponse
+		if json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&failure) == nil && failure.Error != nil && failure.Error.Message != "" {
+			return "", fmt.Errorf("speech API responded %s: %s", resp.Status, failure.Error.Message)
+		}
+		return "", fmt.Errorf("speech API responded %s", resp.Status)
+	}
+
+	file, err := os.CreateTemp("", "tts-*.mp3")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	written, err := io.Copy(file, io.LimitReader(resp.Body, maxAudioSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	switch {
+	case err != nil:
+		err = fmt.Errorf("failed to download speech: %w", err)
+	case written == 0:
+		err = errors.New("speech API returned no audio")
+	case written > maxAudioSize:
+		err = fmt.Errorf("speech API returned more than %d MB of audio", maxAudioSize>>20)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
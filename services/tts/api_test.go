@@ -0,0 +1,43 @@
+package tts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPISynthesize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/audio/speech", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		var request speechRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, speechRequest{Model: "tts-1", Voice: "alloy", ResponseFormat: "mp3", Input: request.Input}, request)
+		if request.Input == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"Invalid voice."}}`)
+			return
+		}
+		w.Write([]byte("mp3 audio"))
+	}))
+	defer server.Close()
+	api := NewAPI(server.URL, "key", "tts-1", "alloy")
+
+	path, err := api.Synthesize(t.Context(), "Up next")
+	require.NoError(t, err)
+	defer os.Remove(path)
+	assert.Equal(t, ".mp3", filepath.Ext(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "mp3 audio", string(data))
+
+	_, err = api.Synthesize(t.Context(), "fail")
+	assert.ErrorContains(t, err, "Invalid voice.")
+}
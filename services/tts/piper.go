@@ -0,0 +1,64 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Piper speaks with a local piper binary, which reads text on stdin and writes WAV
+type Piper struct {
+	binary string
+	model  string
+	run    func(ctx context.Context, stdin io.Reader, name string, args ...string) error // Runs a program, replaced in tests
+}
+
+// NewPiper creates a backend running binary with the voice at model
+func NewPiper(binary, model string) *Piper {
+	return &Piper{binary: binary, model: model, run: runProgram}
+}
+
+// Name describes the backend
+func (p *Piper) Name() string {
+	return "piper (" + strings.TrimSuffix(filepath.Base(p.model), ".onnx") + ")"
+}
+
+// Synthesize runs piper on text, writing a WAV file
+func (p *Piper) Synthesize(ctx context.Context, text string) (string, error) {
+	file, err := os.CreateTemp("", "tts-*.wav")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	file.Close()
+
+	if err := p.run(ctx, strings.NewReader(text), p.binary, "--model", p.model, "--output_file", file.Name()); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("piper failed: %w", err)
+	}
+	if info, err := os.Stat(file.Name()); err != nil || info.Size() == 0 {
+		os.Remove(file.Name())
+		return "", errors.New("piper wrote no audio")
+	}
+	return file.Name(), nil
+}
+
+// runProgram runs a program with input on stdin, returning its error output along with a failure
+func runProgram(ctx context.Context, stdin io.Reader, name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = stdin
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, message[strings.LastIndex(message, "\n")+1:])
+		}
+		return err
+	}
+	return nil
+}
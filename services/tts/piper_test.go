@@ -0,0 +1,38 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPiperSynthesize(t *testing.T) {
+	var spoken string
+	piper := NewPiper("piper", "en_US-lessac-medium.onnx")
+	piper.run = func(ctx context.Context, stdin io.Reader, name string, args ...string) error {
+		data, _ := io.ReadAll(stdin)
+		spoken = string(data)
+		return os.WriteFile(args[len(args)-1], []byte("wav audio"), 0o644)
+	}
+
+	path, err := piper.Synthesize(t.Context(), "Up next")
+	require.NoError(t, err)
+	defer os.Remove(path)
+	assert.Equal(t, "Up next", spoken, "piper reads the text on stdin")
+	assert.FileExists(t, path)
+
+	piper.run = func(ctx context.Context, stdin io.Reader, name string, args ...string) error { return nil }
+	_, err = piper.Synthesize(t.Context(), "Up next")
+	assert.ErrorContains(t, err, "no audio")
+
+	piper.run = func(ctx context.Context, stdin io.Reader, name string, args ...string) error {
+		return errors.New("exit status 1")
+	}
+	_, err = piper.Synthesize(t.Context(), "Up next")
+	assert.ErrorContains(t, err, "piper failed")
+}
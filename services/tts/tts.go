@@ -0,0 +1,131 @@
+// Package tts turns text into speech audio files, with a local piper binary or an OpenAI-compatible
+// speech API.
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"pxnx-discord-bot/tracing"
+)
+
+// Backends that can be chosen with TTS_BACKEND
+const (
+	BackendPiper = "piper" // A local piper binary
+	BackendAPI   = "api"   // An OpenAI-compatible /audio/speech endpoint
+)
+
+// Defaults when the environment doesn't name them
+const (
+	DefaultPiperBinary = "piper"
+	DefaultModel       = "tts-1"
+	DefaultVoice       = "alloy"
+)
+
+// MaxTextLength is the most characters spoken at once, announcements are a sentence or two
+const MaxTextLength = 500
+
+// ErrNoText is returned when there is nothing to say
+var ErrNoText = errors.New("no text to speak")
+
+// Backend speaks text
+type Backend interface {
+	// Synthesize writes text spoken aloud to a new temporary audio file and returns its path
+	Synthesize(ctx context.Context, text string) (string, error)
+	// Name describes the backend for logs and diagnostics
+	Name() string
+}
+
+// Config chooses the backend speaking text
+type Config struct {
+	Backend     string // BackendPiper or BackendAPI, speech is off when empty
+	PiperBinary string // piper command line program
+	PiperModel  string // Path of the .onnx voice piper loads
+	Endpoint    string // Base URL of the API, such as https://api.openai.com/v1
+	APIKey      string // Sent as a bearer token, if set
+	Model       string // Model the API speaks with
+	Voice       string // Voice the API speaks in
+}
+
+// ConfigFromEnv reads TTS_BACKEND, TTS_PIPER_BINARY, TTS_PIPER_MODEL, TTS_ENDPOINT, TTS_API_KEY, TTS_MODEL
+// and TTS_VOICE
+func ConfigFromEnv() Config {
+	config := Config{
+		Backend:     strings.ToLower(strings.TrimSpace(os.Getenv("TTS_BACKEND"))),
+		PiperBinary: strings.TrimSpace(os.Getenv("TTS_PIPER_BINARY")),
+		PiperModel:  strings.TrimSpace(os.Getenv("TTS_PIPER_MODEL")),
+		Endpoint:    strings.TrimRight(strings.TrimSpace(os.Getenv("TTS_ENDPOINT")), "/"),
+		APIKey:      strings.TrimSpace(os.Getenv("TTS_API_KEY")),
+		Model:       strings.TrimSpace(os.Getenv("TTS_MODEL")),
+		Voice:       strings.TrimSpace(os.Getenv("TTS_VOICE")),
+	}
+	if config.PiperBinary == "" {
+		config.PiperBinary = DefaultPiperBinary
+	}
+	if config.Model == "" {
+		config.Model = DefaultModel
+	}
+	if config.Voice == "" {
+		config.Voice = DefaultVoice
+	}
+	return config
+}
+
+// New creates the backend config chooses, nil when speech is off
+func New(config Config) (Backend, error) {
+	switch config.Backend {
+	case "":
+		return nil, nil
+	case BackendPiper:
+		if config.PiperModel == "" {
+			return nil, errors.New("TTS_PIPER_MODEL must name a piper voice file")
+		}
+		return NewPiper(config.PiperBinary, config.PiperModel), nil
+	case BackendAPI:
+		if config.Endpoint == "" {
+			return nil, errors.New("TTS_ENDPOINT must be set for the api backend")
+		}
+		return NewAPI(config.Endpoint, config.APIKey, config.Model, config.Voice), nil
+	default:
+		return nil, fmt.Errorf("unknown TTS_BACKEND %q, expected %s or %s", config.Backend, BackendPiper, BackendAPI)
+	}
+}
+
+// Speaker speaks text with a backend
+type Speaker struct {
+	backend Backend
+}
+
+// NewSpeaker creates a speaker using backend
+func NewSpeaker(backend Backend) *Speaker {
+	return &Speaker{backend: backend}
+}
+
+// Backend returns the backend speaking text
+func (s *Speaker) Backend() Backend {
+	return s.backend
+}
+
+// Speak writes text spoken aloud to a temporary audio file the caller removes, cutting text longer than
+// MaxTextLength short
+func (s *Speaker) Speak(ctx context.Context, text string) (string, error) {
+	ctx, span := tracing.Start(ctx, "tts.speak", tracing.KindClient, "tts.backend", s.backend.Name())
+	defer span.End()
+
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		span.RecordError(ErrNoText)
+		return "", ErrNoText
+	}
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		text = string([]rune(text)[:MaxTextLength])
+	}
+
+	path, err := s.backend.Synthesize(ctx, text)
+	span.RecordError(err)
+	return path, err
+}
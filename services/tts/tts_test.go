@@ -0,0 +1,70 @@
+package tts
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend records the text it was asked to speak
+type fakeBackend struct {
+	text string
+}
+
+func (f *fakeBackend) Synthesize(ctx context.Context, text string) (string, error) {
+	f.text = text
+	return "speech.wav", nil
+}
+
+func (f *fakeBackend) Name() string { return "fake" }
+
+func TestNew(t *testing.T) {
+	backend, err := New(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, backend, "speech is off without a backend")
+
+	_, err = New(Config{Backend: BackendPiper})
+	assert.Error(t, err, "piper needs a voice")
+	backend, err = New(Config{Backend: BackendPiper, PiperBinary: "piper", PiperModel: "/voices/en_US-lessac-medium.onnx"})
+	require.NoError(t, err)
+	assert.Equal(t, "piper (en_US-lessac-medium)", backend.Name())
+
+	_, err = New(Config{Backend: BackendAPI})
+	assert.Error(t, err, "the API needs an endpoint")
+	_, err = New(Config{Backend: "espeak"})
+	assert.Error(t, err)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("TTS_BACKEND", " API ")
+	t.Setenv("TTS_ENDPOINT", "https://api.example.com/v1/")
+	t.Setenv("TTS_MODEL", "")
+	t.Setenv("TTS_VOICE", "")
+	config := ConfigFromEnv()
+	assert.Equal(t, BackendAPI, config.Backend)
+	assert.Equal(t, "https://api.example.com/v1", config.Endpoint)
+	assert.Equal(t, DefaultModel, config.Model)
+	assert.Equal(t, DefaultVoice, config.Voice)
+	assert.Equal(t, DefaultPiperBinary, config.PiperBinary)
+}
+
+func TestSpeak(t *testing.T) {
+	backend := &fakeBackend{}
+	speaker := NewSpeaker(backend)
+
+	path, err := speaker.Speak(t.Context(), "  Up next:\n Bohemian Rhapsody ")
+	require.NoError(t, err)
+	assert.Equal(t, "speech.wav", path)
+	assert.Equal(t, "Up next: Bohemian Rhapsody", backend.text, "whitespace is collapsed")
+
+	_, err = speaker.Speak(t.Context(), strings.Repeat("ä", MaxTextLength+10))
+	require.NoError(t, err)
+	assert.Equal(t, MaxTextLength, utf8.RuneCountInString(backend.text))
+
+	_, err = speaker.Speak(t.Context(), " \n ")
+	assert.ErrorIs(t, err, ErrNoText)
+}
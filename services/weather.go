@@ -8,8 +8,13 @@ import (
 	"net/url"
 	"os"
 	"time"
+
+	"pxnx-discord-bot/httpclient"
 )
 
+// weatherClient calls OpenWeatherMap with the bot's user agent, within OUTBOUND_RATE_LIMITS
+var weatherClient = httpclient.New(10 * time.Second)
+
 // WeatherData represents the response from OpenWeatherMap current weather API
 type WeatherData struct {
 	Main struct {
@@ -87,7 +92,7 @@ func GetWeatherData(city string) (*WeatherData, error) {
 	encodedCity := url.QueryEscape(city)
 	apiURL := fmt.Sprintf("https://api.openweathermap.org/data/2.5/weather?q=%s&appid=%s&units=metric", encodedCity, apiKey)
 
-	resp, err := http.Get(apiURL)
+	resp, err := weatherClient.Get(apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch weather data: %w", err)
 	}
@@ -129,7 +134,7 @@ func GetForecastData(city string, days int) (*ForecastData, error) {
 
 	apiURL := fmt.Sprintf("https://api.openweathermap.org/data/2.5/forecast?q=%s&appid=%s&units=metric&cnt=%d", encodedCity, apiKey, count)
 
-	resp, err := http.Get(apiURL)
+	resp, err := weatherClient.Get(apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch forecast data: %w", err)
 	}
@@ -0,0 +1,48 @@
+package ytdlp
+
+import (
+	"pxnx-discord-bot/music/types"
+)
+
+// BestAudioFormat picks the audio-only format with the highest bitrate, falling back to the best
+// format that carries audio at all
+func (v VideoInfo) BestAudioFormat() (FormatInfo, bool) {
+	var best FormatInfo
+	found, audioOnly := false, false
+
+	for _, format := range v.Formats {
+		if format.URL == "" || format.ACodec == "" || format.ACodec == "none" {
+			continue
+		}
+
+		isAudioOnly := format.VCodec == "none"
+		better := !found ||
+			(isAudioOnly && !audioOnly) ||
+			(isAudioOnly == audioOnly && format.ABR > best.ABR)
+		if better {
+			best, found, audioOnly = format, true, isAudioOnly
+		}
+	}
+	return best, found
+}
+
+// ToAudioSource converts extracted video information into a playable audio source
+func (v VideoInfo) ToAudioSource() types.AudioSource {
+	source := types.AudioSource{
+		Title:     v.Title,
+		URL:       v.URL,
+		Thumbnail: v.Thumbnail,
+		Uploader:  v.Uploader,
+		Provider:  v.Extractor,
+	}
+	if source.Provider == "" {
+		source.Provider = "youtube"
+	}
+
+	source.Duration = types.Seconds(v.Duration)
+	if format, ok := v.BestAudioFormat(); ok {
+		source.StreamURL = format.URL
+		source.StreamExpires, _ = StreamExpiry(format.URL)
+	}
+	return source
+}
@@ -0,0 +1,58 @@
+package ytdlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"pxnx-discord-bot/music/types"
+)
+
+func TestBestAudioFormat(t *testing.T) {
+	video := VideoInfo{Formats: []FormatInfo{
+		{FormatID: "18", URL: "https://stream/18", VCodec: "avc1", ACodec: "mp4a", ABR: 96},
+		{FormatID: "249", URL: "https://stream/249", VCodec: "none", ACodec: "opus", ABR: 50},
+		{FormatID: "251", URL: "https://stream/251", VCodec: "none", ACodec: "opus", ABR: 160},
+		{FormatID: "137", URL: "https://stream/137", VCodec: "avc1", ACodec: "none"},
+	}}
+
+	format, ok := video.BestAudioFormat()
+	assert.True(t, ok)
+	assert.Equal(t, "251", format.FormatID, "audio-only formats win over muxed ones")
+
+	muxedOnly := VideoInfo{Formats: video.Formats[:1]}
+	format, ok = muxedOnly.BestAudioFormat()
+	assert.True(t, ok)
+	assert.Equal(t, "18", format.FormatID)
+
+	_, ok = VideoInfo{Formats: video.Formats[3:]}.BestAudioFormat()
+	assert.False(t, ok)
+}
+
+func TestToAudioSource(t *testing.T) {
+	video := VideoInfo{
+		Title:     "Me at the zoo",
+		URL:       "https://www.youtube.com/watch?v=jNQXAC9IVRw",
+		Thumbnail: "https://i.ytimg.com/vi/jNQXAC9IVRw/hqdefault.jpg",
+		Uploader:  "jawed",
+		Duration:  19,
+		Extractor: "youtube",
+		Formats: []FormatInfo{
+			{FormatID: "251", URL: "https://stream/251", VCodec: "none", ACodec: "opus", ABR: 160},
+		},
+	}
+
+	source := video.ToAudioSource()
+	assert.Equal(t, "Me at the zoo", source.Title)
+	assert.Equal(t, "https://www.youtube.com/watch?v=jNQXAC9IVRw", source.URL)
+	assert.Equal(t, "https://stream/251", source.StreamURL)
+	assert.True(t, source.StreamExpires.IsZero(), "the stream URL doesn't say when it expires")
+	assert.Equal(t, types.Seconds(19), source.Duration)
+	assert.Equal(t, "jawed", source.Uploader)
+	assert.Equal(t, "youtube", source.Provider)
+
+	bare := VideoInfo{Title: "Search result"}.ToAudioSource()
+	assert.Empty(t, bare.StreamURL)
+	assert.False(t, bare.Duration.Known())
+	assert.Equal(t, "youtube", bare.Provider)
+}
@@ -0,0 +1,244 @@
+package ytdlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"pxnx-discord-bot/music/types"
+)
+
+// CLIProviderName is the provider name of tracks resolved by CLIProvider
+const CLIProviderName = "youtube"
+
+// CLIProvider implements types.AudioProvider by running the yt-dlp binary itself and reading its
+// --dump-single-json output, so the bot resolves, searches and lists playlists without the HTTP service.
+// Extracted YouTube tracks are kept in a MetadataCache so playing them again skips yt-dlp.
+type CLIProvider struct {
+	run         CommandRunner
+	format      string
+	cache       atomic.Pointer[MetadataCache]
+	credentials atomic.Pointer[Credentials]
+	proxies     atomic.Pointer[ProxyPool]
+}
+
+// NewCLIProvider creates a provider that runs the yt-dlp binary and format selector named in the config
+func NewCLIProvider(config *ServiceConfig) *CLIProvider {
+	if config == nil {
+		config = DefaultServiceConfig()
+	}
+	return NewCLIProviderWithRunner(config, BinaryRunner(config.BinaryPath))
+}
+
+// NewCLIProviderWithRunner creates a provider that runs yt-dlp through the given runner
+func NewCLIProviderWithRunner(config *ServiceConfig, run CommandRunner) *CLIProvider {
+	if config == nil {
+		config = DefaultServiceConfig()
+	}
+	provider := &CLIProvider{run: run, format: config.Format}
+	provider.cache.Store(NewMetadataCache(config.CacheTTL))
+	provider.UseCredentials(config.Credentials)
+	provider.UseProxies(config.ProxyList()...)
+	return provider
+}
+
+// UseCredentials replaces the cookies and PO token yt-dlp runs with, such as after they were rotated
+func (p *CLIProvider) UseCredentials(credentials Credentials) {
+	p.credentials.Store(&credentials)
+}
+
+// Credentials returns the cookies and PO token yt-dlp runs with
+func (p *CLIProvider) Credentials() Credentials {
+	return *p.credentials.Load()
+}
+
+// UseProxies replaces the proxies yt-dlp runs take turns between, none connects directly
+func (p *CLIProvider) UseProxies(proxies ...string) {
+	p.proxies.Store(NewProxyPool(proxies...))
+}
+
+// Proxies returns how many proxies yt-dlp runs take turns between
+func (p *CLIProvider) Proxies() int {
+	return p.proxies.Load().Len()
+}
+
+// ExtraArgs returns the yt-dlp options every run needs: the credentials and the next proxy in turn
+func (p *CLIProvider) ExtraArgs() []string {
+	return append(p.Credentials().Args(), p.proxies.Load().Args()...)
+}
+
+// UseCache replaces the in-memory metadata cache, such as with one saved to disk
+func (p *CLIProvider) UseCache(cache *MetadataCache) {
+	p.cache.Store(cache)
+}
+
+// Cache returns the metadata cache of extracted tracks
+func (p *CLIProvider) Cache() *MetadataCache {
+	return p.cache.Load()
+}
+
+// GetProviderName returns the provider's name
+func (p *CLIProvider) GetProviderName() string {
+	return CLIProviderName
+}
+
+// Capabilities reports that yt-dlp searches YouTube, expands playlists and plays videos and live streams
+func (p *CLIProvider) Capabilities() types.ProviderCapabilities {
+	return types.ProviderCapabilities{Search: true, Playlists: true, Seek: true, Live: true}
+}
+
+// SupportsURL reports whether a query is a link; yt-dlp supports YouTube and many other sites
+func (p *CLIProvider) SupportsURL(url string) bool {
+	return isURL(url)
+}
+
+// GetAudioSource resolves a link, or the first YouTube search result of other queries, to a track with
+// its stream URL. Tracks extracted before come from the cache while their stream URL is still good.
+func (p *CLIProvider) GetAudioSource(ctx context.Context, query string) (*types.AudioSource, error) {
+	query = strings.TrimSpace(query)
+	cache := p.cache.Load()
+	if track, ok := cache.Get(query); ok {
+		return track, nil
+	}
+
+	target := query
+	if !isURL(query) {
+		target = "ytsearch1:" + query
+	}
+
+	info, err := p.dumpJSON(ctx, "-f", p.format, "--no-playlist", "--", target)
+	if err != nil {
+		return nil, err
+	}
+
+	// Searches come back as a playlist of one
+	if entries := objectList(info["entries"]); len(entries) > 0 {
+		info = entries[0]
+	} else if _, isPlaylist := info["entries"]; isPlaylist {
+		return nil, fmt.Errorf("no results found for %q", query)
+	}
+
+	fallbackURL := ""
+	if isURL(query) {
+		fallbackURL = query
+	}
+	source, err := toAudioSource(info, fallbackURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The selected format's URL is at the top level, the formats list is only a fallback
+	if selected := stringOr(info["url"], ""); selected != "" {
+		source.StreamURL = selected
+		source.StreamExpires, _ = StreamExpiry(selected)
+	}
+	if source.StreamURL == "" {
+		return nil, fmt.Errorf("yt-dlp found no audio stream for %q", query)
+	}
+	cache.Add(query, *source)
+	return source, nil
+}
+
+// Search returns up to maxResults YouTube search results. Results carry metadata only; stream URLs are
+// resolved with GetAudioSource when a track is played.
+func (p *CLIProvider) Search(ctx context.Context, query string, maxResults int) ([]types.AudioSource, error) {
+	if maxResults <= 0 {
+		maxResults = 10
+	}
+	maxResults = min(maxResults, maxServerSearchResults)
+	return p.flatEntries(ctx, fmt.Sprintf("ytsearch%d:%s", maxResults, strings.TrimSpace(query)), maxResults)
+}
+
+// Playlist lists up to limit entries of a playlist, without stream URLs like Search
+func (p *CLIProvider) Playlist(ctx context.Context, url string, limit int) ([]types.AudioSource, error) {
+	return p.flatEntries(ctx, strings.TrimSpace(url), limit)
+}
+
+// flatEntries lists the entries of a playlist or search without extracting each video
+func (p *CLIProvider) flatEntries(ctx context.Context, target string, limit int) ([]types.AudioSource, error) {
+	args := []string{"--flat-playlist"}
+	if limit > 0 {
+		args = append(args, "--playlist-end", fmt.Sprintf("%d", limit))
+	}
+	info, err := p.dumpJSON(ctx, append(args, "--", target)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []types.AudioSource
+	for _, entry := range objectList(info["entries"]) {
+		if limit > 0 && len(sources) >= limit {
+			break
+		}
+
+		// Flat entries link the video with url instead of webpage_url
+		link := stringOr(entry["url"], "")
+		if link == "" || !isURL(link) {
+			continue
+		}
+		// Deleted and private videos show up as placeholders without useful metadata
+		if title := stringOr(entry["title"], ""); title == "[Deleted video]" || title == "[Private video]" {
+			continue
+		}
+
+		source, err := toAudioSource(entry, link)
+		if err != nil {
+			continue
+		}
+		source.StreamURL = ""
+		sources = append(sources, *source)
+	}
+	return sources, nil
+}
+
+// dumpJSON runs yt-dlp in JSON dump mode and decodes its output. Numbers stay json.Number so integer
+// fields decode into VideoInfo exactly as yt-dlp reported them.
+func (p *CLIProvider) dumpJSON(ctx context.Context, args ...string) (map[string]interface{}, error) {
+	base := append([]string{"--dump-single-json", "--no-warnings"}, p.ExtraArgs()...)
+	output, err := p.run(ctx, append(base, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp extraction failed: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.UseNumber()
+
+	var info map[string]interface{}
+	if err := decoder.Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to parse yt-dlp output: %w", err)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("yt-dlp returned no information")
+	}
+	return info, nil
+}
+
+// toAudioSource converts one video of yt-dlp output into a track
+func toAudioSource(info map[string]interface{}, fallbackURL string) (*types.AudioSource, error) {
+	video, err := decodeVideoInfo(cleanVideoInfo(info, fallbackURL))
+	if err != nil {
+		return nil, err
+	}
+
+	source := video.track()
+	return &source, nil
+}
+
+// track converts a video into a track of the YouTube provider, whether the binary or a service extracted it
+func (v VideoInfo) track() types.AudioSource {
+	source := v.ToAudioSource()
+	source.Provider = CLIProviderName
+	source.Live = v.LiveStatus == "is_live"
+	if source.Live {
+		source.Duration = 0
+	}
+	return source
+}
+
+// isURL reports whether a query is an http or https link rather than search terms
+func isURL(query string) bool {
+	return strings.HasPrefix(query, "http://") || strings.HasPrefix(query, "https://")
+}
@@ -0,0 +1,172 @@
+package ytdlp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+// rawFlatSearch is trimmed yt-dlp --flat-playlist --dump-single-json output of a search
+const rawFlatSearch = `{
+	"id": "never gonna",
+	"title": "never gonna",
+	"_type": "playlist",
+	"entries": [
+		{"_type": "url", "id": "dQw4w9WgXcQ", "url": "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		 "title": "Never Gonna Give You Up", "duration": 212.0, "uploader": "Rick Astley",
+		 "thumbnails": [{"url": "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg", "width": 480, "height": 360}]},
+		{"_type": "url", "id": "xxxxxxxxxxx", "url": "https://www.youtube.com/watch?v=xxxxxxxxxxx", "title": "[Private video]"},
+		{"_type": "url", "id": "yPYZpwSpKmA", "url": "https://www.youtube.com/watch?v=yPYZpwSpKmA",
+		 "title": "Together Forever", "duration": 205, "uploader": "Rick Astley"}
+	]
+}`
+
+func TestCLIProviderGetAudioSource(t *testing.T) {
+	runner := &fakeRunner{output: rawVideoInfo}
+	provider := NewCLIProviderWithRunner(nil, runner.run)
+
+	track, err := provider.GetAudioSource(context.Background(), " https://youtu.be/dQw4w9WgXcQ ")
+	require.NoError(t, err)
+	assert.Equal(t, &types.AudioSource{
+		Title:     "Never Gonna Give You Up",
+		URL:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		StreamURL: "https://rr1---sn-example.googlevideo.com/videoplayback?itag=251",
+		Duration:  types.Seconds(212),
+		Thumbnail: "https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg",
+		Uploader:  "Rick Astley",
+		Provider:  CLIProviderName,
+	}, track)
+
+	require.Len(t, runner.calls, 1)
+	assert.Equal(t, []string{"--dump-single-json", "--no-warnings", "-f", "bestaudio/best", "--no-playlist", "--", "https://youtu.be/dQw4w9WgXcQ"}, runner.calls[0])
+}
+
+func TestCLIProviderPassesCredentials(t *testing.T) {
+	runner := &fakeRunner{output: rawVideoInfo}
+	provider := NewCLIProviderWithRunner(&ServiceConfig{Credentials: Credentials{CookiesFile: "cookies.txt"}}, runner.run)
+
+	_, err := provider.GetAudioSource(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--dump-single-json", "--no-warnings", "--cookies", "cookies.txt"}, runner.calls[0][:4])
+
+	provider.UseCredentials(Credentials{POToken: "abc"})
+	provider.Cache().Forget("https://youtu.be/dQw4w9WgXcQ")
+	_, err = provider.GetAudioSource(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	require.Len(t, runner.calls, 2)
+	assert.Equal(t, []string{"--extractor-args", "youtube:po_token=web.gvs+abc"}, runner.calls[1][2:4])
+}
+
+func TestCLIProviderRotatesProxies(t *testing.T) {
+	runner := &fakeRunner{output: rawVideoInfo}
+	provider := NewCLIProviderWithRunner(&ServiceConfig{Proxy: "http://one:3128", Proxies: []string{"socks5://two:1080"}}, runner.run)
+	assert.Equal(t, 2, provider.Proxies())
+
+	for range 3 {
+		provider.Cache().Forget("https://youtu.be/dQw4w9WgXcQ")
+		_, err := provider.GetAudioSource(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+		require.NoError(t, err)
+	}
+	require.Len(t, runner.calls, 3)
+	assert.Equal(t, []string{"--proxy", "http://one:3128"}, runner.calls[0][2:4])
+	assert.Equal(t, []string{"--proxy", "socks5://two:1080"}, runner.calls[1][2:4])
+	assert.Equal(t, []string{"--proxy", "http://one:3128"}, runner.calls[2][2:4])
+
+	provider.UseProxies()
+	assert.Empty(t, provider.ExtraArgs(), "no proxies connect directly")
+}
+
+func TestCLIProviderGetAudioSourceSearchesQueries(t *testing.T) {
+	runner := &fakeRunner{output: `{"_type": "playlist", "entries": [{"title": "Never Gonna Give You Up",
+		"webpage_url": "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "url": "https://example.com/selected.webm?expire=1740830400",
+		"live_status": "is_live", "duration": 60}]}`}
+	provider := NewCLIProviderWithRunner(&ServiceConfig{Format: "bestaudio"}, runner.run)
+
+	track, err := provider.GetAudioSource(context.Background(), "never gonna give you up")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/selected.webm?expire=1740830400", track.StreamURL, "the selected format wins over the formats list")
+	assert.Equal(t, int64(1740830400), track.StreamExpires.Unix(), "the stream URL's expiry is kept with the track")
+	assert.True(t, track.Live)
+	assert.Empty(t, track.Duration, "live streams have no duration")
+	assert.Equal(t, "ytsearch1:never gonna give you up", runner.calls[0][len(runner.calls[0])-1])
+	assert.Contains(t, runner.calls[0], "bestaudio")
+}
+
+func TestCLIProviderGetAudioSourceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		runner  *fakeRunner
+		message string
+	}{
+		{"runner fails", &fakeRunner{err: errors.New("exit status 1: ERROR: Video unavailable")}, "Video unavailable"},
+		{"invalid output", &fakeRunner{output: "not json"}, "failed to parse yt-dlp output"},
+		{"no search results", &fakeRunner{output: `{"_type": "playlist", "entries": []}`}, "no results found"},
+		{"no audio stream", &fakeRunner{output: `{"title": "Silent", "webpage_url": "https://example.com/v"}`}, "no audio stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewCLIProviderWithRunner(nil, tt.runner.run)
+			_, err := provider.GetAudioSource(context.Background(), "query")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestCLIProviderSearch(t *testing.T) {
+	runner := &fakeRunner{output: rawFlatSearch}
+	provider := NewCLIProviderWithRunner(nil, runner.run)
+
+	results, err := provider.Search(context.Background(), "never gonna", 3)
+	require.NoError(t, err)
+	assert.Equal(t, []types.AudioSource{
+		{
+			Title:     "Never Gonna Give You Up",
+			URL:       "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			Duration:  types.Seconds(212),
+			Thumbnail: "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg",
+			Uploader:  "Rick Astley",
+			Provider:  CLIProviderName,
+		},
+		{
+			Title:    "Together Forever",
+			URL:      "https://www.youtube.com/watch?v=yPYZpwSpKmA",
+			Duration: types.Seconds(205),
+			Uploader: "Rick Astley",
+			Provider: CLIProviderName,
+		},
+	}, results, "private videos are skipped and no stream URLs are resolved")
+	assert.Equal(t, []string{"--dump-single-json", "--no-warnings", "--flat-playlist", "--playlist-end", "3", "--", "ytsearch3:never gonna"}, runner.calls[0])
+
+	_, err = provider.Search(context.Background(), "never gonna", 500)
+	require.NoError(t, err)
+	assert.Equal(t, "ytsearch50:never gonna", runner.calls[1][len(runner.calls[1])-1], "searches are capped like the service's")
+}
+
+func TestCLIProviderPlaylist(t *testing.T) {
+	runner := &fakeRunner{output: rawFlatSearch}
+	provider := NewCLIProviderWithRunner(nil, runner.run)
+
+	tracks, err := provider.Playlist(context.Background(), "https://www.youtube.com/playlist?list=PL123", 1)
+	require.NoError(t, err)
+	require.Len(t, tracks, 1)
+	assert.Equal(t, "Never Gonna Give You Up", tracks[0].Title)
+	assert.Equal(t, "https://www.youtube.com/playlist?list=PL123", runner.calls[0][len(runner.calls[0])-1])
+}
+
+func TestCLIProviderSupportsURL(t *testing.T) {
+	provider := NewCLIProviderWithRunner(nil, (&fakeRunner{}).run)
+
+	assert.True(t, provider.SupportsURL("https://soundcloud.com/artist/track"))
+	assert.True(t, provider.SupportsURL("http://www.youtube.com/watch?v=dQw4w9WgXcQ"))
+	assert.False(t, provider.SupportsURL("never gonna give you up"))
+	assert.Equal(t, "youtube", provider.GetProviderName())
+
+	var _ types.AudioProvider = provider
+}
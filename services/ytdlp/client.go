@@ -4,12 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"sync"
 	"time"
+
+	"pxnx-discord-bot/httpclient"
+	"pxnx-discord-bot/tracing"
+	"pxnx-discord-bot/utils"
 )
 
 // Client represents a client for the yt-dlp service
@@ -27,22 +33,12 @@ func NewClient(config *ServiceConfig) *Client {
 		config = DefaultServiceConfig()
 	}
 
-	baseURL := fmt.Sprintf("http://%s:%d", config.Host, config.Port)
-
-	// Create HTTP client with appropriate timeouts
-	httpClient := &http.Client{
-		Timeout: config.Timeout,
-		Transport: &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout:   10 * time.Second,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
-			MaxIdleConns:          10,
-			IdleConnTimeout:       90 * time.Second,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
-			MaxIdleConnsPerHost:   5,
-		},
+	// The service runs next to the bot, requests to it skip the outbound proxy and rate limits
+	baseURL := "http://" + config.Address()
+	httpClient := httpclient.NewInternal(config.Timeout)
+	if config.Socket != "" {
+		baseURL = "http://ytdlp"
+		httpClient = httpclient.NewInternalUnix(config.Socket, config.Timeout)
 	}
 
 	return &Client{
@@ -52,6 +48,25 @@ func NewClient(config *ServiceConfig) *Client {
 	}
 }
 
+// UseURL points the config at a service running at a URL such as http://ytdlp:8080, http://[fd00::5]:8080
+// or unix:/run/ytdlp/ytdlp.sock
+func (config *ServiceConfig) UseURL(raw string) error {
+	if socket, ok := utils.UnixSocketPath(raw); ok {
+		config.Socket = socket
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Hostname() == "" {
+		return fmt.Errorf("invalid yt-dlp service URL %q", raw)
+	}
+	port, err := strconv.Atoi(parsed.Port())
+	if err != nil {
+		return fmt.Errorf("yt-dlp service URL needs an explicit port: %s", raw)
+	}
+	config.Host, config.Port, config.Socket = parsed.Hostname(), port, ""
+	return nil
+}
+
 // HealthCheck checks if the yt-dlp service is healthy
 func (c *Client) HealthCheck(ctx context.Context) (*HealthStatus, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/health", nil)
@@ -76,7 +91,7 @@ func (c *Client) HealthCheck(ctx context.Context) (*HealthStatus, error) {
 		return nil, fmt.Errorf("health check failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var serviceResp ServiceResponse
+	var serviceResp rawServiceResponse
 	if err := json.Unmarshal(body, &serviceResp); err != nil {
 		return nil, fmt.Errorf("failed to parse health check response: %w", err)
 	}
@@ -85,24 +100,18 @@ func (c *Client) HealthCheck(ctx context.Context) (*HealthStatus, error) {
 		return nil, fmt.Errorf("health check failed: %s", serviceResp.Error)
 	}
 
-	healthData, ok := serviceResp.Data.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid health check response format")
+	var data HealthData
+	if err := decodeData(serviceResp.Data, &data); err != nil {
+		return nil, fmt.Errorf("invalid health check response: %w", err)
 	}
 
 	health := &HealthStatus{
-		Status:    getStringFromMap(healthData, "status"),
-		Version:   getStringFromMap(healthData, "version"),
-		Uptime:    getStringFromMap(healthData, "uptime"),
-		LastCheck: time.Now(),
-	}
-
-	if workerCount, ok := healthData["worker_count"].(float64); ok {
-		health.WorkerCount = int(workerCount)
-	}
-
-	if queueSize, ok := healthData["queue_size"].(float64); ok {
-		health.QueueSize = int(queueSize)
+		Status:      data.Status,
+		Version:     data.Version,
+		Uptime:      data.Uptime,
+		LastCheck:   time.Now(),
+		WorkerCount: data.WorkerCount,
+		QueueSize:   data.QueueSize,
 	}
 
 	// Cache the health status
@@ -169,12 +178,12 @@ func (c *Client) ExtractInfoWithFormat(ctx context.Context, url, format string)
 		}
 	}
 
-	videoData, ok := resp.Data.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid response format from yt-dlp service")
+	var video VideoInfo
+	if err := decodeData(resp.Data, &video); err != nil {
+		return nil, fmt.Errorf("invalid extract response from yt-dlp service: %w", err)
 	}
 
-	return c.parseVideoInfo(videoData)
+	return &video, nil
 }
 
 // Search searches for videos using the provided query
@@ -209,12 +218,35 @@ func (c *Client) Search(ctx context.Context, query string, maxResults int) (*Sea
 		}
 	}
 
-	searchData, ok := resp.Data.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid search response format")
+	var result SearchResult
+	if err := decodeData(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("invalid search response from yt-dlp service: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Metrics returns the service's request counts, cache hit ratio and yt-dlp run times
+func (c *Client) Metrics(ctx context.Context) (*ServiceMetrics, error) {
+	resp, err := c.makeRequest(ctx, "GET", "/metrics", nil)
+	if err != nil {
+		return nil, fmt.Errorf("metrics request failed: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, &ServiceError{
+			Code:    resp.Code,
+			Message: resp.Error,
+			Type:    "metrics_failed",
+		}
+	}
+
+	var metrics ServiceMetrics
+	if err := decodeData(resp.Data, &metrics); err != nil {
+		return nil, fmt.Errorf("invalid metrics response from yt-dlp service: %w", err)
 	}
 
-	return c.parseSearchResult(searchData)
+	return &metrics, nil
 }
 
 // ClearCache clears the service cache
@@ -235,8 +267,23 @@ func (c *Client) ClearCache(ctx context.Context) error {
 	return nil
 }
 
-// makeRequest makes an HTTP request to the yt-dlp service
-func (c *Client) makeRequest(ctx context.Context, method, endpoint string, payload interface{}) (*ServiceResponse, error) {
+// makeRequest makes an HTTP request to the yt-dlp service, traced as a span the service continues
+func (c *Client) makeRequest(ctx context.Context, method, endpoint string, payload interface{}) (*rawServiceResponse, error) {
+	ctx, span := tracing.Start(ctx, "ytdlp.client "+method+" "+endpoint, tracing.KindClient,
+		"http.method", method, "http.route", endpoint)
+	defer span.End()
+
+	resp, err := c.sendRequest(ctx, method, endpoint, payload)
+	span.RecordError(err)
+	if err == nil && !resp.Success {
+		span.SetAttributes("ytdlp.code", resp.Code)
+		span.RecordError(errors.New(resp.Error))
+	}
+	return resp, err
+}
+
+// sendRequest sends a request to the yt-dlp service and reads its response envelope
+func (c *Client) sendRequest(ctx context.Context, method, endpoint string, payload interface{}) (*rawServiceResponse, error) {
 	var body io.Reader
 
 	if payload != nil {
@@ -256,6 +303,11 @@ func (c *Client) makeRequest(ctx context.Context, method, endpoint string, paylo
 		req.Header.Set("Content-Type", "application/json")
 	}
 	req.Header.Set("Accept", "application/json")
+	if request, ok := utils.RequestFrom(ctx); ok && request.InteractionID != "" {
+		// Lets the service's logs be matched to the interaction that caused the request
+		req.Header.Set("X-Request-Id", request.InteractionID)
+	}
+	tracing.Inject(ctx, req.Header)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -268,7 +320,7 @@ func (c *Client) makeRequest(ctx context.Context, method, endpoint string, paylo
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	var serviceResp ServiceResponse
+	var serviceResp rawServiceResponse
 	if err := json.Unmarshal(respBody, &serviceResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
@@ -281,173 +333,40 @@ func (c *Client) makeRequest(ctx context.Context, method, endpoint string, paylo
 	return &serviceResp, nil
 }
 
-// parseVideoInfo parses video information from the service response
-func (c *Client) parseVideoInfo(data map[string]interface{}) (*VideoInfo, error) {
-	video := &VideoInfo{
-		ID:           getStringFromMap(data, "id"),
-		Title:        getStringFromMap(data, "title"),
-		Description:  getStringFromMap(data, "description"),
-		URL:          getStringFromMap(data, "webpage_url"),
-		Thumbnail:    getStringFromMap(data, "thumbnail"),
-		Uploader:     getStringFromMap(data, "uploader"),
-		UploadDate:   getStringFromMap(data, "upload_date"),
-		Extractor:    getStringFromMap(data, "extractor"),
-		ExtractorKey: getStringFromMap(data, "extractor_key"),
-		LiveStatus:   getStringFromMap(data, "live_status"),
-		Available:    getBoolFromMap(data, "available"),
-	}
-
-	if duration, ok := data["duration"].(float64); ok {
-		video.Duration = duration
-	}
-
-	if viewCount, ok := data["view_count"].(float64); ok {
-		video.ViewCount = int64(viewCount)
-	}
-
-	// Parse tags
-	if tagsInterface, ok := data["tags"].([]interface{}); ok {
-		tags := make([]string, 0, len(tagsInterface))
-		for _, tag := range tagsInterface {
-			if tagStr, ok := tag.(string); ok {
-				tags = append(tags, tagStr)
-			}
-		}
-		video.Tags = tags
-	}
-
-	// Parse categories
-	if categoriesInterface, ok := data["categories"].([]interface{}); ok {
-		categories := make([]string, 0, len(categoriesInterface))
-		for _, category := range categoriesInterface {
-			if categoryStr, ok := category.(string); ok {
-				categories = append(categories, categoryStr)
-			}
-		}
-		video.Categories = categories
-	}
-
-	// Parse formats
-	if formatsInterface, ok := data["formats"].([]interface{}); ok {
-		formats := make([]FormatInfo, 0, len(formatsInterface))
-		for _, formatInterface := range formatsInterface {
-			if formatData, ok := formatInterface.(map[string]interface{}); ok {
-				format := FormatInfo{
-					FormatID: getStringFromMap(formatData, "format_id"),
-					URL:      getStringFromMap(formatData, "url"),
-					Ext:      getStringFromMap(formatData, "ext"),
-					Format:   getStringFromMap(formatData, "format"),
-					Protocol: getStringFromMap(formatData, "protocol"),
-					VCodec:   getStringFromMap(formatData, "vcodec"),
-					ACodec:   getStringFromMap(formatData, "acodec"),
-					Language: getStringFromMap(formatData, "language"),
-				}
-
-				if width, ok := formatData["width"].(float64); ok {
-					format.Width = int(width)
-				}
-				if height, ok := formatData["height"].(float64); ok {
-					format.Height = int(height)
-				}
-				if fps, ok := formatData["fps"].(float64); ok {
-					format.FPS = fps
-				}
-				if tbr, ok := formatData["tbr"].(float64); ok {
-					format.TBR = tbr
-				}
-				if vbr, ok := formatData["vbr"].(float64); ok {
-					format.VBR = vbr
-				}
-				if abr, ok := formatData["abr"].(float64); ok {
-					format.ABR = abr
-				}
-				if asr, ok := formatData["asr"].(float64); ok {
-					format.ASR = int(asr)
-				}
-				if filesize, ok := formatData["filesize"].(float64); ok {
-					format.Filesize = int64(filesize)
-				}
-				if quality, ok := formatData["quality"].(float64); ok {
-					format.Quality = int(quality)
-				}
-				if preference, ok := formatData["preference"].(float64); ok {
-					format.Preference = int(preference)
-				}
-
-				formats = append(formats, format)
-			}
-		}
-		video.Formats = formats
-	}
-
-	// Parse thumbnails
-	if thumbnailsInterface, ok := data["thumbnails"].([]interface{}); ok {
-		thumbnails := make([]ThumbnailInfo, 0, len(thumbnailsInterface))
-		for _, thumbnailInterface := range thumbnailsInterface {
-			if thumbnailData, ok := thumbnailInterface.(map[string]interface{}); ok {
-				thumbnail := ThumbnailInfo{
-					ID:         getStringFromMap(thumbnailData, "id"),
-					URL:        getStringFromMap(thumbnailData, "url"),
-					Resolution: getStringFromMap(thumbnailData, "resolution"),
-				}
-
-				if width, ok := thumbnailData["width"].(float64); ok {
-					thumbnail.Width = int(width)
-				}
-				if height, ok := thumbnailData["height"].(float64); ok {
-					thumbnail.Height = int(height)
-				}
-
-				thumbnails = append(thumbnails, thumbnail)
-			}
-		}
-		video.Thumbnails = thumbnails
-	}
-
-	return video, nil
+// rawServiceResponse is a ServiceResponse as the client reads it, its data is decoded once the
+// endpoint's type is known
+type rawServiceResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Code    int             `json:"code,omitempty"`
 }
 
-// parseSearchResult parses search results from the service response
-func (c *Client) parseSearchResult(data map[string]interface{}) (*SearchResult, error) {
-	result := &SearchResult{
-		Query: getStringFromMap(data, "query"),
+// decodeData decodes a response's data into the endpoint's type. A missing payload or a field of the
+// wrong type is an error naming the field, rather than a zero value.
+func decodeData(data json.RawMessage, target interface{}) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return fmt.Errorf("response has no data")
 	}
-
-	if totalCount, ok := data["total_count"].(float64); ok {
-		result.TotalCount = int(totalCount)
-	}
-
-	if videosInterface, ok := data["videos"].([]interface{}); ok {
-		videos := make([]VideoInfo, 0, len(videosInterface))
-		for _, videoInterface := range videosInterface {
-			if videoData, ok := videoInterface.(map[string]interface{}); ok {
-				video, err := c.parseVideoInfo(videoData)
-				if err != nil {
-					continue // Skip invalid videos
-				}
-				videos = append(videos, *video)
-			}
-		}
-		result.Videos = videos
+	if err := json.Unmarshal(trimmed, target); err != nil {
+		return fmt.Errorf("malformed response data: %w", err)
 	}
-
-	return result, nil
+	return nil
 }
 
-// Helper functions for safely extracting values from maps
-
-func getStringFromMap(m map[string]interface{}, key string) string {
-	if val, ok := m[key].(string); ok {
-		return val
+// decodeVideoInfo decodes yt-dlp output cleaned into the shape of an /extract response
+func decodeVideoInfo(data map[string]interface{}) (*VideoInfo, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode video information: %w", err)
 	}
-	return ""
-}
 
-func getBoolFromMap(m map[string]interface{}, key string) bool {
-	if val, ok := m[key].(bool); ok {
-		return val
+	var video VideoInfo
+	if err := decodeData(raw, &video); err != nil {
+		return nil, err
 	}
-	return false
+	return &video, nil
 }
 
 // Close closes the client and cleans up resources
@@ -0,0 +1,98 @@
+package ytdlp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/tracing"
+	"pxnx-discord-bot/utils"
+)
+
+func TestClientSendsRequestID(t *testing.T) {
+	var requestIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestIDs = append(requestIDs, r.Header.Get("X-Request-Id"))
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.baseURL = server.URL
+
+	ctx := utils.WithRequest(context.Background(), utils.RequestInfo{InteractionID: "789", GuildID: "123"})
+	require.NoError(t, client.ClearCache(ctx))
+	require.NoError(t, client.ClearCache(context.Background()))
+	assert.Equal(t, []string{"789", ""}, requestIDs)
+}
+
+func TestClientContinuesTrace(t *testing.T) {
+	var traceParents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents = append(traceParents, r.Header.Get(tracing.TraceParentHeader))
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+	shutdown := tracing.Configure(tracing.Config{Endpoint: server.URL + "/v1/traces", SampleRatio: 1})
+	defer shutdown(context.Background())
+
+	client := NewClient(nil)
+	client.baseURL = server.URL
+
+	queued, _ := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.NoError(t, client.ClearCache(tracing.ContextWithSpanContext(context.Background(), queued)))
+	require.Len(t, traceParents, 1)
+
+	// The service sees the client's span, a child of the caller's
+	sent, ok := tracing.ParseTraceParent(traceParents[0])
+	require.True(t, ok)
+	assert.Equal(t, queued.TraceID, sent.TraceID)
+	assert.NotEqual(t, queued.SpanID, sent.SpanID)
+}
+
+func TestClientReportsMalformedResponses(t *testing.T) {
+	responses := map[string]string{
+		"/extract": `{"success": true, "data": {"id": "abc", "formats": [{"format_id": "251", "width": "wide"}]}}`,
+		"/search":  `{"success": true, "data": null}`,
+		"/health":  `{"success": true, "data": {"status": "healthy", "worker_count": 2.5}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(responses[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.baseURL = server.URL
+
+	_, err := client.ExtractInfo(context.Background(), "https://youtu.be/abc")
+	assert.ErrorContains(t, err, "formats.0.width")
+
+	_, err = client.Search(context.Background(), "query", 5)
+	assert.ErrorContains(t, err, "response has no data")
+
+	_, err = client.HealthCheck(context.Background())
+	assert.ErrorContains(t, err, "worker_count")
+	assert.Nil(t, client.GetLastHealthStatus())
+}
+
+func TestClientDecodesHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "data": {"status": "healthy", "version": "2025.01.15", "uptime": "1:02:03",
+			"request_count": 10, "error_count": 1, "worker_count": 4, "last_check": "2025-01-15T12:00:00.123456"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.baseURL = server.URL
+
+	health, err := client.HealthCheck(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", health.Status)
+	assert.Equal(t, "2025.01.15", health.Version)
+	assert.Equal(t, 4, health.WorkerCount)
+	assert.True(t, client.IsHealthy())
+}
@@ -0,0 +1,188 @@
+package ytdlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultDockerSocket is where the Docker Engine API listens
+const DefaultDockerSocket = "/var/run/docker.sock"
+
+// containerWaitTimeout bounds one request waiting for a container to exit; the wait is repeated after it
+const containerWaitTimeout = time.Hour
+
+// ContainerLauncher runs the Python service as a sibling container: one created next to the bot's, such
+// as a compose service with restart: "no" and the services/ytdlp image, that it starts and stops through
+// the Docker Engine API. The bot's container needs the API socket mounted, and reaches the service at the
+// ServiceConfig's address over their shared network, such as YTDLP_SERVICE_URL=http://ytdlp:8080. A
+// HEALTHCHECK of the container reported unhealthy fails the manager's health checks.
+type ContainerLauncher struct {
+	Container string // Name or ID of the container
+	Socket    string // Docker Engine API socket, DefaultDockerSocket when empty
+}
+
+// Launch starts the container, which is fine if it already runs
+func (l ContainerLauncher) Launch(ctx context.Context, _ *ServiceConfig) (Process, error) {
+	if l.Container == "" {
+		return nil, fmt.Errorf("no container to launch the yt-dlp service in")
+	}
+	socket := l.Socket
+	if socket == "" {
+		socket = DefaultDockerSocket
+	}
+	process := &containerProcess{
+		name:   l.Container,
+		client: httpclient.NewInternalUnix(socket, containerWaitTimeout),
+		exited: make(chan struct{}),
+	}
+	if err := process.call(ctx, http.MethodPost, "/start", nil, nil); err != nil {
+		return nil, fmt.Errorf("failed to start container %s: %w", l.Container, err)
+	}
+	return process, nil
+}
+
+// containerProcess is a container started by ContainerLauncher
+type containerProcess struct {
+	name   string
+	client *http.Client
+
+	once    sync.Once
+	exited  chan struct{}
+	waitErr error
+}
+
+// dockerError is the body of a failed Docker Engine API request
+type dockerError struct {
+	Message string `json:"message"`
+}
+
+// call sends a request about the container and decodes the response into out, if not nil. 304 Not
+// Modified, answered when the container already is in the requested state, counts as success.
+func (c *containerProcess) call(ctx context.Context, method, action string, query url.Values, out any) error {
+	endpoint := "http://docker/containers/" + url.PathEscape(c.name) + action
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode/100 != 2 {
+		var body dockerError
+		json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&body)
+		return fmt.Errorf("docker responded %s: %s", resp.Status, body.Message)
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// Wait blocks until the container stops running
+func (c *containerProcess) Wait() error {
+	c.once.Do(func() {
+		c.waitErr = c.wait()
+		close(c.exited)
+	})
+	<-c.exited
+	return c.waitErr
+}
+
+// wait asks Docker to answer once the container stopped, asking again when a request times out
+func (c *containerProcess) wait() error {
+	var result struct {
+		StatusCode int
+		Error      *struct{ Message string }
+	}
+	for {
+		err := c.call(context.Background(), http.MethodPost, "/wait", url.Values{"condition": {"not-running"}}, &result)
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) && urlErr.Timeout() {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("lost track of container %s: %w", c.name, err)
+		}
+		break
+	}
+	switch {
+	case result.Error != nil && result.Error.Message != "":
+		return fmt.Errorf("container %s exited: %s", c.name, result.Error.Message)
+	case result.StatusCode != 0:
+		return fmt.Errorf("container %s exited with status %d", c.name, result.StatusCode)
+	default:
+		return nil
+	}
+}
+
+// Stop has Docker send the container SIGTERM and SIGKILL after timeout
+func (c *containerProcess) Stop(timeout time.Duration) error {
+	utils.SafeGo("ytdlp.waitForContainer", func() { c.Wait() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout+stopTimeout)
+	defer cancel()
+	seconds := strconv.Itoa(int(timeout.Round(time.Second) / time.Second))
+	if err := c.call(ctx, http.MethodPost, "/stop", url.Values{"t": {seconds}}, nil); err != nil {
+		return fmt.Errorf("failed to stop container %s: %w", c.name, err)
+	}
+	select {
+	case <-c.exited:
+	case <-ctx.Done():
+		return fmt.Errorf("container %s didn't stop", c.name)
+	}
+	return nil
+}
+
+// Healthy reports a container that stopped or that its HEALTHCHECK marks unhealthy
+func (c *containerProcess) Healthy(ctx context.Context) error {
+	var inspect struct {
+		State struct {
+			Status  string
+			Running bool
+			Health  *struct {
+				Status string
+				Log    []struct{ Output string }
+			}
+		}
+	}
+	if err := c.call(ctx, http.MethodGet, "/json", nil, &inspect); err != nil {
+		return fmt.Errorf("failed to inspect container %s: %w", c.name, err)
+	}
+	state := inspect.State
+	if !state.Running {
+		return fmt.Errorf("container %s is %s", c.name, state.Status)
+	}
+	if state.Health != nil && state.Health.Status == "unhealthy" {
+		message := "container " + c.name + " is unhealthy"
+		if checks := state.Health.Log; len(checks) > 0 {
+			message += ": " + strings.TrimSpace(checks[len(checks)-1].Output)
+		}
+		return errors.New(message)
+	}
+	return nil
+}
+
+func (c *containerProcess) String() string {
+	return "container " + c.name
+}
@@ -0,0 +1,254 @@
+package ytdlp
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The schema in schema/responses.schema.json is shared with server.py, whose own contract tests
+// (test_server_contract.py) validate what it emits against the same definitions.
+
+type schemaProperty struct {
+	Type  interface{}     `json:"type"` // A type name or a list of them
+	Items *schemaProperty `json:"items"`
+	Ref   string          `json:"$ref"`
+}
+
+type schemaDefinition struct {
+	Properties map[string]schemaProperty `json:"properties"`
+	Required   []string                  `json:"required"`
+}
+
+// loadContractSchema reads the response definitions shared with the Python server
+func loadContractSchema(t *testing.T) map[string]schemaDefinition {
+	t.Helper()
+
+	raw, err := os.ReadFile("schema/responses.schema.json")
+	require.NoError(t, err)
+
+	var schema struct {
+		Defs map[string]schemaDefinition `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &schema))
+	return schema.Defs
+}
+
+// loadVideoFixture reads a sample /extract payload as the client receives it
+func loadVideoFixture(t *testing.T) map[string]interface{} {
+	t.Helper()
+
+	raw, err := os.ReadFile("testdata/video_info.json")
+	require.NoError(t, err)
+
+	var data map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &data))
+	return data
+}
+
+// types lists the JSON types a property allows
+func (p schemaProperty) types() []string {
+	switch value := p.Type.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		names := make([]string, 0, len(value))
+		for _, name := range value {
+			names = append(names, name.(string))
+		}
+		return names
+	}
+	return nil
+}
+
+// jsonFields maps the JSON names of a struct's fields to their Go types
+func jsonFields(structType reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = field.Type
+		}
+	}
+	return fields
+}
+
+// compatibleKind reports whether a Go type can hold every non-null value a schema type allows
+func compatibleKind(jsonType string, goType reflect.Type) bool {
+	switch jsonType {
+	case "null":
+		return true
+	case "string":
+		return goType.Kind() == reflect.String
+	case "boolean":
+		return goType.Kind() == reflect.Bool
+	case "integer":
+		switch goType.Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64, reflect.Float64:
+			return true
+		}
+	case "number":
+		// Fractional values don't decode into integer fields
+		return goType.Kind() == reflect.Float64
+	case "array":
+		return goType.Kind() == reflect.Slice
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestContractSchemaMatchesGoTypes(t *testing.T) {
+	defs := loadContractSchema(t)
+
+	tests := []struct {
+		definition string
+		goType     reflect.Type
+	}{
+		{"VideoInfo", reflect.TypeOf(VideoInfo{})},
+		{"FormatInfo", reflect.TypeOf(FormatInfo{})},
+		{"ThumbnailInfo", reflect.TypeOf(ThumbnailInfo{})},
+		{"SearchResult", reflect.TypeOf(SearchResult{})},
+		{"ServiceResponse", reflect.TypeOf(ServiceResponse{})},
+		{"ServiceMetrics", reflect.TypeOf(ServiceMetrics{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.definition, func(t *testing.T) {
+			definition, exists := defs[tt.definition]
+			require.True(t, exists, "schema has no %s definition", tt.definition)
+
+			fields := jsonFields(tt.goType)
+			assert.Equal(t, sortedKeys(definition.Properties), sortedKeys(fields),
+				"schema properties and %s JSON fields must match", tt.goType.Name())
+
+			for name, property := range definition.Properties {
+				goType, exists := fields[name]
+				if !exists || property.Type == nil {
+					continue
+				}
+				for _, jsonType := range property.types() {
+					assert.True(t, compatibleKind(jsonType, goType),
+						"%s.%s: schema type %q does not fit Go type %s", tt.definition, name, jsonType, goType)
+				}
+			}
+		})
+	}
+}
+
+func TestContractFixtureCoversSchema(t *testing.T) {
+	defs := loadContractSchema(t)
+	data := loadVideoFixture(t)
+
+	assert.ElementsMatch(t, sortedKeys(defs["VideoInfo"].Properties), sortedKeys(data))
+
+	formats := data["formats"].([]interface{})
+	require.NotEmpty(t, formats)
+	for _, format := range formats {
+		assert.ElementsMatch(t, sortedKeys(defs["FormatInfo"].Properties), sortedKeys(format.(map[string]interface{})))
+	}
+
+	thumbnails := data["thumbnails"].([]interface{})
+	require.NotEmpty(t, thumbnails)
+	for _, thumbnail := range thumbnails {
+		assert.ElementsMatch(t, sortedKeys(defs["ThumbnailInfo"].Properties), sortedKeys(thumbnail.(map[string]interface{})))
+	}
+}
+
+func TestContractDecodeVideoInfo(t *testing.T) {
+	data := loadVideoFixture(t)
+
+	// Decoding the fixture file straight into the struct is the reference for decoding cleaned output
+	raw, err := json.Marshal(data)
+	require.NoError(t, err)
+	var expected VideoInfo
+	require.NoError(t, json.Unmarshal(raw, &expected))
+
+	// The fixture sets every field, so a field whose JSON name doesn't match shows up as a zero value
+	assertNoZeroFields(t, expected)
+	assertNoZeroFields(t, expected.Formats[len(expected.Formats)-1])
+	assertNoZeroFields(t, expected.Thumbnails[0])
+
+	video, err := decodeVideoInfo(data)
+	require.NoError(t, err)
+	assert.Equal(t, expected, *video)
+}
+
+func TestContractDecodeVideoInfoNulls(t *testing.T) {
+	defs := loadContractSchema(t)
+	data := loadVideoFixture(t)
+
+	// Null every nullable field the way yt-dlp leaves missing metadata
+	nullify := func(object map[string]interface{}, definition schemaDefinition) {
+		for name, property := range definition.Properties {
+			for _, jsonType := range property.types() {
+				if jsonType == "null" {
+					object[name] = nil
+				}
+			}
+		}
+	}
+	nullify(data, defs["VideoInfo"])
+	for _, format := range data["formats"].([]interface{}) {
+		nullify(format.(map[string]interface{}), defs["FormatInfo"])
+	}
+	for _, thumbnail := range data["thumbnails"].([]interface{}) {
+		nullify(thumbnail.(map[string]interface{}), defs["ThumbnailInfo"])
+	}
+
+	video, err := decodeVideoInfo(data)
+	require.NoError(t, err)
+	assert.Equal(t, "dQw4w9WgXcQ", video.ID)
+	assert.Zero(t, video.Duration)
+	assert.Empty(t, video.LiveStatus)
+	assert.Nil(t, video.Tags)
+	require.Len(t, video.Formats, 2)
+	assert.Equal(t, "251", video.Formats[0].FormatID)
+	assert.Zero(t, video.Formats[0].ABR)
+	require.Len(t, video.Thumbnails, 1)
+	assert.Zero(t, video.Thumbnails[0].Width)
+}
+
+func TestContractDecodeSearchResult(t *testing.T) {
+	data := map[string]interface{}{
+		"videos":      []interface{}{loadVideoFixture(t)},
+		"total_count": float64(1),
+		"query":       "never gonna give you up",
+	}
+
+	raw, err := json.Marshal(data)
+	require.NoError(t, err)
+
+	var result SearchResult
+	require.NoError(t, decodeData(raw, &result))
+	assert.Equal(t, "never gonna give you up", result.Query)
+	assert.Equal(t, 1, result.TotalCount)
+	require.Len(t, result.Videos, 1)
+	assert.Equal(t, "dQw4w9WgXcQ", result.Videos[0].ID)
+	assert.Len(t, result.Videos[0].Formats, 2)
+}
+
+// assertNoZeroFields fails for every field of a struct left at its zero value
+func assertNoZeroFields(t *testing.T, value interface{}) {
+	t.Helper()
+
+	structValue := reflect.ValueOf(value)
+	for index := 0; index < structValue.NumField(); index++ {
+		assert.False(t, structValue.Field(index).IsZero(),
+			"fixture leaves %s.%s unset", structValue.Type().Name(), structValue.Type().Field(index).Name)
+	}
+}
@@ -0,0 +1,62 @@
+package ytdlp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultPOTokenContext is the client and context of PO tokens given without one, the web client's
+// token for video streams
+const defaultPOTokenContext = "web.gvs"
+
+// Credentials sign yt-dlp in to YouTube. Age-restricted videos need a signed-in account, and YouTube
+// asks servers it flags as bots for cookies or a proof of origin (PO) token.
+type Credentials struct {
+	CookiesFile        string `json:"cookies_file,omitempty"`         // cookies.txt exported from a signed-in browser
+	CookiesFromBrowser string `json:"cookies_from_browser,omitempty"` // Browser yt-dlp reads cookies from, such as "firefox" or "chrome:Profile 1"
+	POToken            string `json:"po_token,omitempty"`             // CLIENT.CONTEXT+TOKEN, a bare token is taken as web.gvs
+}
+
+// IsZero reports whether no credentials are configured
+func (c Credentials) IsZero() bool {
+	return c == Credentials{}
+}
+
+// Validate checks that the cookie sources don't conflict and that the cookies file can be read
+func (c Credentials) Validate() error {
+	if c.CookiesFile != "" && c.CookiesFromBrowser != "" {
+		return errors.New("set either a cookies file or a browser to read cookies from, not both")
+	}
+	if c.CookiesFile != "" {
+		file, err := os.Open(c.CookiesFile)
+		if err != nil {
+			return fmt.Errorf("cookies file can't be read: %w", err)
+		}
+		file.Close()
+	}
+	if strings.ContainsAny(c.POToken, " \t\n") {
+		return errors.New("PO token contains whitespace")
+	}
+	return nil
+}
+
+// Args returns the yt-dlp options that pass the credentials
+func (c Credentials) Args() []string {
+	var args []string
+	switch {
+	case c.CookiesFile != "":
+		args = append(args, "--cookies", c.CookiesFile)
+	case c.CookiesFromBrowser != "":
+		args = append(args, "--cookies-from-browser", c.CookiesFromBrowser)
+	}
+	if c.POToken != "" {
+		token := c.POToken
+		if !strings.Contains(token, "+") {
+			token = defaultPOTokenContext + "+" + token
+		}
+		args = append(args, "--extractor-args", "youtube:po_token="+token)
+	}
+	return args
+}
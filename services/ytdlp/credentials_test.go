@@ -0,0 +1,58 @@
+package ytdlp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentialsArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials Credentials
+		expected    []string
+	}{
+		{"none", Credentials{}, nil},
+		{"cookies file", Credentials{CookiesFile: "cookies.txt"}, []string{"--cookies", "cookies.txt"}},
+		{"browser", Credentials{CookiesFromBrowser: "firefox"}, []string{"--cookies-from-browser", "firefox"}},
+		{"bare token", Credentials{POToken: "abc"}, []string{"--extractor-args", "youtube:po_token=web.gvs+abc"}},
+		{"token with context", Credentials{CookiesFile: "cookies.txt", POToken: "mweb.gvs+abc"},
+			[]string{"--cookies", "cookies.txt", "--extractor-args", "youtube:po_token=mweb.gvs+abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.credentials.Args())
+		})
+	}
+}
+
+func TestCredentialsValidate(t *testing.T) {
+	cookies := filepath.Join(t.TempDir(), "cookies.txt")
+	assert.NoError(t, os.WriteFile(cookies, []byte("# Netscape HTTP Cookie File\n"), 0o600))
+
+	tests := []struct {
+		name        string
+		credentials Credentials
+		wantErr     string
+	}{
+		{"none", Credentials{}, ""},
+		{"readable cookies", Credentials{CookiesFile: cookies, POToken: "abc"}, ""},
+		{"missing cookies", Credentials{CookiesFile: cookies + ".missing"}, "can't be read"},
+		{"both cookie sources", Credentials{CookiesFile: cookies, CookiesFromBrowser: "chrome"}, "not both"},
+		{"token with whitespace", Credentials{POToken: "ab c"}, "whitespace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.credentials.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package ytdlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// ServiceProvider resolves and searches tracks through a yt-dlp service
+type ServiceProvider struct {
+	client *ResilientClient
+	format string
+}
+
+// NewServiceProvider creates a provider that asks the service behind client for the given yt-dlp format
+func NewServiceProvider(client *ResilientClient, format string) *ServiceProvider {
+	return &ServiceProvider{client: client, format: format}
+}
+
+// GetProviderName returns the provider's name, the same as CLIProvider's since both run yt-dlp
+func (p *ServiceProvider) GetProviderName() string {
+	return CLIProviderName
+}
+
+// Capabilities reports that the service searches YouTube and plays videos and live streams. Playlists are
+// only listed by the binary.
+func (p *ServiceProvider) Capabilities() types.ProviderCapabilities {
+	return types.ProviderCapabilities{Search: true, Seek: true, Live: true}
+}
+
+// SupportsURL reports whether a query is a link
+func (p *ServiceProvider) SupportsURL(url string) bool {
+	return isURL(url)
+}
+
+// GetAudioSource resolves a link, or the first YouTube search result of other queries, to a track with
+// its stream URL
+func (p *ServiceProvider) GetAudioSource(ctx context.Context, query string) (*types.AudioSource, error) {
+	target := strings.TrimSpace(query)
+	if !isURL(target) {
+		results, err := p.Search(ctx, target, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) == 0 {
+			return nil, &ServiceError{Code: http.StatusNotFound, Message: fmt.Sprintf("no results found for %q", query), Type: "search_failed"}
+		}
+		target = results[0].URL
+	}
+
+	video, err := p.client.ExtractInfoWithFormat(ctx, target, p.format)
+	if err != nil {
+		return nil, err
+	}
+	source := video.track()
+	if source.StreamURL == "" {
+		return nil, &ServiceError{Code: http.StatusNotFound, Message: fmt.Sprintf("yt-dlp service found no audio stream for %q", query), Type: "extraction_failed"}
+	}
+	return &source, nil
+}
+
+// Search returns up to maxResults YouTube search results without stream URLs, none when nothing matches
+func (p *ServiceProvider) Search(ctx context.Context, query string, maxResults int) ([]types.AudioSource, error) {
+	result, err := p.client.Search(ctx, strings.TrimSpace(query), maxResults)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]types.AudioSource, 0, len(result.Videos))
+	for _, video := range result.Videos {
+		source := video.track()
+		source.StreamURL = ""
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// FailoverProvider extracts through a yt-dlp service while it is healthy and runs the yt-dlp binary when
+// it isn't: when its circuit breaker is open, or a request fails for a reason other than the video.
+// Requests go back to the service once the breaker lets them through again.
+type FailoverProvider struct {
+	service *ServiceProvider
+	cli     *CLIProvider
+	failed  atomic.Bool // The last request fell back to the binary
+}
+
+// NewFailoverProvider creates a provider that prefers service and falls back to cli
+func NewFailoverProvider(service *ServiceProvider, cli *CLIProvider) *FailoverProvider {
+	return &FailoverProvider{service: service, cli: cli}
+}
+
+// GetProviderName returns the provider's name, the same as CLIProvider's
+func (p *FailoverProvider) GetProviderName() string {
+	return CLIProviderName
+}
+
+// Capabilities reports what the binary supports, since anything the service can't do falls back to it
+func (p *FailoverProvider) Capabilities() types.ProviderCapabilities {
+	return p.cli.Capabilities()
+}
+
+// SupportsURL reports whether a query is a link
+func (p *FailoverProvider) SupportsURL(url string) bool {
+	return p.cli.SupportsURL(url)
+}
+
+// GetAudioSource resolves a query with the service, or with the binary when the service is unavailable
+func (p *FailoverProvider) GetAudioSource(ctx context.Context, query string) (*types.AudioSource, error) {
+	track, err := p.service.GetAudioSource(ctx, query)
+	if !p.useFallback(ctx, err) {
+		return track, err
+	}
+	return p.cli.GetAudioSource(ctx, query)
+}
+
+// Search searches with the service, or with the binary when the service is unavailable
+func (p *FailoverProvider) Search(ctx context.Context, query string, maxResults int) ([]types.AudioSource, error) {
+	results, err := p.service.Search(ctx, query, maxResults)
+	if !p.useFallback(ctx, err) {
+		return results, err
+	}
+	return p.cli.Search(ctx, query, maxResults)
+}
+
+// CheckHealth asks the service for its health through the circuit breaker, so a service that recovered
+// closes the breaker without a listener's request having to find out. Schedule it to run regularly.
+func (p *FailoverProvider) CheckHealth(ctx context.Context) error {
+	_, err := p.service.client.HealthCheck(ctx)
+	if err == nil && p.failed.CompareAndSwap(true, false) {
+		utils.LogInfo("yt-dlp service is healthy again, extracting through it")
+	}
+	return err
+}
+
+// UsingService reports whether requests currently go to the service rather than the binary
+func (p *FailoverProvider) UsingService() bool {
+	return !p.failed.Load()
+}
+
+// CircuitState returns the state of the service's circuit breaker
+func (p *FailoverProvider) CircuitState() CircuitBreakerState {
+	return p.service.client.GetCircuitBreakerState()
+}
+
+// Circuit returns the state and counters of the service's circuit breaker
+func (p *FailoverProvider) Circuit() CircuitBreakerMetrics {
+	return p.service.client.CircuitBreakerSnapshot()
+}
+
+// RetryMetrics returns the requests and retries of every operation sent to the service
+func (p *FailoverProvider) RetryMetrics() map[Operation]RetryStats {
+	return p.service.client.RetryMetrics()
+}
+
+// ResetCircuit closes the service's circuit breaker so the next request goes to the service again,
+// returning the state the breaker was in
+func (p *FailoverProvider) ResetCircuit() CircuitBreakerState {
+	previous := p.service.client.ResetCircuitBreaker()
+	p.failed.Store(false)
+	return previous
+}
+
+// useFallback reports whether a service request's outcome means the binary should take the request, and
+// logs switching between the two
+func (p *FailoverProvider) useFallback(ctx context.Context, err error) bool {
+	if !serviceUnavailable(err) {
+		if err == nil && p.failed.CompareAndSwap(true, false) {
+			utils.LogInfoContext(ctx, "yt-dlp service is back, extracting through it again")
+		}
+		return false
+	}
+	if !p.failed.Swap(true) {
+		utils.LogWarnContext(ctx, "yt-dlp service is unavailable, running the yt-dlp binary instead: %v", err)
+	}
+	return true
+}
+
+// serviceUnavailable reports whether a service request failed because of the service rather than the
+// query: the breaker is open, the service couldn't be reached or it failed with a server error
+func serviceUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Code >= 500
+	}
+	return !errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,144 @@
+package ytdlp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serviceVideo is an /extract response of the yt-dlp service
+const serviceVideo = `{"success": true, "data": {"id": "abc", "title": "Service Song", "webpage_url": "https://youtu.be/abc",
+	"extractor": "youtube", "extractor_key": "Youtube", "available": true,
+	"formats": [{"format_id": "251", "url": "https://stream.example/251", "ext": "webm", "format": "251", "acodec": "opus", "vcodec": "none", "abr": 130}]}}`
+
+// fakeService answers like a yt-dlp service until it is taken down, counting the requests it gets
+type fakeService struct {
+	*httptest.Server
+	down     atomic.Bool
+	requests atomic.Int32
+}
+
+func newFakeService(t *testing.T, responses map[string]string) *fakeService {
+	service := &fakeService{}
+	service.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service.requests.Add(1)
+		if service.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"success": false, "error": "service unavailable", "code": 503}`))
+			return
+		}
+		w.Write([]byte(responses[r.URL.Path]))
+	}))
+	t.Cleanup(service.Close)
+	return service
+}
+
+// newTestFailover creates a failover provider whose breaker opens after one failure and lets a request
+// through again after resetTimeout
+func newTestFailover(service *fakeService, runner *fakeRunner, resetTimeout time.Duration) *FailoverProvider {
+	client := NewResilientClientWithConfigs(nil, &CircuitBreakerConfig{
+		FailureThreshold:      1,
+		SuccessThreshold:      1,
+		Timeout:               5 * time.Second,
+		ResetTimeout:          resetTimeout,
+		MaxConcurrentRequests: 10,
+	}, &RetryConfig{})
+	client.client.baseURL = service.URL
+	return NewFailoverProvider(NewServiceProvider(client, "bestaudio"), NewCLIProviderWithRunner(nil, runner.run))
+}
+
+func TestFailoverProviderPrefersTheService(t *testing.T) {
+	service := newFakeService(t, map[string]string{"/extract": serviceVideo})
+	runner := &fakeRunner{output: rawVideoInfo}
+	provider := newTestFailover(service, runner, time.Hour)
+
+	track, err := provider.GetAudioSource(context.Background(), "https://youtu.be/abc")
+	require.NoError(t, err)
+	assert.Equal(t, "Service Song", track.Title)
+	assert.Equal(t, "https://stream.example/251", track.StreamURL)
+	assert.Equal(t, CLIProviderName, track.Provider)
+	assert.Empty(t, runner.calls, "the binary isn't run while the service works")
+	assert.True(t, provider.UsingService())
+}
+
+func TestFailoverProviderFallsBackAndFailsBack(t *testing.T) {
+	service := newFakeService(t, map[string]string{
+		"/extract": serviceVideo,
+		"/health":  `{"success": true, "data": {"status": "healthy", "version": "1.0", "uptime": "5s", "worker_count": 2, "queue_size": 0}}`,
+	})
+	runner := &fakeRunner{output: rawVideoInfo}
+	provider := newTestFailover(service, runner, 50*time.Millisecond)
+	service.down.Store(true)
+
+	track, err := provider.GetAudioSource(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.Equal(t, "Never Gonna Give You Up", track.Title, "the binary took over")
+	assert.False(t, provider.UsingService())
+	assert.Equal(t, StateOpen, provider.CircuitState())
+
+	requests := service.requests.Load()
+	provider.cli.Cache().Forget("https://youtu.be/dQw4w9WgXcQ")
+	_, err = provider.GetAudioSource(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.Equal(t, requests, service.requests.Load(), "the open breaker keeps requests away from the service")
+	assert.Len(t, runner.calls, 2)
+
+	service.down.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, provider.CheckHealth(context.Background()))
+	assert.True(t, provider.UsingService())
+	assert.Equal(t, StateClosed, provider.CircuitState())
+
+	track, err = provider.GetAudioSource(context.Background(), "https://youtu.be/abc")
+	require.NoError(t, err)
+	assert.Equal(t, "Service Song", track.Title)
+	assert.Len(t, runner.calls, 2)
+}
+
+func TestFailoverProviderKeepsVideoErrors(t *testing.T) {
+	service := newFakeService(t, map[string]string{
+		"/extract": `{"success": false, "error": "Video unavailable", "code": 404}`,
+	})
+	runner := &fakeRunner{output: rawVideoInfo}
+	provider := newTestFailover(service, runner, time.Hour)
+
+	_, err := provider.GetAudioSource(context.Background(), "https://youtu.be/gone")
+	assert.ErrorContains(t, err, "Video unavailable")
+	assert.Empty(t, runner.calls, "the binary would fail the same way")
+}
+
+func TestFailoverProviderResetCircuit(t *testing.T) {
+	service := newFakeService(t, map[string]string{"/extract": serviceVideo})
+	runner := &fakeRunner{output: rawVideoInfo}
+	provider := newTestFailover(service, runner, time.Hour)
+	service.down.Store(true)
+
+	_, err := provider.GetAudioSource(context.Background(), "https://youtu.be/abc")
+	require.NoError(t, err)
+	_, err = provider.GetAudioSource(context.Background(), "https://youtu.be/abc")
+	require.NoError(t, err)
+
+	circuit := provider.Circuit()
+	assert.Equal(t, StateOpen, circuit.State)
+	assert.Equal(t, int64(1), circuit.Opened)
+	assert.Equal(t, int64(1), circuit.Rejected, "the second request never reached the service")
+	assert.False(t, circuit.LastFailure.IsZero())
+	assert.True(t, circuit.NextAttempt.After(time.Now()))
+
+	// An operator who knows the service is back closes the breaker without waiting an hour
+	service.down.Store(false)
+	assert.Equal(t, StateOpen, provider.ResetCircuit())
+	assert.True(t, provider.UsingService())
+
+	track, err := provider.GetAudioSource(context.Background(), "https://youtu.be/abc")
+	require.NoError(t, err)
+	assert.Equal(t, "Service Song", track.Title)
+	assert.Equal(t, StateClosed, provider.Circuit().State)
+	assert.Equal(t, StateClosed, provider.ResetCircuit())
+}
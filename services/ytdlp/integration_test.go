@@ -0,0 +1,236 @@
+//go:build integration
+
+// Integration tests run real extraction, search and playlist flows through the Go client against the
+// yt-dlp service running in Docker, and check the audio sources the player would receive.
+//
+//	make test-integration
+//
+// Set YTDLP_SERVICE_URL (for example http://localhost:8080) to test an already running service instead;
+// the playlist flow then uses the local yt-dlp binary.
+package ytdlp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/autodj"
+	"pxnx-discord-bot/music/playlist"
+)
+
+const (
+	integrationImage = "pxnx-ytdlp-service:integration"
+
+	// "Me at the zoo", the first YouTube upload, is unlikely to ever disappear
+	integrationVideoID  = "jNQXAC9IVRw"
+	integrationVideoURL = "https://www.youtube.com/watch?v=" + integrationVideoID
+)
+
+var (
+	integrationClient    *Client
+	integrationContainer string // Docker container running the service, empty for an external service
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(runIntegration(m))
+}
+
+// runIntegration starts the service, runs the tests and removes the container again
+func runIntegration(m *testing.M) int {
+	config := DefaultServiceConfig()
+	config.Timeout = 2 * time.Minute
+
+	if external := os.Getenv("YTDLP_SERVICE_URL"); external != "" {
+		if err := config.UseURL(external); err != nil {
+			fmt.Fprintf(os.Stderr, "integration: %v\n", err)
+			return 1
+		}
+	} else {
+		if _, err := exec.LookPath("docker"); err != nil {
+			fmt.Println("integration: docker not found, skipping yt-dlp integration tests")
+			return 0
+		}
+
+		container, port, err := startServiceContainer()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "integration: %v\n", err)
+			return 1
+		}
+		integrationContainer = container
+		defer exec.Command("docker", "rm", "-f", container).Run()
+
+		config.Host, config.Port = "127.0.0.1", port
+	}
+
+	integrationClient = NewClient(config)
+	if err := waitForHealthy(integrationClient, 90*time.Second); err != nil {
+		fmt.Fprintf(os.Stderr, "integration: %v\n", err)
+		return 1
+	}
+	return m.Run()
+}
+
+// startServiceContainer builds the service image and runs it on a random local port
+func startServiceContainer() (string, int, error) {
+	build := exec.Command("docker", "build", "-t", integrationImage, ".")
+	if output, err := build.CombinedOutput(); err != nil {
+		return "", 0, fmt.Errorf("docker build failed: %w\n%s", err, output)
+	}
+
+	output, err := exec.Command("docker", "run", "-d", "--rm", "-p", "127.0.0.1::8080", integrationImage).Output()
+	if err != nil {
+		return "", 0, fmt.Errorf("docker run failed: %w", err)
+	}
+	container := strings.TrimSpace(string(output))
+
+	output, err = exec.Command("docker", "port", container, "8080/tcp").Output()
+	if err != nil {
+		exec.Command("docker", "rm", "-f", container).Run()
+		return "", 0, fmt.Errorf("docker port failed: %w", err)
+	}
+
+	// Output looks like "127.0.0.1:49153", one line per address family
+	mapping := strings.Fields(string(output))
+	if len(mapping) == 0 {
+		exec.Command("docker", "rm", "-f", container).Run()
+		return "", 0, fmt.Errorf("container %s has no port mapping", container)
+	}
+	port, err := strconv.Atoi(mapping[0][strings.LastIndex(mapping[0], ":")+1:])
+	if err != nil {
+		exec.Command("docker", "rm", "-f", container).Run()
+		return "", 0, fmt.Errorf("unexpected port mapping %q: %w", mapping[0], err)
+	}
+	return container, port, nil
+}
+
+// waitForHealthy polls the health endpoint until the service answers
+func waitForHealthy(client *Client, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err := client.HealthCheck(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("yt-dlp service not healthy after %v: %w", timeout, err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// runYTDLP runs the yt-dlp CLI inside the service container, or locally for an external service
+func runYTDLP(ctx context.Context, t *testing.T, args ...string) string {
+	t.Helper()
+
+	var cmd *exec.Cmd
+	if integrationContainer != "" {
+		cmd = exec.CommandContext(ctx, "docker", append([]string{"exec", integrationContainer, "yt-dlp"}, args...)...)
+	} else {
+		if _, err := exec.LookPath("yt-dlp"); err != nil {
+			t.Skip("yt-dlp not found in PATH")
+		}
+		cmd = exec.CommandContext(ctx, "yt-dlp", args...)
+	}
+
+	output, err := cmd.Output()
+	require.NoError(t, err, "yt-dlp %v", args)
+	return string(output)
+}
+
+func TestIntegrationHealthCheck(t *testing.T) {
+	health, err := integrationClient.HealthCheck(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", health.Status)
+	assert.True(t, integrationClient.IsHealthy())
+}
+
+func TestIntegrationExtractInfo(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	video, err := integrationClient.ExtractInfo(ctx, integrationVideoURL)
+	require.NoError(t, err)
+	assert.Equal(t, integrationVideoID, video.ID)
+	assert.NotEmpty(t, video.Formats)
+
+	source := video.ToAudioSource()
+	assert.Contains(t, strings.ToLower(source.Title), "zoo")
+	assert.Contains(t, source.URL, integrationVideoID)
+	assert.True(t, strings.HasPrefix(source.StreamURL, "https://"), "stream URL: %s", source.StreamURL)
+	assert.NotEmpty(t, source.Thumbnail)
+	assert.NotEmpty(t, source.Uploader)
+	assert.Equal(t, "youtube", source.Provider)
+
+	require.True(t, source.Duration.Known(), "duration %s", source.Duration)
+	assert.InDelta(t, 19, duration.Seconds(), 2)
+
+	format, ok := video.BestAudioFormat()
+	require.True(t, ok)
+	assert.Equal(t, "none", format.VCodec, "an audio-only format should be available")
+}
+
+func TestIntegrationExtractInvalidVideo(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	_, err := integrationClient.ExtractInfo(ctx, "https://www.youtube.com/watch?v=00000000000")
+	assert.Error(t, err)
+}
+
+func TestIntegrationSearch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	result, err := integrationClient.Search(ctx, "me at the zoo jawed", 3)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Videos)
+	assert.LessOrEqual(t, len(result.Videos), 3)
+	assert.Equal(t, len(result.Videos), result.TotalCount)
+
+	for _, video := range result.Videos {
+		source := video.ToAudioSource()
+		assert.NotEmpty(t, source.Title)
+		assert.True(t, strings.HasPrefix(source.URL, "https://www.youtube.com/watch?v="), "url: %s", source.URL)
+	}
+}
+
+func TestIntegrationPlaylist(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Mixes exist for every video, unlike user playlists that may be deleted
+	mixURL, ok := autodj.MixURL(integrationVideoURL)
+	require.True(t, ok)
+
+	output := runYTDLP(ctx, t,
+		"--flat-playlist",
+		"--playlist-end", "5",
+		"--print", playlist.PrintTemplate,
+		"--no-download",
+		mixURL,
+	)
+
+	entries := playlist.ParseFlatPlaylist(output, 5)
+	require.NotEmpty(t, entries)
+	assert.LessOrEqual(t, len(entries), 5)
+	for _, entry := range entries {
+		assert.NotEmpty(t, entry.Title)
+		assert.NotEmpty(t, entry.URL)
+		assert.Empty(t, entry.StreamURL, "flat entries are resolved when they play")
+	}
+
+	// The player resolves queued playlist entries right before they play
+	video, err := integrationClient.ExtractInfo(ctx, entries[0].URL)
+	require.NoError(t, err)
+	assert.NotEmpty(t, video.ToAudioSource().StreamURL)
+}
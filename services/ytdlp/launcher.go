@@ -0,0 +1,197 @@
+package ytdlp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// stopTimeout is how long a service gets to exit after SIGTERM before it is killed
+const stopTimeout = 10 * time.Second
+
+// Launcher starts the Python service for ServiceManager, as a child process or somewhere else such as a
+// sibling container. ServiceManager owns the rest of the lifecycle through the returned Process:
+//
+//   - Readiness: after Launch returns, the manager polls the service's /health with its Client until it
+//     answers, so Launch need not wait for the service to listen.
+//   - Liveness: the manager's health checks call /health and then Process.Healthy, which reports what only
+//     the launcher sees, such as Docker marking the container unhealthy. Either failing is reported on
+//     the manager's error channel.
+//   - Exits: the manager waits on Process.Wait; an exit it didn't ask for is restarted by its RestartPolicy
+//     through Launch again.
+//   - Stopping: the manager calls Process.Stop, which must not return before the service exited.
+type Launcher interface {
+	// Launch starts the service configured by config; the client reaches it at config.Address()
+	Launch(ctx context.Context, config *ServiceConfig) (Process, error)
+}
+
+// Process is a service started by a Launcher
+type Process interface {
+	// Wait blocks until the service exits and returns why. Stop may be called while it blocks.
+	Wait() error
+	// Stop asks the service to exit and forces it after timeout
+	Stop(timeout time.Duration) error
+	// Healthy returns an error when the launcher sees the service failing beyond /health, nil otherwise
+	Healthy(ctx context.Context) error
+	// String names the process for logs, such as "PID 42" or "container ytdlp"
+	String() string
+}
+
+// ProcessLauncher runs server.py as a child process in its own process group, logging to
+// <CacheDir>/logs/ytdlp-service.log. Stopping it stops the whole group, so yt-dlp and ffmpeg processes it
+// started don't outlive it, which matters when the bot is PID 1 of a container and nobody else reaps them.
+type ProcessLauncher struct {
+	Python string // Interpreter, python3 when empty
+}
+
+// Launch starts server.py
+func (l ProcessLauncher) Launch(ctx context.Context, config *ServiceConfig) (Process, error) {
+	python := l.Python
+	if python == "" {
+		python = "python3"
+	}
+	serverPath, err := serverScriptPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate server script: %w", err)
+	}
+	logFile, err := openServiceLog(config.CacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("logging setup failed: %w", err)
+	}
+
+	args := []string{
+		serverPath,
+		"--host", config.Host,
+		"--port", fmt.Sprintf("%d", config.Port),
+		"--workers", fmt.Sprintf("%d", config.MaxWorkers),
+	}
+	if config.Socket != "" {
+		args = append(args, "--socket", config.Socket)
+	}
+	log.Printf("[SERVICE] Command: %s %v", python, args)
+
+	// The process outlives the context that started it; Stop ends it
+	cmd := exec.CommandContext(context.WithoutCancel(ctx), python, args...)
+	// Keep PATH so a Python installed by a version manager is found
+	cmd.Env = append(os.Environ(),
+		"PYTHONUNBUFFERED=1",
+		"PYTHONPATH="+filepath.Dir(serverPath),
+	)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+
+	process, err := startProcessGroup(cmd)
+	if err != nil {
+		logFile.Close()
+		return nil, err
+	}
+	process.logFile = logFile
+	return process, nil
+}
+
+// serverScriptPath returns the path to the Python server script
+func serverScriptPath() (string, error) {
+	// Try to find the script relative to the working directory
+	possiblePaths := []string{
+		"services/ytdlp/server.py",
+		"../services/ytdlp/server.py",
+		"../../services/ytdlp/server.py",
+	}
+	for _, path := range possiblePaths {
+		if absPath, err := filepath.Abs(path); err == nil {
+			if _, err := os.Stat(absPath); err == nil {
+				return absPath, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("server.py script not found in expected locations")
+}
+
+// openServiceLog opens the service's log file under cacheDir
+func openServiceLog(cacheDir string) (*os.File, error) {
+	logDir := filepath.Join(cacheDir, "logs")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logFile, err := os.OpenFile(filepath.Join(logDir, "ytdlp-service.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return logFile, nil
+}
+
+// groupProcess is a child process leading its own process group
+type groupProcess struct {
+	cmd     *exec.Cmd
+	logFile *os.File // Closed once the process exited, nil when it logs elsewhere
+
+	exited  chan struct{}
+	waitErr error
+	once    sync.Once
+}
+
+// startProcessGroup starts cmd as the leader of a new process group
+func startProcessGroup(cmd *exec.Cmd) (*groupProcess, error) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start yt-dlp service: %w", err)
+	}
+	log.Printf("[SERVICE] Python process started with PID: %d", cmd.Process.Pid)
+	return &groupProcess{cmd: cmd, exited: make(chan struct{})}, nil
+}
+
+// Wait blocks until the process exits
+func (p *groupProcess) Wait() error {
+	p.once.Do(func() {
+		p.waitErr = p.cmd.Wait()
+		if p.logFile != nil {
+			p.logFile.Close()
+		}
+		close(p.exited)
+	})
+	<-p.exited
+	return p.waitErr
+}
+
+// Stop sends SIGTERM to the process group and SIGKILL after timeout
+func (p *groupProcess) Stop(timeout time.Duration) error {
+	// Wait may not have been called, and only it notices the exit
+	utils.SafeGo("ytdlp.waitForExit", func() { p.Wait() })
+
+	// The group ID is the leader's PID, negated to signal the whole group
+	pgid := -p.cmd.Process.Pid
+	if err := syscall.Kill(pgid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		log.Printf("[SERVICE] Failed to send SIGTERM to the service: %v", err)
+	}
+
+	select {
+	case <-p.exited:
+		// Children that ignored SIGTERM go with their parent
+		syscall.Kill(pgid, syscall.SIGKILL)
+		return nil
+	case <-time.After(timeout):
+	}
+	if err := syscall.Kill(pgid, syscall.SIGKILL); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("failed to kill the service: %w", err)
+	}
+	<-p.exited
+	return nil
+}
+
+// Healthy has nothing to add to /health for a local process
+func (p *groupProcess) Healthy(context.Context) error {
+	return nil
+}
+
+func (p *groupProcess) String() string {
+	return fmt.Sprintf("PID %d", p.cmd.Process.Pid)
+}
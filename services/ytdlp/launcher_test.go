@@ -0,0 +1,202 @@
+package ytdlp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// running reports whether a process runs, counting zombies waiting to be reaped as exited
+func running(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	return err != nil || !strings.Contains(string(stat), ") Z ")
+}
+
+func TestProcessGroupStopKillsChildren(t *testing.T) {
+	// The shell ignores SIGTERM, so only the kill after the timeout ends it
+	cmd := exec.Command("sh", "-c", `trap "" TERM; sleep 60 & echo $!; wait`)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	process, err := startProcessGroup(cmd)
+	require.NoError(t, err)
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	child, err := strconv.Atoi(strings.TrimSpace(line))
+	require.NoError(t, err)
+	require.True(t, running(child))
+
+	started := time.Now()
+	require.NoError(t, process.Stop(200*time.Millisecond))
+	assert.GreaterOrEqual(t, time.Since(started), 200*time.Millisecond, "SIGKILL waits for the timeout")
+	assert.Eventually(t, func() bool { return !running(child) }, 2*time.Second, 20*time.Millisecond,
+		"processes started by the service stop with it")
+	assert.Error(t, process.Wait(), "the process was killed")
+}
+
+// fakeDocker serves the parts of the Docker Engine API ContainerLauncher uses on a unix socket
+type fakeDocker struct {
+	socket  string
+	running atomic.Bool
+	health  atomic.Value // Health status of the container's HEALTHCHECK
+	stopped chan struct{}
+}
+
+func newFakeDocker(t *testing.T) *fakeDocker {
+	dir, err := os.MkdirTemp("", "docker")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	docker := &fakeDocker{socket: filepath.Join(dir, "docker.sock"), stopped: make(chan struct{})}
+	docker.health.Store("healthy")
+	listener, err := net.Listen("unix", docker.socket)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /containers/ytdlp/start", func(w http.ResponseWriter, r *http.Request) {
+		if docker.running.Swap(true) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /containers/ytdlp/stop", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "3", r.URL.Query().Get("t"))
+		if docker.running.Swap(false) {
+			close(docker.stopped)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /containers/ytdlp/wait", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "not-running", r.URL.Query().Get("condition"))
+		<-docker.stopped
+		fmt.Fprint(w, `{"StatusCode":143}`)
+	})
+	mux.HandleFunc("GET /containers/ytdlp/json", func(w http.ResponseWriter, r *http.Request) {
+		status := "exited"
+		if docker.running.Load() {
+			status = "running"
+		}
+		fmt.Fprintf(w, `{"State":{"Status":%q,"Running":%t,"Health":{"Status":%q,"Log":[{"Output":"curl: connection refused\n"}]}}}`,
+			status, docker.running.Load(), docker.health.Load())
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"message":"No such container: %s"}`, r.URL.Path)
+	})
+
+	server := httptest.NewUnstartedServer(mux)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return docker
+}
+
+func TestContainerLauncher(t *testing.T) {
+	docker := newFakeDocker(t)
+
+	process, err := ContainerLauncher{Container: "ytdlp", Socket: docker.socket}.Launch(t.Context(), DefaultServiceConfig())
+	require.NoError(t, err)
+	assert.True(t, docker.running.Load())
+	assert.Equal(t, "container ytdlp", process.String())
+	assert.NoError(t, process.Healthy(t.Context()))
+
+	docker.health.Store("unhealthy")
+	assert.EqualError(t, process.Healthy(t.Context()), "container ytdlp is unhealthy: curl: connection refused")
+
+	waited := make(chan error, 1)
+	go func() { waited <- process.Wait() }()
+	require.NoError(t, process.Stop(3*time.Second))
+	assert.EqualError(t, <-waited, "container ytdlp exited with status 143")
+	assert.EqualError(t, process.Healthy(t.Context()), "container ytdlp is exited")
+
+	_, err = ContainerLauncher{Container: "missing", Socket: docker.socket}.Launch(t.Context(), DefaultServiceConfig())
+	assert.ErrorContains(t, err, "No such container")
+}
+
+// fakeProcess is a service launched by fakeLauncher
+type fakeProcess struct {
+	exit    chan error
+	stopped atomic.Bool
+	healthy atomic.Pointer[error]
+}
+
+func (p *fakeProcess) Wait() error { return <-p.exit }
+
+func (p *fakeProcess) Stop(time.Duration) error {
+	p.stopped.Store(true)
+	p.exit <- nil
+	return nil
+}
+
+func (p *fakeProcess) Healthy(context.Context) error {
+	if err := p.healthy.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func (p *fakeProcess) String() string { return "fake" }
+
+// fakeLauncher hands out one process
+type fakeLauncher struct{ process *fakeProcess }
+
+func (l fakeLauncher) Launch(context.Context, *ServiceConfig) (Process, error) { return l.process, nil }
+
+// managerWithLauncher creates a manager whose service is launched by a fakeLauncher and answers /health
+func managerWithLauncher(t *testing.T) (*ServiceManager, *fakeProcess) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success": true, "data": {"status": "healthy", "worker_count": 2, "queue_size": 0}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	config := DefaultServiceConfig()
+	config.HealthCheckInterval = 20 * time.Millisecond
+	manager := NewServiceManager(config)
+	manager.client.baseURL = server.URL
+	manager.SetRestartPolicy(RestartPolicy{})
+
+	process := &fakeProcess{exit: make(chan error, 1)}
+	manager.SetLauncher(fakeLauncher{process: process})
+	require.NoError(t, manager.Start(t.Context()))
+	assert.True(t, manager.IsRunning())
+	return manager, process
+}
+
+func TestServiceManagerChecksLauncherHealth(t *testing.T) {
+	manager, process := managerWithLauncher(t)
+
+	unhealthy := errors.New("container ytdlp is unhealthy")
+	process.healthy.Store(&unhealthy)
+	assert.ErrorContains(t, receiveError(t, manager), "health check failed: container ytdlp is unhealthy")
+
+	require.NoError(t, manager.Stop(t.Context()))
+	assert.True(t, process.stopped.Load())
+}
+
+func TestServiceManagerNoticesLaunchedProcessExit(t *testing.T) {
+	manager, process := managerWithLauncher(t)
+
+	process.exit <- errors.New("exit status 1")
+	assert.ErrorContains(t, receiveError(t, manager), "service process exited unexpectedly: exit status 1")
+	assert.Equal(t, StatusError, manager.GetStatus())
+}
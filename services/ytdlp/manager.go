@@ -2,28 +2,43 @@ package ytdlp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"sync"
 	"sync/atomic"
-	"syscall"
 	"time"
+
+	"pxnx-discord-bot/utils"
 )
 
 // ServiceManager manages the lifecycle of the yt-dlp service
 type ServiceManager struct {
 	config       *ServiceConfig
 	client       *Client
-	cmd          *exec.Cmd
+	launcher     Launcher // Runs the Python service, nil for a local python3 process or the Go server
+	process      Process  // The running Python service, nil while stopped or with the Go server
 	status       int32 // Use atomic for thread-safe status updates
 	mu           sync.RWMutex
 	healthTicker *time.Ticker
 	stopChan     chan struct{}
 	errorChan    chan error
-	logFile      *os.File
+
+	// Set while the Go server stands in for server.py
+	embeddedCancel context.CancelFunc
+	embeddedDone   chan struct{}
+
+	// Keeps yt-dlp current, nil without automatic updates
+	updater        *Updater
+	idleCheck      func() bool // Reports whether the bot has no use for the service right now
+	restartPending atomic.Bool // An update is installed but the service still runs the old version
+
+	// Restarts after the service exits on its own
+	restartPolicy RestartPolicy
+	restarts      int       // Restarts in a row since the service last ran for restartPolicy.ResetAfter
+	startedAt     time.Time // When the service last started successfully
 }
 
 // NewServiceManager creates a new service manager
@@ -40,6 +55,8 @@ func NewServiceManager(config *ServiceConfig) *ServiceManager {
 		status:    int32(StatusStopped),
 		stopChan:  make(chan struct{}),
 		errorChan: make(chan error, 10),
+
+		restartPolicy: DefaultRestartPolicy(),
 	}
 }
 
@@ -59,80 +76,41 @@ func (sm *ServiceManager) Start(ctx context.Context) error {
 	atomic.StoreInt32(&sm.status, int32(StatusStarting))
 	log.Printf("[SERVICE] Status set to starting")
 
-	// Check if Python is available
-	log.Printf("[SERVICE] Checking Python availability...")
-	if err := sm.checkPythonAvailability(); err != nil {
-		log.Printf("[SERVICE] Python check failed: %v", err)
-		atomic.StoreInt32(&sm.status, int32(StatusError))
-		return fmt.Errorf("python check failed: %w", err)
-	}
-	log.Printf("[SERVICE] Python check passed")
-
-	// Check if yt-dlp is available
-	log.Printf("[SERVICE] Checking yt-dlp availability...")
-	if err := sm.checkYTDLPAvailability(); err != nil {
-		log.Printf("[SERVICE] yt-dlp check failed: %v", err)
-		atomic.StoreInt32(&sm.status, int32(StatusError))
-		return fmt.Errorf("yt-dlp check failed: %w", err)
-	}
-	log.Printf("[SERVICE] yt-dlp check passed")
-
-	// Setup logging
-	log.Printf("[SERVICE] Setting up logging...")
-	if err := sm.setupLogging(); err != nil {
-		log.Printf("[SERVICE] Logging setup failed: %v", err)
-		atomic.StoreInt32(&sm.status, int32(StatusError))
-		return fmt.Errorf("logging setup failed: %w", err)
-	}
-	log.Printf("[SERVICE] Logging setup complete")
-
-	// Get the path to the Python server script
-	log.Printf("[SERVICE] Locating server script...")
-	serverPath, err := sm.getServerScriptPath()
-	if err != nil {
-		log.Printf("[SERVICE] Failed to locate server script: %v", err)
-		atomic.StoreInt32(&sm.status, int32(StatusError))
-		return fmt.Errorf("failed to locate server script: %w", err)
-	}
-	log.Printf("[SERVICE] Server script found at: %s", serverPath)
-
-	// Prepare command arguments
-	args := []string{
-		serverPath,
-		"--host", sm.config.Host,
-		"--port", fmt.Sprintf("%d", sm.config.Port),
-		"--workers", fmt.Sprintf("%d", sm.config.MaxWorkers),
-	}
-	log.Printf("[SERVICE] Command: python3 %v", args)
-
-	// Create the command
-	sm.cmd = exec.CommandContext(ctx, "python3", args...)
-
-	// Set up environment - preserve current PATH to ensure mise Python is available
-	sm.cmd.Env = append(os.Environ(),
-		"PYTHONUNBUFFERED=1",
-		"PYTHONPATH="+filepath.Dir(serverPath),
-	)
-
-	// Set up stdout/stderr redirection
-	if sm.logFile != nil {
-		sm.cmd.Stdout = sm.logFile
-		sm.cmd.Stderr = sm.logFile
-	}
+	// Stop closed the previous channel, a restarted service needs a new one. Starting loads the
+	// installed yt-dlp, so no update waits for a restart anymore.
+	sm.stopChan = make(chan struct{})
+	sm.restartPending.Store(false)
+
+	launcher := sm.launcher
+	if launcher == nil {
+		// Without Python or its yt-dlp module, the Go server provides the same API using the yt-dlp binary
+		if err := sm.checkPythonRuntime(); err != nil {
+			log.Printf("[SERVICE] Python service unavailable: %v", err)
+			if err := sm.startEmbedded(ctx); err != nil {
+				log.Printf("[SERVICE] Go server failed to start: %v", err)
+				atomic.StoreInt32(&sm.status, int32(StatusError))
+				return err
+			}
 
-	// Set process group for proper cleanup
-	sm.cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
+			atomic.StoreInt32(&sm.status, int32(StatusRunning))
+			sm.startedAt = time.Now()
+			sm.startHealthChecks()
+			log.Printf("[SERVICE] Service startup complete (Go server)")
+			return nil
+		}
+		launcher = ProcessLauncher{}
 	}
 
 	// Start the service
-	log.Printf("[SERVICE] Starting Python process...")
-	if err := sm.cmd.Start(); err != nil {
-		log.Printf("[SERVICE] Failed to start process: %v", err)
+	log.Printf("[SERVICE] Launching the service...")
+	process, err := launcher.Launch(ctx, sm.config)
+	if err != nil {
+		log.Printf("[SERVICE] Failed to launch the service: %v", err)
 		atomic.StoreInt32(&sm.status, int32(StatusError))
 		return fmt.Errorf("failed to start yt-dlp service: %w", err)
 	}
-	log.Printf("[SERVICE] Python process started with PID: %d", sm.cmd.Process.Pid)
+	sm.process = process
+	log.Printf("[SERVICE] Service launched as %s", process)
 
 	// Wait for the service to be ready
 	log.Printf("[SERVICE] Waiting for service to become ready...")
@@ -145,9 +123,10 @@ func (sm *ServiceManager) Start(ctx context.Context) error {
 	log.Printf("[SERVICE] Service is ready!")
 
 	atomic.StoreInt32(&sm.status, int32(StatusRunning))
+	sm.startedAt = time.Now()
 
 	// Start monitoring
-	go sm.monitorService()
+	utils.SafeGo("ytdlp.monitorService", func() { sm.monitorService(process) })
 	sm.startHealthChecks()
 
 	log.Printf("[SERVICE] Service startup complete")
@@ -178,12 +157,6 @@ func (sm *ServiceManager) Stop(ctx context.Context) error {
 		return fmt.Errorf("failed to stop service process: %w", err)
 	}
 
-	// Close log file
-	if sm.logFile != nil {
-		sm.logFile.Close()
-		sm.logFile = nil
-	}
-
 	// Close client
 	if err := sm.client.Close(); err != nil {
 		return fmt.Errorf("failed to close client: %w", err)
@@ -193,6 +166,14 @@ func (sm *ServiceManager) Stop(ctx context.Context) error {
 	return nil
 }
 
+// SetLauncher changes how the Python service is run from its next start, such as ContainerLauncher for a
+// sibling container. nil runs python3 locally, or the Go server without Python.
+func (sm *ServiceManager) SetLauncher(launcher Launcher) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.launcher = launcher
+}
+
 // Restart restarts the yt-dlp service
 func (sm *ServiceManager) Restart(ctx context.Context) error {
 	if err := sm.Stop(ctx); err != nil {
@@ -205,6 +186,72 @@ func (sm *ServiceManager) Restart(ctx context.Context) error {
 	return sm.Start(ctx)
 }
 
+// UseUpdater enables AutoUpdate. idle, which may be nil, reports whether the bot has no use for the
+// service right now; the service's own queue must be empty too before it restarts.
+func (sm *ServiceManager) UseUpdater(updater *Updater, idle func() bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.updater = updater
+	sm.idleCheck = idle
+}
+
+// AutoUpdate installs a newer yt-dlp release and restarts the service to load it once it is idle.
+// A restart that had to wait is tried again on the next call, so schedule this to run regularly.
+func (sm *ServiceManager) AutoUpdate(ctx context.Context) (UpdateCheck, error) {
+	sm.mu.RLock()
+	updater := sm.updater
+	sm.mu.RUnlock()
+	if updater == nil {
+		return UpdateCheck{}, fmt.Errorf("automatic yt-dlp updates are not enabled")
+	}
+
+	check, err := updater.Run(ctx)
+	if err != nil {
+		return check, err
+	}
+	if check.Updated {
+		log.Printf("[SERVICE] Updated yt-dlp to %s", check.Installed)
+		// A stopped service loads the update when it starts
+		sm.restartPending.Store(sm.IsRunning())
+	}
+
+	if !sm.restartPending.Load() || !sm.IsRunning() {
+		return check, nil
+	}
+	if !sm.isIdle(ctx) {
+		log.Printf("[SERVICE] Restart for the yt-dlp update waits until the service is idle")
+		return check, nil
+	}
+
+	// The process outlives this call, so it must not stop with ctx
+	if err := sm.Restart(context.WithoutCancel(ctx)); err != nil {
+		return check, fmt.Errorf("failed to restart after the yt-dlp update: %w", err)
+	}
+	sm.restartPending.Store(false)
+	log.Printf("[SERVICE] Restarted with yt-dlp %s", check.Installed)
+	return check, nil
+}
+
+// RestartPending reports whether an installed yt-dlp update waits for the service to restart
+func (sm *ServiceManager) RestartPending() bool {
+	return sm.restartPending.Load()
+}
+
+// isIdle reports whether the service can restart without cutting off requests
+func (sm *ServiceManager) isIdle(ctx context.Context) bool {
+	sm.mu.RLock()
+	idle := sm.idleCheck
+	sm.mu.RUnlock()
+	if idle != nil && !idle() {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	health, err := sm.client.HealthCheck(ctx)
+	return err == nil && health.QueueSize == 0
+}
+
 // GetStatus returns the current service status
 func (sm *ServiceManager) GetStatus() ServiceStatus {
 	return ServiceStatus(atomic.LoadInt32(&sm.status))
@@ -225,6 +272,80 @@ func (sm *ServiceManager) GetErrors() <-chan error {
 	return sm.errorChan
 }
 
+// checkPythonRuntime checks that Python 3 and its yt-dlp module are available for server.py
+func (sm *ServiceManager) checkPythonRuntime() error {
+	log.Printf("[SERVICE] Checking Python availability...")
+	if err := sm.checkPythonAvailability(); err != nil {
+		return fmt.Errorf("python check failed: %w", err)
+	}
+	log.Printf("[SERVICE] Python check passed")
+
+	log.Printf("[SERVICE] Checking yt-dlp availability...")
+	if err := sm.checkYTDLPAvailability(); err != nil {
+		return fmt.Errorf("yt-dlp check failed: %w", err)
+	}
+	log.Printf("[SERVICE] yt-dlp check passed")
+	return nil
+}
+
+// startEmbedded serves the API in-process with the Go server, which needs only the yt-dlp binary
+func (sm *ServiceManager) startEmbedded(ctx context.Context) error {
+	binaryPath, err := exec.LookPath(sm.config.BinaryPath)
+	if err != nil {
+		return fmt.Errorf("neither the Python service nor the %s binary is available: %w", sm.config.BinaryPath, err)
+	}
+	log.Printf("[SERVICE] Starting Go server with %s", binaryPath)
+
+	config := *sm.config
+	config.BinaryPath = binaryPath
+	server := NewServer(&config)
+
+	serverCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	sm.embeddedCancel = cancel
+	sm.embeddedDone = done
+
+	utils.SafeGo("ytdlp.embeddedServer", func() {
+		defer close(done)
+		err := server.ListenAndServe(serverCtx)
+		if err == nil {
+			err = fmt.Errorf("server stopped")
+		}
+
+		// Report an exit the manager did not ask for, like monitorService does for the Python process
+		if serverCtx.Err() == nil && atomic.LoadInt32(&sm.status) == int32(StatusRunning) {
+			atomic.StoreInt32(&sm.status, int32(StatusError))
+			sm.crashed(fmt.Errorf("go server exited unexpectedly: %w", err))
+		} else if serverCtx.Err() == nil {
+			log.Printf("[SERVICE] Go server exited: %v", err)
+		}
+	})
+
+	log.Printf("[SERVICE] Waiting for service to become ready...")
+	if err := sm.waitForService(ctx); err != nil {
+		sm.stopEmbedded()
+		return fmt.Errorf("service failed to become ready: %w", err)
+	}
+	log.Printf("[SERVICE] Service is ready!")
+	return nil
+}
+
+// stopEmbedded shuts down the Go server if it is running
+func (sm *ServiceManager) stopEmbedded() {
+	if sm.embeddedCancel == nil {
+		return
+	}
+
+	sm.embeddedCancel()
+	select {
+	case <-sm.embeddedDone:
+	case <-time.After(15 * time.Second):
+		log.Printf("[SERVICE] Timed out waiting for the Go server to stop")
+	}
+	sm.embeddedCancel = nil
+	sm.embeddedDone = nil
+}
+
 // checkPythonAvailability checks if Python 3 is available
 func (sm *ServiceManager) checkPythonAvailability() error {
 	cmd := exec.Command("python3", "--version")
@@ -245,46 +366,6 @@ func (sm *ServiceManager) checkYTDLPAvailability() error {
 	return nil
 }
 
-// getServerScriptPath returns the path to the Python server script
-func (sm *ServiceManager) getServerScriptPath() (string, error) {
-	// Try to find the script relative to the current executable or working directory
-	possiblePaths := []string{
-		"services/ytdlp/server.py",
-		"./services/ytdlp/server.py",
-		"../services/ytdlp/server.py",
-		"../../services/ytdlp/server.py",
-	}
-
-	for _, path := range possiblePaths {
-		if absPath, err := filepath.Abs(path); err == nil {
-			if _, err := os.Stat(absPath); err == nil {
-				return absPath, nil
-			}
-		}
-	}
-
-	return "", fmt.Errorf("server.py script not found in expected locations")
-}
-
-// setupLogging sets up logging for the service
-func (sm *ServiceManager) setupLogging() error {
-	// Create logs directory if it doesn't exist
-	logDir := filepath.Join(sm.config.CacheDir, "logs")
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("failed to create log directory: %w", err)
-	}
-
-	// Open log file
-	logPath := filepath.Join(logDir, "ytdlp-service.log")
-	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
-	}
-
-	sm.logFile = logFile
-	return nil
-}
-
 // waitForService waits for the service to become ready
 func (sm *ServiceManager) waitForService(ctx context.Context) error {
 	timeout := 30 * time.Second
@@ -310,54 +391,27 @@ func (sm *ServiceManager) waitForService(ctx context.Context) error {
 
 // stopProcess stops the service process
 func (sm *ServiceManager) stopProcess() error {
-	if sm.cmd == nil || sm.cmd.Process == nil {
-		return nil
-	}
-
-	// Try graceful shutdown first
-	if err := sm.cmd.Process.Signal(syscall.SIGTERM); err != nil {
-		// If graceful shutdown fails, force kill
-		if killErr := sm.cmd.Process.Kill(); killErr != nil {
-			return fmt.Errorf("failed to kill process: %w", killErr)
-		}
-	}
+	sm.stopEmbedded()
 
-	// Wait for process to exit
-	done := make(chan error, 1)
-	go func() {
-		done <- sm.cmd.Wait()
-	}()
-
-	select {
-	case <-done:
-		return nil
-	case <-time.After(10 * time.Second):
-		// Force kill if process doesn't exit gracefully
-		if err := sm.cmd.Process.Kill(); err != nil {
-			return fmt.Errorf("failed to force kill process: %w", err)
-		}
+	if sm.process == nil {
 		return nil
 	}
+	err := sm.process.Stop(stopTimeout)
+	sm.process = nil
+	return err
 }
 
-// monitorService monitors the service process
-func (sm *ServiceManager) monitorService() {
-	if sm.cmd == nil {
-		return
+// monitorService reports the service exiting while it should be running
+func (sm *ServiceManager) monitorService(process Process) {
+	err := process.Wait()
+	if err == nil {
+		err = errors.New("exit status 0")
 	}
 
-	err := sm.cmd.Wait()
-
 	// Process has exited
 	if atomic.LoadInt32(&sm.status) == int32(StatusRunning) {
 		atomic.StoreInt32(&sm.status, int32(StatusError))
-
-		// Send error to error channel
-		select {
-		case sm.errorChan <- fmt.Errorf("service process exited unexpectedly: %w", err):
-		default:
-			// Channel is full, skip
-		}
+		sm.crashed(fmt.Errorf("service process exited unexpectedly: %w", err))
 	}
 }
 
@@ -369,22 +423,23 @@ func (sm *ServiceManager) startHealthChecks() {
 
 	sm.healthTicker = time.NewTicker(sm.config.HealthCheckInterval)
 
-	go func() {
+	ticker := sm.healthTicker
+	process := sm.process
+	utils.SafeGoWithRestart("ytdlp.healthChecks", utils.RestartPolicy{MaxRestarts: 5, Backoff: sm.config.HealthCheckInterval}, func() {
 		for {
 			select {
-			case <-sm.healthTicker.C:
+			case <-ticker.C:
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				_, err := sm.client.HealthCheck(ctx)
+				if err == nil && process != nil {
+					err = process.Healthy(ctx)
+				}
 				cancel()
 
 				if err != nil {
 					// Health check failed
 					if atomic.LoadInt32(&sm.status) == int32(StatusRunning) {
-						select {
-						case sm.errorChan <- fmt.Errorf("health check failed: %w", err):
-						default:
-							// Channel is full, skip
-						}
+						sm.notify(fmt.Errorf("health check failed: %w", err))
 					}
 				}
 
@@ -392,7 +447,7 @@ func (sm *ServiceManager) startHealthChecks() {
 				return
 			}
 		}
-	}()
+	})
 }
 
 // stopHealthChecks stops periodic health checks
@@ -0,0 +1,301 @@
+package ytdlp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/music/types"
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultMetadataCachePath is where extracted tracks are saved when YTDLP_CACHE_FILE is not set
+const DefaultMetadataCachePath = "data/ytdlp-cache.json"
+
+// metadataCacheSize bounds how many tracks the cache keeps
+const metadataCacheSize = 1000
+
+// streamExpiryMargin is how long before its stream URL expires a cached track is extracted again, so a
+// track that starts playing still has time to finish
+const streamExpiryMargin = 30 * time.Minute
+
+// CacheStats describes the metadata cache for /admin cache
+type CacheStats struct {
+	Entries  int           // Tracks cached
+	Queries  int           // Search queries that map to a cached track
+	Capacity int           // Most tracks kept
+	Hits     int64         // Lookups answered from the cache since the bot started
+	Misses   int64         // Lookups that needed an extraction since the bot started
+	TTL      time.Duration // Longest a track is kept, shorter when its stream URL expires sooner
+	Path     string        // File the cache is saved to, empty when it only lives in memory
+}
+
+// cachedTrack is an extracted track and when it must be extracted again
+type cachedTrack struct {
+	Track   types.AudioSource `json:"track"`
+	Expires time.Time         `json:"expires"`
+}
+
+// cacheFile is the saved form of the cache
+type cacheFile struct {
+	Tracks  map[string]cachedTrack `json:"tracks"`  // By video ID
+	Queries map[string]string      `json:"queries"` // Normalized search query -> video ID
+}
+
+// MetadataCache keeps extracted YouTube tracks by video ID, in memory and optionally in a JSON file, so
+// playing a popular track again skips yt-dlp. Search queries are remembered with the video they found.
+// Entries live for the TTL but never past their stream URL's expiry.
+type MetadataCache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	tracks  map[string]cachedTrack
+	queries map[string]string
+	hits    int64
+	misses  int64
+}
+
+// NewMetadataCache creates a cache that only lives in memory; a ttl of 0 disables it
+func NewMetadataCache(ttl time.Duration) *MetadataCache {
+	return &MetadataCache{
+		ttl:     max(0, ttl),
+		now:     time.Now,
+		tracks:  make(map[string]cachedTrack),
+		queries: make(map[string]string),
+	}
+}
+
+// LoadMetadataCache opens the cache file at path, dropping expired entries; a missing file starts empty
+func LoadMetadataCache(path string, ttl time.Duration) (*MetadataCache, error) {
+	cache := NewMetadataCache(ttl)
+	cache.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read yt-dlp cache: %w", err)
+	}
+
+	var saved cacheFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse yt-dlp cache %s: %w", path, err)
+	}
+	for videoID, entry := range saved.Tracks {
+		cache.tracks[videoID] = entry
+	}
+	for query, videoID := range saved.Queries {
+		cache.queries[query] = videoID
+	}
+	cache.dropExpired(cache.now())
+	return cache, nil
+}
+
+// Get returns the cached track for a YouTube link or a search query that was extracted before
+func (c *MetadataCache) Get(query string) (*types.AudioSource, bool) {
+	if c.ttl == 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	videoID, ok := c.videoIDFor(query)
+	entry, cached := c.tracks[videoID]
+	if !ok || !cached || !c.now().Before(entry.Expires) {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	track := entry.Track
+	return &track, true
+}
+
+// Add caches a track extracted for a query. Tracks that aren't YouTube videos and live streams are
+// skipped, and so are tracks whose stream URL expires too soon to be worth keeping.
+func (c *MetadataCache) Add(query string, track types.AudioSource) {
+	videoID, ok := VideoID(track.URL)
+	if c.ttl == 0 || !ok || track.Live || track.StreamURL == "" {
+		return
+	}
+
+	now := c.now()
+	expires := now.Add(c.ttl)
+	if streamExpires, ok := StreamExpiry(track.StreamURL); ok && streamExpires.Add(-streamExpiryMargin).Before(expires) {
+		expires = streamExpires.Add(-streamExpiryMargin)
+	}
+	if !now.Before(expires) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.tracks[videoID]; !exists && len(c.tracks) >= metadataCacheSize {
+		c.dropExpired(now)
+		if len(c.tracks) >= metadataCacheSize {
+			c.evictSoonest()
+		}
+	}
+	c.tracks[videoID] = cachedTrack{Track: track, Expires: expires}
+	if _, isLink := VideoID(query); !isLink {
+		if key := normalizeQuery(query); key != "" {
+			c.queries[key] = videoID
+		}
+	}
+	c.saveLocked()
+}
+
+// Forget drops the cached track of a link or query, for when its stream URL stopped working
+func (c *MetadataCache) Forget(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	videoID, ok := c.videoIDFor(query)
+	if _, cached := c.tracks[videoID]; !ok || !cached {
+		return
+	}
+	delete(c.tracks, videoID)
+	c.saveLocked()
+}
+
+// Purge empties the cache and its file
+func (c *MetadataCache) Purge() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tracks = make(map[string]cachedTrack)
+	c.queries = make(map[string]string)
+	return c.save()
+}
+
+// Stats returns the cache's size and hit counts
+func (c *MetadataCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Entries:  len(c.tracks),
+		Queries:  len(c.queries),
+		Capacity: metadataCacheSize,
+		Hits:     c.hits,
+		Misses:   c.misses,
+		TTL:      c.ttl,
+		Path:     c.path,
+	}
+}
+
+// videoIDFor finds the video a link points to or a search query found (caller holds the lock)
+func (c *MetadataCache) videoIDFor(query string) (string, bool) {
+	if videoID, ok := VideoID(query); ok {
+		return videoID, true
+	}
+	videoID, ok := c.queries[normalizeQuery(query)]
+	return videoID, ok
+}
+
+// dropExpired removes expired tracks and the queries pointing to them (caller holds the lock)
+func (c *MetadataCache) dropExpired(now time.Time) {
+	for videoID, entry := range c.tracks {
+		if !now.Before(entry.Expires) {
+			delete(c.tracks, videoID)
+		}
+	}
+	for query, videoID := range c.queries {
+		if _, exists := c.tracks[videoID]; !exists {
+			delete(c.queries, query)
+		}
+	}
+}
+
+// evictSoonest removes the track that would expire first (caller holds the lock)
+func (c *MetadataCache) evictSoonest() {
+	var oldestID string
+	var oldest time.Time
+	for videoID, entry := range c.tracks {
+		if oldestID == "" || entry.Expires.Before(oldest) {
+			oldestID, oldest = videoID, entry.Expires
+		}
+	}
+	delete(c.tracks, oldestID)
+	for query, videoID := range c.queries {
+		if videoID == oldestID {
+			delete(c.queries, query)
+		}
+	}
+}
+
+// saveLocked saves the cache, logging failures since a lost cache only costs extractions (caller holds the lock)
+func (c *MetadataCache) saveLocked() {
+	if err := c.save(); err != nil {
+		utils.LogWarn("yt-dlp cache not saved: %v", err)
+	}
+}
+
+// save writes the cached tracks and queries, if the cache has a file (caller holds the lock)
+func (c *MetadataCache) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(cacheFile{Tracks: c.tracks, Queries: c.queries})
+	if err != nil {
+		return fmt.Errorf("failed to encode yt-dlp cache: %w", err)
+	}
+	if err := utils.WriteFileAtomic(c.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save yt-dlp cache: %w", err)
+	}
+	return nil
+}
+
+// VideoID returns the ID of the YouTube video a watch, short or youtu.be link points to
+func VideoID(rawURL string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		return "", false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	host = strings.TrimPrefix(host, "music.")
+
+	videoID := ""
+	switch host {
+	case "youtube.com":
+		if rest, ok := strings.CutPrefix(parsed.Path, "/shorts/"); ok {
+			videoID = strings.Trim(rest, "/")
+		} else if parsed.Path == "/watch" {
+			videoID = parsed.Query().Get("v")
+		}
+	case "youtu.be":
+		videoID = strings.Trim(parsed.Path, "/")
+	}
+	return videoID, videoID != ""
+}
+
+// StreamExpiry reads when a googlevideo stream URL stops working from its expire parameter
+func StreamExpiry(streamURL string) (time.Time, bool) {
+	parsed, err := url.Parse(streamURL)
+	if err != nil {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(parsed.Query().Get("expire"), 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+// normalizeQuery makes searches that differ only in case and spacing share an entry
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
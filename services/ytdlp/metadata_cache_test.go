@@ -0,0 +1,181 @@
+package ytdlp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pxnx-discord-bot/music/types"
+)
+
+// cacheTestTrack is an extracted YouTube track whose stream URL expires at expire
+func cacheTestTrack(id string, expire time.Time) types.AudioSource {
+	return types.AudioSource{
+		Title:     "Song " + id,
+		URL:       "https://www.youtube.com/watch?v=" + id,
+		StreamURL: fmt.Sprintf("https://rr1---sn-example.googlevideo.com/videoplayback?expire=%d&itag=251", expire.Unix()),
+		Provider:  CLIProviderName,
+	}
+}
+
+func TestMetadataCacheLinksAndQueries(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewMetadataCache(time.Hour)
+	cache.now = func() time.Time { return now }
+
+	track := cacheTestTrack("dQw4w9WgXcQ", now.Add(6*time.Hour))
+	cache.Add("Never Gonna  Give You Up", track)
+
+	for _, query := range []string{
+		"never gonna give you up",
+		"https://youtu.be/dQw4w9WgXcQ",
+		"https://music.youtube.com/watch?v=dQw4w9WgXcQ&list=PL1",
+		"https://www.youtube.com/shorts/dQw4w9WgXcQ",
+	} {
+		cached, ok := cache.Get(query)
+		require.True(t, ok, query)
+		assert.Equal(t, track, *cached, query)
+	}
+
+	_, ok := cache.Get("together forever")
+	assert.False(t, ok)
+	assert.Equal(t, CacheStats{Entries: 1, Queries: 1, Capacity: metadataCacheSize, Hits: 4, Misses: 1, TTL: time.Hour}, cache.Stats())
+
+	now = now.Add(time.Hour)
+	_, ok = cache.Get("https://youtu.be/dQw4w9WgXcQ")
+	assert.False(t, ok, "entries expire after the TTL")
+}
+
+func TestMetadataCacheRespectsStreamExpiry(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewMetadataCache(24 * time.Hour)
+	cache.now = func() time.Time { return now }
+
+	cache.Add("https://youtu.be/a", cacheTestTrack("a", now.Add(2*time.Hour)))
+	cache.Add("https://youtu.be/b", cacheTestTrack("b", now.Add(streamExpiryMargin)))
+
+	now = now.Add(2*time.Hour - streamExpiryMargin - time.Second)
+	_, ok := cache.Get("https://youtu.be/a")
+	assert.True(t, ok)
+	now = now.Add(time.Second)
+	_, ok = cache.Get("https://youtu.be/a")
+	assert.False(t, ok, "tracks are extracted again before their stream URL expires")
+	assert.Equal(t, 1, cache.Stats().Entries, "a stream URL about to expire is not cached")
+}
+
+func TestMetadataCacheSkipsUncacheableTracks(t *testing.T) {
+	cache := NewMetadataCache(time.Hour)
+	expire := time.Now().Add(6 * time.Hour)
+
+	live := cacheTestTrack("live", expire)
+	live.Live = true
+	cache.Add("https://youtu.be/live", live)
+
+	other := cacheTestTrack("x", expire)
+	other.URL = "https://soundcloud.com/artist/track"
+	cache.Add(other.URL, other)
+
+	assert.Zero(t, cache.Stats().Entries)
+
+	disabled := NewMetadataCache(0)
+	disabled.Add("https://youtu.be/a", cacheTestTrack("a", expire))
+	_, ok := disabled.Get("https://youtu.be/a")
+	assert.False(t, ok, "a TTL of 0 disables the cache")
+}
+
+func TestMetadataCachePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "cache.json")
+	cache, err := LoadMetadataCache(path, time.Hour)
+	require.NoError(t, err)
+
+	track := cacheTestTrack("dQw4w9WgXcQ", time.Now().Add(6*time.Hour))
+	cache.Add("rick astley", track)
+	cache.Add("https://youtu.be/gone", cacheTestTrack("gone", time.Now().Add(6*time.Hour)))
+	cache.Forget("https://youtu.be/gone")
+
+	reloaded, err := LoadMetadataCache(path, time.Hour)
+	require.NoError(t, err)
+	cached, ok := reloaded.Get("Rick Astley")
+	require.True(t, ok)
+	assert.Equal(t, track, *cached)
+	assert.Equal(t, 1, reloaded.Stats().Entries)
+	assert.Equal(t, path, reloaded.Stats().Path)
+
+	require.NoError(t, reloaded.Purge())
+	purged, err := LoadMetadataCache(path, time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, purged.Stats().Entries)
+}
+
+func TestLoadMetadataCacheRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	_, err := LoadMetadataCache(path, time.Hour)
+	assert.Error(t, err)
+}
+
+func TestMetadataCacheEvictsSoonestExpiring(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewMetadataCache(24 * time.Hour)
+	cache.now = func() time.Time { return now }
+
+	for n := range metadataCacheSize {
+		cache.Add(fmt.Sprintf("query %d", n), cacheTestTrack(fmt.Sprintf("id%d", n), now.Add(time.Duration(n+60)*time.Minute)))
+	}
+	cache.Add("newest", cacheTestTrack("new", now.Add(10*time.Hour)))
+
+	stats := cache.Stats()
+	assert.Equal(t, metadataCacheSize, stats.Entries)
+	assert.Equal(t, metadataCacheSize, stats.Queries, "queries of evicted tracks are dropped")
+	_, ok := cache.Get("query 0")
+	assert.False(t, ok, "the track expiring first is evicted")
+	_, ok = cache.Get("newest")
+	assert.True(t, ok)
+}
+
+func TestCLIProviderUsesCache(t *testing.T) {
+	runner := &fakeRunner{output: rawVideoInfo}
+	provider := NewCLIProviderWithRunner(nil, runner.run)
+
+	first, err := provider.GetAudioSource(context.Background(), "https://www.youtube.com/watch?v=dQw4w9WgXcQ")
+	require.NoError(t, err)
+	second, err := provider.GetAudioSource(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, runner.callCount(), "the second play skips yt-dlp")
+
+	provider.UseCache(NewMetadataCache(0))
+	_, err = provider.GetAudioSource(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.Equal(t, 2, runner.callCount())
+}
+
+func TestVideoIDAndStreamExpiry(t *testing.T) {
+	tests := map[string]string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ": "dQw4w9WgXcQ",
+		"https://m.youtube.com/watch?v=dQw4w9WgXcQ":   "dQw4w9WgXcQ",
+		"https://youtu.be/dQw4w9WgXcQ?t=42":           "dQw4w9WgXcQ",
+		"https://www.youtube.com/playlist?list=PL123": "",
+		"https://soundcloud.com/artist/track":         "",
+		"never gonna give you up":                     "",
+	}
+	for rawURL, want := range tests {
+		videoID, ok := VideoID(rawURL)
+		assert.Equal(t, want, videoID, rawURL)
+		assert.Equal(t, want != "", ok, rawURL)
+	}
+
+	expires, ok := StreamExpiry("https://rr1---sn-example.googlevideo.com/videoplayback?expire=1740830400&itag=251")
+	require.True(t, ok)
+	assert.Equal(t, int64(1740830400), expires.Unix())
+	_, ok = StreamExpiry("https://example.com/audio.mp3")
+	assert.False(t, ok)
+}
@@ -0,0 +1,157 @@
+package ytdlp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// latencyWindow is how many recent yt-dlp runs the latency figures of /metrics cover
+const latencyWindow = 100
+
+// Between two polls, a service failing more than errorRateWarning of at least errorRateMinRequests
+// requests is logged as a warning
+const (
+	errorRateWarning     = 0.25
+	errorRateMinRequests = 10
+)
+
+// Requests returns how many extract and search requests the service handled
+func (m ServiceMetrics) Requests() int64 {
+	return m.Extractions + m.Searches
+}
+
+// ErrorRate returns the share of requests that failed, 0 before the first request
+func (m ServiceMetrics) ErrorRate() float64 {
+	if m.Requests() == 0 {
+		return 0
+	}
+	return float64(m.Errors) / float64(m.Requests())
+}
+
+// CacheHitRatio returns the share of requests answered from the service's cache, 0 before the first
+func (m ServiceMetrics) CacheHitRatio() float64 {
+	lookups := m.CacheHits + m.CacheMisses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(m.CacheHits) / float64(lookups)
+}
+
+// latencyRecorder keeps how long the most recent yt-dlp runs took
+type latencyRecorder struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// record adds a run, replacing the oldest once the window is full
+func (r *latencyRecorder) record(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.samples) < latencyWindow {
+		r.samples = append(r.samples, d)
+		return
+	}
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % latencyWindow
+}
+
+// summary returns the average, 95th percentile and longest run in milliseconds
+func (r *latencyRecorder) summary() (avg, p95, longest float64) {
+	r.mu.Lock()
+	samples := slices.Clone(r.samples)
+	r.mu.Unlock()
+
+	if len(samples) == 0 {
+		return 0, 0, 0
+	}
+	slices.Sort(samples)
+
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample
+	}
+	index := (len(samples)*95+99)/100 - 1
+	return milliseconds(total / time.Duration(len(samples))), milliseconds(samples[index]), milliseconds(samples[len(samples)-1])
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// MetricsSnapshot is the outcome of a MetricsPoller's last poll
+type MetricsSnapshot struct {
+	Metrics  ServiceMetrics // Metrics of the last successful poll
+	PolledAt time.Time      // When Metrics was read, zero before the first successful poll
+	Err      error          // Error of the last poll, nil when it succeeded
+}
+
+// MetricsPoller reads a yt-dlp service's /metrics periodically, so the bot can show them without
+// waiting on the service and warn when the service starts failing
+type MetricsPoller struct {
+	client *Client
+
+	mu       sync.RWMutex
+	snapshot MetricsSnapshot
+}
+
+// NewMetricsPoller creates a poller for the service the client talks to
+func NewMetricsPoller(client *Client) *MetricsPoller {
+	return &MetricsPoller{client: client}
+}
+
+// Poll reads the service's metrics once. Losing or regaining the service, and a high error rate since
+// the previous poll, are logged.
+func (p *MetricsPoller) Poll(ctx context.Context) error {
+	metrics, err := p.client.Metrics(ctx)
+
+	p.mu.Lock()
+	previous := p.snapshot
+	if err != nil {
+		p.snapshot.Err = err
+	} else {
+		p.snapshot = MetricsSnapshot{Metrics: *metrics, PolledAt: time.Now()}
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		if previous.Err == nil {
+			utils.LogWarn("yt-dlp service metrics are unavailable: %v", err)
+		}
+		return fmt.Errorf("failed to poll yt-dlp service metrics: %w", err)
+	}
+	if previous.Err != nil {
+		utils.LogInfo("yt-dlp service metrics are available again")
+	}
+	if !previous.PolledAt.IsZero() {
+		warnOnErrorRate(previous.Metrics, *metrics, time.Since(previous.PolledAt))
+	}
+	return nil
+}
+
+// Snapshot returns the outcome of the last poll
+func (p *MetricsPoller) Snapshot() MetricsSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.snapshot
+}
+
+// warnOnErrorRate logs a warning when the service failed many of the requests it handled between two
+// polls. A restarted service counts from zero again.
+func warnOnErrorRate(previous, current ServiceMetrics, elapsed time.Duration) {
+	if current.UptimeSeconds < previous.UptimeSeconds || current.Requests() < previous.Requests() {
+		previous = ServiceMetrics{}
+	}
+	requests := current.Requests() - previous.Requests()
+	errors := current.Errors - previous.Errors
+	if requests < errorRateMinRequests || float64(errors)/float64(requests) <= errorRateWarning {
+		return
+	}
+	utils.LogWarn("yt-dlp service failed %d of %d requests in the last %s", errors, requests, elapsed.Round(time.Second))
+}
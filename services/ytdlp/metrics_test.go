@@ -0,0 +1,84 @@
+package ytdlp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyRecorderSummary(t *testing.T) {
+	var recorder latencyRecorder
+	avg, p95, longest := recorder.summary()
+	assert.Zero(t, avg+p95+longest)
+
+	for i := 1; i <= 150; i++ {
+		recorder.record(time.Duration(i) * time.Millisecond)
+	}
+
+	// Only the last 100 runs count: 51ms to 150ms
+	avg, p95, longest = recorder.summary()
+	assert.InDelta(t, 100.5, avg, 0.001)
+	assert.InDelta(t, 145, p95, 0.001)
+	assert.InDelta(t, 150, longest, 0.001)
+}
+
+func TestMetricsPoller(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"success": false, "error": "down"}`))
+			return
+		}
+		fmt.Fprint(w, `{"success": true, "data": {"uptime_seconds": 60, "extractions": 8, "searches": 2, "errors": 1,
+			"cache_hits": 3, "cache_misses": 7, "latency_avg_ms": 850.5, "latency_p95_ms": 2100, "latency_max_ms": 3000}}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.baseURL = server.URL
+	poller := NewMetricsPoller(client)
+	assert.True(t, poller.Snapshot().PolledAt.IsZero())
+
+	require.NoError(t, poller.Poll(context.Background()))
+	snapshot := poller.Snapshot()
+	assert.NoError(t, snapshot.Err)
+	assert.Equal(t, int64(10), snapshot.Metrics.Requests())
+	assert.InDelta(t, 0.1, snapshot.Metrics.ErrorRate(), 0.001)
+	assert.InDelta(t, 0.3, snapshot.Metrics.CacheHitRatio(), 0.001)
+
+	// A failed poll keeps the last metrics
+	failing.Store(true)
+	assert.Error(t, poller.Poll(context.Background()))
+	snapshot = poller.Snapshot()
+	assert.Error(t, snapshot.Err)
+	assert.Equal(t, int64(8), snapshot.Metrics.Extractions)
+	assert.False(t, snapshot.PolledAt.IsZero())
+}
+
+func TestServiceConfigUseURL(t *testing.T) {
+	config := DefaultServiceConfig()
+	require.NoError(t, config.UseURL("http://ytdlp:9000"))
+	assert.Equal(t, "ytdlp", config.Host)
+	assert.Equal(t, 9000, config.Port)
+
+	assert.Equal(t, "ytdlp:9000", config.Address())
+
+	require.NoError(t, config.UseURL("http://[fd00::5]:8080"))
+	assert.Equal(t, "fd00::5", config.Host)
+	assert.Equal(t, "[fd00::5]:8080", config.Address())
+
+	require.NoError(t, config.UseURL("unix:/run/ytdlp/ytdlp.sock"))
+	assert.Equal(t, "/run/ytdlp/ytdlp.sock", config.Socket)
+	assert.Equal(t, "unix:/run/ytdlp/ytdlp.sock", config.Address())
+
+	assert.ErrorContains(t, config.UseURL("http://ytdlp"), "explicit port")
+	assert.Error(t, config.UseURL("not a url"))
+}
@@ -2,8 +2,10 @@ package ytdlp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"math/rand/v2"
 	"sync"
 	"time"
 )
@@ -16,6 +18,9 @@ type RetryConfig struct {
 	BackoffFactor   float64       `json:"backoff_factor"`
 	RandomJitter    bool          `json:"random_jitter"`
 	RetryableErrors []string      `json:"retryable_errors"`
+
+	// Budgets limit each operation's retries; operations without one retry up to MaxRetries times
+	Budgets map[Operation]RetryBudget `json:"budgets"`
 }
 
 // DefaultRetryConfig returns a default retry configuration
@@ -35,9 +40,13 @@ func DefaultRetryConfig() *RetryConfig {
 			"service unavailable",
 			"too many requests",
 		},
+		Budgets: DefaultRetryBudgets(),
 	}
 }
 
+// ErrCircuitOpen is returned without calling the service while the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
 // CircuitBreakerConfig defines configuration for circuit breaker
 type CircuitBreakerConfig struct {
 	FailureThreshold   int           `json:"failure_threshold"`
@@ -89,9 +98,23 @@ type CircuitBreaker struct {
 	lastFailure      time.Time
 	nextAttempt      time.Time
 	activeRequests   int
+	opened           int64 // Times the breaker opened
+	rejected         int64 // Requests refused without calling the service
 	mu               sync.RWMutex
 }
 
+// CircuitBreakerMetrics is a snapshot of a circuit breaker for reports
+type CircuitBreakerMetrics struct {
+	State          CircuitBreakerState
+	Failures       int       // Failures in a row
+	Successes      int       // Successes in a row while half-open
+	ActiveRequests int       // Requests in flight
+	Opened         int64     // Times the breaker opened
+	Rejected       int64     // Requests refused while open or at the concurrency limit
+	LastFailure    time.Time // Zero when no request failed yet
+	NextAttempt    time.Time // When an open breaker lets a request through again
+}
+
 // NewCircuitBreaker creates a new circuit breaker
 func NewCircuitBreaker(config *CircuitBreakerConfig) *CircuitBreaker {
 	if config == nil {
@@ -107,7 +130,10 @@ func NewCircuitBreaker(config *CircuitBreakerConfig) *CircuitBreaker {
 // Execute executes a function with circuit breaker protection
 func (cb *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) error {
 	if !cb.canExecute() {
-		return fmt.Errorf("circuit breaker is open")
+		cb.mu.Lock()
+		cb.rejected++
+		cb.mu.Unlock()
+		return ErrCircuitOpen
 	}
 
 	cb.beforeRequest()
@@ -193,10 +219,43 @@ func (cb *CircuitBreaker) onFailure() {
 		if cb.failures >= cb.config.FailureThreshold {
 			cb.state = StateOpen
 			cb.nextAttempt = time.Now().Add(cb.config.ResetTimeout)
+			cb.opened++
 		}
 	case StateHalfOpen:
 		cb.state = StateOpen
 		cb.nextAttempt = time.Now().Add(cb.config.ResetTimeout)
+		cb.opened++
+	}
+}
+
+// Reset closes the breaker and forgets the failures in a row, for operators who know the service is
+// back before the breaker would try it again. It returns the state it was in.
+func (cb *CircuitBreaker) Reset() CircuitBreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	previous := cb.state
+	cb.state = StateClosed
+	cb.failures = 0
+	cb.successes = 0
+	cb.nextAttempt = time.Time{}
+	return previous
+}
+
+// Snapshot returns the breaker's state and counters
+func (cb *CircuitBreaker) Snapshot() CircuitBreakerMetrics {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	return CircuitBreakerMetrics{
+		State:          cb.state,
+		Failures:       cb.failures,
+		Successes:      cb.successes,
+		ActiveRequests: cb.activeRequests,
+		Opened:         cb.opened,
+		Rejected:       cb.rejected,
+		LastFailure:    cb.lastFailure,
+		NextAttempt:    cb.nextAttempt,
 	}
 }
 
@@ -227,19 +286,12 @@ type ResilientClient struct {
 	client         *Client
 	circuitBreaker *CircuitBreaker
 	retryConfig    *RetryConfig
+	retries        retryBudgets
 }
 
 // NewResilientClient creates a new resilient client
 func NewResilientClient(config *ServiceConfig) *ResilientClient {
-	client := NewClient(config)
-	circuitBreaker := NewCircuitBreaker(DefaultCircuitBreakerConfig())
-	retryConfig := DefaultRetryConfig()
-
-	return &ResilientClient{
-		client:         client,
-		circuitBreaker: circuitBreaker,
-		retryConfig:    retryConfig,
-	}
+	return NewResilientClientWithConfigs(config, DefaultCircuitBreakerConfig(), DefaultRetryConfig())
 }
 
 // NewResilientClientWithConfigs creates a resilient client with custom configurations
@@ -259,6 +311,7 @@ func NewResilientClientWithConfigs(
 		client:         client,
 		circuitBreaker: circuitBreaker,
 		retryConfig:    retryConfig,
+		retries:        newRetryBudgets(retryConfig.Budgets, retryConfig.MaxRetries),
 	}
 }
 
@@ -267,7 +320,7 @@ func (rc *ResilientClient) HealthCheck(ctx context.Context) (*HealthStatus, erro
 	var result *HealthStatus
 	var err error
 
-	retryErr := rc.withRetry(ctx, func(ctx context.Context) error {
+	retryErr := rc.withRetry(ctx, OperationHealth, func(ctx context.Context) error {
 		result, err = rc.client.HealthCheck(ctx)
 		return err
 	})
@@ -284,7 +337,7 @@ func (rc *ResilientClient) ExtractInfo(ctx context.Context, url string) (*VideoI
 	var result *VideoInfo
 	var err error
 
-	retryErr := rc.withRetry(ctx, func(ctx context.Context) error {
+	retryErr := rc.withRetry(ctx, OperationExtract, func(ctx context.Context) error {
 		result, err = rc.client.ExtractInfo(ctx, url)
 		return err
 	})
@@ -301,7 +354,7 @@ func (rc *ResilientClient) ExtractInfoWithFormat(ctx context.Context, url, forma
 	var result *VideoInfo
 	var err error
 
-	retryErr := rc.withRetry(ctx, func(ctx context.Context) error {
+	retryErr := rc.withRetry(ctx, OperationExtract, func(ctx context.Context) error {
 		result, err = rc.client.ExtractInfoWithFormat(ctx, url, format)
 		return err
 	})
@@ -318,7 +371,7 @@ func (rc *ResilientClient) Search(ctx context.Context, query string, maxResults
 	var result *SearchResult
 	var err error
 
-	retryErr := rc.withRetry(ctx, func(ctx context.Context) error {
+	retryErr := rc.withRetry(ctx, OperationSearch, func(ctx context.Context) error {
 		result, err = rc.client.Search(ctx, query, maxResults)
 		return err
 	})
@@ -332,23 +385,24 @@ func (rc *ResilientClient) Search(ctx context.Context, query string, maxResults
 
 // ClearCache clears the cache with resilience
 func (rc *ResilientClient) ClearCache(ctx context.Context) error {
-	return rc.withRetry(ctx, func(ctx context.Context) error {
+	return rc.withRetry(ctx, OperationCache, func(ctx context.Context) error {
 		return rc.client.ClearCache(ctx)
 	})
 }
 
 // withRetry executes a function with retry logic and circuit breaker
-func (rc *ResilientClient) withRetry(ctx context.Context, fn func(context.Context) error) error {
+func (rc *ResilientClient) withRetry(ctx context.Context, operation Operation, fn func(context.Context) error) error {
 	return rc.circuitBreaker.Execute(ctx, func(ctx context.Context) error {
-		return rc.executeWithRetry(ctx, fn)
+		return rc.executeWithRetry(ctx, operation, fn)
 	})
 }
 
-// executeWithRetry executes a function with exponential backoff retry
-func (rc *ResilientClient) executeWithRetry(ctx context.Context, fn func(context.Context) error) error {
-	var lastErr error
+// executeWithRetry executes a function with exponential backoff retry, within the operation's budget
+func (rc *ResilientClient) executeWithRetry(ctx context.Context, operation Operation, fn func(context.Context) error) error {
+	budget := rc.retries[operation]
+	budget.request()
 
-	for attempt := 0; attempt <= rc.retryConfig.MaxRetries; attempt++ {
+	for attempt := 0; ; attempt++ {
 		if attempt > 0 {
 			delay := rc.calculateDelay(attempt)
 			select {
@@ -360,23 +414,21 @@ func (rc *ResilientClient) executeWithRetry(ctx context.Context, fn func(context
 
 		err := fn(ctx)
 		if err == nil {
+			if attempt > 0 {
+				budget.recovered()
+			}
 			return nil
 		}
 
-		lastErr = err
-
 		// Check if error is retryable
 		if !rc.isRetryableError(err) {
 			return err
 		}
 
-		// Don't retry on the last attempt
-		if attempt == rc.retryConfig.MaxRetries {
-			break
+		if reason := budget.retry(attempt + 1); reason != nil {
+			return fmt.Errorf("%w: %w", reason, err)
 		}
 	}
-
-	return fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
 // calculateDelay calculates the delay for exponential backoff
@@ -384,10 +436,9 @@ func (rc *ResilientClient) calculateDelay(attempt int) time.Duration {
 	delay := float64(rc.retryConfig.InitialDelay) * math.Pow(rc.retryConfig.BackoffFactor, float64(attempt-1))
 
 	if rc.retryConfig.RandomJitter {
-		// Add random jitter (±25%)
+		// Add random jitter (±25%) so clients retrying the same outage spread out
 		jitter := delay * 0.25
-		randomFactor := float64(time.Now().UnixNano()%1000) / 1000.0
-		delay += (2*jitter*randomFactor - jitter)
+		delay += 2*jitter*rand.Float64() - jitter
 	}
 
 	delayDuration := time.Duration(delay)
@@ -446,6 +497,25 @@ func (rc *ResilientClient) GetCircuitBreakerState() CircuitBreakerState {
 	return rc.circuitBreaker.GetState()
 }
 
+// CircuitBreakerSnapshot returns the circuit breaker's state and counters
+func (rc *ResilientClient) CircuitBreakerSnapshot() CircuitBreakerMetrics {
+	return rc.circuitBreaker.Snapshot()
+}
+
+// ResetCircuitBreaker closes the circuit breaker, returning the state it was in
+func (rc *ResilientClient) ResetCircuitBreaker() CircuitBreakerState {
+	return rc.circuitBreaker.Reset()
+}
+
+// RetryMetrics returns the requests and retries of every operation
+func (rc *ResilientClient) RetryMetrics() map[Operation]RetryStats {
+	metrics := make(map[Operation]RetryStats, len(rc.retries))
+	for operation, budget := range rc.retries {
+		metrics[operation] = budget.snapshot()
+	}
+	return metrics
+}
+
 // Close closes the resilient client
 func (rc *ResilientClient) Close() error {
 	return rc.client.Close()
@@ -0,0 +1,168 @@
+package ytdlp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrRetryBudgetExhausted is wrapped into the last error when a retry was skipped because too many
+// requests of the same kind were already retried
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// errMaxRetries is wrapped into the last error when a request used all its retries
+var errMaxRetries = errors.New("max retries exceeded")
+
+// Operation is a kind of request to the yt-dlp service, each retried within a budget of its own
+type Operation string
+
+const (
+	OperationHealth  Operation = "health"
+	OperationExtract Operation = "extract"
+	OperationSearch  Operation = "search"
+	OperationCache   Operation = "cache"
+)
+
+// Operations lists every operation, in the order metrics are shown
+var Operations = []Operation{OperationExtract, OperationSearch, OperationHealth, OperationCache}
+
+// retryBudgetReserve is how many retries an operation may make in a row before its budget needs
+// refilling by new requests
+const retryBudgetReserve = 10
+
+// RetryBudget limits the retries of one operation
+type RetryBudget struct {
+	MaxRetries int     `json:"max_retries"` // Retries of a single request
+	Ratio      float64 `json:"ratio"`       // Retries earned per request, 0.2 allows one in five; 0 doesn't limit them
+}
+
+// DefaultRetryBudgets retry extractions and searches more readily than health checks and cache clears,
+// and keep retries to a fifth of requests so an outage isn't met with a flood of them
+func DefaultRetryBudgets() map[Operation]RetryBudget {
+	return map[Operation]RetryBudget{
+		OperationExtract: {MaxRetries: 3, Ratio: 0.2},
+		OperationSearch:  {MaxRetries: 2, Ratio: 0.2},
+		OperationHealth:  {MaxRetries: 1, Ratio: 0.1},
+		OperationCache:   {MaxRetries: 1, Ratio: 0.1},
+	}
+}
+
+// ParseRetryBudgets reads budgets like "extract=3:0.2,search=1". The number after the colon is the
+// share of requests that may be retried; without it the budget keeps its default share. Operations
+// left out keep their default budget.
+func ParseRetryBudgets(raw string) (map[Operation]RetryBudget, error) {
+	budgets := DefaultRetryBudgets()
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(pair, "=")
+		operation := Operation(strings.ToLower(strings.TrimSpace(name)))
+		budget, known := budgets[operation]
+		if !found || !known {
+			return nil, fmt.Errorf("invalid retry budget %q, expected extract, search, health or cache=retries[:ratio]", pair)
+		}
+
+		retries, ratio, hasRatio := strings.Cut(value, ":")
+		n, err := strconv.Atoi(strings.TrimSpace(retries))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid retry count in %q", pair)
+		}
+		budget.MaxRetries = n
+		if hasRatio {
+			share, err := strconv.ParseFloat(strings.TrimSpace(ratio), 64)
+			if err != nil || share < 0 {
+				return nil, fmt.Errorf("invalid retry ratio in %q", pair)
+			}
+			budget.Ratio = share
+		}
+		budgets[operation] = budget
+	}
+	return budgets, nil
+}
+
+// RetryStats counts an operation's requests and their retries
+type RetryStats struct {
+	Requests  int64 // Requests made, not counting retries
+	Retries   int64 // Retries made
+	Recovered int64 // Requests that succeeded after a retry
+	Exhausted int64 // Requests that failed after using all their retries
+	Denied    int64 // Retries skipped because the operation's budget ran out
+}
+
+// retryBudget tracks the retries one operation has left. Every request adds Ratio to the balance and
+// every retry spends one, so retries stay a share of requests however many fail.
+type retryBudget struct {
+	mu      sync.Mutex
+	budget  RetryBudget
+	balance float64
+	stats   RetryStats
+}
+
+// newRetryBudget creates a budget that can retry a few requests right away
+func newRetryBudget(budget RetryBudget) *retryBudget {
+	return &retryBudget{budget: budget, balance: retryBudgetReserve}
+}
+
+// request counts a new request and earns its share of a retry
+func (b *retryBudget) request() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats.Requests++
+	b.balance = min(b.balance+b.budget.Ratio, retryBudgetReserve)
+}
+
+// retry spends from the budget for a request's attempt'th retry, or returns why the request may not
+// retry again
+func (b *retryBudget) retry(attempt int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if attempt > b.budget.MaxRetries {
+		b.stats.Exhausted++
+		return errMaxRetries
+	}
+	if b.budget.Ratio > 0 {
+		if b.balance < 1 {
+			b.stats.Denied++
+			return ErrRetryBudgetExhausted
+		}
+		b.balance--
+	}
+	b.stats.Retries++
+	return nil
+}
+
+// recovered counts a request that succeeded after retrying
+func (b *retryBudget) recovered() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats.Recovered++
+}
+
+// snapshot returns the operation's counters
+func (b *retryBudget) snapshot() RetryStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// retryBudgets holds the budget of every operation
+type retryBudgets map[Operation]*retryBudget
+
+// newRetryBudgets creates budgets from config, retrying operations without one up to maxRetries times
+// without a share limit
+func newRetryBudgets(config map[Operation]RetryBudget, maxRetries int) retryBudgets {
+	budgets := make(retryBudgets, len(Operations))
+	for _, operation := range Operations {
+		budget, ok := config[operation]
+		if !ok {
+			budget = RetryBudget{MaxRetries: maxRetries}
+		}
+		budgets[operation] = newRetryBudget(budget)
+	}
+	return budgets
+}
@@ -0,0 +1,108 @@
+package ytdlp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyClient creates a client for a service that fails the first failures requests with 503
+func newFlakyClient(t *testing.T, failures int32, budgets map[Operation]RetryBudget) (*ResilientClient, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"success": false, "error": "service unavailable", "code": 503}`))
+			return
+		}
+		w.Write([]byte(serviceVideo))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewResilientClientWithConfigs(nil, &CircuitBreakerConfig{
+		FailureThreshold:      100,
+		SuccessThreshold:      1,
+		Timeout:               5 * time.Second,
+		ResetTimeout:          time.Hour,
+		MaxConcurrentRequests: 10,
+	}, &RetryConfig{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffFactor: 1, Budgets: budgets})
+	client.client.baseURL = server.URL
+	return client, &requests
+}
+
+func TestResilientClientRetriesWithinTheOperationsRetries(t *testing.T) {
+	client, requests := newFlakyClient(t, 2, map[Operation]RetryBudget{OperationExtract: {MaxRetries: 3}})
+
+	info, err := client.ExtractInfo(context.Background(), "https://youtu.be/abc")
+	require.NoError(t, err)
+	assert.Equal(t, "Service Song", info.Title)
+	assert.EqualValues(t, 3, requests.Load())
+	assert.Equal(t, RetryStats{Requests: 1, Retries: 2, Recovered: 1}, client.RetryMetrics()[OperationExtract])
+	assert.Zero(t, client.RetryMetrics()[OperationSearch].Requests, "operations are counted separately")
+}
+
+func TestResilientClientGivesUpAfterMaxRetries(t *testing.T) {
+	client, requests := newFlakyClient(t, 10, map[Operation]RetryBudget{OperationExtract: {MaxRetries: 1}})
+
+	_, err := client.ExtractInfo(context.Background(), "https://youtu.be/abc")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max retries exceeded")
+	assert.EqualValues(t, 2, requests.Load())
+	assert.Equal(t, RetryStats{Requests: 1, Retries: 1, Exhausted: 1}, client.RetryMetrics()[OperationExtract])
+}
+
+func TestResilientClientStopsRetryingOverBudget(t *testing.T) {
+	client, requests := newFlakyClient(t, 1000, map[Operation]RetryBudget{OperationExtract: {MaxRetries: 100, Ratio: 0.5}})
+
+	// The reserve covers ten retries, each request earning half of one more
+	_, err := client.ExtractInfo(context.Background(), "https://youtu.be/abc")
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.EqualValues(t, 11, requests.Load())
+
+	_, err = client.ExtractInfo(context.Background(), "https://youtu.be/abc")
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.EqualValues(t, 12, requests.Load(), "half a retry earned isn't enough for one")
+
+	_, err = client.ExtractInfo(context.Background(), "https://youtu.be/abc")
+	assert.ErrorIs(t, err, ErrRetryBudgetExhausted)
+	assert.EqualValues(t, 14, requests.Load())
+
+	assert.Equal(t, RetryStats{Requests: 3, Retries: 11, Denied: 3}, client.RetryMetrics()[OperationExtract])
+}
+
+func TestCalculateDelayJitter(t *testing.T) {
+	client := NewResilientClientWithConfigs(nil, nil, &RetryConfig{
+		InitialDelay:  time.Second,
+		MaxDelay:      time.Minute,
+		BackoffFactor: 2,
+		RandomJitter:  true,
+	})
+
+	seen := make(map[time.Duration]bool)
+	for range 50 {
+		delay := client.calculateDelay(2)
+		assert.GreaterOrEqual(t, delay, 1500*time.Millisecond)
+		assert.LessOrEqual(t, delay, 2500*time.Millisecond)
+		seen[delay] = true
+	}
+	assert.Greater(t, len(seen), 1, "delays in a row differ")
+}
+
+func TestParseRetryBudgets(t *testing.T) {
+	budgets, err := ParseRetryBudgets(" extract=5:0.5 , SEARCH=0")
+	require.NoError(t, err)
+	assert.Equal(t, RetryBudget{MaxRetries: 5, Ratio: 0.5}, budgets[OperationExtract])
+	assert.Equal(t, RetryBudget{MaxRetries: 0, Ratio: DefaultRetryBudgets()[OperationSearch].Ratio}, budgets[OperationSearch])
+	assert.Equal(t, DefaultRetryBudgets()[OperationHealth], budgets[OperationHealth])
+
+	for _, raw := range []string{"extract", "download=1", "extract=-1", "extract=1:x", "search=two"} {
+		_, err := ParseRetryBudgets(raw)
+		assert.Error(t, err, raw)
+	}
+}
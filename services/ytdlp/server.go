@@ -0,0 +1,450 @@
+package ytdlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pxnx-discord-bot/tracing"
+	"pxnx-discord-bot/utils"
+)
+
+// serverCacheSize bounds how many extract and search responses the server keeps
+const serverCacheSize = 500
+
+// maxServerSearchResults caps max_results, matching the client's limit
+const maxServerSearchResults = 50
+
+// CommandRunner runs yt-dlp with the given arguments and returns its standard output
+type CommandRunner func(ctx context.Context, args ...string) ([]byte, error)
+
+// BinaryRunner returns a CommandRunner that executes the yt-dlp binary at path
+func BinaryRunner(path string) CommandRunner {
+	return func(ctx context.Context, args ...string) ([]byte, error) {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, path, args...)
+		cmd.Stderr = &stderr
+
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("%s failed: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+		}
+		return output, nil
+	}
+}
+
+// Server implements the HTTP API of server.py in Go by running the yt-dlp binary, so the service
+// works without a Python runtime. Responses follow schema/responses.schema.json.
+type Server struct {
+	config  *ServiceConfig
+	run     CommandRunner
+	cache   *utils.LRUCache[string, interface{}]
+	workers chan struct{}
+	proxies *ProxyPool
+	started time.Time
+
+	requestCount atomic.Int64
+	errorCount   atomic.Int64
+	extractCount atomic.Int64
+	searchCount  atomic.Int64
+	cacheHits    atomic.Int64
+	cacheMisses  atomic.Int64
+	latencies    latencyRecorder
+
+	versionOnce sync.Once
+	version     string
+}
+
+// NewServer creates a server that runs the yt-dlp binary named in the config
+func NewServer(config *ServiceConfig) *Server {
+	if config == nil {
+		config = DefaultServiceConfig()
+	}
+	return NewServerWithRunner(config, BinaryRunner(config.BinaryPath))
+}
+
+// NewServerWithRunner creates a server that runs yt-dlp through the given runner
+func NewServerWithRunner(config *ServiceConfig, run CommandRunner) *Server {
+	if config == nil {
+		config = DefaultServiceConfig()
+	}
+
+	return &Server{
+		config:  config,
+		run:     run,
+		cache:   utils.NewLRUCache[string, interface{}](serverCacheSize, config.CacheTTL),
+		workers: make(chan struct{}, max(1, config.MaxWorkers)),
+		proxies: NewProxyPool(config.ProxyList()...),
+		started: time.Now(),
+	}
+}
+
+// Handler returns the HTTP handler serving the service API, traced when tracing is on
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.HandleFunc("POST /extract", s.handleExtract)
+	mux.HandleFunc("POST /search", s.handleSearch)
+	mux.HandleFunc("POST /cache/clear", s.handleClearCache)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return tracing.Middleware("ytdlp.server", mux)
+}
+
+// ListenAndServe serves the API on the configured address until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	listener, err := utils.Listen(s.config.Address())
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	utils.SafeGo("ytdlp.serverShutdown", func() {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				utils.LogWarn("yt-dlp server shutdown: %v", err)
+			}
+		case <-done:
+		}
+	})
+
+	utils.LogInfo("yt-dlp server listening on %s", listener.Addr())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeServiceResponse(w, http.StatusOK, map[string]interface{}{
+		"status":        "healthy",
+		"version":       s.binaryVersion(),
+		"uptime":        time.Since(s.started).Round(time.Second).String(),
+		"request_count": s.requestCount.Load(),
+		"error_count":   s.errorCount.Load(),
+		"worker_count":  cap(s.workers),
+		"last_check":    time.Now().Format(time.RFC3339),
+	})
+}
+
+func (s *Server) handleExtract(w http.ResponseWriter, r *http.Request) {
+	var request ExtractRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeServiceError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if request.URL == "" {
+		writeServiceError(w, http.StatusBadRequest, "URL is required")
+		return
+	}
+
+	s.requestCount.Add(1)
+	s.extractCount.Add(1)
+
+	format := request.Format
+	if format == "" {
+		format = s.config.Format
+	}
+
+	cacheKey := "extract:" + format + ":" + request.URL
+	if cached, found := s.cache.Get(cacheKey); found {
+		utils.LogDebug("yt-dlp server cache hit for URL: %s", request.URL)
+		s.cacheHits.Add(1)
+		writeServiceResponse(w, http.StatusOK, cached)
+		return
+	}
+	s.cacheMisses.Add(1)
+
+	info, err := s.runJSON(r.Context(), "-f", format, "--no-playlist", "--", request.URL)
+	if err != nil {
+		s.errorCount.Add(1)
+		utils.LogWarn("yt-dlp extraction error for %s: %v", request.URL, err)
+		writeServiceError(w, http.StatusNotFound, "Failed to extract video information")
+		return
+	}
+
+	video := cleanVideoInfo(info, request.URL)
+	s.cache.Add(cacheKey, video)
+	writeServiceResponse(w, http.StatusOK, video)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var request SearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeServiceError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if request.Query == "" {
+		writeServiceError(w, http.StatusBadRequest, "Query is required")
+		return
+	}
+
+	maxResults := request.MaxResults
+	if maxResults <= 0 {
+		maxResults = 10
+	}
+	maxResults = min(maxResults, maxServerSearchResults)
+
+	s.requestCount.Add(1)
+	s.searchCount.Add(1)
+
+	cacheKey := fmt.Sprintf("search:%s:%d", request.Query, maxResults)
+	if cached, found := s.cache.Get(cacheKey); found {
+		utils.LogDebug("yt-dlp server cache hit for search: %s", request.Query)
+		s.cacheHits.Add(1)
+		writeServiceResponse(w, http.StatusOK, cached)
+		return
+	}
+	s.cacheMisses.Add(1)
+
+	results, err := s.runJSON(r.Context(), "-f", s.config.Format, "--", fmt.Sprintf("ytsearch%d:%s", maxResults, request.Query))
+	if err != nil {
+		s.errorCount.Add(1)
+		utils.LogWarn("yt-dlp search error for '%s': %v", request.Query, err)
+		writeServiceError(w, http.StatusInternalServerError, "Search failed")
+		return
+	}
+
+	videos := []interface{}{}
+	entries, _ := results["entries"].([]interface{})
+	for _, entry := range entries {
+		if info, ok := entry.(map[string]interface{}); ok && len(videos) < maxResults {
+			videos = append(videos, cleanVideoInfo(info, ""))
+		}
+	}
+
+	result := map[string]interface{}{
+		"videos":      videos,
+		"total_count": len(videos),
+		"query":       request.Query,
+	}
+	s.cache.Add(cacheKey, result)
+	writeServiceResponse(w, http.StatusOK, result)
+}
+
+func (s *Server) handleClearCache(w http.ResponseWriter, r *http.Request) {
+	s.cache.Purge()
+	writeServiceResponse(w, http.StatusOK, map[string]interface{}{"message": "Cache cleared successfully"})
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	avg, p95, longest := s.latencies.summary()
+	writeServiceResponse(w, http.StatusOK, ServiceMetrics{
+		UptimeSeconds: int64(time.Since(s.started).Seconds()),
+		Extractions:   s.extractCount.Load(),
+		Searches:      s.searchCount.Load(),
+		Errors:        s.errorCount.Load(),
+		CacheHits:     s.cacheHits.Load(),
+		CacheMisses:   s.cacheMisses.Load(),
+		LatencyAvgMs:  avg,
+		LatencyP95Ms:  p95,
+		LatencyMaxMs:  longest,
+	})
+}
+
+// runJSON runs yt-dlp in JSON dump mode once a worker is free and decodes its output
+func (s *Server) runJSON(ctx context.Context, args ...string) (map[string]interface{}, error) {
+	select {
+	case s.workers <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.workers }()
+
+	base := append([]string{"--dump-single-json", "--no-warnings"}, s.config.Credentials.Args()...)
+	base = append(base, s.proxies.Args()...)
+	started := time.Now()
+	output, err := s.run(ctx, append(base, args...)...)
+	s.latencies.record(time.Since(started))
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers stay json.Number so counts and sizes are passed on exactly as yt-dlp reported them
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	decoder.UseNumber()
+
+	var info map[string]interface{}
+	if err := decoder.Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to parse yt-dlp output: %w", err)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("yt-dlp returned no information")
+	}
+	return info, nil
+}
+
+// binaryVersion reports the yt-dlp version, asking the binary once
+func (s *Server) binaryVersion() string {
+	s.versionOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		s.version = "unknown"
+		if output, err := s.run(ctx, "--version"); err == nil {
+			s.version = strings.TrimSpace(string(output))
+		} else {
+			utils.LogWarn("Failed to read yt-dlp version: %v", err)
+		}
+	})
+	return s.version
+}
+
+// writeServiceResponse writes a successful response envelope
+func writeServiceResponse(w http.ResponseWriter, status int, data interface{}) {
+	writeJSON(w, status, ServiceResponse{Success: true, Data: data})
+}
+
+// writeServiceError writes a failed response envelope carrying the status code
+func writeServiceError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ServiceResponse{Success: false, Error: message, Code: status})
+}
+
+func writeJSON(w http.ResponseWriter, status int, response ServiceResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		utils.LogWarn("Failed to write yt-dlp server response: %v", err)
+	}
+}
+
+// cleanVideoInfo structures yt-dlp output as a VideoInfo, mirroring _clean_video_info in server.py
+func cleanVideoInfo(info map[string]interface{}, fallbackURL string) map[string]interface{} {
+	thumbnails := objectList(info["thumbnails"])
+
+	return map[string]interface{}{
+		"id":            stringOr(info["id"], ""),
+		"title":         stringOr(info["title"], ""),
+		"description":   valueOr(info, "description", ""),
+		"duration":      info["duration"],
+		"webpage_url":   stringOr(info["webpage_url"], fallbackURL),
+		"thumbnail":     bestThumbnail(thumbnails),
+		"uploader":      valueOr(info, "uploader", ""),
+		"upload_date":   valueOr(info, "upload_date", ""),
+		"view_count":    info["view_count"],
+		"extractor":     valueOr(info, "extractor", ""),
+		"extractor_key": valueOr(info, "extractor_key", ""),
+		"available":     true,
+		"live_status":   info["live_status"],
+		"tags":          valueOr(info, "tags", []interface{}{}),
+		"categories":    valueOr(info, "categories", []interface{}{}),
+		"formats":       cleanFormats(objectList(info["formats"])),
+		"thumbnails":    cleanThumbnails(thumbnails),
+	}
+}
+
+func cleanFormats(formats []map[string]interface{}) []interface{} {
+	cleaned := make([]interface{}, 0, len(formats))
+	for _, format := range formats {
+		cleaned = append(cleaned, map[string]interface{}{
+			"format_id":  stringOr(format["format_id"], ""),
+			"url":        stringOr(format["url"], ""),
+			"ext":        stringOr(format["ext"], ""),
+			"format":     valueOr(format, "format", ""),
+			"protocol":   format["protocol"],
+			"vcodec":     format["vcodec"],
+			"acodec":     format["acodec"],
+			"width":      format["width"],
+			"height":     format["height"],
+			"fps":        format["fps"],
+			"tbr":        format["tbr"],
+			"vbr":        format["vbr"],
+			"abr":        format["abr"],
+			"asr":        format["asr"],
+			"filesize":   format["filesize"],
+			"quality":    format["quality"],
+			"language":   format["language"],
+			"preference": format["preference"],
+		})
+	}
+	return cleaned
+}
+
+func cleanThumbnails(thumbnails []map[string]interface{}) []interface{} {
+	cleaned := make([]interface{}, 0, len(thumbnails))
+	for _, thumbnail := range thumbnails {
+		cleaned = append(cleaned, map[string]interface{}{
+			"id":         thumbnail["id"],
+			"url":        stringOr(thumbnail["url"], ""),
+			"width":      thumbnail["width"],
+			"height":     thumbnail["height"],
+			"resolution": thumbnail["resolution"],
+		})
+	}
+	return cleaned
+}
+
+// bestThumbnail returns the URL of the widest thumbnail, then the tallest, keeping the first on ties
+func bestThumbnail(thumbnails []map[string]interface{}) string {
+	var best map[string]interface{}
+	for _, thumbnail := range thumbnails {
+		if best == nil {
+			best = thumbnail
+			continue
+		}
+		width, height := numberOrZero(thumbnail["width"]), numberOrZero(thumbnail["height"])
+		bestWidth, bestHeight := numberOrZero(best["width"]), numberOrZero(best["height"])
+		if width > bestWidth || (width == bestWidth && height > bestHeight) {
+			best = thumbnail
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return stringOr(best["url"], "")
+}
+
+// objectList returns the objects in a JSON array, skipping nulls and other values
+func objectList(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	objects := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if object, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+// stringOr returns value when it is a non-empty string, like Python's `value or fallback`
+func stringOr(value interface{}, fallback string) string {
+	if text, ok := value.(string); ok && text != "" {
+		return text
+	}
+	return fallback
+}
+
+// valueOr returns the value stored under key, or fallback when the key is absent, like Python's dict.get
+func valueOr(object map[string]interface{}, key string, fallback interface{}) interface{} {
+	if value, exists := object[key]; exists {
+		return value
+	}
+	return fallback
+}
+
+// numberOrZero reads a JSON number, treating anything else as zero
+func numberOrZero(value interface{}) float64 {
+	switch number := value.(type) {
+	case json.Number:
+		parsed, _ := number.Float64()
+		return parsed
+	case float64:
+		return number
+	}
+	return 0
+}
@@ -8,8 +8,11 @@ import asyncio
 import json
 import logging
 import os
+import stat
 import sys
+import math
 import time
+from collections import deque
 from datetime import datetime, timedelta
 from pathlib import Path
 from typing import Dict, List, Optional, Any
@@ -30,6 +33,11 @@ class YTDLPService:
         self.start_time = datetime.now()
         self.request_count = 0
         self.error_count = 0
+        self.extract_count = 0
+        self.search_count = 0
+        self.cache_hits = 0
+        self.cache_misses = 0
+        self.latencies = deque(maxlen=100)  # Seconds the last 100 yt-dlp runs took
         self.cache = {}
         self.cache_ttl = timedelta(hours=config.get('cache_ttl_hours', 24))
 
@@ -95,6 +103,7 @@ class YTDLPService:
                 }, status=400)
 
             self.request_count += 1
+            self.extract_count += 1
 
             # Check cache first
             cache_key = f"extract:{url}"
@@ -102,10 +111,12 @@ class YTDLPService:
                 cache_entry = self.cache[cache_key]
                 if datetime.now() - cache_entry['timestamp'] < self.cache_ttl:
                     self.logger.info(f"Cache hit for URL: {url}")
+                    self.cache_hits += 1
                     return web.json_response({
                         'success': True,
                         'data': cache_entry['data']
                     })
+            self.cache_misses += 1
 
             # Extract info using yt-dlp
             loop = asyncio.get_event_loop()
@@ -152,33 +163,16 @@ class YTDLPService:
                 opts['format'] = format_override
 
             with yt_dlp.YoutubeDL(opts) as ydl:
-                info = ydl.extract_info(url, download=False)
+                started = time.monotonic()
+                try:
+                    info = ydl.extract_info(url, download=False)
+                finally:
+                    self.latencies.append(time.monotonic() - started)
 
                 if not info:
                     return None
 
-                # Clean and structure the info
-                clean_info = {
-                    'id': info.get('id', ''),
-                    'title': info.get('title', ''),
-                    'description': info.get('description', ''),
-                    'duration': info.get('duration'),
-                    'webpage_url': info.get('webpage_url', url),
-                    'thumbnail': self._get_best_thumbnail(info.get('thumbnails', [])),
-                    'uploader': info.get('uploader', ''),
-                    'upload_date': info.get('upload_date', ''),
-                    'view_count': info.get('view_count'),
-                    'extractor': info.get('extractor', ''),
-                    'extractor_key': info.get('extractor_key', ''),
-                    'available': True,
-                    'live_status': info.get('live_status'),
-                    'tags': info.get('tags', []),
-                    'categories': info.get('categories', []),
-                    'formats': self._clean_formats(info.get('formats', [])),
-                    'thumbnails': self._clean_thumbnails(info.get('thumbnails', []))
-                }
-
-                return clean_info
+                return self._clean_video_info(info, url)
 
         except Exception as e:
             self.logger.error(f"yt-dlp extraction error for {url}: {str(e)}")
@@ -199,6 +193,7 @@ class YTDLPService:
                 }, status=400)
 
             self.request_count += 1
+            self.search_count += 1
 
             # Check cache first
             cache_key = f"search:{query}:{max_results}"
@@ -206,10 +201,12 @@ class YTDLPService:
                 cache_entry = self.cache[cache_key]
                 if datetime.now() - cache_entry['timestamp'] < self.cache_ttl:
                     self.logger.info(f"Cache hit for search: {query}")
+                    self.cache_hits += 1
                     return web.json_response({
                         'success': True,
                         'data': cache_entry['data']
                     })
+            self.cache_misses += 1
 
             # Perform search using yt-dlp
             loop = asyncio.get_event_loop()
@@ -257,7 +254,11 @@ class YTDLPService:
             opts['quiet'] = True
 
             with yt_dlp.YoutubeDL(opts) as ydl:
-                search_results = ydl.extract_info(search_query, download=False)
+                started = time.monotonic()
+                try:
+                    search_results = ydl.extract_info(search_query, download=False)
+                finally:
+                    self.latencies.append(time.monotonic() - started)
 
                 if not search_results or 'entries' not in search_results:
                     return {
@@ -269,24 +270,7 @@ class YTDLPService:
                 videos = []
                 for entry in search_results['entries'][:max_results]:
                     if entry:
-                        video_info = {
-                            'id': entry.get('id', ''),
-                            'title': entry.get('title', ''),
-                            'description': entry.get('description', ''),
-                            'duration': entry.get('duration'),
-                            'webpage_url': entry.get('webpage_url', ''),
-                            'thumbnail': self._get_best_thumbnail(entry.get('thumbnails', [])),
-                            'uploader': entry.get('uploader', ''),
-                            'upload_date': entry.get('upload_date', ''),
-                            'view_count': entry.get('view_count'),
-                            'extractor': entry.get('extractor', ''),
-                            'extractor_key': entry.get('extractor_key', ''),
-                            'available': True,
-                            'live_status': entry.get('live_status'),
-                            'formats': self._clean_formats(entry.get('formats', [])),
-                            'thumbnails': self._clean_thumbnails(entry.get('thumbnails', []))
-                        }
-                        videos.append(video_info)
+                        videos.append(self._clean_video_info(entry))
 
                 return {
                     'videos': videos,
@@ -298,6 +282,28 @@ class YTDLPService:
             self.logger.error(f"Search error for '{query}': {str(e)}")
             return None
 
+    def _clean_video_info(self, info: Dict, fallback_url: str = '') -> Dict:
+        """Structure yt-dlp info as a VideoInfo (see schema/responses.schema.json)"""
+        return {
+            'id': info.get('id') or '',
+            'title': info.get('title') or '',
+            'description': info.get('description', ''),
+            'duration': info.get('duration'),
+            'webpage_url': info.get('webpage_url') or fallback_url,
+            'thumbnail': self._get_best_thumbnail(info.get('thumbnails') or []),
+            'uploader': info.get('uploader', ''),
+            'upload_date': info.get('upload_date', ''),
+            'view_count': info.get('view_count'),
+            'extractor': info.get('extractor', ''),
+            'extractor_key': info.get('extractor_key', ''),
+            'available': True,
+            'live_status': info.get('live_status'),
+            'tags': info.get('tags', []),
+            'categories': info.get('categories', []),
+            'formats': self._clean_formats(info.get('formats') or []),
+            'thumbnails': self._clean_thumbnails(info.get('thumbnails') or [])
+        }
+
     def _get_best_thumbnail(self, thumbnails: List[Dict]) -> str:
         """Get the best quality thumbnail URL"""
         if not thumbnails:
@@ -306,20 +312,20 @@ class YTDLPService:
         # Sort by preference: width desc, then height desc
         sorted_thumbs = sorted(
             thumbnails,
-            key=lambda x: (x.get('width', 0), x.get('height', 0)),
+            key=lambda x: (x.get('width') or 0, x.get('height') or 0),
             reverse=True
         )
 
-        return sorted_thumbs[0].get('url', '') if sorted_thumbs else ""
+        return (sorted_thumbs[0].get('url') or '') if sorted_thumbs else ""
 
     def _clean_formats(self, formats: List[Dict]) -> List[Dict]:
         """Clean and filter format information"""
         clean_formats = []
         for fmt in formats:
             clean_format = {
-                'format_id': fmt.get('format_id', ''),
-                'url': fmt.get('url', ''),
-                'ext': fmt.get('ext', ''),
+                'format_id': fmt.get('format_id') or '',
+                'url': fmt.get('url') or '',
+                'ext': fmt.get('ext') or '',
                 'format': fmt.get('format', ''),
                 'protocol': fmt.get('protocol'),
                 'vcodec': fmt.get('vcodec'),
@@ -346,7 +352,7 @@ class YTDLPService:
         for thumb in thumbnails:
             clean_thumb = {
                 'id': thumb.get('id'),
-                'url': thumb.get('url', ''),
+                'url': thumb.get('url') or '',
                 'width': thumb.get('width'),
                 'height': thumb.get('height'),
                 'resolution': thumb.get('resolution'),
@@ -355,6 +361,34 @@ class YTDLPService:
 
         return clean_thumbnails
 
+    async def metrics(self, request):
+        """Request counts, cache hits and yt-dlp run times since the service started"""
+        return web.json_response({
+            'success': True,
+            'data': self._metrics()
+        })
+
+    def _metrics(self) -> Dict:
+        """Collect the metrics, mirroring handleMetrics in server.go"""
+        samples = sorted(self.latencies)
+        average = p95 = longest = 0.0
+        if samples:
+            average = sum(samples) / len(samples) * 1000
+            p95 = samples[math.ceil(len(samples) * 0.95) - 1] * 1000
+            longest = samples[-1] * 1000
+
+        return {
+            'uptime_seconds': int((datetime.now() - self.start_time).total_seconds()),
+            'extractions': self.extract_count,
+            'searches': self.search_count,
+            'errors': self.error_count,
+            'cache_hits': self.cache_hits,
+            'cache_misses': self.cache_misses,
+            'latency_avg_ms': float(average),
+            'latency_p95_ms': float(p95),
+            'latency_max_ms': float(longest),
+        }
+
     async def clear_cache(self, request):
         """Clear the service cache"""
         try:
@@ -397,6 +431,7 @@ async def create_app(config: Dict[str, Any]) -> web.Application:
     app.router.add_post('/extract', service.extract_info)
     app.router.add_post('/search', service.search)
     app.router.add_post('/cache/clear', service.clear_cache)
+    app.router.add_get('/metrics', service.metrics)
 
     # Store service instance for cleanup
     app['service'] = service
@@ -422,6 +457,7 @@ def main():
     parser.add_argument('--config', type=str, help='Configuration file path')
     parser.add_argument('--host', type=str, default='localhost', help='Host to bind to')
     parser.add_argument('--port', type=int, default=8080, help='Port to bind to')
+    parser.add_argument('--socket', type=str, help='Unix socket to listen on instead of host and port')
     parser.add_argument('--workers', type=int, default=4, help='Number of worker threads')
 
     args = parser.parse_args()
@@ -451,11 +487,17 @@ def main():
 
         return app
 
-    # Run the service
+    # Run the service, on a unix socket when one is given
+    if args.socket:
+        # A socket left behind by a crashed run would block the new one
+        if os.path.exists(args.socket) and stat.S_ISSOCK(os.stat(args.socket).st_mode):
+            os.remove(args.socket)
+        listen = {'path': args.socket}
+    else:
+        listen = {'host': config['host'], 'port': config['port']}
     web.run_app(
         init(),
-        host=config['host'],
-        port=config['port'],
+        **listen,
         access_log_format='%a %t "%r" %s %b "%{Referer}i" "%{User-Agent}i" %Tf'
     )
 
@@ -0,0 +1,464 @@
+package ytdlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rawVideoInfo is trimmed yt-dlp --dump-single-json output, including fields the service drops
+const rawVideoInfo = `{
+	"id": "dQw4w9WgXcQ",
+	"title": "Never Gonna Give You Up",
+	"description": null,
+	"duration": 212,
+	"webpage_url": "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+	"uploader": "Rick Astley",
+	"upload_date": "20091025",
+	"view_count": 1600000000,
+	"extractor": "youtube",
+	"extractor_key": "Youtube",
+	"live_status": "not_live",
+	"tags": ["rick astley"],
+	"categories": ["Music"],
+	"http_headers": {"User-Agent": "Mozilla/5.0"},
+	"formats": [
+		{"format_id": "251", "url": "https://rr1---sn-example.googlevideo.com/videoplayback?itag=251", "ext": "webm",
+		 "format": "251 - audio only", "vcodec": "none", "acodec": "opus", "abr": 135.5, "asr": 48000, "quality": 3},
+		{"format_id": "sb0", "url": "https://i.ytimg.com/sb/dQw4w9WgXcQ/storyboard3_L0/default.jpg", "ext": "mhtml", "fps": 0.5, "width": null}
+	],
+	"thumbnails": [
+		{"url": "https://i.ytimg.com/vi/dQw4w9WgXcQ/default.jpg", "width": 120, "height": 90},
+		{"url": "https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg", "width": 1920, "height": 1080, "id": "41"},
+		{"url": "https://i.ytimg.com/vi_webp/dQw4w9WgXcQ/maxresdefault.webp"}
+	]
+}`
+
+// fakeRunner stands in for the yt-dlp binary and records how it was called
+type fakeRunner struct {
+	mu     sync.Mutex
+	calls  [][]string
+	output string
+	err    error
+}
+
+func (f *fakeRunner) run(ctx context.Context, args ...string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, args)
+	if len(args) == 1 && args[0] == "--version" {
+		return []byte("2025.09.26\n"), nil
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []byte(f.output), nil
+}
+
+func (f *fakeRunner) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func newTestServer(t *testing.T, runner CommandRunner) *httptest.Server {
+	t.Helper()
+
+	config := DefaultServiceConfig()
+	config.MaxWorkers = 2
+	server := httptest.NewServer(NewServerWithRunner(config, runner).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// postJSON sends a request body to the server and decodes the response envelope generically
+func postJSON(t *testing.T, server *httptest.Server, path, body string) (int, map[string]interface{}) {
+	t.Helper()
+
+	resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var envelope map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+	return resp.StatusCode, envelope
+}
+
+// assertMatchesSchema validates a decoded JSON value against a definition of the shared schema
+func assertMatchesSchema(t *testing.T, defs map[string]schemaDefinition, definition string, value interface{}) {
+	t.Helper()
+
+	object, ok := value.(map[string]interface{})
+	require.True(t, ok, "%s must be an object", definition)
+
+	schema := defs[definition]
+	for _, name := range schema.Required {
+		assert.Contains(t, object, name, "%s is missing %s", definition, name)
+	}
+	for name, field := range object {
+		property, known := schema.Properties[name]
+		if !assert.True(t, known, "%s has unknown property %s", definition, name) {
+			continue
+		}
+		assertPropertyMatches(t, defs, definition+"."+name, property, field)
+	}
+}
+
+func assertPropertyMatches(t *testing.T, defs map[string]schemaDefinition, path string, property schemaProperty, value interface{}) {
+	t.Helper()
+
+	if property.Ref != "" {
+		assertMatchesSchema(t, defs, strings.TrimPrefix(property.Ref, "#/$defs/"), value)
+		return
+	}
+	if property.Type == nil {
+		return
+	}
+
+	var actual string
+	switch typed := value.(type) {
+	case nil:
+		actual = "null"
+	case string:
+		actual = "string"
+	case bool:
+		actual = "boolean"
+	case float64:
+		actual = "number"
+		if typed == math.Trunc(typed) {
+			actual = "integer"
+		}
+	case []interface{}:
+		actual = "array"
+		if property.Items != nil {
+			for _, item := range typed {
+				assertPropertyMatches(t, defs, path+"[]", *property.Items, item)
+			}
+		}
+	case map[string]interface{}:
+		actual = "object"
+	}
+
+	allowed := property.types()
+	if actual == "integer" && !containsString(allowed, "integer") {
+		actual = "number"
+	}
+	assert.Contains(t, allowed, actual, "%s has type %s", path, actual)
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServerExtract(t *testing.T) {
+	defs := loadContractSchema(t)
+	runner := &fakeRunner{output: rawVideoInfo}
+	server := newTestServer(t, runner.run)
+
+	status, envelope := postJSON(t, server, "/extract", `{"url": "https://youtu.be/dQw4w9WgXcQ"}`)
+	require.Equal(t, http.StatusOK, status)
+	assertMatchesSchema(t, defs, "ServiceResponse", envelope)
+	assertMatchesSchema(t, defs, "VideoInfo", envelope["data"])
+
+	video := envelope["data"].(map[string]interface{})
+	assert.Equal(t, "https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg", video["thumbnail"])
+	assert.Equal(t, float64(1600000000), video["view_count"])
+	assert.Nil(t, video["description"])
+
+	require.Equal(t, 1, runner.callCount())
+	args := runner.calls[0]
+	assert.Contains(t, args, "--dump-single-json")
+	assert.Equal(t, []string{"-f", "bestaudio/best", "--no-playlist", "--", "https://youtu.be/dQw4w9WgXcQ"}, args[len(args)-5:])
+
+	// The same URL is answered from the cache
+	status, _ = postJSON(t, server, "/extract", `{"url": "https://youtu.be/dQw4w9WgXcQ"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 1, runner.callCount())
+
+	// The client parses what the server emits
+	config := DefaultServiceConfig()
+	config.Host, config.Port = splitTestHost(t, server.URL)
+	parsed, err := NewClient(config).ExtractInfo(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.Equal(t, "Never Gonna Give You Up", parsed.Title)
+	assert.Equal(t, float64(212), parsed.Duration)
+	assert.Equal(t, int64(1600000000), parsed.ViewCount)
+	require.Len(t, parsed.Formats, 2)
+	assert.Equal(t, 135.5, parsed.Formats[0].ABR)
+}
+
+func TestServerRotatesProxies(t *testing.T) {
+	runner := &fakeRunner{output: rawVideoInfo}
+	config := DefaultServiceConfig()
+	config.Proxies = []string{"http://one:3128", "http://two:3128"}
+	server := NewServerWithRunner(config, runner.run)
+
+	for range 2 {
+		_, err := server.runJSON(context.Background(), "--", "https://youtu.be/dQw4w9WgXcQ")
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, runner.callCount())
+	assert.Contains(t, runner.calls[0], "http://one:3128")
+	assert.Contains(t, runner.calls[1], "http://two:3128")
+}
+
+func TestServerExtractMissingMetadata(t *testing.T) {
+	defs := loadContractSchema(t)
+	server := newTestServer(t, (&fakeRunner{output: `{"id": "abc"}`}).run)
+
+	status, envelope := postJSON(t, server, "/extract", `{"url": "https://youtu.be/abc"}`)
+	require.Equal(t, http.StatusOK, status)
+	assertMatchesSchema(t, defs, "VideoInfo", envelope["data"])
+	assert.Equal(t, "https://youtu.be/abc", envelope["data"].(map[string]interface{})["webpage_url"])
+}
+
+func TestServerExtractErrors(t *testing.T) {
+	defs := loadContractSchema(t)
+
+	tests := []struct {
+		name         string
+		body         string
+		runner       *fakeRunner
+		expectStatus int
+		expectError  string
+	}{
+		{
+			name:         "missing url",
+			body:         `{}`,
+			runner:       &fakeRunner{},
+			expectStatus: http.StatusBadRequest,
+			expectError:  "URL is required",
+		},
+		{
+			name:         "malformed body",
+			body:         `{"url":`,
+			runner:       &fakeRunner{},
+			expectStatus: http.StatusBadRequest,
+			expectError:  "Invalid request body",
+		},
+		{
+			name:         "yt-dlp failure",
+			body:         `{"url": "https://youtu.be/private"}`,
+			runner:       &fakeRunner{err: errors.New("ERROR: Private video")},
+			expectStatus: http.StatusNotFound,
+			expectError:  "Failed to extract video information",
+		},
+		{
+			name:         "unparseable output",
+			body:         `{"url": "https://youtu.be/abc"}`,
+			runner:       &fakeRunner{output: "null"},
+			expectStatus: http.StatusNotFound,
+			expectError:  "Failed to extract video information",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, tt.runner.run)
+
+			status, envelope := postJSON(t, server, "/extract", tt.body)
+			assert.Equal(t, tt.expectStatus, status)
+			assertMatchesSchema(t, defs, "ServiceResponse", envelope)
+			assert.Equal(t, false, envelope["success"])
+			assert.Equal(t, tt.expectError, envelope["error"])
+			assert.Equal(t, float64(tt.expectStatus), envelope["code"])
+		})
+	}
+}
+
+func TestServerSearch(t *testing.T) {
+	defs := loadContractSchema(t)
+	runner := &fakeRunner{output: `{"entries": [` + rawVideoInfo + `, null, ` + rawVideoInfo + `]}`}
+	server := newTestServer(t, runner.run)
+
+	status, envelope := postJSON(t, server, "/search", `{"query": "never gonna give you up", "max_results": 5}`)
+	require.Equal(t, http.StatusOK, status)
+	assertMatchesSchema(t, defs, "SearchResult", envelope["data"])
+
+	result := envelope["data"].(map[string]interface{})
+	assert.Equal(t, float64(2), result["total_count"])
+	assert.Equal(t, "ytsearch5:never gonna give you up", runner.calls[0][len(runner.calls[0])-1])
+
+	// Oversized requests are capped
+	postJSON(t, server, "/search", `{"query": "rick", "max_results": 500}`)
+	assert.Equal(t, "ytsearch50:rick", runner.calls[1][len(runner.calls[1])-1])
+
+	status, envelope = postJSON(t, server, "/search", `{"max_results": 5}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Query is required", envelope["error"])
+}
+
+func TestServerSearchEmptyAndFailure(t *testing.T) {
+	defs := loadContractSchema(t)
+
+	server := newTestServer(t, (&fakeRunner{output: `{"id": "ytsearch5:nothing"}`}).run)
+	status, envelope := postJSON(t, server, "/search", `{"query": "nothing"}`)
+	require.Equal(t, http.StatusOK, status)
+	assertMatchesSchema(t, defs, "SearchResult", envelope["data"])
+	assert.Equal(t, float64(0), envelope["data"].(map[string]interface{})["total_count"])
+
+	failing := newTestServer(t, (&fakeRunner{err: errors.New("network unreachable")}).run)
+	status, envelope = postJSON(t, failing, "/search", `{"query": "rick"}`)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "Search failed", envelope["error"])
+}
+
+func TestServerHealthAndCacheClear(t *testing.T) {
+	runner := &fakeRunner{output: rawVideoInfo}
+	server := newTestServer(t, runner.run)
+
+	postJSON(t, server, "/extract", `{"url": "https://youtu.be/dQw4w9WgXcQ"}`)
+	status, envelope := postJSON(t, server, "/cache/clear", ``)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Cache cleared successfully", envelope["data"].(map[string]interface{})["message"])
+
+	// A cleared cache runs yt-dlp again
+	postJSON(t, server, "/extract", `{"url": "https://youtu.be/dQw4w9WgXcQ"}`)
+	assert.Equal(t, 2, runner.callCount())
+
+	config := DefaultServiceConfig()
+	config.Host, config.Port = splitTestHost(t, server.URL)
+	health, err := NewClient(config).HealthCheck(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", health.Status)
+	assert.Equal(t, "2025.09.26", health.Version)
+	assert.Equal(t, 2, health.WorkerCount)
+
+	resp, err := http.Post(server.URL+"/health", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestServerListensOnUnixSocket(t *testing.T) {
+	config := DefaultServiceConfig()
+	config.Socket = filepath.Join(t.TempDir(), "ytdlp.sock")
+	runner := &fakeRunner{output: rawVideoInfo}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- NewServerWithRunner(config, runner.run).ListenAndServe(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-served)
+	}()
+
+	client := NewClient(config)
+	require.Eventually(t, func() bool {
+		_, err := client.HealthCheck(context.Background())
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+	info, err := client.ExtractInfo(context.Background(), "https://youtu.be/dQw4w9WgXcQ")
+	require.NoError(t, err)
+	assert.Equal(t, "dQw4w9WgXcQ", info.ID)
+}
+
+func TestServerMetrics(t *testing.T) {
+	defs := loadContractSchema(t)
+	runner := &fakeRunner{output: rawVideoInfo}
+	server := newTestServer(t, runner.run)
+
+	postJSON(t, server, "/extract", `{"url": "https://youtu.be/dQw4w9WgXcQ"}`)
+	postJSON(t, server, "/extract", `{"url": "https://youtu.be/dQw4w9WgXcQ"}`)
+	runner.err = errors.New("HTTP Error 429")
+	postJSON(t, server, "/search", `{"query": "rick"}`)
+
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var envelope map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&envelope))
+	assertMatchesSchema(t, defs, "ServiceResponse", envelope)
+	assertMatchesSchema(t, defs, "ServiceMetrics", envelope["data"])
+
+	config := DefaultServiceConfig()
+	config.Host, config.Port = splitTestHost(t, server.URL)
+	metrics, err := NewClient(config).Metrics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), metrics.Extractions)
+	assert.Equal(t, int64(1), metrics.Searches)
+	assert.Equal(t, int64(1), metrics.Errors)
+	assert.Equal(t, int64(1), metrics.CacheHits)
+	assert.Equal(t, int64(2), metrics.CacheMisses)
+	assert.InDelta(t, 1.0/3, metrics.ErrorRate(), 0.001)
+	assert.InDelta(t, 1.0/3, metrics.CacheHitRatio(), 0.001)
+	assert.GreaterOrEqual(t, metrics.LatencyMaxMs, metrics.LatencyAvgMs)
+}
+
+func TestServerLimitsWorkers(t *testing.T) {
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	runner := func(ctx context.Context, args ...string) ([]byte, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		<-release
+		return []byte(rawVideoInfo), nil
+	}
+	server := newTestServer(t, runner)
+
+	var wg sync.WaitGroup
+	for index := 0; index < 5; index++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			body := `{"url": "https://youtu.be/` + string(rune('a'+index)) + `"}`
+			status, _ := postJSON(t, server, "/extract", body)
+			assert.Equal(t, http.StatusOK, status)
+		}(index)
+	}
+
+	require.Eventually(t, func() bool { return running.Load() == 2 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), peak.Load())
+}
+
+func TestBestThumbnail(t *testing.T) {
+	assert.Equal(t, "", bestThumbnail(nil))
+	assert.Equal(t, "tall", bestThumbnail([]map[string]interface{}{
+		{"url": "small", "width": json.Number("120"), "height": json.Number("90")},
+		{"url": "wide", "width": json.Number("1280"), "height": json.Number("360")},
+		{"url": "tall", "width": json.Number("1280"), "height": json.Number("720")},
+		{"url": "same", "width": json.Number("1280"), "height": json.Number("720")},
+		{"url": "unsized"},
+	}))
+}
+
+// splitTestHost splits an httptest server URL into the host and port the client config expects
+func splitTestHost(t *testing.T, serverURL string) (string, int) {
+	t.Helper()
+
+	host, portText, err := net.SplitHostPort(strings.TrimPrefix(serverURL, "http://"))
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portText)
+	require.NoError(t, err)
+	return host, port
+}
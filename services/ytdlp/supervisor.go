@@ -0,0 +1,128 @@
+package ytdlp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// RestartPolicy decides how ServiceManager restarts a service that exited without being stopped
+type RestartPolicy struct {
+	MaxRestarts   int           // Restarts in a row before giving up, 0 never restarts and a negative value restarts forever
+	InitialDelay  time.Duration // Delay before the first restart
+	MaxDelay      time.Duration // Longest delay between restarts
+	BackoffFactor float64       // Growth of the delay with each restart in a row
+	ResetAfter    time.Duration // A service running this long counts as recovered, its next exit starts over
+}
+
+// DefaultRestartPolicy restarts a service 5 times in a row, waiting 2s, 4s, 8s, 16s and 32s
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		MaxRestarts:   5,
+		InitialDelay:  2 * time.Second,
+		MaxDelay:      2 * time.Minute,
+		BackoffFactor: 2,
+		ResetAfter:    10 * time.Minute,
+	}
+}
+
+// delay returns how long to wait before a restart, 1 for the first in a row
+func (p RestartPolicy) delay(attempt int) time.Duration {
+	factor := p.BackoffFactor
+	if factor < 1 {
+		factor = 1
+	}
+	delay := time.Duration(float64(p.InitialDelay) * math.Pow(factor, float64(attempt-1)))
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay < 0) {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// allows reports whether a restart is within the policy's limit
+func (p RestartPolicy) allows(attempt int) bool {
+	return p.MaxRestarts < 0 || attempt <= p.MaxRestarts
+}
+
+// SetRestartPolicy changes how the service is restarted after an unexpected exit
+func (sm *ServiceManager) SetRestartPolicy(policy RestartPolicy) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.restartPolicy = policy
+}
+
+// Restarts returns how many times in a row the service was restarted after exiting on its own
+func (sm *ServiceManager) Restarts() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.restarts
+}
+
+// notify reports a service error on the error channel, dropping it when nobody keeps up
+func (sm *ServiceManager) notify(err error) {
+	select {
+	case sm.errorChan <- err:
+	default:
+		// Channel is full, skip
+	}
+}
+
+// crashed reports an unexpected exit and starts restarting the service in the background
+func (sm *ServiceManager) crashed(err error) {
+	sm.notify(err)
+	utils.SafeGo("ytdlp.supervise", sm.supervise)
+}
+
+// supervise restarts a service that exited on its own, waiting longer after each failed restart, until
+// it runs again, the policy gives up or the service is stopped. Each step is reported on the error channel.
+func (sm *ServiceManager) supervise() {
+	for {
+		sm.mu.Lock()
+		policy := sm.restartPolicy
+		if policy.ResetAfter > 0 && !sm.startedAt.IsZero() && time.Since(sm.startedAt) >= policy.ResetAfter {
+			sm.restarts = 0
+		}
+		sm.startedAt = time.Time{}
+		sm.restarts++
+		attempt := sm.restarts
+		stop := sm.stopChan
+		sm.mu.Unlock()
+
+		if !policy.allows(attempt) {
+			if policy.MaxRestarts > 0 {
+				sm.notify(fmt.Errorf("gave up restarting the yt-dlp service after %d restarts", policy.MaxRestarts))
+			}
+			return
+		}
+
+		delay := policy.delay(attempt)
+		log.Printf("[SERVICE] Restarting in %s (restart %d)", delay, attempt)
+		sm.notify(fmt.Errorf("restarting the yt-dlp service in %s (restart %d)", delay, attempt))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-stop:
+			timer.Stop()
+			return
+		}
+
+		// Stopped, or started by someone else, while waiting
+		if sm.GetStatus() != StatusError {
+			return
+		}
+
+		// The service outlives whoever started it first, so it isn't tied to their context
+		err := sm.Restart(context.Background())
+		if err == nil {
+			log.Printf("[SERVICE] Service recovered after restart %d", attempt)
+			return
+		}
+		log.Printf("[SERVICE] Restart %d failed: %v", attempt, err)
+		sm.notify(fmt.Errorf("restart %d of the yt-dlp service failed: %w", attempt, err))
+	}
+}
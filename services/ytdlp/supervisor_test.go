@@ -0,0 +1,76 @@
+package ytdlp
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartPolicyDelay(t *testing.T) {
+	policy := DefaultRestartPolicy()
+	assert.Equal(t, 2*time.Second, policy.delay(1))
+	assert.Equal(t, 4*time.Second, policy.delay(2))
+	assert.Equal(t, 32*time.Second, policy.delay(5))
+	assert.Equal(t, 2*time.Minute, policy.delay(10), "delays are capped")
+	assert.Equal(t, 2*time.Minute, policy.delay(200), "huge delays don't overflow")
+
+	assert.True(t, policy.allows(5))
+	assert.False(t, policy.allows(6))
+	assert.True(t, RestartPolicy{MaxRestarts: -1}.allows(1000))
+	assert.False(t, RestartPolicy{}.allows(1))
+}
+
+// receiveError waits for the next error the manager reports
+func receiveError(t *testing.T, manager *ServiceManager) error {
+	t.Helper()
+	select {
+	case err := <-manager.GetErrors():
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("no error was reported")
+		return nil
+	}
+}
+
+func TestSupervisorWaitsAndStopsWithTheService(t *testing.T) {
+	manager := NewServiceManager(nil)
+	manager.SetRestartPolicy(RestartPolicy{MaxRestarts: 3, InitialDelay: time.Hour, BackoffFactor: 2})
+	atomic.StoreInt32(&manager.status, int32(StatusError))
+
+	manager.crashed(fmt.Errorf("service process exited unexpectedly"))
+	assert.ErrorContains(t, receiveError(t, manager), "exited unexpectedly")
+	assert.ErrorContains(t, receiveError(t, manager), "restarting the yt-dlp service in 1h0m0s (restart 1)")
+	assert.Equal(t, 1, manager.Restarts())
+
+	require.NoError(t, manager.Stop(t.Context()))
+	assert.Equal(t, StatusStopped, manager.GetStatus(), "a stopped service isn't restarted")
+}
+
+func TestSupervisorGivesUp(t *testing.T) {
+	manager := NewServiceManager(nil)
+	manager.SetRestartPolicy(RestartPolicy{MaxRestarts: 2, InitialDelay: time.Hour})
+	manager.restarts = 2
+	atomic.StoreInt32(&manager.status, int32(StatusError))
+
+	manager.crashed(fmt.Errorf("go server exited unexpectedly"))
+	receiveError(t, manager)
+	assert.ErrorContains(t, receiveError(t, manager), "gave up restarting the yt-dlp service after 2 restarts")
+	assert.Equal(t, StatusError, manager.GetStatus())
+}
+
+func TestSupervisorStartsOverAfterRunningLongEnough(t *testing.T) {
+	manager := NewServiceManager(nil)
+	manager.SetRestartPolicy(RestartPolicy{MaxRestarts: 2, InitialDelay: time.Hour, ResetAfter: time.Minute})
+	manager.restarts = 2
+	manager.startedAt = time.Now().Add(-time.Hour)
+	atomic.StoreInt32(&manager.status, int32(StatusError))
+
+	manager.crashed(fmt.Errorf("service process exited unexpectedly"))
+	receiveError(t, manager)
+	assert.ErrorContains(t, receiveError(t, manager), "(restart 1)")
+	require.NoError(t, manager.Stop(t.Context()))
+}
@@ -0,0 +1,164 @@
+#!/usr/bin/env python3
+"""
+Contract tests for the yt-dlp service responses.
+Validates what server.py emits against schema/responses.schema.json, the same definitions
+the Go client is checked against in contract_test.go.
+
+Run from the repository root: python3 -m unittest discover -s services/ytdlp -p 'test_*.py'
+"""
+
+import json
+import tempfile
+import unittest
+from pathlib import Path
+from unittest import mock
+
+import jsonschema
+
+import server
+
+SERVICE_DIR = Path(__file__).resolve().parent
+SCHEMA = json.loads((SERVICE_DIR / 'schema' / 'responses.schema.json').read_text())
+FIXTURE = json.loads((SERVICE_DIR / 'testdata' / 'video_info.json').read_text())
+
+# Raw yt-dlp output carries many fields the service does not pass on
+RAW_INFO = {
+    'id': 'dQw4w9WgXcQ',
+    'title': 'Never Gonna Give You Up',
+    'description': None,
+    'duration': 212,
+    'webpage_url': 'https://www.youtube.com/watch?v=dQw4w9WgXcQ',
+    'uploader': 'Rick Astley',
+    'upload_date': '20091025',
+    'view_count': 1600000000,
+    'extractor': 'youtube',
+    'extractor_key': 'Youtube',
+    'live_status': 'not_live',
+    'tags': ['rick astley'],
+    'categories': ['Music'],
+    'http_headers': {'User-Agent': 'Mozilla/5.0'},
+    'formats': [
+        {
+            'format_id': '251',
+            'url': 'https://rr1---sn-example.googlevideo.com/videoplayback?itag=251',
+            'ext': 'webm',
+            'format': '251 - audio only',
+            'vcodec': 'none',
+            'acodec': 'opus',
+            'abr': 135.5,
+            'asr': 48000,
+            'quality': 3,
+            'http_headers': {'User-Agent': 'Mozilla/5.0'},
+        },
+        {
+            'format_id': 'sb0',
+            'url': 'https://i.ytimg.com/sb/dQw4w9WgXcQ/storyboard3_L0/default.jpg',
+            'ext': 'mhtml',
+            'fps': 0.5,
+            'width': None,
+        },
+    ],
+    'thumbnails': [
+        {'url': 'https://i.ytimg.com/vi/dQw4w9WgXcQ/default.jpg', 'width': 120, 'height': 90},
+        {'url': 'https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg', 'width': 1920, 'height': 1080, 'id': '41'},
+        {'url': 'https://i.ytimg.com/vi_webp/dQw4w9WgXcQ/maxresdefault.webp'},
+    ],
+}
+
+
+def validate(instance, definition):
+    """Validate an instance against one of the shared schema definitions"""
+    schema = {'$defs': SCHEMA['$defs'], '$ref': f'#/$defs/{definition}'}
+    jsonschema.Draft202012Validator(schema).validate(instance)
+
+
+class FakeYoutubeDL:
+    """Stands in for yt_dlp.YoutubeDL and returns canned info"""
+
+    def __init__(self, info):
+        self.info = info
+
+    def __call__(self, opts):
+        return self
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *args):
+        return False
+
+    def extract_info(self, url, download=False):
+        return self.info
+
+
+class ServerContractTest(unittest.TestCase):
+
+    def setUp(self):
+        self.cache_dir = tempfile.TemporaryDirectory()
+        self.service = server.YTDLPService({'cache_dir': self.cache_dir.name})
+
+    def tearDown(self):
+        self.service.executor.shutdown(wait=False)
+        self.cache_dir.cleanup()
+
+    def test_schema_is_valid(self):
+        jsonschema.Draft202012Validator.check_schema(SCHEMA)
+
+    def test_fixture_matches_schema(self):
+        # The Go contract tests parse the same fixture
+        validate(FIXTURE, 'VideoInfo')
+
+    def test_clean_video_info_matches_schema(self):
+        video = self.service._clean_video_info(RAW_INFO, 'https://youtu.be/dQw4w9WgXcQ')
+        validate(video, 'VideoInfo')
+        self.assertEqual(video['thumbnail'], 'https://i.ytimg.com/vi/dQw4w9WgXcQ/maxresdefault.jpg')
+
+    def test_clean_video_info_with_missing_metadata(self):
+        video = self.service._clean_video_info({'id': 'abc'}, 'https://youtu.be/abc')
+        validate(video, 'VideoInfo')
+        self.assertEqual(video['webpage_url'], 'https://youtu.be/abc')
+
+    def test_extract_matches_schema(self):
+        with mock.patch.object(server.yt_dlp, 'YoutubeDL', FakeYoutubeDL(RAW_INFO)):
+            video = self.service._extract_info_sync('https://youtu.be/dQw4w9WgXcQ')
+
+        self.assertIsNotNone(video)
+        validate(video, 'VideoInfo')
+
+    def test_search_matches_schema(self):
+        results = {'entries': [RAW_INFO, None, dict(RAW_INFO, id='second')]}
+        with mock.patch.object(server.yt_dlp, 'YoutubeDL', FakeYoutubeDL(results)):
+            result = self.service._search_sync('never gonna give you up', 5)
+
+        validate(result, 'SearchResult')
+        self.assertEqual(result['total_count'], 2)
+
+    def test_empty_search_matches_schema(self):
+        with mock.patch.object(server.yt_dlp, 'YoutubeDL', FakeYoutubeDL({})):
+            result = self.service._search_sync('nothing', 5)
+
+        validate(result, 'SearchResult')
+
+    def test_added_field_breaks_contract(self):
+        video = self.service._clean_video_info(RAW_INFO)
+        video['channel_id'] = 'UCuAXFkgsw1L7xaCfnd5JJOw'
+
+        with self.assertRaises(jsonschema.ValidationError):
+            validate(video, 'VideoInfo')
+
+    def test_metrics_match_schema(self):
+        validate(self.service._metrics(), 'ServiceMetrics')
+
+        with mock.patch.object(server.yt_dlp, 'YoutubeDL', FakeYoutubeDL(RAW_INFO)):
+            self.service._extract_info_sync('https://youtu.be/dQw4w9WgXcQ')
+
+        metrics = self.service._metrics()
+        validate(metrics, 'ServiceMetrics')
+        self.assertGreaterEqual(metrics['latency_max_ms'], metrics['latency_avg_ms'])
+
+    def test_error_response_matches_schema(self):
+        validate({'success': False, 'error': 'URL is required', 'code': 400}, 'ServiceResponse')
+
+
+if __name__ == '__main__':
+    unittest.main()
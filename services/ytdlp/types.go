@@ -1,6 +1,8 @@
 package ytdlp
 
 import (
+	"net"
+	"strconv"
 	"time"
 )
 
@@ -9,6 +11,7 @@ type ServiceConfig struct {
 	// Service settings
 	Host        string        `json:"host"`
 	Port        int           `json:"port"`
+	Socket      string        `json:"socket,omitempty"` // Unix socket listened on and connected to instead of Host and Port
 	MaxWorkers  int           `json:"max_workers"`
 	Timeout     time.Duration `json:"timeout"`
 	MaxRetries  int           `json:"max_retries"`
@@ -17,6 +20,10 @@ type ServiceConfig struct {
 	Format      string `json:"format"`
 	AudioFormat string `json:"audio_format"`
 	AudioQuality string `json:"audio_quality"`
+	BinaryPath   string `json:"binary_path"` // yt-dlp executable used by the Go server
+	Credentials  Credentials `json:"credentials"` // Cookies and PO token passed to every yt-dlp run
+	Proxy        string      `json:"proxy,omitempty"`   // Proxy every yt-dlp run goes through, such as socks5://host:1080
+	Proxies      []string    `json:"proxies,omitempty"` // Proxies yt-dlp runs take turns between, after Proxy
 
 	// Rate limiting
 	RateLimit      string `json:"rate_limit"`
@@ -31,6 +38,15 @@ type ServiceConfig struct {
 	HealthCheckInterval time.Duration `json:"health_check_interval"`
 }
 
+// Address returns where the service listens, host:port with IPv6 hosts in brackets, or unix:path when it
+// uses a socket
+func (config *ServiceConfig) Address() string {
+	if config.Socket != "" {
+		return "unix:" + config.Socket
+	}
+	return net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+}
+
 // DefaultServiceConfig returns a default configuration
 func DefaultServiceConfig() *ServiceConfig {
 	return &ServiceConfig{
@@ -43,6 +59,7 @@ func DefaultServiceConfig() *ServiceConfig {
 		Format:      "bestaudio/best",
 		AudioFormat: "opus",
 		AudioQuality: "128K",
+		BinaryPath:   "yt-dlp",
 
 		RateLimit:     "1M",
 		SleepInterval: "0",
@@ -106,7 +123,7 @@ type FormatInfo struct {
 	ABR        float64 `json:"abr,omitempty"`
 	ASR        int     `json:"asr,omitempty"`
 	Filesize   int64   `json:"filesize,omitempty"`
-	Quality    int     `json:"quality,omitempty"`
+	Quality    float64 `json:"quality,omitempty"`
 	Language   string  `json:"language,omitempty"`
 	Preference int     `json:"preference,omitempty"`
 }
@@ -145,6 +162,31 @@ type HealthStatus struct {
 	QueueSize   int       `json:"queue_size,omitempty"`
 }
 
+// HealthData is the data of a /health response
+type HealthData struct {
+	Status       string `json:"status"`
+	Version      string `json:"version"`
+	Uptime       string `json:"uptime"`
+	RequestCount int64  `json:"request_count"`
+	ErrorCount   int64  `json:"error_count"`
+	WorkerCount  int    `json:"worker_count"`
+	QueueSize    int    `json:"queue_size,omitempty"`
+	LastCheck    string `json:"last_check"` // Service's clock in ISO 8601, Python leaves out the time zone
+}
+
+// ServiceMetrics is the data of a /metrics response, counted since the service started
+type ServiceMetrics struct {
+	UptimeSeconds int64   `json:"uptime_seconds"`
+	Extractions   int64   `json:"extractions"`    // /extract requests
+	Searches      int64   `json:"searches"`       // /search requests
+	Errors        int64   `json:"errors"`         // Extract and search requests that failed
+	CacheHits     int64   `json:"cache_hits"`     // Requests answered from the response cache
+	CacheMisses   int64   `json:"cache_misses"`   // Requests that ran yt-dlp
+	LatencyAvgMs  float64 `json:"latency_avg_ms"` // Run time of the last 100 yt-dlp runs
+	LatencyP95Ms  float64 `json:"latency_p95_ms"`
+	LatencyMaxMs  float64 `json:"latency_max_ms"`
+}
+
 // ServiceError represents an error from the yt-dlp service
 type ServiceError struct {
 	Code    int    `json:"code"`
@@ -0,0 +1,246 @@
+package ytdlp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+)
+
+// How Updater installs a newer yt-dlp
+const (
+	UpdateBinary = "binary" // Replace the standalone executable with the latest release's
+	UpdatePip    = "pip"    // pip install --upgrade the yt-dlp package
+)
+
+// DefaultReleaseURL is the GitHub API endpoint of the latest yt-dlp release
+const DefaultReleaseURL = "https://api.github.com/repos/yt-dlp/yt-dlp/releases/latest"
+
+// maxBinarySize bounds a downloaded yt-dlp executable, the standalone builds are around 35 MB
+const maxBinarySize = 200 << 20
+
+// UpdateCheck is the outcome of an update run
+type UpdateCheck struct {
+	Installed string // Version installed after the run
+	Latest    string // Version of the latest release
+	Updated   bool   // Whether the run installed the latest release
+}
+
+// Updater keeps yt-dlp current, YouTube changes often break older releases. It compares the installed
+// version with the latest release and upgrades the pip package or replaces the standalone binary.
+type Updater struct {
+	method      string
+	binaryPath  string
+	releaseURL  string
+	downloadURL string
+	client      *http.Client
+	command     func(ctx context.Context, name string, args ...string) ([]byte, error)
+	upgrade     func(ctx context.Context, pkg string) error
+}
+
+// NewUpdater creates an updater for the yt-dlp binary at binaryPath, installed with method
+func NewUpdater(method, binaryPath string) (*Updater, error) {
+	if method != UpdateBinary && method != UpdatePip {
+		return nil, fmt.Errorf("unknown yt-dlp update method %q, use %s or %s", method, UpdateBinary, UpdatePip)
+	}
+	return &Updater{
+		method:      method,
+		binaryPath:  binaryPath,
+		releaseURL:  DefaultReleaseURL,
+		downloadURL: defaultDownloadURL(),
+		client:      httpclient.New(5 * time.Minute),
+		command:     runCommand,
+		upgrade:     NewServiceUtils().UpgradePackage,
+	}, nil
+}
+
+// Method returns how the updater installs a newer yt-dlp
+func (u *Updater) Method() string {
+	return u.method
+}
+
+// Run installs the latest release when it is newer than the installed version
+func (u *Updater) Run(ctx context.Context) (UpdateCheck, error) {
+	installed, err := u.InstalledVersion(ctx)
+	if err != nil {
+		return UpdateCheck{}, err
+	}
+	latest, err := u.LatestVersion(ctx)
+	if err != nil {
+		return UpdateCheck{Installed: installed}, err
+	}
+
+	check := UpdateCheck{Installed: installed, Latest: latest}
+	if !NewerVersion(latest, installed) {
+		return check, nil
+	}
+
+	if u.method == UpdatePip {
+		err = u.upgrade(ctx, "yt-dlp")
+	} else {
+		err = u.replaceBinary(ctx)
+	}
+	if err != nil {
+		return check, fmt.Errorf("failed to update yt-dlp from %s to %s: %w", installed, latest, err)
+	}
+
+	if check.Installed, err = u.InstalledVersion(ctx); err != nil {
+		return check, fmt.Errorf("yt-dlp was updated but doesn't run: %w", err)
+	}
+	check.Updated = true
+	return check, nil
+}
+
+// InstalledVersion asks the binary, or the pip package's module, for its version
+func (u *Updater) InstalledVersion(ctx context.Context) (string, error) {
+	var output []byte
+	var err error
+	if u.method == UpdatePip {
+		output, err = u.command(ctx, "python3", "-c", "import yt_dlp; print(yt_dlp.version.__version__)")
+	} else {
+		output, err = u.command(ctx, u.binaryPath, "--version")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the installed yt-dlp version: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// LatestVersion returns the tag of the latest yt-dlp release
+func (u *Updater) LatestVersion(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.releaseURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create release request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the latest yt-dlp release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("latest yt-dlp release lookup failed with status %d", resp.StatusCode)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to parse the latest yt-dlp release: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("latest yt-dlp release has no tag")
+	}
+	return release.TagName, nil
+}
+
+// replaceBinary downloads the latest standalone executable next to the installed one, checks that it
+// runs and moves it into place, so yt-dlp runs never see a half-written file
+func (u *Updater) replaceBinary(ctx context.Context) error {
+	path, err := exec.LookPath(u.binaryPath)
+	if err != nil {
+		return err
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.downloadURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".yt-dlp-update-*")
+	if err != nil {
+		return fmt.Errorf("can't write next to %s: %w", path, err)
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath)
+
+	written, err := io.Copy(file, io.LimitReader(resp.Body, maxBinarySize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	if written > maxBinarySize {
+		return fmt.Errorf("download is larger than %d MB", maxBinarySize>>20)
+	}
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		return err
+	}
+	if _, err := u.command(ctx, tmpPath, "--version"); err != nil {
+		return fmt.Errorf("downloaded yt-dlp doesn't run: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// NewerVersion reports whether candidate is a later yt-dlp version than current. Versions are dates
+// such as 2025.01.15, nightly builds add a fourth number.
+func NewerVersion(candidate, current string) bool {
+	a, b := versionParts(candidate), versionParts(current)
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// versionParts splits a version into its numbers, a part that isn't one counts as 0
+func versionParts(version string) []int {
+	fields := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	parts := make([]int, len(fields))
+	for i, field := range fields {
+		parts[i], _ = strconv.Atoi(field)
+	}
+	return parts
+}
+
+// defaultDownloadURL returns the latest release's standalone executable for this platform. Elsewhere
+// the zipapp is used, which needs Python.
+func defaultDownloadURL() string {
+	const base = "https://github.com/yt-dlp/yt-dlp/releases/latest/download/"
+	switch {
+	case runtime.GOOS == "linux" && runtime.GOARCH == "amd64":
+		return base + "yt-dlp_linux"
+	case runtime.GOOS == "linux" && runtime.GOARCH == "arm64":
+		return base + "yt-dlp_linux_aarch64"
+	case runtime.GOOS == "darwin":
+		return base + "yt-dlp_macos"
+	default:
+		return base + "yt-dlp"
+	}
+}
+
+// runCommand runs a program and returns its output, with its error output in the error
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return BinaryRunner(name)(ctx, args...)
+}
@@ -0,0 +1,126 @@
+package ytdlp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestUpdater creates an updater against a fake release server. The installed version is read from
+// *installed, and a finished update moves it to the release's tag.
+func newTestUpdater(t *testing.T, method, binaryPath, tag string, installed *string) (*Updater, *[]string) {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/download" {
+			fmt.Fprint(w, "#!/bin/sh\necho "+tag+"\n")
+			return
+		}
+		fmt.Fprintf(w, `{"tag_name": %q}`, tag)
+	}))
+	t.Cleanup(server.Close)
+
+	updater, err := NewUpdater(method, binaryPath)
+	require.NoError(t, err)
+	updater.releaseURL = server.URL + "/latest"
+	updater.downloadURL = server.URL + "/download"
+
+	var upgrades []string
+	updater.command = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return []byte(*installed + "\n"), nil
+	}
+	updater.upgrade = func(ctx context.Context, pkg string) error {
+		upgrades = append(upgrades, pkg)
+		*installed = tag
+		return nil
+	}
+	return updater, &upgrades
+}
+
+func TestUpdaterRunWithPip(t *testing.T) {
+	installed := "2024.12.23"
+	updater, upgrades := newTestUpdater(t, UpdatePip, "yt-dlp", "2025.01.15", &installed)
+
+	check, err := updater.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, UpdateCheck{Installed: "2025.01.15", Latest: "2025.01.15", Updated: true}, check)
+	assert.Equal(t, []string{"yt-dlp"}, *upgrades)
+
+	check, err = updater.Run(context.Background())
+	require.NoError(t, err)
+	assert.False(t, check.Updated, "the latest release is installed")
+	assert.Len(t, *upgrades, 1)
+}
+
+func TestUpdaterReplacesBinary(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "yt-dlp")
+	require.NoError(t, os.WriteFile(binary, []byte("old"), 0o755))
+
+	installed := "2024.12.23"
+	updater, _ := newTestUpdater(t, UpdateBinary, binary, "2025.01.15", &installed)
+
+	check, err := updater.Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, check.Updated)
+
+	data, err := os.ReadFile(binary)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "echo 2025.01.15")
+	entries, err := os.ReadDir(filepath.Dir(binary))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary download is gone")
+}
+
+func TestUpdaterKeepsBinaryThatDoesntRun(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "yt-dlp")
+	require.NoError(t, os.WriteFile(binary, []byte("old"), 0o755))
+
+	installed := "2024.12.23"
+	updater, _ := newTestUpdater(t, UpdateBinary, binary, "2025.01.15", &installed)
+	updater.command = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		if name != binary {
+			return nil, fmt.Errorf("exec format error")
+		}
+		return []byte(installed), nil
+	}
+
+	_, err := updater.Run(context.Background())
+	assert.ErrorContains(t, err, "doesn't run")
+	data, err := os.ReadFile(binary)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+}
+
+func TestNewUpdaterRejectsUnknownMethod(t *testing.T) {
+	_, err := NewUpdater("apt", "yt-dlp")
+	assert.ErrorContains(t, err, "unknown yt-dlp update method")
+}
+
+func TestNewerVersion(t *testing.T) {
+	assert.True(t, NewerVersion("2025.01.15", "2024.12.23"))
+	assert.True(t, NewerVersion("2025.01.15.232714", "2025.01.15"), "nightly builds are newer than the release")
+	assert.True(t, NewerVersion("2025.01.15", "2025.1.2"))
+	assert.False(t, NewerVersion("2025.01.15", "2025.01.15"))
+	assert.False(t, NewerVersion("2024.12.23", "2025.01.15"))
+}
+
+func TestServiceManagerAutoUpdate(t *testing.T) {
+	manager := NewServiceManager(nil)
+	_, err := manager.AutoUpdate(context.Background())
+	assert.ErrorContains(t, err, "not enabled")
+
+	installed := "2024.12.23"
+	updater, _ := newTestUpdater(t, UpdatePip, "yt-dlp", "2025.01.15", &installed)
+	manager.UseUpdater(updater, func() bool { return false })
+
+	check, err := manager.AutoUpdate(context.Background())
+	require.NoError(t, err)
+	assert.True(t, check.Updated)
+	assert.False(t, manager.RestartPending(), "a stopped service loads the update when it starts")
+}
@@ -81,13 +81,8 @@ func (su *ServiceUtils) InstallDependencies() error {
 
 	for _, pkg := range packages {
 		fmt.Printf("Installing %s...\n", pkg)
-		cmd := exec.Command("pip", "install", "--upgrade", pkg)
-		if err := cmd.Run(); err != nil {
-			// Try pip3 if pip fails
-			cmd = exec.Command("pip3", "install", "--upgrade", pkg)
-			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("failed to install %s: %w", pkg, err)
-			}
+		if err := su.UpgradePackage(context.Background(), pkg); err != nil {
+			return err
 		}
 		fmt.Printf("✓ %s installed successfully\n", pkg)
 	}
@@ -95,6 +90,16 @@ func (su *ServiceUtils) InstallDependencies() error {
 	return nil
 }
 
+// UpgradePackage installs the latest version of a Python package with pip, or pip3 if pip fails
+func (su *ServiceUtils) UpgradePackage(ctx context.Context, pkg string) error {
+	if err := exec.CommandContext(ctx, "pip", "install", "--upgrade", pkg).Run(); err != nil {
+		if err := exec.CommandContext(ctx, "pip3", "install", "--upgrade", pkg).Run(); err != nil {
+			return fmt.Errorf("failed to install %s: %w", pkg, err)
+		}
+	}
+	return nil
+}
+
 // CreateConfigFile creates a configuration file for the yt-dlp service
 func (su *ServiceUtils) CreateConfigFile(config *ServiceConfig, filePath string) error {
 	configDir := filepath.Dir(filePath)
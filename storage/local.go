@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Local keeps objects as files in a directory. Its signed URLs point at Handler, served under PublicURL.
+type Local struct {
+	dir       string
+	publicURL string
+	secret    []byte
+	now       func() time.Time
+}
+
+// NewLocal creates a store in dir. Signed URLs are publicURL, such as "https://bot.example.com/files",
+// followed by the key, and are signed with secret.
+func NewLocal(dir, publicURL string, secret []byte) *Local {
+	return &Local{dir: dir, publicURL: strings.TrimSuffix(publicURL, "/"), secret: secret, now: time.Now}
+}
+
+// Describe names the store for logs
+func (l *Local) Describe() string {
+	return "local directory " + l.dir
+}
+
+// path returns the file of a key
+func (l *Local) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes the object through a temporary file so readers never see part of it
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	written, err := io.Copy(out, r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && size >= 0 && written != size {
+		err = fmt.Errorf("wrote %d bytes of %d", written, size)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get opens the object's file
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return file, err
+}
+
+// Delete removes the object's file
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL links to the object through Handler until lifetime passes
+func (l *Local) SignedURL(key string, lifetime time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	if l.publicURL == "" {
+		return "", ErrNoPublicURL
+	}
+	if err := checkLifetime(lifetime); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(l.now().Add(lifetime).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {l.sign(key, expires)}}
+	return l.publicURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// sign returns the signature of a key's URL valid until expires
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Handler serves objects to requests with a valid signature from SignedURL. Mount it with the prefix of
+// the public URL stripped, so the request path is the key.
+func (l *Local) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		expires := r.URL.Query().Get("expires")
+		signature := r.URL.Query().Get("signature")
+		deadline, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || ValidateKey(key) != nil || !hmac.Equal([]byte(signature), []byte(l.sign(key, expires))) {
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+		if l.now().Unix() > deadline {
+			http.Error(w, "link expired", http.StatusForbidden)
+			return
+		}
+
+		path, _ := l.path(key)
+		file, err := os.Open(path)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "private, no-store")
+		http.ServeContent(w, r, filepath.Base(path), info.ModTime(), file)
+	})
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocal(t.TempDir(), "", []byte("secret"))
+
+	require.NoError(t, store.Put(ctx, "soundboard/airhorn.ogg", strings.NewReader("honk"), 4, "audio/ogg"))
+	body, err := store.Get(ctx, "soundboard/airhorn.ogg")
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "honk", string(data))
+
+	require.NoError(t, store.Delete(ctx, "soundboard/airhorn.ogg"))
+	require.NoError(t, store.Delete(ctx, "soundboard/airhorn.ogg"), "deleting a missing object succeeds")
+	_, err = store.Get(ctx, "soundboard/airhorn.ogg")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Error(t, store.Put(ctx, "soundboard/short.ogg", strings.NewReader("ho"), 4, ""), "a short body is refused")
+	_, err = store.Get(ctx, "soundboard/short.ogg")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Put(ctx, "../escape", strings.NewReader(""), 0, ""), ErrInvalidKey)
+}
+
+func TestLocalSignedURL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	store := NewLocal(t.TempDir(), "https://bot.example.com/files/", []byte("secret"))
+	store.now = func() time.Time { return now }
+	require.NoError(t, store.Put(ctx, "playlists/1/mix.json", strings.NewReader(`["a"]`), 5, "application/json"))
+
+	link, err := store.SignedURL("playlists/1/mix.json", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(link, "https://bot.example.com/files/playlists/1/mix.json?expires="), link)
+
+	get := func(link string) *httptest.ResponseRecorder {
+		u, err := url.Parse(link)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, strings.TrimPrefix(u.Path, "/files")+"?"+u.RawQuery, nil)
+		store.Handler().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	response := get(link)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `["a"]`, response.Body.String())
+
+	assert.Equal(t, http.StatusForbidden, get(strings.Replace(link, "mix.json", "other.json", 1)).Code, "the signature covers the key")
+	assert.Equal(t, http.StatusForbidden, get(link[:len(link)-1]+"0").Code)
+
+	now = now.Add(2 * time.Hour)
+	assert.Equal(t, http.StatusForbidden, get(link).Code, "links expire")
+
+	_, err = store.SignedURL("playlists/1/mix.json", 8*24*time.Hour)
+	assert.Error(t, err)
+	_, err = NewLocal(t.TempDir(), "", []byte("secret")).SignedURL("playlists/1/mix.json", time.Hour)
+	assert.ErrorIs(t, err, ErrNoPublicURL)
+}
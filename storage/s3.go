@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+)
+
+// S3 request signing, AWS Signature Version 4
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	signingService   = "s3"
+	amzDateFormat    = "20060102T150405Z"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+)
+
+// S3Config locates a bucket on AWS S3 or an S3-compatible service such as MinIO, R2 or B2
+type S3Config struct {
+	Endpoint        string // Such as https://s3.us-east-1.amazonaws.com
+	Region          string // us-east-1 when empty; R2 uses auto
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool // Address the bucket as endpoint/bucket instead of bucket.endpoint, as MinIO expects
+}
+
+// S3 keeps objects in an S3-compatible bucket, signing requests itself
+type S3 struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3 creates a store for the bucket, sending requests with client or a client with a 5 minute timeout
+// when nil
+func NewS3(config S3Config, client *http.Client) (*S3, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "https" && endpoint.Scheme != "http") {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+	if config.Bucket == "" || config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 storage needs a bucket, access key ID and secret access key")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if client == nil {
+		client = httpclient.New(5 * time.Minute)
+	}
+	return &S3{config: config, endpoint: endpoint, client: client, now: time.Now}, nil
+}
+
+// Describe names the bucket for logs
+func (s *S3) Describe() string {
+	return fmt.Sprintf("S3 bucket %s at %s", s.config.Bucket, s.endpoint.Host)
+}
+
+// objectURL returns the unsigned URL of a key
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	if s.config.PathStyle {
+		u.Path = s.endpoint.Path + "/" + s.config.Bucket + "/" + key
+	} else {
+		u.Host = s.config.Bucket + "." + s.endpoint.Host
+		u.Path = s.endpoint.Path + "/" + key
+	}
+	return &u
+}
+
+// Put uploads the object in one request
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads the object, the caller closes the body
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object, S3 reports success for missing objects too
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// SignedURL presigns a download of the object, valid for lifetime
+func (s *S3) SignedURL(key string, lifetime time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	if err := checkLifetime(lifetime); err != nil {
+		return "", err
+	}
+
+	u := s.objectURL(key)
+	now := s.now().UTC()
+	query := url.Values{
+		"X-Amz-Algorithm":     {signingAlgorithm},
+		"X-Amz-Credential":    {s.config.AccessKeyID + "/" + s.scope(now)},
+		"X-Amz-Date":          {now.Format(amzDateFormat)},
+		"X-Amz-Expires":       {strconv.Itoa(int(lifetime.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	canonical := canonicalRequest(http.MethodGet, u.EscapedPath(), query, map[string]string{"host": u.Host}, unsignedPayload)
+	u.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + s.signature(now, canonical)
+	return u.String(), nil
+}
+
+// do signs and sends a request, turning error responses into errors
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	s.sign(req)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, req.URL.Path)
+	}
+	return nil, fmt.Errorf("S3 responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// sign adds the Authorization header of a request. The body isn't hashed, so uploads can stream.
+func (s *S3) sign(req *http.Request) {
+	now := s.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": unsignedPayload,
+		"x-amz-date":           now.Format(amzDateFormat),
+	}
+	canonical := canonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.Query(), headers, unsignedPayload)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, s.config.AccessKeyID, s.scope(now), signedHeaders(headers), s.signature(now, canonical)))
+}
+
+// scope is the date, region and service a signature is valid for
+func (s *S3) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + s.config.Region + "/" + signingService + "/aws4_request"
+}
+
+// signature signs a canonical request with a key derived from the secret for the day
+func (s *S3) signature(now time.Time, canonical string) string {
+	digest := sha256.Sum256([]byte(canonical))
+	stringToSign := signingAlgorithm + "\n" + now.Format(amzDateFormat) + "\n" + s.scope(now) + "\n" + hex.EncodeToString(digest[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, signingService)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalRequest lays out a request the way Signature Version 4 hashes it
+func canonicalRequest(method, path string, query url.Values, headers map[string]string, payloadHash string) string {
+	if path == "" {
+		path = "/"
+	}
+	var lines []string
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		lines = append(lines, name+":"+strings.TrimSpace(headers[name]))
+	}
+	return strings.Join([]string{
+		method,
+		path,
+		canonicalQuery(query),
+		strings.Join(lines, "\n") + "\n",
+		signedHeaders(headers),
+		payloadHash,
+	}, "\n")
+}
+
+// canonicalQuery sorts and escapes a query the way Signature Version 4 expects, spaces as %20
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		for _, value := range query[name] {
+			pairs = append(pairs, escape(name)+"="+escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// signedHeaders lists the signed header names
+func signedHeaders(headers map[string]string) string {
+	return strings.Join(slices.Sorted(maps.Keys(headers)), ";")
+}
+
+// escape percent-encodes everything but unreserved characters
+func escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,88 @@
+// Package storage keeps large assets, such as soundboard clips, uploaded playlists, recordings, cached
+// audio and backups, on local disk or in an S3-compatible bucket, and hands out signed URLs that let a
+// browser download one for a limited time.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for a key that holds no object
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey is returned for keys that could escape the store or that S3 would mangle
+	ErrInvalidKey = errors.New("invalid object key")
+	// ErrNoPublicURL is returned by SignedURL of a local store without a public URL to link to
+	ErrNoPublicURL = errors.New("storage has no public URL")
+)
+
+// MaxURLLifetime is the longest a signed URL stays valid, the limit S3 puts on presigned URLs
+const MaxURLLifetime = 7 * 24 * time.Hour
+
+// Kind is a kind of asset, stored under a prefix of its own
+type Kind string
+
+const (
+	KindSoundboard Kind = "soundboard"
+	KindPlaylists  Kind = "playlists"
+	KindRecordings Kind = "recordings"
+	KindAudio      Kind = "audio"
+	KindBackups    Kind = "backups"
+)
+
+// Key returns the key of an asset of a kind, such as "backups/backup-20250601T030405Z.tar.gz"
+func Key(kind Kind, name string) string {
+	return string(kind) + "/" + name
+}
+
+// Store keeps objects by key. Keys are slash separated paths such as "playlists/<guild>/<name>.json".
+type Store interface {
+	// Put stores size bytes from r under key, replacing what was there
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object under key, ErrNotFound when there is none
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object under key, succeeding when there is none
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL anyone can download the object from until it expires
+	SignedURL(key string, lifetime time.Duration) (string, error)
+	// Describe names the store for logs and diagnostics, without credentials
+	Describe() string
+}
+
+// ValidateKey checks a key is a relative path of letters, digits and -._~ segments, which every backend
+// stores as is and which can't climb out of a local store's directory
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 512 {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+		for _, r := range segment {
+			if !unreserved(r) {
+				return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+			}
+		}
+	}
+	return nil
+}
+
+// unreserved reports whether r is left as is in URLs
+func unreserved(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+		r == '-' || r == '.' || r == '_' || r == '~'
+}
+
+// checkLifetime bounds a signed URL's lifetime to what every backend allows
+func checkLifetime(lifetime time.Duration) error {
+	if lifetime <= 0 || lifetime > MaxURLLifetime {
+		return fmt.Errorf("signed URL lifetime must be between 1s and %s, got %s", MaxURLLifetime, lifetime)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"backups/backup-20250601T030405Z.tar.gz", "playlists/123/road_trip.json", "a"} {
+		assert.NoError(t, ValidateKey(key), key)
+	}
+	for _, key := range []string{"", "/abs", "trailing/", "a//b", "../escape", "a/../b", "./a", "with space", "uni/cödé", "q?x=1"} {
+		assert.ErrorIs(t, ValidateKey(key), ErrInvalidKey, key)
+	}
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "recordings/1234.ogg", Key(KindRecordings, "1234.ogg"))
+}
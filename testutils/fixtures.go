@@ -73,6 +73,22 @@ func CreateTestInteraction(commandName string, options []*discordgo.ApplicationC
 	}
 }
 
+// CreateTestComponentInteraction creates a test message component (button/select menu) interaction
+func CreateTestComponentInteraction(customID string, values ...string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:        "component_interaction_id_123",
+			Type:      discordgo.InteractionMessageComponent,
+			GuildID:   "guild_id_123",
+			ChannelID: "channel_id_123",
+			Data: discordgo.MessageComponentInteractionData{
+				CustomID: customID,
+				Values:   values,
+			},
+		},
+	}
+}
+
 // CreateStringOption creates a string command option for testing
 func CreateStringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
 	return &discordgo.ApplicationCommandInteractionDataOption{
@@ -91,6 +107,24 @@ func CreateUserOption(name string, user *discordgo.User) *discordgo.ApplicationC
 	}
 }
 
+// CreateBooleanOption creates a boolean command option for testing
+func CreateBooleanOption(name string, value bool) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionBoolean,
+		Value: value,
+	}
+}
+
+// CreateChannelOption creates a channel command option for testing
+func CreateChannelOption(name, channelID string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{
+		Name:  name,
+		Type:  discordgo.ApplicationCommandOptionChannel,
+		Value: channelID,
+	}
+}
+
 // CreateUserOptionWithResolved creates a user option and stores the user for resolved data
 func CreateUserOptionWithResolved(name string, user *discordgo.User, resolved *discordgo.ApplicationCommandInteractionDataResolved) *discordgo.ApplicationCommandInteractionDataOption {
 	if resolved.Users == nil {
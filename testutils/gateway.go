@@ -0,0 +1,67 @@
+package testutils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+)
+
+// OpenTestSession opens a session against a fake gateway, so code that joins voice channels or
+// otherwise writes to the gateway can run in tests. The gateway completes the handshake as the bot
+// user botUserID and then accepts every command, such as voice state updates, without answering.
+func OpenTestSession(t *testing.T, botUserID string) *discordgo.Session {
+	t.Helper()
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gateway" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"url": "ws://" + r.Host + "/ws"})
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.WriteJSON(map[string]any{"op": 10, "d": map[string]any{"heartbeat_interval": 60000}})
+		if _, _, err := conn.ReadMessage(); err != nil { // Identify
+			return
+		}
+		_ = conn.WriteJSON(map[string]any{"op": 0, "t": "READY", "s": 1, "d": map[string]any{
+			"v":          10,
+			"session_id": "test_session",
+			"user":       map[string]any{"id": botUserID, "username": "pxnx", "bot": true},
+			"guilds":     []any{},
+		}})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+
+	original := discordgo.EndpointGateway
+	discordgo.EndpointGateway = server.URL + "/gateway"
+
+	session, err := discordgo.New("Bot test.token")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	session.LogLevel = discordgo.LogError
+	if err := session.Open(); err != nil {
+		t.Fatalf("Failed to open session against the fake gateway: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = session.Close()
+		server.Close()
+		discordgo.EndpointGateway = original
+	})
+	return session
+}
@@ -13,6 +13,7 @@ type MockSession struct {
 	InteractionResponseEditCalled bool
 	InteractionResponseEditError  error
 	InteractionResponseEditReturn *discordgo.Message
+	InteractionResponseEditData   *discordgo.WebhookEdit
 	FollowupCalled                bool
 	FollowupError                 error
 	FollowupReturn                *discordgo.Message
@@ -32,6 +33,17 @@ type MockSession struct {
 	InteractionResponseReturn     *discordgo.Message
 	MessageReactionAddCalled      bool
 	MessageReactionAddError       error
+	ChannelMessageSendCalled      bool
+	ChannelMessageSendError       error
+	ChannelMessageSendData        *discordgo.MessageSend
+	ChannelMessageSendReturn      *discordgo.Message
+	ChannelMessageEditCalled      bool
+	ChannelMessageEditError       error
+	ChannelMessageEditData        *discordgo.MessageEdit
+	ChannelMessageEditReturn      *discordgo.Message
+	ChannelMessagesCalls          int
+	ChannelMessagesError          error
+	ChannelMessagesReturn         []*discordgo.Message // The channel's history, newest first
 }
 
 // InteractionRespond mocks the Discord session InteractionRespond method
@@ -47,6 +59,7 @@ func (m *MockSession) InteractionRespond(interaction *discordgo.Interaction, res
 // InteractionResponseEdit mocks the Discord session InteractionResponseEdit method
 func (m *MockSession) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
 	m.InteractionResponseEditCalled = true
+	m.InteractionResponseEditData = newresp
 	if m.InteractionResponseEditError != nil {
 		return nil, m.InteractionResponseEditError
 	}
@@ -104,6 +117,45 @@ func (m *MockSession) MessageReactionAdd(channelID, messageID, emojiID string) e
 	return m.MessageReactionAddError
 }
 
+// ChannelMessageSendComplex mocks the Discord session ChannelMessageSendComplex method
+func (m *MockSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	m.ChannelMessageSendCalled = true
+	m.ChannelMessageSendData = data
+	if m.ChannelMessageSendError != nil {
+		return nil, m.ChannelMessageSendError
+	}
+	return m.ChannelMessageSendReturn, nil
+}
+
+// ChannelMessageEditComplex mocks the Discord session ChannelMessageEditComplex method
+func (m *MockSession) ChannelMessageEditComplex(data *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	m.ChannelMessageEditCalled = true
+	m.ChannelMessageEditData = data
+	if m.ChannelMessageEditError != nil {
+		return nil, m.ChannelMessageEditError
+	}
+	return m.ChannelMessageEditReturn, nil
+}
+
+// ChannelMessages mocks the Discord session ChannelMessages method, paging through ChannelMessagesReturn
+// before beforeID
+func (m *MockSession) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	m.ChannelMessagesCalls++
+	if m.ChannelMessagesError != nil {
+		return nil, m.ChannelMessagesError
+	}
+	messages := m.ChannelMessagesReturn
+	if beforeID != "" {
+		for n, message := range messages {
+			if message.ID == beforeID {
+				messages = messages[n+1:]
+				break
+			}
+		}
+	}
+	return messages[:min(limit, len(messages))], nil
+}
+
 // State mocks the Discord session State method
 func (m *MockSession) State() *discordgo.State {
 	m.StateCalled = true
@@ -117,6 +169,7 @@ func (m *MockSession) Reset() {
 	m.RespondData = nil
 	m.RespondType = 0
 	m.InteractionResponseEditCalled = false
+	m.InteractionResponseEditData = nil
 	m.InteractionResponseEditError = nil
 	m.InteractionResponseEditReturn = nil
 	m.FollowupCalled = false
@@ -138,4 +191,15 @@ func (m *MockSession) Reset() {
 	m.InteractionResponseReturn = nil
 	m.MessageReactionAddCalled = false
 	m.MessageReactionAddError = nil
+	m.ChannelMessageSendCalled = false
+	m.ChannelMessageSendError = nil
+	m.ChannelMessageSendData = nil
+	m.ChannelMessageSendReturn = nil
+	m.ChannelMessageEditCalled = false
+	m.ChannelMessageEditError = nil
+	m.ChannelMessageEditData = nil
+	m.ChannelMessageEditReturn = nil
+	m.ChannelMessagesCalls = 0
+	m.ChannelMessagesError = nil
+	m.ChannelMessagesReturn = nil
 }
@@ -0,0 +1,324 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"pxnx-discord-bot/httpclient"
+	"pxnx-discord-bot/utils"
+)
+
+// Export batching
+const (
+	queueSize     = 2048
+	maxBatchSize  = 512
+	flushInterval = 5 * time.Second
+	exportTimeout = 10 * time.Second
+)
+
+// Config is where spans are exported and how many traces are recorded
+type Config struct {
+	Endpoint    string      // OTLP/HTTP traces URL, such as http://otel-collector:4318/v1/traces
+	Headers     http.Header // Sent with every export, such as an API key of a hosted collector
+	ServiceName string      // service.name of the spans, such as pxnx-discord-bot
+	SampleRatio float64     // Share of new traces recorded, 0 to 1
+}
+
+// ConfigFromEnv reads the standard OpenTelemetry variables OTEL_EXPORTER_OTLP_TRACES_ENDPOINT (or
+// OTEL_EXPORTER_OTLP_ENDPOINT with /v1/traces added), OTEL_EXPORTER_OTLP_HEADERS, OTEL_SERVICE_NAME and
+// OTEL_TRACES_SAMPLER_ARG. The endpoint is empty, and tracing off, when neither endpoint is set.
+func ConfigFromEnv(defaultService string) (Config, error) {
+	config := Config{
+		Endpoint:    strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")),
+		ServiceName: strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME")),
+		SampleRatio: 1,
+	}
+	if config.Endpoint == "" {
+		if base := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")); base != "" {
+			config.Endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+	if config.ServiceName == "" {
+		config.ServiceName = defaultService
+	}
+
+	var errs []error
+	if raw := strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")); raw != "" {
+		config.Headers = make(http.Header)
+		for _, pair := range strings.Split(raw, ",") {
+			name, value, found := strings.Cut(pair, "=")
+			if !found || strings.TrimSpace(name) == "" {
+				errs = append(errs, fmt.Errorf("OTEL_EXPORTER_OTLP_HEADERS: invalid header %q, expected name=value", pair))
+				continue
+			}
+			config.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("OTEL_TRACES_SAMPLER_ARG")); raw != "" {
+		ratio, err := strconv.ParseFloat(raw, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			errs = append(errs, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG: invalid ratio %q, expected 0 to 1", raw))
+		} else {
+			config.SampleRatio = ratio
+		}
+	}
+	return config, errors.Join(errs...)
+}
+
+// Configure starts exporting spans as config says, or turns tracing off when the endpoint is empty. The
+// returned function sends the spans still queued and stops the export; call it before exiting.
+func Configure(config Config) (shutdown func(context.Context)) {
+	if previous := current.Swap(nil); previous != nil {
+		previous.stop(context.Background())
+	}
+	if config.Endpoint == "" {
+		return func(context.Context) {}
+	}
+	e := &exporter{
+		config: config,
+		client: httpclient.New(exportTimeout),
+		queue:  make(chan *Span, queueSize),
+		flush:  make(chan chan struct{}),
+		done:   make(chan struct{}),
+	}
+	utils.SafeGo("tracing.export", e.run)
+	current.Store(e)
+	return func(ctx context.Context) {
+		current.CompareAndSwap(e, nil)
+		e.stop(ctx)
+	}
+}
+
+// exporter sends finished spans to the collector in batches
+type exporter struct {
+	config  Config
+	client  *http.Client
+	queue   chan *Span
+	flush   chan chan struct{}
+	done    chan struct{}
+	stopped atomic.Bool
+	dropped atomic.Int64
+	failing atomic.Bool
+}
+
+// sample decides whether a new trace is recorded
+func (e *exporter) sample() bool {
+	return e.config.SampleRatio >= 1 || (e.config.SampleRatio > 0 && rand.Float64() < e.config.SampleRatio)
+}
+
+// enqueue queues a finished span, dropping it when the collector can't keep up
+func (e *exporter) enqueue(span *Span) {
+	select {
+	case e.queue <- span:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// run batches spans until stop
+func (e *exporter) run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	send := func() {
+		if len(batch) > 0 {
+			e.send(batch)
+			batch = nil
+		}
+	}
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) >= maxBatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case reply := <-e.flush:
+			for drained := false; !drained; {
+				select {
+				case span := <-e.queue:
+					batch = append(batch, span)
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(reply)
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// stop sends what is queued, waiting until ctx ends at most, and ends the export
+func (e *exporter) stop(ctx context.Context) {
+	if !e.stopped.CompareAndSwap(false, true) {
+		return
+	}
+	reply := make(chan struct{})
+	select {
+	case e.flush <- reply:
+		select {
+		case <-reply:
+		case <-ctx.Done():
+		}
+	case <-ctx.Done():
+	}
+	close(e.done)
+}
+
+// send posts a batch to the collector, logging when exports start and stop failing
+func (e *exporter) send(batch []*Span) {
+	if dropped := e.dropped.Swap(0); dropped > 0 {
+		utils.LogWarn("Dropped %d trace spans, the collector isn't keeping up", dropped)
+	}
+	body, err := json.Marshal(e.payload(batch))
+	if err != nil {
+		utils.LogWarn("Failed to encode trace spans: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	err = e.post(ctx, body)
+	switch {
+	case err != nil && !e.failing.Swap(true):
+		utils.LogWarn("Failed to export traces to %s: %v", e.config.Endpoint, err)
+	case err == nil && e.failing.Swap(false):
+		utils.LogInfo("Exporting traces to %s again", e.config.Endpoint)
+	}
+}
+
+func (e *exporter) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range e.config.Headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("collector responded %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// OTLP JSON encoding of spans, as in opentelemetry-proto's trace.proto
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              Kind            `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            *otlpStatus     `json:"status,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"` // 2 is STATUS_CODE_ERROR
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	}
+)
+
+// payload encodes a batch as an OTLP export request
+func (e *exporter) payload(batch []*Span) otlpRequest {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, span := range batch {
+		span.mu.Lock()
+		encoded := otlpSpan{
+			TraceID:           hex.EncodeToString(span.context.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.context.SpanID[:]),
+			Name:              span.name,
+			Kind:              span.kind,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+		}
+		if span.parent != (SpanID{}) {
+			encoded.ParentSpanID = hex.EncodeToString(span.parent[:])
+		}
+		for _, attr := range span.attrs {
+			encoded.Attributes = append(encoded.Attributes, otlpAttr(attr))
+		}
+		if span.err != "" {
+			encoded.Status = &otlpStatus{Code: 2, Message: span.err}
+		}
+		span.mu.Unlock()
+		spans = append(spans, encoded)
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{otlpAttr(slog.String("service.name", e.config.ServiceName))}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "pxnx-discord-bot/tracing"},
+			Spans: spans,
+		}},
+	}}}
+}
+
+// otlpAttr encodes an attribute as an OTLP AnyValue; 64-bit integers are strings in OTLP JSON
+func otlpAttr(attr slog.Attr) otlpAttribute {
+	value := attr.Value.Resolve()
+	var encoded map[string]any
+	switch value.Kind() {
+	case slog.KindBool:
+		encoded = map[string]any{"boolValue": value.Bool()}
+	case slog.KindInt64:
+		encoded = map[string]any{"intValue": strconv.FormatInt(value.Int64(), 10)}
+	case slog.KindUint64:
+		encoded = map[string]any{"intValue": strconv.FormatUint(value.Uint64(), 10)}
+	case slog.KindFloat64:
+		encoded = map[string]any{"doubleValue": value.Float64()}
+	case slog.KindDuration:
+		encoded = map[string]any{"doubleValue": value.Duration().Seconds()}
+	default:
+		encoded = map[string]any{"stringValue": value.String()}
+	}
+	return otlpAttribute{Key: attr.Key, Value: encoded}
+}
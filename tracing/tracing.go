@@ -0,0 +1,258 @@
+// Package tracing records OpenTelemetry spans of the bot's work, such as a /play command, the extraction
+// it waits for and the start of playback, and exports them to an OTLP collector over HTTP. The trace
+// follows requests into the yt-dlp service through the W3C traceparent header. Tracing is off until
+// Configure is called with an endpoint, and Start then costs next to nothing.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID identifies a trace, every span of one /play command shares it
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// SpanContext is what a span passes on to its children, in this process or another one
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool // Whether the trace is recorded; children follow their parent's decision
+}
+
+// IsValid reports whether the span context belongs to a trace
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// TraceParent formats the span context as a W3C traceparent header value
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + flags
+}
+
+// ParseTraceParent reads a W3C traceparent header value such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceParent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	flags, err := hex.DecodeString(parts[3])
+	if _, traceErr := hex.Decode(sc.TraceID[:], []byte(parts[1])); traceErr != nil || err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, sc.IsValid()
+}
+
+// TraceParentHeader is the header a span context travels in between services
+const TraceParentHeader = "traceparent"
+
+// Kind tells a collector what a span stands for, numbered like OTLP's SpanKind
+type Kind int
+
+const (
+	// KindInternal is work inside the bot, such as resolving a track
+	KindInternal Kind = 1
+	// KindServer is a request the bot or the yt-dlp service handles, such as an interaction
+	KindServer Kind = 2
+	// KindClient is a request to another service, such as the yt-dlp service
+	KindClient Kind = 3
+)
+
+// Span is one timed step of a trace. A nil *Span is valid and records nothing, which is what Start
+// returns while tracing is off.
+type Span struct {
+	context SpanContext
+	parent  SpanID
+	name    string
+	kind    Kind
+	start   time.Time
+	export  *exporter
+
+	mu    sync.Mutex
+	attrs []slog.Attr
+	err   string
+	end   time.Time
+	ended bool
+}
+
+// SpanContext returns what the span passes on to its children
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetAttributes adds key-value pairs to the span, given like slog's, such as "provider", "youtube"
+func (s *Span) SetAttributes(args ...any) {
+	if s == nil || s.export == nil {
+		return
+	}
+	attrs := toAttrs(args)
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed with err, nil errors are ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || s.export == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	s.err = err.Error()
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil || s.export == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended, s.end = true, time.Now()
+	s.mu.Unlock()
+	s.export.enqueue(s)
+}
+
+// spanKey is the context key the current span is stored under
+type spanKey struct{}
+
+// remoteKey is the context key a span context from another service or an earlier request is stored under
+type remoteKey struct{}
+
+// ContextWithSpan returns a context whose spans are children of span
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// ContextWithSpanContext returns a context whose spans continue the trace of sc, such as one received in
+// a traceparent header or kept with a queued track
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanFromContext returns the span of a context, nil when there is none
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext returns the span context new spans in ctx would be children of
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if ctx == nil {
+		return SpanContext{}
+	}
+	if span := SpanFromContext(ctx); span != nil {
+		return span.context
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// current is the exporter spans go to, nil while tracing is off
+var current atomic.Pointer[exporter]
+
+// Enabled reports whether spans are recorded
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// Start begins a span named after the work it times, such as "music.resolve", as a child of the span in
+// ctx or a new trace. args are attributes given like slog's. The returned context carries the span; the
+// caller ends it.
+func Start(ctx context.Context, name string, kind Kind, args ...any) (context.Context, *Span) {
+	export := current.Load()
+	if export == nil {
+		return ctx, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent := SpanContextFromContext(ctx)
+	span := &Span{name: name, kind: kind, start: time.Now()}
+	if parent.IsValid() {
+		span.context = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+		span.parent = parent.SpanID
+	} else {
+		span.context.TraceID = newTraceID()
+		span.context.Sampled = export.sample()
+	}
+	span.context.SpanID = newSpanID()
+
+	// Unsampled spans still pass the trace on, so the yt-dlp service makes the same decision
+	if span.context.Sampled {
+		span.export = export
+		span.attrs = toAttrs(args)
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// toAttrs converts slog-style key-value pairs to attributes
+func toAttrs(args []any) []slog.Attr {
+	if len(args) == 0 {
+		return nil
+	}
+	record := slog.Record{}
+	record.Add(args...)
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for id == (TraceID{}) {
+		hi, lo := rand.Uint64(), rand.Uint64()
+		for n := range 8 {
+			id[n], id[8+n] = byte(hi>>(8*n)), byte(lo>>(8*n))
+		}
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for id == (SpanID{}) {
+		v := rand.Uint64()
+		for n := range 8 {
+			id[n] = byte(v >> (8 * n))
+		}
+	}
+	return id
+}
@@ -0,0 +1,215 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector receives exported spans like an OTLP/HTTP collector
+type collector struct {
+	mu       sync.Mutex
+	requests []otlpRequest
+	headers  http.Header
+}
+
+// useCollector turns tracing on for the test, exporting to a collector. flush sends the queued spans.
+func useCollector(t *testing.T, ratio float64) (c *collector, flush func() []otlpSpan) {
+	c = &collector{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request otlpRequest
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		c.mu.Lock()
+		c.requests = append(c.requests, request)
+		c.headers = r.Header.Clone()
+		c.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	shutdown := Configure(Config{
+		Endpoint:    server.URL + "/v1/traces",
+		Headers:     http.Header{"X-Api-Key": {"secret"}},
+		ServiceName: "test-bot",
+		SampleRatio: ratio,
+	})
+	t.Cleanup(func() { shutdown(context.Background()) })
+
+	return c, func() []otlpSpan {
+		shutdown(context.Background())
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		var spans []otlpSpan
+		for _, request := range c.requests {
+			for _, resource := range request.ResourceSpans {
+				for _, scope := range resource.ScopeSpans {
+					spans = append(spans, scope.Spans...)
+				}
+			}
+		}
+		return spans
+	}
+}
+
+func TestTraceParent(t *testing.T) {
+	sc, ok := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.TraceParent())
+
+	sc, ok = ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	require.True(t, ok)
+	assert.False(t, sc.Sampled)
+
+	for _, invalid := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6-00f067aa0ba902b7-01",
+	} {
+		_, ok := ParseTraceParent(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestStartWhileOff(t *testing.T) {
+	ctx := context.Background()
+	got, span := Start(ctx, "music.resolve", KindInternal, "provider", "youtube")
+	assert.Nil(t, span)
+	assert.Equal(t, ctx, got)
+
+	// A nil span is safe to use
+	span.SetAttributes("outcome", "ok")
+	span.RecordError(errors.New("failed"))
+	span.End()
+	assert.False(t, span.SpanContext().IsValid())
+}
+
+func TestSpansExportAsOneTrace(t *testing.T) {
+	c, flush := useCollector(t, 1)
+
+	ctx, root := Start(context.Background(), "/play", KindServer, "command", "play", "guild_id", "123")
+	_, child := Start(ctx, "music.resolve", KindInternal, "attempt", 2, "prefetched", true, "took", 1500*time.Millisecond)
+	child.RecordError(errors.New("video unavailable"))
+	child.End()
+	child.End()
+	root.End()
+
+	spans := flush()
+	require.Len(t, spans, 2)
+	resolve, play := spans[0], spans[1]
+	assert.Equal(t, "music.resolve", resolve.Name)
+	assert.Equal(t, KindInternal, resolve.Kind)
+	assert.Equal(t, play.TraceID, resolve.TraceID)
+	assert.Equal(t, play.SpanID, resolve.ParentSpanID)
+	assert.Empty(t, play.ParentSpanID)
+	assert.Equal(t, &otlpStatus{Code: 2, Message: "video unavailable"}, resolve.Status)
+	assert.Nil(t, play.Status)
+	assert.Equal(t, []otlpAttribute{
+		{Key: "attempt", Value: map[string]any{"intValue": "2"}},
+		{Key: "prefetched", Value: map[string]any{"boolValue": true}},
+		{Key: "took", Value: map[string]any{"doubleValue": 1.5}},
+	}, resolve.Attributes)
+	assert.Equal(t, []otlpAttribute{
+		{Key: "command", Value: map[string]any{"stringValue": "play"}},
+		{Key: "guild_id", Value: map[string]any{"stringValue": "123"}},
+	}, play.Attributes)
+	assert.NotEmpty(t, play.StartTimeUnixNano)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	assert.Equal(t, "secret", c.headers.Get("X-Api-Key"))
+	assert.Equal(t, "service.name", c.requests[0].ResourceSpans[0].Resource.Attributes[0].Key)
+	assert.Equal(t, map[string]any{"stringValue": "test-bot"}, c.requests[0].ResourceSpans[0].Resource.Attributes[0].Value)
+}
+
+func TestUnsampledTracesPropagateWithoutExport(t *testing.T) {
+	_, flush := useCollector(t, 0)
+
+	ctx, root := Start(context.Background(), "/play", KindServer)
+	require.NotNil(t, root)
+	assert.True(t, root.SpanContext().IsValid())
+	assert.False(t, root.SpanContext().Sampled)
+
+	header := make(http.Header)
+	Inject(ctx, header)
+	assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-00$`, header.Get(TraceParentHeader))
+	root.End()
+
+	assert.Empty(t, flush())
+}
+
+func TestContextWithSpanContext(t *testing.T) {
+	_, flush := useCollector(t, 0)
+
+	// A sampled parent from a queued track or another service keeps the trace recorded
+	queued, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithSpanContext(context.Background(), queued)
+	assert.Equal(t, queued, SpanContextFromContext(ctx))
+
+	_, span := Start(ctx, "playback.start", KindInternal)
+	span.End()
+
+	spans := flush()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].ParentSpanID)
+}
+
+func TestMiddlewareContinuesTrace(t *testing.T) {
+	_, flush := useCollector(t, 1)
+
+	var inHandler SpanContext
+	handler := Middleware("ytdlp.server", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inHandler = SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/extract", nil)
+	req.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := flush()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "ytdlp.server POST /extract", spans[0].Name)
+	assert.Equal(t, KindServer, spans[0].Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].ParentSpanID)
+	assert.Equal(t, spans[0].SpanID, hex.EncodeToString(inHandler.SpanID[:]))
+	assert.Equal(t, 2, spans[0].Status.Code)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	config, err := ConfigFromEnv("pxnx-discord-bot")
+	require.NoError(t, err)
+	assert.Empty(t, config.Endpoint, "tracing is off by default")
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://otel-collector:4318/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-honeycomb-team=abc, x-env=prod")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	config, err = ConfigFromEnv("pxnx-discord-bot")
+	require.NoError(t, err)
+	assert.Equal(t, "http://otel-collector:4318/v1/traces", config.Endpoint)
+	assert.Equal(t, "abc", config.Headers.Get("X-Honeycomb-Team"))
+	assert.Equal(t, "pxnx-discord-bot", config.ServiceName)
+	assert.Equal(t, 0.25, config.SampleRatio)
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "https://api.honeycomb.io/v1/traces")
+	t.Setenv("OTEL_SERVICE_NAME", "bot-eu")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "2")
+	config, err = ConfigFromEnv("pxnx-discord-bot")
+	assert.ErrorContains(t, err, "OTEL_TRACES_SAMPLER_ARG")
+	assert.Equal(t, "https://api.honeycomb.io/v1/traces", config.Endpoint)
+	assert.Equal(t, "bot-eu", config.ServiceName)
+	assert.Equal(t, 1.0, config.SampleRatio)
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// unixAddressPrefix marks an address as a unix socket path, such as unix:/run/bot/metrics.sock
+const unixAddressPrefix = "unix:"
+
+// UnixSocketPath returns the socket path of a unix: address. unix:///run/bot.sock works too.
+func UnixSocketPath(addr string) (string, bool) {
+	path, ok := strings.CutPrefix(strings.TrimSpace(addr), unixAddressPrefix)
+	if !ok {
+		return "", false
+	}
+	if strings.HasPrefix(path, "//") {
+		path = strings.TrimPrefix(path, "//")
+	}
+	return path, path != ""
+}
+
+// Listen listens on a TCP address such as :9090, 0.0.0.0:9090, [::]:9090 or [::1]:9090, or on a unix socket
+// given as unix:/path/to.sock. A socket file left behind by an earlier run is replaced, and the socket is
+// made readable and writable by the owner's group so a sidecar sharing the volume can connect.
+func Listen(addr string) (net.Listener, error) {
+	path, ok := UnixSocketPath(addr)
+	if !ok {
+		return net.Listen("tcp", strings.TrimSpace(addr))
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0660); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixSocketPath(t *testing.T) {
+	for addr, want := range map[string]string{
+		"unix:/run/bot/metrics.sock":   "/run/bot/metrics.sock",
+		"unix:///run/bot/metrics.sock": "/run/bot/metrics.sock",
+		" unix:relative.sock":          "relative.sock",
+	} {
+		path, ok := UnixSocketPath(addr)
+		assert.True(t, ok, addr)
+		assert.Equal(t, want, path, addr)
+	}
+	for _, addr := range []string{":9090", "[::1]:9090", "unix:", "/run/bot.sock"} {
+		_, ok := UnixSocketPath(addr)
+		assert.False(t, ok, addr)
+	}
+}
+
+func TestListenTCP(t *testing.T) {
+	listener, err := Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	assert.Equal(t, "tcp", listener.Addr().Network())
+
+	if ipv6, err := Listen("[::1]:0"); err == nil {
+		assert.Contains(t, ipv6.Addr().String(), "[::1]:")
+		ipv6.Close()
+	} else {
+		t.Logf("no IPv6 loopback here: %v", err)
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.sock")
+
+	listener, err := Listen("unix:" + path)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+
+	accepted := make(chan struct{})
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+		close(accepted)
+	}()
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+	<-accepted
+
+	listener.Close()
+
+	// A socket left by a crashed run is replaced
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+	listener, err = Listen("unix:" + path)
+	require.NoError(t, err)
+	listener.Close()
+
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0644))
+	_, err = Listen("unix:" + path)
+	assert.ErrorContains(t, err, "not a socket", "regular files are never removed")
+}
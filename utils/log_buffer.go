@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLogBufferSize is how many warnings and errors are kept in memory for /admin logs
+const DefaultLogBufferSize = 500
+
+// maxBufferedMessage caps a buffered message, panic stack traces can be long
+const maxBufferedMessage = 4096
+
+// LogEntry is a warning or error kept in memory
+type LogEntry struct {
+	Time    time.Time
+	Level   LogLevel
+	Module  string // Package that logged it, such as "music/download"
+	Message string
+	Request RequestInfo // Interaction it was logged for, zero outside of one
+}
+
+// logRing keeps the most recent entries, overwriting the oldest once full
+type logRing struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	next    int
+	full    bool
+}
+
+var recentLogs atomic.Pointer[logRing]
+
+func init() {
+	recentLogs.Store(newLogRing(DefaultLogBufferSize))
+}
+
+// newLogRing creates a ring holding up to size entries
+func newLogRing(size int) *logRing {
+	if size < 1 {
+		size = 1
+	}
+	return &logRing{entries: make([]LogEntry, size)}
+}
+
+// add stores an entry, dropping the oldest when the ring is full
+func (r *logRing) add(entry LogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// snapshot returns the stored entries, oldest first
+func (r *logRing) snapshot() []LogEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]LogEntry(nil), r.entries[:r.next]...)
+	}
+	snapshot := make([]LogEntry, 0, len(r.entries))
+	snapshot = append(snapshot, r.entries[r.next:]...)
+	return append(snapshot, r.entries[:r.next]...)
+}
+
+// SetLogBufferSize changes how many warnings and errors are kept, dropping those already kept
+func SetLogBufferSize(size int) {
+	recentLogs.Store(newLogRing(size))
+}
+
+// bufferLog keeps a warning or error logged from the module skip frames above bufferLog's caller
+// and hands it to the log sinks
+func bufferLog(level LogLevel, message string, request RequestInfo, skip int) {
+	if len(message) > maxBufferedMessage {
+		message = message[:maxBufferedMessage] + "…"
+	}
+	entry := LogEntry{
+		Time:    time.Now(),
+		Level:   level,
+		Module:  callerModule(skip + 1),
+		Message: message,
+		Request: request,
+	}
+	recentLogs.Load().add(entry)
+	publishLog(entry)
+}
+
+// RecentLogs returns the kept entries at level or more severe, oldest first. A module limits them to
+// that package and its subpackages, like module log levels.
+func RecentLogs(level LogLevel, module string) []LogEntry {
+	module = strings.Trim(strings.TrimSpace(module), "/")
+
+	var matched []LogEntry
+	for _, entry := range recentLogs.Load().snapshot() {
+		if entry.Level > level {
+			continue
+		}
+		if module != "" && entry.Module != module && !strings.HasPrefix(entry.Module, module+"/") {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	return matched
+}
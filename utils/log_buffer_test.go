@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRingKeepsMostRecent(t *testing.T) {
+	ring := newLogRing(3)
+	assert.Empty(t, ring.snapshot())
+
+	for _, message := range []string{"a", "b"} {
+		ring.add(LogEntry{Message: message})
+	}
+	assert.Equal(t, []LogEntry{{Message: "a"}, {Message: "b"}}, ring.snapshot())
+
+	for _, message := range []string{"c", "d", "e"} {
+		ring.add(LogEntry{Message: message})
+	}
+	assert.Equal(t, []LogEntry{{Message: "c"}, {Message: "d"}, {Message: "e"}}, ring.snapshot())
+}
+
+func TestRecentLogs(t *testing.T) {
+	SetLogBufferSize(10)
+	defer SetLogBufferSize(DefaultLogBufferSize)
+
+	LogWarn("disk %d%% full", 91)
+	LogError("%s failed", "upload")
+	LogInfo("not kept")
+	LogError("%s", strings.Repeat("x", maxBufferedMessage+10))
+
+	entries := RecentLogs(LogLevelWarn, "")
+	require.Len(t, entries, 3)
+	assert.Equal(t, LogLevelWarn, entries[0].Level)
+	assert.Equal(t, "disk 91% full", entries[0].Message)
+	assert.Equal(t, "utils", entries[0].Module, "entries record the package that logged them")
+	assert.False(t, entries[0].Time.IsZero())
+	assert.Len(t, entries[2].Message, maxBufferedMessage+len("…"))
+
+	errors := RecentLogs(LogLevelError, "")
+	require.Len(t, errors, 2)
+	assert.Equal(t, "upload failed", errors[0].Message)
+
+	assert.Len(t, RecentLogs(LogLevelWarn, "utils/"), 3)
+	assert.Empty(t, RecentLogs(LogLevelWarn, "music"))
+}
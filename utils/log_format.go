@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogFormat is how log lines are written
+type LogFormat int
+
+const (
+	// LogFormatText writes "[LEVEL] date time file:line message [fields]" lines
+	LogFormatText LogFormat = iota
+	// LogFormatJSON writes one JSON object per line for log aggregation, with fields as keys
+	LogFormatJSON
+)
+
+// currentLogFormat is set once at startup, like the global level
+var currentLogFormat = LogFormatText
+
+// logWriters are where each level is written, set by InitLogger
+var logWriters [LogLevelDebug + 1]io.Writer
+
+// jsonHandlers write each level as JSON when the format is LogFormatJSON
+var jsonHandlers [LogLevelDebug + 1]slog.Handler
+
+// String returns the format's name as accepted by ParseLogFormat
+func (f LogFormat) String() string {
+	if f == LogFormatJSON {
+		return "json"
+	}
+	return "text"
+}
+
+// ParseLogFormat converts "text" or "json" to a LogFormat
+func ParseLogFormat(format string) (LogFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "text":
+		return LogFormatText, nil
+	case "json":
+		return LogFormatJSON, nil
+	default:
+		return LogFormatText, fmt.Errorf("unknown log format %q, expected text or json", format)
+	}
+}
+
+// SetLogFormat switches every level to the format. Call it at startup, before logging from other
+// goroutines.
+func SetLogFormat(format LogFormat) {
+	currentLogFormat = format
+	configureLogOutputs()
+}
+
+// configureLogOutputs creates the loggers of every level for the current writers and format
+func configureLogOutputs() {
+	for level, w := range logWriters {
+		jsonHandlers[level] = nil
+		if w != nil && currentLogFormat == LogFormatJSON {
+			jsonHandlers[level] = slog.NewJSONHandler(w, &slog.HandlerOptions{
+				AddSource:   true,
+				Level:       slog.LevelDebug, // Levels are checked before a message gets here
+				ReplaceAttr: shortSource,
+			})
+		}
+	}
+}
+
+// shortSource writes the source of a JSON line as "file.go:123", like text lines
+func shortSource(groups []string, attr slog.Attr) slog.Attr {
+	if source, ok := attr.Value.Any().(*slog.Source); ok && attr.Key == slog.SourceKey && len(groups) == 0 {
+		return slog.String(slog.SourceKey, filepath.Base(source.File)+":"+strconv.Itoa(source.Line))
+	}
+	return attr
+}
+
+// logFieldsKey is the context key extra log fields are stored under
+type logFieldsKey struct{}
+
+// WithLogFields returns a context whose Context log functions add the fields, given as key-value pairs
+// like slog's, such as WithLogFields(ctx, "track", track.Title). Fields already in ctx are kept.
+func WithLogFields(ctx context.Context, args ...any) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	record := slog.Record{}
+	record.Add(args...)
+	fields := LogFields(ctx)
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = append(fields, attr)
+		return true
+	})
+	return context.WithValue(ctx, logFieldsKey{}, fields)
+}
+
+// LogFields returns the extra log fields of a context
+func LogFields(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(logFieldsKey{}).([]slog.Attr)
+	return fields[:len(fields):len(fields)]
+}
+
+// attrs returns the set fields of a request as JSON log fields
+func (r RequestInfo) attrs() []slog.Attr {
+	var attrs []slog.Attr
+	for _, field := range []struct{ key, value string }{
+		{"command", r.Command},
+		{"guild_id", r.GuildID},
+		{"user_id", r.UserID},
+		{"locale", r.Locale},
+		{"interaction_id", r.InteractionID},
+	} {
+		if field.value != "" {
+			attrs = append(attrs, slog.String(field.key, field.value))
+		}
+	}
+	return attrs
+}
+
+// slogLevel converts a level to slog's
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelError:
+		return slog.LevelError
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelDebug:
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// writeLog writes a message logged from the function skip frames above writeLog's caller, with the
+// request and the fields of ctx. Nothing is written before InitLogger.
+func writeLog(ctx context.Context, level LogLevel, skip int, message string, request RequestInfo) {
+	fields := LogFields(ctx)
+	if handler := jsonHandlers[level]; handler != nil {
+		var pcs [1]uintptr
+		runtime.Callers(skip+2, pcs[:])
+		record := slog.NewRecord(time.Now(), slogLevel(level), message, pcs[0])
+		if fn := runtime.FuncForPC(pcs[0]); fn != nil {
+			record.AddAttrs(slog.String("module", packageOf(fn.Name())))
+		}
+		record.AddAttrs(request.attrs()...)
+		record.AddAttrs(fields...)
+		handler.Handle(ctx, record)
+		return
+	}
+	if logger := textLogger(level); logger != nil {
+		logger.Output(skip+2, withRequest(message, request, fields...))
+	}
+}
+
+// textLogger returns the text logger of a level
+func textLogger(level LogLevel) *log.Logger {
+	switch level {
+	case LogLevelError:
+		return errorLogger
+	case LogLevelWarn:
+		return warnLogger
+	case LogLevelInfo:
+		return infoLogger
+	default:
+		return debugLogger
+	}
+}
+
+// formatField writes a field as key=value for text lines, quoting values with spaces
+func formatField(attr slog.Attr) string {
+	value := attr.Value.String()
+	if value == "" || strings.ContainsAny(value, " \"=[]") {
+		value = strconv.Quote(value)
+	}
+	return attr.Key + "=" + value
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends every level to a buffer in the format for the test
+func captureLogs(t *testing.T, format LogFormat) *bytes.Buffer {
+	var out bytes.Buffer
+	loggers := []*log.Logger{errorLogger, warnLogger, infoLogger, debugLogger}
+	writers, previousFormat, previousLevel := logWriters, currentLogFormat, currentLogLevel()
+	t.Cleanup(func() {
+		errorLogger, warnLogger, infoLogger, debugLogger = loggers[0], loggers[1], loggers[2], loggers[3]
+		logWriters = writers
+		SetLogLevel(previousLevel)
+		SetLogFormat(previousFormat)
+	})
+
+	errorLogger = log.New(&out, "[ERROR] ", log.Lshortfile)
+	warnLogger = log.New(&out, "[WARN]  ", log.Lshortfile)
+	infoLogger = log.New(&out, "[INFO]  ", log.Lshortfile)
+	debugLogger = log.New(&out, "[DEBUG] ", log.Lshortfile)
+	for level := range logWriters {
+		logWriters[level] = &out
+	}
+	SetLogLevel(LogLevelDebug)
+	SetLogFormat(format)
+	return &out
+}
+
+func TestParseLogFormat(t *testing.T) {
+	for input, want := range map[string]LogFormat{"": LogFormatText, "text": LogFormatText, " JSON ": LogFormatJSON} {
+		format, err := ParseLogFormat(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, format, input)
+	}
+	_, err := ParseLogFormat("xml")
+	assert.Error(t, err)
+	assert.Equal(t, "json", LogFormatJSON.String())
+}
+
+func TestWithLogFields(t *testing.T) {
+	ctx := WithLogFields(context.Background(), "track", "Song")
+	extended := WithLogFields(ctx, "attempt", 2)
+
+	assert.Len(t, LogFields(ctx), 1, "deriving a context doesn't change its parent's fields")
+	fields := LogFields(extended)
+	require.Len(t, fields, 2)
+	assert.Equal(t, "track", fields[0].Key)
+	assert.Equal(t, int64(2), fields[1].Value.Int64())
+	assert.Nil(t, LogFields(nil))
+}
+
+func TestTextLogLinesCarryFields(t *testing.T) {
+	out := captureLogs(t, LogFormatText)
+
+	ctx := WithLogFields(WithRequest(context.Background(), RequestInfo{GuildID: "123", Command: "play"}), "track", "Never Gonna Give You Up")
+	LogErrorContext(ctx, "Failed to play: %s", "403")
+	LogDebug("plain %d", 1)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "[ERROR] log_format_test.go:"), "the caller is the line logging: %s", lines[0])
+	assert.True(t, strings.HasSuffix(lines[0], `: Failed to play: 403 [command=play guild=123 track="Never Gonna Give You Up"]`), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "[DEBUG] log_format_test.go:"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[1], ": plain 1"), lines[1])
+}
+
+func TestJSONLogLines(t *testing.T) {
+	out := captureLogs(t, LogFormatJSON)
+
+	ctx := WithLogFields(WithRequest(context.Background(), RequestInfo{GuildID: "123", UserID: "456", Command: "play"}), "track", "Song")
+	LogWarnContext(ctx, "Queue is %d%% full", 90)
+	LogInfo("Started")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var warning map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &warning))
+	assert.Equal(t, "WARN", warning["level"])
+	assert.Equal(t, "Queue is 90% full", warning["msg"])
+	assert.Equal(t, "utils", warning["module"])
+	assert.Equal(t, "123", warning["guild_id"])
+	assert.Equal(t, "456", warning["user_id"])
+	assert.Equal(t, "play", warning["command"])
+	assert.Equal(t, "Song", warning["track"])
+	assert.Contains(t, warning["source"], "log_format_test.go:")
+	assert.NotEmpty(t, warning["time"])
+
+	var info map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &info))
+	assert.Equal(t, "INFO", info["level"])
+	assert.NotContains(t, info, "guild_id")
+}
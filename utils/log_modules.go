@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// modulePrefix is the import path prefix stripped from package paths to name modules
+const modulePrefix = "pxnx-discord-bot/"
+
+// moduleLevels holds per-module log level overrides; nil or empty when every module logs at the global level
+var moduleLevels atomic.Pointer[map[string]LogLevel]
+
+// String returns the level's name as accepted by ParseLogLevel
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelError:
+		return "error"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelDebug:
+		return "debug"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// ParseLogLevel converts a level name to a LogLevel, rejecting unknown names
+func ParseLogLevel(level string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "error":
+		return LogLevelError, nil
+	case "warn":
+		return LogLevelWarn, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "debug":
+		return LogLevelDebug, nil
+	default:
+		return LogLevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// ParseModuleLevels parses per-module levels such as "music=debug,services/ytdlp=warn". A module is a
+// package directory and includes its subpackages, so "music" also covers "music/download".
+func ParseModuleLevels(spec string) (map[string]LogLevel, error) {
+	levels := make(map[string]LogLevel)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		module, name, found := strings.Cut(entry, "=")
+		module = strings.Trim(strings.TrimSpace(module), "/")
+		if !found || module == "" {
+			return nil, fmt.Errorf("invalid module log level %q, expected module=level", entry)
+		}
+		level, err := ParseLogLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid module log level %q: %w", entry, err)
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
+
+// SetModuleLevels logs the given modules at their own level instead of the global one, so debug logging
+// can be turned on for one area without flooding the log with everything else
+func SetModuleLevels(levels map[string]LogLevel) {
+	if len(levels) == 0 {
+		moduleLevels.Store(nil)
+		return
+	}
+
+	copied := make(map[string]LogLevel, len(levels))
+	modules := make([]string, 0, len(levels))
+	for module, level := range levels {
+		copied[module] = level
+		modules = append(modules, module+"="+level.String())
+	}
+	moduleLevels.Store(&copied)
+
+	sort.Strings(modules)
+	LogInfo("Module log levels: %s", strings.Join(modules, ", "))
+}
+
+// LogEnabled reports whether a message at level would be logged from the calling package. Check it
+// before building expensive log arguments on hot paths.
+func LogEnabled(level LogLevel) bool {
+	return logEnabled(level, 1)
+}
+
+// logEnabled checks level against the module of the function skip frames above logEnabled's caller,
+// looking the module up only when overrides are set
+func logEnabled(level LogLevel, skip int) bool {
+	overrides := moduleLevels.Load()
+	if overrides == nil {
+		return currentLogLevel() >= level
+	}
+	return levelFor(callerModule(skip+1), *overrides) >= level
+}
+
+// levelFor returns the level of a module: its most specific override, or the global level
+func levelFor(module string, overrides map[string]LogLevel) LogLevel {
+	for {
+		if level, exists := overrides[module]; exists {
+			return level
+		}
+		slash := strings.LastIndex(module, "/")
+		if slash < 0 {
+			return currentLogLevel()
+		}
+		module = module[:slash]
+	}
+}
+
+// callerModule names the package of the function skip frames up the stack, such as "music/download"
+func callerModule(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	return packageOf(fn.Name())
+}
+
+// packageOf extracts the module name from a function name like pxnx-discord-bot/music.(*SimplePlayer).Play
+func packageOf(funcName string) string {
+	// Type parameters of generic functions can contain other package paths
+	if bracket := strings.Index(funcName, "["); bracket >= 0 {
+		funcName = funcName[:bracket]
+	}
+	slash := strings.LastIndex(funcName, "/")
+	if dot := strings.Index(funcName[slash+1:], "."); dot >= 0 {
+		funcName = funcName[:slash+1+dot]
+	}
+	return strings.TrimPrefix(funcName, modulePrefix)
+}
+
+// Sampler lets through the first of every n events, for logging from loops that run many times a second
+type Sampler struct {
+	every uint64
+	count atomic.Uint64
+}
+
+// NewSampler creates a sampler that allows one in every n events; n below 1 allows every event
+func NewSampler(n int) *Sampler {
+	if n < 1 {
+		n = 1
+	}
+	return &Sampler{every: uint64(n)}
+}
+
+// Allow counts an event and reports whether it is one to log
+func (s *Sampler) Allow() bool {
+	return (s.count.Add(1)-1)%s.every == 0
+}
+
+// Count returns how many events have been counted
+func (s *Sampler) Count() uint64 {
+	return s.count.Load()
+}
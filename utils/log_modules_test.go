@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModuleLevels(t *testing.T) {
+	levels, err := ParseModuleLevels(" music=debug, services/ytdlp=WARN ,,bot/=error")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]LogLevel{"music": LogLevelDebug, "services/ytdlp": LogLevelWarn, "bot": LogLevelError}, levels)
+
+	levels, err = ParseModuleLevels("")
+	require.NoError(t, err)
+	assert.Empty(t, levels)
+
+	for _, invalid := range []string{"music", "music=loud", "=debug"} {
+		_, err := ParseModuleLevels(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLevelFor(t *testing.T) {
+	original := currentLogLevel()
+	SetLogLevel(LogLevelInfo)
+	defer SetLogLevel(original)
+
+	overrides := map[string]LogLevel{"music": LogLevelDebug, "music/download": LogLevelWarn}
+	assert.Equal(t, LogLevelDebug, levelFor("music", overrides))
+	assert.Equal(t, LogLevelDebug, levelFor("music/queue", overrides), "modules cover their subpackages")
+	assert.Equal(t, LogLevelWarn, levelFor("music/download", overrides), "the most specific module wins")
+	assert.Equal(t, LogLevelInfo, levelFor("musicbot", overrides))
+	assert.Equal(t, LogLevelInfo, levelFor("commands", overrides))
+}
+
+func TestPackageOf(t *testing.T) {
+	assert.Equal(t, "music", packageOf("pxnx-discord-bot/music.(*SimplePlayer).Play.func1"))
+	assert.Equal(t, "services/ytdlp", packageOf("pxnx-discord-bot/services/ytdlp.(*Server).handle"))
+	assert.Equal(t, "utils", packageOf("pxnx-discord-bot/utils.NewLRUCache[go.shape.string,pxnx-discord-bot/music/types.AudioSource]"))
+	assert.Equal(t, "main", packageOf("main.main"))
+}
+
+func TestLogEnabledUsesCallerModule(t *testing.T) {
+	original := currentLogLevel()
+	SetLogLevel(LogLevelInfo)
+	defer SetLogLevel(original)
+	defer SetModuleLevels(nil)
+
+	assert.False(t, LogEnabled(LogLevelDebug))
+
+	SetModuleLevels(map[string]LogLevel{"utils": LogLevelDebug})
+	assert.True(t, LogEnabled(LogLevelDebug))
+
+	SetModuleLevels(map[string]LogLevel{"music": LogLevelDebug, "utils": LogLevelError})
+	assert.False(t, LogEnabled(LogLevelWarn))
+	assert.True(t, LogEnabled(LogLevelError))
+}
+
+func TestSampler(t *testing.T) {
+	sampler := NewSampler(3)
+	var allowed []bool
+	for range 7 {
+		allowed = append(allowed, sampler.Allow())
+	}
+	assert.Equal(t, []bool{true, false, false, true, false, false, true}, allowed)
+	assert.Equal(t, uint64(7), sampler.Count())
+
+	every := NewSampler(0)
+	assert.True(t, every.Allow())
+	assert.True(t, every.Allow())
+}
+
+func TestLogLevelNames(t *testing.T) {
+	for _, level := range []LogLevel{LogLevelError, LogLevelWarn, LogLevelInfo, LogLevelDebug} {
+		parsed, err := ParseLogLevel(level.String())
+		require.NoError(t, err)
+		assert.Equal(t, level, parsed)
+	}
+	assert.Equal(t, LogLevelInfo, GetLogLevelFromString("verbose"))
+}
@@ -0,0 +1,264 @@
+package utils
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogRotation is when the log file starts over and how long the old files are kept
+type LogRotation struct {
+	MaxSize  int64         // Bytes a file grows to before it is rotated, 0 rotates at midnight only
+	MaxAge   time.Duration // Rotated files last written longer ago are deleted, 0 keeps them
+	MaxFiles int           // Rotated files kept at most, newest first, 0 for no limit
+	Compress bool          // Gzip rotated files
+}
+
+// DefaultLogRotation rotates at 100 MB and keeps two weeks of compressed logs
+var DefaultLogRotation = LogRotation{MaxSize: 100 << 20, MaxAge: 14 * 24 * time.Hour, Compress: true}
+
+// logDateFormat names a day's log file, bot-2006-01-02.log
+const logDateFormat = "2006-01-02"
+
+// rotatingFile writes to bot-<day>.log in a directory, moving on to a new file every day and whenever
+// the file outgrows MaxSize. A day's earlier files are numbered bot-<day>.1.log, bot-<day>.2.log and so
+// on, then compressed and pruned in the background.
+type rotatingFile struct {
+	dir string
+	now func() time.Time
+
+	mu     sync.Mutex
+	config LogRotation
+	file   *os.File
+	day    string
+	size   int64
+
+	cleanupMu sync.Mutex
+	cleanups  sync.WaitGroup
+}
+
+// openRotatingFile opens today's log file in dir, appending to it when it exists. Files left by earlier
+// runs wait for the first rotation or SetRotation to be compressed and pruned.
+func openRotatingFile(dir string, config LogRotation) (*rotatingFile, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	r := &rotatingFile{dir: dir, config: config, now: time.Now}
+	if err := r.open(r.now().Format(logDateFormat)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Path returns the file being written
+func (r *rotatingFile) Path() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.path(r.day)
+}
+
+// path is a day's current log file
+func (r *rotatingFile) path(day string) string {
+	return filepath.Join(r.dir, "bot-"+day+".log")
+}
+
+// open starts writing to a day's log file
+func (r *rotatingFile) open(day string) error {
+	file, err := os.OpenFile(r.path(day), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	r.file, r.day, r.size = file, day, info.Size()
+	return nil
+}
+
+// Write appends to the current file, rotating it first when the day changed or p would take it past
+// MaxSize. A line is never split across files.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	day := r.now().Format(logDateFormat)
+	if day != r.day || (r.config.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.config.MaxSize) {
+		if err := r.rotate(day); err != nil {
+			// Keep writing to the full file rather than losing lines
+			fmt.Fprintf(os.Stderr, "Failed to rotate log file: %v\n", err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate closes the current file and opens the day's. A file still in use for the same day is numbered
+// first, a previous day's file keeps its name.
+func (r *rotatingFile) rotate(day string) error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	if day == r.day {
+		numbered := r.numberedPath(day)
+		if err := os.Rename(r.path(day), numbered); err != nil {
+			// Reopen the file so logging goes on
+			if openErr := r.open(day); openErr != nil {
+				return openErr
+			}
+			return err
+		}
+	}
+	if err := r.open(day); err != nil {
+		return err
+	}
+	r.cleanupLater()
+	return nil
+}
+
+// numberedPath is the next free name for a rotated file of a day
+func (r *rotatingFile) numberedPath(day string) string {
+	matches, _ := filepath.Glob(filepath.Join(r.dir, "bot-"+day+".*.log*"))
+	next := 1
+	for _, match := range matches {
+		number, _, _ := strings.Cut(strings.TrimPrefix(filepath.Base(match), "bot-"+day+"."), ".")
+		if n, err := strconv.Atoi(number); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return filepath.Join(r.dir, fmt.Sprintf("bot-%s.%d.log", day, next))
+}
+
+// SetRotation changes when the file rotates and prunes rotated files by the new limits
+func (r *rotatingFile) SetRotation(config LogRotation) {
+	r.mu.Lock()
+	r.config = config
+	r.mu.Unlock()
+	r.cleanupLater()
+}
+
+// cleanupLater runs cleanup in the background, since compressing a large file takes a while
+func (r *rotatingFile) cleanupLater() {
+	r.cleanups.Add(1)
+	SafeGo("log-cleanup", func() {
+		defer r.cleanups.Done()
+		r.cleanup()
+	})
+}
+
+// cleanup compresses rotated files and deletes those past MaxAge or MaxFiles
+func (r *rotatingFile) cleanup() {
+	r.cleanupMu.Lock()
+	defer r.cleanupMu.Unlock()
+
+	r.mu.Lock()
+	config, active := r.config, r.path(r.day)
+	r.mu.Unlock()
+
+	matches, err := filepath.Glob(filepath.Join(r.dir, "bot-*.log*"))
+	if err != nil {
+		return
+	}
+	type rotated struct {
+		path    string
+		modTime time.Time
+	}
+	var files []rotated
+	for _, path := range matches {
+		if path == active || !(strings.HasSuffix(path, ".log") || strings.HasSuffix(path, ".log.gz")) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if config.Compress && strings.HasSuffix(path, ".log") {
+			if compressed, err := compressLog(path, info.ModTime()); err != nil {
+				LogWarn("Failed to compress log file %s: %v", path, err)
+			} else {
+				path = compressed
+			}
+		}
+		files = append(files, rotated{path: path, modTime: info.ModTime()})
+	}
+
+	slices.SortFunc(files, func(a, b rotated) int { return b.modTime.Compare(a.modTime) })
+	now := r.now()
+	for n, file := range files {
+		tooOld := config.MaxAge > 0 && now.Sub(file.modTime) > config.MaxAge
+		tooMany := config.MaxFiles > 0 && n >= config.MaxFiles
+		if tooOld || tooMany {
+			if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+				LogWarn("Failed to delete old log file %s: %v", file.path, err)
+			}
+		}
+	}
+}
+
+// compressLog gzips a log file next to it and removes the original, keeping its modification time so
+// retention counts from the last line written
+func compressLog(path string, modTime time.Time) (string, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	compressed := path + ".gz"
+	tmp := compressed + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return "", err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, compressed)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	os.Chtimes(compressed, modTime, modTime)
+	in.Close()
+	return compressed, os.Remove(path)
+}
+
+// Close waits for background compression and closes the current file
+func (r *rotatingFile) Close() error {
+	r.cleanups.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// SetLogRotation changes when the log file rotates and how long rotated files are kept. InitLogger starts
+// with DefaultLogRotation.
+func SetLogRotation(config LogRotation) {
+	if logFile != nil {
+		logFile.SetRotation(config)
+	}
+}
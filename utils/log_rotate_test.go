@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestLog opens a rotating log in a temporary directory on a clock the test moves
+func openTestLog(t *testing.T, config LogRotation) (*rotatingFile, *time.Time) {
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	r, err := openRotatingFile(t.TempDir(), config)
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+
+	// Reopen today's file on the test clock
+	r.file.Close()
+	os.Remove(r.path(r.day))
+	r.now = func() time.Time { return now }
+	require.NoError(t, r.open(now.Format(logDateFormat)))
+	return r, &now
+}
+
+// logFiles lists the files in a log directory
+func logFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	r, _ := openTestLog(t, LogRotation{MaxSize: 16})
+
+	for _, line := range []string{"first line\n", "second line\n", "third line\n"} {
+		_, err := r.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	r.cleanups.Wait()
+
+	assert.ElementsMatch(t, []string{"bot-2025-06-01.log", "bot-2025-06-01.1.log", "bot-2025-06-01.2.log"}, logFiles(t, r.dir))
+	first, _ := os.ReadFile(filepath.Join(r.dir, "bot-2025-06-01.1.log"))
+	assert.Equal(t, "first line\n", string(first), "lines are never split across files")
+	current, _ := os.ReadFile(r.Path())
+	assert.Equal(t, "third line\n", string(current))
+}
+
+func TestRotatingFileRotatesDailyAndCompresses(t *testing.T) {
+	r, now := openTestLog(t, LogRotation{Compress: true})
+
+	r.Write([]byte("saturday\n"))
+	*now = now.Add(24 * time.Hour)
+	r.Write([]byte("sunday\n"))
+	r.cleanups.Wait()
+
+	assert.ElementsMatch(t, []string{"bot-2025-06-01.log.gz", "bot-2025-06-02.log"}, logFiles(t, r.dir))
+	file, err := os.Open(filepath.Join(r.dir, "bot-2025-06-01.log.gz"))
+	require.NoError(t, err)
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "saturday\n", string(content))
+}
+
+func TestRotatingFileRetention(t *testing.T) {
+	r, now := openTestLog(t, LogRotation{})
+	for name, age := range map[string]time.Duration{
+		"bot-2025-05-31.log.gz": 24 * time.Hour,
+		"bot-2025-05-30.log.gz": 2 * 24 * time.Hour,
+		"bot-2025-05-29.2.log":  3 * 24 * time.Hour,
+		"bot-2025-05-20.log.gz": 12 * 24 * time.Hour,
+	} {
+		path := filepath.Join(r.dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("old\n"), 0644))
+		require.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(r.dir, "notes.txt"), nil, 0644))
+
+	r.SetRotation(LogRotation{MaxAge: 7 * 24 * time.Hour, MaxFiles: 2})
+	r.cleanups.Wait()
+
+	assert.ElementsMatch(t, []string{"bot-2025-06-01.log", "bot-2025-05-31.log.gz", "bot-2025-05-30.log.gz", "notes.txt"}, logFiles(t, r.dir),
+		"the newest two rotated files are kept, other files are left alone")
+
+	r.SetRotation(LogRotation{MaxAge: 36 * time.Hour})
+	r.cleanups.Wait()
+	assert.ElementsMatch(t, []string{"bot-2025-06-01.log", "bot-2025-05-31.log.gz", "notes.txt"}, logFiles(t, r.dir))
+}
+
+func TestRotatingFileClose(t *testing.T) {
+	r, _ := openTestLog(t, DefaultLogRotation)
+	require.NoError(t, r.Close())
+	_, err := r.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
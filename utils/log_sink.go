@@ -0,0 +1,47 @@
+package utils
+
+import "sync"
+
+// LogSink receives warnings and errors as they are logged, to forward them somewhere else.
+// Log runs on the goroutine that logged, so it must return quickly, and a sink must not log
+// errors from inside Log or it receives its own entries.
+type LogSink interface {
+	Log(entry LogEntry)
+}
+
+var (
+	logSinksMu sync.RWMutex
+	logSinks   []LogSink
+)
+
+// AddLogSink starts handing warnings and errors to a sink and returns a function that stops it
+func AddLogSink(sink LogSink) (remove func()) {
+	logSinksMu.Lock()
+	logSinks = append(logSinks, sink)
+	logSinksMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			logSinksMu.Lock()
+			defer logSinksMu.Unlock()
+			for index, added := range logSinks {
+				if added == sink {
+					logSinks = append(logSinks[:index:index], logSinks[index+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// publishLog hands an entry to every sink
+func publishLog(entry LogEntry) {
+	logSinksMu.RLock()
+	sinks := logSinks
+	logSinksMu.RUnlock()
+
+	for _, sink := range sinks {
+		sink.Log(entry)
+	}
+}
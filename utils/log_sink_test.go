@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	entries []LogEntry
+}
+
+func (s *recordingSink) Log(entry LogEntry) {
+	s.entries = append(s.entries, entry)
+}
+
+func TestLogSinks(t *testing.T) {
+	first, second := &recordingSink{}, &recordingSink{}
+	removeFirst := AddLogSink(first)
+	removeSecond := AddLogSink(second)
+	defer removeSecond()
+
+	LogError("%s failed", "upload")
+	LogWarn("disk almost full")
+	LogInfo("not forwarded")
+
+	require.Len(t, first.entries, 2)
+	assert.Equal(t, LogLevelError, first.entries[0].Level)
+	assert.Equal(t, "upload failed", first.entries[0].Message)
+	assert.Equal(t, "utils", first.entries[0].Module)
+	assert.Equal(t, LogLevelWarn, first.entries[1].Level)
+	assert.Equal(t, first.entries, second.entries)
+
+	removeFirst()
+	removeFirst()
+	LogError("after removal")
+	assert.Len(t, first.entries, 2, "removed sinks get nothing more")
+	assert.Len(t, second.entries, 3)
+}
@@ -1,12 +1,12 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"path/filepath"
-	"time"
+	"sync/atomic"
 )
 
 // LogLevel represents the severity of log messages
@@ -24,29 +24,25 @@ var (
 	warnLogger  *log.Logger
 	infoLogger  *log.Logger
 	debugLogger *log.Logger
-	logFile     *os.File
-	currentLogLevel = LogLevelInfo // Default to Info level
+	logFile     *rotatingFile
+	globalLogLevel atomic.Int32 // LogLevel of modules without an override, Info until InitLogger or SetLogLevel
 )
 
+func init() {
+	globalLogLevel.Store(int32(LogLevelInfo))
+}
+
 // InitLogger initializes the logging system with file output
 func InitLogger(logDir string, logLevel LogLevel) error {
-	currentLogLevel = logLevel
-
-	// Create logs directory if it doesn't exist
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("failed to create log directory: %w", err)
-	}
-
-	// Create log file with timestamp
-	timestamp := time.Now().Format("2006-01-02")
-	logFileName := fmt.Sprintf("bot-%s.log", timestamp)
-	logPath := filepath.Join(logDir, logFileName)
+	SetLogLevel(logLevel)
 
+	// Write to a file per day, rotated and pruned by DefaultLogRotation until SetLogRotation
 	var err error
-	logFile, err = os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	logFile, err = openRotatingFile(logDir, DefaultLogRotation)
 	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+		return err
 	}
+	logPath := logFile.Path()
 
 	// Create writers that output to both file and stdout for different levels
 	errorWriter := io.MultiWriter(logFile, os.Stderr)
@@ -59,58 +55,63 @@ func InitLogger(logDir string, logLevel LogLevel) error {
 	warnLogger = log.New(warnWriter, "[WARN]  ", log.Ldate|log.Ltime|log.Lshortfile)
 	infoLogger = log.New(infoWriter, "[INFO]  ", log.Ldate|log.Ltime|log.Lshortfile)
 	debugLogger = log.New(debugWriter, "[DEBUG] ", log.Ldate|log.Ltime|log.Lshortfile)
+	logWriters = [...]io.Writer{LogLevelError: errorWriter, LogLevelWarn: warnWriter, LogLevelInfo: infoWriter, LogLevelDebug: debugWriter}
+	configureLogOutputs()
 
 	LogInfo("Logger initialized - Level: %v, File: %s", logLevel, logPath)
 	return nil
 }
 
-// CloseLogger closes the log file
+// CloseLogger finishes compressing rotated logs and closes the log file
 func CloseLogger() {
 	if logFile != nil {
 		logFile.Close()
 	}
 }
 
-// LogError logs error messages (always visible)
+// LogError logs error messages (always visible) and keeps them for /admin logs
 func LogError(format string, args ...interface{}) {
-	if errorLogger != nil {
-		errorLogger.Printf(format, args...)
-	}
+	message := fmt.Sprintf(format, args...)
+	bufferLog(LogLevelError, message, RequestInfo{}, 1)
+	writeLog(context.Background(), LogLevelError, 1, message, RequestInfo{})
 }
 
-// LogWarn logs warning messages
+// LogWarn logs warning messages and keeps them for /admin logs
 func LogWarn(format string, args ...interface{}) {
-	if currentLogLevel >= LogLevelWarn && warnLogger != nil {
-		warnLogger.Printf(format, args...)
+	if !logEnabled(LogLevelWarn, 1) {
+		return
 	}
+	message := fmt.Sprintf(format, args...)
+	bufferLog(LogLevelWarn, message, RequestInfo{}, 1)
+	writeLog(context.Background(), LogLevelWarn, 1, message, RequestInfo{})
 }
 
 // LogInfo logs info messages
 func LogInfo(format string, args ...interface{}) {
-	if currentLogLevel >= LogLevelInfo && infoLogger != nil {
-		infoLogger.Printf(format, args...)
+	if logEnabled(LogLevelInfo, 1) {
+		writeLog(context.Background(), LogLevelInfo, 1, fmt.Sprintf(format, args...), RequestInfo{})
 	}
 }
 
 // LogDebug logs debug messages
 func LogDebug(format string, args ...interface{}) {
-	if currentLogLevel >= LogLevelDebug && debugLogger != nil {
-		debugLogger.Printf(format, args...)
+	if logEnabled(LogLevelDebug, 1) {
+		writeLog(context.Background(), LogLevelDebug, 1, fmt.Sprintf(format, args...), RequestInfo{})
 	}
 }
 
-// GetLogLevelFromString converts string to LogLevel
+// SetLogLevel changes the level of modules without their own level; it can change while the bot runs
+func SetLogLevel(level LogLevel) {
+	globalLogLevel.Store(int32(level))
+}
+
+// currentLogLevel is the level of modules without their own level
+func currentLogLevel() LogLevel {
+	return LogLevel(globalLogLevel.Load())
+}
+
+// GetLogLevelFromString converts string to LogLevel, Info for unknown names
 func GetLogLevelFromString(level string) LogLevel {
-	switch level {
-	case "error":
-		return LogLevelError
-	case "warn":
-		return LogLevelWarn
-	case "info":
-		return LogLevelInfo
-	case "debug":
-		return LogLevelDebug
-	default:
-		return LogLevelInfo
-	}
+	logLevel, _ := ParseLogLevel(level)
+	return logLevel
 }
\ No newline at end of file
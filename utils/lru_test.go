@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRUCache[string, int](2, 0)
+
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+
+	// Touch "a" so "b" becomes the least recently used entry
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	cache.Add("c", 3)
+	assert.Equal(t, 2, cache.Len())
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+}
+
+func TestLRUCacheUpdateAndRemove(t *testing.T) {
+	cache := NewLRUCache[string, int](2, 0)
+
+	cache.Add("a", 1)
+	cache.Add("a", 10)
+	assert.Equal(t, 1, cache.Len())
+
+	value, _ := cache.Get("a")
+	assert.Equal(t, 10, value)
+
+	cache.Remove("a")
+	assert.Equal(t, 0, cache.Len())
+	cache.Remove("missing")
+
+	cache.Add("b", 2)
+	cache.Add("c", 3)
+	cache.Purge()
+	assert.Equal(t, 0, cache.Len())
+	_, ok := cache.Get("b")
+	assert.False(t, ok)
+}
+
+func TestLRUCacheTTL(t *testing.T) {
+	cache := NewLRUCache[string, int](2, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.Add("a", 1)
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Len(), "expired entries are dropped on access")
+}
+
+func TestLRUCacheMinimumCapacity(t *testing.T) {
+	cache := NewLRUCache[int, int](0, 0)
+	assert.Equal(t, 1, cache.Cap())
+
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+	assert.Equal(t, 1, cache.Len())
+}
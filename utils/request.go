@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// RequestInfo identifies the interaction work is done for, so logs and error reports can be attributed
+// to a server, a member and a command
+type RequestInfo struct {
+	InteractionID string
+	GuildID       string
+	UserID        string
+	Locale        string
+	Command       string // Command name, or the custom ID of a component
+}
+
+// requestKey is the context key RequestInfo is stored under
+type requestKey struct{}
+
+// WithRequest returns a context carrying the interaction's request info
+func WithRequest(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestKey{}, info)
+}
+
+// RequestFrom returns the request info of a context, if it carries any
+func RequestFrom(ctx context.Context) (RequestInfo, bool) {
+	if ctx == nil {
+		return RequestInfo{}, false
+	}
+	info, ok := ctx.Value(requestKey{}).(RequestInfo)
+	return info, ok
+}
+
+// IsZero reports whether no request is set
+func (r RequestInfo) IsZero() bool {
+	return r == RequestInfo{}
+}
+
+// String lists the set fields, such as "command=play guild=123 user=456 interaction=789"
+func (r RequestInfo) String() string {
+	var fields []string
+	for _, field := range []struct{ name, value string }{
+		{"command", r.Command},
+		{"guild", r.GuildID},
+		{"user", r.UserID},
+		{"locale", r.Locale},
+		{"interaction", r.InteractionID},
+	} {
+		if field.value != "" {
+			fields = append(fields, field.name+"="+field.value)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// LogErrorContext logs an error attributed to the request in ctx. The request is kept with the entry
+// for /admin logs and log sinks, and written to the log file with the fields of WithLogFields.
+func LogErrorContext(ctx context.Context, format string, args ...interface{}) {
+	request, _ := RequestFrom(ctx)
+	message := fmt.Sprintf(format, args...)
+	bufferLog(LogLevelError, message, request, 1)
+	writeLog(ctx, LogLevelError, 1, message, request)
+}
+
+// LogWarnContext logs a warning attributed to the request in ctx
+func LogWarnContext(ctx context.Context, format string, args ...interface{}) {
+	if !logEnabled(LogLevelWarn, 1) {
+		return
+	}
+	request, _ := RequestFrom(ctx)
+	message := fmt.Sprintf(format, args...)
+	bufferLog(LogLevelWarn, message, request, 1)
+	writeLog(ctx, LogLevelWarn, 1, message, request)
+}
+
+// LogInfoContext logs an info message attributed to the request in ctx
+func LogInfoContext(ctx context.Context, format string, args ...interface{}) {
+	if logEnabled(LogLevelInfo, 1) {
+		request, _ := RequestFrom(ctx)
+		writeLog(ctx, LogLevelInfo, 1, fmt.Sprintf(format, args...), request)
+	}
+}
+
+// LogDebugContext logs a debug message attributed to the request in ctx
+func LogDebugContext(ctx context.Context, format string, args ...interface{}) {
+	if logEnabled(LogLevelDebug, 1) {
+		request, _ := RequestFrom(ctx)
+		writeLog(ctx, LogLevelDebug, 1, fmt.Sprintf(format, args...), request)
+	}
+}
+
+// withRequest appends the request and extra fields to a text log message
+func withRequest(message string, request RequestInfo, fields ...slog.Attr) string {
+	if request.IsZero() && len(fields) == 0 {
+		return message
+	}
+	parts := []string{}
+	if !request.IsZero() {
+		parts = append(parts, request.String())
+	}
+	for _, field := range fields {
+		parts = append(parts, formatField(field))
+	}
+	return message + " [" + strings.Join(parts, " ") + "]"
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestContext(t *testing.T) {
+	_, ok := RequestFrom(context.Background())
+	assert.False(t, ok)
+
+	info := RequestInfo{InteractionID: "789", GuildID: "123", UserID: "456", Locale: "en-US", Command: "play"}
+	ctx, cancel := context.WithCancel(WithRequest(context.Background(), info))
+	defer cancel()
+
+	got, ok := RequestFrom(ctx)
+	require.True(t, ok, "derived contexts keep the request")
+	assert.Equal(t, info, got)
+	assert.Equal(t, "command=play guild=123 user=456 locale=en-US interaction=789", got.String())
+	assert.Equal(t, "guild=123", RequestInfo{GuildID: "123"}.String())
+}
+
+func TestLogContextAttributesEntries(t *testing.T) {
+	sink := &recordingSink{}
+	defer AddLogSink(sink)()
+
+	info := RequestInfo{GuildID: "123", Command: "play"}
+	LogErrorContext(WithRequest(context.Background(), info), "%s failed", "search")
+	LogWarnContext(context.Background(), "outside of a request")
+
+	require.Len(t, sink.entries, 2)
+	assert.Equal(t, "search failed", sink.entries[0].Message, "the request is kept apart from the message")
+	assert.Equal(t, info, sink.entries[0].Request)
+	assert.Equal(t, "utils", sink.entries[0].Module)
+	assert.True(t, sink.entries[1].Request.IsZero())
+
+	assert.Equal(t, "search failed [command=play guild=123]", withRequest("search failed", info))
+	assert.Equal(t, "search failed", withRequest("search failed", RequestInfo{}))
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// RestartPolicy controls whether a goroutine started with SafeGoWithRestart is restarted after a panic
+type RestartPolicy struct {
+	MaxRestarts int           // Restarts allowed after the first panic, a negative value restarts forever
+	Backoff     time.Duration // Delay before each restart
+}
+
+var (
+	panicCounts   = make(map[string]int64)
+	panicCountsMu sync.Mutex
+)
+
+// SafeGo runs fn in a new goroutine, recovering and logging any panic instead of crashing the process
+func SafeGo(name string, fn func()) {
+	go runRecovered(name, fn)
+}
+
+// SafeGoWithRestart runs fn in a new goroutine and restarts it according to policy when it panics.
+// A normal return ends the goroutine without a restart.
+func SafeGoWithRestart(name string, policy RestartPolicy, fn func()) {
+	go runWithRestart(name, policy, fn)
+}
+
+// runWithRestart runs fn until it returns normally or the restart budget is spent
+func runWithRestart(name string, policy RestartPolicy, fn func()) {
+	for restarts := 0; ; restarts++ {
+		if !runRecovered(name, fn) {
+			return
+		}
+		if policy.MaxRestarts >= 0 && restarts >= policy.MaxRestarts {
+			LogError("Goroutine %s panicked too often, not restarting after %d restarts", name, restarts)
+			return
+		}
+
+		LogWarn("Restarting goroutine %s in %v (restart %d)", name, policy.Backoff, restarts+1)
+		time.Sleep(policy.Backoff)
+	}
+}
+
+// runRecovered calls fn and reports whether it panicked
+func runRecovered(name string, fn func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			recordPanic(name)
+			LogError("Recovered panic in goroutine %s: %v\n%s", name, r, debug.Stack())
+		}
+	}()
+
+	fn()
+	return false
+}
+
+// RecordPanic logs a panic a caller recovered itself and counts it under name, with the panics of goroutines
+// started by SafeGo
+func RecordPanic(name string, recovered any) {
+	recordPanic(name)
+	LogError("Recovered panic in %s: %v\n%s", name, recovered, debug.Stack())
+}
+
+// recordPanic increments the panic counter for a goroutine name
+func recordPanic(name string) {
+	panicCountsMu.Lock()
+	defer panicCountsMu.Unlock()
+	panicCounts[name]++
+}
+
+// PanicCounts returns a snapshot of recovered panics per goroutine name
+func PanicCounts() map[string]int64 {
+	panicCountsMu.Lock()
+	defer panicCountsMu.Unlock()
+
+	counts := make(map[string]int64, len(panicCounts))
+	for name, count := range panicCounts {
+		counts[name] = count
+	}
+	return counts
+}
+
+// TotalPanics returns the number of recovered panics across all goroutines
+func TotalPanics() int64 {
+	var total int64
+	for _, count := range PanicCounts() {
+		total += count
+	}
+	return total
+}
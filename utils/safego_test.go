@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunRecovered(t *testing.T) {
+	before := PanicCounts()["test.recovered"]
+
+	panicked := runRecovered("test.recovered", func() { panic("boom") })
+	assert.True(t, panicked)
+	assert.Equal(t, before+1, PanicCounts()["test.recovered"])
+
+	panicked = runRecovered("test.recovered", func() {})
+	assert.False(t, panicked)
+	assert.Equal(t, before+1, PanicCounts()["test.recovered"])
+}
+
+func TestSafeGoRecoversPanic(t *testing.T) {
+	done := make(chan struct{})
+	SafeGo("test.safego", func() {
+		defer close(done)
+		panic("boom")
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("goroutine did not run")
+	}
+}
+
+func TestRunWithRestartStopsAfterBudget(t *testing.T) {
+	var runs int32
+	runWithRestart("test.restart", RestartPolicy{MaxRestarts: 2}, func() {
+		atomic.AddInt32(&runs, 1)
+		panic("boom")
+	})
+	assert.Equal(t, int32(3), runs, "initial run plus two restarts")
+}
+
+func TestRunWithRestartStopsOnNormalReturn(t *testing.T) {
+	var runs int32
+	runWithRestart("test.restart_ok", RestartPolicy{MaxRestarts: -1}, func() {
+		if atomic.AddInt32(&runs, 1) < 3 {
+			panic("flaky")
+		}
+	})
+	assert.Equal(t, int32(3), runs)
+}
+
+func TestTotalPanics(t *testing.T) {
+	before := TotalPanics()
+	runRecovered("test.total", func() { panic("boom") })
+	assert.Equal(t, before+1, TotalPanics())
+}
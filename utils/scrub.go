@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// sensitiveURLParams are query parameters that sign or authorize a URL, such as those on YouTube stream URLs
+var sensitiveURLParams = map[string]bool{
+	"sig":       true,
+	"signature": true,
+	"lsig":      true,
+	"n":         true,
+	"ip":        true,
+	"token":     true,
+	"key":       true,
+	"api_key":   true,
+	"appid":     true,
+}
+
+// ScrubURL redacts signature and credential query parameters so a URL can be shared in a bug report.
+// Strings that are not absolute URLs are returned unchanged.
+func ScrubURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Scheme == "" || parsed.RawQuery == "" {
+		return raw
+	}
+
+	query := parsed.Query()
+	scrubbed := false
+	for key := range query {
+		if sensitiveURLParams[strings.ToLower(key)] {
+			query.Set(key, "REDACTED")
+			scrubbed = true
+		}
+	}
+	if !scrubbed {
+		return raw
+	}
+
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "stream URL signatures are redacted",
+			input:    "https://rr1.googlevideo.com/videoplayback?expire=1700000000&ip=1.2.3.4&sig=abc&lsig=def&itag=251",
+			expected: "https://rr1.googlevideo.com/videoplayback?expire=1700000000&ip=REDACTED&itag=251&lsig=REDACTED&sig=REDACTED",
+		},
+		{
+			name:     "api keys are redacted",
+			input:    "https://api.openweathermap.org/data/2.5/weather?q=Vilnius&appid=secret",
+			expected: "https://api.openweathermap.org/data/2.5/weather?appid=REDACTED&q=Vilnius",
+		},
+		{
+			name:     "watch URLs are kept",
+			input:    "https://www.youtube.com/watch?v=abc123&list=RDabc123",
+			expected: "https://www.youtube.com/watch?v=abc123&list=RDabc123",
+		},
+		{name: "plain text is kept", input: "not a url", expected: "not a url"},
+		{name: "empty string", input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ScrubURL(tt.input))
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrPoolFull is returned when a worker pool's queue has no room for another task
+var ErrPoolFull = errors.New("worker pool queue is full")
+
+// WorkerPool runs background tasks of one feature on at most a fixed number of goroutines, queueing
+// the rest. Workers are started as tasks arrive and exit once the queue is empty, so an idle pool holds
+// no goroutines. Panics in tasks are recovered and counted like SafeGo.
+type WorkerPool struct {
+	name     string
+	maxQueue int
+
+	mu        sync.Mutex
+	workers   int
+	running   int
+	queue     []func()
+	completed int64
+	rejected  int64
+}
+
+// PoolStats is a snapshot of a worker pool for reports
+type PoolStats struct {
+	Name      string
+	Workers   int   // Most tasks run at once
+	Running   int   // Workers currently running
+	Queued    int   // Tasks waiting for a worker
+	Completed int64 // Tasks finished, including ones that panicked
+	Rejected  int64 // Tasks refused because the queue was full
+}
+
+var (
+	workerPoolsMu sync.Mutex
+	workerPools   = make(map[string]*WorkerPool)
+	poolSizes     = make(map[string]int) // Configured sizes, also applied to pools created later
+)
+
+// NewWorkerPool creates and registers a pool running up to workers tasks at once with up to queueSize
+// waiting. A size set with ConfigureWorkerPools for the name wins over workers.
+func NewWorkerPool(name string, workers, queueSize int) *WorkerPool {
+	workerPoolsMu.Lock()
+	defer workerPoolsMu.Unlock()
+
+	if configured, exists := poolSizes[name]; exists {
+		workers = configured
+	}
+	pool := &WorkerPool{name: name, workers: max(workers, 1), maxQueue: max(queueSize, 0)}
+	workerPools[name] = pool
+	return pool
+}
+
+// Submit queues a task without waiting for it to run
+func (p *WorkerPool) Submit(task func()) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Tasks start right away while a worker is free, the queue only holds what has to wait
+	if len(p.queue) >= p.maxQueue && p.running >= p.workers {
+		p.rejected++
+		return fmt.Errorf("%s: %w", p.name, ErrPoolFull)
+	}
+	p.queue = append(p.queue, task)
+	if p.running < p.workers {
+		p.running++
+		go p.work()
+	}
+	return nil
+}
+
+// Do runs a task in the pool and waits until it is done or ctx ends. A task that already started
+// keeps running after ctx ends, so it should watch ctx itself.
+func (p *WorkerPool) Do(ctx context.Context, task func()) error {
+	done := make(chan struct{})
+	if err := p.Submit(func() {
+		defer close(done)
+		task()
+	}); err != nil {
+		return err
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work runs queued tasks until the queue is empty or the pool shrank below the running workers
+func (p *WorkerPool) work() {
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 || p.running > p.workers {
+			p.running--
+			p.mu.Unlock()
+			return
+		}
+		task := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+
+		runRecovered("pool."+p.name, task)
+
+		p.mu.Lock()
+		p.completed++
+		p.mu.Unlock()
+	}
+}
+
+// SetWorkers changes how many tasks run at once; extra workers finish their current task first
+func (p *WorkerPool) SetWorkers(workers int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.workers = max(workers, 1)
+	for p.running < p.workers && p.running < len(p.queue) {
+		p.running++
+		go p.work()
+	}
+}
+
+// Stats returns a snapshot of the pool
+func (p *WorkerPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolStats{
+		Name:      p.name,
+		Workers:   p.workers,
+		Running:   p.running,
+		Queued:    len(p.queue),
+		Completed: p.completed,
+		Rejected:  p.rejected,
+	}
+}
+
+// WorkerPoolStats returns a snapshot of every pool, by name
+func WorkerPoolStats() []PoolStats {
+	workerPoolsMu.Lock()
+	pools := make([]*WorkerPool, 0, len(workerPools))
+	for _, pool := range workerPools {
+		pools = append(pools, pool)
+	}
+	workerPoolsMu.Unlock()
+
+	stats := make([]PoolStats, 0, len(pools))
+	for _, pool := range pools {
+		stats = append(stats, pool.Stats())
+	}
+	sort.Slice(stats, func(a, b int) bool { return stats[a].Name < stats[b].Name })
+	return stats
+}
+
+// ConfigureWorkerPools sets pool sizes by name, for pools that exist and ones created later
+func ConfigureWorkerPools(sizes map[string]int) {
+	workerPoolsMu.Lock()
+	defer workerPoolsMu.Unlock()
+
+	for name, workers := range sizes {
+		poolSizes[name] = workers
+		if pool, exists := workerPools[name]; exists {
+			pool.SetWorkers(workers)
+		}
+	}
+}
+
+// ParsePoolSizes parses pool sizes like "prefetch=8,playlist=2"
+func ParsePoolSizes(raw string) (map[string]int, error) {
+	sizes := make(map[string]int)
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid pool size %q, expected name=workers", pair)
+		}
+		workers, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || workers < 1 {
+			return nil, fmt.Errorf("invalid worker count %q for pool %s", value, name)
+		}
+		sizes[name] = workers
+	}
+	return sizes, nil
+}
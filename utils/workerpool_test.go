@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForStats polls a pool until its stats match, workers exit on their own goroutines
+func waitForStats(t *testing.T, pool *WorkerPool, match func(PoolStats) bool) PoolStats {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		stats := pool.Stats()
+		if match(stats) {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pool stats never matched, last %+v", stats)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWorkerPoolBoundsConcurrency(t *testing.T) {
+	pool := NewWorkerPool("test.bounded", 2, 10)
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		require.NoError(t, pool.Submit(func() {
+			defer wg.Done()
+			mu.Lock()
+			active++
+			peak = max(peak, active)
+			mu.Unlock()
+
+			<-release
+
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}))
+	}
+
+	stats := waitForStats(t, pool, func(s PoolStats) bool { return s.Queued == 4 })
+	assert.Equal(t, 2, stats.Running)
+
+	close(release)
+	wg.Wait()
+	stats = waitForStats(t, pool, func(s PoolStats) bool { return s.Running == 0 })
+	assert.Equal(t, int64(6), stats.Completed)
+	assert.Equal(t, 2, peak)
+}
+
+func TestWorkerPoolRejectsWhenFull(t *testing.T) {
+	pool := NewWorkerPool("test.full", 1, 1)
+	release := make(chan struct{})
+	defer close(release)
+
+	require.NoError(t, pool.Submit(func() { <-release }))
+	waitForStats(t, pool, func(s PoolStats) bool { return s.Queued == 0 })
+	require.NoError(t, pool.Submit(func() {}))
+
+	err := pool.Submit(func() {})
+	assert.ErrorIs(t, err, ErrPoolFull)
+	assert.Equal(t, int64(1), pool.Stats().Rejected)
+}
+
+func TestWorkerPoolDo(t *testing.T) {
+	pool := NewWorkerPool("test.do", 1, 4)
+
+	ran := false
+	require.NoError(t, pool.Do(context.Background(), func() { ran = true }))
+	assert.True(t, ran)
+
+	release := make(chan struct{})
+	defer close(release)
+	require.NoError(t, pool.Submit(func() { <-release }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := pool.Do(ctx, func() {})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "waiting for a busy pool ends with ctx")
+}
+
+func TestWorkerPoolRecoversPanics(t *testing.T) {
+	pool := NewWorkerPool("test.panic", 1, 4)
+	before := PanicCounts()["pool.test.panic"]
+
+	require.NoError(t, pool.Do(context.Background(), func() { panic("boom") }))
+	waitForStats(t, pool, func(s PoolStats) bool { return s.Completed == 1 && s.Running == 0 })
+	assert.Equal(t, before+1, PanicCounts()["pool.test.panic"])
+
+	require.NoError(t, pool.Do(context.Background(), func() {}), "the pool keeps working after a panic")
+}
+
+func TestConfigureWorkerPools(t *testing.T) {
+	existing := NewWorkerPool("test.configured", 1, 4)
+	ConfigureWorkerPools(map[string]int{"test.configured": 3, "test.later": 5})
+
+	assert.Equal(t, 3, existing.Stats().Workers)
+	assert.Equal(t, 5, NewWorkerPool("test.later", 1, 4).Stats().Workers, "configured sizes win over defaults")
+
+	var names []string
+	for _, stats := range WorkerPoolStats() {
+		names = append(names, stats.Name)
+	}
+	assert.IsIncreasing(t, names)
+	assert.Contains(t, names, "test.configured")
+}
+
+func TestParsePoolSizes(t *testing.T) {
+	sizes, err := ParsePoolSizes(" prefetch=8, playlist = 2 ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"prefetch": 8, "playlist": 2}, sizes)
+
+	for _, invalid := range []string{"prefetch", "=2", "prefetch=0", "prefetch=many"} {
+		_, err := ParsePoolSizes(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
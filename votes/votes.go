@@ -0,0 +1,126 @@
+package votes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"pxnx-discord-bot/utils"
+)
+
+// DefaultPath is where votes are saved when VOTES_FILE is not set
+const DefaultPath = "data/votes.json"
+
+// PerkDuration is how long a vote's perks last. Bot lists such as top.gg allow a vote every 12 hours, so
+// members who vote whenever they can keep their perks.
+const PerkDuration = 12 * time.Hour
+
+// Vote is what is known about a member's votes on bot lists
+type Vote struct {
+	Last  time.Time `json:"last"`  // Most recent vote
+	Site  string    `json:"site"`  // Bot list of the most recent vote, such as "top.gg"
+	Total int       `json:"total"` // Votes received since the bot started recording them
+}
+
+// PerkExpires returns when the perks of the most recent vote end
+func (v Vote) PerkExpires() time.Time {
+	return v.Last.Add(PerkDuration)
+}
+
+// Store keeps the votes of each member in memory and writes them to a JSON file on every vote
+type Store struct {
+	path string
+	now  func() time.Time
+
+	mu     sync.RWMutex
+	voters map[string]Vote
+}
+
+// New creates a store that only lives in memory
+func New() *Store {
+	return &Store{now: time.Now, voters: make(map[string]Vote)}
+}
+
+// Load opens the votes file at path; a missing file starts without votes
+func Load(path string) (*Store, error) {
+	store := New()
+	store.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read votes: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.voters); err != nil {
+		return nil, fmt.Errorf("failed to parse votes %s: %w", path, err)
+	}
+	if store.voters == nil {
+		store.voters = make(map[string]Vote)
+	}
+	return store, nil
+}
+
+// Record adds a vote by a member on a bot list and saves it
+func (s *Store) Record(userID, site string) (Vote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vote := s.voters[userID]
+	vote.Last = s.now()
+	vote.Site = site
+	vote.Total++
+	s.voters[userID] = vote
+	return vote, s.save()
+}
+
+// Get returns a member's votes, false when they never voted
+func (s *Store) Get(userID string) (Vote, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	vote, exists := s.voters[userID]
+	return vote, exists
+}
+
+// HasPerk reports whether a member voted within the last PerkDuration
+func (s *Store) HasPerk(userID string) bool {
+	vote, exists := s.Get(userID)
+	return exists && s.now().Before(vote.PerkExpires())
+}
+
+// Voters returns how many members voted so far and how many of them have perks right now
+func (s *Store) Voters() (total, active int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+	for _, vote := range s.voters {
+		if now.Before(vote.PerkExpires()) {
+			active++
+		}
+	}
+	return len(s.voters), active
+}
+
+// save writes every voter to the file (caller holds the lock)
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.voters, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode votes: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save votes: %w", err)
+	}
+	return nil
+}
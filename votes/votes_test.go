@@ -0,0 +1,63 @@
+package votes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRecordsAndPersistsVotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "votes.json")
+	store, err := Load(path)
+	require.NoError(t, err)
+
+	_, exists := store.Get("user_1")
+	assert.False(t, exists)
+
+	_, err = store.Record("user_1", "top.gg")
+	require.NoError(t, err)
+	vote, err := store.Record("user_1", "discordbotlist")
+	require.NoError(t, err)
+	assert.Equal(t, 2, vote.Total)
+	assert.Equal(t, "discordbotlist", vote.Site)
+
+	reloaded, err := Load(path)
+	require.NoError(t, err)
+	saved, exists := reloaded.Get("user_1")
+	require.True(t, exists)
+	assert.Equal(t, 2, saved.Total)
+	assert.True(t, saved.Last.Equal(vote.Last))
+}
+
+func TestStorePerksExpire(t *testing.T) {
+	now := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	store := New()
+	store.now = func() time.Time { return now }
+
+	vote, err := store.Record("user_1", "top.gg")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(PerkDuration), vote.PerkExpires())
+	assert.True(t, store.HasPerk("user_1"))
+	assert.False(t, store.HasPerk("user_2"))
+
+	now = now.Add(PerkDuration)
+	assert.False(t, store.HasPerk("user_1"), "perks end PerkDuration after the vote")
+
+	_, err = store.Record("user_2", "top.gg")
+	require.NoError(t, err)
+	total, active := store.Voters()
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, active)
+}
+
+func TestLoadRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "votes.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	_, err := Load(path)
+	assert.Error(t, err)
+}